@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var unexpectedStatusError = &microerror.Error{
+	Kind: "unexpectedStatusError",
+}
+
+// IsUnexpectedStatus asserts unexpectedStatusError.
+func IsUnexpectedStatus(err error) bool {
+	return microerror.Cause(err) == unexpectedStatusError
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+)
+
+const (
+	// RunLabel is the label of the synthetic IngressConfigs holding the ID of
+	// the load test run which created them.
+	RunLabel = "ingress-operator.giantswarm.io/load-test"
+)
+
+// Config represents the configuration used to create a new load test.
+type Config struct {
+	// Dependencies.
+	G8sClient clients.IngressConfigs
+	// HTTPClient is used to scrape the metrics of the operator. It defaults to
+	// a client with a timeout of 30 seconds.
+	HTTPClient *http.Client
+	Logger     micrologger.Logger
+
+	// Settings.
+
+	// Cleanup is whether the synthetic IngressConfigs are deleted once the
+	// load test finished.
+	Cleanup bool
+	// Count is the number of synthetic IngressConfigs created.
+	Count int
+	// FirstLBPort is the LB port of the first synthetic IngressConfig. Every
+	// IngressConfig requests the next LB port.
+	FirstLBPort int
+	// IngressControllerConfigMap, IngressControllerNamespace and
+	// IngressControllerService reference the host cluster ingress controller
+	// of the synthetic IngressConfigs.
+	IngressControllerConfigMap string
+	IngressControllerNamespace string
+	IngressControllerService   string
+	// MetricsURL is the URL of the metrics endpoint of the operator, e.g.
+	// http://127.0.0.1:8000/metrics. The API call volume of the operator is not
+	// measured in case it is empty.
+	MetricsURL string
+	// Namespace is the namespace the synthetic IngressConfigs are created in.
+	Namespace string
+	// PollInterval is the interval in which the conditions of the synthetic
+	// IngressConfigs are checked.
+	PollInterval time.Duration
+	// Rate is the number of synthetic IngressConfigs created per second.
+	Rate float64
+	// RunID identifies the load test run. It is part of the names of the
+	// synthetic IngressConfigs, so that runs do not collide.
+	RunID string
+	// Timeout is the time the load test waits for all synthetic IngressConfigs
+	// to become ready.
+	Timeout time.Duration
+	// VersionBundleVersion is the version bundle version of the synthetic
+	// IngressConfigs.
+	VersionBundleVersion string
+}
+
+// LoadTest creates synthetic IngressConfigs at a given rate and measures the
+// time the operator takes until they are ready.
+type LoadTest struct {
+	// Dependencies.
+	g8sClient  clients.IngressConfigs
+	httpClient *http.Client
+	logger     micrologger.Logger
+
+	// Settings.
+	cleanup                    bool
+	count                      int
+	firstLBPort                int
+	ingressControllerConfigMap string
+	ingressControllerNamespace string
+	ingressControllerService   string
+	metricsURL                 string
+	namespace                  string
+	pollInterval               time.Duration
+	rate                       float64
+	runID                      string
+	timeout                    time.Duration
+	versionBundleVersion       string
+}
+
+// New creates a new configured load test.
+func New(config Config) (*LoadTest, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Count < 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Count must be greater than 0", config)
+	}
+	if config.FirstLBPort < 1 || config.FirstLBPort+config.Count-1 > 65535 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.FirstLBPort must leave room for %d LB ports below 65536", config, config.Count)
+	}
+	if config.IngressControllerConfigMap == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IngressControllerConfigMap must not be empty", config)
+	}
+	if config.IngressControllerNamespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IngressControllerNamespace must not be empty", config)
+	}
+	if config.IngressControllerService == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IngressControllerService must not be empty", config)
+	}
+	if config.Namespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Namespace must not be empty", config)
+	}
+	if config.PollInterval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.PollInterval must be greater than 0", config)
+	}
+	if config.Rate <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Rate must be greater than 0", config)
+	}
+	if config.RunID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RunID must not be empty", config)
+	}
+	if config.Timeout <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Timeout must be greater than 0", config)
+	}
+	if config.VersionBundleVersion == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.VersionBundleVersion must not be empty", config)
+	}
+
+	l := &LoadTest{
+		g8sClient:  config.G8sClient,
+		httpClient: config.HTTPClient,
+		logger:     config.Logger,
+
+		cleanup:                    config.Cleanup,
+		count:                      config.Count,
+		firstLBPort:                config.FirstLBPort,
+		ingressControllerConfigMap: config.IngressControllerConfigMap,
+		ingressControllerNamespace: config.IngressControllerNamespace,
+		ingressControllerService:   config.IngressControllerService,
+		metricsURL:                 config.MetricsURL,
+		namespace:                  config.Namespace,
+		pollInterval:               config.PollInterval,
+		rate:                       config.Rate,
+		runID:                      config.RunID,
+		timeout:                    config.Timeout,
+		versionBundleVersion:       config.VersionBundleVersion,
+	}
+
+	return l, nil
+}
+
+// Run creates the synthetic IngressConfigs and waits until all of them are
+// ready or the timeout passed. IngressConfigs failing to be created or to
+// become ready are part of the report. Errors are only returned in case the
+// load test itself fails.
+func (l *LoadTest) Run(ctx context.Context) (Report, error) {
+	report := Report{
+		Count: l.count,
+	}
+
+	var before map[string]float64
+	if l.metricsURL != "" {
+		var err error
+		before, err = scrapeRequests(l.httpClient, l.metricsURL)
+		if err != nil {
+			return Report{}, microerror.Mask(err)
+		}
+	}
+
+	created := map[string]time.Time{}
+	ready := map[string]bool{}
+
+	createTicker := time.NewTicker(time.Duration(float64(time.Second) / l.rate))
+	defer createTicker.Stop()
+	pollTicker := time.NewTicker(l.pollInterval)
+	defer pollTicker.Stop()
+
+	start := time.Now()
+	timeout := time.After(l.timeout)
+
+	next := 0
+	for next < l.count || len(ready) < len(created) {
+		select {
+		case <-ctx.Done():
+			return Report{}, microerror.Mask(ctx.Err())
+		case <-timeout:
+			l.logger.Log("level", "warning", "message", fmt.Sprintf("timed out after %s with %d of %d IngressConfigs ready", l.timeout, len(ready), l.count))
+			next = l.count
+			created = pending(created, ready, &report)
+		case <-createTicker.C:
+			if next >= l.count {
+				continue
+			}
+
+			ingressConfig := l.newIngressConfig(next)
+			next++
+
+			report.Requests++
+			_, err := l.g8sClient.CreateIngressConfig(ctx, l.namespace, ingressConfig)
+			if err != nil {
+				l.logger.Log("level", "warning", "message", fmt.Sprintf("failed creating IngressConfig %s/%s", l.namespace, ingressConfig.Name), "stack", fmt.Sprintf("%#v", err))
+				report.CreateErrors++
+				continue
+			}
+			created[ingressConfig.Name] = time.Now()
+			report.Created++
+		case <-pollTicker.C:
+			report.Requests++
+			err := l.poll(ctx, created, ready, &report)
+			if err != nil {
+				l.logger.Log("level", "warning", "message", "failed polling IngressConfigs", "stack", fmt.Sprintf("%#v", err))
+			}
+
+			l.logger.Log("level", "info", "message", fmt.Sprintf("%d of %d IngressConfigs ready", len(ready), l.count))
+		}
+	}
+
+	report.Duration = time.Since(start)
+
+	if l.metricsURL != "" {
+		after, err := scrapeRequests(l.httpClient, l.metricsURL)
+		if err != nil {
+			return Report{}, microerror.Mask(err)
+		}
+		report.OperatorRequests = requestsDelta(before, after)
+	}
+
+	if l.cleanup {
+		l.deleteAll(ctx, &report)
+	}
+
+	return report, nil
+}
+
+// deleteAll deletes the synthetic IngressConfigs of the load test run. Errors
+// are only logged, since the report is still valid.
+func (l *LoadTest) deleteAll(ctx context.Context, report *Report) {
+	for i := 0; i < l.count; i++ {
+		name := l.name(i)
+
+		report.Requests++
+		err := l.g8sClient.DeleteIngressConfig(ctx, l.namespace, name)
+		if err != nil {
+			l.logger.Log("level", "warning", "message", fmt.Sprintf("failed deleting IngressConfig %s/%s", l.namespace, name), "stack", fmt.Sprintf("%#v", err))
+		}
+	}
+}
+
+// poll records the latencies of the created IngressConfigs which became ready
+// since the last poll. The latency ends with the transition of the Ready
+// condition, so that it does not depend on the poll interval.
+func (l *LoadTest) poll(ctx context.Context, created map[string]time.Time, ready map[string]bool, report *Report) error {
+	list, err := l.g8sClient.ListIngressConfigs(ctx, l.namespace)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	now := time.Now()
+	for _, customObject := range list.Items {
+		createdAt, ok := created[customObject.Name]
+		if !ok || ready[customObject.Name] || customObject.Labels[RunLabel] != l.runID {
+			continue
+		}
+
+		c, err := conditions.FromCustomObject(customObject)
+		if err != nil || !conditions.IsTrue(c, conditions.Ready) {
+			continue
+		}
+
+		readyAt := conditions.Get(c, conditions.Ready).LastTransitionTime.Time
+		if readyAt.Before(createdAt) {
+			readyAt = now
+		}
+
+		ready[customObject.Name] = true
+		report.Latencies = append(report.Latencies, readyAt.Sub(createdAt))
+	}
+
+	return nil
+}
+
+// name returns the name of the i-th synthetic IngressConfig, which is also
+// the ID of its guest cluster.
+func (l *LoadTest) name(i int) string {
+	return fmt.Sprintf("lt%s-%d", l.runID, i)
+}
+
+// newIngressConfig returns the i-th synthetic IngressConfig. It requests a
+// single http LB port.
+func (l *LoadTest) newIngressConfig(i int) *v1alpha1.IngressConfig {
+	return &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      l.name(i),
+			Namespace: l.namespace,
+			Labels: map[string]string{
+				RunLabel: l.runID,
+			},
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID: l.name(i),
+			},
+			HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+				IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+					ConfigMap: l.ingressControllerConfigMap,
+					Namespace: l.ingressControllerNamespace,
+					Service:   l.ingressControllerService,
+				},
+			},
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{
+					IngressPort: 30010,
+					LBPort:      l.firstLBPort + i,
+					Protocol:    "http",
+				},
+			},
+			VersionBundle: v1alpha1.IngressConfigSpecVersionBundle{
+				Version: l.versionBundleVersion,
+			},
+		},
+	}
+}
+
+// pending records the created IngressConfigs which are not ready in the given
+// report and returns the created IngressConfigs which are ready, so that the
+// load test stops waiting for the others.
+func pending(created map[string]time.Time, ready map[string]bool, report *Report) map[string]time.Time {
+	done := map[string]time.Time{}
+	for name, createdAt := range created {
+		if ready[name] {
+			done[name] = createdAt
+			continue
+		}
+		report.Pending = append(report.Pending, name)
+	}
+
+	return done
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+)
+
+// testIngressConfigs is an in-memory implementation of clients.IngressConfigs
+// marking the created IngressConfigs as ready, unless their name is one of the
+// stuck ones. Creating the IngressConfigs of the failed names fails.
+type testIngressConfigs struct {
+	mutex sync.Mutex
+
+	deleted []string
+	failed  map[string]bool
+	items   map[string]v1alpha1.IngressConfig
+	stuck   map[string]bool
+}
+
+func (c *testIngressConfigs) CreateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.failed[ingressConfig.Name] {
+		return nil, microerror.New("test error")
+	}
+
+	customObject := *ingressConfig.DeepCopy()
+	if !c.stuck[customObject.Name] {
+		a, err := conditions.ToAnnotation([]conditions.Condition{conditions.New(conditions.Ready, conditions.StatusTrue, "", "")})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		customObject.Annotations = map[string]string{conditions.Annotation: a}
+	}
+	c.items[customObject.Name] = customObject
+
+	return &customObject, nil
+}
+
+func (c *testIngressConfigs) DeleteIngressConfig(ctx context.Context, namespace, name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.deleted = append(c.deleted, name)
+	delete(c.items, name)
+
+	return nil
+}
+
+func (c *testIngressConfigs) GetIngressConfig(ctx context.Context, namespace, name string) (*v1alpha1.IngressConfig, error) {
+	return nil, microerror.New("not implemented")
+}
+
+func (c *testIngressConfigs) ListIngressConfigs(ctx context.Context, namespace string) (*v1alpha1.IngressConfigList, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	list := &v1alpha1.IngressConfigList{}
+	for _, customObject := range c.items {
+		list.Items = append(list.Items, customObject)
+	}
+
+	return list, nil
+}
+
+func (c *testIngressConfigs) UpdateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error) {
+	return nil, microerror.New("not implemented")
+}
+
+func newTestConfig(g8sClient *testIngressConfigs) Config {
+	return Config{
+		G8sClient: g8sClient,
+		Logger:    microloggertest.New(),
+
+		Cleanup:                    true,
+		Count:                      3,
+		FirstLBPort:                31000,
+		IngressControllerConfigMap: "ingress-controller",
+		IngressControllerNamespace: "kube-system",
+		IngressControllerService:   "ingress-controller",
+		Namespace:                  "default",
+		PollInterval:               5 * time.Millisecond,
+		Rate:                       1000,
+		RunID:                      "test",
+		Timeout:                    time.Second,
+		VersionBundleVersion:       "0.1.0",
+	}
+}
+
+func Test_LoadTest_Run(t *testing.T) {
+	g8sClient := &testIngressConfigs{
+		failed: map[string]bool{"lttest-1": true},
+		items:  map[string]v1alpha1.IngressConfig{},
+	}
+
+	var scrapes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scrapes++
+		if scrapes == 1 {
+			w.Write([]byte("# TYPE " + RequestsMetric + " counter\n" + RequestsMetric + "{method=\"GET\"} 10\n"))
+		} else {
+			w.Write([]byte("# TYPE " + RequestsMetric + " counter\n" + RequestsMetric + "{method=\"GET\"} 14\n" + RequestsMetric + "{method=\"PUT\"} 2\n"))
+		}
+	}))
+	defer server.Close()
+
+	c := newTestConfig(g8sClient)
+	c.MetricsURL = server.URL
+
+	l, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	report, err := l.Run(context.Background())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	if report.Created != 2 {
+		t.Fatal("expected", 2, "got", report.Created)
+	}
+	if report.CreateErrors != 1 {
+		t.Fatal("expected", 1, "got", report.CreateErrors)
+	}
+	if len(report.Latencies) != 2 {
+		t.Fatal("expected", 2, "got", len(report.Latencies))
+	}
+	if len(report.Pending) != 0 {
+		t.Fatal("expected", 0, "got", len(report.Pending))
+	}
+	if report.OperatorRequests["GET"] != 4 || report.OperatorRequests["PUT"] != 2 {
+		t.Fatal("expected", map[string]float64{"GET": 4, "PUT": 2}, "got", report.OperatorRequests)
+	}
+	if len(g8sClient.deleted) != 3 {
+		t.Fatal("expected", 3, "got", len(g8sClient.deleted))
+	}
+	if len(g8sClient.items) != 0 {
+		t.Fatal("expected", 0, "got", len(g8sClient.items))
+	}
+}
+
+func Test_LoadTest_Run_timeout(t *testing.T) {
+	g8sClient := &testIngressConfigs{
+		items: map[string]v1alpha1.IngressConfig{},
+		stuck: map[string]bool{"lttest-2": true},
+	}
+
+	c := newTestConfig(g8sClient)
+	c.Cleanup = false
+	c.Timeout = 100 * time.Millisecond
+
+	l, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	report, err := l.Run(context.Background())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	if len(report.Latencies) != 2 {
+		t.Fatal("expected", 2, "got", len(report.Latencies))
+	}
+	if len(report.Pending) != 1 || report.Pending[0] != "lttest-2" {
+		t.Fatal("expected", []string{"lttest-2"}, "got", report.Pending)
+	}
+	if report.OperatorRequests != nil {
+		t.Fatal("expected", nil, "got", report.OperatorRequests)
+	}
+	if len(g8sClient.items) != 3 {
+		t.Fatal("expected", 3, "got", len(g8sClient.items))
+	}
+}
+
+func Test_LoadTest_New(t *testing.T) {
+	c := newTestConfig(&testIngressConfigs{})
+	c.FirstLBPort = 65535
+	c.Count = 2
+
+	_, err := New(c)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
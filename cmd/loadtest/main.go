@@ -0,0 +1,132 @@
+// Command loadtest creates synthetic IngressConfigs against a test cluster at
+// a configurable rate. It measures the time the operator takes until they are
+// ready and the number of requests the operator sends to the Kubernetes API
+// meanwhile, and prints a report once all of them are ready or the timeout
+// passed. It must never be run against a production cluster, since the
+// synthetic IngressConfigs allocate LB ports of the shared ingress controller.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/ingress-operator/pkg/logger"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/kubeconfig"
+)
+
+func main() {
+	cobraCommand := &cobra.Command{
+		Use:   "loadtest",
+		Short: "Measure the reconciliation of synthetic IngressConfigs.",
+		Long:  "Create synthetic IngressConfigs against a test cluster at a configurable rate and report the latency until they are ready and the number of Kubernetes API requests of the operator.",
+		Run:   execute,
+	}
+
+	cobraCommand.Flags().String("kubeconfig", os.Getenv("KUBECONFIG"), "Path of the kubeconfig file of the test cluster.")
+	cobraCommand.Flags().String("context", "", "Name of the kubeconfig context. The current context is used when empty.")
+	cobraCommand.Flags().String("namespace", "default", "Namespace the synthetic IngressConfigs are created in.")
+	cobraCommand.Flags().Int("count", 100, "Number of synthetic IngressConfigs to create.")
+	cobraCommand.Flags().Float64("rate", 10, "Number of synthetic IngressConfigs created per second.")
+	cobraCommand.Flags().Int("first-lb-port", 31000, "LB port of the first synthetic IngressConfig. Every IngressConfig requests the next LB port.")
+	cobraCommand.Flags().String("ingress-controller-configmap", "ingress-controller", "Name of the config map of the shared ingress controller.")
+	cobraCommand.Flags().String("ingress-controller-namespace", "kube-system", "Namespace of the shared ingress controller.")
+	cobraCommand.Flags().String("ingress-controller-service", "ingress-controller", "Name of the service of the shared ingress controller.")
+	cobraCommand.Flags().String("version-bundle-version", "0.1.0", "Version bundle version of the synthetic IngressConfigs.")
+	cobraCommand.Flags().String("operator-metrics-url", "", "URL of the metrics endpoint of the operator, e.g. http://127.0.0.1:8000/metrics. The API requests of the operator are not reported when empty.")
+	cobraCommand.Flags().String("run-id", strconv.FormatInt(time.Now().Unix(), 36), "ID of the load test run, which is part of the names of the synthetic IngressConfigs.")
+	cobraCommand.Flags().Duration("poll-interval", time.Second, "Interval in which the conditions of the synthetic IngressConfigs are checked.")
+	cobraCommand.Flags().Duration("timeout", 10*time.Minute, "Time to wait for all synthetic IngressConfigs to become ready.")
+	cobraCommand.Flags().Bool("cleanup", true, "Delete the synthetic IngressConfigs once the load test finished.")
+
+	err := cobraCommand.Execute()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func execute(cmd *cobra.Command, args []string) {
+	newLogger, err := logger.New(logger.DefaultConfig())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not create logger: %#v\n", err)
+		os.Exit(1)
+	}
+
+	kubeConfigPath, _ := cmd.Flags().GetString("kubeconfig")
+	kubeConfigContext, _ := cmd.Flags().GetString("context")
+
+	restConfig, err := kubeconfig.New(kubeconfig.Config{Context: kubeConfigContext, Path: kubeConfigPath})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not load kubeconfig: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	g8sClient, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not create clientset: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	g8sClients, err := clients.NewG8s(clients.G8sConfig{G8sClient: g8sClient})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not create clients: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	c := Config{
+		G8sClient: g8sClients,
+		Logger:    newLogger,
+	}
+	c.Cleanup, _ = cmd.Flags().GetBool("cleanup")
+	c.Count, _ = cmd.Flags().GetInt("count")
+	c.FirstLBPort, _ = cmd.Flags().GetInt("first-lb-port")
+	c.IngressControllerConfigMap, _ = cmd.Flags().GetString("ingress-controller-configmap")
+	c.IngressControllerNamespace, _ = cmd.Flags().GetString("ingress-controller-namespace")
+	c.IngressControllerService, _ = cmd.Flags().GetString("ingress-controller-service")
+	c.MetricsURL, _ = cmd.Flags().GetString("operator-metrics-url")
+	c.Namespace, _ = cmd.Flags().GetString("namespace")
+	c.PollInterval, _ = cmd.Flags().GetDuration("poll-interval")
+	c.Rate, _ = cmd.Flags().GetFloat64("rate")
+	c.RunID, _ = cmd.Flags().GetString("run-id")
+	c.Timeout, _ = cmd.Flags().GetDuration("timeout")
+	c.VersionBundleVersion, _ = cmd.Flags().GetString("version-bundle-version")
+
+	loadTest, err := New(c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not create load test: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	// The load test is canceled on interrupt. The synthetic IngressConfigs
+	// created so far are left behind in that case and carry the run label.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	{
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			cancel()
+		}()
+	}
+
+	report, err := loadTest.Run(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not run load test: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	err = render(os.Stdout, report)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not render report: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	// RequestsMetric is the metric of the operator counting the requests it
+	// sent to the Kubernetes API by HTTP method.
+	RequestsMetric = "ingress_operator_kubernetes_requests_total"
+)
+
+// scrapeRequests returns the number of requests the operator sent to the
+// Kubernetes API by HTTP method, as exposed by its metrics endpoint at the
+// given URL.
+func scrapeRequests(httpClient *http.Client, url string) (map[string]float64, error) {
+	res, err := httpClient.Get(url)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, microerror.Maskf(unexpectedStatusError, "expected status %d from %s, got %d", http.StatusOK, url, res.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(res.Body)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	requests := map[string]float64{}
+	family, ok := families[RequestsMetric]
+	if !ok {
+		return requests, nil
+	}
+
+	for _, m := range family.GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "method" {
+				requests[l.GetValue()] += m.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return requests, nil
+}
+
+// requestsDelta returns the requests counted between the given scrapes.
+func requestsDelta(before, after map[string]float64) map[string]float64 {
+	delta := map[string]float64{}
+	for method, v := range after {
+		delta[method] = v - before[method]
+	}
+
+	return delta
+}
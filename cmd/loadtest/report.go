@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Report is the outcome of a load test run.
+type Report struct {
+	// Count is the number of IngressConfigs the load test was meant to create.
+	Count int
+	// Created is the number of IngressConfigs created successfully.
+	Created int
+	// CreateErrors is the number of IngressConfigs failing to be created.
+	CreateErrors int
+	// Duration is the time from the first creation until all IngressConfigs
+	// were ready or the timeout passed.
+	Duration time.Duration
+	// Latencies are the times between the creation of the IngressConfigs and
+	// the transition of their Ready condition, in the order the load test
+	// observed them.
+	Latencies []time.Duration
+	// OperatorRequests is the number of requests the operator sent to the
+	// Kubernetes API during the load test by HTTP method. It is nil in case the
+	// metrics of the operator were not scraped.
+	OperatorRequests map[string]float64
+	// Pending are the names of the IngressConfigs which were created but not
+	// ready when the timeout passed.
+	Pending []string
+	// Requests is the number of requests the load test itself sent to the
+	// Kubernetes API.
+	Requests int
+}
+
+// Percentile returns the latency below which the given percentage of the
+// observed latencies fall, using the nearest rank method. It returns zero in
+// case no latency was observed.
+func (r Report) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+
+	sorted := make([]time.Duration, len(r.Latencies))
+	copy(sorted, r.Latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+
+	return sorted[rank]
+}
+
+// render writes the given report to w as table.
+func render(w io.Writer, r Report) error {
+	t := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(t, "IngressConfigs\t%d\n", r.Count)
+	fmt.Fprintf(t, "Created\t%d\n", r.Created)
+	fmt.Fprintf(t, "Create errors\t%d\n", r.CreateErrors)
+	fmt.Fprintf(t, "Ready\t%d\n", len(r.Latencies))
+	fmt.Fprintf(t, "Pending\t%d\n", len(r.Pending))
+	fmt.Fprintf(t, "Duration\t%s\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(t, "\n")
+
+	fmt.Fprintf(t, "Latency p50\t%s\n", r.Percentile(50).Round(time.Millisecond))
+	fmt.Fprintf(t, "Latency p90\t%s\n", r.Percentile(90).Round(time.Millisecond))
+	fmt.Fprintf(t, "Latency p99\t%s\n", r.Percentile(99).Round(time.Millisecond))
+	fmt.Fprintf(t, "Latency max\t%s\n", r.Percentile(100).Round(time.Millisecond))
+	fmt.Fprintf(t, "\n")
+
+	fmt.Fprintf(t, "Load test API requests\t%d\n", r.Requests)
+	if r.OperatorRequests == nil {
+		fmt.Fprintf(t, "Operator API requests\tn/a\n")
+	} else {
+		var methods []string
+		var total float64
+		for m, v := range r.OperatorRequests {
+			methods = append(methods, m)
+			total += v
+		}
+		sort.Strings(methods)
+
+		fmt.Fprintf(t, "Operator API requests\t%.0f\n", total)
+		for _, m := range methods {
+			fmt.Fprintf(t, "  %s\t%.0f\n", m, r.OperatorRequests[m])
+		}
+		if len(r.Latencies) > 0 {
+			fmt.Fprintf(t, "Operator API requests per IngressConfig\t%.1f\n", total/float64(len(r.Latencies)))
+		}
+	}
+
+	if len(r.Pending) > 0 {
+		pending := make([]string, len(r.Pending))
+		copy(pending, r.Pending)
+		sort.Strings(pending)
+
+		fmt.Fprintf(t, "\n")
+		fmt.Fprintf(t, "Pending IngressConfigs\t%s\n", strings.Join(pending, ", "))
+	}
+
+	err := t.Flush()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Report_Percentile(t *testing.T) {
+	testCases := []struct {
+		latencies []time.Duration
+		p         float64
+		expected  time.Duration
+	}{
+		// Test 0 ensures zero is returned without latencies.
+		{
+			latencies: nil,
+			p:         50,
+			expected:  0,
+		},
+		// Test 1 ensures the median of unsorted latencies is returned.
+		{
+			latencies: []time.Duration{3 * time.Second, 1 * time.Second, 2 * time.Second},
+			p:         50,
+			expected:  2 * time.Second,
+		},
+		// Test 2 ensures the maximum is returned for the 100th percentile.
+		{
+			latencies: []time.Duration{3 * time.Second, 1 * time.Second, 2 * time.Second},
+			p:         100,
+			expected:  3 * time.Second,
+		},
+		// Test 3 ensures the nearest rank is used for small samples.
+		{
+			latencies: []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second},
+			p:         90,
+			expected:  4 * time.Second,
+		},
+	}
+
+	for i, tc := range testCases {
+		r := Report{Latencies: tc.latencies}
+		p := r.Percentile(tc.p)
+		if p != tc.expected {
+			t.Fatal("test", i, "expected", tc.expected, "got", p)
+		}
+	}
+}
+
+func Test_Report_Render(t *testing.T) {
+	r := Report{
+		Count:            3,
+		Created:          3,
+		Latencies:        []time.Duration{time.Second, 2 * time.Second},
+		OperatorRequests: map[string]float64{"GET": 8, "PUT": 4},
+		Pending:          []string{"lttest-2"},
+		Requests:         5,
+	}
+
+	var b bytes.Buffer
+	err := render(&b, r)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// The columns are compared regardless of their padding.
+	var lines []string
+	for _, l := range strings.Split(b.String(), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(l), " "))
+	}
+	output := strings.Join(lines, "\n")
+
+	for _, s := range []string{"Ready 2", "Latency p50 1s", "Latency max 2s", "Operator API requests 12", "PUT 4", "Operator API requests per IngressConfig 6.0", "Pending IngressConfigs lttest-2"} {
+		if !strings.Contains(output, s) {
+			t.Fatal("expected", s, "got", output)
+		}
+	}
+}
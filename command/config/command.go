@@ -0,0 +1,155 @@
+// Package config implements the config command of the operator. The command
+// resolves command line flags, environment variables and config files the same
+// way the daemon command does and prints the resulting effective configuration
+// as YAML.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	daemonflag "github.com/giantswarm/microkit/command/daemon/flag"
+	microflag "github.com/giantswarm/microkit/flag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// RedactedValue is printed instead of the actual value of configuration
+	// keys considered sensitive.
+	RedactedValue = "<redacted>"
+)
+
+var (
+	f = daemonflag.New()
+
+	// sensitiveKeyParts are segments of configuration keys which mark the
+	// configured value as sensitive. Only whole segments match, so that file
+	// paths of e.g. token files are not considered sensitive, only inline
+	// secrets are.
+	sensitiveKeyParts = []string{
+		"password",
+		"secret",
+		"token",
+	}
+)
+
+// Config represents the configuration used to create a new config command.
+type Config struct {
+	// FlagSet is the set of flags the effective configuration is resolved for.
+	// This is usually the set of persistent flags of the daemon command.
+	FlagSet *pflag.FlagSet
+	Viper   *viper.Viper
+}
+
+// New creates a new config command.
+func New(config Config) (Command, error) {
+	if config.FlagSet == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.FlagSet must not be empty", config)
+	}
+	if config.Viper == nil {
+		config.Viper = viper.New()
+	}
+
+	newCommand := &command{
+		cobraCommand: nil,
+
+		viper: config.Viper,
+	}
+
+	newCommand.cobraCommand = &cobra.Command{
+		Use:   "config",
+		Short: "Print the effective configuration of the operator.",
+		Long:  "Print the effective configuration of the operator, resolved from command line flags, environment variables and config files. Sensitive values are redacted.",
+		Run:   newCommand.Execute,
+	}
+
+	newCommand.cobraCommand.Flags().AddFlagSet(config.FlagSet)
+
+	return newCommand, nil
+}
+
+type command struct {
+	// Internals.
+	cobraCommand *cobra.Command
+
+	// Settings.
+	viper *viper.Viper
+}
+
+func (c *command) CobraCommand() *cobra.Command {
+	return c.cobraCommand
+}
+
+func (c *command) Execute(cmd *cobra.Command, args []string) {
+	// The configuration is resolved exactly like the daemon command does it, so
+	// what we print here is what the daemon would actually use.
+	microflag.Parse(c.viper, cmd.Flags())
+
+	err := microflag.Merge(c.viper, cmd.Flags(), c.viper.GetStringSlice(f.Config.Dirs), c.viper.GetStringSlice(f.Config.Files))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not resolve configuration: %#v\n", err)
+		os.Exit(1)
+	}
+
+	d, err := yaml.Marshal(effectiveConfig(c.viper, cmd.Flags()))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not format configuration: %#v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s", d)
+}
+
+// effectiveConfig converts the flat, dot separated flag names of the given
+// flag set into a nested map holding the values resolved by the given viper.
+func effectiveConfig(v *viper.Viper, fs *pflag.FlagSet) map[string]interface{} {
+	m := map[string]interface{}{}
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Name == "help" {
+			return
+		}
+
+		var value interface{}
+		if isSensitive(f.Name) {
+			value = RedactedValue
+		} else {
+			value = v.Get(f.Name)
+		}
+
+		parts := strings.Split(f.Name, ".")
+		current := m
+		for i, p := range parts {
+			if i == len(parts)-1 {
+				current[p] = value
+				break
+			}
+
+			next, ok := current[p].(map[string]interface{})
+			if !ok {
+				next = map[string]interface{}{}
+				current[p] = next
+			}
+			current = next
+		}
+	})
+
+	return m
+}
+
+func isSensitive(name string) bool {
+	for _, s := range strings.Split(strings.ToLower(name), ".") {
+		for _, p := range sensitiveKeyParts {
+			if s == p {
+				return true
+			}
+		}
+	}
+
+	return false
+}
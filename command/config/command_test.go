@@ -0,0 +1,89 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func Test_Config_effectiveConfig(t *testing.T) {
+	testCases := []struct {
+		Args     []string
+		Expected map[string]interface{}
+	}{
+		// Test 0 ensures default values are resolved into a nested map.
+		{
+			Args: []string{},
+			Expected: map[string]interface{}{
+				"service": map[string]interface{}{
+					"kubernetes": map[string]interface{}{
+						"address":   "http://127.0.0.1:6443",
+						"incluster": false,
+						"token":     RedactedValue,
+						"tokenfile": "",
+					},
+				},
+			},
+		},
+
+		// Test 1 ensures given flags overwrite defaults and sensitive values are
+		// redacted.
+		{
+			Args: []string{
+				"--service.kubernetes.address=https://10.0.0.1",
+				"--service.kubernetes.incluster=true",
+				"--service.kubernetes.token=secret-value",
+			},
+			Expected: map[string]interface{}{
+				"service": map[string]interface{}{
+					"kubernetes": map[string]interface{}{
+						"address":   "https://10.0.0.1",
+						"incluster": true,
+						"token":     RedactedValue,
+						"tokenfile": "",
+					},
+				},
+			},
+		},
+
+		// Test 2 ensures file paths of sensitive values are not redacted.
+		{
+			Args: []string{
+				"--service.kubernetes.tokenfile=/var/run/secrets/token",
+			},
+			Expected: map[string]interface{}{
+				"service": map[string]interface{}{
+					"kubernetes": map[string]interface{}{
+						"address":   "http://127.0.0.1:6443",
+						"incluster": false,
+						"token":     RedactedValue,
+						"tokenfile": "/var/run/secrets/token",
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.String("service.kubernetes.address", "http://127.0.0.1:6443", "")
+		fs.Bool("service.kubernetes.incluster", false, "")
+		fs.String("service.kubernetes.token", "", "")
+		fs.String("service.kubernetes.tokenfile", "", "")
+
+		err := fs.Parse(tc.Args)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		v := viper.New()
+		v.BindPFlags(fs)
+
+		result := effectiveConfig(v, fs)
+		if !reflect.DeepEqual(tc.Expected, result) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, result)
+		}
+	}
+}
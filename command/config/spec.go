@@ -0,0 +1,13 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Command represents the config command of the operator.
+type Command interface {
+	// CobraCommand returns the actual cobra command for the config command.
+	CobraCommand() *cobra.Command
+	// Execute represents the cobra run method.
+	Execute(cmd *cobra.Command, args []string)
+}
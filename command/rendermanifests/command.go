@@ -0,0 +1,130 @@
+// Package rendermanifests implements the render-manifests command of the
+// operator. The command prints the manifests required to deploy the operator,
+// with the daemon flags given to the command baked into the arguments of the
+// Deployment. It is meant for installations not using the app platform, which
+// can then deploy the operator reproducibly from the binary itself, e.g.:
+//
+//	ingress-operator render-manifests --namespace=ingress --service.hostcluster.reservedports=30000 | kubectl apply -f -
+package rendermanifests
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	// DefaultNamespace is the default namespace the operator is deployed to.
+	DefaultNamespace = "giantswarm"
+	// DefaultRegistry is the default registry the image of the operator is
+	// pulled from.
+	DefaultRegistry = "quay.io/giantswarm"
+)
+
+// Config represents the configuration used to create a new render-manifests
+// command.
+type Config struct {
+	// FlagSet is the set of daemon flags which can be baked into the rendered
+	// Deployment. This is usually the set of persistent flags of the daemon
+	// command.
+	FlagSet *pflag.FlagSet
+}
+
+// New creates a new render-manifests command.
+func New(config Config) (Command, error) {
+	if config.FlagSet == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.FlagSet must not be empty", config)
+	}
+
+	newCommand := &command{
+		cobraCommand: nil,
+
+		daemonFlags: config.FlagSet,
+	}
+
+	newCommand.cobraCommand = &cobra.Command{
+		Use:   "render-manifests",
+		Short: "Print the manifests required to deploy the operator.",
+		Long:  "Print the Deployment, RBAC, CRD, Service and PodMonitor manifests required to deploy the operator. Daemon flags given to this command are baked into the arguments of the Deployment.",
+		Run:   newCommand.Execute,
+	}
+
+	newCommand.cobraCommand.Flags().String("image", defaultImage(), "Container image of the operator.")
+	newCommand.cobraCommand.Flags().String("namespace", DefaultNamespace, "Namespace the operator is deployed to.")
+	newCommand.cobraCommand.Flags().Bool("rbac-only", false, "Print only the ClusterRole, Role and their bindings granting the permissions the operator uses.")
+	newCommand.cobraCommand.Flags().AddFlagSet(config.FlagSet)
+
+	return newCommand, nil
+}
+
+type command struct {
+	// Internals.
+	cobraCommand *cobra.Command
+
+	// Settings.
+	daemonFlags *pflag.FlagSet
+}
+
+func (c *command) CobraCommand() *cobra.Command {
+	return c.cobraCommand
+}
+
+func (c *command) Execute(cmd *cobra.Command, args []string) {
+	image, err := cmd.Flags().GetString("image")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not resolve image: %#v\n", err)
+		os.Exit(1)
+	}
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not resolve namespace: %#v\n", err)
+		os.Exit(1)
+	}
+
+	rbacOnly, err := cmd.Flags().GetBool("rbac-only")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not resolve rbac-only: %#v\n", err)
+		os.Exit(1)
+	}
+
+	// The certificate namespace is a daemon flag, whose default applies in
+	// case it is not given.
+	certificateNamespace, err := c.daemonFlags.GetString(f.Service.Controller.Certificates.Namespace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not resolve certificate namespace: %#v\n", err)
+		os.Exit(1)
+	}
+
+	s := Settings{
+		Args:                 DaemonArgs(c.daemonFlags),
+		CertificateNamespace: certificateNamespace,
+		Image:                image,
+		Namespace:            namespace,
+		RBACOnly:             rbacOnly,
+	}
+
+	b, err := Render(s)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not render manifests: %#v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s", b)
+}
+
+// defaultImage returns the image of the operator build executing the command.
+// Builds are tagged with their git SHA, so the version is only used in case no
+// git SHA got injected.
+func defaultImage() string {
+	tag := project.GitSHA()
+	if tag == "n/a" {
+		tag = project.Version()
+	}
+
+	return fmt.Sprintf("%s/%s:%s", DefaultRegistry, project.Name(), tag)
+}
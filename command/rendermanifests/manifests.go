@@ -0,0 +1,367 @@
+package rendermanifests
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	daemonflag "github.com/giantswarm/microkit/command/daemon/flag"
+	"github.com/spf13/pflag"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/giantswarm/ingress-operator/flag"
+	"github.com/giantswarm/ingress-operator/pkg/project"
+	"github.com/giantswarm/ingress-operator/pkg/rbac"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller"
+)
+
+const (
+	// Port is the container port the HTTP server of the operator listens on.
+	Port = 8000
+)
+
+var (
+	d = daemonflag.New()
+	f = flag.New()
+
+	// defaultArgs are the daemon flags every rendered Deployment is given,
+	// unless they are explicitly set. The operator listens on all interfaces
+	// so that it can be scraped and authenticates using its service account.
+	defaultArgs = map[string]string{
+		d.Server.Listen.Address:        fmt.Sprintf("http://0.0.0.0:%d", Port),
+		f.Service.Kubernetes.InCluster: "true",
+	}
+
+	// ignoredFlags are daemon flags which are never baked into the rendered
+	// Deployment. The rendered manifests do not ship config files, so the
+	// configuration is given by command line flags only.
+	ignoredFlags = map[string]bool{
+		d.Config.Dirs:  true,
+		d.Config.Files: true,
+	}
+)
+
+// Settings are the settings manifests are rendered with.
+type Settings struct {
+	// Args are the arguments of the daemon command in the format
+	// --name=value.
+	Args []string
+	// CertificateNamespace is the namespace the operator reads the certificate
+	// Secrets of guest clusters in. The namespaced permissions of the operator
+	// are granted by a Role in it.
+	CertificateNamespace string
+	Image                string
+	Namespace            string
+	// RBACOnly restricts the rendered manifests to the ClusterRole, the Role
+	// and their bindings.
+	RBACOnly bool
+}
+
+// DaemonArgs returns the arguments of the daemon command for all flags
+// explicitly set in the given flag set, merged with the default arguments
+// required to run the operator in a cluster. The arguments are sorted by flag
+// name.
+func DaemonArgs(fs *pflag.FlagSet) []string {
+	values := map[string]string{}
+	for k, v := range defaultArgs {
+		values[k] = v
+	}
+
+	fs.VisitAll(func(fl *pflag.Flag) {
+		if !fl.Changed || ignoredFlags[fl.Name] {
+			return
+		}
+
+		v := fl.Value.String()
+		if strings.HasSuffix(fl.Value.Type(), "Slice") {
+			// Slice values are formatted like [a,b] but parsed like a,b.
+			v = strings.TrimSuffix(strings.TrimPrefix(v, "["), "]")
+		}
+		values[fl.Name] = v
+	})
+
+	var names []string
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, n := range names {
+		args = append(args, fmt.Sprintf("--%s=%s", n, values[n]))
+	}
+
+	return args
+}
+
+// Render returns the YAML documents of all manifests required to deploy the
+// operator with the given settings.
+func Render(s Settings) ([]byte, error) {
+	if s.Image == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Image must not be empty", s)
+	}
+	if s.Namespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Namespace must not be empty", s)
+	}
+	if s.CertificateNamespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CertificateNamespace must not be empty", s)
+	}
+
+	objects := []interface{}{
+		v1alpha1.NewIngressConfigCRD(),
+		newServiceAccount(s),
+		newClusterRole(s),
+		newClusterRoleBinding(s),
+		newRole(s),
+		newRoleBinding(s),
+		newDeployment(s),
+		newService(s),
+		newPodMonitor(s),
+	}
+	if s.RBACOnly {
+		objects = []interface{}{
+			newClusterRole(s),
+			newClusterRoleBinding(s),
+			newRole(s),
+			newRoleBinding(s),
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, o := range objects {
+		b, err := yaml.Marshal(o)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		buf.WriteString("---\n")
+		buf.Write(b)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func labels() map[string]string {
+	return map[string]string{
+		"app": project.Name(),
+	}
+}
+
+func newClusterRole(s Settings) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "ClusterRole",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   project.Name(),
+			Labels: labels(),
+		},
+		Rules: rbac.Rules(permissions()...),
+	}
+}
+
+func newClusterRoleBinding(s Settings) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "ClusterRoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   project.Name(),
+			Labels: labels(),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      project.Name(),
+				Namespace: s.Namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     project.Name(),
+		},
+	}
+}
+
+func newDeployment(s Settings) *appsv1.Deployment {
+	replicas := int32(1)
+	revisionHistoryLimit := int32(3)
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      project.Name(),
+			Namespace: s.Namespace,
+			Labels:    labels(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas:             &replicas,
+			RevisionHistoryLimit: &revisionHistoryLimit,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels(),
+			},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels(),
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: project.Name(),
+					Containers: []corev1.Container{
+						{
+							Name:  project.Name(),
+							Image: s.Image,
+							Args:  append([]string{"daemon"}, s.Args...),
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: Port,
+								},
+							},
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									HTTPGet: &corev1.HTTPGetAction{
+										Path: "/healthz",
+										Port: intstr.FromString("http"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newPodMonitor returns the PodMonitor of the Prometheus operator scraping the
+// operator. Its types are not vendored, which is why it is defined as plain
+// map.
+func newPodMonitor(s Settings) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "PodMonitor",
+		"metadata": map[string]interface{}{
+			"name":      project.Name(),
+			"namespace": s.Namespace,
+			"labels":    labels(),
+		},
+		"spec": map[string]interface{}{
+			"podMetricsEndpoints": []interface{}{
+				map[string]interface{}{
+					"path": "/metrics",
+					"port": "http",
+				},
+			},
+			"selector": map[string]interface{}{
+				"matchLabels": labels(),
+			},
+		},
+	}
+}
+
+func newRole(s Settings) *rbacv1.Role {
+	return &rbacv1.Role{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "Role",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      project.Name(),
+			Namespace: s.CertificateNamespace,
+			Labels:    labels(),
+		},
+		Rules: rbac.NamespacedRules(permissions()...),
+	}
+}
+
+func newRoleBinding(s Settings) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "RoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      project.Name(),
+			Namespace: s.CertificateNamespace,
+			Labels:    labels(),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      project.Name(),
+				Namespace: s.Namespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     project.Name(),
+		},
+	}
+}
+
+func newService(s Settings) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      project.Name(),
+			Namespace: s.Namespace,
+			Labels:    labels(),
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       Port,
+					TargetPort: intstr.FromString("http"),
+				},
+			},
+			Selector: labels(),
+		},
+	}
+}
+
+func newServiceAccount(s Settings) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ServiceAccount",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      project.Name(),
+			Namespace: s.Namespace,
+			Labels:    labels(),
+		},
+	}
+}
+
+// permissions returns the permissions declared by the clients and controllers
+// of the operator.
+func permissions() []rbac.Permission {
+	var l []rbac.Permission
+	l = append(l, clients.Permissions()...)
+	l = append(l, controller.Permissions()...)
+
+	return l
+}
@@ -0,0 +1,137 @@
+package rendermanifests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/pflag"
+)
+
+func Test_RenderManifests_DaemonArgs(t *testing.T) {
+	testCases := []struct {
+		Args     []string
+		Expected []string
+	}{
+		// Test 0 ensures the default arguments are given when no flags are set.
+		{
+			Args: []string{},
+			Expected: []string{
+				"--server.listen.address=http://0.0.0.0:8000",
+				"--service.kubernetes.incluster=true",
+			},
+		},
+
+		// Test 1 ensures set flags are baked in and overwrite the defaults, while
+		// config file flags are ignored.
+		{
+			Args: []string{
+				"--config.dirs=/etc/ingress-operator",
+				"--server.listen.address=http://0.0.0.0:9000",
+				"--service.hostcluster.reservedports=30000,30001",
+			},
+			Expected: []string{
+				"--server.listen.address=http://0.0.0.0:9000",
+				"--service.hostcluster.reservedports=30000,30001",
+				"--service.kubernetes.incluster=true",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.StringSlice("config.dirs", []string{"."}, "")
+		fs.String("server.listen.address", "http://127.0.0.1:8000", "")
+		fs.StringSlice("service.hostcluster.reservedports", []string{}, "")
+		fs.Bool("service.kubernetes.incluster", false, "")
+
+		err := fs.Parse(tc.Args)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		args := DaemonArgs(fs)
+		if !reflect.DeepEqual(args, tc.Expected) {
+			t.Fatal("test", i, "expected", tc.Expected, "got", args)
+		}
+	}
+}
+
+func Test_RenderManifests_Render(t *testing.T) {
+	s := Settings{
+		Args:                 []string{"--service.kubernetes.incluster=true"},
+		CertificateNamespace: "default",
+		Image:                "quay.io/giantswarm/ingress-operator:0.1.0",
+		Namespace:            "ingress",
+	}
+
+	b, err := Render(s)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	var kinds []string
+	for _, d := range strings.Split(string(b), "---\n") {
+		if d == "" {
+			continue
+		}
+
+		var o struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		err := yaml.Unmarshal([]byte(d), &o)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		kinds = append(kinds, o.Kind)
+
+		// The Role and its binding grant access to the certificate Secrets in
+		// their namespace.
+		namespace := s.Namespace
+		if o.Kind == "Role" || o.Kind == "RoleBinding" {
+			namespace = s.CertificateNamespace
+		}
+		if o.Metadata.Namespace != "" && o.Metadata.Namespace != namespace {
+			t.Fatal("expected", namespace, "got", o.Metadata.Namespace)
+		}
+	}
+
+	expected := []string{
+		"CustomResourceDefinition",
+		"ServiceAccount",
+		"ClusterRole",
+		"ClusterRoleBinding",
+		"Role",
+		"RoleBinding",
+		"Deployment",
+		"Service",
+		"PodMonitor",
+	}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Fatal("expected", expected, "got", kinds)
+	}
+
+	for _, e := range []string{s.Image, "- daemon", "- --service.kubernetes.incluster=true"} {
+		if !strings.Contains(string(b), e) {
+			t.Fatal("expected", e, "got", string(b))
+		}
+	}
+
+	s.RBACOnly = true
+	b, err = Render(s)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if strings.Count(string(b), "---\n") != 4 || !strings.Contains(string(b), "kind: ClusterRole\n") || !strings.Contains(string(b), "kind: Role\n") {
+		t.Fatal("expected", "ClusterRole, Role and their bindings", "got", string(b))
+	}
+
+	_, err = Render(Settings{Image: s.Image})
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
@@ -0,0 +1,14 @@
+package rendermanifests
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Command represents the render-manifests command of the operator.
+type Command interface {
+	// CobraCommand returns the actual cobra command for the render-manifests
+	// command.
+	CobraCommand() *cobra.Command
+	// Execute represents the cobra run method.
+	Execute(cmd *cobra.Command, args []string)
+}
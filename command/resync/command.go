@@ -0,0 +1,116 @@
+// Package resync implements the resync command of the operator. The command
+// asks a running operator to requeue all IngressConfigs for reconciliation at
+// the rate the operator is configured with. It is meant to be used after
+// maintenance of the host cluster instead of restarting the operator.
+package resync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// DefaultAddress is the default address of the operator's HTTP server.
+	DefaultAddress = "http://127.0.0.1:8000"
+	// Path is the HTTP request path of the resync endpoint of the operator.
+	Path = "/resync"
+)
+
+// Config represents the configuration used to create a new resync command.
+type Config struct {
+	// HTTPClient is used to call the operator. It defaults to a client with a
+	// timeout of 30 seconds.
+	HTTPClient *http.Client
+}
+
+// New creates a new resync command.
+func New(config Config) (Command, error) {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	newCommand := &command{
+		cobraCommand: nil,
+
+		httpClient: config.HTTPClient,
+	}
+
+	newCommand.cobraCommand = &cobra.Command{
+		Use:   "resync",
+		Short: "Requeue all IngressConfigs of a running operator for reconciliation.",
+		Long:  "Requeue all IngressConfigs of a running operator for reconciliation at the rate the operator is configured with, e.g. after maintenance of the host cluster.",
+		Run:   newCommand.Execute,
+	}
+
+	newCommand.cobraCommand.Flags().String("address", DefaultAddress, "Address of the HTTP server of the operator.")
+
+	return newCommand, nil
+}
+
+type command struct {
+	// Internals.
+	cobraCommand *cobra.Command
+
+	// Settings.
+	httpClient *http.Client
+}
+
+// Response is the response of the resync endpoint of the operator.
+type Response struct {
+	IngressConfigs int `json:"ingressConfigs"`
+	Rate           int `json:"rate"`
+}
+
+func (c *command) CobraCommand() *cobra.Command {
+	return c.cobraCommand
+}
+
+func (c *command) Execute(cmd *cobra.Command, args []string) {
+	address, err := cmd.Flags().GetString("address")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not resolve address: %#v\n", err)
+		os.Exit(1)
+	}
+
+	r, err := resync(c.httpClient, address)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not start resync: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("Requeueing %d IngressConfigs at %d per second.\n", r.IngressConfigs, r.Rate)
+}
+
+// resync starts a resync of the operator listening on the given address.
+func resync(httpClient *http.Client, address string) (Response, error) {
+	res, err := httpClient.Post(strings.TrimSuffix(address, "/")+Path, "application/json", nil)
+	if err != nil {
+		return Response{}, microerror.Mask(err)
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return Response{}, microerror.Mask(err)
+	}
+
+	if res.StatusCode != http.StatusAccepted {
+		return Response{}, microerror.Maskf(unexpectedStatusError, "expected %d, got %d: %s", http.StatusAccepted, res.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	var r Response
+	err = json.Unmarshal(b, &r)
+	if err != nil {
+		return Response{}, microerror.Mask(err)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,58 @@
+package resync
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Resync_resync(t *testing.T) {
+	testCases := []struct {
+		Status       int
+		Body         string
+		Expected     Response
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures the response of a started resync is returned.
+		{
+			Status:       http.StatusAccepted,
+			Body:         `{"ingressConfigs":42,"rate":5}`,
+			Expected:     Response{IngressConfigs: 42, Rate: 5},
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures a rejected resync results in an error.
+		{
+			Status:       http.StatusTooManyRequests,
+			Body:         `{"code":"TOO_MANY_REQUESTS"}`,
+			Expected:     Response{},
+			ErrorMatcher: IsUnexpectedStatus,
+		},
+	}
+
+	for i, tc := range testCases {
+		var method, path string
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method = r.Method
+			path = r.URL.Path
+			w.WriteHeader(tc.Status)
+			w.Write([]byte(tc.Body))
+		}))
+
+		r, err := resync(s.Client(), s.URL+"/")
+		s.Close()
+
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if r != tc.Expected {
+			t.Fatal("test", i, "expected", tc.Expected, "got", r)
+		}
+		if method != "POST" || path != Path {
+			t.Fatal("test", i, "expected", "POST "+Path, "got", method+" "+path)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package resync
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var unexpectedStatusError = &microerror.Error{
+	Kind: "unexpectedStatusError",
+}
+
+// IsUnexpectedStatus asserts unexpectedStatusError.
+func IsUnexpectedStatus(err error) bool {
+	return microerror.Cause(err) == unexpectedStatusError
+}
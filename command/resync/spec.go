@@ -0,0 +1,13 @@
+package resync
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Command represents the resync command of the operator.
+type Command interface {
+	// CobraCommand returns the actual cobra command for the resync command.
+	CobraCommand() *cobra.Command
+	// Execute represents the cobra run method.
+	Execute(cmd *cobra.Command, args []string)
+}
@@ -0,0 +1,256 @@
+// Package status implements the status command of the operator. The command
+// queries the HTTP API of a running operator and renders a table of the guest
+// clusters, their LB ports and the outcome of their latest reconciliation. It
+// is meant for on-call engineers lacking access to the host cluster.
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	healthzservice "github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/ingress-operator/server/endpoint/allocations"
+	historyendpoint "github.com/giantswarm/ingress-operator/server/endpoint/history"
+	"github.com/giantswarm/ingress-operator/service/history"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+const (
+	// DefaultEndpoint is the default address of the operator's HTTP server.
+	DefaultEndpoint = "http://127.0.0.1:8000"
+	// HealthzPath is the HTTP request path of the health endpoint of the
+	// operator.
+	HealthzPath = "/healthz"
+)
+
+// Config represents the configuration used to create a new status command.
+type Config struct {
+	// HTTPClient is used to call the operator. It defaults to a client with a
+	// timeout of 30 seconds.
+	HTTPClient *http.Client
+}
+
+// New creates a new status command.
+func New(config Config) (Command, error) {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	newCommand := &command{
+		cobraCommand: nil,
+
+		httpClient: config.HTTPClient,
+	}
+
+	newCommand.cobraCommand = &cobra.Command{
+		Use:   "status",
+		Short: "Show the guest clusters reconciled by a running operator.",
+		Long:  "Show the health of a running operator and a table of the guest clusters, their LB ports and the outcome of their latest reconciliation, without requiring access to the host cluster.",
+		Run:   newCommand.Execute,
+	}
+
+	newCommand.cobraCommand.Flags().String("endpoint", DefaultEndpoint, "Address of the HTTP server of the operator.")
+
+	return newCommand, nil
+}
+
+type command struct {
+	// Internals.
+	cobraCommand *cobra.Command
+
+	// Settings.
+	httpClient *http.Client
+}
+
+// Status is the state of a running operator as queried from its HTTP API.
+type Status struct {
+	Allocations     []ledger.Allocation
+	Health          healthzservice.Responses
+	Reconciliations []history.Entry
+}
+
+func (c *command) CobraCommand() *cobra.Command {
+	return c.cobraCommand
+}
+
+func (c *command) Execute(cmd *cobra.Command, args []string) {
+	endpoint, err := cmd.Flags().GetString("endpoint")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not resolve endpoint: %#v\n", err)
+		os.Exit(1)
+	}
+
+	s, err := status(c.httpClient, endpoint)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not query status: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	err = render(os.Stdout, s, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not render status: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+// status queries the status of the operator listening on the given address.
+func status(httpClient *http.Client, address string) (Status, error) {
+	address = strings.TrimSuffix(address, "/")
+
+	var s Status
+
+	// Failing health checks are reported with an internal server error, but
+	// still carry the results of all checks.
+	err := get(httpClient, address+HealthzPath, &s.Health, http.StatusOK, http.StatusInternalServerError)
+	if err != nil {
+		return Status{}, microerror.Mask(err)
+	}
+
+	var a allocations.Response
+	err = get(httpClient, address+allocations.Path, &a, http.StatusOK)
+	if err != nil {
+		return Status{}, microerror.Mask(err)
+	}
+	s.Allocations = a.Allocations
+
+	// Operators not serving the history yet answer with not found, in which
+	// case the latest reconciliations are left out.
+	var h historyendpoint.Response
+	err = get(httpClient, address+historyendpoint.Path, &h, http.StatusOK)
+	if err != nil && !IsNotFound(err) {
+		return Status{}, microerror.Mask(err)
+	}
+	s.Reconciliations = h.Reconciliations
+
+	return s, nil
+}
+
+// get decodes the response of the given URL into v in case its status is one
+// of the given ones.
+func get(httpClient *http.Client, url string, v interface{}, statuses ...int) error {
+	res, err := httpClient.Get(url)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		return microerror.Maskf(notFoundError, "%s", url)
+	}
+
+	var expected bool
+	for _, s := range statuses {
+		if res.StatusCode == s {
+			expected = true
+		}
+	}
+	if !expected {
+		return microerror.Maskf(unexpectedStatusError, "expected %v, got %d: %s", statuses, res.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	err = json.Unmarshal(b, v)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// render writes the given status as tables of health checks and guest
+// clusters. Guest clusters are listed in case they have LB ports allocated or
+// got reconciled since the operator started. Ages are relative to now.
+func render(w io.Writer, s Status, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "HEALTH CHECK\tSTATUS\tMESSAGE")
+	for _, h := range s.Health {
+		status := "ok"
+		if h.Failed {
+			status = "failed"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", h.Name, status, h.Message)
+	}
+	fmt.Fprintln(tw)
+
+	type cluster struct {
+		ports           []string
+		reconciliations []history.Entry
+	}
+
+	clusters := map[string]*cluster{}
+	clusterOf := func(id string) *cluster {
+		c, ok := clusters[id]
+		if !ok {
+			c = &cluster{}
+			clusters[id] = c
+		}
+		return c
+	}
+
+	allocs := append([]ledger.Allocation{}, s.Allocations...)
+	sort.Slice(allocs, func(i, j int) bool { return allocs[i].LBPort < allocs[j].LBPort })
+	for _, a := range allocs {
+		port := fmt.Sprintf("%d/%s", a.LBPort, a.Protocol)
+		if a.External {
+			port = fmt.Sprintf("%d/external", a.LBPort)
+		}
+		if a.Legacy {
+			port += "/legacy"
+		}
+
+		c := clusterOf(a.ClusterID)
+		c.ports = append(c.ports, port)
+	}
+	for _, r := range s.Reconciliations {
+		c := clusterOf(r.ClusterID)
+		c.reconciliations = append(c.reconciliations, r)
+	}
+
+	var ids []string
+	for id := range clusters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Fprintln(tw, "CLUSTER\tLB PORTS\tLAST RECONCILE\tOUTCOME\tFAILURES\tERROR")
+	for _, id := range ids {
+		c := clusters[id]
+
+		ports := strings.Join(c.ports, ",")
+		if ports == "" {
+			ports = "-"
+		}
+
+		// IngressConfigs of the same guest cluster are listed one per row.
+		if len(c.reconciliations) == 0 {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", id, ports, "-", "-", "-", "")
+		}
+		for _, r := range c.reconciliations {
+			age := now.Sub(r.ReconciledAt).Round(time.Second)
+			fmt.Fprintf(tw, "%s\t%s\t%s ago\t%s\t%d\t%s\n", id, ports, age, r.Outcome, r.Failures, r.Error)
+		}
+	}
+
+	err := tw.Flush()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
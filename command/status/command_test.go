@@ -0,0 +1,140 @@
+package status
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	healthzservice "github.com/giantswarm/microendpoint/service/healthz"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+	"github.com/giantswarm/ingress-operator/service/history"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+func Test_Status_status(t *testing.T) {
+	testCases := []struct {
+		Responses       map[string]string
+		Statuses        map[string]int
+		Allocations     int
+		Health          int
+		Reconciliations int
+		ErrorMatcher    func(error) bool
+	}{
+		// Test 0 ensures the status of a healthy operator is queried.
+		{
+			Responses: map[string]string{
+				"/healthz":     `[{"name":"k8s","failed":false}]`,
+				"/allocations": `{"allocations":[{"clusterID":"al9qy","lbPort":30010,"protocol":"http"}]}`,
+				"/history":     `{"reconciliations":[{"clusterID":"al9qy","outcome":"succeeded"}]}`,
+			},
+			Statuses:        map[string]int{},
+			Allocations:     1,
+			Health:          1,
+			Reconciliations: 1,
+			ErrorMatcher:    nil,
+		},
+
+		// Test 1 ensures failing health checks and operators not serving the
+		// history are rendered anyway.
+		{
+			Responses: map[string]string{
+				"/healthz":     `[{"name":"k8s","failed":true,"message":"timeout"}]`,
+				"/allocations": `{"allocations":[]}`,
+			},
+			Statuses: map[string]int{
+				"/healthz": http.StatusInternalServerError,
+			},
+			Allocations:     0,
+			Health:          1,
+			Reconciliations: 0,
+			ErrorMatcher:    nil,
+		},
+
+		// Test 2 ensures failing endpoints result in an error.
+		{
+			Responses: map[string]string{
+				"/healthz":     `[]`,
+				"/allocations": `{"code":"INTERNAL_ERROR"}`,
+			},
+			Statuses: map[string]int{
+				"/allocations": http.StatusInternalServerError,
+			},
+			ErrorMatcher: IsUnexpectedStatus,
+		},
+	}
+
+	for i, tc := range testCases {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, ok := tc.Responses[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if status, ok := tc.Statuses[r.URL.Path]; ok {
+				w.WriteHeader(status)
+			}
+			w.Write([]byte(b))
+		}))
+
+		result, err := status(s.Client(), s.URL+"/")
+		s.Close()
+
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if len(result.Allocations) != tc.Allocations {
+			t.Fatal("test", i, "expected", tc.Allocations, "got", len(result.Allocations))
+		}
+		if len(result.Health) != tc.Health {
+			t.Fatal("test", i, "expected", tc.Health, "got", len(result.Health))
+		}
+		if len(result.Reconciliations) != tc.Reconciliations {
+			t.Fatal("test", i, "expected", tc.Reconciliations, "got", len(result.Reconciliations))
+		}
+	}
+}
+
+func Test_Status_render(t *testing.T) {
+	now := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	s := Status{
+		Allocations: []ledger.Allocation{
+			{ClusterID: "al9qy", LBPort: 30011, Protocol: "https"},
+			{ClusterID: "al9qy", LBPort: 30010, Protocol: "http"},
+			{ClusterID: "x8c2p", LBPort: 30020, External: true},
+		},
+		Health: healthzservice.Responses{
+			{Name: "breaker", Failed: false},
+			{Name: "k8s", Failed: true, Message: "timeout"},
+		},
+		Reconciliations: []history.Entry{
+			{ClusterID: "al9qy", Outcome: outcome.Succeeded, ReconciledAt: now.Add(-90 * time.Second)},
+			{ClusterID: "b4d1e", Outcome: outcome.Failed, Failures: 3, Error: "test error", ReconciledAt: now.Add(-time.Minute)},
+		},
+	}
+
+	expected := `HEALTH CHECK  STATUS  MESSAGE
+breaker       ok      
+k8s           failed  timeout
+
+CLUSTER  LB PORTS                LAST RECONCILE  OUTCOME    FAILURES  ERROR
+al9qy    30010/http,30011/https  1m30s ago       succeeded  0         
+b4d1e    -                       1m0s ago        failed     3         test error
+x8c2p    30020/external          -               -          -         
+`
+
+	var b bytes.Buffer
+	err := render(&b, s, now)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if b.String() != expected {
+		t.Fatal("expected", expected, "got", b.String())
+	}
+}
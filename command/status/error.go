@@ -0,0 +1,23 @@
+package status
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var unexpectedStatusError = &microerror.Error{
+	Kind: "unexpectedStatusError",
+}
+
+// IsUnexpectedStatus asserts unexpectedStatusError.
+func IsUnexpectedStatus(err error) bool {
+	return microerror.Cause(err) == unexpectedStatusError
+}
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
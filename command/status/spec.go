@@ -0,0 +1,13 @@
+package status
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Command represents the status command of the operator.
+type Command interface {
+	// CobraCommand returns the actual cobra command for the status command.
+	CobraCommand() *cobra.Command
+	// Execute represents the cobra run method.
+	Execute(cmd *cobra.Command, args []string)
+}
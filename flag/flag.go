@@ -3,10 +3,12 @@ package flag
 import (
 	"github.com/giantswarm/microkit/flag"
 
+	"github.com/giantswarm/ingress-operator/flag/log"
 	"github.com/giantswarm/ingress-operator/flag/service"
 )
 
 type Flag struct {
+	Log     log.Log
 	Service service.Service
 }
 
@@ -0,0 +1,5 @@
+package log
+
+type Log struct {
+	Format string
+}
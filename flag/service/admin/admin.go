@@ -0,0 +1,8 @@
+package admin
+
+type Admin struct {
+	ClientCAFile     string
+	ClientCertHeader string
+	ListenAddress    string
+	TokenFile        string
+}
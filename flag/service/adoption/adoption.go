@@ -0,0 +1,14 @@
+package adoption
+
+import (
+	"github.com/giantswarm/ingress-operator/flag/service/adoption/ingresscontroller"
+)
+
+type Adoption struct {
+	CreateIngressConfigs string
+	Enabled              string
+	GuestClusterService  string
+	IngressController    ingresscontroller.IngressController
+	Interval             string
+	Namespace            string
+}
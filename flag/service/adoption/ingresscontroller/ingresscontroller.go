@@ -0,0 +1,7 @@
+package ingresscontroller
+
+type IngressController struct {
+	ConfigMap string
+	Namespace string
+	Service   string
+}
@@ -0,0 +1,13 @@
+package bridge
+
+import (
+	"github.com/giantswarm/ingress-operator/flag/service/bridge/guestcluster"
+	"github.com/giantswarm/ingress-operator/flag/service/bridge/ingresscontroller"
+)
+
+type Bridge struct {
+	Enabled           string
+	GuestCluster      guestcluster.GuestCluster
+	IngressController ingresscontroller.IngressController
+	Namespace         string
+}
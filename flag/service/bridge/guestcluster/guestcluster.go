@@ -0,0 +1,5 @@
+package guestcluster
+
+type GuestCluster struct {
+	Service string
+}
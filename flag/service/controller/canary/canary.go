@@ -0,0 +1,8 @@
+package canary
+
+type Canary struct {
+	DedicatedIngressController string
+	Enabled                    string
+	ResourceOrder              string
+	SelfTest                   string
+}
@@ -0,0 +1,6 @@
+package certificates
+
+type Certificates struct {
+	Components string
+	Namespace  string
+}
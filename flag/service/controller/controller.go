@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"github.com/giantswarm/ingress-operator/flag/service/controller/canary"
+	"github.com/giantswarm/ingress-operator/flag/service/controller/certificates"
+	"github.com/giantswarm/ingress-operator/flag/service/controller/lease"
+	"github.com/giantswarm/ingress-operator/flag/service/controller/quarantine"
+	"github.com/giantswarm/ingress-operator/flag/service/controller/selftest"
+)
+
+type Controller struct {
+	Canary               canary.Canary
+	Certificates         certificates.Certificates
+	Concurrency          string
+	DeletionSLA          string
+	DeprecationInterval  string
+	IngressControllers   string
+	Lease                lease.Lease
+	LegacyInterval       string
+	ListLimit            string
+	Namespaces           string
+	Quarantine           quarantine.Quarantine
+	ResourceOrder        string
+	ResultCacheTTL       string
+	ResyncRate           string
+	SelfTest             selftest.SelfTest
+	StartupReportTimeout string
+}
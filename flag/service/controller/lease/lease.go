@@ -0,0 +1,7 @@
+package lease
+
+type Lease struct {
+	Duration string
+	Enabled  string
+	Timeout  string
+}
@@ -0,0 +1,6 @@
+package quarantine
+
+type Quarantine struct {
+	RetryInterval string
+	Threshold     string
+}
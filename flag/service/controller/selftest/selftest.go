@@ -0,0 +1,6 @@
+package selftest
+
+type SelfTest struct {
+	Enabled string
+	Timeout string
+}
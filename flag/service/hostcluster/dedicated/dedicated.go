@@ -0,0 +1,8 @@
+package dedicated
+
+type Dedicated struct {
+	Enabled        string
+	Image          string
+	Namespace      string
+	ServiceAccount string
+}
@@ -0,0 +1,23 @@
+package hostcluster
+
+import (
+	"github.com/giantswarm/ingress-operator/flag/service/hostcluster/dedicated"
+	"github.com/giantswarm/ingress-operator/flag/service/hostcluster/ingresscontroller"
+	"github.com/giantswarm/ingress-operator/flag/service/hostcluster/ledger"
+	"github.com/giantswarm/ingress-operator/flag/service/hostcluster/propagation"
+)
+
+type HostCluster struct {
+	AvailablePorts         string
+	Dedicated              dedicated.Dedicated
+	IngressController      ingresscontroller.IngressController
+	Ledger                 ledger.Ledger
+	MaxServicePorts        string
+	PortCoolingOff         string
+	PortRanges             string
+	PreservedServiceFields string
+	Propagation            propagation.Propagation
+	ReservedPorts          string
+	StreamSnippets         string
+	UtilizationThresholds  string
+}
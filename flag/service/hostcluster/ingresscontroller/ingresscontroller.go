@@ -0,0 +1,7 @@
+package ingresscontroller
+
+type IngressController struct {
+	ConfigMapSelector string
+	ProxyProtocol     string
+	ServiceSelector   string
+}
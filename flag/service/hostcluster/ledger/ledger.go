@@ -0,0 +1,7 @@
+package ledger
+
+type Ledger struct {
+	AllowDowngrade string
+	Name           string
+	Namespace      string
+}
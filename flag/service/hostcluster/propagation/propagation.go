@@ -0,0 +1,6 @@
+package propagation
+
+type Propagation struct {
+	Annotations string
+	Labels      string
+}
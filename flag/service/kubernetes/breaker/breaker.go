@@ -0,0 +1,8 @@
+package breaker
+
+type Breaker struct {
+	CoolDown    string
+	MinRequests string
+	Threshold   string
+	Window      string
+}
@@ -0,0 +1,6 @@
+package healthz
+
+type Healthz struct {
+	Interval string
+	Timeout  string
+}
@@ -0,0 +1,6 @@
+package kubeconfig
+
+type KubeConfig struct {
+	Context string
+	Path    string
+}
@@ -1,11 +1,21 @@
 package kubernetes
 
 import (
+	"github.com/giantswarm/ingress-operator/flag/service/kubernetes/breaker"
+	"github.com/giantswarm/ingress-operator/flag/service/kubernetes/healthz"
+	"github.com/giantswarm/ingress-operator/flag/service/kubernetes/kubeconfig"
+	"github.com/giantswarm/ingress-operator/flag/service/kubernetes/timeout"
 	"github.com/giantswarm/ingress-operator/flag/service/kubernetes/tls"
 )
 
 type Kubernetes struct {
-	Address   string
-	InCluster string
-	TLS       tls.TLS
+	Address    string
+	Breaker    breaker.Breaker
+	Healthz    healthz.Healthz
+	InCluster  string
+	KeepAlive  string
+	KubeConfig kubeconfig.KubeConfig
+	Timeout    timeout.Timeout
+	TLS        tls.TLS
+	TokenFile  string
 }
@@ -0,0 +1,7 @@
+package timeout
+
+type Timeout struct {
+	Dial         string
+	Request      string
+	TLSHandshake string
+}
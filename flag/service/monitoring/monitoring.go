@@ -0,0 +1,8 @@
+package monitoring
+
+type Monitoring struct {
+	Enabled   string
+	Interval  string
+	Labels    string
+	Namespace string
+}
@@ -0,0 +1,5 @@
+package resources
+
+type Resources struct {
+	Disable string
+}
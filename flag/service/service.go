@@ -1,9 +1,23 @@
 package service
 
 import (
+	"github.com/giantswarm/ingress-operator/flag/service/admin"
+	"github.com/giantswarm/ingress-operator/flag/service/adoption"
+	"github.com/giantswarm/ingress-operator/flag/service/bridge"
+	"github.com/giantswarm/ingress-operator/flag/service/controller"
+	"github.com/giantswarm/ingress-operator/flag/service/hostcluster"
 	"github.com/giantswarm/ingress-operator/flag/service/kubernetes"
+	"github.com/giantswarm/ingress-operator/flag/service/monitoring"
+	"github.com/giantswarm/ingress-operator/flag/service/resources"
 )
 
 type Service struct {
-	Kubernetes kubernetes.Kubernetes
+	Admin       admin.Admin
+	Adoption    adoption.Adoption
+	Bridge      bridge.Bridge
+	Controller  controller.Controller
+	HostCluster hostcluster.HostCluster
+	Kubernetes  kubernetes.Kubernetes
+	Monitoring  monitoring.Monitoring
+	Resources   resources.Resources
 }
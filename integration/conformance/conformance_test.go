@@ -0,0 +1,289 @@
+//go:build conformance
+// +build conformance
+
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/configmap"
+)
+
+const (
+	// backendText is the response of the guest cluster backend, which proves
+	// traffic got routed through the ingress controller.
+	backendText = "al9qy"
+	// k3sImage is the image of the Kubernetes distribution the ingress
+	// controllers run in.
+	k3sImage = "rancher/k3s:v0.5.0"
+	// timeout is the maximum time waiting for the cluster, the ingress
+	// controller or the routes to become ready.
+	timeout = 5 * time.Minute
+)
+
+// release is a supported release of ingress-nginx together with the features
+// of generated config map data it is asserted to handle.
+type release struct {
+	Version string
+	Image   string
+
+	// NamedPorts is whether the release resolves ports referenced by name.
+	NamedPorts bool
+	// ProxyProtocol is whether the release hands over connections with the
+	// PROXY protocol for entries having the PROXY protocol suffix.
+	ProxyProtocol bool
+}
+
+var releases = []release{
+	{
+		Version:       "0.24.1",
+		Image:         "quay.io/kubernetes-ingress-controller/nginx-ingress-controller:0.24.1",
+		NamedPorts:    true,
+		ProxyProtocol: true,
+	},
+	{
+		Version:       "0.25.1",
+		Image:         "quay.io/kubernetes-ingress-controller/nginx-ingress-controller:0.25.1",
+		NamedPorts:    true,
+		ProxyProtocol: true,
+	},
+	{
+		Version:       "0.26.1",
+		Image:         "quay.io/kubernetes-ingress-controller/nginx-ingress-controller:0.26.1",
+		NamedPorts:    true,
+		ProxyProtocol: true,
+	},
+}
+
+func Test_Conformance(t *testing.T) {
+	for _, r := range releases {
+		r := r
+		t.Run(r.Version, func(t *testing.T) {
+			c := newCluster(t)
+			defer c.Delete()
+
+			c.Apply(backendManifests()...)
+			c.Apply(ingressControllerManifests(r)...)
+
+			customObject := newIngressConfig(r)
+			cc := &controllercontext.Context{}
+			if r.NamedPorts {
+				cc.PortNames = map[int]string{31001: "named"}
+			}
+
+			data := configmap.DesiredData(cc, customObject)
+			c.Apply(&corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.IngressControllerConfigMap(customObject),
+					Namespace: key.IngressControllerNamespace(customObject),
+				},
+				Data: data,
+			})
+
+			c.WaitFor("the ingress controller pod", func() bool {
+				return c.PodIP("kube-system", "app=ingress-controller") != ""
+			})
+			podIP := c.PodIP("kube-system", "app=ingress-controller")
+
+			// The LB ports of all generated entries must be configured, which
+			// proves the entries got accepted.
+			for k := range data {
+				k := k
+				c.WaitFor(fmt.Sprintf("LB port %s to be configured", k), func() bool {
+					conf, err := c.Kubectl("-n", "kube-system", "exec", "deploy/ingress-controller", "--", "cat", "/etc/nginx/nginx.conf")
+					return err == nil && strings.Contains(conf, fmt.Sprintf("listen %s", k))
+				})
+			}
+
+			// The LB ports not expecting the PROXY protocol must route to the
+			// backend.
+			for _, p := range []int{31000, 31001} {
+				if p == 31001 && !r.NamedPorts {
+					continue
+				}
+
+				p := p
+				c.WaitFor(fmt.Sprintf("LB port %d to route to the backend", p), func() bool {
+					out, err := c.Exec("wget", "-q", "-O", "-", fmt.Sprintf("http://%s:%d/", podIP, p))
+					return err == nil && strings.TrimSpace(out) == backendText
+				})
+			}
+
+			// The LB port passing TLS through must hand over connections with
+			// the PROXY protocol.
+			if r.ProxyProtocol {
+				conf, err := c.Kubectl("-n", "kube-system", "exec", "deploy/ingress-controller", "--", "cat", "/etc/nginx/nginx.conf")
+				if err != nil {
+					t.Fatal("expected", nil, "got", err)
+				}
+				if !strings.Contains(conf, "proxy_protocol on;") {
+					t.Fatal("expected", "proxy_protocol on;", "got", conf)
+				}
+			}
+		})
+	}
+}
+
+// newIngressConfig returns the IngressConfig the config map data is generated
+// from. LB port 31000 references the backend by number, LB port 31001 by name
+// and LB port 31002 passes TLS through.
+func newIngressConfig(r release) v1alpha1.IngressConfig {
+	annotations := map[string]string{}
+	if r.NamedPorts {
+		annotations[key.NamedPortsAnnotation] = "31001"
+	}
+	if r.ProxyProtocol {
+		annotations[key.TLSPassthroughAnnotation] = "31002"
+	}
+
+	protocolPorts := []v1alpha1.IngressConfigSpecProtocolPort{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{IngressPort: 30011, LBPort: 31001, Protocol: "tcp"},
+	}
+	if r.ProxyProtocol {
+		protocolPorts = append(protocolPorts, v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30012, LBPort: 31002, Protocol: "https"})
+	}
+
+	customObject := v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: annotations,
+			Name:        "al9qy",
+			Namespace:   "default",
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        "al9qy",
+				Namespace: "al9qy",
+				Service:   "worker",
+			},
+			HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+				IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+					ConfigMap: "ingress-controller-tcp-services",
+					Namespace: "kube-system",
+					Service:   "ingress-controller",
+				},
+			},
+			ProtocolPorts: protocolPorts,
+		},
+	}
+
+	return customObject
+}
+
+// cluster is a throwaway k3s cluster running in a docker container.
+type cluster struct {
+	t         *testing.T
+	container string
+}
+
+func newCluster(t *testing.T) *cluster {
+	t.Helper()
+
+	out, err := run(nil, "docker", "run", "-d", "--privileged", k3sImage, "server", "--no-deploy", "traefik")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	c := &cluster{
+		t:         t,
+		container: strings.TrimSpace(out),
+	}
+
+	c.WaitFor("the cluster", func() bool {
+		out, err := c.Kubectl("get", "serviceaccount", "default")
+		return err == nil && out != ""
+	})
+
+	return c
+}
+
+// Apply creates or updates the given objects.
+func (c *cluster) Apply(objects ...interface{}) {
+	c.t.Helper()
+
+	for _, o := range objects {
+		b, err := json.Marshal(o)
+		if err != nil {
+			c.t.Fatal("expected", nil, "got", err)
+		}
+
+		_, err = run(b, "docker", "exec", "-i", c.container, "kubectl", "apply", "-f", "-")
+		if err != nil {
+			c.t.Fatal("expected", nil, "got", err)
+		}
+	}
+}
+
+// Delete removes the container of the cluster.
+func (c *cluster) Delete() {
+	_, err := run(nil, "docker", "rm", "-f", c.container)
+	if err != nil {
+		c.t.Log("failed removing container", c.container, err)
+	}
+}
+
+// Exec executes the given command in the container of the cluster, which is
+// attached to the pod network.
+func (c *cluster) Exec(args ...string) (string, error) {
+	return run(nil, "docker", append([]string{"exec", c.container}, args...)...)
+}
+
+// Kubectl executes kubectl with the given arguments in the cluster.
+func (c *cluster) Kubectl(args ...string) (string, error) {
+	return c.Exec(append([]string{"kubectl"}, args...)...)
+}
+
+// PodIP returns the IP of the first running pod matching the given selector.
+func (c *cluster) PodIP(namespace, selector string) string {
+	out, err := c.Kubectl("-n", namespace, "get", "pods", "-l", selector, "--field-selector", "status.phase=Running", "-o", "jsonpath={.items[0].status.podIP}")
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(out)
+}
+
+// WaitFor waits for the given condition and fails the test once the timeout
+// passed.
+func (c *cluster) WaitFor(description string, condition func() bool) {
+	c.t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			c.t.Fatal("timed out waiting for", description)
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+func run(stdin []byte, name string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %s: %s", name, strings.Join(args, " "), err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
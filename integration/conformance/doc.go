@@ -0,0 +1,13 @@
+// Package conformance implements a test suite asserting that the config map
+// data generated by the operator is accepted and routed by every supported
+// release of ingress-nginx. Releases changed how tcp-services entries are
+// handled, e.g. the PROXY protocol suffix and ports referenced by name, so
+// each release is exercised on its own.
+//
+// The suite only needs docker. Every release gets a throwaway k3s container
+// running the ingress controller, a backend in a guest cluster namespace and
+// the tcp-services config map rendered by the config map resource. It is
+// excluded from regular test runs by the conformance build tag:
+//
+//	go test -v -tags conformance ./integration/conformance
+package conformance
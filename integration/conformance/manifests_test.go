@@ -0,0 +1,105 @@
+//go:build conformance
+// +build conformance
+
+package conformance
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// backendManifests returns the objects of the guest cluster backend. Its
+// service exposes the same backend port by number and by name, so that both
+// kinds of config map data values can be routed.
+func backendManifests() []interface{} {
+	labels := map[string]string{"app": "worker"}
+
+	return []interface{}{
+		&corev1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: "al9qy"},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "al9qy"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "worker",
+								Image: "hashicorp/http-echo:0.2.3",
+								Args:  []string{"-listen=:5678", "-text=" + backendText},
+							},
+						},
+					},
+				},
+			},
+		},
+		&corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "al9qy"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: 30010, TargetPort: intstr.FromInt(5678)},
+					{Name: "named", Port: 30011, TargetPort: intstr.FromInt(5678)},
+					{Name: "https", Port: 30012, TargetPort: intstr.FromInt(5678)},
+				},
+				Selector: labels,
+			},
+		},
+	}
+}
+
+// ingressControllerManifests returns the objects of the host cluster ingress
+// controller of the given release, reading the tcp-services config map the
+// operator writes.
+func ingressControllerManifests(r release) []interface{} {
+	labels := map[string]string{"app": "ingress-controller"}
+
+	return []interface{}{
+		&rbacv1.ClusterRoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress-controller"},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "ClusterRole",
+				Name:     "cluster-admin",
+			},
+			Subjects: []rbacv1.Subject{
+				{Kind: "ServiceAccount", Name: "default", Namespace: "kube-system"},
+			},
+		},
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress-controller", Namespace: "kube-system"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "ingress-controller",
+								Image: r.Image,
+								Args: []string{
+									"/nginx-ingress-controller",
+									"--tcp-services-configmap=kube-system/ingress-controller-tcp-services",
+								},
+								Env: []corev1.EnvVar{
+									{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+									{Name: "POD_NAMESPACE", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"}}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -1,31 +1,48 @@
 package main
 
 import (
-	"github.com/giantswarm/ingress-operator/flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/giantswarm/microkit/command"
+	microdaemonflag "github.com/giantswarm/microkit/command/daemon/flag"
 	microserver "github.com/giantswarm/microkit/server"
-	"github.com/giantswarm/micrologger"
 	"github.com/spf13/viper"
 
+	configcommand "github.com/giantswarm/ingress-operator/command/config"
+	rendermanifestscommand "github.com/giantswarm/ingress-operator/command/rendermanifests"
+	resynccommand "github.com/giantswarm/ingress-operator/command/resync"
+	statuscommand "github.com/giantswarm/ingress-operator/command/status"
+	"github.com/giantswarm/ingress-operator/flag"
+	"github.com/giantswarm/ingress-operator/pkg/logger"
+	"github.com/giantswarm/ingress-operator/pkg/project"
 	"github.com/giantswarm/ingress-operator/server"
 	"github.com/giantswarm/ingress-operator/service"
+	"github.com/giantswarm/ingress-operator/service/configcheck"
 )
 
 var (
-	description string     = "The ingress-operator connects host cluster ingress controllers with guest cluster ingress controllers on a Giant Swarm Kubernetes host cluster."
-	f           *flag.Flag = flag.New()
-	gitCommit   string     = "n/a"
-	name        string     = "ingress-operator"
-	source      string     = "https://github.com/giantswarm/ingress-operator"
+	f *flag.Flag = flag.New()
+	// gitCommit is injected at build time by architect and handed to
+	// pkg/project, which reports it.
+	gitCommit string = "n/a"
+	// daemonFlag holds the flags the microkit daemon command registers itself.
+	daemonFlag = microdaemonflag.New()
 )
 
 func main() {
+	project.SetGitSHA(gitCommit)
+
 	var err error
 
-	// Create a new logger which is used by all packages.
-	var newLogger micrologger.Logger
+	// Create a new logger which is used by all packages. Its format is set as
+	// soon as the command line flags are parsed.
+	var newLogger *logger.Logger
 	{
-		newLogger, err = micrologger.New(micrologger.Config{})
+		newLogger, err = logger.New(logger.DefaultConfig())
 		if err != nil {
 			panic(err)
 		}
@@ -34,6 +51,20 @@ func main() {
 	// We define a server factory to create the custom server once all command
 	// line flags are parsed and all microservice configuration is storted out.
 	newServerFactory := func(v *viper.Viper) microserver.Server {
+		// All flags are validated before anything else, so that every invalid
+		// flag is reported at once instead of the first one failing somewhere
+		// inside the creation of the service.
+		problems := configcheck.Check(f, v)
+		if len(problems) > 0 {
+			fmt.Fprint(os.Stderr, configcheck.Render(problems))
+			os.Exit(1)
+		}
+
+		err = newLogger.SetFormat(v.GetString(f.Log.Format))
+		if err != nil {
+			panic(err)
+		}
+
 		// Create a new custom service which implements business logic.
 		var newService *service.Service
 		{
@@ -43,11 +74,6 @@ func main() {
 			serviceConfig.Logger = newLogger
 			serviceConfig.Viper = v
 
-			serviceConfig.Description = description
-			serviceConfig.GitCommit = gitCommit
-			serviceConfig.Name = name
-			serviceConfig.Source = source
-
 			newService, err = service.New(serviceConfig)
 			if err != nil {
 				panic(err)
@@ -63,15 +89,37 @@ func main() {
 				Service: newService,
 				Viper:   v,
 
-				ProjectName: name,
+				AdminClientCAFile:     v.GetString(f.Service.Admin.ClientCAFile),
+				AdminClientCertHeader: v.GetString(f.Service.Admin.ClientCertHeader),
+				AdminListenAddress:    v.GetString(f.Service.Admin.ListenAddress),
+				AdminTokenFile:        v.GetString(f.Service.Admin.TokenFile),
+				ProjectName:           project.Name(),
+				TLSCrtFile:            v.GetString(daemonFlag.Server.TLS.CrtFile),
+				TLSKeyFile:            v.GetString(daemonFlag.Server.TLS.KeyFile),
 			}
 
 			newServer, err = server.New(c)
 			if err != nil {
 				panic(err)
 			}
+			// The microkit daemon only boots its own server created from the
+			// configuration of our custom server, which therefore has to boot
+			// the admin listener itself.
+			newServer.Boot()
 		}
 
+		// The microkit daemon only shuts down its HTTP server on termination
+		// signals. Our custom server is shut down alongside to stop the service
+		// gracefully.
+		go func() {
+			listener := make(chan os.Signal, 2)
+			signal.Notify(listener, syscall.SIGINT, syscall.SIGTERM)
+
+			<-listener
+
+			newServer.Shutdown()
+		}()
+
 		return newServer
 	}
 
@@ -82,10 +130,10 @@ func main() {
 			Logger:        newLogger,
 			ServerFactory: newServerFactory,
 
-			Description:    description,
-			GitCommit:      gitCommit,
-			Name:           name,
-			Source:         source,
+			Description:    project.Description(),
+			GitCommit:      project.GitSHA(),
+			Name:           project.Name(),
+			Source:         project.Source(),
 			VersionBundles: service.NewVersionBundles(),
 		}
 
@@ -97,11 +145,140 @@ func main() {
 
 	daemonCommand := newCommand.DaemonCommand().CobraCommand()
 
+	daemonCommand.PersistentFlags().String(f.Log.Format, logger.FormatJSON, "Format of the log output, either json or logfmt.")
+	daemonCommand.PersistentFlags().String(f.Service.Admin.ClientCAFile, "", "File path of the PEM encoded CAs client certificates authenticating requests to administrative endpoints have to be issued by. The organizations of client certificates are their roles, either admin or operator. Client certificates are not accepted when empty.")
+	daemonCommand.PersistentFlags().String(f.Service.Admin.ClientCertHeader, "", "Request header a TLS terminating proxy forwards URL encoded PEM client certificates in, e.g. X-Client-Cert. Only set it in case the operator is reachable through the proxy only. Client certificates are only taken from the TLS connection when empty.")
+	daemonCommand.PersistentFlags().String(f.Service.Admin.ListenAddress, "", "Host and port of the TLS listener serving all endpoints with client certificates being requested, e.g. :8443, using the server TLS certificate and key files. Client certificates are only taken from TLS connections to it, since the listener of the server does not terminate TLS. Disabled when empty.")
+	daemonCommand.PersistentFlags().String(f.Service.Admin.TokenFile, "", "File path of the tokens administrative endpoints accept as bearer token, one per line, optionally followed by the comma separated roles admin or operator. Tokens without roles are admin tokens. Administrative endpoints are disabled when neither tokens nor a client CA are configured.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Adoption.CreateIngressConfigs, false, "Whether the adoption controller creates and updates IngressConfigs for the guest clusters of unowned config map entries. Unowned entries not covered by any IngressConfig are only logged otherwise.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Adoption.Enabled, false, "Whether to watch the config map of the ingress controller for entries of existing guest cluster namespaces which are not recorded in the ledger, e.g. configured manually before the operator existed, and adopt them.")
+	daemonCommand.PersistentFlags().String(f.Service.Adoption.GuestClusterService, "worker", "Guest cluster service config map entries have to forward to in order to be adopted, as in <guest cluster namespace>/worker:<port>.")
+	daemonCommand.PersistentFlags().String(f.Service.Adoption.IngressController.ConfigMap, "ingress-controller", "Config map of the host cluster ingress controller watched for unowned entries.")
+	daemonCommand.PersistentFlags().String(f.Service.Adoption.IngressController.Namespace, "kube-system", "Namespace of the host cluster ingress controller watched for unowned entries.")
+	daemonCommand.PersistentFlags().String(f.Service.Adoption.IngressController.Service, "ingress-controller", "Service of the host cluster ingress controller referenced by IngressConfigs created for unowned entries.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Adoption.Interval, 5*time.Minute, "Interval in which the config map of the ingress controller is checked for unowned entries.")
+	daemonCommand.PersistentFlags().String(f.Service.Adoption.Namespace, "default", "Namespace IngressConfigs created for unowned entries are created in. They are created in the namespace of their guest cluster when empty.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Bridge.Enabled, false, "Whether to create IngressConfigs automatically from KVMConfigs instead of relying on cluster-operator.")
+	daemonCommand.PersistentFlags().String(f.Service.Bridge.GuestCluster.Service, "worker", "Guest cluster service ingress traffic is forwarded to by IngressConfigs created from KVMConfigs.")
+	daemonCommand.PersistentFlags().String(f.Service.Bridge.IngressController.ConfigMap, "ingress-controller", "Config map of the host cluster ingress controller referenced by IngressConfigs created from KVMConfigs.")
+	daemonCommand.PersistentFlags().String(f.Service.Bridge.IngressController.Namespace, "kube-system", "Namespace of the host cluster ingress controller referenced by IngressConfigs created from KVMConfigs.")
+	daemonCommand.PersistentFlags().String(f.Service.Bridge.IngressController.Service, "ingress-controller", "Service of the host cluster ingress controller referenced by IngressConfigs created from KVMConfigs.")
+	daemonCommand.PersistentFlags().String(f.Service.Bridge.Namespace, "default", "Namespace IngressConfigs created from KVMConfigs are created in. They are created in the namespace of their guest cluster when empty.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.Canary.DedicatedIngressController, false, "Whether to provision a dedicated ingress controller per guest cluster for canary IngressConfigs. Dedicated ingress controllers are provisioned for canary IngressConfigs as well in case they are enabled for all IngressConfigs. Guest clusters must not leave the canary while it differs from the stable setting, since their ports are not moved between ingress controllers.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.Canary.Enabled, false, "Whether to reconcile IngressConfigs annotated with ingress-operator.giantswarm.io/canary=true with the canary settings, so that behaviour changes can be rolled out to a few guest clusters before the whole fleet. The annotation is ignored when disabled.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Canary.ResourceOrder, "", "Order of the service and config map resources for canary IngressConfigs. The stable resource order is used when empty.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.Canary.SelfTest, false, "Whether to run the self-test for canary IngressConfigs. The self-test runs for canary IngressConfigs as well in case it is enabled for all IngressConfigs.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Controller.Certificates.Components, []string{}, "Cluster components whose certificates https LB ports wait for before being added, e.g. ingress. cert-operator issues them into Secrets named <cluster ID>-<component>. Nothing is waited for when empty.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.Certificates.Namespace, "default", "Namespace cert-operator issues the certificate Secrets of guest clusters into.")
+	daemonCommand.PersistentFlags().Int(f.Service.Controller.Concurrency, 1, "Number of workers reconciling IngressConfigs in parallel. Each IngressConfig is always reconciled by the same worker.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.DeletionSLA, 2*time.Hour, "Time deletions of guest clusters may be delayed by pods remaining in their namespace before a warning event naming the pods is raised on the IngressConfig and the deletion is exposed as blocked metric. Zero disables the alert.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.DeprecationInterval, time.Hour, "Interval in which IngressConfigs relying on deprecated behaviour, e.g. lacking a version bundle version, are reported as warning and metric.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Controller.IngressControllers, []string{}, "Host cluster ingress controllers this operator instance reconciles IngressConfigs of, as <namespace>/<service>, e.g. kube-system/ingress-controller. IngressConfigs referencing other ingress controllers are left to other operator instances. All IngressConfigs are reconciled when empty.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.Lease.Duration, 15*time.Second, "Time the Lease of a shared ingress controller stays valid without being renewed, e.g. after the replica holding it crashed.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.Lease.Enabled, false, "Whether to hold a Lease per shared ingress controller while writing its config map and service, so that the writes are serialized across all replicas of the operator. Requires coordination.k8s.io/v1.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.Lease.Timeout, 30*time.Second, "Maximum time waited for the Lease of a shared ingress controller held by another replica before the reconciliation fails and is retried.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.LegacyInterval, time.Hour, "Interval in which the remaining legacy ingresstpr objects are listed, so that they are exposed as metric and by the allocations endpoint, flagged as legacy, until they got migrated to IngressConfigs. They are never reconciled. Zero disables listing them.")
+	daemonCommand.PersistentFlags().Int64(f.Service.Controller.ListLimit, 500, "Maximum number of IngressConfigs listed per page by the IngressConfig cache all workers are served from. Smaller pages bound the memory used by the API server and the operator while listing large fleets. Zero lists all IngressConfigs in a single page.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Controller.Namespaces, []string{}, "Namespaces IngressConfigs are honored in, for shared host clusters with a namespace per tenant. IngressConfigs of other namespaces are ignored and IngressConfigs referencing guest cluster services outside of their own namespace are rejected. All namespaces are honored when empty.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.Quarantine.RetryInterval, 30*time.Minute, "Interval in which quarantined IngressConfigs are reconciled.")
+	daemonCommand.PersistentFlags().Int(f.Service.Controller.Quarantine.Threshold, 10, "Number of consecutive failed reconciliations after which an IngressConfig is quarantined. Zero disables the quarantine.")
+	daemonCommand.PersistentFlags().String(f.Service.Controller.ResourceOrder, "service-first", "Order of the service and config map resources. Either service-first, creating service ports before config map entries and deleting them after, or configmap-first, rolling back config map updates whose service update fails.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.ResultCacheTTL, 30*time.Minute, "Time successful reconciliations are cached. IngressConfigs whose spec, annotations and labels and whose affected config maps and services did not change since are not reconciled again until it passed. Zero disables the cache.")
+	daemonCommand.PersistentFlags().Int(f.Service.Controller.ResyncRate, 5, "Maximum number of IngressConfigs requeued per second when a resync of all IngressConfigs is requested.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Controller.SelfTest.Enabled, false, "Whether to connect to the LB ports of IngressConfigs through the host cluster ingress controller once they got applied. The result is recorded in the IngressReachable condition and raised as an event.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.SelfTest.Timeout, 2*time.Second, "Maximum time connecting to an LB port takes during the self-test. It is also the time a connection has to stay open to pass.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Controller.StartupReportTimeout, 10*time.Minute, "Maximum time waited for the first reconciliation of all IngressConfigs after startup before the startup report is emitted anyway.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.HostCluster.AvailablePorts, []string{}, "LB ports available for guest clusters. They define the capacity of the port pool the utilization is reported for.")
+	daemonCommand.PersistentFlags().Bool(f.Service.HostCluster.Dedicated.Enabled, false, "Whether to provision a dedicated ingress controller per guest cluster instead of using the shared one referenced by the IngressConfig.")
+	daemonCommand.PersistentFlags().String(f.Service.HostCluster.Dedicated.Image, "quay.io/giantswarm/nginx-ingress-controller:0.12.0", "Container image used for dedicated ingress controllers.")
+	daemonCommand.PersistentFlags().String(f.Service.HostCluster.Dedicated.Namespace, "kube-system", "Host cluster namespace dedicated ingress controllers are provisioned in.")
+	daemonCommand.PersistentFlags().String(f.Service.HostCluster.Dedicated.ServiceAccount, "nginx-ingress-controller", "Service account used by the pods of dedicated ingress controllers.")
+	daemonCommand.PersistentFlags().String(f.Service.HostCluster.IngressController.ConfigMapSelector, "", "Label selector discovering the config map of shared ingress controllers in their namespace instead of using the name referenced by the IngressConfig, e.g. app=nginx-ingress-controller. Requires the service selector.")
+	daemonCommand.PersistentFlags().Bool(f.Service.HostCluster.IngressController.ProxyProtocol, false, "Whether shared ingress controllers receive the PROXY protocol and nginx takes the client IP of connections to LB ports from it. Source ranges of LB ports are refused otherwise, unless the service of the ingress controller has the Local external traffic policy.")
+	daemonCommand.PersistentFlags().String(f.Service.HostCluster.IngressController.ServiceSelector, "", "Label selector discovering the service of shared ingress controllers in their namespace instead of using the name referenced by the IngressConfig, e.g. app=nginx-ingress-controller. Requires the config map selector.")
+	daemonCommand.PersistentFlags().Bool(f.Service.HostCluster.Ledger.AllowDowngrade, false, "Whether to overwrite the ledger in case it got written by a newer operator version, e.g. after rolling back an upgrade. Older versions refuse to overwrite it otherwise.")
+	daemonCommand.PersistentFlags().String(f.Service.HostCluster.Ledger.Name, "ingress-operator-allocations", "Name of the config map recording which LB ports are allocated to which guest clusters.")
+	daemonCommand.PersistentFlags().String(f.Service.HostCluster.Ledger.Namespace, "kube-system", "Host cluster namespace of the config map recording the LB port allocations.")
+	daemonCommand.PersistentFlags().Int(f.Service.HostCluster.MaxServicePorts, 0, "Maximum number of ports of the service of a shared ingress controller. IngressConfigs exceeding it are refused. Zero disables the limit.")
+	daemonCommand.PersistentFlags().Duration(f.Service.HostCluster.PortCoolingOff, 0, "Time LB ports released by a deleted guest cluster cannot be reassigned to another guest cluster, so that stale client connections are not routed to the wrong tenant. Zero reassigns released LB ports right away.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.HostCluster.PortRanges, []string{}, "LB port ranges available for guest clusters in addition to the available ports, e.g. 31000-31499,32000-32099. Ranges must not overlap.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.HostCluster.PreservedServiceFields, []string{"externalIPs", "loadBalancerSourceRanges", "topologyKeys"}, "Service spec fields of shared ingress controllers the operator must preserve verbatim, out of externalIPs, externalTrafficPolicy, loadBalancerIP, loadBalancerSourceRanges, selector, sessionAffinity and topologyKeys. Preserving topologyKeys, which is unknown to the API types of the operator, writes only the ports with a merge patch instead of updating the whole service.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.HostCluster.Propagation.Annotations, []string{}, "Annotation keys propagated from IngressConfigs to the host cluster objects managed for their guest clusters.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.HostCluster.Propagation.Labels, []string{}, "Label keys propagated from IngressConfigs to the host cluster objects managed for their guest clusters, e.g. environment,customer,pipeline.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.HostCluster.ReservedPorts, []string{}, "LB ports reserved for system use. They are never handed to guest clusters, even if an IngressConfig requests them.")
+	daemonCommand.PersistentFlags().String(f.Service.HostCluster.StreamSnippets, "ingress-controller-stream-snippets", "Config map in the namespace of the shared ingress controller holding the nginx stream snippets tuning single LB ports.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.HostCluster.UtilizationThresholds, []string{"0.8", "0.95"}, "Utilization fractions of the port pool raising a warning event on the ledger config map when being crossed.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.Address, "http://127.0.0.1:6443", "Address used to connect to Kubernetes. When empty in-cluster config is created.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Kubernetes.Breaker.CoolDown, 30*time.Second, "Time writes to Kubernetes are paused for once the circuit breaker opened. Reads continue.")
+	daemonCommand.PersistentFlags().Int(f.Service.Kubernetes.Breaker.MinRequests, 20, "Minimum number of writes to Kubernetes within the window before the circuit breaker opens.")
+	daemonCommand.PersistentFlags().Float64(f.Service.Kubernetes.Breaker.Threshold, 0.5, "Rate of writes to Kubernetes within the window failing because of an overloaded or unavailable API server, between 0 and 1, which opens the circuit breaker. Zero disables the circuit breaker.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Kubernetes.Breaker.Window, time.Minute, "Time writes to Kubernetes are taken into account for the error rate of the circuit breaker.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Kubernetes.Healthz.Interval, 0, "Interval in which the connectivity to Kubernetes is checked. Health checks within the interval report the result of the last check, so that frequent probes do not add load to slow API servers. Zero checks on every health check.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Kubernetes.Healthz.Timeout, 5*time.Second, "Maximum time the connectivity check of Kubernetes may take before the health check fails. The measured latency is reported by the health check, so that it can be tuned to slow API servers.")
 	daemonCommand.PersistentFlags().Bool(f.Service.Kubernetes.InCluster, false, "Whether to use the in-cluster config to authenticate with Kubernetes.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Kubernetes.KeepAlive, 30*time.Second, "Interval of TCP keep-alive probes of connections to Kubernetes. Dead connections are detected after a few intervals.")
+	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.KubeConfig.Context, "", "Context of the kubeconfig to use. When empty the current context of the kubeconfig is used.")
+	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.KubeConfig.Path, "", "Kubeconfig file path to use to authenticate with Kubernetes, e.g. via exec credential plugins. Takes precedence over address and TLS settings.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Kubernetes.Timeout.Dial, 10*time.Second, "Maximum time establishing a connection to Kubernetes may take.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Kubernetes.Timeout.Request, 30*time.Second, "Maximum time a single request to Kubernetes may take.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Kubernetes.Timeout.TLSHandshake, 10*time.Second, "Maximum time the TLS handshake with Kubernetes may take.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.CAFile, "", "Certificate authority file path to use to authenticate with Kubernetes.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.CrtFile, "", "Certificate file path to use to authenticate with Kubernetes.")
 	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TLS.KeyFile, "", "Key file path to use to authenticate with Kubernetes.")
+	daemonCommand.PersistentFlags().String(f.Service.Kubernetes.TokenFile, "", "Bearer token file path to use to authenticate with Kubernetes, e.g. a ServiceAccount token file.")
+	daemonCommand.PersistentFlags().Bool(f.Service.Monitoring.Enabled, false, "Whether to create and keep in sync a ServiceMonitor and a PrometheusRule with the recommended alerts of the operator. Requires the Prometheus Operator. Replaces the PodMonitor of the rendered manifests, which would scrape the operator twice otherwise.")
+	daemonCommand.PersistentFlags().Duration(f.Service.Monitoring.Interval, 5*time.Minute, "Interval in which the ServiceMonitor and the PrometheusRule of the operator are synced.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Monitoring.Labels, []string{}, "Labels added to the ServiceMonitor and the PrometheusRule of the operator, so that they are selected by the Prometheus instance, e.g. release=prometheus.")
+	daemonCommand.PersistentFlags().String(f.Service.Monitoring.Namespace, "giantswarm", "Namespace of the operator the ServiceMonitor and the PrometheusRule are managed in.")
+	daemonCommand.PersistentFlags().StringSlice(f.Service.Resources.Disable, []string{}, "Resources which are not executed, e.g. configmap in case the config map of the ingress controller is managed by another system. Conditions of disabled resources are not required for IngressConfigs to be ready.")
+
+	// Create the config command printing the effective configuration of the
+	// daemon command. It therefore has to be created after all daemon flags got
+	// registered. The same applies to the render-manifests command.
+	var configCommand configcommand.Command
+	{
+		c := configcommand.Config{
+			FlagSet: daemonCommand.PersistentFlags(),
+		}
+
+		configCommand, err = configcommand.New(c)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	var renderManifestsCommand rendermanifestscommand.Command
+	{
+		c := rendermanifestscommand.Config{
+			FlagSet: daemonCommand.PersistentFlags(),
+		}
+
+		renderManifestsCommand, err = rendermanifestscommand.New(c)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	var resyncCommand resynccommand.Command
+	{
+		resyncCommand, err = resynccommand.New(resynccommand.Config{})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	var statusCommand statuscommand.Command
+	{
+		statusCommand, err = statuscommand.New(statuscommand.Config{})
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	newCommand.CobraCommand().AddCommand(configCommand.CobraCommand())
+	newCommand.CobraCommand().AddCommand(renderManifestsCommand.CobraCommand())
+	newCommand.CobraCommand().AddCommand(resyncCommand.CobraCommand())
+	newCommand.CobraCommand().AddCommand(statusCommand.CobraCommand())
 
 	newCommand.CobraCommand().Execute()
 }
@@ -0,0 +1,225 @@
+// Package client implements a Go client of the HTTP API of the operator, so
+// that other services, e.g. cluster-operator or the provisioning API, can look
+// up LB port allocations and guest cluster status and simulate IngressConfigs
+// without hand-rolling HTTP calls. The types of the responses are the ones the
+// operator encodes.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/server/endpoint/allocations"
+	"github.com/giantswarm/ingress-operator/server/endpoint/history"
+	"github.com/giantswarm/ingress-operator/server/endpoint/simulate"
+	historyservice "github.com/giantswarm/ingress-operator/service/history"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+	"github.com/giantswarm/ingress-operator/service/simulation"
+)
+
+const (
+	// DefaultTimeout is the timeout of the HTTP client used in case none is
+	// configured.
+	DefaultTimeout = 30 * time.Second
+)
+
+// Config represents the configuration used to create a new client.
+type Config struct {
+	// Address is the address of the HTTP server of the operator, e.g.
+	// http://ingress-operator:8000.
+	Address string
+	// HTTPClient is used to call the operator. It defaults to a client with a
+	// timeout of DefaultTimeout.
+	HTTPClient *http.Client
+}
+
+// Client calls the HTTP API of a running operator.
+type Client struct {
+	address    string
+	httpClient *http.Client
+}
+
+// ClusterStatus is the state of a guest cluster as known by the operator.
+type ClusterStatus struct {
+	// Allocations are the LB ports allocated to the guest cluster.
+	Allocations []ledger.Allocation `json:"allocations"`
+	ClusterID   string              `json:"clusterID"`
+	// Reconciliations are the outcomes of the latest reconciliation of the
+	// IngressConfigs of the guest cluster since the operator started.
+	Reconciliations []historyservice.Entry `json:"reconciliations"`
+}
+
+// New creates a new configured client.
+func New(config Config) (*Client, error) {
+	if config.Address == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Address must not be empty", config)
+	}
+
+	u, err := url.Parse(config.Address)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Address must be a URL, got %q", config, config.Address)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Address must be an http or https URL, got %q", config, config.Address)
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	c := &Client{
+		address:    strings.TrimSuffix(config.Address, "/"),
+		httpClient: config.HTTPClient,
+	}
+
+	return c, nil
+}
+
+// ListAllocations returns the LB port allocations recorded by the operator.
+func (c *Client) ListAllocations(ctx context.Context) ([]ledger.Allocation, error) {
+	var r allocations.Response
+	err := c.do(ctx, http.MethodGet, allocations.Path, nil, &r)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return r.Allocations, nil
+}
+
+// ListAllocationsByIngressController returns the LB port allocations of the
+// guest clusters whose IngressConfigs target the given ingress controller.
+func (c *Client) ListAllocationsByIngressController(ctx context.Context, namespace, service string) ([]ledger.Allocation, error) {
+	query := url.Values{}
+	query.Set(allocations.IngressControllerQuery, namespace+"/"+service)
+
+	var r allocations.Response
+	err := c.do(ctx, http.MethodGet, allocations.Path+"?"+query.Encode(), nil, &r)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return r.Allocations, nil
+}
+
+// GetClusterStatus returns the LB ports allocated to the given guest cluster
+// and the outcome of the latest reconciliation of its IngressConfigs. It
+// returns a not found error in case the operator neither allocated LB ports to
+// the guest cluster nor reconciled it since it started. Operators not serving
+// the reconciliation history yet report allocations only.
+func (c *Client) GetClusterStatus(ctx context.Context, clusterID string) (*ClusterStatus, error) {
+	var a allocations.Response
+	err := c.do(ctx, http.MethodGet, allocations.Path, nil, &a)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var h history.Response
+	err = c.do(ctx, http.MethodGet, history.Path, nil, &h)
+	if err != nil && !IsNotFound(err) {
+		return nil, microerror.Mask(err)
+	}
+
+	s := &ClusterStatus{
+		Allocations:     []ledger.Allocation{},
+		ClusterID:       clusterID,
+		Reconciliations: []historyservice.Entry{},
+	}
+	for _, allocation := range a.Allocations {
+		if allocation.ClusterID == clusterID {
+			s.Allocations = append(s.Allocations, allocation)
+		}
+	}
+	for _, entry := range h.Reconciliations {
+		if entry.ClusterID == clusterID {
+			s.Reconciliations = append(s.Reconciliations, entry)
+		}
+	}
+
+	if len(s.Allocations) == 0 && len(s.Reconciliations) == 0 {
+		return nil, microerror.Maskf(notFoundError, "guest cluster %q", clusterID)
+	}
+
+	return s, nil
+}
+
+// Simulate returns the state the operator would generate for the given
+// IngressConfig without persisting anything.
+func (c *Client) Simulate(ctx context.Context, ingressConfig v1alpha1.IngressConfig) (*simulation.Result, error) {
+	b, err := json.Marshal(ingressConfig)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var r simulation.Result
+	err = c.do(ctx, simulate.Method, simulate.Path, bytes.NewReader(b), &r)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return &r, nil
+}
+
+// do sends a request with the given body to the given path of the operator and
+// decodes the response into v.
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, v interface{}) error {
+	req, err := http.NewRequest(method, c.address+path, body)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	req = req.WithContext(ctx)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer res.Body.Close()
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest:
+		return microerror.Maskf(invalidRequestError, "%s", errorMessage(b))
+	case http.StatusNotFound:
+		return microerror.Maskf(notFoundError, "%s %s", method, path)
+	default:
+		return microerror.Maskf(unexpectedStatusError, "expected %d, got %d: %s", http.StatusOK, res.StatusCode, errorMessage(b))
+	}
+
+	err = json.Unmarshal(b, v)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// errorMessage returns the message of the given error response of the
+// operator, or the response itself in case it is not an error response.
+func errorMessage(b []byte) string {
+	var r struct {
+		Error string `json:"error"`
+	}
+	err := json.Unmarshal(b, &r)
+	if err != nil || r.Error == "" {
+		return strings.TrimSpace(string(b))
+	}
+
+	return r.Error
+}
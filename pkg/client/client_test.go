@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestServer returns a server answering the given paths with the given
+// responses. The given statuses override the default status 200. Requests of
+// other paths are answered with status 404.
+func newTestServer(responses map[string]string, statuses map[string]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if r.URL.RawQuery != "" {
+			path += "?" + r.URL.RawQuery
+		}
+
+		response, ok := responses[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if s, ok := statuses[path]; ok {
+			w.WriteHeader(s)
+		}
+		w.Write([]byte(response))
+	}))
+}
+
+func newTestClient(t *testing.T, address string) *Client {
+	c, err := New(Config{Address: address + "/"})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return c
+}
+
+func Test_Client_New(t *testing.T) {
+	for _, address := range []string{"", "127.0.0.1:8000", "ftp://127.0.0.1"} {
+		_, err := New(Config{Address: address})
+		if !IsInvalidConfig(err) {
+			t.Fatal("expected", true, "got", false, "for", address)
+		}
+	}
+}
+
+func Test_Client_ListAllocations(t *testing.T) {
+	server := newTestServer(map[string]string{
+		"/allocations": `{"allocations":[{"clusterID":"al9qy","lbPort":30010,"protocol":"http"},{"clusterID":"p1l6x","lbPort":30011,"protocol":"https"}]}`,
+		"/allocations?ingressController=kube-system%2Fingress-controller": `{"allocations":[{"clusterID":"al9qy","lbPort":30010,"protocol":"http"}]}`,
+	}, nil)
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+
+	list, err := c.ListAllocations(context.TODO())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list) != 2 {
+		t.Fatal("expected", 2, "got", len(list))
+	}
+
+	list, err = c.ListAllocationsByIngressController(context.TODO(), "kube-system", "ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list) != 1 || list[0].LBPort != 30010 {
+		t.Fatalf("expected LB port 30010 got %#v", list)
+	}
+}
+
+func Test_Client_GetClusterStatus(t *testing.T) {
+	testCases := []struct {
+		name            string
+		responses       map[string]string
+		statuses        map[string]int
+		clusterID       string
+		allocations     int
+		reconciliations int
+		errorMatcher    func(error) bool
+	}{
+		{
+			name: "case 0: allocated and reconciled guest cluster",
+			responses: map[string]string{
+				"/allocations": `{"allocations":[{"clusterID":"al9qy","lbPort":30010},{"clusterID":"al9qy","lbPort":30011},{"clusterID":"p1l6x","lbPort":30012}]}`,
+				"/history":     `{"reconciliations":[{"clusterID":"al9qy","outcome":"succeeded"},{"clusterID":"p1l6x","outcome":"failed"}]}`,
+			},
+			clusterID:       "al9qy",
+			allocations:     2,
+			reconciliations: 1,
+		},
+		{
+			name: "case 1: operator not serving the history",
+			responses: map[string]string{
+				"/allocations": `{"allocations":[{"clusterID":"al9qy","lbPort":30010}]}`,
+			},
+			clusterID:       "al9qy",
+			allocations:     1,
+			reconciliations: 0,
+		},
+		{
+			name: "case 2: unknown guest cluster",
+			responses: map[string]string{
+				"/allocations": `{"allocations":[{"clusterID":"p1l6x","lbPort":30012}]}`,
+				"/history":     `{"reconciliations":[]}`,
+			},
+			clusterID:    "al9qy",
+			errorMatcher: IsNotFound,
+		},
+		{
+			name: "case 3: failing endpoint",
+			responses: map[string]string{
+				"/allocations": `{"code":"INTERNAL_ERROR","error":"An unexpected error occurred."}`,
+			},
+			statuses: map[string]int{
+				"/allocations": http.StatusInternalServerError,
+			},
+			clusterID:    "al9qy",
+			errorMatcher: IsUnexpectedStatus,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(tc.responses, tc.statuses)
+			defer server.Close()
+
+			s, err := newTestClient(t, server.URL).GetClusterStatus(context.TODO(), tc.clusterID)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if tc.errorMatcher != nil {
+				return
+			}
+
+			if len(s.Allocations) != tc.allocations {
+				t.Fatal("expected", tc.allocations, "got", len(s.Allocations))
+			}
+			if len(s.Reconciliations) != tc.reconciliations {
+				t.Fatal("expected", tc.reconciliations, "got", len(s.Reconciliations))
+			}
+		})
+	}
+}
+
+func Test_Client_Simulate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/simulate" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var customObject v1alpha1.IngressConfig
+		err := json.NewDecoder(r.Body).Decode(&customObject)
+		if err != nil || customObject.Name == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"INVALID_INPUT","error":"request body must be an IngressConfig"}`))
+			return
+		}
+
+		w.Write([]byte(`{"configMapData":{"30010":"default/worker:30010"},"conflicts":[],"validationErrors":[]}`))
+	}))
+	defer server.Close()
+
+	c := newTestClient(t, server.URL)
+
+	ingressConfig := v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "al9qy",
+		},
+	}
+
+	result, err := c.Simulate(context.TODO(), ingressConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if result.ConfigMapData["30010"] != "default/worker:30010" {
+		t.Fatalf("expected config map data got %#v", result.ConfigMapData)
+	}
+
+	_, err = c.Simulate(context.TODO(), v1alpha1.IngressConfig{})
+	if !IsInvalidRequest(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
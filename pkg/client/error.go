@@ -0,0 +1,43 @@
+package client
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidRequestError = &microerror.Error{
+	Kind: "invalidRequestError",
+}
+
+// IsInvalidRequest asserts invalidRequestError, which is returned in case the
+// operator rejected a request as invalid.
+func IsInvalidRequest(err error) bool {
+	return microerror.Cause(err) == invalidRequestError
+}
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+// IsNotFound asserts notFoundError, which is returned in case the operator
+// does not serve an endpoint or does not know a guest cluster.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
+
+var unexpectedStatusError = &microerror.Error{
+	Kind: "unexpectedStatusError",
+}
+
+// IsUnexpectedStatus asserts unexpectedStatusError.
+func IsUnexpectedStatus(err error) bool {
+	return microerror.Cause(err) == unexpectedStatusError
+}
@@ -0,0 +1,155 @@
+// Package logger implements a micrologger.Logger whose output format can be
+// chosen, so that the logs can be ingested by structured log pipelines
+// expecting either JSON or logfmt. The format can be changed after the logger
+// got created, since the logger has to exist before the command line flags
+// selecting the format are parsed. Loggers derived via With follow format
+// changes as well.
+package logger
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/loggermeta"
+	kitlog "github.com/go-kit/kit/log"
+)
+
+const (
+	// FormatJSON formats every log line as JSON object. This is the format of
+	// micrologger.
+	FormatJSON = "json"
+	// FormatLogfmt formats every log line as logfmt key value pairs.
+	FormatLogfmt = "logfmt"
+)
+
+// Formats returns all supported formats.
+func Formats() []string {
+	return []string{
+		FormatJSON,
+		FormatLogfmt,
+	}
+}
+
+type Config struct {
+	// Format is the initial format of the log output. It defaults to JSON.
+	Format   string
+	IOWriter io.Writer
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Format:   FormatJSON,
+		IOWriter: micrologger.DefaultIOWriter,
+	}
+}
+
+// Logger implements micrologger.Logger with a configurable output format. It
+// adds the same caller and time key value pairs micrologger does.
+type Logger struct {
+	format *formatLogger
+	logger kitlog.Logger
+}
+
+func New(config Config) (*Logger, error) {
+	if config.IOWriter == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IOWriter must not be empty", config)
+	}
+
+	f := &formatLogger{
+		writer: kitlog.NewSyncWriter(config.IOWriter),
+	}
+
+	err := f.setFormat(config.Format)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	l := &Logger{
+		format: f,
+		logger: kitlog.With(
+			f,
+			"caller", kitlog.Valuer(micrologger.DefaultCaller),
+			"time", kitlog.Valuer(micrologger.DefaultTimestampFormatter),
+		),
+	}
+
+	return l, nil
+}
+
+func (l *Logger) Log(keyVals ...interface{}) error {
+	return l.logger.Log(keyVals...)
+}
+
+func (l *Logger) LogCtx(ctx context.Context, keyVals ...interface{}) error {
+	meta, ok := loggermeta.FromContext(ctx)
+	if !ok {
+		return l.logger.Log(keyVals...)
+	}
+
+	var newKeyVals []interface{}
+	{
+		newKeyVals = append(newKeyVals, keyVals...)
+
+		for k, v := range meta.KeyVals {
+			newKeyVals = append(newKeyVals, k)
+			newKeyVals = append(newKeyVals, v)
+		}
+	}
+
+	return l.logger.Log(newKeyVals...)
+}
+
+// SetFormat changes the format of the log output of the logger and all
+// loggers derived from it. An empty format means JSON.
+func (l *Logger) SetFormat(format string) error {
+	err := l.format.setFormat(format)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (l *Logger) With(keyVals ...interface{}) micrologger.Logger {
+	return &Logger{
+		format: l.format,
+		logger: kitlog.With(l.logger, keyVals...),
+	}
+}
+
+// formatLogger is the innermost kit logger encoding the key value pairs in the
+// currently configured format.
+type formatLogger struct {
+	mutex  sync.RWMutex
+	logger kitlog.Logger
+	writer io.Writer
+}
+
+func (f *formatLogger) Log(keyVals ...interface{}) error {
+	f.mutex.RLock()
+	l := f.logger
+	f.mutex.RUnlock()
+
+	return l.Log(keyVals...)
+}
+
+func (f *formatLogger) setFormat(format string) error {
+	var l kitlog.Logger
+	switch format {
+	case "", FormatJSON:
+		l = kitlog.NewJSONLogger(f.writer)
+	case FormatLogfmt:
+		l = kitlog.NewLogfmtLogger(f.writer)
+	default:
+		return microerror.Maskf(invalidFormatError, "format must be one of %v but got %#q", Formats(), format)
+	}
+
+	f.mutex.Lock()
+	f.logger = l
+	f.mutex.Unlock()
+
+	return nil
+}
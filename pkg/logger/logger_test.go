@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+func Test_Logger_Format(t *testing.T) {
+	testCases := []struct {
+		Format       string
+		Check        func(line string) bool
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures the JSON format writes JSON objects.
+		{
+			Format: FormatJSON,
+			Check: func(line string) bool {
+				var m map[string]interface{}
+				return json.Unmarshal([]byte(line), &m) == nil && m["message"] == "test" && m["object"] == "al9qy" && m["caller"] != nil
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures the logfmt format writes key value pairs.
+		{
+			Format: FormatLogfmt,
+			Check: func(line string) bool {
+				return strings.Contains(line, "message=test") && strings.Contains(line, "object=al9qy") && strings.Contains(line, "caller=")
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures an empty format means JSON.
+		{
+			Format: "",
+			Check: func(line string) bool {
+				return strings.HasPrefix(line, "{")
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 3 ensures unknown formats are rejected.
+		{
+			Format:       "xml",
+			Check:        nil,
+			ErrorMatcher: IsInvalidFormat,
+		},
+	}
+
+	for i, tc := range testCases {
+		var b bytes.Buffer
+
+		c := DefaultConfig()
+		c.IOWriter = &b
+
+		l, err := New(c)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		err = l.SetFormat(tc.Format)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if tc.ErrorMatcher != nil {
+			continue
+		}
+
+		// The logger is derived before logging, so the format change is expected
+		// to apply to derived loggers and the context key value pairs.
+		meta := loggermeta.New()
+		meta.KeyVals["object"] = "al9qy"
+		ctx := loggermeta.NewContext(context.Background(), meta)
+
+		err = l.With("level", "debug").LogCtx(ctx, "message", "test")
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		line := strings.TrimSpace(b.String())
+		if !tc.Check(line) {
+			t.Fatal("test", i, "expected", "matching log line", "got", line)
+		}
+	}
+}
+
+func Test_Logger_SetFormat_derived(t *testing.T) {
+	var b bytes.Buffer
+
+	c := DefaultConfig()
+	c.IOWriter = &b
+
+	l, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	derived := l.With("level", "debug")
+
+	err = l.SetFormat(FormatLogfmt)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	derived.Log("message", "test")
+
+	if !strings.Contains(b.String(), "message=test") {
+		t.Fatal("expected", "logfmt", "got", b.String())
+	}
+}
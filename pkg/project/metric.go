@@ -0,0 +1,26 @@
+package project
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	PrometheusNamespace = "ingress_operator"
+)
+
+var (
+	buildInfoGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: PrometheusNamespace,
+			Name:      "build_info",
+			Help:      "A metric with a constant '1' value labeled by the name, version and git SHA of the operator.",
+		},
+		[]string{"name", "version", "git_sha"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(buildInfoGauge)
+
+	buildInfoGauge.WithLabelValues(name, version, gitSHA).Set(1)
+}
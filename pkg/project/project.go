@@ -0,0 +1,53 @@
+// Package project provides the metadata of the operator. It is the single
+// source of the name, version and git SHA of the build, which are reported by
+// the version endpoint, the build info metric and the version bundle. The git
+// SHA is injected into main.gitCommit at build time by architect, e.g.:
+//
+//	go build -ldflags "-X main.gitCommit=$(git rev-parse HEAD)"
+//
+// and set via SetGitSHA by main.
+package project
+
+var (
+	description = "The ingress-operator connects host cluster ingress controllers with guest cluster ingress controllers on a Giant Swarm Kubernetes host cluster."
+	gitSHA      = "n/a"
+	name        = "ingress-operator"
+	source      = "https://github.com/giantswarm/ingress-operator"
+	version     = "0.1.0"
+)
+
+// Description returns the description of the operator.
+func Description() string {
+	return description
+}
+
+// GitSHA returns the git SHA the operator got built from. It is "n/a" in case
+// it was not injected at build time.
+func GitSHA() string {
+	return gitSHA
+}
+
+// SetGitSHA sets the git SHA the operator got built from and updates the build
+// info metric. It is meant to be called by main before anything else runs.
+func SetGitSHA(sha string) {
+	gitSHA = sha
+
+	buildInfoGauge.Reset()
+	buildInfoGauge.WithLabelValues(name, version, gitSHA).Set(1)
+}
+
+// Name returns the name of the operator.
+func Name() string {
+	return name
+}
+
+// Source returns the URL of the source code of the operator.
+func Source() string {
+	return source
+}
+
+// Version returns the version of the operator. It is the version of its
+// version bundle.
+func Version() string {
+	return version
+}
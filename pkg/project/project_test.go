@@ -0,0 +1,26 @@
+package project
+
+import (
+	"testing"
+)
+
+// Test_Project_NewVersionBundle ensures the version bundle is valid and
+// describes the version of the operator.
+func Test_Project_NewVersionBundle(t *testing.T) {
+	b := NewVersionBundle()
+
+	err := b.Validate()
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	if b.Name != Name() {
+		t.Fatal("expected", Name(), "got", b.Name)
+	}
+	if b.Version != Version() {
+		t.Fatal("expected", Version(), "got", b.Version)
+	}
+	if len(b.Changelogs) == 0 {
+		t.Fatal("expected", "changelogs", "got", nil)
+	}
+}
@@ -0,0 +1,28 @@
+package project
+
+import (
+	"github.com/giantswarm/versionbundle"
+)
+
+// NewVersionBundle returns the version bundle of the operator. Its changelogs
+// describe the changes of the current version and are exposed by the version
+// endpoint, so release tooling can verify what is deployed.
+func NewVersionBundle() versionbundle.Bundle {
+	return versionbundle.Bundle{
+		Changelogs: []versionbundle.Changelog{
+			{
+				Component:   name,
+				Description: "Introduce the first version of the ingress-operator.",
+				Kind:        versionbundle.KindAdded,
+			},
+		},
+		Components: []versionbundle.Component{
+			{
+				Name:    name,
+				Version: version,
+			},
+		},
+		Name:    name,
+		Version: version,
+	}
+}
@@ -0,0 +1,116 @@
+// Package rbac derives the RBAC rules of the operator from the permissions its
+// packages declare. Every package accessing the Kubernetes API declares the
+// API group, resource and verb of each of its operations, so the ClusterRole
+// of the operator grants exactly what the operator uses and grows with the
+// operations added to it. Requests of non-resource URLs declare the URL
+// instead. Permissions of operations limited to a single namespace are granted
+// by a Role in that namespace instead of the ClusterRole.
+package rbac
+
+import (
+	"sort"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// Permission is the permission a single operation of the operator requires.
+type Permission struct {
+	// APIGroup is the API group of the resource. It is empty for the core API
+	// group.
+	APIGroup string
+	// Namespaced is whether the operation only accesses the resource in the
+	// namespace it is configured with, e.g. the namespace of the certificate
+	// Secrets.
+	Namespaced bool
+	// NonResourceURL is the URL of requests not accessing a resource, e.g. the
+	// root path requested by the health check. APIGroup and Resource are empty
+	// in this case.
+	NonResourceURL string
+	Resource       string
+	Verb           string
+}
+
+// Rules returns the minimal RBAC rules of a ClusterRole granting the given
+// permissions which are not namespaced. There is a rule per API group and
+// resource, followed by a rule per non-resource URL. Rules and verbs are
+// sorted, so that the rules are stable regardless of the order of the
+// permissions.
+func Rules(permissions ...Permission) []rbacv1.PolicyRule {
+	var l []Permission
+	for _, p := range permissions {
+		if !p.Namespaced {
+			l = append(l, p)
+		}
+	}
+
+	return policyRules(l)
+}
+
+// NamespacedRules returns the minimal RBAC rules of a Role granting the given
+// permissions which are namespaced. The rules are built like the ones of
+// Rules.
+func NamespacedRules(permissions ...Permission) []rbacv1.PolicyRule {
+	var l []Permission
+	for _, p := range permissions {
+		if p.Namespaced {
+			l = append(l, p)
+		}
+	}
+
+	return policyRules(l)
+}
+
+func policyRules(permissions []Permission) []rbacv1.PolicyRule {
+	type groupResource struct {
+		APIGroup       string
+		NonResourceURL string
+		Resource       string
+	}
+
+	verbs := map[groupResource]map[string]struct{}{}
+	for _, p := range permissions {
+		k := groupResource{APIGroup: p.APIGroup, NonResourceURL: p.NonResourceURL, Resource: p.Resource}
+		if verbs[k] == nil {
+			verbs[k] = map[string]struct{}{}
+		}
+		verbs[k][p.Verb] = struct{}{}
+	}
+
+	var keys []groupResource
+	for k := range verbs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].NonResourceURL != keys[j].NonResourceURL {
+			return keys[i].NonResourceURL < keys[j].NonResourceURL
+		}
+		if keys[i].APIGroup != keys[j].APIGroup {
+			return keys[i].APIGroup < keys[j].APIGroup
+		}
+		return keys[i].Resource < keys[j].Resource
+	})
+
+	var rules []rbacv1.PolicyRule
+	for _, k := range keys {
+		var v []string
+		for verb := range verbs[k] {
+			v = append(v, verb)
+		}
+		sort.Strings(v)
+
+		r := rbacv1.PolicyRule{
+			APIGroups: []string{k.APIGroup},
+			Resources: []string{k.Resource},
+			Verbs:     v,
+		}
+		if k.NonResourceURL != "" {
+			r = rbacv1.PolicyRule{
+				NonResourceURLs: []string{k.NonResourceURL},
+				Verbs:           v,
+			}
+		}
+		rules = append(rules, r)
+	}
+
+	return rules
+}
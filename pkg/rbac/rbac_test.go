@@ -0,0 +1,99 @@
+package rbac
+
+import (
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func Test_RBAC_Rules(t *testing.T) {
+	testCases := []struct {
+		Permissions []Permission
+		Expected    []rbacv1.PolicyRule
+	}{
+		// Test 0 ensures no permissions result in no rules.
+		{
+			Permissions: nil,
+			Expected:    nil,
+		},
+
+		// Test 1 ensures permissions are grouped by API group and resource, and
+		// duplicated verbs are removed.
+		{
+			Permissions: []Permission{
+				{Resource: "services", Verb: "update"},
+				{APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "watch"},
+				{Resource: "configmaps", Verb: "get"},
+				{Resource: "services", Verb: "get"},
+				{Resource: "services", Verb: "get"},
+			},
+			Expected: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{""},
+					Resources: []string{"configmaps"},
+					Verbs:     []string{"get"},
+				},
+				{
+					APIGroups: []string{""},
+					Resources: []string{"services"},
+					Verbs:     []string{"get", "update"},
+				},
+				{
+					APIGroups: []string{"core.giantswarm.io"},
+					Resources: []string{"ingressconfigs"},
+					Verbs:     []string{"watch"},
+				},
+			},
+		},
+
+		// Test 2 ensures non-resource URLs get a rule each after the resource
+		// rules, and namespaced permissions are left out.
+		{
+			Permissions: []Permission{
+				{NonResourceURL: "/", Verb: "get"},
+				{Namespaced: true, Resource: "secrets", Verb: "get"},
+				{Resource: "services", Verb: "get"},
+			},
+			Expected: []rbacv1.PolicyRule{
+				{
+					APIGroups: []string{""},
+					Resources: []string{"services"},
+					Verbs:     []string{"get"},
+				},
+				{
+					NonResourceURLs: []string{"/"},
+					Verbs:           []string{"get"},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		rules := Rules(tc.Permissions...)
+		if !reflect.DeepEqual(rules, tc.Expected) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, rules)
+		}
+	}
+}
+
+func Test_RBAC_NamespacedRules(t *testing.T) {
+	permissions := []Permission{
+		{NonResourceURL: "/", Verb: "get"},
+		{Namespaced: true, Resource: "secrets", Verb: "get"},
+		{Resource: "services", Verb: "get"},
+	}
+
+	expected := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     []string{"get"},
+		},
+	}
+
+	rules := NamespacedRules(permissions...)
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("expected %#v got %#v", expected, rules)
+	}
+}
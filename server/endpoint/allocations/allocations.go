@@ -0,0 +1,195 @@
+// Package allocations implements the endpoint listing the LB port allocations
+// recorded in the allocation ledger. The allocations can be filtered by the
+// ingress controller the IngressConfigs of their guest clusters target, which
+// is looked up in the index of the IngressConfig lister. The allocations of
+// the remaining legacy ingresstpr objects are listed as well, flagged as
+// legacy, in case they are watched.
+package allocations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+	"github.com/giantswarm/ingress-operator/service/legacy"
+	"github.com/giantswarm/ingress-operator/service/lister"
+)
+
+const (
+	// Method is the HTTP method this endpoint is registered for.
+	Method = "GET"
+	// Name identifies the endpoint. It is aligned to the package path.
+	Name = "allocations"
+	// Path is the HTTP request path this endpoint is registered for.
+	Path = "/allocations"
+	// IngressControllerQuery is the query parameter filtering the allocations
+	// by ingress controller, in the format namespace/service.
+	IngressControllerQuery = "ingressController"
+)
+
+// Config represents the configuration used to create an allocations endpoint.
+type Config struct {
+	// Dependencies.
+	// Legacy lists the allocations of the remaining legacy ingresstpr
+	// objects. It is optional.
+	Legacy *legacy.Watcher
+	Ledger *ledger.Ledger
+	Lister *lister.Lister
+	Logger micrologger.Logger
+}
+
+// DefaultConfig provides a default configuration to create a new allocations
+// endpoint by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		Legacy: nil,
+		Ledger: nil,
+		Lister: nil,
+		Logger: nil,
+	}
+}
+
+// New creates a new configured allocations endpoint.
+func New(config Config) (*Endpoint, error) {
+	// Dependencies.
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Ledger must not be empty")
+	}
+	if config.Lister == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Lister must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	newEndpoint := &Endpoint{
+		Config: config,
+	}
+
+	return newEndpoint, nil
+}
+
+type Endpoint struct {
+	Config
+}
+
+// Response is the response of the allocations endpoint.
+type Response struct {
+	Allocations []ledger.Allocation `json:"allocations"`
+}
+
+// Request is the decoded request of the allocations endpoint.
+type Request struct {
+	// IngressControllerNamespace and IngressControllerService filter the
+	// allocations by ingress controller. Both are empty in case the
+	// allocations are not filtered.
+	IngressControllerNamespace string
+	IngressControllerService   string
+}
+
+// Decoder decodes the optional ingress controller filter from the query.
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		v := r.URL.Query().Get(IngressControllerQuery)
+		if v == "" {
+			return Request{}, nil
+		}
+
+		parts := strings.Split(v, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, microerror.Maskf(invalidRequestError, "%s must be in the format namespace/service, got %q", IngressControllerQuery, v)
+		}
+
+		request := Request{
+			IngressControllerNamespace: parts[0],
+			IngressControllerService:   parts[1],
+		}
+
+		return request, nil
+	}
+}
+
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		return json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		allocations, err := e.Ledger.List(ctx)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		r := request.(Request)
+		if r.IngressControllerService != "" {
+			allocations, err = e.filter(ctx, allocations, r.IngressControllerNamespace, r.IngressControllerService)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+		if e.Legacy != nil {
+			allocations = append(allocations, e.Legacy.Allocations(r.IngressControllerNamespace, r.IngressControllerService)...)
+		}
+
+		response := Response{
+			Allocations: allocations,
+		}
+		if response.Allocations == nil {
+			response.Allocations = []ledger.Allocation{}
+		}
+
+		return response, nil
+	}
+}
+
+// filter returns the allocations of the guest clusters whose IngressConfigs
+// target the given ingress controller.
+func (e *Endpoint) filter(ctx context.Context, allocations []ledger.Allocation, namespace, service string) ([]ledger.Allocation, error) {
+	list, err := e.Lister.ListIngressConfigsByIngressController(ctx, namespace, service)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	clusterIDs := map[string]bool{}
+	for _, customObject := range list.Items {
+		clusterIDs[key.ClusterID(customObject)] = true
+	}
+
+	var filtered []ledger.Allocation
+	for _, a := range allocations {
+		if clusterIDs[a.ClusterID] {
+			filtered = append(filtered, a)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *Endpoint) Name() string {
+	return Name
+}
+
+func (e *Endpoint) Path() string {
+	return Path
+}
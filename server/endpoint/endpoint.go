@@ -7,6 +7,16 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
+	"github.com/giantswarm/ingress-operator/server/endpoint/allocations"
+	"github.com/giantswarm/ingress-operator/server/endpoint/deletions"
+	"github.com/giantswarm/ingress-operator/server/endpoint/export"
+	"github.com/giantswarm/ingress-operator/server/endpoint/features"
+	"github.com/giantswarm/ingress-operator/server/endpoint/history"
+	"github.com/giantswarm/ingress-operator/server/endpoint/release"
+	"github.com/giantswarm/ingress-operator/server/endpoint/resync"
+	"github.com/giantswarm/ingress-operator/server/endpoint/simulate"
+	"github.com/giantswarm/ingress-operator/server/endpoint/swagger"
+	"github.com/giantswarm/ingress-operator/server/endpoint/usage"
 	"github.com/giantswarm/ingress-operator/server/middleware"
 	"github.com/giantswarm/ingress-operator/service"
 )
@@ -34,12 +44,36 @@ func DefaultConfig() Config {
 func New(config Config) (*Endpoint, error) {
 	var err error
 
+	var allocationsEndpoint *allocations.Endpoint
+	{
+		allocationsConfig := allocations.DefaultConfig()
+		allocationsConfig.Legacy = config.Service.Legacy
+		allocationsConfig.Ledger = config.Service.Ledger
+		allocationsConfig.Lister = config.Service.Lister
+		allocationsConfig.Logger = config.Logger
+		allocationsEndpoint, err = allocations.New(allocationsConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var deletionsEndpoint *deletions.Endpoint
+	{
+		deletionsConfig := deletions.DefaultConfig()
+		deletionsConfig.Logger = config.Logger
+		deletionsEndpoint, err = deletions.New(deletionsConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var healthzEndpoint *healthz.Endpoint
 	{
 		healthzConfig := healthz.DefaultConfig()
 		healthzConfig.Logger = config.Logger
 		healthzConfig.Services = []healthzservice.Service{
 			config.Service.Healthz.K8s,
+			config.Service.Healthz.Breaker,
 		}
 		healthzEndpoint, err = healthz.New(healthzConfig)
 		if err != nil {
@@ -47,6 +81,94 @@ func New(config Config) (*Endpoint, error) {
 		}
 	}
 
+	var exportEndpoint *export.Endpoint
+	{
+		exportConfig := export.DefaultConfig()
+		exportConfig.Exporter = config.Service.Export
+		exportConfig.Logger = config.Logger
+		exportEndpoint, err = export.New(exportConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var featuresEndpoint *features.Endpoint
+	{
+		featuresConfig := features.DefaultConfig()
+		featuresConfig.Logger = config.Logger
+		featuresEndpoint, err = features.New(featuresConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var historyEndpoint *history.Endpoint
+	{
+		historyConfig := history.DefaultConfig()
+		historyConfig.Logger = config.Logger
+		historyConfig.Recorder = config.Service.History
+		historyEndpoint, err = history.New(historyConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var releaseEndpoint *release.Endpoint
+	{
+		releaseConfig := release.DefaultConfig()
+		releaseConfig.Logger = config.Logger
+		releaseConfig.Middleware = config.Middleware
+		releaseConfig.Releaser = config.Service.Release
+		releaseEndpoint, err = release.New(releaseConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var resyncEndpoint *resync.Endpoint
+	{
+		resyncConfig := resync.DefaultConfig()
+		resyncConfig.Logger = config.Logger
+		resyncConfig.Middleware = config.Middleware
+		resyncConfig.Resyncer = config.Service.Resync
+		resyncEndpoint, err = resync.New(resyncConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var simulateEndpoint *simulate.Endpoint
+	{
+		simulateConfig := simulate.DefaultConfig()
+		simulateConfig.Logger = config.Logger
+		simulateConfig.Simulator = config.Service.Simulation
+		simulateEndpoint, err = simulate.New(simulateConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var swaggerEndpoint *swagger.Endpoint
+	{
+		swaggerConfig := swagger.DefaultConfig()
+		swaggerConfig.Logger = config.Logger
+		swaggerEndpoint, err = swagger.New(swaggerConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var usageEndpoint *usage.Endpoint
+	{
+		usageConfig := usage.DefaultConfig()
+		usageConfig.Logger = config.Logger
+		usageConfig.Reporter = config.Service.Usage
+		usageEndpoint, err = usage.New(usageConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var versionEndpoint *version.Endpoint
 	{
 		versionConfig := version.DefaultConfig()
@@ -59,8 +181,18 @@ func New(config Config) (*Endpoint, error) {
 	}
 
 	newEndpoint := &Endpoint{
-		Healthz: healthzEndpoint,
-		Version: versionEndpoint,
+		Allocations: allocationsEndpoint,
+		Deletions:   deletionsEndpoint,
+		Export:      exportEndpoint,
+		Features:    featuresEndpoint,
+		Healthz:     healthzEndpoint,
+		History:     historyEndpoint,
+		Release:     releaseEndpoint,
+		Resync:      resyncEndpoint,
+		Simulate:    simulateEndpoint,
+		Swagger:     swaggerEndpoint,
+		Usage:       usageEndpoint,
+		Version:     versionEndpoint,
 	}
 
 	return newEndpoint, nil
@@ -68,6 +200,16 @@ func New(config Config) (*Endpoint, error) {
 
 // Endpoint is the endpoint collection.
 type Endpoint struct {
-	Healthz *healthz.Endpoint
-	Version *version.Endpoint
+	Allocations *allocations.Endpoint
+	Deletions   *deletions.Endpoint
+	Export      *export.Endpoint
+	Features    *features.Endpoint
+	Healthz     *healthz.Endpoint
+	History     *history.Endpoint
+	Release     *release.Endpoint
+	Resync      *resync.Endpoint
+	Simulate    *simulate.Endpoint
+	Swagger     *swagger.Endpoint
+	Usage       *usage.Endpoint
+	Version     *version.Endpoint
 }
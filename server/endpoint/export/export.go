@@ -0,0 +1,116 @@
+// Package export implements the endpoint rendering the allocation table of the
+// allocation ledger as nginx stream config snippet or haproxy map file.
+package export
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+
+	"github.com/giantswarm/ingress-operator/service/export"
+)
+
+const (
+	// Method is the HTTP method this endpoint is registered for.
+	Method = "GET"
+	// Name identifies the endpoint. It is aligned to the package path.
+	Name = "export"
+	// Path is the HTTP request path this endpoint is registered for.
+	Path = "/export/{format}"
+)
+
+// Config represents the configuration used to create an export endpoint.
+type Config struct {
+	// Dependencies.
+	Exporter *export.Exporter
+	Logger   micrologger.Logger
+}
+
+// DefaultConfig provides a default configuration to create a new export
+// endpoint by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		Exporter: nil,
+		Logger:   nil,
+	}
+}
+
+// New creates a new configured export endpoint.
+func New(config Config) (*Endpoint, error) {
+	// Dependencies.
+	if config.Exporter == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Exporter must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	newEndpoint := &Endpoint{
+		Config: config,
+	}
+
+	return newEndpoint, nil
+}
+
+type Endpoint struct {
+	Config
+}
+
+// Decoder decodes the format of the export from the request path.
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		f := export.Format(mux.Vars(r)["format"])
+		if !export.IsFormat(f) {
+			return nil, microerror.Maskf(invalidRequestError, "format must be %s or %s, got %q", export.HAProxy, export.Nginx, f)
+		}
+
+		return f, nil
+	}
+}
+
+// Encoder writes the export as plain text, so that it can be used as is.
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		_, err := w.Write(response.([]byte))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+}
+
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		b, err := e.Exporter.Export(ctx, request.(export.Format))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return b, nil
+	}
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *Endpoint) Name() string {
+	return Name
+}
+
+func (e *Endpoint) Path() string {
+	return Path
+}
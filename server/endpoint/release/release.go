@@ -0,0 +1,121 @@
+// Package release implements the administrative endpoint force releasing a
+// leaked LB port allocation.
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+
+	"github.com/giantswarm/ingress-operator/server/middleware"
+	"github.com/giantswarm/ingress-operator/server/middleware/admin"
+	"github.com/giantswarm/ingress-operator/service/release"
+)
+
+const (
+	// Method is the HTTP method this endpoint is registered for.
+	Method = "DELETE"
+	// Name identifies the endpoint. It is aligned to the package path.
+	Name = "release"
+	// Path is the HTTP request path this endpoint is registered for.
+	Path = "/allocations/{port}"
+)
+
+// Config represents the configuration used to create a release endpoint.
+type Config struct {
+	// Dependencies.
+	Logger     micrologger.Logger
+	Middleware *middleware.Middleware
+	Releaser   *release.Releaser
+}
+
+// DefaultConfig provides a default configuration to create a new release
+// endpoint by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		Logger:     nil,
+		Middleware: nil,
+		Releaser:   nil,
+	}
+}
+
+// New creates a new configured release endpoint.
+func New(config Config) (*Endpoint, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+	if config.Middleware == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Middleware must not be empty")
+	}
+	if config.Releaser == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Releaser must not be empty")
+	}
+
+	newEndpoint := &Endpoint{
+		Config: config,
+	}
+
+	return newEndpoint, nil
+}
+
+type Endpoint struct {
+	Config
+}
+
+// Decoder decodes the LB port being released from the request path.
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		port, err := strconv.Atoi(mux.Vars(r)["port"])
+		if err != nil || port <= 0 || port > 65535 {
+			return nil, microerror.Maskf(invalidRequestError, "port must be a valid port, got %q", mux.Vars(r)["port"])
+		}
+
+		return port, nil
+	}
+}
+
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		return json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		result, err := e.Releaser.Release(ctx, request.(int))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{
+		e.Middleware.Admin.Middleware(admin.RoleAdmin),
+	}
+}
+
+func (e *Endpoint) Name() string {
+	return Name
+}
+
+func (e *Endpoint) Path() string {
+	return Path
+}
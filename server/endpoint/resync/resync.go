@@ -0,0 +1,115 @@
+// Package resync implements the endpoint requeueing all IngressConfigs for
+// reconciliation at a bounded rate. It is an administrative endpoint which
+// admins and operators may call.
+package resync
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"github.com/giantswarm/ingress-operator/server/middleware"
+	"github.com/giantswarm/ingress-operator/server/middleware/admin"
+	"github.com/giantswarm/ingress-operator/service/resync"
+)
+
+const (
+	// Method is the HTTP method this endpoint is registered for.
+	Method = "POST"
+	// Name identifies the endpoint. It is aligned to the package path.
+	Name = "resync"
+	// Path is the HTTP request path this endpoint is registered for.
+	Path = "/resync"
+)
+
+// Config represents the configuration used to create a resync endpoint.
+type Config struct {
+	// Dependencies.
+	Logger     micrologger.Logger
+	Middleware *middleware.Middleware
+	Resyncer   *resync.Resyncer
+}
+
+// DefaultConfig provides a default configuration to create a new resync
+// endpoint by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		Logger:     nil,
+		Middleware: nil,
+		Resyncer:   nil,
+	}
+}
+
+// New creates a new configured resync endpoint.
+func New(config Config) (*Endpoint, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+	if config.Middleware == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Middleware must not be empty")
+	}
+	if config.Resyncer == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Resyncer must not be empty")
+	}
+
+	newEndpoint := &Endpoint{
+		Config: config,
+	}
+
+	return newEndpoint, nil
+}
+
+type Endpoint struct {
+	Config
+}
+
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		return nil, nil
+	}
+}
+
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusAccepted)
+
+		return json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		result, err := e.Resyncer.Start(ctx)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{
+		e.Middleware.Admin.Middleware(admin.RoleOperator),
+	}
+}
+
+func (e *Endpoint) Name() string {
+	return Name
+}
+
+func (e *Endpoint) Path() string {
+	return Path
+}
@@ -0,0 +1,112 @@
+// Package simulate implements the endpoint computing the state the operator
+// would generate for an IngressConfig without persisting anything.
+package simulate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+
+	"github.com/giantswarm/ingress-operator/service/simulation"
+)
+
+const (
+	// Method is the HTTP method this endpoint is registered for.
+	Method = "POST"
+	// Name identifies the endpoint. It is aligned to the package path.
+	Name = "simulate"
+	// Path is the HTTP request path this endpoint is registered for.
+	Path = "/simulate"
+)
+
+// Config represents the configuration used to create a simulate endpoint.
+type Config struct {
+	// Dependencies.
+	Logger    micrologger.Logger
+	Simulator *simulation.Simulator
+}
+
+// DefaultConfig provides a default configuration to create a new simulate
+// endpoint by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		Logger:    nil,
+		Simulator: nil,
+	}
+}
+
+// New creates a new configured simulate endpoint.
+func New(config Config) (*Endpoint, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+	if config.Simulator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Simulator must not be empty")
+	}
+
+	newEndpoint := &Endpoint{
+		Config: config,
+	}
+
+	return newEndpoint, nil
+}
+
+type Endpoint struct {
+	Config
+}
+
+// Decoder decodes the IngressConfig being simulated from the request body.
+func (e *Endpoint) Decoder() kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		var customObject v1alpha1.IngressConfig
+		err := json.NewDecoder(r.Body).Decode(&customObject)
+		if err != nil {
+			return nil, microerror.Maskf(invalidRequestError, "request body must be an IngressConfig: %s", err.Error())
+		}
+
+		return customObject, nil
+	}
+}
+
+func (e *Endpoint) Encoder() kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		return json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (e *Endpoint) Endpoint() kitendpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		result, err := e.Simulator.Simulate(ctx, request.(v1alpha1.IngressConfig))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+}
+
+func (e *Endpoint) Method() string {
+	return Method
+}
+
+func (e *Endpoint) Middlewares() []kitendpoint.Middleware {
+	return []kitendpoint.Middleware{}
+}
+
+func (e *Endpoint) Name() string {
+	return Name
+}
+
+func (e *Endpoint) Path() string {
+	return Path
+}
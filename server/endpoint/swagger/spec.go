@@ -0,0 +1,687 @@
+package swagger
+
+// Spec is the OpenAPI 2.0 document describing the HTTP endpoints of the
+// operator. It is served by the swagger endpoint and has to be kept in sync
+// with the endpoints registered in the server. Info.Version is the version of
+// the operator API, which is bumped on every change of this document.
+const Spec = `{
+  "swagger": "2.0",
+  "info": {
+    "title": "ingress-operator",
+    "description": "HTTP API of the ingress-operator.",
+    "version": "1.9.0"
+  },
+  "schemes": [
+    "http"
+  ],
+  "produces": [
+    "application/json"
+  ],
+  "paths": {
+    "/": {
+      "get": {
+        "operationId": "getVersion",
+        "summary": "Returns version information of the operator.",
+        "responses": {
+          "200": {
+            "description": "Version information.",
+            "schema": {
+              "$ref": "#/definitions/Version"
+            }
+          }
+        }
+      }
+    },
+    "/allocations": {
+      "get": {
+        "operationId": "listAllocations",
+        "summary": "Lists the LB port allocations recorded in the allocation ledger.",
+        "parameters": [
+          {
+            "name": "ingressController",
+            "in": "query",
+            "description": "Only lists the allocations of guest clusters whose IngressConfigs target the ingress controller of the given service, in the format namespace/service.",
+            "required": false,
+            "type": "string"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "LB port allocations.",
+            "schema": {
+              "$ref": "#/definitions/Allocations"
+            }
+          },
+          "400": {
+            "description": "The ingress controller is not in the format namespace/service.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Internal error.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/allocations/{port}": {
+      "delete": {
+        "operationId": "releaseAllocation",
+        "summary": "Force releases a leaked LB port allocation. Requires the admin role, authenticated by bearer token or client certificate.",
+        "parameters": [
+          {
+            "name": "port",
+            "in": "path",
+            "description": "LB port to release.",
+            "required": true,
+            "type": "integer"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The LB port got released.",
+            "schema": {
+              "$ref": "#/definitions/Release"
+            }
+          },
+          "400": {
+            "description": "The port is not a valid port.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "401": {
+            "description": "The request provides neither a valid admin token nor a valid client certificate.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "Administrative endpoints are disabled or the caller lacks the admin role.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "409": {
+            "description": "An IngressConfig still owns the LB port.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Internal error.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/deletions": {
+      "get": {
+        "operationId": "listDeletions",
+        "summary": "Lists the deletions of IngressConfigs delayed by pods remaining in their guest cluster namespace, how long they are pending and the pods remaining at the last check.",
+        "responses": {
+          "200": {
+            "description": "Delayed deletions ordered by guest cluster ID.",
+            "schema": {
+              "$ref": "#/definitions/Deletions"
+            }
+          }
+        }
+      }
+    },
+    "/export/{format}": {
+      "get": {
+        "operationId": "exportAllocations",
+        "summary": "Renders the LB port allocations recorded in the allocation ledger as nginx stream config snippet or haproxy map file. Upstreams assume the default namespace and service of guest clusters.",
+        "produces": [
+          "text/plain"
+        ],
+        "parameters": [
+          {
+            "name": "format",
+            "in": "path",
+            "description": "Format of the export.",
+            "required": true,
+            "type": "string",
+            "enum": [
+              "haproxy",
+              "nginx"
+            ]
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The rendered allocations.",
+            "schema": {
+              "type": "string"
+            }
+          },
+          "400": {
+            "description": "The format is unknown.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Internal error.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/features": {
+      "get": {
+        "operationId": "listFeatures",
+        "summary": "Lists the spec fields and annotations of IngressConfigs supported per version bundle version. IngressConfigs using features their version bundle version does not support are rejected.",
+        "responses": {
+          "200": {
+            "description": "Supported features per version bundle version in the order of their release.",
+            "schema": {
+              "$ref": "#/definitions/Features"
+            }
+          }
+        }
+      }
+    },
+    "/healthz": {
+      "get": {
+        "operationId": "getHealthz",
+        "summary": "Returns the results of the health checks of the operator.",
+        "responses": {
+          "200": {
+            "description": "All health checks succeeded.",
+            "schema": {
+              "$ref": "#/definitions/Healthz"
+            }
+          },
+          "500": {
+            "description": "At least one health check failed.",
+            "schema": {
+              "$ref": "#/definitions/Healthz"
+            }
+          }
+        }
+      }
+    },
+    "/history": {
+      "get": {
+        "operationId": "listHistory",
+        "summary": "Lists the outcome of the latest reconciliation of every IngressConfig reconciled since the operator started.",
+        "responses": {
+          "200": {
+            "description": "Latest reconciliations.",
+            "schema": {
+              "$ref": "#/definitions/History"
+            }
+          }
+        }
+      }
+    },
+    "/resync": {
+      "post": {
+        "operationId": "resync",
+        "summary": "Requeues all IngressConfigs for reconciliation at a bounded rate. Requires the admin or operator role, authenticated by bearer token or client certificate.",
+        "responses": {
+          "202": {
+            "description": "The resync got started.",
+            "schema": {
+              "$ref": "#/definitions/Resync"
+            }
+          },
+          "401": {
+            "description": "The request provides neither a valid admin token nor a valid client certificate.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "403": {
+            "description": "Administrative endpoints are disabled or the caller lacks the admin and operator roles.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "429": {
+            "description": "Another resync is still in progress.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Internal error.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/simulate": {
+      "post": {
+        "operationId": "simulate",
+        "summary": "Computes the state generated for an IngressConfig without persisting anything.",
+        "consumes": [
+          "application/json"
+        ],
+        "parameters": [
+          {
+            "name": "body",
+            "in": "body",
+            "description": "IngressConfig to simulate.",
+            "required": true,
+            "schema": {
+              "type": "object"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The state generated for the IngressConfig, its validation errors and port conflicts.",
+            "schema": {
+              "$ref": "#/definitions/Simulation"
+            }
+          },
+          "400": {
+            "description": "The request body is not an IngressConfig.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "500": {
+            "description": "Internal error.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/swagger.json": {
+      "get": {
+        "operationId": "getSwagger",
+        "summary": "Returns this OpenAPI document.",
+        "responses": {
+          "200": {
+            "description": "OpenAPI document."
+          }
+        }
+      }
+    },
+    "/usage": {
+      "get": {
+        "operationId": "getUsage",
+        "summary": "Returns the LB ports allocated per guest cluster and for how long they have been allocated.",
+        "responses": {
+          "200": {
+            "description": "Usage of all guest clusters having LB ports allocated.",
+            "schema": {
+              "$ref": "#/definitions/Usage"
+            }
+          },
+          "500": {
+            "description": "Internal error.",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "Allocation": {
+      "type": "object",
+      "properties": {
+        "allocatedAt": {
+          "type": "string",
+          "format": "date-time"
+        },
+        "clusterID": {
+          "type": "string"
+        },
+        "external": {
+          "type": "boolean"
+        },
+        "ingressConfig": {
+          "type": "string"
+        },
+        "ingressPort": {
+          "type": "integer"
+        },
+        "labels": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "lbPort": {
+          "type": "integer"
+        },
+        "legacy": {
+          "type": "boolean"
+        },
+        "pinned": {
+          "type": "boolean"
+        },
+        "protocol": {
+          "type": "string"
+        }
+      }
+    },
+    "Allocations": {
+      "type": "object",
+      "properties": {
+        "allocations": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/Allocation"
+          }
+        }
+      }
+    },
+    "Deletions": {
+      "type": "object",
+      "properties": {
+        "deletions": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "alerted": {
+                "type": "boolean"
+              },
+              "clusterID": {
+                "type": "string"
+              },
+              "name": {
+                "type": "string"
+              },
+              "namespace": {
+                "type": "string"
+              },
+              "pendingSeconds": {
+                "type": "integer"
+              },
+              "pods": {
+                "type": "array",
+                "items": {
+                  "type": "string"
+                }
+              },
+              "remainingPods": {
+                "type": "integer"
+              },
+              "since": {
+                "type": "string",
+                "format": "date-time"
+              }
+            }
+          }
+        }
+      }
+    },
+    "Error": {
+      "type": "object",
+      "properties": {
+        "code": {
+          "type": "string"
+        },
+        "error": {
+          "type": "string"
+        },
+        "from": {
+          "type": "string"
+        }
+      }
+    },
+    "Feature": {
+      "type": "object",
+      "properties": {
+        "kind": {
+          "type": "string",
+          "enum": [
+            "annotation",
+            "field"
+          ]
+        },
+        "name": {
+          "type": "string"
+        },
+        "since": {
+          "type": "string"
+        }
+      }
+    },
+    "Features": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "features": {
+            "type": "array",
+            "items": {
+              "$ref": "#/definitions/Feature"
+            }
+          },
+          "version": {
+            "type": "string"
+          }
+        }
+      }
+    },
+    "Healthz": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "description": {
+            "type": "string"
+          },
+          "failed": {
+            "type": "boolean"
+          },
+          "message": {
+            "type": "string"
+          },
+          "name": {
+            "type": "string"
+          }
+        }
+      }
+    },
+    "History": {
+      "type": "object",
+      "properties": {
+        "reconciliations": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "clusterID": {
+                "type": "string"
+              },
+              "error": {
+                "type": "string"
+              },
+              "failures": {
+                "type": "integer"
+              },
+              "name": {
+                "type": "string"
+              },
+              "namespace": {
+                "type": "string"
+              },
+              "outcome": {
+                "type": "string",
+                "enum": [
+                  "canceled",
+                  "failed",
+                  "succeeded"
+                ]
+              },
+              "reconciledAt": {
+                "type": "string",
+                "format": "date-time"
+              }
+            }
+          }
+        }
+      }
+    },
+    "Release": {
+      "type": "object",
+      "properties": {
+        "clusterID": {
+          "type": "string"
+        },
+        "configMaps": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "lbPort": {
+          "type": "integer"
+        },
+        "services": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      }
+    },
+    "Resync": {
+      "type": "object",
+      "properties": {
+        "ingressConfigs": {
+          "type": "integer"
+        },
+        "rate": {
+          "type": "integer"
+        }
+      }
+    },
+    "Simulation": {
+      "type": "object",
+      "properties": {
+        "configMapData": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "conflicts": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "clusterID": {
+                "type": "string"
+              },
+              "lbPort": {
+                "type": "integer"
+              }
+            }
+          }
+        },
+        "servicePorts": {
+          "type": "array",
+          "items": {
+            "type": "object"
+          }
+        },
+        "validationErrors": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        }
+      }
+    },
+    "Usage": {
+      "type": "object",
+      "properties": {
+        "clusters": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "properties": {
+              "allocations": {
+                "type": "array",
+                "items": {
+                  "type": "object",
+                  "properties": {
+                    "allocatedAt": {
+                      "type": "string",
+                      "format": "date-time"
+                    },
+                    "lbPort": {
+                      "type": "integer"
+                    },
+                    "protocol": {
+                      "type": "string"
+                    },
+                    "uptimeSeconds": {
+                      "type": "integer"
+                    }
+                  }
+                }
+              },
+              "clusterID": {
+                "type": "string"
+              },
+              "lbPorts": {
+                "type": "integer"
+              },
+              "portSeconds": {
+                "type": "integer"
+              }
+            }
+          }
+        },
+        "time": {
+          "type": "string",
+          "format": "date-time"
+        }
+      }
+    },
+    "Version": {
+      "type": "object",
+      "properties": {
+        "description": {
+          "type": "string"
+        },
+        "git_commit": {
+          "type": "string"
+        },
+        "go_version": {
+          "type": "string"
+        },
+        "name": {
+          "type": "string"
+        },
+        "os_arch": {
+          "type": "string"
+        },
+        "source": {
+          "type": "string"
+        },
+        "version_bundles": {
+          "type": "array",
+          "items": {
+            "type": "object"
+          }
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,72 @@
+package swagger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/microendpoint/endpoint/healthz"
+	"github.com/giantswarm/microendpoint/endpoint/version"
+
+	"github.com/giantswarm/ingress-operator/server/endpoint/allocations"
+	"github.com/giantswarm/ingress-operator/server/endpoint/deletions"
+	"github.com/giantswarm/ingress-operator/server/endpoint/export"
+	"github.com/giantswarm/ingress-operator/server/endpoint/features"
+	"github.com/giantswarm/ingress-operator/server/endpoint/history"
+	"github.com/giantswarm/ingress-operator/server/endpoint/release"
+	"github.com/giantswarm/ingress-operator/server/endpoint/resync"
+	"github.com/giantswarm/ingress-operator/server/endpoint/simulate"
+	"github.com/giantswarm/ingress-operator/server/endpoint/usage"
+)
+
+type testSpec struct {
+	Swagger string                                `json:"swagger"`
+	Paths   map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+// Test_Spec ensures the OpenAPI document is valid JSON and documents all
+// endpoints registered in the server. Endpoints added to the server have to
+// be added here and to the document.
+func Test_Spec(t *testing.T) {
+	var spec testSpec
+	err := json.Unmarshal([]byte(Spec), &spec)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	if spec.Swagger != "2.0" {
+		t.Fatal("expected", "2.0", "got", spec.Swagger)
+	}
+
+	testCases := []struct {
+		Method string
+		Path   string
+	}{
+		{Method: allocations.Method, Path: allocations.Path},
+		{Method: deletions.Method, Path: deletions.Path},
+		{Method: export.Method, Path: export.Path},
+		{Method: features.Method, Path: features.Path},
+		{Method: healthz.Method, Path: healthz.Path},
+		{Method: history.Method, Path: history.Path},
+		{Method: release.Method, Path: release.Path},
+		{Method: resync.Method, Path: resync.Path},
+		{Method: simulate.Method, Path: simulate.Path},
+		{Method: usage.Method, Path: usage.Path},
+		{Method: Method, Path: Path},
+		{Method: version.Method, Path: version.Path},
+	}
+
+	for i, tc := range testCases {
+		methods, ok := spec.Paths[tc.Path]
+		if !ok {
+			t.Fatal("test", i, "expected", tc.Path, "got", nil)
+		}
+		if _, ok := methods[strings.ToLower(tc.Method)]; !ok {
+			t.Fatal("test", i, "expected", tc.Method, "got", nil)
+		}
+	}
+
+	if len(spec.Paths) != len(testCases) {
+		t.Fatal("expected", len(testCases), "got", len(spec.Paths))
+	}
+}
@@ -0,0 +1,369 @@
+// Package admin implements the middleware guarding administrative endpoints,
+// i.e. endpoints modifying state shared by all guest clusters or triggering
+// work of the operator. Requests authenticate either with a bearer token in
+// their authorization header or with a client certificate issued by the
+// configured client CA. Every principal has roles, and every endpoint allows
+// certain roles. Administrative endpoints are disabled in case neither tokens
+// nor a client CA are configured.
+package admin
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+)
+
+const (
+	bearerPrefix = "Bearer "
+)
+
+// Role is a set of endpoints a principal is allowed to call.
+type Role string
+
+const (
+	// RoleAdmin is allowed to call all administrative endpoints, e.g. force
+	// releasing LB ports.
+	RoleAdmin Role = "admin"
+	// RoleOperator is allowed to call the endpoints triggering work of the
+	// operator, e.g. resyncs, but not the ones modifying shared state.
+	RoleOperator Role = "operator"
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Name identifies the principal in logs. It is the common name of client
+	// certificates.
+	Name  string
+	Roles []Role
+}
+
+// has returns whether the principal is allowed to call an endpoint allowing
+// the given roles. Admins are allowed to call all endpoints.
+func (p Principal) has(allowed []Role) bool {
+	for _, r := range p.Roles {
+		if r == RoleAdmin {
+			return true
+		}
+		for _, a := range allowed {
+			if r == a {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ParseTokens parses the content of a token file. Every line holds a token
+// optionally followed by a comma separated list of roles, e.g.
+// "s3cr3t operator". Tokens without roles are admin tokens, so that files
+// holding a single token keep granting admin access. Empty lines and lines
+// starting with # are ignored.
+func ParseTokens(content string) (map[string]Principal, error) {
+	tokens := map[string]Principal{}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, microerror.Maskf(invalidConfigError, "line %d of the token file must hold a token optionally followed by roles", n)
+		}
+
+		roles := []Role{RoleAdmin}
+		if len(fields) == 2 {
+			var err error
+			roles, err = parseRoles(strings.Split(fields[1], ","))
+			if err != nil {
+				return nil, microerror.Maskf(invalidConfigError, "line %d of the token file: %s", n, err.Error())
+			}
+		}
+
+		if _, ok := tokens[fields[0]]; ok {
+			return nil, microerror.Maskf(invalidConfigError, "line %d of the token file duplicates a token", n)
+		}
+		tokens[fields[0]] = Principal{
+			Name:  fmt.Sprintf("token of line %d", n),
+			Roles: roles,
+		}
+	}
+
+	return tokens, nil
+}
+
+// Config represents the configuration used to create a new admin middleware.
+type Config struct {
+	// Dependencies.
+	Logger micrologger.Logger
+
+	// Settings.
+
+	// ClientCAs are the CAs client certificates have to be issued by. The
+	// organizations of client certificates are their roles. Client certificates
+	// are not accepted in case it is nil.
+	ClientCAs *x509.CertPool
+	// ClientCertHeader is the request header a TLS terminating proxy forwards
+	// the client certificate of the request in, URL encoded in PEM format, e.g.
+	// X-Client-Cert for nginx' $ssl_client_escaped_cert. Client certificates
+	// are only taken from the TLS connection in case it is empty. The header
+	// must only be configured in case the operator is reachable through the
+	// proxy only, which has to verify the client's possession of the key.
+	ClientCertHeader string
+	// Token is an admin token requests can provide. It is kept for
+	// compatibility with single token setups and merged into Tokens.
+	Token string
+	// Tokens are the tokens requests can provide, mapped to their principals.
+	Tokens map[string]Principal
+}
+
+// DefaultConfig provides a default configuration to create a new admin
+// middleware by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		Logger: nil,
+
+		// Settings.
+		ClientCAs:        nil,
+		ClientCertHeader: "",
+		Token:            "",
+		Tokens:           nil,
+	}
+}
+
+// New creates a new configured admin middleware.
+func New(config Config) (*Middleware, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	tokens := map[string]Principal{}
+	for t, p := range config.Tokens {
+		tokens[t] = p
+	}
+	if config.Token != "" {
+		tokens[config.Token] = Principal{Name: "admin token", Roles: []Role{RoleAdmin}}
+	}
+
+	newMiddleware := &Middleware{
+		logger: config.Logger,
+
+		clientCAs:        config.ClientCAs,
+		clientCertHeader: config.ClientCertHeader,
+		tokens:           tokens,
+	}
+
+	return newMiddleware, nil
+}
+
+// Middleware implements the admin middleware.
+type Middleware struct {
+	logger micrologger.Logger
+
+	clientCAs        *x509.CertPool
+	clientCertHeader string
+	tokens           map[string]Principal
+}
+
+type clientCertificatesKey struct{}
+
+// TLSConfig returns a copy of the given server TLS configuration requesting
+// client certificates issued by the configured CAs. Requests without client
+// certificate are still accepted, so that they can authenticate with bearer
+// tokens. Client certificates are not requested in case no CAs are configured.
+func (m *Middleware) TLSConfig(base *tls.Config) *tls.Config {
+	c := base.Clone()
+	if m.clientCAs != nil {
+		c.ClientAuth = tls.VerifyClientCertIfGiven
+		c.ClientCAs = m.clientCAs
+	}
+
+	return c
+}
+
+// RequestFunc returns the request function populating the request context
+// with the client certificates of the request, taken from the TLS connection
+// or the configured header. It has to be configured for the server, so that
+// the middleware can authenticate client certificates.
+func (m *Middleware) RequestFunc() kithttp.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+			return context.WithValue(ctx, clientCertificatesKey{}, r.TLS.PeerCertificates)
+		}
+
+		if m.clientCertHeader == "" || r.Header.Get(m.clientCertHeader) == "" {
+			return ctx
+		}
+
+		certificates, err := parseCertificates(r.Header.Get(m.clientCertHeader))
+		if err != nil {
+			m.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("ignoring malformed client certificate header: %s", err.Error()))
+			return ctx
+		}
+
+		return context.WithValue(ctx, clientCertificatesKey{}, certificates)
+	}
+}
+
+// Middleware returns the endpoint middleware authorizing requests of
+// principals having one of the given roles. Only admins are authorized in case
+// no role is given. It relies on the authorization header being populated in
+// the request context by kithttp.PopulateRequestContext and the client
+// certificates being populated by RequestFunc.
+func (m *Middleware) Middleware(allowed ...Role) kitendpoint.Middleware {
+	return func(next kitendpoint.Endpoint) kitendpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			err := m.authorize(ctx, allowed)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+func (m *Middleware) authorize(ctx context.Context, allowed []Role) error {
+	if len(m.tokens) == 0 && m.clientCAs == nil {
+		return microerror.Maskf(disabledError, "administrative endpoints are disabled since neither admin tokens nor a client CA are configured")
+	}
+
+	principal, err := m.authenticate(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !principal.has(allowed) {
+		m.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("rejected request of %s to administrative endpoint lacking role", principal.Name))
+		return microerror.Maskf(forbiddenError, "%s must have one of the roles %s", principal.Name, rolesString(allowed))
+	}
+
+	return nil
+}
+
+// authenticate returns the principal of the request. Bearer tokens take
+// precedence over client certificates.
+func (m *Middleware) authenticate(ctx context.Context) (Principal, error) {
+	header, _ := ctx.Value(kithttp.ContextKeyRequestAuthorization).(string)
+	if strings.HasPrefix(header, bearerPrefix) {
+		token := strings.TrimPrefix(header, bearerPrefix)
+		for t, p := range m.tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				return p, nil
+			}
+		}
+
+		m.logger.LogCtx(ctx, "level", "warning", "message", "rejected request to administrative endpoint with invalid admin token")
+		return Principal{}, microerror.Maskf(unauthorizedError, "request must provide a valid admin token as bearer token or a valid client certificate")
+	}
+
+	certificates, _ := ctx.Value(clientCertificatesKey{}).([]*x509.Certificate)
+	if m.clientCAs != nil && len(certificates) > 0 {
+		intermediates := x509.NewCertPool()
+		for _, c := range certificates[1:] {
+			intermediates.AddCert(c)
+		}
+
+		_, err := certificates[0].Verify(x509.VerifyOptions{
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			Roots:         m.clientCAs,
+		})
+		if err != nil {
+			m.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("rejected request to administrative endpoint with invalid client certificate: %s", err.Error()))
+			return Principal{}, microerror.Maskf(unauthorizedError, "request must provide a valid admin token as bearer token or a valid client certificate")
+		}
+
+		// Unknown organizations are ignored, since client certificates are
+		// usually issued for other purposes as well.
+		var roles []Role
+		for _, o := range certificates[0].Subject.Organization {
+			r, err := parseRoles([]string{o})
+			if err == nil {
+				roles = append(roles, r...)
+			}
+		}
+
+		principal := Principal{
+			Name:  fmt.Sprintf("client certificate %q", certificates[0].Subject.CommonName),
+			Roles: roles,
+		}
+
+		return principal, nil
+	}
+
+	return Principal{}, microerror.Maskf(unauthorizedError, "request must provide a valid admin token as bearer token or a valid client certificate")
+}
+
+// parseCertificates parses the given URL encoded PEM certificates. The first
+// certificate is the client certificate, the others are intermediates.
+func parseCertificates(escaped string) ([]*x509.Certificate, error) {
+	b, err := url.QueryUnescape(escaped)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var certificates []*x509.Certificate
+	rest := []byte(b)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		certificates = append(certificates, c)
+	}
+
+	if len(certificates) == 0 {
+		return nil, microerror.Maskf(invalidCertificateError, "no PEM encoded certificate found")
+	}
+
+	return certificates, nil
+}
+
+func parseRoles(list []string) ([]Role, error) {
+	var roles []Role
+	for _, s := range list {
+		switch r := Role(strings.TrimSpace(s)); r {
+		case RoleAdmin, RoleOperator:
+			roles = append(roles, r)
+		default:
+			return nil, microerror.Maskf(invalidConfigError, "role must be %s or %s, got %q", RoleAdmin, RoleOperator, s)
+		}
+	}
+
+	return roles, nil
+}
+
+func rolesString(allowed []Role) string {
+	names := []string{string(RoleAdmin)}
+	for _, r := range allowed {
+		if r != RoleAdmin {
+			names = append(names, string(r))
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
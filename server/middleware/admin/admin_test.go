@@ -0,0 +1,405 @@
+package admin
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	kithttp "github.com/go-kit/kit/transport/http"
+)
+
+// newTestCertificate returns a certificate with the given subject signed by
+// the given parent, or a self signed CA in case parent is nil.
+func newTestCertificate(t *testing.T, subject pkix.Name, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent = template
+		parentKey = key
+	}
+
+	b, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	c, err := x509.ParseCertificate(b)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return c, key
+}
+
+func Test_Admin_Middleware(t *testing.T) {
+	testCases := []struct {
+		Token         string
+		Authorization string
+		ErrorMatcher  func(error) bool
+	}{
+		// Test 0 ensures requests with the admin token are authorized.
+		{
+			Token:         "secret",
+			Authorization: "Bearer secret",
+			ErrorMatcher:  nil,
+		},
+
+		// Test 1 ensures requests with another token are rejected.
+		{
+			Token:         "secret",
+			Authorization: "Bearer other",
+			ErrorMatcher:  IsUnauthorized,
+		},
+
+		// Test 2 ensures requests without token are rejected.
+		{
+			Token:         "secret",
+			Authorization: "",
+			ErrorMatcher:  IsUnauthorized,
+		},
+
+		// Test 3 ensures requests with other authorization schemes are
+		// rejected.
+		{
+			Token:         "secret",
+			Authorization: "Basic secret",
+			ErrorMatcher:  IsUnauthorized,
+		},
+
+		// Test 4 ensures all requests are rejected in case no admin token is
+		// configured.
+		{
+			Token:         "",
+			Authorization: "Bearer ",
+			ErrorMatcher:  IsDisabled,
+		},
+	}
+
+	for i, tc := range testCases {
+		c := DefaultConfig()
+		c.Logger = microloggertest.New()
+		c.Token = tc.Token
+
+		m, err := New(c)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		var called bool
+		next := func(ctx context.Context, request interface{}) (interface{}, error) {
+			called = true
+			return nil, nil
+		}
+
+		ctx := context.WithValue(context.Background(), kithttp.ContextKeyRequestAuthorization, tc.Authorization)
+
+		_, err = m.Middleware()(next)(ctx, nil)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if called != (tc.ErrorMatcher == nil) {
+			t.Fatal("test", i, "expected", tc.ErrorMatcher == nil, "got", called)
+		}
+	}
+}
+
+func Test_Admin_ParseTokens(t *testing.T) {
+	testCases := []struct {
+		Content       string
+		ExpectedRoles map[string][]Role
+		ErrorMatcher  func(error) bool
+	}{
+		// Test 0 ensures a single token is an admin token.
+		{
+			Content:       "secret\n",
+			ExpectedRoles: map[string][]Role{"secret": {RoleAdmin}},
+			ErrorMatcher:  nil,
+		},
+
+		// Test 1 ensures roles of tokens are parsed and comments are ignored.
+		{
+			Content:       "# resync automation\nresync operator\n\nroot admin,operator\n",
+			ExpectedRoles: map[string][]Role{"resync": {RoleOperator}, "root": {RoleAdmin, RoleOperator}},
+			ErrorMatcher:  nil,
+		},
+
+		// Test 2 ensures unknown roles are rejected.
+		{
+			Content:      "secret viewer",
+			ErrorMatcher: IsInvalidConfig,
+		},
+
+		// Test 3 ensures duplicated tokens are rejected.
+		{
+			Content:      "secret\nsecret operator",
+			ErrorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		tokens, err := ParseTokens(tc.Content)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil {
+			if !tc.ErrorMatcher(err) {
+				t.Fatal("test", i, "expected", true, "got", false)
+			}
+			continue
+		}
+
+		if len(tokens) != len(tc.ExpectedRoles) {
+			t.Fatal("test", i, "expected", len(tc.ExpectedRoles), "got", len(tokens))
+		}
+		for token, roles := range tc.ExpectedRoles {
+			if len(tokens[token].Roles) != len(roles) {
+				t.Fatal("test", i, "expected", roles, "got", tokens[token].Roles)
+			}
+			for j := range roles {
+				if tokens[token].Roles[j] != roles[j] {
+					t.Fatal("test", i, "expected", roles, "got", tokens[token].Roles)
+				}
+			}
+		}
+	}
+}
+
+func Test_Admin_Middleware_roles(t *testing.T) {
+	ca, caKey := newTestCertificate(t, pkix.Name{CommonName: "ca"}, nil, nil)
+	otherCA, otherCAKey := newTestCertificate(t, pkix.Name{CommonName: "other-ca"}, nil, nil)
+
+	operatorCert, _ := newTestCertificate(t, pkix.Name{CommonName: "automation", Organization: []string{"operator", "unrelated"}}, ca, caKey)
+	adminCert, _ := newTestCertificate(t, pkix.Name{CommonName: "oncall", Organization: []string{"admin"}}, ca, caKey)
+	foreignCert, _ := newTestCertificate(t, pkix.Name{CommonName: "foreign", Organization: []string{"admin"}}, otherCA, otherCAKey)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+
+	testCases := []struct {
+		Allowed       []Role
+		Authorization string
+		Certificate   *x509.Certificate
+		ErrorMatcher  func(error) bool
+	}{
+		// Test 0 ensures operator tokens are authorized for endpoints allowing
+		// operators.
+		{
+			Allowed:       []Role{RoleOperator},
+			Authorization: "Bearer resync",
+			ErrorMatcher:  nil,
+		},
+
+		// Test 1 ensures operator tokens are forbidden for admin endpoints.
+		{
+			Allowed:       nil,
+			Authorization: "Bearer resync",
+			ErrorMatcher:  IsForbidden,
+		},
+
+		// Test 2 ensures admin tokens are authorized for all endpoints.
+		{
+			Allowed:       []Role{RoleOperator},
+			Authorization: "Bearer root",
+			ErrorMatcher:  nil,
+		},
+
+		// Test 3 ensures client certificates are authorized by their
+		// organizations.
+		{
+			Allowed:      []Role{RoleOperator},
+			Certificate:  operatorCert,
+			ErrorMatcher: nil,
+		},
+
+		// Test 4 ensures client certificates lacking the role are forbidden.
+		{
+			Allowed:      []Role{RoleAdmin},
+			Certificate:  operatorCert,
+			ErrorMatcher: IsForbidden,
+		},
+
+		// Test 5 ensures admin client certificates are authorized.
+		{
+			Allowed:      []Role{RoleAdmin},
+			Certificate:  adminCert,
+			ErrorMatcher: nil,
+		},
+
+		// Test 6 ensures client certificates of other CAs are rejected.
+		{
+			Allowed:      []Role{RoleAdmin},
+			Certificate:  foreignCert,
+			ErrorMatcher: IsUnauthorized,
+		},
+
+		// Test 7 ensures invalid tokens are rejected even with a valid client
+		// certificate, since tokens take precedence.
+		{
+			Allowed:       []Role{RoleAdmin},
+			Authorization: "Bearer other",
+			Certificate:   adminCert,
+			ErrorMatcher:  IsUnauthorized,
+		},
+	}
+
+	for i, tc := range testCases {
+		c := DefaultConfig()
+		c.Logger = microloggertest.New()
+		c.ClientCAs = clientCAs
+		c.ClientCertHeader = "X-Client-Cert"
+		c.Tokens = map[string]Principal{
+			"resync": {Name: "resync", Roles: []Role{RoleOperator}},
+			"root":   {Name: "root", Roles: []Role{RoleAdmin}},
+		}
+
+		m, err := New(c)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		r, err := http.NewRequest("POST", "/resync", nil)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		r.Header.Set("Authorization", tc.Authorization)
+		if tc.Certificate != nil {
+			b := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tc.Certificate.Raw})
+			r.Header.Set("X-Client-Cert", url.QueryEscape(string(b)))
+		}
+
+		ctx := kithttp.PopulateRequestContext(context.Background(), r)
+		ctx = m.RequestFunc()(ctx, r)
+
+		next := func(ctx context.Context, request interface{}) (interface{}, error) {
+			return nil, nil
+		}
+
+		_, err = m.Middleware(tc.Allowed...)(next)(ctx, nil)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+	}
+}
+
+func Test_Admin_TLSConfig(t *testing.T) {
+	ca, caKey := newTestCertificate(t, pkix.Name{CommonName: "ca"}, nil, nil)
+	otherCA, otherCAKey := newTestCertificate(t, pkix.Name{CommonName: "other-ca"}, nil, nil)
+
+	adminCert, adminKey := newTestCertificate(t, pkix.Name{CommonName: "oncall", Organization: []string{"admin"}}, ca, caKey)
+	foreignCert, foreignKey := newTestCertificate(t, pkix.Name{CommonName: "foreign", Organization: []string{"admin"}}, otherCA, otherCAKey)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+
+	testCases := []struct {
+		Certificate  *tls.Certificate
+		ExpectedCode int
+		HandshakeErr bool
+	}{
+		// Test 0 ensures client certificates issued by the client CA are taken
+		// from the TLS connection and authorized.
+		{
+			Certificate:  &tls.Certificate{Certificate: [][]byte{adminCert.Raw}, PrivateKey: adminKey},
+			ExpectedCode: http.StatusOK,
+		},
+
+		// Test 1 ensures connections without client certificate are accepted,
+		// so that requests can still authenticate with tokens.
+		{
+			Certificate:  nil,
+			ExpectedCode: http.StatusUnauthorized,
+		},
+
+		// Test 2 ensures client certificates of other CAs are rejected during
+		// the handshake.
+		{
+			Certificate:  &tls.Certificate{Certificate: [][]byte{foreignCert.Raw}, PrivateKey: foreignKey},
+			HandshakeErr: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		c := DefaultConfig()
+		c.Logger = microloggertest.New()
+		c.ClientCAs = clientCAs
+
+		m, err := New(c)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := kithttp.PopulateRequestContext(r.Context(), r)
+			ctx = m.RequestFunc()(ctx, r)
+
+			next := func(ctx context.Context, request interface{}) (interface{}, error) {
+				return nil, nil
+			}
+
+			_, err := m.Middleware(RoleAdmin)(next)(ctx, nil)
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+
+		s := httptest.NewUnstartedServer(handler)
+		s.TLS = m.TLSConfig(&tls.Config{})
+		s.StartTLS()
+
+		client := s.Client()
+		client.Transport.(*http.Transport).TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if tc.Certificate == nil {
+				return &tls.Certificate{}, nil
+			}
+			return tc.Certificate, nil
+		}
+
+		res, err := client.Get(s.URL)
+		s.Close()
+		if tc.HandshakeErr {
+			if err == nil {
+				t.Fatal("test", i, "expected", "handshake error", "got", nil)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != tc.ExpectedCode {
+			t.Fatal("test", i, "expected", tc.ExpectedCode, "got", res.StatusCode)
+		}
+	}
+}
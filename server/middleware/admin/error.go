@@ -0,0 +1,50 @@
+package admin
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var disabledError = &microerror.Error{
+	Kind: "disabledError",
+}
+
+// IsDisabled asserts disabledError.
+func IsDisabled(err error) bool {
+	return microerror.Cause(err) == disabledError
+}
+
+var unauthorizedError = &microerror.Error{
+	Kind: "unauthorizedError",
+}
+
+// IsUnauthorized asserts unauthorizedError.
+func IsUnauthorized(err error) bool {
+	return microerror.Cause(err) == unauthorizedError
+}
+
+var forbiddenError = &microerror.Error{
+	Kind: "forbiddenError",
+}
+
+// IsForbidden asserts forbiddenError.
+func IsForbidden(err error) bool {
+	return microerror.Cause(err) == forbiddenError
+}
+
+var invalidCertificateError = &microerror.Error{
+	Kind: "invalidCertificateError",
+}
+
+// IsInvalidCertificate asserts invalidCertificateError.
+func IsInvalidCertificate(err error) bool {
+	return microerror.Cause(err) == invalidCertificateError
+}
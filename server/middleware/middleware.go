@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"crypto/x509"
+
+	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
+	"github.com/giantswarm/ingress-operator/server/middleware/admin"
 	"github.com/giantswarm/ingress-operator/service"
 )
 
@@ -11,6 +15,11 @@ type Config struct {
 	// Dependencies.
 	Logger  micrologger.Logger
 	Service *service.Service
+
+	// Settings.
+	AdminClientCAs        *x509.CertPool
+	AdminClientCertHeader string
+	AdminTokens           map[string]admin.Principal
 }
 
 // DefaultConfig provides a default configuration to create a new
@@ -20,14 +29,39 @@ func DefaultConfig() Config {
 		// Dependencies.
 		Logger:  nil,
 		Service: nil,
+
+		// Settings.
+		AdminClientCAs:        nil,
+		AdminClientCertHeader: "",
+		AdminTokens:           nil,
 	}
 }
 
 // New creates a new configured middleware.
 func New(config Config) (*Middleware, error) {
-	return &Middleware{}, nil
+	var err error
+
+	var adminMiddleware *admin.Middleware
+	{
+		adminConfig := admin.DefaultConfig()
+		adminConfig.Logger = config.Logger
+		adminConfig.ClientCAs = config.AdminClientCAs
+		adminConfig.ClientCertHeader = config.AdminClientCertHeader
+		adminConfig.Tokens = config.AdminTokens
+		adminMiddleware, err = admin.New(adminConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	newMiddleware := &Middleware{
+		Admin: adminMiddleware,
+	}
+
+	return newMiddleware, nil
 }
 
 // Middleware is middleware collection.
 type Middleware struct {
+	Admin *admin.Middleware
 }
@@ -4,17 +4,30 @@ package server
 
 import (
 	"context"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"sync"
 
 	"github.com/giantswarm/microerror"
 	microserver "github.com/giantswarm/microkit/server"
+	microtls "github.com/giantswarm/microkit/tls"
 	"github.com/giantswarm/micrologger"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
 	"github.com/spf13/viper"
 
 	"github.com/giantswarm/ingress-operator/server/endpoint"
+	allocationsendpoint "github.com/giantswarm/ingress-operator/server/endpoint/allocations"
+	exportendpoint "github.com/giantswarm/ingress-operator/server/endpoint/export"
+	releaseendpoint "github.com/giantswarm/ingress-operator/server/endpoint/release"
+	"github.com/giantswarm/ingress-operator/server/endpoint/simulate"
 	"github.com/giantswarm/ingress-operator/server/middleware"
+	"github.com/giantswarm/ingress-operator/server/middleware/admin"
 	"github.com/giantswarm/ingress-operator/service"
+	"github.com/giantswarm/ingress-operator/service/release"
+	"github.com/giantswarm/ingress-operator/service/resync"
 )
 
 // Config represents the configuration used to create a new server object.
@@ -23,14 +36,39 @@ type Config struct {
 	Service *service.Service
 	Viper   *viper.Viper
 
-	ProjectName string
+	// AdminClientCAFile is the path of the file holding the PEM encoded CAs
+	// client certificates authenticating requests to administrative endpoints
+	// have to be issued by. Client certificates are not accepted in case it is
+	// empty.
+	AdminClientCAFile string
+	// AdminClientCertHeader is the request header a TLS terminating proxy
+	// forwards client certificates in. See the admin middleware.
+	AdminClientCertHeader string
+	// AdminListenAddress is the host and port of the TLS listener serving all
+	// endpoints with client certificates being requested, e.g. :8443. The
+	// microkit server does not terminate TLS itself, so that client
+	// certificates are only taken from the TLS connection in case it is
+	// configured. It requires TLSCrtFile and TLSKeyFile.
+	AdminListenAddress string
+	// AdminTokenFile is the path of the file holding the tokens accepted by
+	// administrative endpoints. See admin.ParseTokens for its format.
+	// Administrative endpoints are disabled in case neither it nor the client CA
+	// file is configured.
+	AdminTokenFile string
+	ProjectName    string
+	// TLSCrtFile is the path of the certificate file of the admin listener.
+	TLSCrtFile string
+	// TLSKeyFile is the path of the key file of the admin listener.
+	TLSKeyFile string
 }
 
 type Server struct {
 	// Dependencies.
-	logger micrologger.Logger
+	logger  micrologger.Logger
+	service *service.Service
 
 	// Internals.
+	adminServer  *http.Server
 	bootOnce     sync.Once
 	config       microserver.Config
 	shutdownOnce sync.Once
@@ -51,12 +89,42 @@ func New(config Config) (*Server, error) {
 	if config.ProjectName == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.ProjectName must not be empty", config)
 	}
+	if config.AdminListenAddress != "" && (config.TLSCrtFile == "" || config.TLSKeyFile == "") {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TLSCrtFile and %T.TLSKeyFile must not be empty in case %T.AdminListenAddress is configured", config, config, config)
+	}
 
 	var err error
 
+	var adminTokens map[string]admin.Principal
+	if config.AdminTokenFile != "" {
+		b, err := ioutil.ReadFile(config.AdminTokenFile)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		adminTokens, err = admin.ParseTokens(string(b))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var adminClientCAs *x509.CertPool
+	if config.AdminClientCAFile != "" {
+		b, err := ioutil.ReadFile(config.AdminClientCAFile)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		adminClientCAs = x509.NewCertPool()
+		if !adminClientCAs.AppendCertsFromPEM(b) {
+			return nil, microerror.Maskf(invalidConfigError, "%T.AdminClientCAFile must hold PEM encoded certificates", config)
+		}
+	}
+
 	var middlewareCollection *middleware.Middleware
 	{
 		middlewareConfig := middleware.DefaultConfig()
+		middlewareConfig.AdminClientCAs = adminClientCAs
+		middlewareConfig.AdminClientCertHeader = config.AdminClientCertHeader
+		middlewareConfig.AdminTokens = adminTokens
 		middlewareConfig.Logger = config.Logger
 		middlewareConfig.Service = config.Service
 		middlewareCollection, err = middleware.New(middlewareConfig)
@@ -77,22 +145,62 @@ func New(config Config) (*Server, error) {
 		}
 	}
 
+	// The router is shared with the microkit server, which registers all
+	// endpoints when it boots, so that the admin listener serves them as well.
+	router := mux.NewRouter()
+
+	var adminServer *http.Server
+	if config.AdminListenAddress != "" {
+		tlsConfig, err := microtls.LoadTLSConfig(microtls.CertFiles{
+			Cert: config.TLSCrtFile,
+			Key:  config.TLSKeyFile,
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		adminServer = &http.Server{
+			Addr:      config.AdminListenAddress,
+			Handler:   router,
+			TLSConfig: middlewareCollection.Admin.TLSConfig(tlsConfig),
+		}
+	}
+
 	s := &Server{
 		// Dependencies.
-		logger: config.Logger,
+		logger:  config.Logger,
+		service: config.Service,
 
 		// Internals.
-		bootOnce: sync.Once{},
+		adminServer: adminServer,
+		bootOnce:    sync.Once{},
 		config: microserver.Config{
 			Logger:      config.Logger,
+			Router:      router,
 			ServiceName: config.ProjectName,
 			Viper:       config.Viper,
 
 			Endpoints: []microserver.Endpoint{
+				endpointCollection.Allocations,
+				endpointCollection.Deletions,
+				endpointCollection.Export,
+				endpointCollection.Features,
 				endpointCollection.Healthz,
+				endpointCollection.History,
+				endpointCollection.Release,
+				endpointCollection.Resync,
+				endpointCollection.Simulate,
+				endpointCollection.Swagger,
+				endpointCollection.Usage,
 				endpointCollection.Version,
 			},
 			ErrorEncoder: errorEncoder,
+			// The request context is populated with the request headers and
+			// client certificates, so that middlewares can authorize requests.
+			RequestFuncs: []kithttp.RequestFunc{
+				kithttp.PopulateRequestContext,
+				middlewareCollection.Admin.RequestFunc(),
+			},
 		},
 		shutdownOnce: sync.Once{},
 	}
@@ -102,8 +210,19 @@ func New(config Config) (*Server, error) {
 
 func (s *Server) Boot() {
 	s.bootOnce.Do(func() {
-		// Here goes your custom boot logic for your server/endpoint/middleware, if
-		// any.
+		if s.adminServer == nil {
+			return
+		}
+
+		go func() {
+			s.logger.Log("level", "debug", "message", fmt.Sprintf("running admin server at https://%s", s.adminServer.Addr))
+
+			// The certificate is part of the TLS configuration already.
+			err := s.adminServer.ListenAndServeTLS("", "")
+			if err != nil && err != http.ErrServerClosed {
+				s.logger.Log("level", "error", "message", "running admin server failed", "stack", fmt.Sprintf("%#v", err))
+			}
+		}()
 	})
 }
 
@@ -113,13 +232,64 @@ func (s *Server) Config() microserver.Config {
 
 func (s *Server) Shutdown() {
 	s.shutdownOnce.Do(func() {
-		// Here goes your custom shutdown logic for your server/endpoint/middleware,
-		// if any.
+		if s.adminServer != nil {
+			err := s.adminServer.Shutdown(context.Background())
+			if err != nil {
+				s.logger.Log("level", "error", "message", "shutting down admin server failed", "stack", fmt.Sprintf("%#v", err))
+			}
+		}
+
+		s.logger.Log("level", "debug", "message", "shutting down service")
+		s.service.Shutdown()
+		s.logger.Log("level", "debug", "message", "shut down service")
 	})
 }
 
 func errorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
 	rErr := err.(microserver.ResponseError)
+
+	if resync.IsResyncInProgress(rErr.Underlying()) {
+		rErr.SetCode(microserver.CodeTooManyRequests)
+		rErr.SetMessage("A resync is already in progress. Try again once it finished.")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	if admin.IsDisabled(rErr.Underlying()) {
+		rErr.SetCode(microserver.CodePermissionDenied)
+		rErr.SetMessage(rErr.Underlying().Error())
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if admin.IsForbidden(rErr.Underlying()) {
+		rErr.SetCode(microserver.CodePermissionDenied)
+		rErr.SetMessage(rErr.Underlying().Error())
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if admin.IsUnauthorized(rErr.Underlying()) {
+		rErr.SetCode(microserver.CodeInvalidCredentials)
+		rErr.SetMessage(rErr.Underlying().Error())
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if release.IsPortOwned(rErr.Underlying()) {
+		rErr.SetCode(microserver.CodeFailure)
+		rErr.SetMessage(rErr.Underlying().Error())
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	if allocationsendpoint.IsInvalidRequest(rErr.Underlying()) || simulate.IsInvalidRequest(rErr.Underlying()) || releaseendpoint.IsInvalidRequest(rErr.Underlying()) || exportendpoint.IsInvalidRequest(rErr.Underlying()) {
+		rErr.SetCode(microserver.CodeInvalidInput)
+		rErr.SetMessage(rErr.Underlying().Error())
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
 	rErr.SetCode(microserver.CodeInternalError)
 	rErr.SetMessage("An unexpected error occurred. Sorry for the inconvenience.")
 	w.WriteHeader(http.StatusInternalServerError)
@@ -0,0 +1,319 @@
+// Package adoption implements the one-time adoption of legacy entries of the
+// config maps of the shared ingress controllers into the ledger. Installations
+// predating the ledger only know about LB port ownership through the TCP
+// services entries of the ingress controller config maps. Entries are
+// attributed to the IngressConfig whose guest cluster service, ingress port
+// and LB port they match. Entries which cannot be attributed are logged for
+// manual review and left alone.
+//
+// The same adoption reconstructs the ledger on boot in case it got deleted or
+// corrupted. The LB ports requested by IngressConfigs which are found on the
+// services of the ingress controllers are attributed in addition, so that
+// allocations whose config map entries got lost are recovered as well.
+// Entries found in only one of the config map and the service are logged as
+// discrepancies.
+//
+// The adoption controller keeps watching for such entries afterwards, e.g. of
+// guest clusters configured manually before the operator existed, and
+// optionally creates the IngressConfigs covering them.
+package adoption
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/configmap"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+// Config represents the configuration used to create a new adopter.
+type Config struct {
+	// G8sClient lists the IngressConfigs whose entries are adopted. In
+	// namespace-scoped mode it must only list the IngressConfigs of the
+	// configured namespaces, like the lister does.
+	G8sClient clients.IngressConfigs
+	K8sClient clients.Interface
+	Ledger    *ledger.Ledger
+	Logger    micrologger.Logger
+}
+
+// Adopter adopts legacy config map entries into the ledger.
+type Adopter struct {
+	g8sClient clients.IngressConfigs
+	k8sClient clients.Interface
+	ledger    *ledger.Ledger
+	logger    micrologger.Logger
+}
+
+// New creates a new configured adopter.
+func New(config Config) (*Adopter, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Ledger must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	a := &Adopter{
+		g8sClient: config.G8sClient,
+		k8sClient: config.K8sClient,
+		ledger:    config.Ledger,
+		logger:    config.Logger,
+	}
+
+	return a, nil
+}
+
+// configMapRef identifies the config map of a shared ingress controller.
+type configMapRef struct {
+	Name      string
+	Namespace string
+}
+
+// serviceRef identifies the service of a shared ingress controller.
+type serviceRef struct {
+	Name      string
+	Namespace string
+}
+
+// Adopt records the attributable entries of the config maps and services
+// referenced by all IngressConfigs the client lists in the ledger. It does nothing in case the
+// ledger got adopted before and is intact.
+func (a *Adopter) Adopt(ctx context.Context) error {
+	adopted, err := a.ledger.IsAdopted(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	inspection, err := a.ledger.Inspect(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if adopted && !inspection.IsCorrupted() {
+		a.logger.LogCtx(ctx, "level", "debug", "message", "ledger got adopted already")
+		return nil
+	}
+	if adopted {
+		a.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("ledger contains malformed entries %s, reconstructing them from the IngressConfigs and the ingress controllers", strings.Join(inspection.Malformed, ",")))
+	} else if inspection.Missing {
+		a.logger.LogCtx(ctx, "level", "debug", "message", "ledger does not exist, reconstructing it from the IngressConfigs and the ingress controllers")
+	} else {
+		a.logger.LogCtx(ctx, "level", "debug", "message", "adopting legacy config map entries into the ledger")
+	}
+
+	list, err := a.g8sClient.ListIngressConfigs(ctx, "")
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var refs []configMapRef
+	byRef := map[configMapRef][]v1alpha1.IngressConfig{}
+	for _, customObject := range list.Items {
+		ref := configMapRef{
+			Name:      key.IngressControllerConfigMap(customObject),
+			Namespace: key.IngressControllerNamespace(customObject),
+		}
+		if _, ok := byRef[ref]; !ok {
+			refs = append(refs, ref)
+		}
+		byRef[ref] = append(byRef[ref], customObject)
+	}
+
+	// attributed are the LB ports attributed from config map entries, by the
+	// namespace of the ingress controller.
+	attributed := map[string]map[int]bool{}
+
+	var allocations []ledger.Allocation
+	for _, ref := range refs {
+		configMap, err := a.k8sClient.GetConfigMap(ctx, ref.Namespace, ref.Name)
+		if clients.IsNotFound(err) {
+			a.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find config map %s/%s", ref.Namespace, ref.Name))
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		var keys []string
+		for k := range configMap.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			v := configMap.Data[k]
+
+			allocation, ok := attribute(byRef[ref], k, v)
+			if !ok {
+				a.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("cannot attribute entry %s=%s of config map %s/%s to any ingress config, review manually", k, v, ref.Namespace, ref.Name))
+				continue
+			}
+
+			allocations = append(allocations, allocation)
+			if attributed[ref.Namespace] == nil {
+				attributed[ref.Namespace] = map[int]bool{}
+			}
+			attributed[ref.Namespace][allocation.LBPort] = true
+		}
+	}
+
+	fromServices, err := a.attributeServices(ctx, list.Items, attributed)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	allocations = append(allocations, fromServices...)
+
+	recorded, err := a.ledger.Adopt(ctx, allocations)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, r := range recorded {
+		a.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("adopted LB port %d of guest cluster %s into the ledger", r.LBPort, r.ClusterID))
+	}
+
+	a.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("adopted %d of %d attributable entries into the ledger", len(recorded), len(allocations)))
+
+	return nil
+}
+
+// attributeServices returns the allocations of the LB ports requested by the
+// given IngressConfigs which are found on the services of their ingress
+// controllers but were not attributed from config map entries. LB ports
+// requested by more than one IngressConfig of the same ingress controller
+// cannot be attributed. LB ports attributed from config map entries but
+// missing on the service are logged as discrepancy.
+func (a *Adopter) attributeServices(ctx context.Context, customObjects []v1alpha1.IngressConfig, attributed map[string]map[int]bool) ([]ledger.Allocation, error) {
+	var refs []serviceRef
+	byRef := map[serviceRef][]v1alpha1.IngressConfig{}
+	for _, customObject := range customObjects {
+		ref := serviceRef{
+			Name:      key.IngressControllerService(customObject),
+			Namespace: key.IngressControllerNamespace(customObject),
+		}
+		if _, ok := byRef[ref]; !ok {
+			refs = append(refs, ref)
+		}
+		byRef[ref] = append(byRef[ref], customObject)
+	}
+
+	var allocations []ledger.Allocation
+	for _, ref := range refs {
+		service, err := a.k8sClient.GetService(ctx, ref.Namespace, ref.Name)
+		if clients.IsNotFound(err) {
+			a.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find service %s/%s", ref.Namespace, ref.Name))
+			continue
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		servicePorts := map[int]bool{}
+		for _, p := range service.Spec.Ports {
+			servicePorts[int(p.Port)] = true
+		}
+
+		requests := map[int]int{}
+		for _, customObject := range byRef[ref] {
+			for _, p := range key.LBPorts(customObject) {
+				requests[p]++
+			}
+		}
+
+		for _, customObject := range byRef[ref] {
+			for _, p := range key.ProtocolPorts(customObject) {
+				switch {
+				case attributed[ref.Namespace][p.LBPort] && !servicePorts[p.LBPort]:
+					a.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("found LB port %d of guest cluster %s in the config map but not in service %s/%s", p.LBPort, key.ClusterID(customObject), ref.Namespace, ref.Name))
+				case attributed[ref.Namespace][p.LBPort] || !servicePorts[p.LBPort]:
+				case requests[p.LBPort] > 1:
+					a.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("cannot attribute LB port %d of service %s/%s, it is requested by %d IngressConfigs, review manually", p.LBPort, ref.Namespace, ref.Name, requests[p.LBPort]))
+				default:
+					a.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("found LB port %d of guest cluster %s in service %s/%s but not in the config map", p.LBPort, key.ClusterID(customObject), ref.Namespace, ref.Name))
+
+					allocation := ledger.Allocation{
+						ClusterID:   key.ClusterID(customObject),
+						IngressPort: p.IngressPort,
+						LBPort:      p.LBPort,
+						Protocol:    key.Protocol(p),
+					}
+					allocations = append(allocations, allocation)
+				}
+			}
+		}
+	}
+
+	return allocations, nil
+}
+
+// attribute returns the allocation of the given config map entry in case it
+// matches one of the protocol ports of the given IngressConfigs. Entries of
+// named ports reference the guest cluster service port by name, which cannot
+// be compared with the ingress port, so that they match by namespace and
+// service only.
+func attribute(customObjects []v1alpha1.IngressConfig, k, v string) (ledger.Allocation, bool) {
+	lbPort, err := strconv.Atoi(k)
+	if err != nil {
+		return ledger.Allocation{}, false
+	}
+	// LB ports passing TLS through carry the PROXY protocol suffix, which does
+	// not affect the attribution.
+	value, err := configmap.ParseDataValue(v)
+	if err != nil {
+		return ledger.Allocation{}, false
+	}
+
+	for _, customObject := range customObjects {
+		if value.Namespace != key.ClusterNamespace(customObject) || value.Service != key.ClusterService(customObject) {
+			continue
+		}
+
+		named, _ := key.NamedPorts(customObject)
+
+		for _, p := range key.ProtocolPorts(customObject) {
+			if p.LBPort != lbPort {
+				continue
+			}
+			if value.IsNamed() && !inPorts(named, p.LBPort) {
+				continue
+			}
+			if !value.IsNamed() && value.Port != strconv.Itoa(p.IngressPort) {
+				continue
+			}
+
+			allocation := ledger.Allocation{
+				ClusterID:   key.ClusterID(customObject),
+				IngressPort: p.IngressPort,
+				LBPort:      p.LBPort,
+				Protocol:    key.Protocol(p),
+			}
+
+			return allocation, true
+		}
+	}
+
+	return ledger.Allocation{}, false
+}
+
+func inPorts(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+
+	return false
+}
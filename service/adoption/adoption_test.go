@@ -0,0 +1,285 @@
+package adoption
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+func newTestIngressConfig(clusterID string, ports ...v1alpha1.IngressConfigSpecProtocolPort) v1alpha1.IngressConfig {
+	return v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterID,
+			Namespace: "default",
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        clusterID,
+				Namespace: clusterID,
+				Service:   "worker",
+			},
+			HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+				IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+					ConfigMap: "ingress-controller",
+					Namespace: "kube-system",
+					Service:   "ingress-controller",
+				},
+			},
+			ProtocolPorts: ports,
+		},
+	}
+}
+
+func Test_Adopter_Adopt(t *testing.T) {
+	ctx := context.TODO()
+
+	k8sClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-controller",
+			Namespace: "kube-system",
+		},
+		Data: map[string]string{
+			"31000": "al9qy/worker:30010",
+			"31001": "al9qy/worker:30011",
+			// The LB port is not configured for the guest cluster.
+			"31002": "al9qy/worker:30012",
+			// The guest cluster is unknown.
+			"31003": "p1l6x/worker:30010",
+			// The key is no LB port.
+			"http": "al9qy/worker:30010",
+		},
+	})
+
+	k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	var l *ledger.Ledger
+	{
+		c := ledger.Config{
+			K8sClient: k8sClients,
+			Logger:    microloggertest.New(),
+
+			Name:      "ingress-operator-allocations",
+			Namespace: "kube-system",
+		}
+
+		l, err = ledger.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	g8sClient := clientstest.NewIngressConfigs(
+		newTestIngressConfig(
+			"al9qy",
+			v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+			v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+		),
+	)
+
+	var a *Adopter
+	{
+		c := Config{
+			G8sClient: g8sClient,
+			K8sClient: k8sClients,
+			Ledger:    l,
+			Logger:    microloggertest.New(),
+		}
+
+		a, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	err = a.Adopt(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err := l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	expected := []ledger.Allocation{
+		{ClusterID: "al9qy", IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{ClusterID: "al9qy", IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+	}
+	if len(list) != len(expected) {
+		t.Fatalf("expected %#v got %#v", expected, list)
+	}
+	for i, e := range expected {
+		if list[i].ClusterID != e.ClusterID || list[i].IngressPort != e.IngressPort || list[i].LBPort != e.LBPort || list[i].Protocol != e.Protocol {
+			t.Fatal("test", i, "expected", e, "got", list[i])
+		}
+	}
+
+	// Adopting again must not touch the ledger, even when the config map
+	// entries could be attributed now.
+	ingressConfig := newTestIngressConfig(
+		"p1l6x",
+		v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30010, LBPort: 31003, Protocol: "http"},
+	)
+	_, err = g8sClient.CreateIngressConfig(ctx, ingressConfig.Namespace, &ingressConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = a.Adopt(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err = l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list) != len(expected) {
+		t.Fatalf("expected %#v got %#v", expected, list)
+	}
+}
+
+func Test_Adopter_Adopt_corrupted(t *testing.T) {
+	ctx := context.TODO()
+
+	k8sClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+			Data: map[string]string{
+				"31000": "al9qy/worker:30010",
+				// The service port is missing.
+				"31002": "p1l6x/worker:30010",
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{Port: 31000},
+					// The config map entry is missing.
+					{Port: 31001},
+					// The LB port is requested by two IngressConfigs.
+					{Port: 31003},
+				},
+			},
+		},
+	)
+
+	k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	var l *ledger.Ledger
+	{
+		c := ledger.Config{
+			K8sClient: k8sClients,
+			Logger:    microloggertest.New(),
+
+			Name:      "ingress-operator-allocations",
+			Namespace: "kube-system",
+		}
+
+		l, err = ledger.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	g8sClient := clientstest.NewIngressConfigs(
+		newTestIngressConfig(
+			"al9qy",
+			v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+			v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+		),
+		newTestIngressConfig(
+			"p1l6x",
+			v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30010, LBPort: 31002, Protocol: "http"},
+			v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30011, LBPort: 31003, Protocol: "https"},
+		),
+		newTestIngressConfig(
+			"x7a2b",
+			v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30011, LBPort: 31003, Protocol: "https"},
+		),
+	)
+
+	var a *Adopter
+	{
+		c := Config{
+			G8sClient: g8sClient,
+			K8sClient: k8sClients,
+			Ledger:    l,
+			Logger:    microloggertest.New(),
+		}
+
+		a, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	// Test 1 ensures the missing ledger is reconstructed from the config map
+	// entries and the service ports.
+	err = a.Adopt(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err := l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	assertLBPorts(t, list, 31000, 31001, 31002)
+
+	// Test 2 ensures a corrupted ledger is reconstructed, even though it got
+	// adopted before.
+	configMap, err := k8sClients.GetConfigMap(ctx, "kube-system", "ingress-operator-allocations")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	configMap.Data["31001"] = "{"
+	_, err = k8sClients.UpdateConfigMap(ctx, "kube-system", configMap)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = a.Adopt(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err = l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	assertLBPorts(t, list, 31000, 31001, 31002)
+	if list[1].ClusterID != "al9qy" || list[1].Protocol != "https" {
+		t.Fatal("expected", "https of al9qy", "got", list[1].Protocol+" of "+list[1].ClusterID)
+	}
+
+	inspection, err := l.Inspect(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if inspection.IsCorrupted() {
+		t.Fatal("expected", false, "got", true)
+	}
+}
@@ -0,0 +1,518 @@
+package adoption
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/configmap"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+const (
+	// AdoptedLabel is the label marking IngressConfigs created by the adoption
+	// controller. Only IngressConfigs carrying the label are updated with
+	// further adopted entries. IngressConfigs lacking the label are created by
+	// someone else, e.g. cluster-operator, and are never touched.
+	AdoptedLabel = "ingress-operator.giantswarm.io/adopted"
+)
+
+// ControllerConfig represents the configuration used to create a new adoption
+// controller.
+type ControllerConfig struct {
+	// Dependencies.
+	G8sClient clients.IngressConfigs
+	K8sClient clients.Interface
+	Ledger    *ledger.Ledger
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// CreateIngressConfigs is whether IngressConfigs are created and updated
+	// for the guest clusters of unowned entries. Unowned entries of guest
+	// clusters lacking a matching IngressConfig are only logged otherwise.
+	CreateIngressConfigs bool
+	// GuestClusterService is the guest cluster service entries have to forward
+	// to in order to be adopted, e.g. worker.
+	GuestClusterService string
+	// IngressControllerConfigMap is the config map of the ingress controller
+	// watched for unowned entries.
+	IngressControllerConfigMap string
+	// IngressControllerNamespace is the namespace of the ingress controller.
+	IngressControllerNamespace string
+	// IngressControllerService is the service of the ingress controller
+	// referenced by created IngressConfigs.
+	IngressControllerService string
+	// Interval is the interval in which the config map is checked.
+	Interval time.Duration
+	// Namespace is the namespace IngressConfigs are created in. They are
+	// created in the namespace of their guest cluster in case it is empty.
+	Namespace string
+	// VersionBundleVersion is the version bundle version IngressConfigs are
+	// created with.
+	VersionBundleVersion string
+}
+
+// DefaultControllerConfig provides a default configuration to create a new
+// adoption controller by best effort.
+func DefaultControllerConfig() ControllerConfig {
+	return ControllerConfig{
+		// Dependencies.
+		G8sClient: nil,
+		K8sClient: nil,
+		Ledger:    nil,
+		Logger:    nil,
+
+		// Settings.
+		CreateIngressConfigs:       false,
+		GuestClusterService:        key.DefaultClusterService,
+		IngressControllerConfigMap: "",
+		IngressControllerNamespace: "",
+		IngressControllerService:   "",
+		Interval:                   5 * time.Minute,
+		Namespace:                  "",
+		VersionBundleVersion:       "",
+	}
+}
+
+// Controller watches the config map of an ingress controller for entries of
+// known guest clusters, which are not owned by any allocation of the ledger,
+// e.g. because they got configured manually before the operator existed.
+// Entries have to match <guest cluster namespace>/<guest cluster service>:<port>
+// and their guest cluster namespace has to exist. Matching entries are
+// recorded in the ledger once an IngressConfig covers them, so that the
+// operator starts managing them.
+type Controller struct {
+	// Dependencies.
+	g8sClient clients.IngressConfigs
+	k8sClient clients.Interface
+	ledger    *ledger.Ledger
+	logger    micrologger.Logger
+
+	// Internals.
+	entryPattern *regexp.Regexp
+	mutex        sync.Mutex
+	stop         chan struct{}
+
+	// Settings.
+	createIngressConfigs       bool
+	guestClusterService        string
+	ingressControllerConfigMap string
+	ingressControllerNamespace string
+	ingressControllerService   string
+	interval                   time.Duration
+	namespace                  string
+	versionBundleVersion       string
+}
+
+// NewController creates a new configured adoption controller.
+func NewController(config ControllerConfig) (*Controller, error) {
+	// Dependencies.
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Ledger must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	// Settings.
+	if config.GuestClusterService == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GuestClusterService must not be empty", config)
+	}
+	if config.IngressControllerConfigMap == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IngressControllerConfigMap must not be empty", config)
+	}
+	if config.IngressControllerNamespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IngressControllerNamespace must not be empty", config)
+	}
+	if config.IngressControllerService == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.IngressControllerService must not be empty", config)
+	}
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+	if config.VersionBundleVersion == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.VersionBundleVersion must not be empty", config)
+	}
+
+	c := &Controller{
+		// Dependencies.
+		g8sClient: config.G8sClient,
+		k8sClient: config.K8sClient,
+		ledger:    config.Ledger,
+		logger:    config.Logger,
+
+		// Internals.
+		entryPattern: regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?)/` + regexp.QuoteMeta(config.GuestClusterService) + `:([0-9]+)$`),
+		mutex:        sync.Mutex{},
+		stop:         nil,
+
+		// Settings.
+		createIngressConfigs:       config.CreateIngressConfigs,
+		guestClusterService:        config.GuestClusterService,
+		ingressControllerConfigMap: config.IngressControllerConfigMap,
+		ingressControllerNamespace: config.IngressControllerNamespace,
+		ingressControllerService:   config.IngressControllerService,
+		interval:                   config.Interval,
+		namespace:                  config.Namespace,
+		versionBundleVersion:       config.VersionBundleVersion,
+	}
+
+	return c, nil
+}
+
+// Boot starts checking the config map in the background. Failing checks are
+// logged and retried in the next interval.
+func (c *Controller) Boot() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.stop != nil {
+		return
+	}
+
+	c.stop = make(chan struct{})
+	go c.run(c.stop)
+}
+
+// Shutdown stops checking the config map. Adopted entries stay managed.
+func (c *Controller) Shutdown() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.stop == nil {
+		return
+	}
+
+	close(c.stop)
+	c.stop = nil
+}
+
+// entry is a config map entry of the ingress controller which is not owned by
+// any allocation of the ledger.
+type entry struct {
+	ClusterID   string
+	IngressPort int
+	LBPort      int
+	Value       string
+}
+
+// Sync adopts the unowned entries of known guest clusters found in the config
+// map of the ingress controller.
+func (c *Controller) Sync(ctx context.Context) error {
+	configMap, err := c.k8sClient.GetConfigMap(ctx, c.ingressControllerNamespace, c.ingressControllerConfigMap)
+	if clients.IsNotFound(err) {
+		c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("did not find config map %s/%s", c.ingressControllerNamespace, c.ingressControllerConfigMap))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	allocations, err := c.ledger.List(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	owned := map[int]bool{}
+	for _, a := range allocations {
+		owned[a.LBPort] = true
+	}
+
+	var keys []string
+	for k := range configMap.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var entries []entry
+	for _, k := range keys {
+		lbPort, err := strconv.Atoi(k)
+		if err != nil || owned[lbPort] {
+			continue
+		}
+
+		v := configMap.Data[k]
+
+		// LB ports passing TLS through carry the PROXY protocol suffix, which
+		// does not affect the matching.
+		matches := c.entryPattern.FindStringSubmatch(strings.TrimSuffix(v, configmap.ProxyProtocolSuffix))
+		if matches == nil {
+			continue
+		}
+		ingressPort, err := strconv.Atoi(matches[3])
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, entry{
+			ClusterID:   matches[1],
+			IngressPort: ingressPort,
+			LBPort:      lbPort,
+			Value:       v,
+		})
+	}
+
+	if len(entries) == 0 {
+		c.logger.LogCtx(ctx, "level", "debug", "message", "found no unowned config map entries")
+		return nil
+	}
+
+	for _, e := range entries {
+		err := c.adopt(ctx, e)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// adopt records the given entry in the ledger in case an IngressConfig covers
+// it. The IngressConfig is created or updated before in case this is enabled.
+func (c *Controller) adopt(ctx context.Context, e entry) error {
+	_, err := c.k8sClient.GetNamespace(ctx, e.ClusterID)
+	if clients.IsNotFound(err) {
+		c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("not adopting entry %d=%s since guest cluster namespace %s does not exist", e.LBPort, e.Value, e.ClusterID))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// The IngressConfigs are listed per entry, so that IngressConfigs created
+	// for previous entries of the same guest cluster are taken into account.
+	list, err := c.g8sClient.ListIngressConfigs(ctx, "")
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var customObjects []v1alpha1.IngressConfig
+	for _, customObject := range list.Items {
+		if key.IngressControllerConfigMap(customObject) == c.ingressControllerConfigMap && key.IngressControllerNamespace(customObject) == c.ingressControllerNamespace {
+			customObjects = append(customObjects, customObject)
+		}
+	}
+
+	allocation, ok := attribute(customObjects, strconv.Itoa(e.LBPort), e.Value)
+	if !ok {
+		if !c.createIngressConfigs {
+			c.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("found unowned entry %d=%s of guest cluster %s, enable the creation of IngressConfigs to adopt it", e.LBPort, e.Value, e.ClusterID))
+			return nil
+		}
+
+		allocation, ok, err = c.ensureIngressConfig(ctx, customObjects, e)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	recorded, err := c.ledger.Adopt(ctx, []ledger.Allocation{allocation})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, r := range recorded {
+		c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("adopted LB port %d of guest cluster %s into the ledger", r.LBPort, r.ClusterID))
+	}
+
+	return nil
+}
+
+// ensureIngressConfig adds a protocol port for the given entry to the
+// IngressConfig of its guest cluster, which is created in case it does not
+// exist. The protocol is the name of the guest cluster service port the entry
+// forwards to. It returns the allocation of the entry and whether the entry is
+// covered by the IngressConfig.
+func (c *Controller) ensureIngressConfig(ctx context.Context, customObjects []v1alpha1.IngressConfig, e entry) (ledger.Allocation, bool, error) {
+	var current *v1alpha1.IngressConfig
+	for _, customObject := range customObjects {
+		if key.ClusterID(customObject) == e.ClusterID {
+			current = customObject.DeepCopy()
+			break
+		}
+	}
+	if current != nil && current.GetLabels()[AdoptedLabel] != "true" {
+		c.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not adopting entry %d=%s since ingress config %s/%s of guest cluster %s is not managed by the adoption due to missing label %s, review manually", e.LBPort, e.Value, current.Namespace, current.Name, e.ClusterID, AdoptedLabel))
+		return ledger.Allocation{}, false, nil
+	}
+
+	protocol, err := c.protocol(ctx, e)
+	if err != nil {
+		return ledger.Allocation{}, false, microerror.Mask(err)
+	}
+	if protocol == "" {
+		c.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not adopting entry %d=%s since service %s/%s has no named port %d, review manually", e.LBPort, e.Value, e.ClusterID, c.guestClusterService, e.IngressPort))
+		return ledger.Allocation{}, false, nil
+	}
+
+	protocolPort := v1alpha1.IngressConfigSpecProtocolPort{
+		IngressPort: e.IngressPort,
+		LBPort:      e.LBPort,
+		Protocol:    protocol,
+	}
+	allocation := ledger.Allocation{
+		ClusterID:   e.ClusterID,
+		IngressPort: e.IngressPort,
+		LBPort:      e.LBPort,
+		Protocol:    protocol,
+	}
+
+	if current == nil {
+		desired := c.newIngressConfig(e.ClusterID, protocolPort)
+		if strings.HasSuffix(e.Value, configmap.ProxyProtocolSuffix) {
+			addTLSPassthroughPort(desired, e.LBPort)
+		}
+
+		c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating ingress config %s/%s for entry %d=%s", desired.Namespace, desired.Name, e.LBPort, e.Value))
+
+		_, err = c.g8sClient.CreateIngressConfig(ctx, desired.Namespace, desired)
+		if err != nil {
+			return ledger.Allocation{}, false, microerror.Mask(err)
+		}
+
+		c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("created ingress config %s/%s for entry %d=%s", desired.Namespace, desired.Name, e.LBPort, e.Value))
+
+		return allocation, true, nil
+	}
+
+	for _, p := range key.ProtocolPorts(*current) {
+		if p.LBPort == e.LBPort || key.Protocol(p) == protocol {
+			c.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("not adopting entry %d=%s since ingress config %s/%s already defines LB port %d for protocol %s, review manually", e.LBPort, e.Value, current.Namespace, current.Name, p.LBPort, p.Protocol))
+			return ledger.Allocation{}, false, nil
+		}
+	}
+
+	current.Spec.ProtocolPorts = append(current.Spec.ProtocolPorts, protocolPort)
+	if strings.HasSuffix(e.Value, configmap.ProxyProtocolSuffix) {
+		addTLSPassthroughPort(current, e.LBPort)
+	}
+
+	c.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("updating ingress config %s/%s for entry %d=%s", current.Namespace, current.Name, e.LBPort, e.Value))
+
+	_, err = c.g8sClient.UpdateIngressConfig(ctx, current.Namespace, current)
+	if err != nil {
+		return ledger.Allocation{}, false, microerror.Mask(err)
+	}
+
+	c.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("updated ingress config %s/%s for entry %d=%s", current.Namespace, current.Name, e.LBPort, e.Value))
+
+	return allocation, true, nil
+}
+
+// protocol returns the name of the port of the guest cluster service the given
+// entry forwards to. It returns an empty string in case the service or the
+// named port does not exist.
+func (c *Controller) protocol(ctx context.Context, e entry) (string, error) {
+	service, err := c.k8sClient.GetService(ctx, e.ClusterID, c.guestClusterService)
+	if clients.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	for _, p := range service.Spec.Ports {
+		if int(p.Port) == e.IngressPort {
+			return key.Protocol(v1alpha1.IngressConfigSpecProtocolPort{Protocol: p.Name}), nil
+		}
+	}
+
+	return "", nil
+}
+
+// newIngressConfig returns the IngressConfig adopting the entries of the given
+// guest cluster.
+func (c *Controller) newIngressConfig(clusterID string, protocolPort v1alpha1.IngressConfigSpecProtocolPort) *v1alpha1.IngressConfig {
+	ingressConfig := &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      clusterID,
+			Namespace: c.ingressConfigNamespace(clusterID),
+			Labels: map[string]string{
+				AdoptedLabel: "true",
+			},
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        clusterID,
+				Namespace: clusterID,
+				Service:   c.guestClusterService,
+			},
+			HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+				IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+					ConfigMap: c.ingressControllerConfigMap,
+					Namespace: c.ingressControllerNamespace,
+					Service:   c.ingressControllerService,
+				},
+			},
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				protocolPort,
+			},
+			VersionBundle: v1alpha1.IngressConfigSpecVersionBundle{
+				Version: c.versionBundleVersion,
+			},
+		},
+	}
+
+	return ingressConfig
+}
+
+// addTLSPassthroughPort adds the given LB port to the TLS passthrough ports of
+// the given IngressConfig, so that the entry keeps handing over connections
+// with the PROXY protocol once the operator manages it.
+func addTLSPassthroughPort(customObject *v1alpha1.IngressConfig, lbPort int) {
+	if customObject.Annotations == nil {
+		customObject.Annotations = map[string]string{}
+	}
+
+	v := customObject.Annotations[key.TLSPassthroughAnnotation]
+	if v != "" {
+		v += ","
+	}
+	customObject.Annotations[key.TLSPassthroughAnnotation] = v + strconv.Itoa(lbPort)
+}
+
+func (c *Controller) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		err := c.Sync(context.Background())
+		if err != nil {
+			c.logger.Log("level", "error", "message", "failed adopting unowned config map entries", "stack", fmt.Sprintf("%#v", err))
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ingressConfigNamespace returns the namespace the IngressConfig of the given
+// guest cluster is created in.
+func (c *Controller) ingressConfigNamespace(clusterID string) string {
+	if c.namespace == "" {
+		return clusterID
+	}
+
+	return c.namespace
+}
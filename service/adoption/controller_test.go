@@ -0,0 +1,184 @@
+package adoption
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+func Test_Controller_Sync(t *testing.T) {
+	ctx := context.TODO()
+
+	k8sClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+			Data: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31001": "al9qy/worker:30011::PROXY",
+				// The guest cluster namespace does not exist.
+				"31002": "x7a2b/worker:30010",
+				// The entry does not forward to the guest cluster service.
+				"31003": "kube-system/dns:53",
+				// The LB port is owned by the ledger already.
+				"31004": "p1l6x/worker:30010",
+				// The entry is covered by an IngressConfig not created by the
+				// adoption controller.
+				"31005": "cl0ck/worker:30010",
+			},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "al9qy"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "cl0ck"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "p1l6x"}},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "worker",
+				Namespace: "al9qy",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: 30010},
+					{Name: "HTTPS", Port: 30011},
+				},
+			},
+		},
+	)
+
+	k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	var l *ledger.Ledger
+	{
+		c := ledger.Config{
+			K8sClient: k8sClients,
+			Logger:    microloggertest.New(),
+
+			Name:      "ingress-operator-allocations",
+			Namespace: "kube-system",
+		}
+
+		l, err = ledger.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	err = l.Record(ctx, "p1l6x", []ledger.Allocation{{IngressPort: 30010, LBPort: 31004, Protocol: "http"}})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	g8sClient := clientstest.NewIngressConfigs(
+		newTestIngressConfig(
+			"cl0ck",
+			v1alpha1.IngressConfigSpecProtocolPort{IngressPort: 30010, LBPort: 31005, Protocol: "http"},
+		),
+	)
+
+	newController := func(createIngressConfigs bool) *Controller {
+		c := DefaultControllerConfig()
+
+		c.G8sClient = g8sClient
+		c.K8sClient = k8sClients
+		c.Ledger = l
+		c.Logger = microloggertest.New()
+
+		c.CreateIngressConfigs = createIngressConfigs
+		c.IngressControllerConfigMap = "ingress-controller"
+		c.IngressControllerNamespace = "kube-system"
+		c.IngressControllerService = "ingress-controller"
+		c.Namespace = "default"
+		c.VersionBundleVersion = "0.2.0"
+
+		controller, err := NewController(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		return controller
+	}
+
+	// Without creating IngressConfigs only the entry covered by an existing
+	// IngressConfig is adopted.
+	err = newController(false).Sync(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err := l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	assertLBPorts(t, list, 31004, 31005)
+	if g8sClient.Len() != 1 {
+		t.Fatal("expected", 1, "got", g8sClient.Len())
+	}
+
+	// Creating IngressConfigs adopts the entries of the guest cluster whose
+	// service ports name the protocols.
+	err = newController(true).Sync(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err = l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	assertLBPorts(t, list, 31000, 31001, 31004, 31005)
+	if g8sClient.Len() != 2 {
+		t.Fatal("expected", 2, "got", g8sClient.Len())
+	}
+
+	created, err := g8sClient.GetIngressConfig(ctx, "default", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if created.Labels[AdoptedLabel] != "true" {
+		t.Fatal("expected", "true", "got", created.Labels[AdoptedLabel])
+	}
+	if created.Annotations[key.TLSPassthroughAnnotation] != "31001" {
+		t.Fatal("expected", "31001", "got", created.Annotations[key.TLSPassthroughAnnotation])
+	}
+
+	expected := []v1alpha1.IngressConfigSpecProtocolPort{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+	}
+	ports := key.ProtocolPorts(*created)
+	if len(ports) != len(expected) {
+		t.Fatalf("expected %#v got %#v", expected, ports)
+	}
+	for i, e := range expected {
+		if ports[i] != e {
+			t.Fatal("test", i, "expected", e, "got", ports[i])
+		}
+	}
+}
+
+func assertLBPorts(t *testing.T, list []ledger.Allocation, expected ...int) {
+	t.Helper()
+
+	if len(list) != len(expected) {
+		t.Fatalf("expected LB ports %v got %#v", expected, list)
+	}
+	for i, e := range expected {
+		if list[i].LBPort != e {
+			t.Fatal("test", i, "expected", e, "got", list[i].LBPort)
+		}
+	}
+}
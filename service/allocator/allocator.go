@@ -0,0 +1,217 @@
+// Package allocator implements the policy of the LB port pool of the host
+// cluster ingress controllers. The allocator knows which ports are off-limits
+// and validates the ports requested by IngressConfigs against them. It also
+// knows the size of the pool, so that its utilization can be computed, and
+// how long freed ports cool off before they can be reassigned.
+package allocator
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// ParsePorts parses the given list of ports as used for the configuration of
+// the allocator, e.g. the reserved ports.
+func ParsePorts(list []string) ([]int, error) {
+	var ports []int
+	for _, s := range list {
+		p, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "port must be a number, got %q", s)
+		}
+		ports = append(ports, p)
+	}
+
+	return ports, nil
+}
+
+// ParsePortRanges parses the given list of port ranges as used for the
+// configuration of the available ports, e.g. "31000-31499". Single ports are
+// accepted as ranges of one port. Ranges must be within the valid port range
+// and must not overlap, since overlaps are most likely configuration mistakes.
+func ParsePortRanges(list []string) ([]int, error) {
+	type portRange struct {
+		start int
+		end   int
+		s     string
+	}
+
+	var ranges []portRange
+	for _, s := range list {
+		bounds := strings.SplitN(strings.TrimSpace(s), "-", 2)
+
+		start, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "port range must be a port or two ports separated by '-', got %q", s)
+		}
+		end := start
+		if len(bounds) == 2 {
+			end, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+			if err != nil {
+				return nil, microerror.Maskf(invalidConfigError, "port range must be a port or two ports separated by '-', got %q", s)
+			}
+		}
+
+		if start <= 0 || end > 65535 {
+			return nil, microerror.Maskf(invalidConfigError, "port range must only contain valid ports, got %q", s)
+		}
+		if start > end {
+			return nil, microerror.Maskf(invalidConfigError, "port range must not end before it starts, got %q", s)
+		}
+
+		ranges = append(ranges, portRange{start: start, end: end, s: s})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+
+	var ports []int
+	for i, r := range ranges {
+		if i > 0 && r.start <= ranges[i-1].end {
+			return nil, microerror.Maskf(invalidConfigError, "port ranges must not overlap, got %q and %q", strings.TrimSpace(ranges[i-1].s), strings.TrimSpace(r.s))
+		}
+		for p := r.start; p <= r.end; p++ {
+			ports = append(ports, p)
+		}
+	}
+
+	return ports, nil
+}
+
+// Config represents the configuration used to create a new allocator.
+type Config struct {
+	// Available is the set of LB ports available for guest clusters. It defines
+	// the capacity of the port pool. Reserved ports do not count towards the
+	// capacity.
+	Available []int
+	// CoolingOff is the time LB ports freed by a guest cluster cannot be
+	// reassigned to another guest cluster, so that stale client connections
+	// are not routed to the wrong tenant. Zero reassigns freed LB ports right
+	// away.
+	CoolingOff time.Duration
+	// Reserved is the set of LB ports which must never be used by any guest
+	// cluster.
+	Reserved []int
+}
+
+// Allocator implements the LB port pool policy.
+type Allocator struct {
+	available  map[int]struct{}
+	coolingOff time.Duration
+	reserved   map[int]struct{}
+}
+
+// New creates a new configured allocator.
+func New(config Config) (*Allocator, error) {
+	available := map[int]struct{}{}
+	for _, p := range config.Available {
+		if p <= 0 || p > 65535 {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Available must only contain valid ports, got %d", config, p)
+		}
+		available[p] = struct{}{}
+	}
+
+	if config.CoolingOff < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CoolingOff must not be negative", config)
+	}
+
+	reserved := map[int]struct{}{}
+	for _, p := range config.Reserved {
+		if p <= 0 || p > 65535 {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Reserved must only contain valid ports, got %d", config, p)
+		}
+		reserved[p] = struct{}{}
+	}
+
+	a := &Allocator{
+		available:  available,
+		coolingOff: config.CoolingOff,
+		reserved:   reserved,
+	}
+
+	return a, nil
+}
+
+// Capacity returns the number of available LB ports which are not reserved.
+// The capacity is 0 in case no available ports are configured.
+func (a *Allocator) Capacity() int {
+	var n int
+	for p := range a.available {
+		if !a.IsReserved(p) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Utilization returns the fraction of the capacity used by the given allocated
+// LB ports. Allocated ports outside of the available ports do not count. The
+// utilization is 0 in case no available ports are configured.
+func (a *Allocator) Utilization(allocated []int) float64 {
+	capacity := a.Capacity()
+	if capacity == 0 {
+		return 0
+	}
+
+	used := map[int]struct{}{}
+	for _, p := range allocated {
+		_, ok := a.available[p]
+		if ok && !a.IsReserved(p) {
+			used[p] = struct{}{}
+		}
+	}
+
+	return float64(len(used)) / float64(capacity)
+}
+
+// CoolingOff returns the time freed LB ports cannot be reassigned to another
+// guest cluster.
+func (a *Allocator) CoolingOff() time.Duration {
+	return a.coolingOff
+}
+
+// IsReserved returns whether the given port is reserved.
+func (a *Allocator) IsReserved(port int) bool {
+	_, ok := a.reserved[port]
+	return ok
+}
+
+// Reserved returns the sorted list of reserved ports.
+func (a *Allocator) Reserved() []int {
+	var ports []int
+	for p := range a.reserved {
+		ports = append(ports, p)
+	}
+	sort.Ints(ports)
+
+	return ports
+}
+
+// ValidatePinned returns an error in case any of the given pinned ports is
+// reserved. Pinned ports are exempt from any reallocation, so they must never
+// collide with the reserved set.
+func (a *Allocator) ValidatePinned(ports []int) error {
+	for _, p := range ports {
+		if a.IsReserved(p) {
+			return microerror.Maskf(reservedPortError, "pinned port %d is reserved", p)
+		}
+	}
+
+	return nil
+}
+
+// Validate returns an error in case any of the given LB ports requested by an
+// IngressConfig is reserved for system use.
+func (a *Allocator) Validate(ports []int) error {
+	for _, p := range ports {
+		if a.IsReserved(p) {
+			return microerror.Maskf(reservedPortError, "LB port %d is reserved for system use", p)
+		}
+	}
+
+	return nil
+}
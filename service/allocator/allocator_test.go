@@ -0,0 +1,242 @@
+package allocator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Allocator_ValidatePinned(t *testing.T) {
+	testCases := []struct {
+		Reserved     []int
+		Pinned       []int
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures pinned ports are valid without reserved ports.
+		{
+			Reserved:     nil,
+			Pinned:       []int{31000, 31001},
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures pinned ports not being reserved are valid.
+		{
+			Reserved:     []int{31010},
+			Pinned:       []int{31000, 31001},
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures pinned ports being reserved are rejected.
+		{
+			Reserved:     []int{31001},
+			Pinned:       []int{31000, 31001},
+			ErrorMatcher: IsReservedPort,
+		},
+	}
+
+	for i, tc := range testCases {
+		a, err := New(Config{Reserved: tc.Reserved})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		err = a.ValidatePinned(tc.Pinned)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+	}
+}
+
+func Test_Allocator_ParsePorts(t *testing.T) {
+	testCases := []struct {
+		List         []string
+		Expected     []int
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures an empty list results in no ports.
+		{
+			List:         nil,
+			Expected:     nil,
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures ports are parsed.
+		{
+			List:         []string{"31000", " 31001"},
+			Expected:     []int{31000, 31001},
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures malformed ports are rejected.
+		{
+			List:         []string{"31000", "http"},
+			Expected:     nil,
+			ErrorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		ports, err := ParsePorts(tc.List)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if !reflect.DeepEqual(tc.Expected, ports) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, ports)
+		}
+	}
+}
+
+func Test_Allocator_ParsePortRanges(t *testing.T) {
+	testCases := []struct {
+		List         []string
+		Expected     []int
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures an empty list results in no ports.
+		{
+			List:         nil,
+			Expected:     nil,
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures ranges and single ports are parsed into ordered ports.
+		{
+			List:         []string{"32000-32002", " 31000 - 31001", "31500"},
+			Expected:     []int{31000, 31001, 31500, 32000, 32001, 32002},
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures malformed ranges are rejected.
+		{
+			List:         []string{"31000-http"},
+			Expected:     nil,
+			ErrorMatcher: IsInvalidConfig,
+		},
+
+		// Test 3 ensures ranges ending before they start are rejected.
+		{
+			List:         []string{"31499-31000"},
+			Expected:     nil,
+			ErrorMatcher: IsInvalidConfig,
+		},
+
+		// Test 4 ensures ranges exceeding the valid ports are rejected.
+		{
+			List:         []string{"65000-65536"},
+			Expected:     nil,
+			ErrorMatcher: IsInvalidConfig,
+		},
+
+		// Test 5 ensures overlapping ranges are rejected.
+		{
+			List:         []string{"31000-31499", "32000-32099", "31400-31600"},
+			Expected:     nil,
+			ErrorMatcher: IsInvalidConfig,
+		},
+
+		// Test 6 ensures single ports within ranges are rejected as overlap.
+		{
+			List:         []string{"31000-31499", "31499"},
+			Expected:     nil,
+			ErrorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		ports, err := ParsePortRanges(tc.List)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if !reflect.DeepEqual(tc.Expected, ports) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, ports)
+		}
+	}
+}
+
+func Test_Allocator_Validate(t *testing.T) {
+	a, err := New(Config{Reserved: []int{31010}})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = a.Validate([]int{31000, 31001})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = a.Validate([]int{31000, 31010})
+	if !IsReservedPort(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Allocator_Utilization(t *testing.T) {
+	testCases := []struct {
+		Available           []int
+		Reserved            []int
+		Allocated           []int
+		ExpectedCapacity    int
+		ExpectedUtilization float64
+	}{
+		// Test 0 ensures the utilization is 0 without available ports.
+		{
+			Available:           nil,
+			Reserved:            nil,
+			Allocated:           []int{31000},
+			ExpectedCapacity:    0,
+			ExpectedUtilization: 0,
+		},
+
+		// Test 1 ensures allocated ports are counted against the available ports.
+		{
+			Available:           []int{31000, 31001, 31002, 31003},
+			Reserved:            nil,
+			Allocated:           []int{31000, 31001, 31002},
+			ExpectedCapacity:    4,
+			ExpectedUtilization: 0.75,
+		},
+
+		// Test 2 ensures reserved ports do not count towards the capacity.
+		{
+			Available:           []int{31000, 31001, 31002, 31003},
+			Reserved:            []int{31003},
+			Allocated:           []int{31000},
+			ExpectedCapacity:    3,
+			ExpectedUtilization: 1.0 / 3.0,
+		},
+
+		// Test 3 ensures allocated ports outside the available ports and
+		// duplicates are not counted.
+		{
+			Available:           []int{31000, 31001},
+			Reserved:            nil,
+			Allocated:           []int{31000, 31000, 32000},
+			ExpectedCapacity:    2,
+			ExpectedUtilization: 0.5,
+		},
+	}
+
+	for i, tc := range testCases {
+		a, err := New(Config{Available: tc.Available, Reserved: tc.Reserved})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		capacity := a.Capacity()
+		if capacity != tc.ExpectedCapacity {
+			t.Fatal("test", i, "expected", tc.ExpectedCapacity, "got", capacity)
+		}
+		utilization := a.Utilization(tc.Allocated)
+		if utilization != tc.ExpectedUtilization {
+			t.Fatal("test", i, "expected", tc.ExpectedUtilization, "got", utilization)
+		}
+	}
+}
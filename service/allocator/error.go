@@ -0,0 +1,21 @@
+package allocator
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var reservedPortError = &microerror.Error{
+	Kind: "reservedPortError",
+}
+
+// IsReservedPort asserts reservedPortError.
+func IsReservedPort(err error) bool {
+	return microerror.Cause(err) == reservedPortError
+}
@@ -0,0 +1,332 @@
+// Package breaker implements a circuit breaker for writes to the Kubernetes
+// API. When the API server is overloaded, the retries of every reconciliation
+// add to its load. The breaker tracks the outcome of all writes going through
+// the transport of the Kubernetes clients. Once the error rate of the writes
+// within a window exceeds a threshold, the breaker opens and rejects all
+// writes for a cool-down period without sending them. Reads are never
+// rejected, so that the informers and resources keep observing the host
+// cluster. After the cool-down a single write is let through as a probe. It
+// closes the breaker in case it succeeds and opens it again otherwise.
+package breaker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/rest"
+)
+
+// State is the state of the breaker.
+type State string
+
+const (
+	// StateClosed is the state in which all writes are sent.
+	StateClosed State = "closed"
+	// StateHalfOpen is the state after the cool-down in which a single write
+	// is sent as a probe.
+	StateHalfOpen State = "half-open"
+	// StateOpen is the state in which all writes are rejected.
+	StateOpen State = "open"
+)
+
+var states = []State{StateClosed, StateHalfOpen, StateOpen}
+
+const (
+	// HealthzName is the name of the health check of the breaker.
+	HealthzName = "breaker"
+)
+
+// Config represents the configuration used to create a new breaker.
+type Config struct {
+	Logger micrologger.Logger
+
+	// CoolDown is the time writes are rejected for once the breaker opened.
+	CoolDown time.Duration
+	// MinRequests is the minimum number of writes within the window before the
+	// breaker opens, so that a few failing writes of an idle operator do not
+	// open it.
+	MinRequests int
+	// Threshold is the error rate of the writes within the window opening the
+	// breaker, between 0 and 1. Zero disables the breaker.
+	Threshold float64
+	// Window is the time writes are taken into account for the error rate.
+	Window time.Duration
+}
+
+// Breaker is the circuit breaker of writes to the Kubernetes API.
+type Breaker struct {
+	logger micrologger.Logger
+
+	coolDown    time.Duration
+	minRequests int
+	threshold   float64
+	window      time.Duration
+
+	mutex sync.Mutex
+	// now returns the current time. It is replaced in tests.
+	now      func() time.Time
+	opened   time.Time
+	outcomes []writeOutcome
+	probing  bool
+	state    State
+}
+
+type writeOutcome struct {
+	failed bool
+	time   time.Time
+}
+
+// New creates a new configured breaker.
+func New(config Config) (*Breaker, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Threshold < 0 || config.Threshold > 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Threshold must be between 0 and 1", config)
+	}
+	if config.Threshold > 0 {
+		if config.CoolDown <= 0 {
+			return nil, microerror.Maskf(invalidConfigError, "%T.CoolDown must be greater than 0", config)
+		}
+		if config.MinRequests < 1 {
+			return nil, microerror.Maskf(invalidConfigError, "%T.MinRequests must be greater than 0", config)
+		}
+		if config.Window <= 0 {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Window must be greater than 0", config)
+		}
+	}
+
+	b := &Breaker{
+		logger: config.Logger,
+
+		coolDown:    config.CoolDown,
+		minRequests: config.MinRequests,
+		threshold:   config.Threshold,
+		window:      config.Window,
+
+		now:   time.Now,
+		state: StateClosed,
+	}
+
+	b.updateStateGauge()
+
+	return b, nil
+}
+
+// Allow returns an open error in case a write must not be sent. Otherwise the
+// caller has to send the write and pass its outcome to Done. The returned bool
+// tells whether the write is the probe of a half-open breaker.
+func (b *Breaker) Allow() (bool, error) {
+	if b.threshold == 0 {
+		return false, nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		until := b.opened.Add(b.coolDown)
+		if b.now().Before(until) {
+			writesCounter.WithLabelValues("rejected").Inc()
+			return false, microerror.Maskf(openError, "writes to the Kubernetes API are paused until %s", until.Format(time.RFC3339))
+		}
+
+		b.setState(StateHalfOpen)
+		b.probing = true
+
+		return true, nil
+	case StateHalfOpen:
+		if b.probing {
+			writesCounter.WithLabelValues("rejected").Inc()
+			return false, microerror.Maskf(openError, "writes to the Kubernetes API are paused until the probe write finished")
+		}
+
+		b.probing = true
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Done records the outcome of a write allowed before. Writes sent before the
+// breaker opened only count in case it is still closed.
+func (b *Breaker) Done(probe, failed bool) {
+	if failed {
+		writesCounter.WithLabelValues("failed").Inc()
+	} else {
+		writesCounter.WithLabelValues("succeeded").Inc()
+	}
+
+	if b.threshold == 0 {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+
+	if probe {
+		b.probing = false
+		if failed {
+			b.open(now, "the probe write failed")
+		} else {
+			b.logger.Log("level", "info", "message", "closing the circuit breaker, the probe write succeeded")
+			b.outcomes = nil
+			b.setState(StateClosed)
+		}
+
+		return
+	}
+
+	if b.state != StateClosed {
+		return
+	}
+
+	b.outcomes = append(b.outcomes, writeOutcome{failed: failed, time: now})
+
+	var i int
+	for i < len(b.outcomes) && now.Sub(b.outcomes[i].time) > b.window {
+		i++
+	}
+	b.outcomes = b.outcomes[i:]
+
+	if len(b.outcomes) < b.minRequests {
+		return
+	}
+
+	var failures int
+	for _, o := range b.outcomes {
+		if o.failed {
+			failures++
+		}
+	}
+
+	rate := float64(failures) / float64(len(b.outcomes))
+	if rate >= b.threshold {
+		b.open(now, fmt.Sprintf("%d of %d writes within %s failed", failures, len(b.outcomes), b.window))
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state
+}
+
+// Apply configures the given rest config, so that the writes of clients
+// created from it afterwards go through the breaker.
+func (b *Breaker) Apply(restConfig *rest.Config) error {
+	if restConfig == nil {
+		return microerror.Maskf(invalidConfigError, "restConfig must not be empty")
+	}
+
+	wrapTransport := restConfig.WrapTransport
+	restConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrapTransport != nil {
+			rt = wrapTransport(rt)
+		}
+
+		return &transport{breaker: b, transport: rt}
+	}
+
+	return nil
+}
+
+// GetHealthz reports the state of the breaker. An open breaker does not fail
+// the health check, since restarting the operator would only add to the load
+// of the Kubernetes API.
+func (b *Breaker) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	state := b.State()
+
+	message := fmt.Sprintf("Circuit breaker of writes to the Kubernetes API is %s.", state)
+	if b.threshold == 0 {
+		message = "Circuit breaker of writes to the Kubernetes API is disabled."
+	}
+
+	r := healthz.Response{
+		Description: "Report the state of the circuit breaker of writes to the Kubernetes API.",
+		Failed:      false,
+		Message:     message,
+		Name:        HealthzName,
+	}
+
+	return r, nil
+}
+
+func (b *Breaker) open(now time.Time, reason string) {
+	b.logger.Log("level", "warning", "message", fmt.Sprintf("opening the circuit breaker, %s, pausing writes to the Kubernetes API for %s", reason, b.coolDown))
+
+	b.opened = now
+	b.outcomes = nil
+	b.setState(StateOpen)
+}
+
+func (b *Breaker) setState(s State) {
+	b.state = s
+	b.updateStateGauge()
+}
+
+func (b *Breaker) updateStateGauge() {
+	for _, s := range states {
+		var v float64
+		if s == b.state {
+			v = 1
+		}
+		stateGauge.WithLabelValues(string(s)).Set(v)
+	}
+}
+
+// transport sends reads right away and writes only in case the breaker
+// allows them.
+type transport struct {
+	breaker   *Breaker
+	transport http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isWrite(req) {
+		return t.transport.RoundTrip(req)
+	}
+
+	probe, err := t.breaker.Allow()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	res, err := t.transport.RoundTrip(req)
+	t.breaker.Done(probe, isFailure(res, err))
+
+	return res, err
+}
+
+func isWrite(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodDelete, http.MethodPatch, http.MethodPost, http.MethodPut:
+		return true
+	}
+
+	return false
+}
+
+// isFailure returns whether the given result of a write indicates an
+// overloaded or unavailable API server. Client errors like conflicts are
+// regular results of concurrent writes.
+func isFailure(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,203 @@
+package breaker
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/rest"
+)
+
+// testTransport responds with the status it is set to.
+type testTransport struct {
+	requests int
+	status   int
+}
+
+func (t *testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return &http.Response{StatusCode: t.status, Request: req}, nil
+}
+
+type testRequest struct {
+	// Advance is the time passing before the request is sent.
+	Advance time.Duration
+	Method  string
+	Status  int
+	// ExpectedSent is whether the request reaches the API server.
+	ExpectedSent bool
+}
+
+// repeat returns the given request n times.
+func repeat(n int, r testRequest) []testRequest {
+	var requests []testRequest
+	for i := 0; i < n; i++ {
+		requests = append(requests, r)
+	}
+
+	return requests
+}
+
+func concat(requests ...[]testRequest) []testRequest {
+	var result []testRequest
+	for _, r := range requests {
+		result = append(result, r...)
+	}
+
+	return result
+}
+
+func Test_Breaker_Transport(t *testing.T) {
+	failedWrite := testRequest{Method: http.MethodPut, Status: http.StatusServiceUnavailable, ExpectedSent: true}
+	rejectedWrite := testRequest{Method: http.MethodPut, Status: http.StatusOK, ExpectedSent: false}
+	succeededWrite := testRequest{Method: http.MethodPut, Status: http.StatusOK, ExpectedSent: true}
+
+	testCases := []struct {
+		Threshold     float64
+		Requests      []testRequest
+		ExpectedState State
+	}{
+		// Test 0 ensures the breaker stays closed while writes succeed.
+		{
+			Threshold:     0.5,
+			Requests:      repeat(10, succeededWrite),
+			ExpectedState: StateClosed,
+		},
+
+		// Test 1 ensures the breaker opens once the error rate reaches the
+		// threshold and rejects writes afterwards.
+		{
+			Threshold: 0.5,
+			Requests: concat(
+				repeat(2, succeededWrite),
+				repeat(2, failedWrite),
+				repeat(3, rejectedWrite),
+			),
+			ExpectedState: StateOpen,
+		},
+
+		// Test 2 ensures the breaker does not open before the minimum number of
+		// writes.
+		{
+			Threshold:     0.5,
+			Requests:      repeat(3, failedWrite),
+			ExpectedState: StateClosed,
+		},
+
+		// Test 3 ensures reads are sent while the breaker is open.
+		{
+			Threshold: 0.5,
+			Requests: concat(
+				repeat(4, failedWrite),
+				repeat(3, testRequest{Method: http.MethodGet, Status: http.StatusOK, ExpectedSent: true}),
+			),
+			ExpectedState: StateOpen,
+		},
+
+		// Test 4 ensures the breaker closes once the probe write after the
+		// cool-down succeeded.
+		{
+			Threshold: 0.5,
+			Requests: concat(
+				repeat(4, failedWrite),
+				[]testRequest{{Advance: time.Minute, Method: http.MethodPost, Status: http.StatusCreated, ExpectedSent: true}},
+				repeat(2, succeededWrite),
+			),
+			ExpectedState: StateClosed,
+		},
+
+		// Test 5 ensures the breaker opens again in case the probe write failed.
+		{
+			Threshold: 0.5,
+			Requests: concat(
+				repeat(4, failedWrite),
+				[]testRequest{{Advance: time.Minute, Method: http.MethodDelete, Status: http.StatusTooManyRequests, ExpectedSent: true}},
+				repeat(2, rejectedWrite),
+			),
+			ExpectedState: StateOpen,
+		},
+
+		// Test 6 ensures failed writes leaving the window do not count.
+		{
+			Threshold: 0.5,
+			Requests: concat(
+				repeat(3, failedWrite),
+				[]testRequest{{Advance: 2 * time.Minute, Method: http.MethodPut, Status: http.StatusOK, ExpectedSent: true}},
+				repeat(3, succeededWrite),
+			),
+			ExpectedState: StateClosed,
+		},
+
+		// Test 7 ensures conflicts do not count as failures.
+		{
+			Threshold:     0.5,
+			Requests:      repeat(10, testRequest{Method: http.MethodPut, Status: http.StatusConflict, ExpectedSent: true}),
+			ExpectedState: StateClosed,
+		},
+
+		// Test 8 ensures a zero threshold disables the breaker.
+		{
+			Threshold:     0,
+			Requests:      repeat(10, failedWrite),
+			ExpectedState: StateClosed,
+		},
+	}
+
+	for i, tc := range testCases {
+		var err error
+		var b *Breaker
+		{
+			c := Config{
+				Logger: microloggertest.New(),
+
+				CoolDown:    30 * time.Second,
+				MinRequests: 4,
+				Threshold:   tc.Threshold,
+				Window:      time.Minute,
+			}
+
+			b, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		now := time.Now()
+		b.now = func() time.Time { return now }
+
+		restConfig := &rest.Config{}
+		err = b.Apply(restConfig)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		inner := &testTransport{}
+		rt := restConfig.WrapTransport(inner)
+
+		for j, r := range tc.Requests {
+			now = now.Add(r.Advance)
+			inner.status = r.Status
+
+			req, err := http.NewRequest(r.Method, "https://kubernetes/api/v1/namespaces/default/configmaps", nil)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			before := inner.requests
+			_, err = rt.RoundTrip(req)
+
+			sent := inner.requests > before
+			if sent != r.ExpectedSent {
+				t.Fatal("test", i, "request", j, "expected", r.ExpectedSent, "got", sent)
+			}
+			if !sent && !IsOpen(err) {
+				t.Fatal("test", i, "request", j, "expected", true, "got", false)
+			}
+		}
+
+		if b.State() != tc.ExpectedState {
+			t.Fatal("test", i, "expected", tc.ExpectedState, "got", b.State())
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package breaker
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var openError = &microerror.Error{
+	Kind: "openError",
+}
+
+// IsOpen asserts openError.
+func IsOpen(err error) bool {
+	return microerror.Cause(err) == openError
+}
@@ -0,0 +1,37 @@
+package breaker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "breaker"
+)
+
+var (
+	stateGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "state",
+			Help:      "A gauge metric expressing whether the circuit breaker of writes to the Kubernetes API is in the given state.",
+		},
+		[]string{"state"},
+	)
+	writesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "writes_total",
+			Help:      "A counter metric expressing the number of writes to the Kubernetes API by result, i.e. succeeded, failed or rejected by the circuit breaker.",
+		},
+		[]string{"result"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(stateGauge)
+	prometheus.MustRegister(writesCounter)
+}
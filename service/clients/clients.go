@@ -0,0 +1,574 @@
+// Package clients implements the typed Kubernetes operations the resources of
+// the operator need on top of client-go. This is the only place depending on
+// the client-go clientset API, which keeps client-go and apimachinery upgrades
+// contained in this package.
+package clients
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// Config represents the configuration used to create new clients.
+type Config struct {
+	K8sClient kubernetes.Interface
+}
+
+// Clients implements Interface using a client-go clientset. Requests are
+// issued through the REST clients of the clientset, whose request builders
+// take the given context, so that cancelled reconciliations end their
+// in-flight requests.
+type Clients struct {
+	k8sClient kubernetes.Interface
+
+	// appsV1 and coreV1 are the REST clients of the API groups of k8sClient.
+	// They are nil for clientsets without REST clients, e.g. the fake
+	// clientset of the tests, whose typed clients are used without context
+	// then.
+	appsV1 rest.Interface
+	coreV1 rest.Interface
+}
+
+// New creates new configured clients.
+func New(config Config) (*Clients, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+
+	c := &Clients{
+		k8sClient: config.K8sClient,
+
+		appsV1: restClientOf(config.K8sClient.AppsV1().RESTClient()),
+		coreV1: restClientOf(config.K8sClient.CoreV1().RESTClient()),
+	}
+
+	return c, nil
+}
+
+func (c *Clients) CreateConfigMap(ctx context.Context, namespace string, configMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().ConfigMaps(namespace).Create(configMap)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.ConfigMap{}
+	err := c.coreV1.Post().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("configmaps").
+		Body(configMap).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) CreateDeployment(ctx context.Context, namespace string, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
+	if c.appsV1 == nil {
+		result, err := c.k8sClient.AppsV1().Deployments(namespace).Create(deployment)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &appsv1.Deployment{}
+	err := c.appsV1.Post().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("deployments").
+		Body(deployment).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) CreateEvent(ctx context.Context, namespace string, event *corev1.Event) (*corev1.Event, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().Events(namespace).Create(event)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.Event{}
+	err := c.coreV1.Post().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("events").
+		Body(event).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) CreateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().Services(namespace).Create(service)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.Service{}
+	err := c.coreV1.Post().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("services").
+		Body(service).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) DeleteConfigMap(ctx context.Context, namespace, name string) error {
+	if c.coreV1 == nil {
+		err := c.k8sClient.CoreV1().ConfigMaps(namespace).Delete(name, &metav1.DeleteOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	err := c.coreV1.Delete().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("configmaps").
+		Name(name).
+		Body(&metav1.DeleteOptions{}).
+		Do().
+		Error()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *Clients) DeleteDeployment(ctx context.Context, namespace, name string) error {
+	if c.appsV1 == nil {
+		err := c.k8sClient.AppsV1().Deployments(namespace).Delete(name, &metav1.DeleteOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	err := c.appsV1.Delete().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("deployments").
+		Name(name).
+		Body(&metav1.DeleteOptions{}).
+		Do().
+		Error()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *Clients) DeleteService(ctx context.Context, namespace, name string) error {
+	if c.coreV1 == nil {
+		err := c.k8sClient.CoreV1().Services(namespace).Delete(name, &metav1.DeleteOptions{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	err := c.coreV1.Delete().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("services").
+		Name(name).
+		Body(&metav1.DeleteOptions{}).
+		Do().
+		Error()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *Clients) GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.ConfigMap{}
+	err := c.coreV1.Get().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("configmaps").
+		Name(name).
+		VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) GetDeployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error) {
+	if c.appsV1 == nil {
+		result, err := c.k8sClient.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &appsv1.Deployment{}
+	err := c.appsV1.Get().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("deployments").
+		Name(name).
+		VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) GetNamespace(ctx context.Context, name string) (*corev1.Namespace, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.Namespace{}
+	err := c.coreV1.Get().
+		Context(ctx).
+		Resource("namespaces").
+		Name(name).
+		VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.Secret{}
+	err := c.coreV1.Get().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("secrets").
+		Name(name).
+		VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) GetService(ctx context.Context, namespace, name string) (*corev1.Service, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().Services(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.Service{}
+	err := c.coreV1.Get().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("services").
+		Name(name).
+		VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) ListConfigMaps(ctx context.Context, namespace, labelSelector string) (*corev1.ConfigMapList, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().ConfigMaps(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.ConfigMapList{}
+	err := c.coreV1.Get().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("configmaps").
+		VersionedParams(&metav1.ListOptions{LabelSelector: labelSelector}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) ListPods(ctx context.Context, namespace string) (*corev1.PodList, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.PodList{}
+	err := c.coreV1.Get().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("pods").
+		VersionedParams(&metav1.ListOptions{}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) ListServices(ctx context.Context, namespace, labelSelector string) (*corev1.ServiceList, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().Services(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.ServiceList{}
+	err := c.coreV1.Get().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("services").
+		VersionedParams(&metav1.ListOptions{LabelSelector: labelSelector}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) PatchConfigMap(ctx context.Context, namespace, name string, pt types.PatchType, data []byte) (*corev1.ConfigMap, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().ConfigMaps(namespace).Patch(name, pt, data)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.ConfigMap{}
+	err := c.coreV1.Patch(pt).
+		Context(ctx).
+		Namespace(namespace).
+		Resource("configmaps").
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) PatchService(ctx context.Context, namespace, name string, pt types.PatchType, data []byte) (*corev1.Service, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().Services(namespace).Patch(name, pt, data)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.Service{}
+	err := c.coreV1.Patch(pt).
+		Context(ctx).
+		Namespace(namespace).
+		Resource("services").
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) UpdateConfigMap(ctx context.Context, namespace string, configMap *corev1.ConfigMap) (*corev1.ConfigMap, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().ConfigMaps(namespace).Update(configMap)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.ConfigMap{}
+	err := c.coreV1.Put().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("configmaps").
+		Name(configMap.Name).
+		Body(configMap).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) UpdateDeployment(ctx context.Context, namespace string, deployment *appsv1.Deployment) (*appsv1.Deployment, error) {
+	if c.appsV1 == nil {
+		result, err := c.k8sClient.AppsV1().Deployments(namespace).Update(deployment)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &appsv1.Deployment{}
+	err := c.appsV1.Put().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("deployments").
+		Name(deployment.Name).
+		Body(deployment).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *Clients) UpdateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error) {
+	if c.coreV1 == nil {
+		result, err := c.k8sClient.CoreV1().Services(namespace).Update(service)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	}
+
+	result := &corev1.Service{}
+	err := c.coreV1.Put().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("services").
+		Name(service.Name).
+		Body(service).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+// restClientOf returns the given REST client, or nil if it is a nil pointer,
+// which is what the fake clientset returns.
+func restClientOf(restClient rest.Interface) rest.Interface {
+	c, ok := restClient.(*rest.RESTClient)
+	if ok && c == nil {
+		return nil
+	}
+
+	return restClient
+}
@@ -0,0 +1,393 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+// Test_Clients_ConfigMaps ensures config maps are managed the same using the
+// REST clients of a clientset and using the fake clientset, which has none.
+func Test_Clients_ConfigMaps(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	k8sClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	for _, k := range []kubernetes.Interface{k8sClient, fake.NewSimpleClientset()} {
+		testClientsConfigMaps(t, k)
+	}
+}
+
+func testClientsConfigMaps(t *testing.T, k8sClient kubernetes.Interface) {
+	c, err := New(Config{K8sClient: k8sClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx := context.TODO()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "ingress-controller",
+		},
+		Data: map[string]string{
+			"31000": "al9qy/worker:30010",
+		},
+	}
+
+	_, err = c.CreateConfigMap(ctx, "kube-system", configMap)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err := c.GetConfigMap(ctx, "kube-system", "ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	current.Data["31001"] = "al9qy/worker:30011"
+
+	_, err = c.UpdateConfigMap(ctx, "kube-system", current)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err = c.GetConfigMap(ctx, "kube-system", "ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(current.Data) != 2 {
+		t.Fatal("expected", 2, "got", len(current.Data))
+	}
+
+	err = c.DeleteConfigMap(ctx, "kube-system", "ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	_, err = c.GetConfigMap(ctx, "kube-system", "ingress-controller")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+// Test_Clients_ConfigMapsREST ensures config maps are listed by label selector
+// and patched through the REST clients of a clientset.
+func Test_Clients_ConfigMapsREST(t *testing.T) {
+	c, server := newTestClients(t)
+	defer server.Close()
+	ctx := context.TODO()
+
+	for _, name := range []string{"ingress-controller", "ingress-operator-ledger"} {
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					"app": name,
+				},
+			},
+		}
+
+		_, err := c.CreateConfigMap(ctx, "kube-system", configMap)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	list, err := c.ListConfigMaps(ctx, "kube-system", "app=ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "ingress-controller" {
+		t.Fatal("expected", "ingress-controller", "got", list.Items)
+	}
+
+	patched, err := c.PatchConfigMap(ctx, "kube-system", "ingress-controller", types.MergePatchType, []byte(`{"data":{"31000":"al9qy/worker:30010"}}`))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if patched.Data["31000"] != "al9qy/worker:30010" {
+		t.Fatal("expected", "al9qy/worker:30010", "got", patched.Data["31000"])
+	}
+
+	_, err = c.PatchConfigMap(ctx, "kube-system", "missing", types.MergePatchType, []byte(`{}`))
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+// Test_Clients_Deployments ensures deployments are managed through the REST
+// clients of a clientset.
+func Test_Clients_Deployments(t *testing.T) {
+	c, server := newTestClients(t)
+	defer server.Close()
+	ctx := context.TODO()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "nginx-ingress-controller",
+		},
+	}
+
+	_, err := c.CreateDeployment(ctx, "kube-system", deployment)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	_, err = c.CreateDeployment(ctx, "kube-system", deployment)
+	if !IsAlreadyExists(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	current, err := c.GetDeployment(ctx, "kube-system", "nginx-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	replicas := int32(3)
+	current.Spec.Replicas = &replicas
+
+	_, err = c.UpdateDeployment(ctx, "kube-system", current)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err = c.GetDeployment(ctx, "kube-system", "nginx-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if current.Spec.Replicas == nil || *current.Spec.Replicas != 3 {
+		t.Fatal("expected", 3, "got", current.Spec.Replicas)
+	}
+
+	err = c.DeleteDeployment(ctx, "kube-system", "nginx-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = c.DeleteDeployment(ctx, "kube-system", "nginx-ingress-controller")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	_, err = c.UpdateDeployment(ctx, "kube-system", current)
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+// Test_Clients_Services ensures services are managed through the REST clients
+// of a clientset.
+func Test_Clients_Services(t *testing.T) {
+	c, server := newTestClients(t)
+	defer server.Close()
+	ctx := context.TODO()
+
+	for _, name := range []string{"nginx-ingress-controller", "worker"} {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Labels: map[string]string{
+					"app": name,
+				},
+			},
+		}
+
+		_, err := c.CreateService(ctx, "al9qy", service)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	list, err := c.ListServices(ctx, "al9qy", "app=worker")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != "worker" {
+		t.Fatal("expected", "worker", "got", list.Items)
+	}
+
+	patched, err := c.PatchService(ctx, "al9qy", "worker", types.MergePatchType, []byte(`{"spec":{"ports":[{"name":"http","port":30010}]}}`))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(patched.Spec.Ports) != 1 || patched.Spec.Ports[0].Port != 30010 {
+		t.Fatal("expected", 30010, "got", patched.Spec.Ports)
+	}
+
+	current, err := c.GetService(ctx, "al9qy", "worker")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	current.Spec.Ports[0].Port = 30011
+
+	_, err = c.UpdateService(ctx, "al9qy", current)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err = c.GetService(ctx, "al9qy", "worker")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if current.Spec.Ports[0].Port != 30011 {
+		t.Fatal("expected", 30011, "got", current.Spec.Ports[0].Port)
+	}
+
+	err = c.DeleteService(ctx, "al9qy", "worker")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	_, err = c.GetService(ctx, "al9qy", "worker")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+// Test_Clients_Read ensures the objects the operator only reads or creates are
+// handled through the REST clients of a clientset.
+func Test_Clients_Read(t *testing.T) {
+	c, server := newTestClients(t)
+	defer server.Close()
+	ctx := context.TODO()
+
+	_, err := c.GetNamespace(ctx, "al9qy")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+	_, err = c.GetSecret(ctx, "default", "ingress-operator-certificate")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	objects := []struct {
+		Namespace string
+		Resource  string
+		Object    runtime.Object
+	}{
+		{
+			Resource: "namespaces",
+			Object:   &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "al9qy"}},
+		},
+		{
+			Namespace: "default",
+			Resource:  "secrets",
+			Object:    &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ingress-operator-certificate"}},
+		},
+		{
+			Namespace: "kube-system",
+			Resource:  "pods",
+			Object:    &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-ingress-controller-0"}},
+		},
+	}
+	for _, o := range objects {
+		err := c.coreV1.Post().NamespaceIfScoped(o.Namespace, o.Namespace != "").Resource(o.Resource).Body(o.Object).Do().Error()
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	namespace, err := c.GetNamespace(ctx, "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if namespace.Name != "al9qy" {
+		t.Fatal("expected", "al9qy", "got", namespace.Name)
+	}
+
+	secret, err := c.GetSecret(ctx, "default", "ingress-operator-certificate")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if secret.Name != "ingress-operator-certificate" {
+		t.Fatal("expected", "ingress-operator-certificate", "got", secret.Name)
+	}
+
+	pods, err := c.ListPods(ctx, "kube-system")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(pods.Items) != 1 || pods.Items[0].Name != "nginx-ingress-controller-0" {
+		t.Fatal("expected", "nginx-ingress-controller-0", "got", pods.Items)
+	}
+
+	event, err := c.CreateEvent(ctx, "kube-system", &corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: "ingress-operator.1"}})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if event.Name != "ingress-operator.1" {
+		t.Fatal("expected", "ingress-operator.1", "got", event.Name)
+	}
+}
+
+// Test_Clients_Context ensures cancelling the given context ends in-flight
+// requests, instead of waiting for the API server to respond.
+func Test_Clients_Context(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	k8sClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	c, err := New(Config{K8sClient: k8sClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	ch := make(chan error, 1)
+	go func() {
+		_, err := c.GetConfigMap(ctx, "kube-system", "ingress-controller")
+		ch <- err
+	}()
+
+	select {
+	case err := <-ch:
+		if err == nil {
+			t.Fatal("expected", "error", "got", nil)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected", "cancelled request", "got", "request in flight")
+	}
+}
+
+// newTestClients returns clients using the REST clients of a clientset
+// talking to the returned test server, which is the path taken in production.
+func newTestClients(t *testing.T) (*Clients, *httptest.Server) {
+	server := newTestServer()
+
+	k8sClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	c, err := New(Config{K8sClient: k8sClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return c, server
+}
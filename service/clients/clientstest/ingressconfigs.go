@@ -0,0 +1,156 @@
+// Package clientstest provides in-memory implementations of the clients of the
+// clients package for tests.
+package clientstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var ingressConfigsResource = schema.GroupResource{Group: v1alpha1.SchemeGroupVersion.Group, Resource: "ingressconfigs"}
+
+// IngressConfigs is an in-memory implementation of clients.IngressConfigs. It
+// answers like the API server, e.g. with not found errors for missing
+// IngressConfigs, and lists IngressConfigs in the order they were created. It
+// counts the reads and updates it serves, so that tests can tell how often the
+// API server would have been hit.
+type IngressConfigs struct {
+	items   map[string]*v1alpha1.IngressConfig
+	keys    []string
+	mutex   sync.Mutex
+	reads   int
+	updates int
+}
+
+// NewIngressConfigs returns an in-memory client holding the given
+// IngressConfigs.
+func NewIngressConfigs(items ...v1alpha1.IngressConfig) *IngressConfigs {
+	c := &IngressConfigs{
+		items: map[string]*v1alpha1.IngressConfig{},
+	}
+
+	for _, i := range items {
+		k := i.Namespace + "/" + i.Name
+		c.items[k] = i.DeepCopy()
+		c.keys = append(c.keys, k)
+	}
+
+	return c
+}
+
+func (c *IngressConfigs) CreateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	k := namespace + "/" + ingressConfig.Name
+	if _, ok := c.items[k]; ok {
+		return nil, errors.NewAlreadyExists(ingressConfigsResource, ingressConfig.Name)
+	}
+
+	created := ingressConfig.DeepCopy()
+	created.Namespace = namespace
+	c.items[k] = created
+	c.keys = append(c.keys, k)
+
+	return created.DeepCopy(), nil
+}
+
+func (c *IngressConfigs) DeleteIngressConfig(ctx context.Context, namespace, name string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	k := namespace + "/" + name
+	if _, ok := c.items[k]; !ok {
+		return errors.NewNotFound(ingressConfigsResource, name)
+	}
+
+	delete(c.items, k)
+	for i, existing := range c.keys {
+		if existing == k {
+			c.keys = append(c.keys[:i], c.keys[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (c *IngressConfigs) GetIngressConfig(ctx context.Context, namespace, name string) (*v1alpha1.IngressConfig, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.reads++
+
+	i, ok := c.items[namespace+"/"+name]
+	if !ok {
+		return nil, errors.NewNotFound(ingressConfigsResource, name)
+	}
+
+	return i.DeepCopy(), nil
+}
+
+// ListIngressConfigs lists the IngressConfigs of the given namespace, or of
+// all namespaces in case it is empty.
+func (c *IngressConfigs) ListIngressConfigs(ctx context.Context, namespace string) (*v1alpha1.IngressConfigList, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.reads++
+
+	list := &v1alpha1.IngressConfigList{}
+	for _, k := range c.keys {
+		i := c.items[k]
+		if namespace == "" || i.Namespace == namespace {
+			list.Items = append(list.Items, *i.DeepCopy())
+		}
+	}
+
+	return list, nil
+}
+
+func (c *IngressConfigs) UpdateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.updates++
+
+	k := namespace + "/" + ingressConfig.Name
+	if _, ok := c.items[k]; !ok {
+		return nil, errors.NewNotFound(ingressConfigsResource, ingressConfig.Name)
+	}
+
+	updated := ingressConfig.DeepCopy()
+	updated.Namespace = namespace
+	c.items[k] = updated
+
+	return updated.DeepCopy(), nil
+}
+
+// Len returns the number of IngressConfigs held.
+func (c *IngressConfigs) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.items)
+}
+
+// Reads returns the number of gets and lists served.
+func (c *IngressConfigs) Reads() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.reads
+}
+
+// Updates returns the number of updates served, including the ones failing
+// because the IngressConfig does not exist.
+func (c *IngressConfigs) Updates() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.updates
+}
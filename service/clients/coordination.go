@@ -0,0 +1,58 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// CoordinationGroupVersion is the API group version of the Lease objects the
+// coordination clients manage.
+var CoordinationGroupVersion = schema.GroupVersion{Group: "coordination.k8s.io", Version: "v1"}
+
+// NewCoordinationRESTClient creates a REST client for the coordination API
+// group using the given REST config. The vendored clientset predates Lease
+// objects, so they are handled as unstructured objects.
+func NewCoordinationRESTClient(restConfig *rest.Config) (rest.Interface, error) {
+	return newUnstructuredRESTClient(restConfig, CoordinationGroupVersion)
+}
+
+// CoordinationConfig represents the configuration used to create new
+// coordination clients.
+type CoordinationConfig struct {
+	RESTClient rest.Interface
+}
+
+// CoordinationClients implements Coordination using a REST client of the
+// coordination API group.
+type CoordinationClients struct {
+	restClient rest.Interface
+}
+
+// NewCoordination creates new configured coordination clients.
+func NewCoordination(config CoordinationConfig) (*CoordinationClients, error) {
+	if config.RESTClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RESTClient must not be empty", config)
+	}
+
+	c := &CoordinationClients{
+		restClient: config.RESTClient,
+	}
+
+	return c, nil
+}
+
+func (c *CoordinationClients) CreateLease(ctx context.Context, namespace string, lease *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c.restClient, "leases", namespace, lease)
+}
+
+func (c *CoordinationClients) GetLease(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	return getUnstructured(ctx, c.restClient, "leases", namespace, name)
+}
+
+func (c *CoordinationClients) UpdateLease(ctx context.Context, namespace string, lease *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return updateUnstructured(ctx, c.restClient, "leases", namespace, lease)
+}
@@ -0,0 +1,62 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+func Test_Clients_Coordination(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	restClient, err := NewCoordinationRESTClient(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	c, err := NewCoordination(CoordinationConfig{RESTClient: restClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx := context.TODO()
+
+	_, err = c.GetLease(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	lease := &unstructured.Unstructured{}
+	lease.SetAPIVersion(CoordinationGroupVersion.String())
+	lease.SetKind("Lease")
+	lease.SetName("ingress-operator-ingress-controller")
+	lease.SetNamespace("kube-system")
+	lease.Object["spec"] = map[string]interface{}{"holderIdentity": "ingress-operator-0"}
+
+	_, err = c.CreateLease(ctx, "kube-system", lease)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err := c.GetLease(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	unstructured.SetNestedField(current.Object, "ingress-operator-1", "spec", "holderIdentity")
+
+	_, err = c.UpdateLease(ctx, "kube-system", current)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err = c.GetLease(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	holder, _, _ := unstructured.NestedString(current.Object, "spec", "holderIdentity")
+	if holder != "ingress-operator-1" {
+		t.Fatal("expected", "ingress-operator-1", "got", holder)
+	}
+}
@@ -0,0 +1,33 @@
+package clients
+
+import (
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+// IsAlreadyExists asserts the Kubernetes API error of objects being created
+// while they exist, also when the error got masked.
+func IsAlreadyExists(err error) bool {
+	return errors.IsAlreadyExists(microerror.Cause(err))
+}
+
+// IsConflict asserts the Kubernetes API error of objects being updated from a
+// stale resource version, also when the error got masked.
+func IsConflict(err error) bool {
+	return errors.IsConflict(microerror.Cause(err))
+}
+
+// IsNotFound asserts the Kubernetes API error of objects not being found,
+// also when the error got masked.
+func IsNotFound(err error) bool {
+	return errors.IsNotFound(microerror.Cause(err))
+}
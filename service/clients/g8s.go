@@ -0,0 +1,156 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned/scheme"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+// G8sConfig represents the configuration used to create new Giant Swarm
+// clients.
+type G8sConfig struct {
+	G8sClient versioned.Interface
+}
+
+// G8sClients implements IngressConfigs using the REST client of the Giant
+// Swarm clientset, whose request builders take the given context.
+type G8sClients struct {
+	restClient rest.Interface
+}
+
+// NewG8s creates new configured Giant Swarm clients.
+func NewG8s(config G8sConfig) (*G8sClients, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+
+	c := &G8sClients{
+		restClient: config.G8sClient.CoreV1alpha1().RESTClient(),
+	}
+
+	return c, nil
+}
+
+func (c *G8sClients) CreateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error) {
+	result := &v1alpha1.IngressConfig{}
+	err := c.restClient.Post().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("ingressconfigs").
+		Body(ingressConfig).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *G8sClients) DeleteIngressConfig(ctx context.Context, namespace, name string) error {
+	err := c.restClient.Delete().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("ingressconfigs").
+		Name(name).
+		Body(&metav1.DeleteOptions{}).
+		Do().
+		Error()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (c *G8sClients) GetIngressConfig(ctx context.Context, namespace, name string) (*v1alpha1.IngressConfig, error) {
+	result := &v1alpha1.IngressConfig{}
+	err := c.restClient.Get().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("ingressconfigs").
+		Name(name).
+		VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *G8sClients) ListIngressConfigs(ctx context.Context, namespace string) (*v1alpha1.IngressConfigList, error) {
+	result := &v1alpha1.IngressConfigList{}
+	err := c.restClient.Get().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("ingressconfigs").
+		VersionedParams(&metav1.ListOptions{}, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+func (c *G8sClients) UpdateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error) {
+	result := &v1alpha1.IngressConfig{}
+	err := c.restClient.Put().
+		Context(ctx).
+		Namespace(namespace).
+		Resource("ingressconfigs").
+		Name(ingressConfig.Name).
+		Body(ingressConfig).
+		Do().
+		Into(result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return result, nil
+}
+
+// NewG8sRESTClient creates a REST client for the API group of the
+// IngressConfigs using the given REST config. It serves IngressConfigs as
+// unstructured objects, so that fields unknown to the vendored types are kept.
+func NewG8sRESTClient(restConfig *rest.Config) (rest.Interface, error) {
+	return newUnstructuredRESTClient(restConfig, v1alpha1.SchemeGroupVersion)
+}
+
+// G8sUnstructuredConfig represents the configuration used to create new
+// unstructured Giant Swarm clients.
+type G8sUnstructuredConfig struct {
+	RESTClient rest.Interface
+}
+
+// G8sUnstructuredClients implements UnstructuredIngressConfigs using a REST
+// client of the API group of the IngressConfigs.
+type G8sUnstructuredClients struct {
+	restClient rest.Interface
+}
+
+// NewG8sUnstructured creates new configured unstructured Giant Swarm clients.
+func NewG8sUnstructured(config G8sUnstructuredConfig) (*G8sUnstructuredClients, error) {
+	if config.RESTClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RESTClient must not be empty", config)
+	}
+
+	c := &G8sUnstructuredClients{
+		restClient: config.RESTClient,
+	}
+
+	return c, nil
+}
+
+func (c *G8sUnstructuredClients) GetIngressConfigUnstructured(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	return getUnstructured(ctx, c.restClient, "ingressconfigs", namespace, name)
+}
@@ -0,0 +1,120 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+func Test_Clients_G8s(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	g8sClient, err := versioned.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	c, err := NewG8s(G8sConfig{G8sClient: g8sClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx := context.TODO()
+
+	ingressConfig := &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy",
+			Namespace: "default",
+		},
+	}
+
+	_, err = c.CreateIngressConfig(ctx, "default", ingressConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err := c.GetIngressConfig(ctx, "default", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	current.Spec.GuestCluster.ID = "al9qy"
+
+	_, err = c.UpdateIngressConfig(ctx, "default", current)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err = c.GetIngressConfig(ctx, "default", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if current.Spec.GuestCluster.ID != "al9qy" {
+		t.Fatal("expected", "al9qy", "got", current.Spec.GuestCluster.ID)
+	}
+
+	err = c.DeleteIngressConfig(ctx, "default", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	_, err = c.GetIngressConfig(ctx, "default", "al9qy")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// Cancelled contexts end requests before they are sent.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = c.GetIngressConfig(cancelled, "default", "al9qy")
+	if err == nil || IsNotFound(err) {
+		t.Fatal("expected", "cancellation error", "got", err)
+	}
+}
+
+func Test_Clients_G8sUnstructured(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	restClient, err := NewG8sRESTClient(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	c, err := NewG8sUnstructured(G8sUnstructuredConfig{RESTClient: restClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx := context.TODO()
+
+	_, err = c.GetIngressConfigUnstructured(ctx, "default", "al9qy")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	ingressConfig := &unstructured.Unstructured{}
+	ingressConfig.SetAPIVersion(v1alpha1.SchemeGroupVersion.String())
+	ingressConfig.SetKind("IngressConfig")
+	ingressConfig.SetName("al9qy")
+	ingressConfig.SetNamespace("default")
+	ingressConfig.Object["spec"] = map[string]interface{}{"tlsPassthrough": true}
+
+	_, err = createUnstructured(ctx, restClient, "ingressconfigs", "default", ingressConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Fields unknown to the vendored types are kept.
+	current, err := c.GetIngressConfigUnstructured(ctx, "default", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	passthrough, _, _ := unstructured.NestedBool(current.Object, "spec", "tlsPassthrough")
+	if !passthrough {
+		t.Fatal("expected", true, "got", false)
+	}
+}
@@ -0,0 +1,52 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// LegacyGroupVersion is the API group version of the legacy ingresstpr
+// objects. They were served by the ingress-config.giantswarm.io third party
+// resource, which clusters migrated to a custom resource definition of the
+// same group, version and resource.
+var LegacyGroupVersion = schema.GroupVersion{Group: "giantswarm.io", Version: "v1"}
+
+// NewLegacyRESTClient creates a REST client for the API group of the legacy
+// ingresstpr objects using the given REST config. No typed client of them
+// exists anymore, so they are handled as unstructured objects.
+func NewLegacyRESTClient(restConfig *rest.Config) (rest.Interface, error) {
+	return newUnstructuredRESTClient(restConfig, LegacyGroupVersion)
+}
+
+// LegacyConfig represents the configuration used to create new legacy
+// clients.
+type LegacyConfig struct {
+	RESTClient rest.Interface
+}
+
+// LegacyClients implements Legacy using a REST client of the API group of the
+// legacy ingresstpr objects.
+type LegacyClients struct {
+	restClient rest.Interface
+}
+
+// NewLegacy creates new configured legacy clients.
+func NewLegacy(config LegacyConfig) (*LegacyClients, error) {
+	if config.RESTClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RESTClient must not be empty", config)
+	}
+
+	c := &LegacyClients{
+		restClient: config.RESTClient,
+	}
+
+	return c, nil
+}
+
+func (c *LegacyClients) ListIngressTPRs(ctx context.Context, namespace string) (*unstructured.UnstructuredList, error) {
+	return listUnstructured(ctx, c.restClient, "ingressconfigs", namespace)
+}
@@ -0,0 +1,48 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func Test_Clients_Legacy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path != "/apis/giantswarm.io/v1/namespaces/default/ingressconfigs" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`))
+			return
+		}
+		w.Write([]byte(`{"kind":"IngressConfigList","apiVersion":"giantswarm.io/v1","items":[{"kind":"IngressConfig","apiVersion":"giantswarm.io/v1","metadata":{"name":"al9qy","namespace":"default"}}]}`))
+	}))
+	defer server.Close()
+
+	restClient, err := NewLegacyRESTClient(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	c, err := NewLegacy(LegacyConfig{RESTClient: restClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx := context.TODO()
+
+	list, err := c.ListIngressTPRs(ctx, "default")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].GetName() != "al9qy" {
+		t.Fatal("expected", "al9qy", "got", list.Items)
+	}
+
+	_, err = c.ListIngressTPRs(ctx, "kube-system")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
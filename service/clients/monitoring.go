@@ -0,0 +1,166 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// MonitoringGroupVersion is the API group version of the Prometheus Operator
+// custom objects the monitoring clients manage.
+var MonitoringGroupVersion = schema.GroupVersion{Group: "monitoring.coreos.com", Version: "v1"}
+
+// NewMonitoringRESTClient creates a REST client for the Prometheus Operator
+// API group using the given REST config. No typed clientset of the
+// Prometheus Operator is vendored, so its custom objects are handled as
+// unstructured objects.
+func NewMonitoringRESTClient(restConfig *rest.Config) (rest.Interface, error) {
+	return newUnstructuredRESTClient(restConfig, MonitoringGroupVersion)
+}
+
+// MonitoringConfig represents the configuration used to create new monitoring
+// clients.
+type MonitoringConfig struct {
+	RESTClient rest.Interface
+}
+
+// MonitoringClients implements Monitoring using a REST client of the
+// Prometheus Operator API group.
+type MonitoringClients struct {
+	restClient rest.Interface
+}
+
+// NewMonitoring creates new configured monitoring clients.
+func NewMonitoring(config MonitoringConfig) (*MonitoringClients, error) {
+	if config.RESTClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RESTClient must not be empty", config)
+	}
+
+	c := &MonitoringClients{
+		restClient: config.RESTClient,
+	}
+
+	return c, nil
+}
+
+func (c *MonitoringClients) CreatePrometheusRule(ctx context.Context, namespace string, prometheusRule *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return c.create(ctx, "prometheusrules", namespace, prometheusRule)
+}
+
+func (c *MonitoringClients) CreateServiceMonitor(ctx context.Context, namespace string, serviceMonitor *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return c.create(ctx, "servicemonitors", namespace, serviceMonitor)
+}
+
+func (c *MonitoringClients) GetPrometheusRule(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	return c.get(ctx, "prometheusrules", namespace, name)
+}
+
+func (c *MonitoringClients) GetServiceMonitor(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	return c.get(ctx, "servicemonitors", namespace, name)
+}
+
+func (c *MonitoringClients) UpdatePrometheusRule(ctx context.Context, namespace string, prometheusRule *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return c.update(ctx, "prometheusrules", namespace, prometheusRule)
+}
+
+func (c *MonitoringClients) UpdateServiceMonitor(ctx context.Context, namespace string, serviceMonitor *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return c.update(ctx, "servicemonitors", namespace, serviceMonitor)
+}
+
+func (c *MonitoringClients) create(ctx context.Context, resource, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return createUnstructured(ctx, c.restClient, resource, namespace, obj)
+}
+
+func (c *MonitoringClients) get(ctx context.Context, resource, namespace, name string) (*unstructured.Unstructured, error) {
+	return getUnstructured(ctx, c.restClient, resource, namespace, name)
+}
+
+func (c *MonitoringClients) update(ctx context.Context, resource, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return updateUnstructured(ctx, c.restClient, resource, namespace, obj)
+}
+
+// newUnstructuredRESTClient creates a REST client for the given API group
+// version, whose objects are handled as unstructured objects.
+func newUnstructuredRESTClient(restConfig *rest.Config, groupVersion schema.GroupVersion) (rest.Interface, error) {
+	c := rest.CopyConfig(restConfig)
+
+	c.APIPath = "/apis"
+	c.ContentType = runtime.ContentTypeJSON
+	c.GroupVersion = &groupVersion
+	c.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+
+	restClient, err := rest.RESTClientFor(c)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return restClient, nil
+}
+
+func createUnstructured(ctx context.Context, restClient rest.Interface, resource, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	body, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	b, err := restClient.Post().Context(ctx).Namespace(namespace).Resource(resource).Body(body).Do().Raw()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return toUnstructured(b)
+}
+
+func getUnstructured(ctx context.Context, restClient rest.Interface, resource, namespace, name string) (*unstructured.Unstructured, error) {
+	b, err := restClient.Get().Context(ctx).Namespace(namespace).Resource(resource).Name(name).Do().Raw()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return toUnstructured(b)
+}
+
+func listUnstructured(ctx context.Context, restClient rest.Interface, resource, namespace string) (*unstructured.UnstructuredList, error) {
+	b, err := restClient.Get().Context(ctx).Namespace(namespace).Resource(resource).Do().Raw()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	list := &unstructured.UnstructuredList{}
+	err = list.UnmarshalJSON(b)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list, nil
+}
+
+func updateUnstructured(ctx context.Context, restClient rest.Interface, resource, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	body, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	b, err := restClient.Put().Context(ctx).Namespace(namespace).Resource(resource).Name(obj.GetName()).Body(body).Do().Raw()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return toUnstructured(b)
+}
+
+func toUnstructured(b []byte) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	err := obj.UnmarshalJSON(b)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return obj, nil
+}
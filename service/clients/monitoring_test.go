@@ -0,0 +1,66 @@
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+func Test_Clients_Monitoring(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	restClient, err := NewMonitoringRESTClient(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	c, err := NewMonitoring(MonitoringConfig{RESTClient: restClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx := context.TODO()
+
+	_, err = c.GetServiceMonitor(ctx, "giantswarm", "ingress-operator")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetAPIVersion(MonitoringGroupVersion.String())
+	serviceMonitor.SetKind("ServiceMonitor")
+	serviceMonitor.SetName("ingress-operator")
+	serviceMonitor.SetNamespace("giantswarm")
+
+	_, err = c.CreateServiceMonitor(ctx, "giantswarm", serviceMonitor)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err := c.GetServiceMonitor(ctx, "giantswarm", "ingress-operator")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	current.SetLabels(map[string]string{"app": "ingress-operator"})
+
+	_, err = c.UpdateServiceMonitor(ctx, "giantswarm", current)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	current, err = c.GetServiceMonitor(ctx, "giantswarm", "ingress-operator")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if current.GetLabels()["app"] != "ingress-operator" {
+		t.Fatal("expected", "ingress-operator", "got", current.GetLabels()["app"])
+	}
+
+	// Prometheus rules are separate objects of the same name.
+	_, err = c.GetPrometheusRule(ctx, "giantswarm", "ingress-operator")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
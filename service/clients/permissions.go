@@ -0,0 +1,90 @@
+package clients
+
+import (
+	"sort"
+
+	"github.com/giantswarm/ingress-operator/pkg/rbac"
+)
+
+// permissions maps the operations of Interface, IngressConfigs,
+// UnstructuredIngressConfigs, Coordination, Legacy and Monitoring to the
+// permissions they require. Every operation has to be listed here, which is ensured by the
+// tests of this package.
+var permissions = map[string]rbac.Permission{
+	"CreateConfigMap": {Resource: "configmaps", Verb: "create"},
+	"DeleteConfigMap": {Resource: "configmaps", Verb: "delete"},
+	"GetConfigMap":    {Resource: "configmaps", Verb: "get"},
+	"ListConfigMaps":  {Resource: "configmaps", Verb: "list"},
+	"PatchConfigMap":  {Resource: "configmaps", Verb: "patch"},
+	"UpdateConfigMap": {Resource: "configmaps", Verb: "update"},
+
+	"CreateDeployment": {APIGroup: "apps", Resource: "deployments", Verb: "create"},
+	"DeleteDeployment": {APIGroup: "apps", Resource: "deployments", Verb: "delete"},
+	"GetDeployment":    {APIGroup: "apps", Resource: "deployments", Verb: "get"},
+	"UpdateDeployment": {APIGroup: "apps", Resource: "deployments", Verb: "update"},
+
+	"CreateEvent": {Resource: "events", Verb: "create"},
+
+	"GetNamespace": {Resource: "namespaces", Verb: "get"},
+
+	"ListPods": {Resource: "pods", Verb: "list"},
+
+	// Secrets are only read in the namespace cert-operator issues the
+	// certificates of guest clusters into.
+	"GetSecret": {Namespaced: true, Resource: "secrets", Verb: "get"},
+
+	"CreateService": {Resource: "services", Verb: "create"},
+	"DeleteService": {Resource: "services", Verb: "delete"},
+	"GetService":    {Resource: "services", Verb: "get"},
+	"ListServices":  {Resource: "services", Verb: "list"},
+	"PatchService":  {Resource: "services", Verb: "patch"},
+	"UpdateService": {Resource: "services", Verb: "update"},
+
+	"CreateIngressConfig": {APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "create"},
+	"DeleteIngressConfig": {APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "delete"},
+	"GetIngressConfig":    {APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "get"},
+	"ListIngressConfigs":  {APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "list"},
+	"UpdateIngressConfig": {APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "update"},
+
+	"GetIngressConfigUnstructured": {APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "get"},
+
+	"CreateLease": {APIGroup: "coordination.k8s.io", Resource: "leases", Verb: "create"},
+	"GetLease":    {APIGroup: "coordination.k8s.io", Resource: "leases", Verb: "get"},
+	"UpdateLease": {APIGroup: "coordination.k8s.io", Resource: "leases", Verb: "update"},
+
+	"ListIngressTPRs": {APIGroup: "giantswarm.io", Resource: "ingressconfigs", Verb: "list"},
+
+	"CreatePrometheusRule": {APIGroup: "monitoring.coreos.com", Resource: "prometheusrules", Verb: "create"},
+	"CreateServiceMonitor": {APIGroup: "monitoring.coreos.com", Resource: "servicemonitors", Verb: "create"},
+	"GetPrometheusRule":    {APIGroup: "monitoring.coreos.com", Resource: "prometheusrules", Verb: "get"},
+	"GetServiceMonitor":    {APIGroup: "monitoring.coreos.com", Resource: "servicemonitors", Verb: "get"},
+	"UpdatePrometheusRule": {APIGroup: "monitoring.coreos.com", Resource: "prometheusrules", Verb: "update"},
+	"UpdateServiceMonitor": {APIGroup: "monitoring.coreos.com", Resource: "servicemonitors", Verb: "update"},
+}
+
+// nonResourcePermissions are the permissions of the requests made with the
+// clients which are not operations of the interfaces above. The Kubernetes
+// health check gets the root path of the API server.
+var nonResourcePermissions = []rbac.Permission{
+	{NonResourceURL: "/", Verb: "get"},
+}
+
+// Permissions returns the permissions required by all operations of
+// Interface, IngressConfigs, UnstructuredIngressConfigs, Coordination, Legacy
+// and Monitoring, sorted by operation name, followed by the permissions of
+// the requests of non-resource URLs.
+func Permissions() []rbac.Permission {
+	var names []string
+	for n := range permissions {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var l []rbac.Permission
+	for _, n := range names {
+		l = append(l, permissions[n])
+	}
+	l = append(l, nonResourcePermissions...)
+
+	return l
+}
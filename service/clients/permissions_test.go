@@ -0,0 +1,35 @@
+package clients
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test_Clients_Permissions ensures every operation of Interface,
+// IngressConfigs, UnstructuredIngressConfigs, Coordination, Legacy and
+// Monitoring declares the permission it requires, and that no permission is declared for
+// operations which do not exist.
+func Test_Clients_Permissions(t *testing.T) {
+	operations := map[string]bool{}
+	for _, i := range []reflect.Type{reflect.TypeOf((*Interface)(nil)).Elem(), reflect.TypeOf((*IngressConfigs)(nil)).Elem(), reflect.TypeOf((*UnstructuredIngressConfigs)(nil)).Elem(), reflect.TypeOf((*Coordination)(nil)).Elem(), reflect.TypeOf((*Legacy)(nil)).Elem(), reflect.TypeOf((*Monitoring)(nil)).Elem()} {
+		for n := 0; n < i.NumMethod(); n++ {
+			operations[i.Method(n).Name] = true
+		}
+	}
+
+	for o := range operations {
+		_, ok := permissions[o]
+		if !ok {
+			t.Fatal("expected", "permission of "+o, "got", nil)
+		}
+	}
+	for o := range permissions {
+		if !operations[o] {
+			t.Fatal("expected", "operation "+o, "got", nil)
+		}
+	}
+
+	if len(Permissions()) != len(operations)+len(nonResourcePermissions) {
+		t.Fatal("expected", len(operations)+len(nonResourcePermissions), "got", len(Permissions()))
+	}
+}
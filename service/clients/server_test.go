@@ -0,0 +1,182 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// testListKinds maps the resources listed in the tests to the kinds of their
+// lists, which the typed clients decode into.
+var testListKinds = map[string]string{
+	"configmaps":     "ConfigMapList",
+	"ingressconfigs": "IngressConfigList",
+	"pods":           "PodList",
+	"services":       "ServiceList",
+}
+
+// newTestServer returns a server storing the objects put and posted to it by
+// path, which is enough to exercise the REST clients of the clientsets. It
+// lists the objects of collections filtered by label selector, applies JSON
+// merge patches and answers with the API errors of missing and existing
+// objects.
+func newTestServer() *httptest.Server {
+	var mutex sync.Mutex
+	objects := map[string][]byte{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		groupVersion, rest := testSplitPath(r.URL.Path)
+		collection := len(rest)%2 == 1
+
+		switch r.Method {
+		case http.MethodGet:
+			if collection {
+				selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+				if err != nil {
+					testWriteStatus(w, http.StatusBadRequest, "BadRequest")
+					return
+				}
+				b := testList(objects, r.URL.Path, groupVersion, testListKinds[rest[len(rest)-1]], selector)
+				w.Write(b)
+				return
+			}
+
+			b, ok := objects[r.URL.Path]
+			if !ok {
+				testWriteStatus(w, http.StatusNotFound, "NotFound")
+				return
+			}
+			w.Write(b)
+		case http.MethodPost, http.MethodPut:
+			b, _ := ioutil.ReadAll(r.Body)
+			obj := &unstructured.Unstructured{}
+			obj.UnmarshalJSON(b)
+
+			path := r.URL.Path
+			if r.Method == http.MethodPost {
+				path += "/" + obj.GetName()
+			}
+			_, ok := objects[path]
+			if r.Method == http.MethodPost && ok {
+				testWriteStatus(w, http.StatusConflict, "AlreadyExists")
+				return
+			}
+			if r.Method == http.MethodPut && !ok {
+				testWriteStatus(w, http.StatusNotFound, "NotFound")
+				return
+			}
+			objects[path] = b
+			w.Write(b)
+		case http.MethodPatch:
+			if r.Header.Get("Content-Type") != string(types.MergePatchType) {
+				testWriteStatus(w, http.StatusUnsupportedMediaType, "UnsupportedMediaType")
+				return
+			}
+			b, ok := objects[r.URL.Path]
+			if !ok {
+				testWriteStatus(w, http.StatusNotFound, "NotFound")
+				return
+			}
+
+			var obj, patch map[string]interface{}
+			json.Unmarshal(b, &obj)
+			p, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(p, &patch)
+
+			b, _ = json.Marshal(testMergePatch(obj, patch))
+			objects[r.URL.Path] = b
+			w.Write(b)
+		case http.MethodDelete:
+			_, ok := objects[r.URL.Path]
+			if !ok {
+				testWriteStatus(w, http.StatusNotFound, "NotFound")
+				return
+			}
+			delete(objects, r.URL.Path)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Success"}`))
+		}
+	}))
+}
+
+// testSplitPath splits the given request path into the API group version and
+// the segments following it, e.g. namespaces, kube-system and configmaps.
+func testSplitPath(path string) (string, []string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	if segments[0] == "api" {
+		return segments[1], segments[2:]
+	}
+
+	return segments[1] + "/" + segments[2], segments[3:]
+}
+
+func testList(objects map[string][]byte, path, groupVersion, kind string, selector labels.Selector) []byte {
+	var paths []string
+	for p := range objects {
+		if strings.HasPrefix(p, path+"/") && !strings.Contains(strings.TrimPrefix(p, path+"/"), "/") {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	items := []json.RawMessage{}
+	for _, p := range paths {
+		obj := &unstructured.Unstructured{}
+		obj.UnmarshalJSON(objects[p])
+		if selector.Matches(labels.Set(obj.GetLabels())) {
+			items = append(items, objects[p])
+		}
+	}
+
+	b, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": groupVersion,
+		"items":      items,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{},
+	})
+
+	return b
+}
+
+// testMergePatch applies the given JSON merge patch to the given object.
+func testMergePatch(obj, patch map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		obj = map[string]interface{}{}
+	}
+
+	for k, v := range patch {
+		if v == nil {
+			delete(obj, k)
+			continue
+		}
+
+		p, ok := v.(map[string]interface{})
+		if !ok {
+			obj[k] = v
+			continue
+		}
+		o, _ := obj[k].(map[string]interface{})
+		obj[k] = testMergePatch(o, p)
+	}
+
+	return obj
+}
+
+func testWriteStatus(w http.ResponseWriter, code int, reason string) {
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":%q,"code":%d}`, reason, code)
+}
@@ -0,0 +1,117 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Interface exposes the typed Kubernetes operations the resources of the
+// operator need. All operations take a context, whose cancellation ends their
+// in-flight requests.
+type Interface interface {
+	ConfigMaps
+	Deployments
+	Events
+	Namespaces
+	Pods
+	Secrets
+	Services
+}
+
+// ConfigMaps exposes the config map operations.
+type ConfigMaps interface {
+	CreateConfigMap(ctx context.Context, namespace string, configMap *corev1.ConfigMap) (*corev1.ConfigMap, error)
+	DeleteConfigMap(ctx context.Context, namespace, name string) error
+	GetConfigMap(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
+	ListConfigMaps(ctx context.Context, namespace, labelSelector string) (*corev1.ConfigMapList, error)
+	PatchConfigMap(ctx context.Context, namespace, name string, pt types.PatchType, data []byte) (*corev1.ConfigMap, error)
+	UpdateConfigMap(ctx context.Context, namespace string, configMap *corev1.ConfigMap) (*corev1.ConfigMap, error)
+}
+
+// Deployments exposes the deployment operations.
+type Deployments interface {
+	CreateDeployment(ctx context.Context, namespace string, deployment *appsv1.Deployment) (*appsv1.Deployment, error)
+	DeleteDeployment(ctx context.Context, namespace, name string) error
+	GetDeployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error)
+	UpdateDeployment(ctx context.Context, namespace string, deployment *appsv1.Deployment) (*appsv1.Deployment, error)
+}
+
+// Events exposes the event operations.
+type Events interface {
+	CreateEvent(ctx context.Context, namespace string, event *corev1.Event) (*corev1.Event, error)
+}
+
+// Namespaces exposes the namespace operations.
+type Namespaces interface {
+	GetNamespace(ctx context.Context, name string) (*corev1.Namespace, error)
+}
+
+// Pods exposes the pod operations.
+type Pods interface {
+	ListPods(ctx context.Context, namespace string) (*corev1.PodList, error)
+}
+
+// Secrets exposes the secret operations.
+type Secrets interface {
+	GetSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error)
+}
+
+// Services exposes the service operations.
+type Services interface {
+	CreateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error)
+	DeleteService(ctx context.Context, namespace, name string) error
+	GetService(ctx context.Context, namespace, name string) (*corev1.Service, error)
+	ListServices(ctx context.Context, namespace, labelSelector string) (*corev1.ServiceList, error)
+	PatchService(ctx context.Context, namespace, name string, pt types.PatchType, data []byte) (*corev1.Service, error)
+	UpdateService(ctx context.Context, namespace string, service *corev1.Service) (*corev1.Service, error)
+}
+
+// IngressConfigs exposes the IngressConfig operations. They are implemented by
+// G8sClients, since IngressConfigs are served by the Giant Swarm clientset.
+type IngressConfigs interface {
+	CreateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error)
+	DeleteIngressConfig(ctx context.Context, namespace, name string) error
+	GetIngressConfig(ctx context.Context, namespace, name string) (*v1alpha1.IngressConfig, error)
+	ListIngressConfigs(ctx context.Context, namespace string) (*v1alpha1.IngressConfigList, error)
+	UpdateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error)
+}
+
+// UnstructuredIngressConfigs exposes the IngressConfig operations serving
+// IngressConfigs as stored by the API server. They are implemented by
+// G8sUnstructuredClients, since the typed clientset drops the fields unknown
+// to the vendored types.
+type UnstructuredIngressConfigs interface {
+	GetIngressConfigUnstructured(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error)
+}
+
+// Coordination exposes the Lease operations. They are implemented by
+// CoordinationClients, since the vendored clientset predates Lease objects.
+type Coordination interface {
+	CreateLease(ctx context.Context, namespace string, lease *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	GetLease(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error)
+	UpdateLease(ctx context.Context, namespace string, lease *unstructured.Unstructured) (*unstructured.Unstructured, error)
+}
+
+// Legacy exposes the read-only operations of the legacy ingresstpr objects.
+// They are implemented by LegacyClients, since no typed client of them exists
+// anymore.
+type Legacy interface {
+	ListIngressTPRs(ctx context.Context, namespace string) (*unstructured.UnstructuredList, error)
+}
+
+// Monitoring exposes the operations of the Prometheus Operator custom objects.
+// They are implemented by MonitoringClients, since no typed clientset of the
+// Prometheus Operator is available.
+type Monitoring interface {
+	CreatePrometheusRule(ctx context.Context, namespace string, prometheusRule *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	CreateServiceMonitor(ctx context.Context, namespace string, serviceMonitor *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	GetPrometheusRule(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error)
+	GetServiceMonitor(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error)
+	UpdatePrometheusRule(ctx context.Context, namespace string, prometheusRule *unstructured.Unstructured) (*unstructured.Unstructured, error)
+	UpdateServiceMonitor(ctx context.Context, namespace string, serviceMonitor *unstructured.Unstructured) (*unstructured.Unstructured, error)
+}
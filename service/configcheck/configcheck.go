@@ -0,0 +1,425 @@
+// Package configcheck validates the configuration of the daemon command as a
+// whole before the service gets created. Invalid flags otherwise surface one
+// at a time as errors deep inside the creation of the service, after clients
+// for Kubernetes got created already. All problems are collected instead, so
+// that they can be fixed in one go.
+package configcheck
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/giantswarm/ingress-operator/flag"
+	"github.com/giantswarm/ingress-operator/pkg/logger"
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/controller/v2"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/ordering"
+	"github.com/giantswarm/ingress-operator/service/monitoring"
+	"github.com/giantswarm/ingress-operator/service/pool"
+	"github.com/giantswarm/ingress-operator/service/servicepolicy"
+)
+
+// Problem is a flag whose configured value is invalid.
+type Problem struct {
+	// Flag is the name of the flag, which is also its key in config files.
+	Flag string
+	// Message describes what is wrong with the configured value and what is
+	// expected instead.
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("--%s: %s", p.Flag, p.Message)
+}
+
+// Check returns the problems of all flags configured in the given viper,
+// sorted by flag. Flags of disabled features are only checked in case they
+// are used.
+func Check(f *flag.Flag, v *viper.Viper) []Problem {
+	c := &checker{
+		viper: v,
+	}
+
+	c.oneOf(f.Log.Format, logger.Formats())
+
+	c.file(f.Service.Admin.ClientCAFile)
+	c.file(f.Service.Admin.TokenFile)
+
+	if c.bool(f.Service.Adoption.Enabled) {
+		c.notEmpty(f.Service.Adoption.GuestClusterService)
+		c.notEmpty(f.Service.Adoption.IngressController.ConfigMap)
+		c.namespace(f.Service.Adoption.IngressController.Namespace)
+		c.notEmpty(f.Service.Adoption.IngressController.Service)
+		c.positive(f.Service.Adoption.Interval)
+		c.optionalNamespace(f.Service.Adoption.Namespace)
+	}
+
+	if c.bool(f.Service.Bridge.Enabled) {
+		c.notEmpty(f.Service.Bridge.GuestCluster.Service)
+		c.notEmpty(f.Service.Bridge.IngressController.ConfigMap)
+		c.namespace(f.Service.Bridge.IngressController.Namespace)
+		c.notEmpty(f.Service.Bridge.IngressController.Service)
+		c.optionalNamespace(f.Service.Bridge.Namespace)
+	}
+
+	if c.bool(f.Service.Controller.Canary.Enabled) && c.viper.GetString(f.Service.Controller.Canary.ResourceOrder) != "" {
+		c.order(f.Service.Controller.Canary.ResourceOrder)
+	}
+	if len(c.viper.GetStringSlice(f.Service.Controller.Certificates.Components)) > 0 {
+		c.namespace(f.Service.Controller.Certificates.Namespace)
+	}
+	if n, ok := c.int(f.Service.Controller.Concurrency); ok && n <= 0 {
+		c.add(f.Service.Controller.Concurrency, "must be greater than 0, got %d", n)
+	}
+	c.notNegative(f.Service.Controller.DeletionSLA)
+	c.positive(f.Service.Controller.DeprecationInterval)
+	c.check(f.Service.Controller.IngressControllers, v2.ValidateIngressControllers(c.viper.GetStringSlice(f.Service.Controller.IngressControllers)))
+	if c.bool(f.Service.Controller.Lease.Enabled) {
+		if d, ok := c.duration(f.Service.Controller.Lease.Duration); ok && d < time.Second {
+			c.add(f.Service.Controller.Lease.Duration, "must be at least 1s, got %s", d)
+		}
+		c.positive(f.Service.Controller.Lease.Timeout)
+	}
+	c.notNegative(f.Service.Controller.LegacyInterval)
+	if n, ok := c.int(f.Service.Controller.ListLimit); ok && n < 0 {
+		c.add(f.Service.Controller.ListLimit, "must not be negative, got %d", n)
+	}
+	for _, n := range c.viper.GetStringSlice(f.Service.Controller.Namespaces) {
+		c.namespaceValue(f.Service.Controller.Namespaces, strings.TrimSpace(n))
+	}
+	c.positive(f.Service.Controller.Quarantine.RetryInterval)
+	if n, ok := c.int(f.Service.Controller.Quarantine.Threshold); ok && n < 0 {
+		c.add(f.Service.Controller.Quarantine.Threshold, "must not be negative, got %d", n)
+	}
+	c.order(f.Service.Controller.ResourceOrder)
+	c.notNegative(f.Service.Controller.ResultCacheTTL)
+	if n, ok := c.int(f.Service.Controller.ResyncRate); ok && n <= 0 {
+		c.add(f.Service.Controller.ResyncRate, "must be greater than 0, got %d", n)
+	}
+	if c.bool(f.Service.Controller.SelfTest.Enabled) || c.bool(f.Service.Controller.Canary.SelfTest) {
+		c.positive(f.Service.Controller.SelfTest.Timeout)
+	}
+	c.positive(f.Service.Controller.StartupReportTimeout)
+
+	// Available ports and port ranges are parsed together, so that ports
+	// configured twice are detected as overlap. The overlap is reported for
+	// the port ranges in case the available ports are valid on their own.
+	_, err := allocator.ParsePortRanges(c.viper.GetStringSlice(f.Service.HostCluster.AvailablePorts))
+	if err != nil {
+		c.check(f.Service.HostCluster.AvailablePorts, err)
+	} else {
+		var list []string
+		list = append(list, c.viper.GetStringSlice(f.Service.HostCluster.AvailablePorts)...)
+		list = append(list, c.viper.GetStringSlice(f.Service.HostCluster.PortRanges)...)
+
+		_, err := allocator.ParsePortRanges(list)
+		c.check(f.Service.HostCluster.PortRanges, err)
+	}
+	if c.bool(f.Service.HostCluster.Dedicated.Enabled) {
+		c.notEmpty(f.Service.HostCluster.Dedicated.Image)
+		c.namespace(f.Service.HostCluster.Dedicated.Namespace)
+		c.notEmpty(f.Service.HostCluster.Dedicated.ServiceAccount)
+	}
+	{
+		configMapSelector := c.selector(f.Service.HostCluster.IngressController.ConfigMapSelector)
+		serviceSelector := c.selector(f.Service.HostCluster.IngressController.ServiceSelector)
+		if configMapSelector != "" && serviceSelector == "" {
+			c.add(f.Service.HostCluster.IngressController.ServiceSelector, "must not be empty in case --%s is set", f.Service.HostCluster.IngressController.ConfigMapSelector)
+		}
+		if serviceSelector != "" && configMapSelector == "" {
+			c.add(f.Service.HostCluster.IngressController.ConfigMapSelector, "must not be empty in case --%s is set", f.Service.HostCluster.IngressController.ServiceSelector)
+		}
+	}
+	c.name(f.Service.HostCluster.Ledger.Name)
+	c.namespace(f.Service.HostCluster.Ledger.Namespace)
+	if n, ok := c.int(f.Service.HostCluster.MaxServicePorts); ok && n < 0 {
+		c.add(f.Service.HostCluster.MaxServicePorts, "must not be negative, got %d", n)
+	}
+	c.notNegative(f.Service.HostCluster.PortCoolingOff)
+	{
+		_, err := servicepolicy.New(c.viper.GetStringSlice(f.Service.HostCluster.PreservedServiceFields))
+		c.check(f.Service.HostCluster.PreservedServiceFields, err)
+	}
+	{
+		_, err := allocator.ParsePorts(c.viper.GetStringSlice(f.Service.HostCluster.ReservedPorts))
+		c.check(f.Service.HostCluster.ReservedPorts, err)
+	}
+	c.name(f.Service.HostCluster.StreamSnippets)
+	{
+		thresholds, err := pool.ParseThresholds(c.viper.GetStringSlice(f.Service.HostCluster.UtilizationThresholds))
+		c.check(f.Service.HostCluster.UtilizationThresholds, err)
+		for _, t := range thresholds {
+			if t <= 0 || t > 1 {
+				c.add(f.Service.HostCluster.UtilizationThresholds, "thresholds must be fractions between 0 and 1, got %v", t)
+			}
+		}
+	}
+
+	// The address and the TLS files are not used in case a kubeconfig or the
+	// in-cluster config is used.
+	if c.viper.GetString(f.Service.Kubernetes.KubeConfig.Path) != "" {
+		c.file(f.Service.Kubernetes.KubeConfig.Path)
+	} else if !c.bool(f.Service.Kubernetes.InCluster) {
+		c.address(f.Service.Kubernetes.Address)
+		c.file(f.Service.Kubernetes.TLS.CAFile)
+		c.file(f.Service.Kubernetes.TLS.CrtFile)
+		c.file(f.Service.Kubernetes.TLS.KeyFile)
+
+		crtFile := c.viper.GetString(f.Service.Kubernetes.TLS.CrtFile)
+		keyFile := c.viper.GetString(f.Service.Kubernetes.TLS.KeyFile)
+		if crtFile != "" && keyFile == "" {
+			c.add(f.Service.Kubernetes.TLS.KeyFile, "must not be empty in case --%s is set", f.Service.Kubernetes.TLS.CrtFile)
+		}
+		if keyFile != "" && crtFile == "" {
+			c.add(f.Service.Kubernetes.TLS.CrtFile, "must not be empty in case --%s is set", f.Service.Kubernetes.TLS.KeyFile)
+		}
+	}
+	c.file(f.Service.Kubernetes.TokenFile)
+	if t, ok := c.float(f.Service.Kubernetes.Breaker.Threshold); ok {
+		if t < 0 || t > 1 {
+			c.add(f.Service.Kubernetes.Breaker.Threshold, "must be between 0 and 1, got %v", t)
+		} else if t > 0 {
+			c.positive(f.Service.Kubernetes.Breaker.CoolDown)
+			if n, ok := c.int(f.Service.Kubernetes.Breaker.MinRequests); ok && n <= 0 {
+				c.add(f.Service.Kubernetes.Breaker.MinRequests, "must be greater than 0, got %d", n)
+			}
+			c.positive(f.Service.Kubernetes.Breaker.Window)
+		}
+	}
+	c.notNegative(f.Service.Kubernetes.Healthz.Interval)
+	c.positive(f.Service.Kubernetes.Healthz.Timeout)
+	c.notNegative(f.Service.Kubernetes.KeepAlive)
+	c.positive(f.Service.Kubernetes.Timeout.Dial)
+	c.positive(f.Service.Kubernetes.Timeout.Request)
+	c.positive(f.Service.Kubernetes.Timeout.TLSHandshake)
+
+	if c.bool(f.Service.Monitoring.Enabled) {
+		c.positive(f.Service.Monitoring.Interval)
+		_, err := monitoring.ParseLabels(c.viper.GetStringSlice(f.Service.Monitoring.Labels))
+		c.check(f.Service.Monitoring.Labels, err)
+		c.namespace(f.Service.Monitoring.Namespace)
+	}
+
+	sort.SliceStable(c.problems, func(i, j int) bool {
+		return c.problems[i].Flag < c.problems[j].Flag
+	})
+
+	return c.problems
+}
+
+// Render returns the given problems as human readable list, one per line.
+func Render(problems []Problem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d invalid flags, fix them and start the operator again:\n", len(problems))
+	for _, p := range problems {
+		fmt.Fprintf(&b, "  %s\n", p)
+	}
+
+	return b.String()
+}
+
+// checker collects the problems of single flags.
+type checker struct {
+	viper *viper.Viper
+
+	problems []Problem
+}
+
+func (c *checker) add(key, format string, v ...interface{}) {
+	c.problems = append(c.problems, Problem{Flag: key, Message: fmt.Sprintf(format, v...)})
+}
+
+// check adds the message of the given error as problem of the given flag, in
+// case it is not nil.
+func (c *checker) check(key string, err error) {
+	if err == nil {
+		return
+	}
+
+	// The kind of the cause is not helpful for fixing the flag, e.g. "invalid
+	// config error", so only the message is added.
+	c.add(key, "%s", strings.TrimSuffix(err.Error(), ": "+microerror.Cause(err).Error()))
+}
+
+func (c *checker) address(key string) {
+	s := c.viper.GetString(key)
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		c.add(key, "must be an http or https URL, e.g. https://127.0.0.1:6443, got %q", s)
+	}
+}
+
+func (c *checker) bool(key string) bool {
+	b, err := cast.ToBoolE(c.value(key))
+	if err != nil {
+		c.add(key, "must be true or false, got %q", c.viper.GetString(key))
+		return false
+	}
+
+	return b
+}
+
+// duration returns the duration of the given flag and whether it could be
+// parsed.
+func (c *checker) duration(key string) (time.Duration, bool) {
+	d, err := cast.ToDurationE(c.value(key))
+	if err != nil {
+		c.add(key, "must be a duration, e.g. 30s or 5m, got %q", c.viper.GetString(key))
+		return 0, false
+	}
+
+	return d, true
+}
+
+// file adds a problem in case the file of the given flag is not readable. An
+// empty flag is no problem, since all file flags are optional.
+func (c *checker) file(key string) {
+	p := c.viper.GetString(key)
+	if p == "" {
+		return
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		c.add(key, "file must be readable, %s", err.Error())
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		c.add(key, "file must be readable, %s", err.Error())
+		return
+	}
+	if info.IsDir() {
+		c.add(key, "must be a file, got directory %q", p)
+	}
+}
+
+func (c *checker) float(key string) (float64, bool) {
+	f, err := cast.ToFloat64E(c.value(key))
+	if err != nil {
+		c.add(key, "must be a number, got %q", c.viper.GetString(key))
+		return 0, false
+	}
+
+	return f, true
+}
+
+func (c *checker) int(key string) (int, bool) {
+	n, err := cast.ToIntE(c.value(key))
+	if err != nil {
+		c.add(key, "must be an integer, got %q", c.viper.GetString(key))
+		return 0, false
+	}
+
+	return n, true
+}
+
+// name adds a problem in case the given flag is not the name of a Kubernetes
+// object.
+func (c *checker) name(key string) {
+	s := c.viper.GetString(key)
+	if s == "" {
+		c.add(key, "must not be empty")
+		return
+	}
+	for _, m := range validation.IsDNS1123Subdomain(s) {
+		c.add(key, "must be the name of a Kubernetes object, got %q: %s", s, m)
+	}
+}
+
+func (c *checker) namespace(key string) {
+	s := c.viper.GetString(key)
+	if s == "" {
+		c.add(key, "must not be empty")
+		return
+	}
+	c.namespaceValue(key, s)
+}
+
+func (c *checker) namespaceValue(key, s string) {
+	for _, m := range validation.IsDNS1123Label(s) {
+		c.add(key, "must be a namespace, got %q: %s", s, m)
+	}
+}
+
+func (c *checker) notEmpty(key string) {
+	if strings.TrimSpace(c.viper.GetString(key)) == "" {
+		c.add(key, "must not be empty")
+	}
+}
+
+func (c *checker) notNegative(key string) {
+	if d, ok := c.duration(key); ok && d < 0 {
+		c.add(key, "must not be negative, got %s", d)
+	}
+}
+
+func (c *checker) oneOf(key string, values []string) {
+	s := c.viper.GetString(key)
+	for _, v := range values {
+		if s == v {
+			return
+		}
+	}
+
+	c.add(key, "must be one of %s, got %q", strings.Join(values, ", "), s)
+}
+
+// optionalNamespace is like namespace, but accepts an empty flag.
+func (c *checker) optionalNamespace(key string) {
+	s := c.viper.GetString(key)
+	if s == "" {
+		return
+	}
+	c.namespaceValue(key, s)
+}
+
+func (c *checker) order(key string) {
+	_, err := ordering.Parse(c.viper.GetString(key))
+	c.check(key, err)
+}
+
+func (c *checker) positive(key string) {
+	if d, ok := c.duration(key); ok && d <= 0 {
+		c.add(key, "must be greater than 0, got %s", d)
+	}
+}
+
+// selector returns the label selector of the given flag. A problem is added
+// in case it cannot be parsed.
+func (c *checker) selector(key string) string {
+	s := c.viper.GetString(key)
+	if s == "" {
+		return ""
+	}
+
+	_, err := labels.Parse(s)
+	if err != nil {
+		c.add(key, "must be a label selector, e.g. app=nginx-ingress-controller, %s", err.Error())
+	}
+
+	return s
+}
+
+// value returns the raw value of the given flag. Unset flags are the zero
+// value, the same as when reading them from viper.
+func (c *checker) value(key string) interface{} {
+	v := c.viper.Get(key)
+	if v == nil {
+		return 0
+	}
+
+	return v
+}
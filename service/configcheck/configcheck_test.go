@@ -0,0 +1,173 @@
+package configcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/giantswarm/ingress-operator/flag"
+)
+
+// newTestViper returns a viper configured with the valid defaults of the
+// daemon command.
+func newTestViper(f *flag.Flag) *viper.Viper {
+	v := viper.New()
+
+	v.Set(f.Log.Format, "json")
+	v.Set(f.Service.Controller.Concurrency, 1)
+	v.Set(f.Service.Controller.DeprecationInterval, time.Hour)
+	v.Set(f.Service.Controller.Quarantine.RetryInterval, 30*time.Minute)
+	v.Set(f.Service.Controller.ResourceOrder, "service-first")
+	v.Set(f.Service.Controller.ResyncRate, 5)
+	v.Set(f.Service.Controller.StartupReportTimeout, 10*time.Minute)
+	v.Set(f.Service.HostCluster.Ledger.Name, "ingress-operator-allocations")
+	v.Set(f.Service.HostCluster.Ledger.Namespace, "kube-system")
+	v.Set(f.Service.HostCluster.StreamSnippets, "ingress-controller-stream-snippets")
+	v.Set(f.Service.Kubernetes.Address, "http://127.0.0.1:6443")
+	v.Set(f.Service.Kubernetes.Healthz.Timeout, 5*time.Second)
+	v.Set(f.Service.Kubernetes.Timeout.Dial, 10*time.Second)
+	v.Set(f.Service.Kubernetes.Timeout.Request, 30*time.Second)
+	v.Set(f.Service.Kubernetes.Timeout.TLSHandshake, 10*time.Second)
+
+	return v
+}
+
+func Test_Check(t *testing.T) {
+	f := flag.New()
+
+	dir, err := ioutil.TempDir("", "configcheck")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	err = ioutil.WriteFile(caFile, []byte("ca"), 0600)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	testCases := []struct {
+		settings      map[string]interface{}
+		expectedFlags []string
+	}{
+		// Test 0 ensures the defaults are valid.
+		{
+			settings:      nil,
+			expectedFlags: nil,
+		},
+		// Test 1 ensures all invalid flags are reported at once, sorted by
+		// flag.
+		{
+			settings: map[string]interface{}{
+				f.Log.Format:                           "text",
+				f.Service.Controller.Concurrency:       0,
+				f.Service.Controller.Namespaces:        []string{"tenant-a", "Tenant_B"},
+				f.Service.Controller.ResourceOrder:     "random",
+				f.Service.HostCluster.AvailablePorts:   []string{"31005"},
+				f.Service.HostCluster.PortRanges:       []string{"31000-31010"},
+				f.Service.Kubernetes.Breaker.Threshold: 2,
+				f.Service.Kubernetes.TLS.CAFile:        filepath.Join(dir, "missing.pem"),
+			},
+			expectedFlags: []string{
+				f.Log.Format,
+				f.Service.Controller.Concurrency,
+				f.Service.Controller.Namespaces,
+				f.Service.Controller.ResourceOrder,
+				f.Service.HostCluster.PortRanges,
+				f.Service.Kubernetes.Breaker.Threshold,
+				f.Service.Kubernetes.TLS.CAFile,
+			},
+		},
+		// Test 2 ensures readable TLS files are valid, while a certificate
+		// without key is not.
+		{
+			settings: map[string]interface{}{
+				f.Service.Kubernetes.TLS.CAFile:  caFile,
+				f.Service.Kubernetes.TLS.CrtFile: caFile,
+			},
+			expectedFlags: []string{
+				f.Service.Kubernetes.TLS.KeyFile,
+			},
+		},
+		// Test 3 ensures the address and TLS files are not checked in case the
+		// in-cluster config is used.
+		{
+			settings: map[string]interface{}{
+				f.Service.Kubernetes.Address:    "",
+				f.Service.Kubernetes.InCluster:  true,
+				f.Service.Kubernetes.TLS.CAFile: filepath.Join(dir, "missing.pem"),
+			},
+			expectedFlags: nil,
+		},
+		// Test 4 ensures the flags of disabled features are not checked, while
+		// the ones of enabled features are.
+		{
+			settings: map[string]interface{}{
+				f.Service.Monitoring.Enabled:              true,
+				f.Service.Monitoring.Labels:               []string{"release"},
+				f.Service.HostCluster.Dedicated.Namespace: "",
+			},
+			expectedFlags: []string{
+				f.Service.Monitoring.Interval,
+				f.Service.Monitoring.Labels,
+				f.Service.Monitoring.Namespace,
+			},
+		},
+		// Test 5 ensures values given as strings, e.g. via environment
+		// variables, have to be parseable.
+		{
+			settings: map[string]interface{}{
+				f.Service.Controller.DeletionSLA: "2 hours",
+				f.Service.Controller.ListLimit:   "all",
+			},
+			expectedFlags: []string{
+				f.Service.Controller.DeletionSLA,
+				f.Service.Controller.ListLimit,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		v := newTestViper(f)
+		for k, s := range tc.settings {
+			v.Set(k, s)
+		}
+
+		problems := Check(f, v)
+
+		var flags []string
+		for _, p := range problems {
+			flags = append(flags, p.Flag)
+			if p.Message == "" || strings.HasSuffix(p.Message, "error") {
+				t.Fatal("test", i, "expected", "actionable message", "got", p.Message)
+			}
+		}
+		if !reflect.DeepEqual(flags, tc.expectedFlags) {
+			t.Fatal("test", i, "expected", tc.expectedFlags, "got", problems)
+		}
+	}
+}
+
+func Test_Render(t *testing.T) {
+	problems := []Problem{
+		{Flag: "log.format", Message: `must be one of json, logfmt, got "text"`},
+		{Flag: "service.controller.concurrency", Message: "must be greater than 0, got 0"},
+	}
+
+	expected := `2 invalid flags, fix them and start the operator again:
+  --log.format: must be one of json, logfmt, got "text"
+  --service.controller.concurrency: must be greater than 0, got 0
+`
+
+	rendered := Render(problems)
+	if rendered != expected {
+		t.Fatal("expected", expected, "got", rendered)
+	}
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/informer"
+
+	"github.com/giantswarm/ingress-operator/service/controller/bridge"
+	"github.com/giantswarm/ingress-operator/service/controller/queue"
+	"github.com/giantswarm/ingress-operator/service/crdschema"
+)
+
+type BridgeConfig struct {
+	G8sClient versioned.Interface
+	Logger    micrologger.Logger
+	// SchemaGuard refuses updates of IngressConfigs setting fields unknown to
+	// this version of the operator. It is optional.
+	SchemaGuard *crdschema.Guard
+
+	GuestClusterService        string
+	IngressControllerConfigMap string
+	IngressControllerNamespace string
+	IngressControllerService   string
+	Namespace                  string
+	ProjectName                string
+}
+
+// Bridge is the controller creating IngressConfigs from the KVMConfigs of
+// guest clusters. The KVMConfig CRD is owned by cluster-operator, so the
+// bridge does not ensure it.
+type Bridge struct {
+	*workerGroup
+}
+
+func NewBridge(config BridgeConfig) (*Bridge, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+
+	var err error
+
+	var queueInformer *queue.Informer
+	{
+		c := queue.Config{
+			Logger:  config.Logger,
+			Watcher: config.G8sClient.ProviderV1alpha1().KVMConfigs(""),
+
+			ResyncPeriod: informer.DefaultResyncPeriod,
+		}
+
+		queueInformer, err = queue.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var bridgeResourceSet *controller.ResourceSet
+	{
+		c := bridge.ResourceSetConfig{
+			G8sClient:   config.G8sClient,
+			Logger:      config.Logger,
+			SchemaGuard: config.SchemaGuard,
+
+			GuestClusterService:        config.GuestClusterService,
+			IngressControllerConfigMap: config.IngressControllerConfigMap,
+			IngressControllerNamespace: config.IngressControllerNamespace,
+			IngressControllerService:   config.IngressControllerService,
+			Namespace:                  config.Namespace,
+			ProjectName:                config.ProjectName,
+		}
+
+		bridgeResourceSet, err = bridge.NewResourceSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var operatorkitController *controller.Controller
+	{
+		c := controller.Config{
+			Informer: idleInformer{resyncPeriod: queueInformer.ResyncPeriod()},
+			Logger:   config.Logger,
+			ResourceSets: []*controller.ResourceSet{
+				bridgeResourceSet,
+			},
+			RESTClient: config.G8sClient.ProviderV1alpha1().RESTClient(),
+
+			// The name is used for the finalizers on KVMConfigs and must
+			// therefore differ from the one of the ingress controller.
+			Name: config.ProjectName + "-bridge",
+		}
+
+		operatorkitController, err = controller.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	b := &Bridge{
+		workerGroup: &workerGroup{
+			logger: config.Logger,
+			workers: []worker{
+				{controller: operatorkitController, queue: queueInformer},
+			},
+		},
+	}
+
+	return b, nil
+}
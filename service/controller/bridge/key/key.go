@@ -0,0 +1,89 @@
+package key
+
+import (
+	corev1alpha1 "github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// ManagedByLabel is the label marking IngressConfigs created from
+	// KVMConfigs. IngressConfigs lacking the label are created by someone else,
+	// e.g. cluster-operator, and are never touched by the bridge.
+	ManagedByLabel = "giantswarm.io/managed-by"
+	// ManagedByValue is the value of ManagedByLabel.
+	ManagedByValue = "ingress-operator-bridge"
+)
+
+const (
+	// DefaultHTTPIngressPort is the ingress port of the HTTP port mapping in
+	// case the KVMConfig does not define a target port.
+	DefaultHTTPIngressPort = 30010
+	// DefaultHTTPSIngressPort is the ingress port of the HTTPS port mapping in
+	// case the KVMConfig does not define a target port.
+	DefaultHTTPSIngressPort = 30011
+)
+
+const (
+	ProtocolHTTP  = "http"
+	ProtocolHTTPS = "https"
+)
+
+func ClusterID(customObject v1alpha1.KVMConfig) string {
+	return customObject.Spec.Cluster.ID
+}
+
+// IngressConfigName returns the name of the IngressConfig created for the
+// guest cluster of the given KVMConfig.
+func IngressConfigName(customObject v1alpha1.KVMConfig) string {
+	return ClusterID(customObject)
+}
+
+// IsManaged returns whether the given IngressConfig has been created from a
+// KVMConfig.
+func IsManaged(ingressConfig corev1alpha1.IngressConfig) bool {
+	return ingressConfig.GetLabels()[ManagedByLabel] == ManagedByValue
+}
+
+// ProtocolPorts returns the protocol ports of the IngressConfig created for
+// the guest cluster of the given KVMConfig. Only the HTTP and HTTPS port
+// mappings of the KVMConfig are considered. The node port of a port mapping
+// is the LB port and its target port is the ingress port.
+func ProtocolPorts(customObject v1alpha1.KVMConfig) []corev1alpha1.IngressConfigSpecProtocolPort {
+	var protocolPorts []corev1alpha1.IngressConfigSpecProtocolPort
+
+	for _, m := range customObject.Spec.KVM.PortMappings {
+		var defaultIngressPort int
+		switch m.Name {
+		case ProtocolHTTP:
+			defaultIngressPort = DefaultHTTPIngressPort
+		case ProtocolHTTPS:
+			defaultIngressPort = DefaultHTTPSIngressPort
+		default:
+			continue
+		}
+
+		p := corev1alpha1.IngressConfigSpecProtocolPort{
+			IngressPort: m.TargetPort,
+			LBPort:      m.NodePort,
+			Protocol:    m.Name,
+		}
+		if p.IngressPort == 0 {
+			p.IngressPort = defaultIngressPort
+		}
+
+		protocolPorts = append(protocolPorts, p)
+	}
+
+	return protocolPorts
+}
+
+func ToCustomObject(v interface{}) (v1alpha1.KVMConfig, error) {
+	customObjectPointer, ok := v.(*v1alpha1.KVMConfig)
+	if !ok {
+		return v1alpha1.KVMConfig{}, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &v1alpha1.KVMConfig{}, v)
+	}
+	customObject := *customObjectPointer
+
+	return customObject, nil
+}
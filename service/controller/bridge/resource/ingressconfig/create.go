@@ -0,0 +1,63 @@
+package ingressconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/bridge/key"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+)
+
+// EnsureCreated makes sure the IngressConfig of the guest cluster exists and
+// its spec matches the KVMConfig. IngressConfigs not created by the bridge,
+// e.g. the ones created by cluster-operator, are left untouched.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	desired := r.newIngressConfig(customObject)
+
+	logging.Debug(ctx, r.logger, "ensuring ingress config exists")
+
+	current, err := r.currentIngressConfig(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if current == nil {
+		_, err = r.g8sClient.CreateIngressConfig(ctx, desired.Namespace, desired)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		logging.Debug(ctx, r.logger, fmt.Sprintf("created ingress config %s/%s", desired.Namespace, desired.Name))
+
+		return nil
+	}
+
+	if !key.IsManaged(*current) {
+		logging.Debug(ctx, r.logger, fmt.Sprintf("ingress config is not managed by the bridge due to missing label %s", key.ManagedByLabel))
+		return nil
+	}
+
+	if reflect.DeepEqual(current.Spec, desired.Spec) {
+		logging.Debug(ctx, r.logger, "ingress config is up to date")
+		return nil
+	}
+
+	current.Spec = desired.Spec
+
+	_, err = r.g8sClient.UpdateIngressConfig(ctx, current.Namespace, current)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "updated ingress config")
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package ingressconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	providerv1alpha1 "github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+	"github.com/giantswarm/ingress-operator/service/controller/bridge/key"
+)
+
+func newTestKVMConfig(clusterID string, httpNodePort int) *providerv1alpha1.KVMConfig {
+	return &providerv1alpha1.KVMConfig{
+		Spec: providerv1alpha1.KVMConfigSpec{
+			Cluster: providerv1alpha1.Cluster{
+				ID: clusterID,
+			},
+			KVM: providerv1alpha1.KVMConfigSpecKVM{
+				PortMappings: []providerv1alpha1.KVMConfigSpecKVMPortMappings{
+					{
+						Name:       "http",
+						NodePort:   httpNodePort,
+						TargetPort: 30010,
+					},
+					{
+						Name:     "https",
+						NodePort: httpNodePort + 1,
+					},
+					{
+						Name:       "ssh",
+						NodePort:   httpNodePort + 2,
+						TargetPort: 22,
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestResource(t *testing.T, g8sClient *clientstest.IngressConfigs) *Resource {
+	c := DefaultConfig()
+
+	c.G8sClient = g8sClient
+	c.Logger = microloggertest.New()
+
+	c.GuestClusterService = "worker"
+	c.IngressControllerConfigMap = "ingress-controller"
+	c.IngressControllerNamespace = "kube-system"
+	c.IngressControllerService = "ingress-controller"
+	c.Namespace = "default"
+	c.VersionBundleVersion = "0.1.0"
+
+	newResource, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return newResource
+}
+
+func Test_IngressConfig_EnsureCreated(t *testing.T) {
+	g8sClient := clientstest.NewIngressConfigs()
+	newResource := newTestResource(t, g8sClient)
+
+	// Ensuring the ingress config twice verifies the resource is idempotent.
+	for i := 0; i < 2; i++ {
+		err := newResource.EnsureCreated(context.TODO(), newTestKVMConfig("al9qy", 31000))
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+	}
+	if g8sClient.Updates() != 0 {
+		t.Fatal("expected", 0, "got", g8sClient.Updates())
+	}
+
+	ingressConfig, err := g8sClient.GetIngressConfig(context.TODO(), "default", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !key.IsManaged(*ingressConfig) {
+		t.Fatal("expected", true, "got", false)
+	}
+	if ingressConfig.Spec.GuestCluster.Namespace != "al9qy" {
+		t.Fatal("expected", "al9qy", "got", ingressConfig.Spec.GuestCluster.Namespace)
+	}
+	if ingressConfig.Spec.VersionBundle.Version != "0.1.0" {
+		t.Fatal("expected", "0.1.0", "got", ingressConfig.Spec.VersionBundle.Version)
+	}
+
+	expectedPorts := []v1alpha1.IngressConfigSpecProtocolPort{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+	}
+	if len(ingressConfig.Spec.ProtocolPorts) != len(expectedPorts) {
+		t.Fatal("expected", expectedPorts, "got", ingressConfig.Spec.ProtocolPorts)
+	}
+	for i, p := range expectedPorts {
+		if ingressConfig.Spec.ProtocolPorts[i] != p {
+			t.Fatal("test", i, "expected", p, "got", ingressConfig.Spec.ProtocolPorts[i])
+		}
+	}
+
+	// Changing the port mappings of the KVMConfig must update the ingress
+	// config.
+	err = newResource.EnsureCreated(context.TODO(), newTestKVMConfig("al9qy", 31010))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if g8sClient.Updates() != 1 {
+		t.Fatal("expected", 1, "got", g8sClient.Updates())
+	}
+
+	err = newResource.EnsureDeleted(context.TODO(), newTestKVMConfig("al9qy", 31010))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	_, err = g8sClient.GetIngressConfig(context.TODO(), "default", "al9qy")
+	if !clients.IsNotFound(err) {
+		t.Fatal("expected", "not found error", "got", err)
+	}
+}
+
+func Test_IngressConfig_Unmanaged(t *testing.T) {
+	g8sClient := clientstest.NewIngressConfigs()
+	newResource := newTestResource(t, g8sClient)
+
+	unmanaged := &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "al9qy",
+		},
+	}
+	_, err := g8sClient.CreateIngressConfig(context.TODO(), "default", unmanaged)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = newResource.EnsureCreated(context.TODO(), newTestKVMConfig("al9qy", 31000))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if g8sClient.Updates() != 0 {
+		t.Fatal("expected", 0, "got", g8sClient.Updates())
+	}
+
+	err = newResource.EnsureDeleted(context.TODO(), newTestKVMConfig("al9qy", 31000))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	_, err = g8sClient.GetIngressConfig(context.TODO(), "default", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+}
+
+func Test_IngressConfig_GuestClusterNamespace(t *testing.T) {
+	g8sClient := clientstest.NewIngressConfigs()
+	newResource := newTestResource(t, g8sClient)
+
+	err := newResource.EnsureCreated(context.TODO(), newTestKVMConfig("al9qy", 31000))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Creating IngressConfigs in the namespace of their guest cluster must
+	// keep updating the IngressConfig created in the previous namespace
+	// instead of creating another one.
+	newResource.namespace = ""
+
+	err = newResource.EnsureCreated(context.TODO(), newTestKVMConfig("al9qy", 31010))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if g8sClient.Len() != 1 {
+		t.Fatal("expected", 1, "got", g8sClient.Len())
+	}
+	if g8sClient.Updates() != 1 {
+		t.Fatal("expected", 1, "got", g8sClient.Updates())
+	}
+
+	err = newResource.EnsureDeleted(context.TODO(), newTestKVMConfig("al9qy", 31010))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if g8sClient.Len() != 0 {
+		t.Fatal("expected", 0, "got", g8sClient.Len())
+	}
+
+	err = newResource.EnsureCreated(context.TODO(), newTestKVMConfig("al9qy", 31000))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	_, err = g8sClient.GetIngressConfig(context.TODO(), "al9qy", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+}
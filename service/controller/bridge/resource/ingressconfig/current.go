@@ -0,0 +1,52 @@
+package ingressconfig
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	providerv1alpha1 "github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/bridge/key"
+)
+
+// currentIngressConfig returns the IngressConfig of the guest cluster of the
+// given KVMConfig, or nil in case it does not exist. It is looked up in the
+// namespace IngressConfigs are created in first. IngressConfigs created by the
+// bridge are looked up in all namespaces afterwards, so that they are still
+// found after the namespace setting changed.
+func (r *Resource) currentIngressConfig(ctx context.Context, customObject providerv1alpha1.KVMConfig) (*v1alpha1.IngressConfig, error) {
+	current, err := r.g8sClient.GetIngressConfig(ctx, r.ingressConfigNamespace(customObject), key.IngressConfigName(customObject))
+	if clients.IsNotFound(err) {
+		// fall through
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	} else {
+		return current, nil
+	}
+
+	list, err := r.g8sClient.ListIngressConfigs(ctx, "")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, i := range list.Items {
+		if i.Name == key.IngressConfigName(customObject) && i.Spec.GuestCluster.ID == key.ClusterID(customObject) && key.IsManaged(i) {
+			return i.DeepCopy(), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ingressConfigNamespace returns the namespace the IngressConfig of the guest
+// cluster of the given KVMConfig is created in. It is the namespace of the
+// guest cluster in case no namespace is configured.
+func (r *Resource) ingressConfigNamespace(customObject providerv1alpha1.KVMConfig) string {
+	if r.namespace == "" {
+		return key.ClusterID(customObject)
+	}
+
+	return r.namespace
+}
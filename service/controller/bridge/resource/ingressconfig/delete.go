@@ -0,0 +1,50 @@
+package ingressconfig
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/bridge/key"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+)
+
+// EnsureDeleted removes the IngressConfig of the guest cluster in case it has
+// been created by the bridge. The ingress controller then takes care of the
+// host cluster objects of the guest cluster, including the delayed deletion
+// while the guest cluster is still draining.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "deleting ingress config")
+
+	current, err := r.currentIngressConfig(ctx, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if current == nil {
+		logging.Debug(ctx, r.logger, "ingress config does not exist")
+		return nil
+	}
+
+	if !key.IsManaged(*current) {
+		logging.Debug(ctx, r.logger, "ingress config is not managed by the bridge")
+		return nil
+	}
+
+	err = r.g8sClient.DeleteIngressConfig(ctx, current.Namespace, current.Name)
+	if clients.IsNotFound(err) {
+		// fall through
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "deleted ingress config")
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package ingressconfig
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	providerv1alpha1 "github.com/giantswarm/apiextensions/pkg/apis/provider/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/bridge/key"
+)
+
+// newIngressConfig returns the IngressConfig of the guest cluster of the given
+// KVMConfig.
+func (r *Resource) newIngressConfig(customObject providerv1alpha1.KVMConfig) *v1alpha1.IngressConfig {
+	ingressConfig := &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.IngressConfigName(customObject),
+			Namespace: r.ingressConfigNamespace(customObject),
+			Labels: map[string]string{
+				key.ManagedByLabel: key.ManagedByValue,
+			},
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        key.ClusterID(customObject),
+				Namespace: key.ClusterID(customObject),
+				Service:   r.guestClusterService,
+			},
+			HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+				IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+					ConfigMap: r.ingressControllerConfigMap,
+					Namespace: r.ingressControllerNamespace,
+					Service:   r.ingressControllerService,
+				},
+			},
+			ProtocolPorts: key.ProtocolPorts(customObject),
+			VersionBundle: v1alpha1.IngressConfigSpecVersionBundle{
+				Version: r.versionBundleVersion,
+			},
+		},
+	}
+
+	return ingressConfig
+}
@@ -0,0 +1,122 @@
+// Package ingressconfig implements a resource creating the IngressConfig of a
+// guest cluster from its KVMConfig. This removes the dependency on
+// cluster-operator creating IngressConfigs.
+package ingressconfig
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "ingressconfigbridge"
+)
+
+// Config represents the configuration used to create a new ingress config
+// resource.
+type Config struct {
+	// Dependencies.
+	G8sClient clients.IngressConfigs
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// GuestClusterService is the guest cluster service ingress traffic is
+	// forwarded to.
+	GuestClusterService string
+	// IngressControllerConfigMap, IngressControllerNamespace and
+	// IngressControllerService reference the host cluster ingress controller.
+	IngressControllerConfigMap string
+	IngressControllerNamespace string
+	IngressControllerService   string
+	// Namespace is the namespace IngressConfigs are created in. They are
+	// created in the namespace of their guest cluster in case it is empty.
+	Namespace string
+	// VersionBundleVersion is the version bundle version IngressConfigs are
+	// created with.
+	VersionBundleVersion string
+}
+
+// DefaultConfig provides a default configuration to create a new ingress
+// config resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		G8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		GuestClusterService:        "",
+		IngressControllerConfigMap: "",
+		IngressControllerNamespace: "",
+		IngressControllerService:   "",
+		Namespace:                  "",
+		VersionBundleVersion:       "",
+	}
+}
+
+// Resource implements the ingress config resource.
+type Resource struct {
+	// Dependencies.
+	g8sClient clients.IngressConfigs
+	logger    micrologger.Logger
+
+	// Settings.
+	guestClusterService        string
+	ingressControllerConfigMap string
+	ingressControllerNamespace string
+	ingressControllerService   string
+	namespace                  string
+	versionBundleVersion       string
+}
+
+// New creates a new configured ingress config resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.G8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	// Settings.
+	if config.GuestClusterService == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.GuestClusterService must not be empty")
+	}
+	if config.IngressControllerConfigMap == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.IngressControllerConfigMap must not be empty")
+	}
+	if config.IngressControllerNamespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.IngressControllerNamespace must not be empty")
+	}
+	if config.IngressControllerService == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.IngressControllerService must not be empty")
+	}
+	if config.VersionBundleVersion == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.VersionBundleVersion must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		g8sClient: config.G8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		guestClusterService:        config.GuestClusterService,
+		ingressControllerConfigMap: config.IngressControllerConfigMap,
+		ingressControllerNamespace: config.IngressControllerNamespace,
+		ingressControllerService:   config.IngressControllerService,
+		namespace:                  config.Namespace,
+		versionBundleVersion:       config.VersionBundleVersion,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
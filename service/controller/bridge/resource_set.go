@@ -0,0 +1,150 @@
+// Package bridge implements the resource set of the bridge controller, which
+// creates IngressConfigs from the KVMConfigs of guest clusters.
+package bridge
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/resource/metricsresource"
+	"github.com/giantswarm/operatorkit/controller/resource/retryresource"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/bridge/key"
+	"github.com/giantswarm/ingress-operator/service/controller/bridge/resource/ingressconfig"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2"
+	"github.com/giantswarm/ingress-operator/service/crdschema"
+)
+
+type ResourceSetConfig struct {
+	G8sClient versioned.Interface
+	Logger    micrologger.Logger
+	// SchemaGuard refuses updates of IngressConfigs setting fields unknown to
+	// this version of the operator. It is optional.
+	SchemaGuard *crdschema.Guard
+
+	GuestClusterService        string
+	IngressControllerConfigMap string
+	IngressControllerNamespace string
+	IngressControllerService   string
+	Namespace                  string
+	ProjectName                string
+}
+
+func NewResourceSet(config ResourceSetConfig) (*controller.ResourceSet, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.ProjectName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ProjectName must not be empty", config)
+	}
+
+	var err error
+
+	var g8sClient clients.IngressConfigs
+	{
+		c := clients.G8sConfig{
+			G8sClient: config.G8sClient,
+		}
+
+		g8sClient, err = clients.NewG8s(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		if config.SchemaGuard != nil {
+			g8sClient = config.SchemaGuard.Wrap(g8sClient)
+		}
+	}
+
+	var ingressConfigResource controller.Resource
+	{
+		c := ingressconfig.Config{
+			G8sClient: g8sClient,
+			Logger:    config.Logger,
+
+			GuestClusterService:        config.GuestClusterService,
+			IngressControllerConfigMap: config.IngressControllerConfigMap,
+			IngressControllerNamespace: config.IngressControllerNamespace,
+			IngressControllerService:   config.IngressControllerService,
+			Namespace:                  config.Namespace,
+			// IngressConfigs are created for the latest version bundle the
+			// ingress controller handles.
+			VersionBundleVersion: v2.VersionBundle().Version,
+		}
+
+		ingressConfigResource, err = ingressconfig.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	resources := []controller.Resource{
+		ingressConfigResource,
+	}
+
+	{
+		c := retryresource.WrapConfig{
+			Logger: config.Logger,
+		}
+
+		resources, err = retryresource.Wrap(resources, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	{
+		c := metricsresource.WrapConfig{
+			Name: config.ProjectName,
+		}
+
+		resources, err = metricsresource.Wrap(resources, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	handlesFunc := func(obj interface{}) bool {
+		_, err := key.ToCustomObject(obj)
+		if err != nil {
+			return false
+		}
+
+		return true
+	}
+
+	initCtxFunc := func(ctx context.Context, obj interface{}) (context.Context, error) {
+		customObject, err := key.ToCustomObject(obj)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return logging.NewContext(ctx, key.ClusterID(customObject)), nil
+	}
+
+	var resourceSet *controller.ResourceSet
+	{
+		c := controller.ResourceSetConfig{
+			Handles:   handlesFunc,
+			InitCtx:   initCtxFunc,
+			Logger:    config.Logger,
+			Resources: resources,
+		}
+
+		resourceSet, err = controller.NewResourceSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return resourceSet, nil
+}
@@ -1,6 +1,10 @@
 package controller
 
 import (
+	"context"
+	"strconv"
+	"time"
+
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
 	"github.com/giantswarm/microerror"
@@ -11,7 +15,18 @@ import (
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/controller/queue"
 	"github.com/giantswarm/ingress-operator/service/controller/v2"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/lock"
+	"github.com/giantswarm/ingress-operator/service/crdschema"
+	"github.com/giantswarm/ingress-operator/service/history"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+	"github.com/giantswarm/ingress-operator/service/lister"
+	"github.com/giantswarm/ingress-operator/service/locator"
+	"github.com/giantswarm/ingress-operator/service/pool"
+	"github.com/giantswarm/ingress-operator/service/servicepolicy"
+	"github.com/giantswarm/ingress-operator/service/startup"
 )
 
 const (
@@ -19,22 +34,89 @@ const (
 )
 
 type IngressConfig struct {
-	G8sClient    versioned.Interface
-	K8sClient    kubernetes.Interface
-	K8sExtClient apiextensionsclient.Interface
-	Logger       micrologger.Logger
+	Allocator *allocator.Allocator
+	G8sClient versioned.Interface
+	// HistoryRecorder records the latest reconciliation of all IngressConfigs.
+	// It is optional.
+	HistoryRecorder *history.Recorder
+	K8sClient       kubernetes.Interface
+	K8sExtClient    apiextensionsclient.Interface
+	Ledger          *ledger.Ledger
+	// Locator discovers the config maps and services of shared ingress
+	// controllers by label selectors instead of using the names referenced by
+	// IngressConfigs. It is optional.
+	Locator *locator.Locator
+	// Locker serializes the writes to the config maps and services of shared
+	// ingress controllers across replicas. It is optional.
+	Locker lock.Locker
+	// Lister serves the IngressConfigs the informers of the workers watch
+	// from its cache. It also looks up IngressConfigs by the LB ports they
+	// request, so that allocation conflicts name the IngressConfigs involved.
+	Lister *lister.Lister
+	Logger micrologger.Logger
+	Pool   *pool.Monitor
+	// SchemaGuard refuses updates of IngressConfigs setting fields unknown to
+	// this version of the operator. It is optional.
+	SchemaGuard *crdschema.Guard
+	// StartupReporter records the first reconciliation of all IngressConfigs.
+	// It is optional.
+	StartupReporter *startup.Reporter
 
-	ProjectName string
+	// Canary enables reconciling the IngressConfigs annotated as canary with
+	// a separate resource set, whose settings differ by the canary settings
+	// below. The canary annotation is ignored in case it is disabled.
+	Canary                           bool
+	CanaryDedicatedIngressController bool
+	// CanaryResourceOrder is the resource order of the canary resource set.
+	// The stable resource order is used in case it is empty.
+	CanaryResourceOrder   string
+	CanarySelfTest        bool
+	CertificateComponents []string
+	CertificateNamespace  string
+	// Concurrency is the number of workers reconciling IngressConfigs in
+	// parallel. Each IngressConfig is always reconciled by the same worker.
+	Concurrency                              int
+	DedicatedIngressController               bool
+	DedicatedIngressControllerImage          string
+	DedicatedIngressControllerNamespace      string
+	DedicatedIngressControllerServiceAccount string
+	DisabledResources                        []string
+	IngressControllers                       []string
+	MaxServicePorts                          int
+	Namespaces                               []string
+	ProjectName                              string
+	PropagatedAnnotations                    []string
+	PropagatedLabels                         []string
+	ProxyProtocol                            bool
+	QuarantineRetryInterval                  time.Duration
+	QuarantineThreshold                      int
+	// ResourceOrder is the name of the order of the service and config map
+	// resources, see the ordering package.
+	ResourceOrder string
+	// ResultCacheTTL is the time successful reconciliations are cached. Zero
+	// disables the cache.
+	ResultCacheTTL  time.Duration
+	SelfTest        bool
+	SelfTestTimeout time.Duration
+	ServicePolicy   servicepolicy.Policy
+	StreamSnippets  string
 }
 
 type Ingress struct {
-	*controller.Controller
+	*workerGroup
 }
 
 func NewIngress(config IngressConfig) (*Ingress, error) {
 	if config.G8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
 	}
+	if config.Lister == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Lister must not be empty", config)
+	}
+
+	if config.Concurrency < 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Concurrency must be greater than 0", config)
+	}
 
 	var err error
 
@@ -51,61 +133,139 @@ func NewIngress(config IngressConfig) (*Ingress, error) {
 		}
 	}
 
-	var newInformer *informer.Informer
+	var resourceSets []*controller.ResourceSet
 	{
-		c := informer.Config{
-			Logger:  config.Logger,
-			Watcher: config.G8sClient.CoreV1alpha1().IngressConfigs(""),
+		c := v2.ResourceSetConfig{
+			Allocator:       config.Allocator,
+			G8sClient:       config.G8sClient,
+			HistoryRecorder: config.HistoryRecorder,
+			K8sClient:       config.K8sClient,
+			Ledger:          config.Ledger,
+			Lister:          config.Lister,
+			Locator:         config.Locator,
+			Locker:          config.Locker,
+			Logger:          config.Logger,
+			Pool:            config.Pool,
+			SchemaGuard:     config.SchemaGuard,
+			StartupReporter: config.StartupReporter,
+
+			CertificateComponents:                    config.CertificateComponents,
+			CertificateNamespace:                     config.CertificateNamespace,
+			DedicatedIngressController:               config.DedicatedIngressController,
+			DedicatedIngressControllerImage:          config.DedicatedIngressControllerImage,
+			DedicatedIngressControllerNamespace:      config.DedicatedIngressControllerNamespace,
+			DedicatedIngressControllerServiceAccount: config.DedicatedIngressControllerServiceAccount,
+			DisabledResources:                        config.DisabledResources,
+			IngressControllers:                       config.IngressControllers,
+			MaxServicePorts:                          config.MaxServicePorts,
+			Namespaces:                               config.Namespaces,
+			ProjectName:                              config.ProjectName,
+			PropagatedAnnotations:                    config.PropagatedAnnotations,
+			PropagatedLabels:                         config.PropagatedLabels,
+			ProxyProtocol:                            config.ProxyProtocol,
+			QuarantineRetryInterval:                  config.QuarantineRetryInterval,
+			QuarantineThreshold:                      config.QuarantineThreshold,
+			ResourceOrder:                            config.ResourceOrder,
+			ResultCacheTTL:                           config.ResultCacheTTL,
+			SelfTest:                                 config.SelfTest,
+			SelfTestTimeout:                          config.SelfTestTimeout,
+			ServicePolicy:                            config.ServicePolicy,
+			StreamSnippets:                           config.StreamSnippets,
+		}
 
-			RateWait:     informer.DefaultRateWait,
-			ResyncPeriod: informer.DefaultResyncPeriod,
+		if config.Canary {
+			c.Route = v2.RouteStable
 		}
 
-		newInformer, err = informer.New(c)
+		v2ResourceSet, err := v2.NewResourceSet(c)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
-	}
+		resourceSets = append(resourceSets, v2ResourceSet)
 
-	var v2ResourceSet *controller.ResourceSet
-	{
-		c := v2.ResourceSetConfig{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
+		// The canary resource set only differs from the stable one by the
+		// canary settings. Its logs are marked, so that the behaviour of both
+		// can be compared.
+		if config.Canary {
+			c.Logger = config.Logger.With("route", string(v2.RouteCanary))
+			c.Route = v2.RouteCanary
 
-			ProjectName: config.ProjectName,
-		}
+			c.DedicatedIngressController = config.DedicatedIngressController || config.CanaryDedicatedIngressController
+			if config.CanaryResourceOrder != "" {
+				c.ResourceOrder = config.CanaryResourceOrder
+			}
+			c.SelfTest = config.SelfTest || config.CanarySelfTest
 
-		v2ResourceSet, err = v2.NewResourceSet(c)
-		if err != nil {
-			return nil, microerror.Mask(err)
+			canaryResourceSet, err := v2.NewResourceSet(c)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			resourceSets = append(resourceSets, canaryResourceSet)
 		}
 	}
 
-	var operatorkitController *controller.Controller
-	{
-		c := controller.Config{
-			CRD:       v1alpha1.NewIngressConfigCRD(),
-			CRDClient: crdClient,
-			Informer:  newInformer,
-			Logger:    config.Logger,
-			ResourceSets: []*controller.ResourceSet{
-				v2ResourceSet,
-			},
-			RESTClient: config.G8sClient.CoreV1alpha1().RESTClient(),
-
-			Name: config.ProjectName,
+	i := &Ingress{
+		workerGroup: &workerGroup{
+			logger: config.Logger,
+		},
+	}
+
+	// The operatorkit controller reconciles one object at a time. Reconciling in
+	// parallel therefore means running one controller per worker. The queue of
+	// each worker only dispatches the events of its shard of IngressConfigs, so
+	// that the same IngressConfig is never reconciled by two workers at the same
+	// time. All workers share the controller name, which is the finalizer name
+	// of the IngressConfigs.
+	for w := 0; w < config.Concurrency; w++ {
+		var queueInformer *queue.Informer
+		{
+			c := queue.Config{
+				Logger: config.Logger.With("worker", strconv.Itoa(w)),
+				// The queues of all workers are served from the cache of the
+				// lister, so that only the lister lists and watches the API
+				// server.
+				Watcher: config.Lister,
+
+				ResyncPeriod: informer.DefaultResyncPeriod,
+				Shard:        w,
+				Shards:       config.Concurrency,
+			}
+
+			queueInformer, err = queue.New(c)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
 		}
 
-		operatorkitController, err = controller.New(c)
-		if err != nil {
-			return nil, microerror.Mask(err)
+		var operatorkitController *controller.Controller
+		{
+			c := controller.Config{
+				CRD:          v1alpha1.NewIngressConfigCRD(),
+				CRDClient:    crdClient,
+				Informer:     idleInformer{resyncPeriod: queueInformer.ResyncPeriod()},
+				Logger:       config.Logger.With("worker", strconv.Itoa(w)),
+				ResourceSets: resourceSets,
+				RESTClient:   config.G8sClient.CoreV1alpha1().RESTClient(),
+
+				Name: config.ProjectName,
+			}
+
+			operatorkitController, err = controller.New(c)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
 		}
-	}
 
-	i := &Ingress{
-		Controller: operatorkitController,
+		i.workers = append(i.workers, worker{controller: operatorkitController, queue: queueInformer})
 	}
 
 	return i, nil
 }
+
+// Requeue queues the given IngressConfig for reconciliation by the worker it
+// belongs to.
+func (i *Ingress) Requeue(ctx context.Context, customObject *v1alpha1.IngressConfig) {
+	for _, w := range i.workers {
+		w.queue.Enqueue(ctx, customObject)
+	}
+}
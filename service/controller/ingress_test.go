@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/micrologger/microloggertest"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/ordering"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+	"github.com/giantswarm/ingress-operator/service/lister"
+	"github.com/giantswarm/ingress-operator/service/pool"
+)
+
+// testClient lists no IngressConfigs and then streams the events of its fake
+// watcher.
+type testClient struct {
+	watcher *watch.FakeWatcher
+}
+
+func (c *testClient) List(opts metav1.ListOptions) (*v1alpha1.IngressConfigList, error) {
+	list := &v1alpha1.IngressConfigList{}
+	list.ResourceVersion = "1"
+
+	return list, nil
+}
+
+func (c *testClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.watcher, nil
+}
+
+// newTestAPIServer serves the IngressConfig CRD as established and no
+// IngressConfigs.
+func newTestAPIServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if !strings.HasPrefix(r.URL.Path, "/apis/apiextensions.k8s.io/") {
+			w.Write([]byte(`{"apiVersion":"core.giantswarm.io/v1alpha1","kind":"IngressConfigList","items":[]}`))
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			crd := v1alpha1.NewIngressConfigCRD()
+			crd.APIVersion = "apiextensions.k8s.io/v1beta1"
+			crd.Kind = "CustomResourceDefinition"
+			crd.Status.Conditions = []apiextensionsv1beta1.CustomResourceDefinitionCondition{
+				{
+					Type:   apiextensionsv1beta1.Established,
+					Status: apiextensionsv1beta1.ConditionTrue,
+				},
+			}
+			b, _ := json.Marshal(crd)
+			w.Write(b)
+		default:
+			b, _ := ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write(b)
+		}
+	}))
+}
+
+// waitForLen waits until the given number of objects is waiting in the queues
+// of the given ingress controller.
+func waitForLen(i *Ingress, expected int) int {
+	var l int
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		l = 0
+		for _, w := range i.workers {
+			l += w.queue.Len()
+		}
+		if l == expected {
+			break
+		}
+	}
+
+	return l
+}
+
+// Test_Ingress_BootShutdown ensures the workers of the ingress controller stop
+// dispatching IngressConfigs on shutdown and resume once booted again.
+func Test_Ingress_BootShutdown(t *testing.T) {
+	server := newTestAPIServer()
+	defer server.Close()
+
+	var err error
+
+	restConfig := &rest.Config{Host: server.URL}
+
+	g8sClient, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	k8sExtClient, err := apiextensionsclient.NewForConfig(restConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	k8sClient := fake.NewSimpleClientset()
+
+	var k8sClients clients.Interface
+	{
+		k8sClients, err = clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var portAllocator *allocator.Allocator
+	{
+		portAllocator, err = allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var allocationLedger *ledger.Ledger
+	{
+		c := ledger.Config{
+			K8sClient: k8sClients,
+			Logger:    microloggertest.New(),
+
+			Name:      "ingress-operator-allocations",
+			Namespace: "kube-system",
+		}
+
+		allocationLedger, err = ledger.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var poolMonitor *pool.Monitor
+	{
+		c := pool.Config{
+			Allocator: portAllocator,
+			K8sClient: k8sClients,
+			Ledger:    allocationLedger,
+			Logger:    microloggertest.New(),
+
+			ProjectName: "ingress-operator",
+		}
+
+		poolMonitor, err = pool.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var ingressConfigLister *lister.Lister
+	{
+		g8sClients, err := clients.NewG8s(clients.G8sConfig{G8sClient: g8sClient})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := lister.Config{
+			Clients:   map[string]lister.Client{"": &testClient{watcher: watch.NewFake()}},
+			G8sClient: g8sClients,
+			Logger:    microloggertest.New(),
+		}
+
+		ingressConfigLister, err = lister.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var ingress *Ingress
+	{
+		c := IngressConfig{
+			Allocator:    portAllocator,
+			G8sClient:    g8sClient,
+			K8sClient:    k8sClient,
+			K8sExtClient: k8sExtClient,
+			Ledger:       allocationLedger,
+			Lister:       ingressConfigLister,
+			Logger:       microloggertest.New(),
+			Pool:         poolMonitor,
+
+			Concurrency:    2,
+			ProjectName:    "ingress-operator",
+			ResourceOrder:  string(ordering.ConfigMapFirst),
+			StreamSnippets: "ingress-operator-stream-snippets",
+		}
+
+		ingress, err = NewIngress(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	ingressConfigLister.Boot()
+	defer ingressConfigLister.Shutdown()
+
+	ctx := context.Background()
+
+	// The IngressConfig is of a version bundle no resource set handles, so
+	// that dispatching it does not reconcile anything.
+	customObject := &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			VersionBundle: v1alpha1.IngressConfigSpecVersionBundle{
+				Version: "0.0.0",
+			},
+		},
+	}
+
+	ingress.Boot()
+
+	ingress.Requeue(ctx, customObject)
+	if l := waitForLen(ingress, 0); l != 0 {
+		t.Fatal("expected", 0, "got", l)
+	}
+
+	ingress.Shutdown()
+
+	// Objects requeued while shut down wait in the queues.
+	ingress.Requeue(ctx, customObject)
+	time.Sleep(100 * time.Millisecond)
+	if l := waitForLen(ingress, 1); l != 1 {
+		t.Fatal("expected", 1, "got", l)
+	}
+
+	ingress.Boot()
+	defer ingress.Shutdown()
+
+	if l := waitForLen(ingress, 0); l != 0 {
+		t.Fatal("expected", 0, "got", l)
+	}
+}
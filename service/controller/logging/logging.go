@@ -0,0 +1,85 @@
+// Package logging implements the logging of reconciliations shared by the
+// bridge and the v2 controller. Every log line carries the same set of keys,
+// so that log based dashboards can parse the logs of both controllers with
+// the same rules. A log line looks like the following.
+//
+//	{"cluster":"al9qy","level":"debug","message":"updated the config map data in the Kubernetes API", ...}
+//
+// The cluster is taken from the logger meta of the given context, see
+// NewContext. Errors are logged with their stack.
+package logging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+const (
+	// KeyCluster is the key of the guest cluster ID the reconciled object
+	// belongs to.
+	KeyCluster = "cluster"
+	// KeyLevel is the key of the log level.
+	KeyLevel = "level"
+	// KeyMessage is the key of the human readable message.
+	KeyMessage = "message"
+	// KeyStack is the key of the stack of logged errors.
+	KeyStack = "stack"
+)
+
+const (
+	LevelDebug   = "debug"
+	LevelError   = "error"
+	LevelInfo    = "info"
+	LevelWarning = "warning"
+)
+
+// NewContext returns a context whose logger meta carries the given cluster
+// ID, so that all log lines issued with it carry the cluster key, including
+// the ones of operatorkit. The controllers call it when initializing the
+// context of a reconciliation. The logger meta of the given context is copied,
+// so that it is not changed.
+func NewContext(ctx context.Context, clusterID string) context.Context {
+	meta := loggermeta.New()
+	if m, ok := loggermeta.FromContext(ctx); ok {
+		for k, v := range m.KeyVals {
+			meta.KeyVals[k] = v
+		}
+	}
+	meta.KeyVals[KeyCluster] = clusterID
+
+	return loggermeta.NewContext(ctx, meta)
+}
+
+// Debug logs the given message and key value pairs on debug level.
+func Debug(ctx context.Context, logger micrologger.Logger, message string, keyVals ...interface{}) {
+	log(ctx, logger, LevelDebug, message, keyVals...)
+}
+
+// Error logs the given message, the stack of the given error and the given
+// key value pairs on error level.
+func Error(ctx context.Context, logger micrologger.Logger, message string, err error, keyVals ...interface{}) {
+	log(ctx, logger, LevelError, message, append([]interface{}{KeyStack, fmt.Sprintf("%#v", err)}, keyVals...)...)
+}
+
+// Info logs the given message and key value pairs on info level.
+func Info(ctx context.Context, logger micrologger.Logger, message string, keyVals ...interface{}) {
+	log(ctx, logger, LevelInfo, message, keyVals...)
+}
+
+// Warning logs the given message and key value pairs on warning level.
+func Warning(ctx context.Context, logger micrologger.Logger, message string, keyVals ...interface{}) {
+	log(ctx, logger, LevelWarning, message, keyVals...)
+}
+
+// WarningErr logs the given message, the stack of the given error and the
+// given key value pairs on warning level.
+func WarningErr(ctx context.Context, logger micrologger.Logger, message string, err error, keyVals ...interface{}) {
+	log(ctx, logger, LevelWarning, message, append([]interface{}{KeyStack, fmt.Sprintf("%#v", err)}, keyVals...)...)
+}
+
+func log(ctx context.Context, logger micrologger.Logger, level, message string, keyVals ...interface{}) {
+	logger.LogCtx(ctx, append([]interface{}{KeyLevel, level, KeyMessage, message}, keyVals...)...)
+}
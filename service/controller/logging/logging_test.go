@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/micrologger/loggermeta"
+)
+
+// testLogger records the key value pairs of the logged lines.
+type testLogger struct {
+	lines []map[string]interface{}
+}
+
+func (l *testLogger) Log(keyVals ...interface{}) error {
+	return l.LogCtx(context.Background(), keyVals...)
+}
+
+func (l *testLogger) LogCtx(ctx context.Context, keyVals ...interface{}) error {
+	line := map[string]interface{}{}
+	for i := 0; i < len(keyVals); i += 2 {
+		line[keyVals[i].(string)] = keyVals[i+1]
+	}
+	if meta, ok := loggermeta.FromContext(ctx); ok {
+		for k, v := range meta.KeyVals {
+			line[k] = v
+		}
+	}
+
+	l.lines = append(l.lines, line)
+
+	return nil
+}
+
+func (l *testLogger) With(keyVals ...interface{}) micrologger.Logger {
+	return l
+}
+
+// Test_Logging ensures all log lines carry the level and the message, the
+// cluster of the context and the logger meta of operatorkit.
+func Test_Logging(t *testing.T) {
+	logger := &testLogger{}
+
+	meta := loggermeta.New()
+	meta.KeyVals["object"] = "/apis/core.giantswarm.io/v1alpha1/namespaces/default/ingressconfigs/al9qy"
+	ctx := loggermeta.NewContext(context.Background(), meta)
+
+	clusterCtx := NewContext(ctx, "al9qy")
+
+	Debug(clusterCtx, logger, "updated the config map data", "source", "test")
+	Error(clusterCtx, logger, "failed raising event", microerror.New("test"))
+	Warning(ctx, logger, "found orphaned service port")
+
+	if len(meta.KeyVals) != 1 {
+		t.Fatal("expected", 1, "got", len(meta.KeyVals))
+	}
+
+	expected := []map[string]interface{}{
+		{
+			KeyCluster: "al9qy",
+			KeyLevel:   LevelDebug,
+			KeyMessage: "updated the config map data",
+			"object":   meta.KeyVals["object"],
+			"source":   "test",
+		},
+		{
+			KeyCluster: "al9qy",
+			KeyLevel:   LevelError,
+			KeyMessage: "failed raising event",
+			"object":   meta.KeyVals["object"],
+		},
+		{
+			KeyLevel:   LevelWarning,
+			KeyMessage: "found orphaned service port",
+			"object":   meta.KeyVals["object"],
+		},
+	}
+
+	if len(logger.lines) != len(expected) {
+		t.Fatal("expected", len(expected), "got", len(logger.lines))
+	}
+	if _, ok := logger.lines[1][KeyStack]; !ok {
+		t.Fatal("expected", true, "got", false)
+	}
+	delete(logger.lines[1], KeyStack)
+
+	for i, e := range expected {
+		if !reflect.DeepEqual(logger.lines[i], e) {
+			t.Fatal("line", i, "expected", e, "got", logger.lines[i])
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package pager
+
+import (
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+// IsResourceNotRegistered asserts errors of the API server returned in case the
+// listed or watched resource is not registered, e.g. because its CRD got
+// deleted.
+func IsResourceNotRegistered(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	c := microerror.Cause(err)
+
+	return errors.IsNotFound(c) || meta.IsNoMatchError(c)
+}
@@ -0,0 +1,325 @@
+// Package pager implements a watcher for the operatorkit informer which lists
+// IngressConfigs in pages instead of all at once. The operatorkit informer
+// watches without a resource version, which makes the API server send every
+// existing object in one go on each resync. On host clusters with thousands of
+// IngressConfigs this requires the API server and the operator to hold all of
+// them in memory at the same time. The pager lists them with limit and continue
+// instead and only then watches from the resource version of the list. Fields
+// the operator never reads are stripped from every IngressConfig before it
+// reaches the informer, which caches it until the next resync. The pager also
+// lists all pages at once for the IngressConfig lister, whose cache the
+// informers of the controller workers watch.
+//
+// In case the IngressConfig CRD got deleted and recreated, e.g. during disaster
+// recovery, listing and watching fails because the resource is not registered
+// in the meantime. The pager then ensures the CRD and retries, so that the
+// informer watches again without restarting the operator.
+package pager
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// DefaultLimit is the default maximum number of IngressConfigs listed per
+	// page.
+	DefaultLimit = 500
+	// LastAppliedConfigAnnotation is the annotation kubectl apply stores the
+	// complete last applied object in. It usually is the largest part of an
+	// IngressConfig and is never read by the operator.
+	LastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// Client is the subset of the IngressConfig client the pager needs.
+type Client interface {
+	List(opts metav1.ListOptions) (*v1alpha1.IngressConfigList, error)
+	Watch(opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// Config represents the configuration used to create a new pager.
+type Config struct {
+	Client Client
+	Logger micrologger.Logger
+
+	// EnsureCRD creates the IngressConfig CRD in case it does not exist and
+	// waits for it to be established. It is called in case listing or watching
+	// fails because the IngressConfig resource is not registered, after which
+	// listing or watching is retried once. It is optional.
+	EnsureCRD func() error
+	// Limit is the maximum number of IngressConfigs listed per page. Zero
+	// lists all IngressConfigs in a single page.
+	Limit int64
+}
+
+// DefaultConfig provides a default configuration to create a new pager by
+// best effort.
+func DefaultConfig() Config {
+	return Config{
+		Client: nil,
+		Logger: nil,
+
+		EnsureCRD: nil,
+		Limit:     DefaultLimit,
+	}
+}
+
+// Pager implements informer.Watcher.
+type Pager struct {
+	client Client
+	logger micrologger.Logger
+
+	ensureCRD func() error
+	limit     int64
+}
+
+// New creates a new configured pager.
+func New(config Config) (*Pager, error) {
+	if config.Client == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Client must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Limit < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Limit must not be negative", config)
+	}
+
+	p := &Pager{
+		client: config.Client,
+		logger: config.Logger,
+
+		ensureCRD: config.EnsureCRD,
+		limit:     config.Limit,
+	}
+
+	return p, nil
+}
+
+// Watch emits an added event for every existing IngressConfig, listed page by
+// page, and then forwards the events of a watch started at the resource
+// version of the list. This is what the API server emits for a watch without
+// resource version, which is what the operatorkit informer relies on. In case
+// opts has a resource version, Watch only watches from there. The first page
+// is listed before Watch returns, so that errors of the API server are
+// returned to the informer, which retries. In case a later page fails, e.g.
+// because the continue token expired, the returned watch is closed, which
+// makes the informer watch again.
+func (p *Pager) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	if opts.ResourceVersion != "" {
+		var w watch.Interface
+		err := p.retryUnregistered(func() error {
+			var err error
+			w, err = p.client.Watch(opts)
+			return err
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return newPagedWatch(p, opts, nil, w), nil
+	}
+
+	opts.Limit = p.limit
+	opts.Continue = ""
+
+	var list *v1alpha1.IngressConfigList
+	err := p.retryUnregistered(func() error {
+		var err error
+		list, err = p.client.List(opts)
+		return err
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return newPagedWatch(p, opts, list, nil), nil
+}
+
+// List lists all IngressConfigs page by page and returns them in a single
+// list having the resource version of the last page, so that it can back the
+// list and watch of a client-go informer. The resource version of opts is
+// ignored, since the API server serves lists of resource version 0 from its
+// watch cache in a single page.
+func (p *Pager) List(opts metav1.ListOptions) (*v1alpha1.IngressConfigList, error) {
+	opts.Limit = p.limit
+	opts.Continue = ""
+	opts.ResourceVersion = ""
+
+	result := &v1alpha1.IngressConfigList{}
+	for {
+		var list *v1alpha1.IngressConfigList
+		err := p.retryUnregistered(func() error {
+			var err error
+			list, err = p.client.List(opts)
+			return err
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for i := range list.Items {
+			Strip(&list.Items[i])
+		}
+		result.Items = append(result.Items, list.Items...)
+		result.ResourceVersion = list.ResourceVersion
+
+		if list.Continue == "" {
+			break
+		}
+		opts.Continue = list.Continue
+	}
+
+	return result, nil
+}
+
+// retryUnregistered executes the given operation and retries it once after
+// ensuring the CRD in case it failed because the IngressConfig resource is not
+// registered.
+func (p *Pager) retryUnregistered(o func() error) error {
+	err := o()
+	if !IsResourceNotRegistered(err) || p.ensureCRD == nil {
+		return err
+	}
+
+	p.logger.Log("level", "warning", "message", "ingress config resource is not registered, ensuring the CRD")
+
+	err = p.ensureCRD()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	p.logger.Log("level", "info", "message", "ensured the CRD, watching ingress configs again")
+
+	return o()
+}
+
+// Strip removes the fields the operator never reads from the given
+// IngressConfig.
+func Strip(customObject *v1alpha1.IngressConfig) {
+	delete(customObject.Annotations, LastAppliedConfigAnnotation)
+}
+
+type pagedWatch struct {
+	pager *Pager
+
+	result   chan watch.Event
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// newPagedWatch starts emitting the items of list and its following pages
+// before watching. In case list is nil, the events of w are emitted right
+// away.
+func newPagedWatch(p *Pager, opts metav1.ListOptions, list *v1alpha1.IngressConfigList, w watch.Interface) *pagedWatch {
+	pw := &pagedWatch{
+		pager: p,
+
+		result: make(chan watch.Event),
+		stop:   make(chan struct{}),
+	}
+
+	go pw.run(opts, list, w)
+
+	return pw
+}
+
+func (pw *pagedWatch) ResultChan() <-chan watch.Event {
+	return pw.result
+}
+
+func (pw *pagedWatch) Stop() {
+	pw.stopOnce.Do(func() {
+		close(pw.stop)
+	})
+}
+
+func (pw *pagedWatch) run(opts metav1.ListOptions, list *v1alpha1.IngressConfigList, w watch.Interface) {
+	defer close(pw.result)
+
+	if list != nil {
+		var ok bool
+		w, ok = pw.list(opts, list)
+		if !ok {
+			return
+		}
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-pw.stop:
+			return
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+
+			customObject, ok := e.Object.(*v1alpha1.IngressConfig)
+			if ok {
+				Strip(customObject)
+			}
+
+			if !pw.send(e) {
+				return
+			}
+		}
+	}
+}
+
+// list emits the items of all pages starting at the given one and returns the
+// watch started at the resource version of the list. It returns false in case
+// the watch got stopped or listing or watching failed.
+func (pw *pagedWatch) list(opts metav1.ListOptions, list *v1alpha1.IngressConfigList) (watch.Interface, bool) {
+	var err error
+
+	for {
+		for i := range list.Items {
+			customObject := &list.Items[i]
+			Strip(customObject)
+
+			if !pw.send(watch.Event{Type: watch.Added, Object: customObject}) {
+				return nil, false
+			}
+		}
+
+		if list.Continue == "" {
+			break
+		}
+
+		opts.Continue = list.Continue
+		list, err = pw.pager.client.List(opts)
+		if err != nil {
+			pw.pager.logger.Log("level", "warning", "message", "failed listing next page of ingress configs", "stack", fmt.Sprintf("%#v", err))
+			return nil, false
+		}
+	}
+
+	opts.Continue = ""
+	opts.Limit = 0
+	opts.ResourceVersion = list.ResourceVersion
+
+	w, err := pw.pager.client.Watch(opts)
+	if err != nil {
+		pw.pager.logger.Log("level", "warning", "message", "failed watching ingress configs", "stack", fmt.Sprintf("%#v", err))
+		return nil, false
+	}
+
+	return w, true
+}
+
+func (pw *pagedWatch) send(e watch.Event) bool {
+	select {
+	case <-pw.stop:
+		return false
+	case pw.result <- e:
+		return true
+	}
+}
@@ -0,0 +1,526 @@
+package pager
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// testClient serves its items in pages like the API server. The continue
+// token is the index of the next item. Items are decoded freshly for every
+// response.
+type testClient struct {
+	items []v1alpha1.IngressConfig
+	// failContinue makes listing any page but the first one fail.
+	failContinue bool
+	// unregistered makes listing and watching fail like for a resource whose
+	// CRD does not exist.
+	unregistered bool
+
+	lists   int
+	watches []metav1.ListOptions
+	watcher *watch.FakeWatcher
+}
+
+func newTestClient(items []v1alpha1.IngressConfig) *testClient {
+	c := &testClient{
+		items:   items,
+		watcher: watch.NewFakeWithChanSize(len(items)+1, false),
+	}
+
+	return c
+}
+
+func (c *testClient) List(opts metav1.ListOptions) (*v1alpha1.IngressConfigList, error) {
+	c.lists++
+
+	if c.unregistered {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "core.giantswarm.io", Resource: "ingressconfigs"}, "")
+	}
+
+	start := 0
+	if opts.Continue != "" {
+		if c.failContinue {
+			return nil, fmt.Errorf("continue token expired")
+		}
+
+		var err error
+		start, err = strconv.Atoi(opts.Continue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	end := len(c.items)
+	if opts.Limit > 0 && start+int(opts.Limit) < end {
+		end = start + int(opts.Limit)
+	}
+
+	list := &v1alpha1.IngressConfigList{}
+	list.ResourceVersion = "42"
+	for _, item := range c.items[start:end] {
+		list.Items = append(list.Items, *decode(item))
+	}
+	if end < len(c.items) {
+		list.Continue = strconv.Itoa(end)
+	}
+
+	return list, nil
+}
+
+func (c *testClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	c.watches = append(c.watches, opts)
+
+	if c.unregistered {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "core.giantswarm.io", Resource: "ingressconfigs"}, "")
+	}
+
+	// A watch without resource version emits all existing items first, which
+	// is what the operatorkit informer relies on without the pager.
+	if opts.ResourceVersion == "" {
+		for _, item := range c.items {
+			c.watcher.Add(decode(item))
+		}
+	}
+
+	return c.watcher, nil
+}
+
+// decode returns a copy of the given IngressConfig which shares no memory
+// with it, like an IngressConfig decoded from a response of the API server.
+func decode(customObject v1alpha1.IngressConfig) *v1alpha1.IngressConfig {
+	b, err := json.Marshal(customObject)
+	if err != nil {
+		panic(err)
+	}
+
+	var decoded v1alpha1.IngressConfig
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		panic(err)
+	}
+
+	return &decoded
+}
+
+func newTestItems(n int) []v1alpha1.IngressConfig {
+	var items []v1alpha1.IngressConfig
+
+	for i := 0; i < n; i++ {
+		customObject := v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					"ingress-operator.giantswarm.io/pinned-ports": "31000",
+				},
+				Name:      fmt.Sprintf("cluster-%d", i),
+				Namespace: "default",
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+					ID: fmt.Sprintf("cluster-%d", i),
+				},
+			},
+		}
+
+		for p := 0; p < 20; p++ {
+			customObject.Spec.ProtocolPorts = append(customObject.Spec.ProtocolPorts, v1alpha1.IngressConfigSpecProtocolPort{
+				IngressPort: 30010 + p,
+				LBPort:      31000 + p,
+				Protocol:    "http",
+			})
+		}
+
+		// kubectl apply stores the whole object again, which roughly doubles
+		// its size.
+		customObject.Annotations[LastAppliedConfigAnnotation] = fmt.Sprintf("%#v", customObject)
+
+		items = append(items, customObject)
+	}
+
+	return items
+}
+
+// receive returns the next n events of w.
+func receive(t *testing.T, w watch.Interface, n int) []watch.Event {
+	var events []watch.Event
+
+	for len(events) < n {
+		select {
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		case <-time.After(time.Second):
+			t.Fatal("expected", n, "events", "got", len(events))
+		}
+	}
+
+	return events
+}
+
+func Test_Pager_Watch(t *testing.T) {
+	testCases := []struct {
+		Items         int
+		Limit         int64
+		ExpectedLists int
+	}{
+		// Test 0 ensures all IngressConfigs are listed in a single page without
+		// limit.
+		{
+			Items:         5,
+			Limit:         0,
+			ExpectedLists: 1,
+		},
+
+		// Test 1 ensures IngressConfigs are listed in pages of the limit.
+		{
+			Items:         5,
+			Limit:         2,
+			ExpectedLists: 3,
+		},
+
+		// Test 2 ensures a limit dividing the IngressConfigs lists no empty
+		// page.
+		{
+			Items:         4,
+			Limit:         2,
+			ExpectedLists: 2,
+		},
+
+		// Test 3 ensures a limit greater than the number of IngressConfigs lists
+		// a single page.
+		{
+			Items:         5,
+			Limit:         500,
+			ExpectedLists: 1,
+		},
+
+		// Test 4 ensures no IngressConfigs are listed in a single page.
+		{
+			Items:         0,
+			Limit:         2,
+			ExpectedLists: 1,
+		},
+	}
+
+	for i, tc := range testCases {
+		client := newTestClient(newTestItems(tc.Items))
+
+		var err error
+		var p *Pager
+		{
+			c := DefaultConfig()
+
+			c.Client = client
+			c.Logger = microloggertest.New()
+			c.Limit = tc.Limit
+
+			p, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		w, err := p.Watch(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		events := receive(t, w, tc.Items)
+		for j, e := range events {
+			if e.Type != watch.Added {
+				t.Fatal("test", i, "expected", watch.Added, "got", e.Type)
+			}
+
+			customObject := e.Object.(*v1alpha1.IngressConfig)
+			if customObject.Name != fmt.Sprintf("cluster-%d", j) {
+				t.Fatal("test", i, "expected", fmt.Sprintf("cluster-%d", j), "got", customObject.Name)
+			}
+			if _, ok := customObject.Annotations[LastAppliedConfigAnnotation]; ok {
+				t.Fatal("test", i, "expected", false, "got", true)
+			}
+		}
+
+		// Events of the watch following the list are forwarded stripped too.
+		modified := newTestItems(1)[0]
+		client.watcher.Modify(&modified)
+
+		events = receive(t, w, 1)
+		if events[0].Type != watch.Modified {
+			t.Fatal("test", i, "expected", watch.Modified, "got", events[0].Type)
+		}
+		if _, ok := events[0].Object.(*v1alpha1.IngressConfig).Annotations[LastAppliedConfigAnnotation]; ok {
+			t.Fatal("test", i, "expected", false, "got", true)
+		}
+
+		if client.lists != tc.ExpectedLists {
+			t.Fatal("test", i, "expected", tc.ExpectedLists, "got", client.lists)
+		}
+		if len(client.watches) != 1 {
+			t.Fatal("test", i, "expected", 1, "got", len(client.watches))
+		}
+		if client.watches[0].ResourceVersion != "42" {
+			t.Fatal("test", i, "expected", "42", "got", client.watches[0].ResourceVersion)
+		}
+		if client.watches[0].Limit != 0 || client.watches[0].Continue != "" {
+			t.Fatal("test", i, "expected", "no pagination", "got", client.watches[0])
+		}
+
+		w.Stop()
+
+		_, ok := <-w.ResultChan()
+		if ok {
+			t.Fatal("test", i, "expected", false, "got", true)
+		}
+	}
+}
+
+func Test_Pager_List(t *testing.T) {
+	testCases := []struct {
+		Items         int
+		Limit         int64
+		ExpectedLists int
+	}{
+		// Test 0 ensures all IngressConfigs are listed in a single page without
+		// limit.
+		{
+			Items:         5,
+			Limit:         0,
+			ExpectedLists: 1,
+		},
+
+		// Test 1 ensures all pages are listed into a single list.
+		{
+			Items:         5,
+			Limit:         2,
+			ExpectedLists: 3,
+		},
+	}
+
+	for i, tc := range testCases {
+		client := newTestClient(newTestItems(tc.Items))
+
+		c := DefaultConfig()
+
+		c.Client = client
+		c.Logger = microloggertest.New()
+		c.Limit = tc.Limit
+
+		p, err := New(c)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		// The resource version of informers is ignored, since the API server
+		// would not paginate.
+		list, err := p.List(metav1.ListOptions{ResourceVersion: "0"})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		if len(list.Items) != tc.Items {
+			t.Fatal("test", i, "expected", tc.Items, "got", len(list.Items))
+		}
+		for j, customObject := range list.Items {
+			if customObject.Name != fmt.Sprintf("cluster-%d", j) {
+				t.Fatal("test", i, "expected", fmt.Sprintf("cluster-%d", j), "got", customObject.Name)
+			}
+			if _, ok := customObject.Annotations[LastAppliedConfigAnnotation]; ok {
+				t.Fatal("test", i, "expected", false, "got", true)
+			}
+		}
+		if list.ResourceVersion != "42" {
+			t.Fatal("test", i, "expected", "42", "got", list.ResourceVersion)
+		}
+		if client.lists != tc.ExpectedLists {
+			t.Fatal("test", i, "expected", tc.ExpectedLists, "got", client.lists)
+		}
+	}
+}
+
+func Test_Pager_Watch_failedPage(t *testing.T) {
+	client := newTestClient(newTestItems(5))
+	client.failContinue = true
+
+	c := DefaultConfig()
+
+	c.Client = client
+	c.Logger = microloggertest.New()
+	c.Limit = 2
+
+	p, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	w, err := p.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// The first page is emitted and then the watch is closed, which makes the
+	// informer watch again.
+	events := receive(t, w, 3)
+	if len(events) != 2 {
+		t.Fatal("expected", 2, "got", len(events))
+	}
+	if len(client.watches) != 0 {
+		t.Fatal("expected", 0, "got", len(client.watches))
+	}
+}
+
+// Benchmark_Pager_Watch compares the memory the informer cache retains per
+// IngressConfig when watching all IngressConfigs directly and through the
+// pager. Run it with go test -bench . -benchmem.
+func Benchmark_Pager_Watch(b *testing.B) {
+	items := newTestItems(1000)
+
+	benchmarks := []struct {
+		Name    string
+		Watcher func(c *testClient) (watch.Interface, error)
+	}{
+		{
+			Name: "unpaged",
+			Watcher: func(c *testClient) (watch.Interface, error) {
+				return c.Watch(metav1.ListOptions{})
+			},
+		},
+		{
+			Name: "paged",
+			Watcher: func(c *testClient) (watch.Interface, error) {
+				p, err := New(Config{Client: c, Logger: microloggertest.New(), Limit: DefaultLimit})
+				if err != nil {
+					return nil, err
+				}
+
+				return p.Watch(metav1.ListOptions{})
+			},
+		},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.Name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			var retained uint64
+			for n := 0; n < b.N; n++ {
+				client := newTestClient(items)
+
+				var before runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+
+				w, err := bm.Watcher(client)
+				if err != nil {
+					b.Fatal("expected", nil, "got", err)
+				}
+
+				// The informer caches every event until the next resync.
+				cache := map[string]watch.Event{}
+				for len(cache) < len(items) {
+					e := <-w.ResultChan()
+					cache[e.Object.(*v1alpha1.IngressConfig).Name] = e
+				}
+				w.Stop()
+
+				var after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				runtime.KeepAlive(cache)
+
+				if after.HeapAlloc > before.HeapAlloc {
+					retained += after.HeapAlloc - before.HeapAlloc
+				}
+			}
+
+			b.ReportMetric(float64(retained)/float64(b.N*len(items)), "retained-B/object")
+		})
+	}
+}
+
+func Test_Pager_Watch_unregistered(t *testing.T) {
+	testCases := []struct {
+		ResourceVersion string
+		EnsureCRD       bool
+		ExpectedEnsures int
+		ExpectedError   bool
+	}{
+		// Test 0 ensures listing an unregistered resource ensures the CRD and
+		// lists again.
+		{
+			ResourceVersion: "",
+			EnsureCRD:       true,
+			ExpectedEnsures: 1,
+			ExpectedError:   false,
+		},
+
+		// Test 1 ensures watching an unregistered resource ensures the CRD and
+		// watches again.
+		{
+			ResourceVersion: "42",
+			EnsureCRD:       true,
+			ExpectedEnsures: 1,
+			ExpectedError:   false,
+		},
+
+		// Test 2 ensures the error is returned to the informer in case the CRD
+		// is not ensured.
+		{
+			ResourceVersion: "",
+			EnsureCRD:       false,
+			ExpectedEnsures: 0,
+			ExpectedError:   true,
+		},
+	}
+
+	for i, tc := range testCases {
+		client := newTestClient(newTestItems(2))
+		client.unregistered = true
+
+		ensures := 0
+
+		c := DefaultConfig()
+
+		c.Client = client
+		c.Logger = microloggertest.New()
+		if tc.EnsureCRD {
+			c.EnsureCRD = func() error {
+				ensures++
+				client.unregistered = false
+				return nil
+			}
+		}
+
+		p, err := New(c)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		w, err := p.Watch(metav1.ListOptions{ResourceVersion: tc.ResourceVersion})
+		if tc.ExpectedError {
+			if !IsResourceNotRegistered(err) {
+				t.Fatal("test", i, "expected", true, "got", false)
+			}
+		} else {
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			w.Stop()
+		}
+
+		if ensures != tc.ExpectedEnsures {
+			t.Fatal("test", i, "expected", tc.ExpectedEnsures, "got", ensures)
+		}
+	}
+}
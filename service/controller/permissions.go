@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"github.com/giantswarm/ingress-operator/pkg/rbac"
+)
+
+// Permissions returns the permissions the controllers require in addition to
+// the ones of the clients their resources use. The informers watch the
+// reconciled custom objects, operatorkit manages finalizers by getting and
+// patching them, and the IngressConfig CRD is ensured on boot.
+func Permissions() []rbac.Permission {
+	return []rbac.Permission{
+		{APIGroup: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Verb: "create"},
+		{APIGroup: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Verb: "delete"},
+		{APIGroup: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Verb: "get"},
+		{APIGroup: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Verb: "update"},
+
+		{APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "get"},
+		{APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "patch"},
+		{APIGroup: "core.giantswarm.io", Resource: "ingressconfigs", Verb: "watch"},
+
+		{APIGroup: "provider.giantswarm.io", Resource: "kvmconfigs", Verb: "get"},
+		{APIGroup: "provider.giantswarm.io", Resource: "kvmconfigs", Verb: "patch"},
+		{APIGroup: "provider.giantswarm.io", Resource: "kvmconfigs", Verb: "watch"},
+	}
+}
@@ -0,0 +1,98 @@
+package queue
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "queue"
+)
+
+var (
+	addsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "adds_total",
+			Help:      "A counter metric expressing the number of events added to the queue.",
+		},
+		[]string{"worker", "event"},
+	)
+	cachedBytesGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "cached_bytes",
+			Help:      "A gauge metric expressing the estimated memory usage of the objects known to the informer, based on their JSON encoding. Services and config maps are not cached and hence not reported.",
+		},
+		[]string{"worker", "kind"},
+	)
+	cachedObjectsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "cached_objects",
+			Help:      "A gauge metric expressing the number of objects known to the informer. Services and config maps are not cached and hence not reported.",
+		},
+		[]string{"worker", "kind"},
+	)
+	depthGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "depth",
+			Help:      "A gauge metric expressing the number of objects waiting in the queue.",
+		},
+		[]string{"worker"},
+	)
+	dispatchesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "dispatches_total",
+			Help:      "A counter metric expressing the number of events dispatched for reconciliation, labeled by their source being add, update, delete or resync.",
+		},
+		[]string{"worker", "source"},
+	)
+	latencyHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "latency_seconds",
+			Help:      "A histogram metric expressing the time objects wait in the queue before being processed.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15),
+		},
+		[]string{"worker"},
+	)
+	priorityDepthGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "priority_depth",
+			Help:      "A gauge metric expressing the number of objects waiting in the queue with high priority, i.e. added or deleted objects.",
+		},
+		[]string{"worker"},
+	)
+	retriesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "retries_total",
+			Help:      "A counter metric expressing the number of events dispatching an object version which was already dispatched before.",
+		},
+		[]string{"worker"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(addsCounter)
+	prometheus.MustRegister(cachedBytesGauge)
+	prometheus.MustRegister(cachedObjectsGauge)
+	prometheus.MustRegister(depthGauge)
+	prometheus.MustRegister(dispatchesCounter)
+	prometheus.MustRegister(latencyHistogram)
+	prometheus.MustRegister(priorityDepthGauge)
+	prometheus.MustRegister(retriesCounter)
+}
@@ -0,0 +1,658 @@
+// Package queue implements an informer which queues the events of a watcher
+// before they are dispatched to the controller. Events of
+// the same object are collapsed, so that the latest known version of an object
+// is reconciled. Events can be sharded by object key across several queues, so
+// that each object is only ever reconciled by one controller. Events of added
+// and deleted objects are dispatched before events of updated and resynced
+// objects. The queue exposes metrics about its depth, the wait time, the
+// source of dispatched events and the objects known to the informer.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/informer"
+	"github.com/giantswarm/operatorkit/informer/collector"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+)
+
+const (
+	eventDelete = "delete"
+	eventUpdate = "update"
+)
+
+const (
+	// sourceAdd is the source of events of objects not known before.
+	sourceAdd = "add"
+	// sourceDelete is the source of events of deleted objects.
+	sourceDelete = "delete"
+	// sourceResync is the source of events of objects which did not change
+	// since they were last seen, e.g. because of the resync period of the
+	// queue or because they got enqueued explicitly.
+	sourceResync = "resync"
+	// sourceUpdate is the source of events of changed objects.
+	sourceUpdate = "update"
+)
+
+// rewatchWait is the time waited before the watcher is watched again after a
+// watch ended.
+const rewatchWait = informer.DefaultRateWait
+
+// Config represents the configuration used to create a new queue informer.
+type Config struct {
+	Logger micrologger.Logger
+	// Watcher provides the events being queued. A new watch is started every
+	// time the queue starts watching and after every resync period, which
+	// dispatches all objects again.
+	Watcher informer.Watcher
+
+	// ResyncPeriod is the period after which all objects are dispatched again.
+	// It defaults to the resync period of the operatorkit informer.
+	ResyncPeriod time.Duration
+	// Shard is the index of the shard of objects the queue dispatches events
+	// of. It is also the worker label of the queue metrics.
+	Shard int
+	// Shards is the total number of shards objects are distributed across by
+	// their key. Zero or one disables sharding.
+	Shards int
+}
+
+// Informer implements informer.Interface by watching a watcher and dispatching
+// its events through a queue. The operatorkit informer is not used to watch,
+// since it cannot be watched more than once and races with its own cache
+// refills. Every watch of the queue owns its watcher instead, so that the queue
+// can be stopped and resumed any number of times.
+type Informer struct {
+	collector *collector.Set
+	logger    micrologger.Logger
+	watcher   informer.Watcher
+
+	resyncPeriod time.Duration
+	shard        int
+	shards       int
+	worker       string
+
+	// cancel stops the currently running watch, if any. ctx is the context of
+	// the consumer given to Watch. It is used to resume watching after the
+	// queue got stopped.
+	cancel    context.CancelFunc
+	ctx       context.Context
+	deleteOut chan watch.Event
+	errOut    chan error
+	updateOut chan watch.Event
+
+	items map[string]*item
+	// keys are the keys of the queued objects with normal priority in the
+	// order they are dispatched. priorityKeys are the keys of the queued
+	// objects with high priority, which are dispatched before any object with
+	// normal priority.
+	keys         []string
+	priorityKeys []string
+	mutex        sync.Mutex
+	notify       chan struct{}
+	// cached tracks the estimated size of every object of the shard known to
+	// the queue. It is used to expose the memory used by the watched objects.
+	cached map[string]cachedObject
+	// versions tracks the resource version of the last dispatched event per
+	// object. It is used to detect retries of already dispatched object
+	// versions.
+	versions map[string]string
+}
+
+type cachedObject struct {
+	kind    string
+	size    int
+	version string
+}
+
+type item struct {
+	enqueued time.Time
+	event    watch.Event
+	key      string
+	kind     string
+	priority bool
+	source   string
+}
+
+// New creates a new queue informer.
+func New(config Config) (*Informer, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Watcher == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Watcher must not be empty", config)
+	}
+
+	if config.ResyncPeriod == 0 {
+		config.ResyncPeriod = informer.DefaultResyncPeriod
+	}
+
+	if config.Shards < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Shards must not be negative", config)
+	}
+	if config.Shard < 0 || (config.Shards > 1 && config.Shard >= config.Shards) {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Shard must be in the range of %T.Shards", config, config)
+	}
+
+	var err error
+
+	// The collectors of the operatorkit informer are kept, so that its metrics
+	// are still exposed.
+	var informerCollector *collector.Set
+	{
+		c := collector.SetConfig{
+			Logger:  config.Logger,
+			Watcher: config.Watcher,
+		}
+
+		informerCollector, err = collector.NewSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	i := &Informer{
+		collector: informerCollector,
+		logger:    config.Logger,
+		watcher:   config.Watcher,
+
+		resyncPeriod: config.ResyncPeriod,
+		shard:        config.Shard,
+		shards:       config.Shards,
+		worker:       strconv.Itoa(config.Shard),
+
+		cancel:    nil,
+		ctx:       nil,
+		deleteOut: make(chan watch.Event),
+		errOut:    make(chan error),
+		updateOut: make(chan watch.Event),
+
+		cached:       map[string]cachedObject{},
+		items:        map[string]*item{},
+		keys:         nil,
+		priorityKeys: nil,
+		mutex:        sync.Mutex{},
+		notify:       make(chan struct{}, 1),
+		versions:     map[string]string{},
+	}
+
+	return i, nil
+}
+
+func (i *Informer) Boot(ctx context.Context) error {
+	err := i.collector.Boot(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// Enqueue queues an update event for the given object, as if the watcher had
+// dispatched one. This is used to have objects reconciled again
+// without waiting for the resync period of the informer. Objects not belonging
+// to the shard of the queue are ignored.
+func (i *Informer) Enqueue(ctx context.Context, obj runtime.Object) {
+	e := watch.Event{
+		Type:   watch.Modified,
+		Object: obj,
+	}
+
+	i.addFromSource(ctx, eventUpdate, sourceResync, e)
+}
+
+// FlushCache forgets the objects known to the queue and removes its cache
+// metrics. It is meant for stopped queues, which would otherwise report objects
+// deleted meanwhile forever. All objects are dispatched again once the queue
+// watches again.
+func (i *Informer) FlushCache() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	kinds := map[string]bool{}
+	for _, c := range i.cached {
+		kinds[c.kind] = true
+	}
+	for k := range kinds {
+		cachedObjectsGauge.DeleteLabelValues(i.worker, k)
+		cachedBytesGauge.DeleteLabelValues(i.worker, k)
+	}
+
+	i.cached = map[string]cachedObject{}
+}
+
+// Len returns the number of objects currently waiting in the queue.
+func (i *Informer) Len() int {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return len(i.keys) + len(i.priorityKeys)
+}
+
+func (i *Informer) ResyncPeriod() time.Duration {
+	return i.resyncPeriod
+}
+
+// Resume starts watching the watcher again after the queue got
+// stopped. Resume does nothing in case Watch was not called yet or the queue
+// is already watching.
+func (i *Informer) Resume() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.ctx == nil || i.cancel != nil {
+		return
+	}
+
+	i.start()
+}
+
+// Stop stops watching the watcher and dispatching events. Objects
+// waiting in the queue are kept and dispatched as soon as the queue is resumed.
+// The channels returned by Watch are not closed, since the consumer would
+// otherwise receive zero value events.
+func (i *Informer) Stop() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.cancel == nil {
+		return
+	}
+
+	i.cancel()
+	i.cancel = nil
+
+	i.logger.Log("level", "debug", "message", fmt.Sprintf("stopped queue with %d objects waiting", len(i.keys)+len(i.priorityKeys)))
+}
+
+// Watch returns channels for delete, update and error events, in this order,
+// like the operatorkit informer does. Events received from the watcher are
+// queued and dispatched one after another as soon as the consumer
+// is ready to receive them. The same channels are returned on every call.
+func (i *Informer) Watch(ctx context.Context) (chan watch.Event, chan watch.Event, chan error) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	if i.cancel != nil {
+		i.cancel()
+	}
+	i.ctx = ctx
+	i.start()
+
+	return i.deleteOut, i.updateOut, i.errOut
+}
+
+// start watches the watcher and dispatches queued events until the consumer's
+// context is done or the queue gets stopped. The caller must hold the mutex.
+func (i *Informer) start() {
+	ctx, cancel := context.WithCancel(i.ctx)
+	i.cancel = cancel
+
+	go func() {
+		for {
+			err := i.stream(ctx)
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case i.errOut <- err:
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(rewatchWait):
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			it, ok := i.pop()
+			if !ok {
+				select {
+				case <-ctx.Done():
+					return
+				case <-i.notify:
+					continue
+				}
+			}
+
+			latencyHistogram.WithLabelValues(i.worker).Observe(time.Since(it.enqueued).Seconds())
+			dispatchesCounter.WithLabelValues(i.worker, it.source).Inc()
+			logging.Debug(ctx, i.logger, fmt.Sprintf("dispatching %s event of object %#q", it.source, it.key), "source", it.source)
+
+			out := i.updateOut
+			if it.kind == eventDelete {
+				out = i.deleteOut
+			}
+
+			select {
+			case <-ctx.Done():
+				// The object was not dispatched, so it is put back to the front of
+				// the queue unless a newer event got queued meanwhile.
+				i.requeue(it)
+				return
+			case out <- it.event:
+			}
+		}
+	}()
+}
+
+// stream queues the events of a new watch of the watcher until the given
+// context is done, the resync period passed or the watch ended. A new watch
+// starts with events of all objects, so that streaming again after the resync
+// period dispatches all objects again.
+func (i *Informer) stream(ctx context.Context) error {
+	w, err := i.watcher.Watch(metav1.ListOptions{})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer w.Stop()
+
+	resync := time.NewTimer(i.resyncPeriod)
+	defer resync.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-resync.C:
+			return nil
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+
+			switch e.Type {
+			case watch.Added, watch.Modified:
+				// Objects being deleted are dispatched as deletes, like the
+				// operatorkit informer does, so that their finalizers get removed.
+				m, err := meta.Accessor(e.Object)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+				if m.GetDeletionTimestamp() != nil {
+					i.add(ctx, eventDelete, e)
+				} else {
+					i.add(ctx, eventUpdate, e)
+				}
+			case watch.Deleted:
+				i.add(ctx, eventDelete, e)
+			default:
+				return microerror.Maskf(invalidEventError, "%#v", e)
+			}
+		}
+	}
+}
+
+// add puts the given event into the queue. In case the object of the event is
+// already queued, the queued event is replaced by the given one while the
+// object keeps its position in the queue. Objects queued with normal priority
+// are moved to the end of the high priority objects in case the given event
+// has high priority.
+func (i *Informer) add(ctx context.Context, kind string, e watch.Event) {
+	i.addFromSource(ctx, kind, "", e)
+}
+
+// addFromSource works like add but uses the given source for the event. An
+// empty source means the source is derived from the event and the objects
+// known to the queue.
+func (i *Informer) addFromSource(ctx context.Context, kind, source string, e watch.Event) {
+	k, err := cache.MetaNamespaceKeyFunc(e.Object)
+	if err != nil {
+		logging.WarningErr(ctx, i.logger, "cannot queue event without object key", err)
+		return
+	}
+
+	if !i.inShard(k) {
+		return
+	}
+
+	addsCounter.WithLabelValues(i.worker, kind).Inc()
+
+	i.mutex.Lock()
+	{
+		if source == "" {
+			source = i.sourceOf(k, kind, e)
+		}
+
+		i.track(k, e)
+
+		priority := isPriority(source)
+
+		it, ok := i.items[k]
+		if ok {
+			it.event = e
+			it.kind = kind
+			// A resync must not hide a real change of the object which was not
+			// dispatched yet.
+			if source != sourceResync {
+				it.source = source
+			}
+			if priority && !it.priority {
+				i.keys = remove(i.keys, k)
+				i.priorityKeys = append(i.priorityKeys, k)
+				it.priority = true
+			}
+		} else {
+			i.items[k] = &item{
+				enqueued: time.Now(),
+				event:    e,
+				key:      k,
+				kind:     kind,
+				priority: priority,
+				source:   source,
+			}
+			if priority {
+				i.priorityKeys = append(i.priorityKeys, k)
+			} else {
+				i.keys = append(i.keys, k)
+			}
+		}
+
+		i.updateDepth()
+	}
+	i.mutex.Unlock()
+
+	select {
+	case i.notify <- struct{}{}:
+	default:
+	}
+}
+
+// sourceOf returns the source of the given event of the object with the given
+// key. The caller must hold the mutex.
+func (i *Informer) sourceOf(k, kind string, e watch.Event) string {
+	if kind == eventDelete || e.Type == watch.Deleted {
+		return sourceDelete
+	}
+
+	c, ok := i.cached[k]
+	if !ok {
+		return sourceAdd
+	}
+	if c.version == resourceVersionOf(e.Object) {
+		return sourceResync
+	}
+
+	return sourceUpdate
+}
+
+// track records the estimated size of the object of the given event, or
+// forgets the object in case it got deleted, and updates the cache metrics of
+// its kind. The caller must hold the mutex.
+//
+// Only the kinds the controllers are informed about are tracked, i.e.
+// IngressConfigs and KVMConfigs in case the bridge is enabled. Services and
+// ConfigMaps are out of scope, since the operator does not cache them. The
+// resources get the few shared ingress controller objects they write from the
+// API server on every reconciliation, so there is no informer cache of them
+// to size.
+func (i *Informer) track(k string, e watch.Event) {
+	kind := kindOf(e.Object)
+
+	old, ok := i.cached[k]
+	if ok {
+		cachedObjectsGauge.WithLabelValues(i.worker, old.kind).Dec()
+		cachedBytesGauge.WithLabelValues(i.worker, old.kind).Sub(float64(old.size))
+		delete(i.cached, k)
+	}
+
+	if e.Type == watch.Deleted {
+		return
+	}
+
+	// The JSON encoding is only an estimate of the memory used by the decoded
+	// object, but it grows with the object and is cheap to compute.
+	b, err := json.Marshal(e.Object)
+	if err != nil {
+		return
+	}
+
+	i.cached[k] = cachedObject{kind: kind, size: len(b), version: resourceVersionOf(e.Object)}
+	cachedObjectsGauge.WithLabelValues(i.worker, kind).Inc()
+	cachedBytesGauge.WithLabelValues(i.worker, kind).Add(float64(len(b)))
+}
+
+// requeue puts the given object back to the front of the queue, unless the
+// object got queued again meanwhile.
+func (i *Informer) requeue(it *item) {
+	i.mutex.Lock()
+	{
+		_, ok := i.items[it.key]
+		if !ok {
+			i.items[it.key] = it
+			if it.priority {
+				i.priorityKeys = append([]string{it.key}, i.priorityKeys...)
+			} else {
+				i.keys = append([]string{it.key}, i.keys...)
+			}
+		}
+
+		i.updateDepth()
+	}
+	i.mutex.Unlock()
+
+	select {
+	case i.notify <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes the first object from the queue and returns its latest event.
+// Objects with high priority are removed before objects with normal priority.
+func (i *Informer) pop() (*item, bool) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	var k string
+	if len(i.priorityKeys) > 0 {
+		k = i.priorityKeys[0]
+		i.priorityKeys = i.priorityKeys[1:]
+	} else if len(i.keys) > 0 {
+		k = i.keys[0]
+		i.keys = i.keys[1:]
+	} else {
+		return nil, false
+	}
+	it := i.items[k]
+	delete(i.items, k)
+
+	i.updateDepth()
+
+	m, err := meta.Accessor(it.event.Object)
+	if err == nil {
+		if i.versions[k] == m.GetResourceVersion() {
+			retriesCounter.WithLabelValues(i.worker).Inc()
+		}
+		if it.event.Type == watch.Deleted {
+			delete(i.versions, k)
+		} else {
+			i.versions[k] = m.GetResourceVersion()
+		}
+	}
+
+	return it, true
+}
+
+// updateDepth updates the depth metrics of the queue. The caller must hold the
+// mutex.
+func (i *Informer) updateDepth() {
+	depthGauge.WithLabelValues(i.worker).Set(float64(len(i.keys) + len(i.priorityKeys)))
+	priorityDepthGauge.WithLabelValues(i.worker).Set(float64(len(i.priorityKeys)))
+}
+
+// inShard returns whether the object with the given key belongs to the shard
+// of the queue.
+func (i *Informer) inShard(k string) bool {
+	if i.shards <= 1 {
+		return true
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(k))
+
+	return int(h.Sum32()%uint32(i.shards)) == i.shard
+}
+
+// isPriority returns whether events of the given source have high priority.
+// Added and deleted objects are provisioned and deprovisioned guest clusters,
+// whose latency matters more than the one of changes to existing guest
+// clusters.
+func isPriority(source string) bool {
+	return source == sourceAdd || source == sourceDelete
+}
+
+// remove returns the given keys without the given key.
+func remove(keys []string, k string) []string {
+	for n, key := range keys {
+		if key == k {
+			return append(keys[:n:n], keys[n+1:]...)
+		}
+	}
+
+	return keys
+}
+
+// resourceVersionOf returns the resource version of the given object, or an
+// empty string in case it has none.
+func resourceVersionOf(obj runtime.Object) string {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+
+	return m.GetResourceVersion()
+}
+
+// kindOf returns the lower case type name of the given object, e.g.
+// "ingressconfig".
+func kindOf(obj interface{}) string {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+
+	return strings.ToLower(t.Name())
+}
@@ -0,0 +1,492 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// testWatcher hands out a new fake watch on every call to Watch. The watches
+// are sent to the watches channel, so that tests can send events through them.
+type testWatcher struct {
+	watches chan *watch.FakeWatcher
+}
+
+func newTestWatcher() *testWatcher {
+	return &testWatcher{
+		watches: make(chan *watch.FakeWatcher, 10),
+	}
+}
+
+func (w *testWatcher) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	f := watch.NewFake()
+	w.watches <- f
+
+	return f, nil
+}
+
+// next returns the watch started next, or nil in case no watch got started
+// within a second.
+func (w *testWatcher) next() *watch.FakeWatcher {
+	select {
+	case f := <-w.watches:
+		return f
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+func newTestEvent(name, resourceVersion string) watch.Event {
+	return watch.Event{
+		Type: watch.Modified,
+		Object: &v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				ResourceVersion: resourceVersion,
+			},
+		},
+	}
+}
+
+func Test_Queue_Informer_collapsesEvents(t *testing.T) {
+	var err error
+
+	var newInformer *Informer
+	{
+		c := Config{
+			Logger:  microloggertest.New(),
+			Watcher: newTestWatcher(),
+		}
+
+		newInformer, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	ctx := context.Background()
+
+	newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "1"))
+	newInformer.add(ctx, eventUpdate, newTestEvent("p1l6x", "1"))
+	newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "2"))
+
+	if newInformer.Len() != 2 {
+		t.Fatal("expected", 2, "got", newInformer.Len())
+	}
+
+	expected := []struct {
+		Name            string
+		ResourceVersion string
+	}{
+		{Name: "al9qy", ResourceVersion: "2"},
+		{Name: "p1l6x", ResourceVersion: "1"},
+	}
+
+	for i, e := range expected {
+		it, ok := newInformer.pop()
+		if !ok {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		m := it.event.Object.(*v1alpha1.IngressConfig)
+		if m.Name != e.Name || m.ResourceVersion != e.ResourceVersion {
+			t.Fatalf("test %d expected %s/%s got %s/%s", i, e.Name, e.ResourceVersion, m.Name, m.ResourceVersion)
+		}
+	}
+
+	_, ok := newInformer.pop()
+	if ok {
+		t.Fatal("expected", false, "got", true)
+	}
+}
+
+func Test_Queue_Informer_Watch(t *testing.T) {
+	var err error
+
+	watcher := newTestWatcher()
+
+	var newInformer *Informer
+	{
+		c := Config{
+			Logger:  microloggertest.New(),
+			Watcher: watcher,
+		}
+
+		newInformer, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deleteChan, updateChan, _ := newInformer.Watch(ctx)
+
+	w := watcher.next()
+	if w == nil {
+		t.Fatal("expected", "watch", "got", nil)
+	}
+
+	e := newTestEvent("al9qy", "1")
+	w.Modify(e.Object)
+	select {
+	case e := <-updateChan:
+		m := e.Object.(*v1alpha1.IngressConfig)
+		if m.Name != "al9qy" {
+			t.Fatal("expected", "al9qy", "got", m.Name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected", "update event", "got", "timeout")
+	}
+
+	e = newTestEvent("p1l6x", "1")
+	w.Delete(e.Object)
+	select {
+	case e := <-deleteChan:
+		m := e.Object.(*v1alpha1.IngressConfig)
+		if m.Name != "p1l6x" {
+			t.Fatal("expected", "p1l6x", "got", m.Name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected", "delete event", "got", "timeout")
+	}
+}
+
+func Test_Queue_Informer_StopResume(t *testing.T) {
+	var err error
+
+	watcher := newTestWatcher()
+
+	var newInformer *Informer
+	{
+		c := Config{
+			Logger:  microloggertest.New(),
+			Watcher: watcher,
+		}
+
+		newInformer, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	// Resuming and stopping before watching must not have any effect.
+	newInformer.Resume()
+	newInformer.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, updateChan, _ := newInformer.Watch(ctx)
+
+	first := watcher.next()
+	if first == nil {
+		t.Fatal("expected", "watch", "got", nil)
+	}
+
+	newInformer.Stop()
+	newInformer.Stop()
+
+	// The watch of a stopped queue is stopped as well.
+	for n := 0; !first.IsStopped(); n++ {
+		if n == 100 {
+			t.Fatal("expected", true, "got", false)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	newInformer.Enqueue(ctx, newTestEvent("al9qy", "1").Object)
+	select {
+	case e := <-updateChan:
+		t.Fatal("expected", "no event", "got", e)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	newInformer.Resume()
+	newInformer.Resume()
+
+	select {
+	case e := <-updateChan:
+		m := e.Object.(*v1alpha1.IngressConfig)
+		if m.Name != "al9qy" {
+			t.Fatal("expected", "al9qy", "got", m.Name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected", "update event", "got", "timeout")
+	}
+
+	// Resuming starts exactly one new watch, whose events are dispatched.
+	second := watcher.next()
+	if second == nil {
+		t.Fatal("expected", "watch", "got", nil)
+	}
+
+	e := newTestEvent("p1l6x", "1")
+	second.Add(e.Object)
+	select {
+	case e := <-updateChan:
+		m := e.Object.(*v1alpha1.IngressConfig)
+		if m.Name != "p1l6x" {
+			t.Fatal("expected", "p1l6x", "got", m.Name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected", "update event", "got", "timeout")
+	}
+
+	select {
+	case <-watcher.watches:
+		t.Fatal("expected", "one watch", "got", "more")
+	default:
+	}
+}
+
+func Test_Queue_Informer_sharding(t *testing.T) {
+	names := []string{"al9qy", "p1l6x", "xk3m2", "b8s0d", "q2w9e", "z7n4v"}
+
+	var informers []*Informer
+	for s := 0; s < 3; s++ {
+		c := Config{
+			Logger:  microloggertest.New(),
+			Watcher: newTestWatcher(),
+
+			Shard:  s,
+			Shards: 3,
+		}
+
+		newInformer, err := New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		informers = append(informers, newInformer)
+	}
+
+	ctx := context.Background()
+
+	for _, n := range names {
+		for _, i := range informers {
+			i.add(ctx, eventUpdate, newTestEvent(n, "1"))
+		}
+	}
+
+	// Each object has to be queued by exactly one shard, so that it is never
+	// reconciled by two workers at the same time.
+	var total int
+	for _, i := range informers {
+		total += i.Len()
+	}
+	if total != len(names) {
+		t.Fatal("expected", len(names), "got", total)
+	}
+
+	// Objects have to stay in their shard.
+	for s, i := range informers {
+		for {
+			it, ok := i.pop()
+			if !ok {
+				break
+			}
+			for o, other := range informers {
+				if o != s && other.inShard(it.key) {
+					t.Fatal("expected", s, "got", o)
+				}
+			}
+		}
+	}
+}
+
+func Test_Queue_New_invalidShard(t *testing.T) {
+	c := Config{
+		Logger:  microloggertest.New(),
+		Watcher: newTestWatcher(),
+
+		Shard:  3,
+		Shards: 3,
+	}
+
+	_, err := New(c)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Queue_Informer_tracksCache(t *testing.T) {
+	var err error
+
+	var newInformer *Informer
+	{
+		c := Config{
+			Logger:  microloggertest.New(),
+			Watcher: newTestWatcher(),
+		}
+
+		newInformer, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	ctx := context.Background()
+
+	newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "1"))
+	newInformer.add(ctx, eventUpdate, newTestEvent("p1l6x", "1"))
+	newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "2"))
+
+	if len(newInformer.cached) != 2 {
+		t.Fatal("expected", 2, "got", len(newInformer.cached))
+	}
+	c := newInformer.cached["default/al9qy"]
+	if c.kind != "ingressconfig" || c.size == 0 {
+		t.Fatalf("expected %s got %#v", "cached ingressconfig", c)
+	}
+
+	e := newTestEvent("al9qy", "3")
+	e.Type = watch.Deleted
+	newInformer.add(ctx, eventDelete, e)
+
+	if len(newInformer.cached) != 1 {
+		t.Fatal("expected", 1, "got", len(newInformer.cached))
+	}
+
+	newInformer.FlushCache()
+
+	if len(newInformer.cached) != 0 {
+		t.Fatal("expected", 0, "got", len(newInformer.cached))
+	}
+}
+
+func Test_Queue_Informer_sources(t *testing.T) {
+	var err error
+
+	var newInformer *Informer
+	{
+		c := Config{
+			Logger:  microloggertest.New(),
+			Watcher: newTestWatcher(),
+		}
+
+		newInformer, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	ctx := context.Background()
+
+	deleted := newTestEvent("al9qy", "3")
+	deleted.Type = watch.Deleted
+
+	testCases := []struct {
+		Add      func()
+		Expected string
+	}{
+		// Test 0 ensures events of objects not known before are added.
+		{
+			Add:      func() { newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "1")) },
+			Expected: sourceAdd,
+		},
+		// Test 1 ensures events of unchanged objects are resyncs.
+		{
+			Add:      func() { newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "1")) },
+			Expected: sourceResync,
+		},
+		// Test 2 ensures events of changed objects are updates.
+		{
+			Add:      func() { newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "2")) },
+			Expected: sourceUpdate,
+		},
+		// Test 3 ensures a resync does not hide a queued update.
+		{
+			Add: func() {
+				newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "3"))
+				newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "3"))
+			},
+			Expected: sourceUpdate,
+		},
+		// Test 4 ensures explicitly enqueued objects are resyncs.
+		{
+			Add:      func() { newInformer.Enqueue(ctx, newTestEvent("al9qy", "3").Object) },
+			Expected: sourceResync,
+		},
+		// Test 5 ensures events of deleted objects are deletes.
+		{
+			Add:      func() { newInformer.add(ctx, eventDelete, deleted) },
+			Expected: sourceDelete,
+		},
+	}
+
+	for i, tc := range testCases {
+		tc.Add()
+
+		it, ok := newInformer.pop()
+		if !ok {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if it.source != tc.Expected {
+			t.Fatal("test", i, "expected", tc.Expected, "got", it.source)
+		}
+	}
+}
+
+func Test_Queue_Informer_priority(t *testing.T) {
+	var err error
+
+	var newInformer *Informer
+	{
+		c := Config{
+			Logger:  microloggertest.New(),
+			Watcher: newTestWatcher(),
+		}
+
+		newInformer, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	ctx := context.Background()
+
+	// Make the objects known, so that their next events are no adds.
+	for _, n := range []string{"al9qy", "p1l6x", "xk3m2"} {
+		newInformer.add(ctx, eventUpdate, newTestEvent(n, "1"))
+		newInformer.pop()
+	}
+
+	deleted := newTestEvent("xk3m2", "2")
+	deleted.Type = watch.Deleted
+
+	newInformer.add(ctx, eventUpdate, newTestEvent("al9qy", "2"))
+	newInformer.Enqueue(ctx, newTestEvent("p1l6x", "1").Object)
+	newInformer.add(ctx, eventUpdate, newTestEvent("xk3m2", "2"))
+	newInformer.add(ctx, eventUpdate, newTestEvent("b8s0d", "1"))
+	newInformer.add(ctx, eventDelete, deleted)
+
+	if newInformer.Len() != 4 {
+		t.Fatal("expected", 4, "got", newInformer.Len())
+	}
+
+	// The added object and the object promoted by its delete event are
+	// dispatched before the updated and resynced objects.
+	expected := []string{
+		"default/b8s0d",
+		"default/xk3m2",
+		"default/al9qy",
+		"default/p1l6x",
+	}
+
+	for i, e := range expected {
+		it, ok := newInformer.pop()
+		if !ok {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if it.key != e {
+			t.Fatal("test", i, "expected", e, "got", it.key)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package conditions implements the conditions describing the state of the
+// reconciliation of IngressConfigs. Resources record the conditions they are
+// responsible for in the controller context. The status resource persists
+// them on the IngressConfig. Since the IngressConfig type does not define a
+// status, the conditions are stored as JSON in the conditions annotation.
+package conditions
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Annotation is the annotation of IngressConfigs holding their conditions.
+	Annotation = "ingress-operator.giantswarm.io/conditions"
+)
+
+// Type is the type of a condition.
+type Type string
+
+const (
+	// CertificatesIssued expresses whether the certificates the https LB ports
+	// of the IngressConfig wait for got issued. It is only recorded in case
+	// waiting for certificates is enabled.
+	CertificatesIssued Type = "CertificatesIssued"
+	// ConfigMapSynced expresses whether the config map data of the guest
+	// cluster is in sync with the IngressConfig.
+	ConfigMapSynced Type = "ConfigMapSynced"
+	// Degraded expresses whether the IngressConfig cannot be reconciled
+	// successfully.
+	Degraded Type = "Degraded"
+	// IngressReachable expresses whether TCP connections to the LB ports of
+	// the IngressConfig through the host cluster ingress controller reach the
+	// guest cluster. It is only recorded in case the self-test is enabled.
+	IngressReachable Type = "IngressReachable"
+	// PortsAllocated expresses whether the LB ports of the IngressConfig are
+	// allocated to its guest cluster.
+	PortsAllocated Type = "PortsAllocated"
+	// Ready expresses whether ingress traffic reaches the guest cluster. It is
+	// computed from the other conditions.
+	Ready Type = "Ready"
+	// ServiceSynced expresses whether the service ports of the guest cluster are
+	// in sync with the IngressConfig.
+	ServiceSynced Type = "ServiceSynced"
+)
+
+// Status is the status of a condition.
+type Status string
+
+const (
+	StatusFalse   Status = "False"
+	StatusTrue    Status = "True"
+	StatusUnknown Status = "Unknown"
+)
+
+// Condition describes a single aspect of the state of an IngressConfig.
+type Condition struct {
+	Type               Type        `json:"type"`
+	Status             Status      `json:"status"`
+	Reason             string      `json:"reason,omitempty"`
+	Message            string      `json:"message,omitempty"`
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
+
+// New returns a condition of the given type and status which transitioned
+// now.
+func New(t Type, status Status, reason, message string) Condition {
+	return Condition{
+		Type:               t,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(time.Now()),
+	}
+}
+
+// Get returns the condition of the given type. The returned condition has
+// the unknown status in case there is no condition of the given type.
+func Get(conditions []Condition, t Type) Condition {
+	for _, c := range conditions {
+		if c.Type == t {
+			return c
+		}
+	}
+
+	return Condition{Type: t, Status: StatusUnknown}
+}
+
+// IsTrue returns whether the condition of the given type has the true status.
+func IsTrue(conditions []Condition, t Type) bool {
+	return Get(conditions, t).Status == StatusTrue
+}
+
+// Set returns the given conditions with the given condition set. The last
+// transition time of an existing condition is kept in case its status did not
+// change, so that setting the same condition repeatedly does not change the
+// conditions. The conditions are ordered by type.
+func Set(conditions []Condition, c Condition) []Condition {
+	var result []Condition
+	for _, existing := range conditions {
+		if existing.Type == c.Type {
+			if existing.Status == c.Status {
+				c.LastTransitionTime = existing.LastTransitionTime
+			}
+			continue
+		}
+		result = append(result, existing)
+	}
+	result = append(result, c)
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Type < result[j].Type })
+
+	return result
+}
+
+// Merge returns the current conditions with all updated conditions set.
+// Conditions not being updated are kept as they are.
+func Merge(current, updated []Condition) []Condition {
+	var result []Condition
+	for _, c := range current {
+		result = Set(result, c)
+	}
+	for _, c := range updated {
+		result = Set(result, c)
+	}
+
+	return result
+}
+
+// Equal returns whether the given conditions are the same, ignoring their
+// order.
+func Equal(a, b []Condition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, c := range a {
+		o := Get(b, c.Type)
+		if o.Status != c.Status || o.Reason != c.Reason || o.Message != c.Message || !o.LastTransitionTime.Equal(&c.LastTransitionTime) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FromCustomObject returns the conditions stored on the given custom object.
+func FromCustomObject(customObject v1alpha1.IngressConfig) ([]Condition, error) {
+	v, ok := customObject.GetAnnotations()[Annotation]
+	if !ok || v == "" {
+		return nil, nil
+	}
+
+	var conditions []Condition
+	err := json.Unmarshal([]byte(v), &conditions)
+	if err != nil {
+		return nil, microerror.Maskf(invalidAnnotationError, "%s must be a JSON list of conditions: %s", Annotation, err.Error())
+	}
+
+	return conditions, nil
+}
+
+// ToAnnotation returns the value of the conditions annotation representing
+// the given conditions.
+func ToAnnotation(conditions []Condition) (string, error) {
+	b, err := json.Marshal(conditions)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return string(b), nil
+}
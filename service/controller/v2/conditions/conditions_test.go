@@ -0,0 +1,101 @@
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_Conditions_Set(t *testing.T) {
+	past := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+
+	current := []Condition{
+		{Type: ServiceSynced, Status: StatusTrue, LastTransitionTime: past},
+		{Type: PortsAllocated, Status: StatusTrue, LastTransitionTime: past},
+	}
+
+	// Setting a condition with an unchanged status must keep its last
+	// transition time.
+	result := Set(current, New(PortsAllocated, StatusTrue, "", ""))
+	if !Equal(result, current) {
+		t.Fatalf("expected %#v got %#v", current, result)
+	}
+
+	// Setting a condition with a changed status must update its last transition
+	// time.
+	result = Set(current, New(PortsAllocated, StatusFalse, "AllocationConflict", ""))
+	c := Get(result, PortsAllocated)
+	if c.Status != StatusFalse || c.Reason != "AllocationConflict" || !c.LastTransitionTime.After(past.Time) {
+		t.Fatalf("expected %s got %#v", "updated condition", c)
+	}
+	if len(result) != 2 || result[0].Type != PortsAllocated || result[1].Type != ServiceSynced {
+		t.Fatalf("expected %s got %#v", "conditions ordered by type", result)
+	}
+
+	// Unknown conditions have the unknown status.
+	if Get(result, Ready).Status != StatusUnknown {
+		t.Fatal("expected", StatusUnknown, "got", Get(result, Ready).Status)
+	}
+}
+
+func Test_Conditions_Merge(t *testing.T) {
+	past := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+
+	current := []Condition{
+		{Type: ConfigMapSynced, Status: StatusTrue, LastTransitionTime: past},
+		{Type: Ready, Status: StatusFalse, LastTransitionTime: past},
+	}
+	updated := []Condition{
+		New(ConfigMapSynced, StatusTrue, "", ""),
+		New(Ready, StatusTrue, "", ""),
+	}
+
+	result := Merge(current, updated)
+
+	if len(result) != 2 {
+		t.Fatal("expected", 2, "got", len(result))
+	}
+	transitioned := Get(result, ConfigMapSynced).LastTransitionTime
+	if !transitioned.Equal(&past) {
+		t.Fatal("expected", past, "got", transitioned)
+	}
+	if !IsTrue(result, Ready) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Conditions_Annotation(t *testing.T) {
+	conditions := []Condition{
+		{Type: Ready, Status: StatusTrue, LastTransitionTime: metav1.NewTime(time.Now().Truncate(time.Second))},
+	}
+
+	v, err := ToAnnotation(conditions)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	customObject := v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				Annotation: v,
+			},
+		},
+	}
+
+	result, err := FromCustomObject(customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !Equal(result, conditions) {
+		t.Fatalf("expected %#v got %#v", conditions, result)
+	}
+
+	customObject.Annotations[Annotation] = "ready"
+
+	_, err = FromCustomObject(customObject)
+	if !IsInvalidAnnotation(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
@@ -0,0 +1,223 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/backoff"
+	"github.com/giantswarm/micrologger/microloggertest"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/configmap"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/service"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/transaction"
+)
+
+// Test_Contract_ClusterOperator ensures the IngressConfigs created by the
+// current and previous cluster-operator releases are understood by this
+// operator. The fixtures in testdata/contract are decoded strictly, so that
+// fields unknown to this operator fail the test instead of being dropped
+// silently. New cluster-operator releases changing the shape of IngressConfigs
+// must add a fixture here before being rolled out.
+func Test_Contract_ClusterOperator(t *testing.T) {
+	testCases := []struct {
+		Fixture               string
+		ExpectedClusterID     string
+		ExpectedLBPorts       []int
+		ExpectedVersion       string
+		ExpectedConfigMapData map[string]string
+		ExpectedServicePorts  []apiv1.ServicePort
+	}{
+		// Test 0.
+		{
+			Fixture:           "cluster-operator-previous.yaml",
+			ExpectedClusterID: "al9qy",
+			ExpectedLBPorts:   []int{31000, 31001},
+			ExpectedVersion:   "0.1.0",
+			ExpectedConfigMapData: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31001": "al9qy/worker:30011",
+			},
+			ExpectedServicePorts: []apiv1.ServicePort{
+				{
+					Name:       "http-30010-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31000),
+					TargetPort: intstr.FromInt(31000),
+					NodePort:   int32(31000),
+				},
+				{
+					Name:       "https-30011-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31001),
+					TargetPort: intstr.FromInt(31001),
+					NodePort:   int32(31001),
+				},
+			},
+		},
+
+		// Test 1.
+		{
+			Fixture:           "cluster-operator-current.yaml",
+			ExpectedClusterID: "p1l6x",
+			ExpectedLBPorts:   []int{31003, 31002},
+			ExpectedVersion:   "0.2.0",
+			ExpectedConfigMapData: map[string]string{
+				"31002": "p1l6x/worker:30010",
+				"31003": "p1l6x/worker:30011",
+			},
+			ExpectedServicePorts: []apiv1.ServicePort{
+				{
+					Name:       "https-30011-p1l6x",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31003),
+					TargetPort: intstr.FromInt(31003),
+					NodePort:   int32(31003),
+				},
+				{
+					Name:       "http-30010-p1l6x",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31002),
+					TargetPort: intstr.FromInt(31002),
+					NodePort:   int32(31002),
+				},
+			},
+		},
+	}
+
+	var err error
+
+	var a *allocator.Allocator
+	{
+		a, err = allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var k8sClient clients.Interface
+	{
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var configMapResource *configmap.Resource
+	{
+		c := configmap.DefaultConfig()
+
+		c.Allocator = a
+		c.K8sClient = k8sClient
+		c.Logger = microloggertest.New()
+
+		configMapResource, err = configmap.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var coordinator *transaction.Coordinator
+	{
+		c := transaction.Config{
+			K8sClient: k8sClient,
+			Logger:    microloggertest.New(),
+
+			BackOffFactory: func() backoff.Interface { return backoff.NewMaxRetries(2, time.Millisecond) },
+		}
+
+		coordinator, err = transaction.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var serviceResource *service.Resource
+	{
+		c := service.DefaultConfig()
+
+		c.Allocator = a
+		c.Coordinator = coordinator
+		c.K8sClient = k8sClient
+		c.Logger = microloggertest.New()
+		c.ProjectName = "ingress-operator"
+
+		serviceResource, err = service.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	for i, tc := range testCases {
+		customObject, err := decodeFixture(filepath.Join("testdata", "contract", tc.Fixture))
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		if key.ClusterID(customObject) != tc.ExpectedClusterID {
+			t.Fatal("test", i, "expected", tc.ExpectedClusterID, "got", key.ClusterID(customObject))
+		}
+		if !reflect.DeepEqual(key.LBPorts(customObject), tc.ExpectedLBPorts) {
+			t.Fatal("test", i, "expected", tc.ExpectedLBPorts, "got", key.LBPorts(customObject))
+		}
+		if key.VersionBundleVersion(customObject) != tc.ExpectedVersion {
+			t.Fatal("test", i, "expected", tc.ExpectedVersion, "got", key.VersionBundleVersion(customObject))
+		}
+
+		ctx := controllercontext.NewContext(context.TODO(), controllercontext.Context{})
+
+		data, err := configMapResource.GetDesiredState(ctx, &customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(data, tc.ExpectedConfigMapData) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedConfigMapData, data)
+		}
+
+		ports, err := serviceResource.GetDesiredState(ctx, &customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(ports, tc.ExpectedServicePorts) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedServicePorts, ports)
+		}
+	}
+}
+
+// decodeFixture decodes the IngressConfig of the given YAML file. Unknown
+// fields are rejected, since they indicate a spec the operator does not know.
+func decodeFixture(path string) (v1alpha1.IngressConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return v1alpha1.IngressConfig{}, err
+	}
+
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return v1alpha1.IngressConfig{}, err
+	}
+
+	var customObject v1alpha1.IngressConfig
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.DisallowUnknownFields()
+	err = d.Decode(&customObject)
+	if err != nil {
+		return v1alpha1.IngressConfig{}, err
+	}
+
+	return customObject, nil
+}
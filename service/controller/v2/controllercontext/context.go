@@ -0,0 +1,115 @@
+// Package controllercontext stores and accesses the controller context. The
+// controller context is initialized once per reconciliation loop and carries
+// information shared between all resources of the resource set.
+package controllercontext
+
+import (
+	"context"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/transaction"
+)
+
+type contextKey string
+
+const controllerKey contextKey = "controller"
+
+// Context is the information shared between resources during a single
+// reconciliation loop.
+type Context struct {
+	// CertificatePendingPorts are the https LB ports whose guest cluster
+	// certificates are not issued yet. They are left out of the config map and
+	// the service until the certificates exist, so that no broken TLS is
+	// served.
+	CertificatePendingPorts map[int]bool
+	// ConfigMapApplied is whether the config map resource applied its changes
+	// during the reconciliation loop already.
+	ConfigMapApplied bool
+	// Conditions are the conditions recorded by the resources during the
+	// reconciliation loop. They are persisted by the status resource.
+	Conditions []conditions.Condition
+	// Corrections is the number of host cluster objects updated during the
+	// reconciliation loop because they drifted from the desired state.
+	Corrections int
+	// IngressController is the host cluster ingress controller the resources
+	// manage the service ports and config map data of.
+	IngressController IngressController
+	// IngressPorts are the ingress ports discovered from the Service of the
+	// guest cluster ingress controller by LB port. They take precedence over
+	// the ingress ports of the IngressConfig spec.
+	IngressPorts map[int]int
+	// Lease is the Lease of the shared ingress controller held across the
+	// config map and service resources, see the lock package. It is nil in
+	// case no Lease is held.
+	Lease *Lease
+	// NodePorts are the node ports Kubernetes assigned to the LB ports of the
+	// guest cluster in case the service of the ingress controller is of type
+	// LoadBalancer. They are recorded in the IngressConfig by the status
+	// resource.
+	NodePorts map[int]int
+	// PortNames are the names of the guest cluster service ports by LB port,
+	// which the config map data values of named ports reference instead of the
+	// ingress ports.
+	PortNames map[int]string
+	// ServicePortRemovalsDeferred is whether the service resource deferred
+	// removing service ports until the config map resource removed the config
+	// map entries routing to them, see the ordering package.
+	ServicePortRemovalsDeferred bool
+	// ServicePorts are the ports of the service of the ingress controller as
+	// found by the service resource, before its changes got applied. They are
+	// compared against the written ports to count the port mutations, which
+	// cause kube-proxy to update the dataplane of all nodes.
+	ServicePorts []apiv1.ServicePort
+	// Transaction records the config map update of the reconciliation loop
+	// until the service got updated as well, so that the service resource can
+	// resolve a partial apply.
+	Transaction transaction.Transaction
+}
+
+// Lease is a held Lease.
+type Lease struct {
+	// Context is canceled once the Lease is lost.
+	Context context.Context
+	// Release releases the Lease.
+	Release func()
+}
+
+// IngressController identifies the Kubernetes objects of a host cluster
+// ingress controller.
+type IngressController struct {
+	ConfigMap string
+	Namespace string
+	Service   string
+	// StreamSnippets is the config map holding the nginx stream snippets
+	// tuning the connections of single LB ports.
+	StreamSnippets string
+}
+
+// IngressPort returns the ingress port traffic of the given protocol port is
+// forwarded to. Discovered ingress ports take precedence over the one of the
+// spec.
+func (c *Context) IngressPort(p v1alpha1.IngressConfigSpecProtocolPort) int {
+	ingressPort, ok := c.IngressPorts[p.LBPort]
+	if ok {
+		return ingressPort
+	}
+
+	return p.IngressPort
+}
+
+func NewContext(ctx context.Context, c Context) context.Context {
+	return context.WithValue(ctx, controllerKey, &c)
+}
+
+func FromContext(ctx context.Context) (*Context, error) {
+	c, ok := ctx.Value(controllerKey).(*Context)
+	if !ok {
+		return nil, microerror.Mask(notFoundError)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,399 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/backoff"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/ordering"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/configmap"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/service"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/transaction"
+)
+
+// crashFixture is the state of the host cluster ingress controller a
+// reconciliation starts from, as found in testdata/crash.
+type crashFixture struct {
+	Deleted       bool                   `json:"deleted"`
+	IngressConfig v1alpha1.IngressConfig `json:"ingressConfig"`
+	ConfigMapData map[string]string      `json:"configMapData"`
+	ServicePorts  []apiv1.ServicePort    `json:"servicePorts"`
+}
+
+// crashHost is the fake host cluster of a crash scenario. All writes fail
+// once the write budget is used up, which stops the replica at this point
+// like a killed process. Writes of a killed process never happen, so the
+// rollbacks of failed transactions fail as well.
+type crashHost struct {
+	k8sClient *fake.Clientset
+	budget    int
+	order     ordering.Order
+	writes    int
+}
+
+func newCrashHost(t *testing.T, fixture crashFixture) *crashHost {
+	t.Helper()
+
+	h := &crashHost{
+		k8sClient: fake.NewSimpleClientset(),
+		budget:    -1,
+		order:     ordering.ConfigMapFirst,
+	}
+
+	// The host cluster objects are kept in a separate tracker, since the
+	// object tracker of the fake clientset cannot apply the JSON patches of
+	// the config map.
+	tracker := k8stesting.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())
+	for _, obj := range []runtime.Object{
+		&apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.IngressControllerConfigMap(fixture.IngressConfig),
+				Namespace: key.IngressControllerNamespace(fixture.IngressConfig),
+			},
+			Data: fixture.ConfigMapData,
+		},
+		&apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.IngressControllerService(fixture.IngressConfig),
+				Namespace: key.IngressControllerNamespace(fixture.IngressConfig),
+			},
+			Spec: apiv1.ServiceSpec{
+				Ports: fixture.ServicePorts,
+			},
+		},
+	} {
+		err := tracker.Add(obj)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	h.k8sClient.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction, ok := action.(k8stesting.PatchAction)
+		if !ok || action.GetResource().Resource != "configmaps" {
+			return k8stesting.ObjectReaction(tracker)(action)
+		}
+
+		gvr := apiv1.SchemeGroupVersion.WithResource("configmaps")
+		obj, err := tracker.Get(gvr, patchAction.GetNamespace(), patchAction.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		configMap := obj.(*apiv1.ConfigMap)
+
+		err = applyDataPatch(configMap, patchAction.GetPatch())
+		if err != nil {
+			return true, nil, err
+		}
+
+		return true, configMap, tracker.Update(gvr, configMap, patchAction.GetNamespace())
+	})
+
+	h.k8sClient.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		switch action.GetVerb() {
+		case "get", "list", "watch":
+			return false, nil, nil
+		}
+
+		if h.budget >= 0 && h.writes >= h.budget {
+			return true, nil, microerror.New("killed")
+		}
+		// Events are raised by best effort and are not part of the state of
+		// the host cluster, so they do not use up the write budget.
+		if action.GetResource().Resource == "events" {
+			return false, nil, nil
+		}
+		h.writes++
+
+		return false, nil, nil
+	})
+
+	return h
+}
+
+// Reconcile reconciles the given fixture once with the config map and the
+// service resources of a freshly booted replica, so that no state of earlier
+// reconciliations is kept in memory. The resources are executed in the order
+// of the host.
+func (h *crashHost) Reconcile(t *testing.T, fixture crashFixture) error {
+	t.Helper()
+
+	k8sClient, err := clients.New(clients.Config{K8sClient: h.k8sClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	a, err := allocator.New(allocator.Config{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	var configMapResource *controller.CRUDResource
+	{
+		c := configmap.DefaultConfig()
+
+		c.Allocator = a
+		c.K8sClient = k8sClient
+		c.Logger = microloggertest.New()
+
+		ops, err := configmap.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		configMapResource, err = toCRUDResource(microloggertest.New(), ops)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var serviceResource *controller.CRUDResource
+	{
+		coordinator, err := transaction.New(transaction.Config{
+			K8sClient: k8sClient,
+			Logger:    microloggertest.New(),
+
+			BackOffFactory: func() backoff.Interface { return backoff.NewMaxRetries(1, time.Millisecond) },
+		})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := service.DefaultConfig()
+
+		c.Allocator = a
+		c.Coordinator = coordinator
+		c.K8sClient = k8sClient
+		c.Logger = microloggertest.New()
+		c.ProjectName = "ingress-operator"
+
+		ops, err := service.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		serviceResource, err = toCRUDResource(microloggertest.New(), ops)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	customObject := fixture.IngressConfig.DeepCopy()
+	if fixture.Deleted {
+		now := metav1.Now()
+		customObject.DeletionTimestamp = &now
+	}
+
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+		IngressController: controllercontext.IngressController{
+			ConfigMap: key.IngressControllerConfigMap(*customObject),
+			Namespace: key.IngressControllerNamespace(*customObject),
+			Service:   key.IngressControllerService(*customObject),
+		},
+	})
+	ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+	resources, err := ordering.Resources(h.order, configMapResource, serviceResource)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	for _, r := range resources {
+		if fixture.Deleted {
+			err = r.EnsureDeleted(ctx, customObject)
+		} else {
+			err = r.EnsureCreated(ctx, customObject)
+		}
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if reconciliationcanceledcontext.IsCanceled(ctx) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// State returns the config map data and the service ports of the host cluster
+// ingress controller.
+func (h *crashHost) State(t *testing.T, fixture crashFixture) (map[string]string, []apiv1.ServicePort) {
+	t.Helper()
+
+	namespace := key.IngressControllerNamespace(fixture.IngressConfig)
+
+	configMap, err := h.k8sClient.CoreV1().ConfigMaps(namespace).Get(key.IngressControllerConfigMap(fixture.IngressConfig), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	service, err := h.k8sClient.CoreV1().Services(namespace).Get(key.IngressControllerService(fixture.IngressConfig), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return configMap.Data, service.Spec.Ports
+}
+
+// Test_Crash_Recovery ensures a replica killed at any point of a
+// reconciliation, in particular between the config map and the service
+// update, leaves a state the next replica fully repairs within a single
+// reconciliation. The scenarios in testdata/crash are first reconciled without
+// interruption, which yields the expected state and the number of writes.
+// Every scenario is then killed after each number of writes, rebooted and
+// reconciled once, which must result in the expected state without any write
+// left to do.
+func Test_Crash_Recovery(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "crash", "*.yaml"))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("expected", "fixtures", "got", nil)
+	}
+
+	for _, p := range paths {
+		fixture, err := decodeCrashFixture(p)
+		if err != nil {
+			t.Fatal("fixture", p, "expected", nil, "got", err)
+		}
+
+		reference := newCrashHost(t, fixture)
+		err = reference.Reconcile(t, fixture)
+		if err != nil {
+			t.Fatal("fixture", p, "expected", nil, "got", err)
+		}
+		expectedData, expectedPorts := reference.State(t, fixture)
+		if reference.writes == 0 {
+			t.Fatal("fixture", p, "expected", "writes", "got", 0)
+		}
+
+		for killedAfter := 0; killedAfter < reference.writes; killedAfter++ {
+			h := newCrashHost(t, fixture)
+
+			h.budget = killedAfter
+			err := h.Reconcile(t, fixture)
+			if err == nil {
+				t.Fatal("fixture", p, "killed after", killedAfter, "expected", "error", "got", nil)
+			}
+
+			h.budget = -1
+			err = h.Reconcile(t, fixture)
+			if err != nil {
+				t.Fatal("fixture", p, "killed after", killedAfter, "expected", nil, "got", err)
+			}
+
+			data, ports := h.State(t, fixture)
+			if !reflect.DeepEqual(data, expectedData) {
+				t.Fatalf("fixture %s killed after %d expected %#v got %#v", p, killedAfter, expectedData, data)
+			}
+			if !reflect.DeepEqual(ports, expectedPorts) {
+				t.Fatalf("fixture %s killed after %d expected %#v got %#v", p, killedAfter, expectedPorts, ports)
+			}
+
+			writes := h.writes
+			err = h.Reconcile(t, fixture)
+			if err != nil {
+				t.Fatal("fixture", p, "killed after", killedAfter, "expected", nil, "got", err)
+			}
+			if h.writes != writes {
+				t.Fatal("fixture", p, "killed after", killedAfter, "expected", 0, "got", h.writes-writes)
+			}
+		}
+	}
+}
+
+// applyDataPatch applies the given JSON Patch of config map data keys to the
+// given config map the way the Kubernetes API does, since the fake clientset
+// cannot apply JSON patches. It only supports the operations of the patches
+// written by the config map resource.
+func applyDataPatch(configMap *apiv1.ConfigMap, patch []byte) error {
+	var ops []struct {
+		Op    string  `json:"op"`
+		Path  string  `json:"path"`
+		Value *string `json:"value"`
+	}
+	err := json.Unmarshal(patch, &ops)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if configMap.Data == nil {
+		return microerror.New("path /data does not exist")
+	}
+
+	data := map[string]string{}
+	for k, v := range configMap.Data {
+		data[k] = v
+	}
+
+	for _, o := range ops {
+		k := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(o.Path, "/data/"))
+		v, ok := data[k]
+
+		switch {
+		case o.Op == "test" && o.Value == nil && ok:
+			return microerror.Newf("path %#q exists", o.Path)
+		case o.Op == "test" && o.Value != nil && (!ok || v != *o.Value):
+			return microerror.Newf("path %#q does not have value %#q", o.Path, *o.Value)
+		case o.Op == "add":
+			data[k] = *o.Value
+		case o.Op == "replace" && ok:
+			data[k] = *o.Value
+		case o.Op == "remove" && ok:
+			delete(data, k)
+		case o.Op != "test":
+			return microerror.Newf("operation %#q of path %#q cannot be applied", o.Op, o.Path)
+		}
+	}
+
+	configMap.Data = data
+
+	return nil
+}
+
+// decodeCrashFixture decodes the crash fixture of the given YAML file. Unknown
+// fields are rejected, so that typos do not silently weaken the scenario.
+func decodeCrashFixture(path string) (crashFixture, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return crashFixture{}, err
+	}
+
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return crashFixture{}, err
+	}
+
+	var fixture crashFixture
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.DisallowUnknownFields()
+	err = d.Decode(&fixture)
+	if err != nil {
+		return crashFixture{}, err
+	}
+
+	return fixture, nil
+}
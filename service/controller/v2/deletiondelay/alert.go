@@ -0,0 +1,242 @@
+package deletiondelay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+const (
+	// EventReason is the reason of the events raised for deletions blocked
+	// beyond the SLA.
+	EventReason = "DeletionBlocked"
+
+	// maxPodNames is the maximum number of remaining pods named by an event.
+	maxPodNames = 10
+)
+
+// Blocked describes a deletion delayed by remaining pods for longer than the
+// SLA.
+type Blocked struct {
+	ClusterID     string
+	IngressConfig v1alpha1.IngressConfig
+	// New is whether the deletion exceeded the SLA since the last check.
+	New     bool
+	Pending time.Duration
+	// Pods are the names of the pods remaining in the guest cluster namespace
+	// at the last check.
+	Pods []string
+}
+
+// AlerterConfig represents the configuration used to create a new alerter.
+type AlerterConfig struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// Interval is the interval in which delayed deletions are checked.
+	Interval    time.Duration
+	ProjectName string
+	// SLA is the time deletions may be delayed by remaining pods before they
+	// are reported as blocked.
+	SLA time.Duration
+}
+
+// DefaultAlerterConfig provides a default configuration to create a new
+// alerter by best effort.
+func DefaultAlerterConfig() AlerterConfig {
+	return AlerterConfig{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		Interval:    time.Minute,
+		ProjectName: "",
+		SLA:         0,
+	}
+}
+
+// Alerter reports deletions blocked by remaining pods for longer than the
+// SLA, so that on-call can intervene, e.g. by draining the remaining nodes
+// manually. Each blocked deletion raises a warning event on its IngressConfig
+// naming the remaining pods once and is exposed as metric until it finished.
+type Alerter struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Internals.
+	mutex   sync.Mutex
+	stop    chan struct{}
+	tracker *tracker
+
+	// Settings.
+	interval    time.Duration
+	projectName string
+	sla         time.Duration
+}
+
+// NewAlerter creates a new configured alerter.
+func NewAlerter(config AlerterConfig) (*Alerter, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	// Settings.
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+	if config.ProjectName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ProjectName must not be empty", config)
+	}
+	if config.SLA <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.SLA must be greater than 0", config)
+	}
+
+	a := &Alerter{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		// Internals.
+		mutex:   sync.Mutex{},
+		stop:    nil,
+		tracker: deletions,
+
+		// Settings.
+		interval:    config.Interval,
+		projectName: config.ProjectName,
+		sla:         config.SLA,
+	}
+
+	return a, nil
+}
+
+// Boot checks the delayed deletions periodically in the background.
+func (a *Alerter) Boot() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.stop != nil {
+		return
+	}
+
+	a.stop = make(chan struct{})
+	go a.run(a.stop)
+}
+
+// Shutdown stops checking the delayed deletions.
+func (a *Alerter) Shutdown() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.stop == nil {
+		return
+	}
+
+	close(a.stop)
+	a.stop = nil
+}
+
+// Alert updates the metric of the deletions blocked beyond the SLA and raises
+// an event for the ones which exceeded it since the last check.
+func (a *Alerter) Alert(ctx context.Context) []Blocked {
+	blocked := a.tracker.blocked(a.sla)
+
+	blockedGauge.Reset()
+	for _, b := range blocked {
+		blockedGauge.WithLabelValues(b.ClusterID).Set(b.Pending.Seconds())
+
+		if !b.New {
+			continue
+		}
+
+		message := fmt.Sprintf("deletion of guest cluster %s is blocked for %s, exceeding the SLA of %s, by %d pods remaining in namespace %s: %s", b.ClusterID, b.Pending.Round(time.Second), a.sla, len(b.Pods), key.ClusterNamespace(b.IngressConfig), describePods(b.Pods))
+
+		logging.Warning(logging.NewContext(ctx, b.ClusterID), a.logger, message)
+		breachesCounter.Inc()
+
+		// Failing to raise the event must not stop reporting the other
+		// deletions, so the error is only logged.
+		_, err := a.k8sClient.CreateEvent(ctx, b.IngressConfig.Namespace, a.newEvent(b.IngressConfig, message))
+		if err != nil {
+			logging.Error(logging.NewContext(ctx, b.ClusterID), a.logger, "failed raising event for blocked deletion", err)
+		}
+	}
+
+	return blocked
+}
+
+func (a *Alerter) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		a.Alert(context.Background())
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Alerter) newEvent(customObject v1alpha1.IngressConfig, message string) *corev1.Event {
+	now := metav1.NewTime(time.Now())
+
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", customObject.Name, now.UnixNano()),
+			Namespace: customObject.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      v1alpha1.SchemeGroupVersion.String(),
+			Kind:            "IngressConfig",
+			Name:            customObject.Name,
+			Namespace:       customObject.Namespace,
+			ResourceVersion: customObject.ResourceVersion,
+			UID:             customObject.UID,
+		},
+		Reason:  EventReason,
+		Message: message,
+		Source: corev1.EventSource{
+			Component: a.projectName,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           corev1.EventTypeWarning,
+	}
+
+	return event
+}
+
+// describePods lists the given pod names, shortened to the first ones in case
+// there are many, so that the event stays readable.
+func describePods(pods []string) string {
+	if len(pods) <= maxPodNames {
+		return strings.Join(pods, ", ")
+	}
+
+	return fmt.Sprintf("%s and %d more", strings.Join(pods[:maxPodNames], ", "), len(pods)-maxPodNames)
+}
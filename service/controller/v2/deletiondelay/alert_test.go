@@ -0,0 +1,117 @@
+package deletiondelay
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+func Test_Alerter_Alert(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+
+	deletions = newTracker()
+	deletions.now = func() time.Time { return now }
+
+	deletionTimestamp := metav1.NewTime(now)
+	customObject := v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			DeletionTimestamp: &deletionTimestamp,
+			Name:              "al9qy",
+			Namespace:         "default",
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	k8sClient := fake.NewSimpleClientset()
+	k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	c := DefaultAlerterConfig()
+
+	c.K8sClient = k8sClients
+	c.Logger = microloggertest.New()
+
+	c.ProjectName = "ingress-operator"
+	c.SLA = time.Hour
+
+	a, err := NewAlerter(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	events := func() []string {
+		list, err := k8sClient.CoreV1().Events("default").List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		var messages []string
+		for _, e := range list.Items {
+			if e.Reason != EventReason || e.InvolvedObject.Name != "al9qy" {
+				t.Fatal("expected", EventReason+" al9qy", "got", e.Reason+" "+e.InvolvedObject.Name)
+			}
+			messages = append(messages, e.Message)
+		}
+
+		return messages
+	}
+
+	// Deletions delayed within the SLA are not reported.
+	now = now.Add(30 * time.Minute)
+	Wait(customObject, newPods(12))
+	blocked := a.Alert(context.TODO())
+	if len(blocked) != 0 {
+		t.Fatal("expected", 0, "got", len(blocked))
+	}
+	if len(events()) != 0 {
+		t.Fatal("expected", 0, "got", len(events()))
+	}
+
+	// Deletions delayed beyond the SLA raise an event naming the remaining
+	// pods.
+	now = now.Add(time.Hour)
+	Wait(customObject, newPods(12))
+	blocked = a.Alert(context.TODO())
+	if len(blocked) != 1 || !blocked[0].New {
+		t.Fatalf("expected one new blocked deletion got %#v", blocked)
+	}
+	messages := events()
+	if len(messages) != 1 {
+		t.Fatal("expected", 1, "got", len(messages))
+	}
+	if !strings.Contains(messages[0], "12 pods remaining in namespace al9qy: pod-00, pod-01") || !strings.HasSuffix(messages[0], "pod-09 and 2 more") {
+		t.Fatal("expected", "message naming the remaining pods", "got", messages[0])
+	}
+
+	// Blocked deletions raise a single event.
+	now = now.Add(time.Minute)
+	Wait(customObject, newPods(1))
+	blocked = a.Alert(context.TODO())
+	if len(blocked) != 1 || blocked[0].New {
+		t.Fatalf("expected one known blocked deletion got %#v", blocked)
+	}
+	if len(events()) != 1 {
+		t.Fatal("expected", 1, "got", len(events()))
+	}
+
+	// Finished deletions are not reported anymore.
+	Done(customObject)
+	blocked = a.Alert(context.TODO())
+	if len(blocked) != 0 {
+		t.Fatal("expected", 0, "got", len(blocked))
+	}
+}
@@ -0,0 +1,227 @@
+// Package deletiondelay exposes the deletions of IngressConfigs delayed by pods
+// remaining in the guest cluster namespace. Resources keep the ingress
+// controller data of deleted guest clusters as long as their pods exist, so
+// nodes can still be drained through ingress, which can block deletions for a
+// long time. Stuck deletions become visible on dashboards instead of only in
+// the logs. Deletions blocked for longer than an SLA are reported to on-call
+// by the alerter.
+package deletiondelay
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// deletions tracks the delayed deletions of all controllers, since the
+// resource sets of all workers share the registered metrics.
+var deletions = newTracker()
+
+// Wait records that the deletion of the given IngressConfig is delayed by the
+// given pods remaining in the guest cluster namespace. It is called by every
+// resource delaying the deletion.
+func Wait(customObject v1alpha1.IngressConfig, pods []corev1.Pod) {
+	deletions.wait(customObject, pods)
+}
+
+// Done records that no pods remain in the guest cluster namespace of the given
+// IngressConfig, so its deletion is not delayed anymore. The delay is observed
+// once for deletions which got delayed. It is called by every resource
+// delaying the deletion.
+func Done(customObject v1alpha1.IngressConfig) {
+	deletions.done(customObject)
+}
+
+// Pending returns all deletions currently delayed by remaining pods, sorted by
+// guest cluster ID.
+func Pending() []Deletion {
+	return deletions.pending()
+}
+
+// Deletion describes a deletion delayed by pods remaining in the guest cluster
+// namespace.
+type Deletion struct {
+	// Alerted is whether the deletion got reported as blocked beyond the SLA
+	// already, see Alerter.
+	Alerted   bool   `json:"alerted"`
+	ClusterID string `json:"clusterID"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// PendingSeconds is the number of seconds the deletion is pending since
+	// it got requested.
+	PendingSeconds int64 `json:"pendingSeconds"`
+	// Pods are the names of the pods remaining in the guest cluster namespace
+	// at the last check.
+	Pods []string `json:"pods"`
+	// RemainingPods is the number of pods remaining in the guest cluster
+	// namespace at the last check.
+	RemainingPods int       `json:"remainingPods"`
+	Since         time.Time `json:"since"`
+}
+
+type deletion struct {
+	// alerted is whether the deletion got reported as blocked beyond the SLA
+	// already, see Alerter.
+	alerted       bool
+	ingressConfig v1alpha1.IngressConfig
+	pods          []string
+	since         time.Time
+}
+
+// tracker records the delayed deletions per guest cluster and exposes the
+// time they are pending at collection time.
+type tracker struct {
+	pendingDesc *prometheus.Desc
+	podsDesc    *prometheus.Desc
+
+	mutex     sync.Mutex
+	now       func() time.Time
+	deletions map[string]deletion
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		pendingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(project.PrometheusNamespace, PrometheusSubsystem, "pending_seconds"),
+			"A gauge metric expressing the seconds the deletion of a guest cluster is pending while being delayed by remaining pods.",
+			[]string{"cluster_id"},
+			nil,
+		),
+		podsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(project.PrometheusNamespace, PrometheusSubsystem, "remaining_pods"),
+			"A gauge metric expressing the number of pods in the namespace of a guest cluster delaying its deletion.",
+			[]string{"cluster_id"},
+			nil,
+		),
+
+		mutex:     sync.Mutex{},
+		now:       time.Now,
+		deletions: map[string]deletion{},
+	}
+}
+
+func (t *tracker) wait(customObject v1alpha1.IngressConfig, pods []corev1.Pod) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var names []string
+	for _, p := range pods {
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+
+	id := key.ClusterID(customObject)
+	t.deletions[id] = deletion{
+		alerted:       t.deletions[id].alerted,
+		ingressConfig: customObject,
+		pods:          names,
+		since:         deletionTime(customObject, t.now()),
+	}
+}
+
+func (t *tracker) done(customObject v1alpha1.IngressConfig) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	id := key.ClusterID(customObject)
+
+	d, ok := t.deletions[id]
+	if !ok {
+		return
+	}
+
+	delayHistogram.Observe(t.now().Sub(d.since).Seconds())
+	delete(t.deletions, id)
+}
+
+func (t *tracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.pendingDesc
+	ch <- t.podsDesc
+}
+
+func (t *tracker) Collect(ch chan<- prometheus.Metric) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	for id, d := range t.deletions {
+		ch <- prometheus.MustNewConstMetric(t.pendingDesc, prometheus.GaugeValue, now.Sub(d.since).Seconds(), id)
+		ch <- prometheus.MustNewConstMetric(t.podsDesc, prometheus.GaugeValue, float64(len(d.pods)), id)
+	}
+}
+
+// pending returns all delayed deletions, sorted by guest cluster ID.
+func (t *tracker) pending() []Deletion {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+
+	var list []Deletion
+	for id, d := range t.deletions {
+		list = append(list, Deletion{
+			Alerted:        d.alerted,
+			ClusterID:      id,
+			Name:           d.ingressConfig.Name,
+			Namespace:      d.ingressConfig.Namespace,
+			PendingSeconds: int64(now.Sub(d.since).Seconds()),
+			Pods:           append([]string(nil), d.pods...),
+			RemainingPods:  len(d.pods),
+			Since:          d.since,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ClusterID < list[j].ClusterID })
+
+	return list
+}
+
+// blocked returns the deletions pending for at least the given duration. Each
+// deletion is reported as new once.
+func (t *tracker) blocked(sla time.Duration) []Blocked {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+
+	var list []Blocked
+	for id, d := range t.deletions {
+		pending := now.Sub(d.since)
+		if pending < sla {
+			continue
+		}
+
+		list = append(list, Blocked{
+			ClusterID:     id,
+			IngressConfig: d.ingressConfig,
+			New:           !d.alerted,
+			Pending:       pending,
+			Pods:          d.pods,
+		})
+
+		d.alerted = true
+		t.deletions[id] = d
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ClusterID < list[j].ClusterID })
+
+	return list
+}
+
+// deletionTime returns the time the deletion of the given IngressConfig got
+// requested, so that delays survive restarts of the operator. It falls back to
+// the given time in case the IngressConfig is not being deleted.
+func deletionTime(customObject v1alpha1.IngressConfig, fallback time.Time) time.Time {
+	if customObject.DeletionTimestamp == nil {
+		return fallback
+	}
+
+	return customObject.DeletionTimestamp.Time
+}
@@ -0,0 +1,172 @@
+package deletiondelay
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// collect returns the values of the gauges of the tracker by metric name and
+// cluster ID.
+func collect(t *tracker) map[string]float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		t.Collect(ch)
+		close(ch)
+	}()
+
+	values := map[string]float64{}
+	for m := range ch {
+		var pb dto.Metric
+		err := m.Write(&pb)
+		if err != nil {
+			panic(err)
+		}
+
+		name := "pending"
+		if m.Desc() == t.podsDesc {
+			name = "pods"
+		}
+		values[name+"/"+pb.GetLabel()[0].GetValue()] = pb.GetGauge().GetValue()
+	}
+
+	return values
+}
+
+// newPods returns the given number of pods named after their index.
+func newPods(n int) []corev1.Pod {
+	var pods []corev1.Pod
+	for i := 0; i < n; i++ {
+		pods = append(pods, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%02d", i)}})
+	}
+
+	return pods
+}
+
+func Test_DeletionDelay(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+
+	deletions = newTracker()
+	deletions.now = func() time.Time { return now }
+
+	deletionTimestamp := metav1.NewTime(now)
+	customObject := v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			DeletionTimestamp: &deletionTimestamp,
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	// Deletions not being delayed are not exposed.
+	Done(customObject)
+	if len(collect(deletions)) != 0 {
+		t.Fatal("expected", 0, "got", len(collect(deletions)))
+	}
+
+	// Delayed deletions count from the deletion request and expose the pods
+	// remaining at the last check.
+	now = now.Add(5 * time.Minute)
+	Wait(customObject, newPods(7))
+	now = now.Add(5 * time.Minute)
+	Wait(customObject, newPods(3))
+
+	values := collect(deletions)
+	if values["pending/al9qy"] != 600 {
+		t.Fatal("expected", 600, "got", values["pending/al9qy"])
+	}
+	if values["pods/al9qy"] != 3 {
+		t.Fatal("expected", 3, "got", values["pods/al9qy"])
+	}
+
+	// Finished deletions are not exposed anymore.
+	Done(customObject)
+	Done(customObject)
+	if len(collect(deletions)) != 0 {
+		t.Fatal("expected", 0, "got", len(collect(deletions)))
+	}
+}
+
+// Test_Pending ensures all delayed deletions are listed with the time they are
+// pending and the pods remaining at the last check, regardless of the SLA.
+func Test_Pending(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+
+	deletions = newTracker()
+	deletions.now = func() time.Time { return now }
+
+	newCustomObject := func(clusterID string) v1alpha1.IngressConfig {
+		deletionTimestamp := metav1.NewTime(now)
+		return v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				DeletionTimestamp: &deletionTimestamp,
+				Name:              clusterID,
+				Namespace:         "default",
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+					ID: clusterID,
+				},
+			},
+		}
+	}
+
+	if len(Pending()) != 0 {
+		t.Fatal("expected", 0, "got", len(Pending()))
+	}
+
+	p1l6x := newCustomObject("p1l6x")
+	al9qy := newCustomObject("al9qy")
+	now = now.Add(time.Minute)
+	Wait(p1l6x, newPods(1))
+	Wait(al9qy, newPods(2))
+	now = now.Add(time.Minute)
+
+	expected := []Deletion{
+		{
+			ClusterID:      "al9qy",
+			Name:           "al9qy",
+			Namespace:      "default",
+			PendingSeconds: 120,
+			Pods:           []string{"pod-00", "pod-01"},
+			RemainingPods:  2,
+			Since:          al9qy.DeletionTimestamp.Time,
+		},
+		{
+			ClusterID:      "p1l6x",
+			Name:           "p1l6x",
+			Namespace:      "default",
+			PendingSeconds: 120,
+			Pods:           []string{"pod-00"},
+			RemainingPods:  1,
+			Since:          p1l6x.DeletionTimestamp.Time,
+		},
+	}
+	pending := Pending()
+	if !reflect.DeepEqual(pending, expected) {
+		t.Fatalf("expected %#v got %#v", expected, pending)
+	}
+
+	// Deletions reported beyond the SLA are flagged as alerted.
+	deletions.blocked(time.Minute)
+	pending = Pending()
+	if !pending[0].Alerted || !pending[1].Alerted {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	Done(al9qy)
+	pending = Pending()
+	if len(pending) != 1 || pending[0].ClusterID != "p1l6x" {
+		t.Fatalf("expected %#v got %#v", expected[1:], pending)
+	}
+}
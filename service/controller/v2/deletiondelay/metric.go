@@ -0,0 +1,47 @@
+package deletiondelay
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "deletion"
+)
+
+var (
+	blockedGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "blocked_seconds",
+			Help:      "A gauge metric expressing the seconds the deletion of a guest cluster is pending while being blocked by remaining pods for longer than the SLA.",
+		},
+		[]string{"cluster_id"},
+	)
+	breachesCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "sla_breaches_total",
+			Help:      "A counter metric expressing the number of deletions of guest clusters blocked by remaining pods for longer than the SLA.",
+		},
+	)
+	delayHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "delay_seconds",
+			Help:      "A histogram metric expressing the seconds deletions of guest clusters got delayed by remaining pods, from the deletion request until no pods remained.",
+			Buckets:   prometheus.ExponentialBuckets(15, 2, 12),
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(blockedGauge)
+	prometheus.MustRegister(breachesCounter)
+	prometheus.MustRegister(delayHistogram)
+	prometheus.MustRegister(deletions)
+}
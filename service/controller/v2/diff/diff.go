@@ -0,0 +1,73 @@
+// Package diff renders concise human readable representations of the changes
+// computed by resources, e.g. to log patches without dumping full structures.
+// A rendered diff looks like the following.
+//
+//	+31007 -> x9k2p/worker:30010, -31005, ~31001 al9qy/worker:30011 -> p1l6x/worker:30011
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// NoChanges is rendered in case there are no changes at all.
+	NoChanges = "no changes"
+)
+
+// Change describes a single modification of a keyed value. An empty Old value
+// describes an addition. An empty New value describes a removal. Otherwise the
+// change describes an update of the value.
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+// Added returns a change describing the addition of the given key and value.
+func Added(key, value string) Change {
+	return Change{Key: key, New: value}
+}
+
+// Removed returns a change describing the removal of the given key and value.
+func Removed(key, value string) Change {
+	return Change{Key: key, Old: value}
+}
+
+// Updated returns a change describing the update of the value of the given
+// key.
+func Updated(key, oldValue, newValue string) Change {
+	return Change{Key: key, Old: oldValue, New: newValue}
+}
+
+// Render renders the given changes ordered by key.
+func Render(changes []Change) string {
+	if len(changes) == 0 {
+		return NoChanges
+	}
+
+	sorted := make([]Change, len(changes))
+	copy(sorted, changes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	var rendered []string
+	for _, c := range sorted {
+		rendered = append(rendered, c.String())
+	}
+
+	return strings.Join(rendered, ", ")
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Old == "":
+		return fmt.Sprintf("+%s -> %s", c.Key, c.New)
+	case c.New == "":
+		return fmt.Sprintf("-%s", c.Key)
+	default:
+		return fmt.Sprintf("~%s %s -> %s", c.Key, c.Old, c.New)
+	}
+}
@@ -0,0 +1,44 @@
+package diff
+
+import (
+	"testing"
+)
+
+func Test_Diff_Render(t *testing.T) {
+	testCases := []struct {
+		Changes  []Change
+		Expected string
+	}{
+		// Test 0 ensures an empty list of changes is rendered accordingly.
+		{
+			Changes:  nil,
+			Expected: NoChanges,
+		},
+
+		// Test 1 ensures additions and removals are rendered as expected.
+		{
+			Changes: []Change{
+				Added("31007", "x9k2p/worker:30010"),
+				Removed("31005", "x9k2p/worker:30011"),
+			},
+			Expected: "-31005, +31007 -> x9k2p/worker:30010",
+		},
+
+		// Test 2 ensures updates are rendered as expected and changes are ordered
+		// by key.
+		{
+			Changes: []Change{
+				Updated("31001", "al9qy/worker:30011", "p1l6x/worker:30011"),
+				Added("31000", "p1l6x/worker:30010"),
+			},
+			Expected: "+31000 -> p1l6x/worker:30010, ~31001 al9qy/worker:30011 -> p1l6x/worker:30011",
+		},
+	}
+
+	for i, tc := range testCases {
+		result := Render(tc.Changes)
+		if result != tc.Expected {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, result)
+		}
+	}
+}
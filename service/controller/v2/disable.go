@@ -0,0 +1,88 @@
+package v2
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+)
+
+// resourceConditions are the conditions recorded by the resources which can be
+// disabled, by resource name.
+var resourceConditions = map[string]conditions.Type{
+	"configmap": conditions.ConfigMapSynced,
+	"ledger":    conditions.PortsAllocated,
+	"selftest":  conditions.IngressReachable,
+	"service":   conditions.ServiceSynced,
+}
+
+// resourceName returns the name the given resource is disabled with, which is
+// its name without the version suffix, e.g. "configmap" for "configmapv2".
+func resourceName(r controller.Resource) string {
+	return strings.TrimSuffix(r.Name(), "v2")
+}
+
+// disableResources returns the given resources without the ones having any of
+// the given names. It returns an error in case any of the given names is not
+// the one of a resource, so that typos do not go unnoticed.
+func disableResources(resources []controller.Resource, disabled []string) ([]controller.Resource, error) {
+	known := map[string]bool{}
+	for _, r := range resources {
+		known[resourceName(r)] = true
+	}
+
+	skip := map[string]bool{}
+	for _, n := range disabled {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+		if !known[n] {
+			var names []string
+			for k := range known {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+
+			return nil, microerror.Maskf(invalidConfigError, "unknown resource %q, expected one of %s", n, strings.Join(names, ", "))
+		}
+		skip[n] = true
+	}
+
+	var enabled []controller.Resource
+	for _, r := range resources {
+		if !skip[resourceName(r)] {
+			enabled = append(enabled, r)
+		}
+	}
+
+	return enabled, nil
+}
+
+// isDisabled returns whether the given resource is disabled.
+func isDisabled(disabled []string, r controller.Resource) bool {
+	for _, n := range disabled {
+		if strings.TrimSpace(n) == resourceName(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unmanagedConditions returns the conditions no resource records,
+// since the resources recording them are disabled.
+func unmanagedConditions(disabled []string) []conditions.Type {
+	var types []conditions.Type
+	for _, n := range disabled {
+		t, ok := resourceConditions[strings.TrimSpace(n)]
+		if ok {
+			types = append(types, t)
+		}
+	}
+
+	return types
+}
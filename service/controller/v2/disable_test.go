@@ -0,0 +1,93 @@
+package v2
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/operatorkit/controller"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+)
+
+// namedResource is a resource doing nothing but having a name.
+type namedResource struct {
+	name string
+}
+
+func (r *namedResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	return nil
+}
+
+func (r *namedResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
+
+func (r *namedResource) Name() string {
+	return r.name
+}
+
+func Test_ResourceSet_disableResources(t *testing.T) {
+	resources := []controller.Resource{
+		&namedResource{name: "servicev2"},
+		&namedResource{name: "configmapv2"},
+		&namedResource{name: "servicev2"},
+		&namedResource{name: "statusv2"},
+	}
+
+	testCases := []struct {
+		Disabled           []string
+		ExpectedNames      []string
+		ExpectedConditions []conditions.Type
+		ErrorMatcher       func(error) bool
+	}{
+		// Test 0 ensures all resources are executed in case none is disabled.
+		{
+			Disabled:           nil,
+			ExpectedNames:      []string{"service", "configmap", "service", "status"},
+			ExpectedConditions: nil,
+			ErrorMatcher:       nil,
+		},
+
+		// Test 1 ensures disabled resources are removed wherever they occur and
+		// their conditions become unmanaged.
+		{
+			Disabled:           []string{"service", " status"},
+			ExpectedNames:      []string{"configmap"},
+			ExpectedConditions: []conditions.Type{conditions.ServiceSynced},
+			ErrorMatcher:       nil,
+		},
+
+		// Test 2 ensures unknown resources are rejected.
+		{
+			Disabled:     []string{"configmapv2"},
+			ErrorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		enabled, err := disableResources(resources, tc.Disabled)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil {
+			if !tc.ErrorMatcher(err) {
+				t.Fatal("test", i, "expected", true, "got", false)
+			}
+			continue
+		}
+
+		var names []string
+		for _, r := range enabled {
+			names = append(names, resourceName(r))
+		}
+		if !reflect.DeepEqual(names, tc.ExpectedNames) {
+			t.Fatal("test", i, "expected", tc.ExpectedNames, "got", names)
+		}
+
+		unmanaged := unmanagedConditions(tc.Disabled)
+		if !reflect.DeepEqual(unmanaged, tc.ExpectedConditions) {
+			t.Fatal("test", i, "expected", tc.ExpectedConditions, "got", unmanaged)
+		}
+	}
+}
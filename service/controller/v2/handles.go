@@ -0,0 +1,80 @@
+package v2
+
+import (
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// Handles returns whether the resource set reconciles the given custom object.
+// Custom objects have to be of the version bundle version of the resource set
+// and have to reference one of the given host cluster ingress controllers, so
+// that multiple operator instances can manage different ingress controllers
+// of the same host cluster. Any ingress controller is matched in case none is
+// given. In namespace-scoped mode custom objects have to be in one of the
+// given namespaces in addition, so that tenants of shared host clusters
+// cannot have IngressConfigs honored outside of their namespaces. Any
+// namespace is matched in case none is given.
+func Handles(customObject v1alpha1.IngressConfig, ingressControllers, namespaces []string) bool {
+	if !handlesVersion(customObject) {
+		return false
+	}
+	if !handlesNamespace(customObject, namespaces) {
+		return false
+	}
+	if len(ingressControllers) == 0 {
+		return true
+	}
+
+	id := key.IngressControllerID(customObject)
+	for _, c := range ingressControllers {
+		if strings.TrimSpace(c) == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func handlesNamespace(customObject v1alpha1.IngressConfig, namespaces []string) bool {
+	if len(namespaces) == 0 {
+		return true
+	}
+
+	for _, n := range namespaces {
+		if strings.TrimSpace(n) == customObject.Namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateIngressControllers returns an error in case any of the given
+// ingress controller identities is not of the form <namespace>/<service>.
+func ValidateIngressControllers(ingressControllers []string) error {
+	for _, c := range ingressControllers {
+		parts := strings.Split(strings.TrimSpace(c), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return microerror.Maskf(invalidConfigError, "ingress controller %q must be of the form <namespace>/<service>", c)
+		}
+	}
+
+	return nil
+}
+
+func handlesVersion(customObject v1alpha1.IngressConfig) bool {
+	if key.VersionBundleVersion(customObject) == VersionBundle().Version {
+		return true
+	}
+	// TODO remove this hack with the next version bundle version or as soon as
+	// all ingressconfigs obtain a real version bundle version.
+	if key.VersionBundleVersion(customObject) == "" {
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,144 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ResourceSet_Handles(t *testing.T) {
+	testCases := []struct {
+		Version            string
+		Namespace          string
+		IngressControllers []string
+		Namespaces         []string
+		Expected           bool
+	}{
+		// Test 0 ensures custom objects of any ingress controller are handled in
+		// case no ingress controllers are configured.
+		{
+			Version:            VersionBundle().Version,
+			IngressControllers: nil,
+			Expected:           true,
+		},
+
+		// Test 1 ensures custom objects referencing a configured ingress
+		// controller are handled.
+		{
+			Version:            VersionBundle().Version,
+			IngressControllers: []string{"kube-system/other", " kube-system/ingress-controller"},
+			Expected:           true,
+		},
+
+		// Test 2 ensures custom objects referencing other ingress controllers are
+		// left to other operator instances.
+		{
+			Version:            VersionBundle().Version,
+			IngressControllers: []string{"kube-system/other"},
+			Expected:           false,
+		},
+
+		// Test 3 ensures custom objects without version bundle version are
+		// handled.
+		{
+			Version:            "",
+			IngressControllers: []string{"kube-system/ingress-controller"},
+			Expected:           true,
+		},
+
+		// Test 4 ensures custom objects of other version bundle versions are not
+		// handled.
+		{
+			Version:            "0.0.1",
+			IngressControllers: nil,
+			Expected:           false,
+		},
+
+		// Test 5 ensures custom objects of the configured namespaces are handled
+		// in namespace-scoped mode.
+		{
+			Version:            VersionBundle().Version,
+			Namespace:          "tenant-a",
+			IngressControllers: nil,
+			Namespaces:         []string{"tenant-b", " tenant-a"},
+			Expected:           true,
+		},
+
+		// Test 6 ensures custom objects of other namespaces are ignored in
+		// namespace-scoped mode.
+		{
+			Version:            VersionBundle().Version,
+			Namespace:          "tenant-c",
+			IngressControllers: nil,
+			Namespaces:         []string{"tenant-a", "tenant-b"},
+			Expected:           false,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject := v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: tc.Namespace,
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+					IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+						ConfigMap: "ingress-controller",
+						Namespace: "kube-system",
+						Service:   "ingress-controller",
+					},
+				},
+				VersionBundle: v1alpha1.IngressConfigSpecVersionBundle{
+					Version: tc.Version,
+				},
+			},
+		}
+
+		handles := Handles(customObject, tc.IngressControllers, tc.Namespaces)
+		if handles != tc.Expected {
+			t.Fatal("test", i, "expected", tc.Expected, "got", handles)
+		}
+	}
+}
+
+func Test_ResourceSet_ValidateIngressControllers(t *testing.T) {
+	testCases := []struct {
+		IngressControllers []string
+		ErrorMatcher       func(error) bool
+	}{
+		// Test 0 ensures no ingress controllers are valid.
+		{
+			IngressControllers: nil,
+			ErrorMatcher:       nil,
+		},
+
+		// Test 1 ensures namespaced ingress controllers are valid.
+		{
+			IngressControllers: []string{"kube-system/ingress-controller"},
+			ErrorMatcher:       nil,
+		},
+
+		// Test 2 ensures ingress controllers without namespace are rejected.
+		{
+			IngressControllers: []string{"ingress-controller"},
+			ErrorMatcher:       IsInvalidConfig,
+		},
+
+		// Test 3 ensures ingress controllers with empty parts are rejected.
+		{
+			IngressControllers: []string{"kube-system/"},
+			ErrorMatcher:       IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		err := ValidateIngressControllers(tc.IngressControllers)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+	}
+}
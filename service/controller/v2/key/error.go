@@ -2,6 +2,24 @@ package key
 
 import "github.com/giantswarm/microerror"
 
+var invalidAnnotationError = &microerror.Error{
+	Kind: "invalidAnnotationError",
+}
+
+// IsInvalidAnnotation asserts invalidAnnotationError.
+func IsInvalidAnnotation(err error) bool {
+	return microerror.Cause(err) == invalidAnnotationError
+}
+
+var invalidSpecError = &microerror.Error{
+	Kind: "invalidSpecError",
+}
+
+// IsInvalidSpec asserts invalidSpecError.
+func IsInvalidSpec(err error) bool {
+	return microerror.Cause(err) == invalidSpecError
+}
+
 var wrongTypeError = &microerror.Error{
 	Kind: "wrongTypeError",
 }
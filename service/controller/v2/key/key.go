@@ -1,22 +1,465 @@
 package key
 
 import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/microerror"
 )
 
+const (
+	// ApplicationProtocolsAnnotation is the annotation of IngressConfigs
+	// hinting the application protocol spoken on LB ports, e.g.
+	// "31000=grpc,31001=h2". The host cluster ingress controller forwards the
+	// connections of these LB ports at layer 4, so the hints only tune how
+	// connections are held, e.g. keeping idle gRPC streams open.
+	ApplicationProtocolsAnnotation = "ingress-operator.giantswarm.io/application-protocols"
+	// ApplicationProtocolGRPC is the application protocol hint of LB ports
+	// carrying gRPC.
+	ApplicationProtocolGRPC = "grpc"
+	// ApplicationProtocolH2 is the application protocol hint of LB ports
+	// carrying HTTP/2 other than gRPC.
+	ApplicationProtocolH2 = "h2"
+	// CanaryAnnotation is the annotation of IngressConfigs opting their guest
+	// cluster into the canary behaviour of the operator while set to "true",
+	// so that behaviour changes can be rolled out to a few guest clusters
+	// before the whole fleet.
+	CanaryAnnotation = "ingress-operator.giantswarm.io/canary"
+	// DefaultClusterService is the guest cluster service ingress traffic is
+	// forwarded to in case the IngressConfig does not define one.
+	DefaultClusterService = "worker"
+	// DeleteProtectionAnnotation is the annotation of IngressConfigs blocking
+	// their deletion while set to "true". The deletion of a protected
+	// IngressConfig only proceeds once the annotation got removed.
+	DeleteProtectionAnnotation = "ingress-operator.giantswarm.io/delete-protection"
+	// DisabledProtocolsAnnotation is the annotation of IngressConfigs listing
+	// the protocols which must not be served for their guest cluster, e.g.
+	// "http" for tenants bound to compliance rules forbidding plain HTTP. The
+	// LB ports of disabled protocols stay allocated to the guest cluster, but
+	// their config map entries and service ports are removed.
+	DisabledProtocolsAnnotation = "ingress-operator.giantswarm.io/disabled-protocols"
+	// ExternalPortsAnnotation is the annotation of IngressConfigs listing LB
+	// ports of their guest cluster which are managed by an external system,
+	// e.g. "31500,31501". External ports are recorded in the ledger, so that
+	// they are never allocated to another guest cluster and count towards the
+	// utilization of the port pool, but the operator never writes or deletes
+	// their config map entries and service ports.
+	ExternalPortsAnnotation = "ingress-operator.giantswarm.io/external-ports"
+	// IdleTimeoutAnnotation is the annotation of IngressConfigs tuning the
+	// idle timeout of connections per LB port, e.g. "31000=10m,31001=1h".
+	IdleTimeoutAnnotation = "ingress-operator.giantswarm.io/idle-timeout"
+	// IngressPortSelectorAnnotation is the annotation of IngressConfigs enabling
+	// the discovery of ingress ports. Its value is the label selector of the
+	// Service of the guest cluster ingress controller in the guest cluster
+	// namespace, e.g. "app=nginx-ingress-controller". The NodePorts of the
+	// Service ports named like the protocols of the IngressConfig are used
+	// instead of the ingress ports of the spec.
+	IngressPortSelectorAnnotation = "ingress-operator.giantswarm.io/ingress-port-selector"
+	// MaxConnectionsAnnotation is the annotation of IngressConfigs limiting the
+	// number of concurrent connections per LB port, e.g. "31000=1000".
+	MaxConnectionsAnnotation = "ingress-operator.giantswarm.io/max-connections"
+	// NamedPortsAnnotation is the annotation of IngressConfigs listing the LB
+	// ports whose config map data values reference the port of the guest
+	// cluster service by name instead of by number, e.g. "31001". The host
+	// cluster ingress controller then resolves the port itself, e.g.
+	// "namespace/service:https", so that renumbered service ports are followed
+	// without reconciliation. The names are resolved from the guest cluster
+	// service before writing.
+	NamedPortsAnnotation = "ingress-operator.giantswarm.io/named-ports"
+	// NodePortsAnnotation is the annotation of IngressConfigs the operator
+	// records the node ports in, which Kubernetes assigned to their LB ports,
+	// e.g. "31000=30518,31001=32107". It is only set in case the service of the
+	// ingress controller is of type LoadBalancer.
+	NodePortsAnnotation = "ingress-operator.giantswarm.io/node-ports"
+	// PinnedPortsAnnotation is the annotation of IngressConfigs listing the LB
+	// ports which are pinned to the guest cluster, e.g. "31000,31001". Pinned
+	// ports are exempt from any reallocation and are never handed over to
+	// another guest cluster.
+	PinnedPortsAnnotation = "ingress-operator.giantswarm.io/pinned-ports"
+	// ProtocolHTTPS is the normalized protocol of protocol ports carrying TLS.
+	ProtocolHTTPS = "https"
+	// SourceRangesAnnotation is the annotation of IngressConfigs restricting
+	// the clients allowed to connect to LB ports to the given CIDRs, separated
+	// by spaces, e.g. "31000=10.0.0.0/8 192.168.0.0/16,31001=203.0.113.7/32".
+	// Connections of other clients are refused by the ingress controller.
+	SourceRangesAnnotation = "ingress-operator.giantswarm.io/source-ranges"
+	// TLSPassthroughAnnotation is the annotation of IngressConfigs listing the
+	// LB ports of https protocol ports passing TLS through to the guest
+	// cluster ingress controller, e.g. "31001". Their connections are handed
+	// over with the PROXY protocol, since the guest cluster ingress controller
+	// cannot learn the client address from the encrypted traffic otherwise.
+	TLSPassthroughAnnotation = "ingress-operator.giantswarm.io/tls-passthrough"
+)
+
+// maxPort is the highest valid TCP port.
+const maxPort = 65535
+
+// PortSettings are the optional connection settings of a single LB port. Zero
+// values leave the defaults of the ingress controller in place.
+type PortSettings struct {
+	// ApplicationProtocol is either ApplicationProtocolGRPC or
+	// ApplicationProtocolH2.
+	ApplicationProtocol string
+	IdleTimeout         time.Duration
+	MaxConnections      int
+	// SourceRanges are the CIDRs of the clients allowed to connect, in their
+	// canonical form. All clients are allowed in case it is empty.
+	SourceRanges []string
+}
+
+// IsMultiplexed returns whether the LB port carries HTTP/2, whose connections
+// multiplex long lived streams and must not be cut by short idle timeouts.
+func (s PortSettings) IsMultiplexed() bool {
+	return s.ApplicationProtocol == ApplicationProtocolGRPC || s.ApplicationProtocol == ApplicationProtocolH2
+}
+
+// CertificateSecretName returns the name of the Secret cert-operator issues
+// the certificate of the given cluster component of the guest cluster of the
+// given custom object into, e.g. "al9qy-ingress".
+func CertificateSecretName(customObject v1alpha1.IngressConfig, component string) string {
+	return fmt.Sprintf("%s-%s", ClusterID(customObject), component)
+}
+
 func ClusterID(customObject v1alpha1.IngressConfig) string {
 	return customObject.Spec.GuestCluster.ID
 }
 
+// ClusterNamespace returns the namespace of the guest cluster in the host
+// cluster. Guest cluster namespaces are named after the cluster ID, which is
+// used in case the IngressConfig does not define one.
 func ClusterNamespace(customObject v1alpha1.IngressConfig) string {
+	if customObject.Spec.GuestCluster.Namespace == "" {
+		return ClusterID(customObject)
+	}
+
 	return customObject.Spec.GuestCluster.Namespace
 }
 
+// ClusterService returns the service in the guest cluster namespace ingress
+// traffic is forwarded to. DefaultClusterService is used in case the
+// IngressConfig does not define one.
+func ClusterService(customObject v1alpha1.IngressConfig) string {
+	if customObject.Spec.GuestCluster.Service == "" {
+		return DefaultClusterService
+	}
+
+	return customObject.Spec.GuestCluster.Service
+}
+
+// DedicatedIngressControllerName returns the name used for the objects of the
+// dedicated ingress controller of the given guest cluster. The name is used
+// for the Deployment, the Service and the main config map of the dedicated
+// ingress controller.
+func DedicatedIngressControllerName(customObject v1alpha1.IngressConfig) string {
+	return fmt.Sprintf("nginx-ingress-controller-%s", ClusterID(customObject))
+}
+
+// DedicatedIngressControllerTCPServicesName returns the name of the config map
+// holding the TCP services of the dedicated ingress controller of the given
+// guest cluster.
+func DedicatedIngressControllerTCPServicesName(customObject v1alpha1.IngressConfig) string {
+	return fmt.Sprintf("%s-tcp-services", DedicatedIngressControllerName(customObject))
+}
+
+// DedicatedIngressControllerStreamSnippetsName returns the name of the config
+// map holding the stream snippets of the dedicated ingress controller of the
+// given guest cluster.
+func DedicatedIngressControllerStreamSnippetsName(customObject v1alpha1.IngressConfig) string {
+	return fmt.Sprintf("%s-stream-snippets", DedicatedIngressControllerName(customObject))
+}
+
+// ExternalPorts returns the LB ports of the guest cluster of the given custom
+// object which are managed by an external system.
+func ExternalPorts(customObject v1alpha1.IngressConfig) ([]int, error) {
+	ports, err := portList(customObject, ExternalPortsAnnotation)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return ports, nil
+}
+
+func IngressControllerConfigMap(customObject v1alpha1.IngressConfig) string {
+	return customObject.Spec.HostCluster.IngressController.ConfigMap
+}
+
+// IngressControllerID returns the identity of the host cluster ingress
+// controller referenced by the given custom object, e.g.
+// "kube-system/ingress-controller".
+func IngressControllerID(customObject v1alpha1.IngressConfig) string {
+	return fmt.Sprintf("%s/%s", IngressControllerNamespace(customObject), IngressControllerService(customObject))
+}
+
+func IngressControllerNamespace(customObject v1alpha1.IngressConfig) string {
+	return customObject.Spec.HostCluster.IngressController.Namespace
+}
+
+func IngressControllerService(customObject v1alpha1.IngressConfig) string {
+	return customObject.Spec.HostCluster.IngressController.Service
+}
+
+// IngressPortSelector returns the label selector used to discover the ingress
+// ports of the given custom object. It is empty in case discovery is disabled.
+func IngressPortSelector(customObject v1alpha1.IngressConfig) string {
+	return strings.TrimSpace(customObject.GetAnnotations()[IngressPortSelectorAnnotation])
+}
+
 func IsDeleted(customObject v1alpha1.IngressConfig) bool {
 	return customObject.GetDeletionTimestamp() != nil
 }
 
+// LBPorts returns the LB ports requested by the given custom object.
+func LBPorts(customObject v1alpha1.IngressConfig) []int {
+	var ports []int
+	for _, p := range customObject.Spec.ProtocolPorts {
+		ports = append(ports, p.LBPort)
+	}
+
+	return ports
+}
+
+// DisabledProtocols returns the normalized protocols listed by the disabled
+// protocols annotation of the given custom object.
+func DisabledProtocols(customObject v1alpha1.IngressConfig) []string {
+	v := customObject.GetAnnotations()[DisabledProtocolsAnnotation]
+
+	var protocols []string
+	for _, s := range strings.Split(v, ",") {
+		p := Protocol(v1alpha1.IngressConfigSpecProtocolPort{Protocol: s})
+		if p != "" {
+			protocols = append(protocols, p)
+		}
+	}
+
+	return protocols
+}
+
+// DisabledProtocolPorts returns the protocol ports of the given custom object
+// whose protocol is disabled.
+func DisabledProtocolPorts(customObject v1alpha1.IngressConfig) []v1alpha1.IngressConfigSpecProtocolPort {
+	var protocolPorts []v1alpha1.IngressConfigSpecProtocolPort
+	for _, p := range ProtocolPorts(customObject) {
+		if isDisabled(customObject, p) {
+			protocolPorts = append(protocolPorts, p)
+		}
+	}
+
+	return protocolPorts
+}
+
+// EnabledProtocolPorts returns the protocol ports of the given custom object
+// whose protocol is not disabled, which are the ones served by the host
+// cluster ingress controller.
+func EnabledProtocolPorts(customObject v1alpha1.IngressConfig) []v1alpha1.IngressConfigSpecProtocolPort {
+	var protocolPorts []v1alpha1.IngressConfigSpecProtocolPort
+	for _, p := range ProtocolPorts(customObject) {
+		if !isDisabled(customObject, p) {
+			protocolPorts = append(protocolPorts, p)
+		}
+	}
+
+	return protocolPorts
+}
+
+// IsCanary returns whether the given custom object opted into the canary
+// behaviour by the canary annotation.
+func IsCanary(customObject v1alpha1.IngressConfig) bool {
+	return customObject.GetAnnotations()[CanaryAnnotation] == "true"
+}
+
+// IsDeleteProtected returns whether the deletion of the given custom object is
+// blocked by the delete protection annotation.
+func IsDeleteProtected(customObject v1alpha1.IngressConfig) bool {
+	return customObject.GetAnnotations()[DeleteProtectionAnnotation] == "true"
+}
+
+// NamedPorts returns the LB ports of the given custom object whose config
+// map data values reference the port of the guest cluster service by name.
+func NamedPorts(customObject v1alpha1.IngressConfig) ([]int, error) {
+	ports, err := portList(customObject, NamedPortsAnnotation)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return ports, nil
+}
+
+// NodePortsValue returns the value of the node ports annotation for the given
+// node ports by LB port. It is empty in case there are no node ports.
+func NodePortsValue(nodePorts map[int]int) string {
+	var lbPorts []int
+	for p := range nodePorts {
+		lbPorts = append(lbPorts, p)
+	}
+	sort.Ints(lbPorts)
+
+	var pairs []string
+	for _, p := range lbPorts {
+		pairs = append(pairs, fmt.Sprintf("%d=%d", p, nodePorts[p]))
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// PinnedPorts returns the LB ports pinned to the guest cluster of the given
+// custom object.
+func PinnedPorts(customObject v1alpha1.IngressConfig) ([]int, error) {
+	ports, err := portList(customObject, PinnedPortsAnnotation)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return ports, nil
+}
+
+// PortSettingsByLBPort returns the connection settings of the given custom
+// object by LB port. Only LB ports having settings are part of the result.
+func PortSettingsByLBPort(customObject v1alpha1.IngressConfig) (map[int]PortSettings, error) {
+	settings := map[int]PortSettings{}
+
+	protocols, err := portValues(customObject, ApplicationProtocolsAnnotation)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	for p, v := range protocols {
+		v = strings.ToLower(v)
+		if v != ApplicationProtocolGRPC && v != ApplicationProtocolH2 {
+			return nil, microerror.Maskf(invalidAnnotationError, "%s must map ports to %s or %s, got %q for port %d", ApplicationProtocolsAnnotation, ApplicationProtocolGRPC, ApplicationProtocolH2, v, p)
+		}
+		s := settings[p]
+		s.ApplicationProtocol = v
+		settings[p] = s
+	}
+
+	timeouts, err := portValues(customObject, IdleTimeoutAnnotation)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	for p, v := range timeouts {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, microerror.Maskf(invalidAnnotationError, "%s must map ports to positive durations, got %q for port %d", IdleTimeoutAnnotation, v, p)
+		}
+		s := settings[p]
+		s.IdleTimeout = d
+		settings[p] = s
+	}
+
+	limits, err := portValues(customObject, MaxConnectionsAnnotation)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	for p, v := range limits {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, microerror.Maskf(invalidAnnotationError, "%s must map ports to positive numbers, got %q for port %d", MaxConnectionsAnnotation, v, p)
+		}
+		s := settings[p]
+		s.MaxConnections = n
+		settings[p] = s
+	}
+
+	ranges, err := portValues(customObject, SourceRangesAnnotation)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	for p, v := range ranges {
+		cidrs := strings.Fields(v)
+		if len(cidrs) == 0 {
+			return nil, microerror.Maskf(invalidAnnotationError, "%s must map ports to space separated CIDRs, got %q for port %d", SourceRangesAnnotation, v, p)
+		}
+		s := settings[p]
+		for _, c := range cidrs {
+			_, n, err := net.ParseCIDR(c)
+			if err != nil {
+				return nil, microerror.Maskf(invalidAnnotationError, "%s must map ports to space separated CIDRs, got %q for port %d", SourceRangesAnnotation, c, p)
+			}
+			s.SourceRanges = append(s.SourceRanges, n.String())
+		}
+		settings[p] = s
+	}
+
+	return settings, nil
+}
+
+// ServiceSourceRanges returns the CIDRs of the clients allowed to connect to
+// the service of the dedicated ingress controller of the given custom object,
+// sorted. The source ranges of a service apply to all of its ports, so they
+// are only returned in case every LB port of the custom object restricts its
+// clients. They are the union of the source ranges of all LB ports then,
+// while the ingress controller still enforces the ones of each LB port.
+func ServiceSourceRanges(customObject v1alpha1.IngressConfig) ([]string, error) {
+	settings, err := PortSettingsByLBPort(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	lbPorts := LBPorts(customObject)
+	if len(lbPorts) == 0 {
+		return nil, nil
+	}
+
+	union := map[string]bool{}
+	for _, p := range lbPorts {
+		if len(settings[p].SourceRanges) == 0 {
+			return nil, nil
+		}
+		for _, c := range settings[p].SourceRanges {
+			union[c] = true
+		}
+	}
+
+	var ranges []string
+	for c := range union {
+		ranges = append(ranges, c)
+	}
+	sort.Strings(ranges)
+
+	return ranges, nil
+}
+
+// PropagatedAnnotations returns the annotations of the given custom object
+// having one of the given keys. These annotations are propagated to the host
+// cluster objects managed for the guest cluster.
+func PropagatedAnnotations(customObject v1alpha1.IngressConfig, keys []string) map[string]string {
+	return selectKeys(customObject.GetAnnotations(), keys)
+}
+
+// PropagatedLabels returns the labels of the given custom object having one
+// of the given keys. These labels are propagated to the host cluster objects
+// managed for the guest cluster.
+func PropagatedLabels(customObject v1alpha1.IngressConfig, keys []string) map[string]string {
+	return selectKeys(customObject.GetLabels(), keys)
+}
+
+// ProtocolPorts returns the protocol ports of the given custom object.
+func ProtocolPorts(customObject v1alpha1.IngressConfig) []v1alpha1.IngressConfigSpecProtocolPort {
+	return customObject.Spec.ProtocolPorts
+}
+
+// Protocol returns the normalized protocol of the given protocol port.
+// Protocols are case insensitive, so that e.g. HTTP and http result in the same
+// service port names and ledger entries.
+func Protocol(p v1alpha1.IngressConfigSpecProtocolPort) string {
+	return strings.ToLower(strings.TrimSpace(p.Protocol))
+}
+
+// TLSPassthroughPorts returns the LB ports of the given custom object passing
+// TLS through to the guest cluster ingress controller.
+func TLSPassthroughPorts(customObject v1alpha1.IngressConfig) ([]int, error) {
+	ports, err := portList(customObject, TLSPassthroughAnnotation)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return ports, nil
+}
+
 func ToCustomObject(v interface{}) (v1alpha1.IngressConfig, error) {
 	customObjectPointer, ok := v.(*v1alpha1.IngressConfig)
 	if !ok {
@@ -27,6 +470,105 @@ func ToCustomObject(v interface{}) (v1alpha1.IngressConfig, error) {
 	return customObject, nil
 }
 
+// ValidateSpec returns an error in case any field of the spec of the given
+// custom object lacks a value the operator cannot default, or any port is out
+// of range.
+func ValidateSpec(customObject v1alpha1.IngressConfig) error {
+	if ClusterID(customObject) == "" {
+		return microerror.Maskf(invalidSpecError, "spec.guestCluster.id must not be empty")
+	}
+	if IngressControllerConfigMap(customObject) == "" {
+		return microerror.Maskf(invalidSpecError, "spec.hostCluster.ingressController.configMap must not be empty")
+	}
+	if IngressControllerNamespace(customObject) == "" {
+		return microerror.Maskf(invalidSpecError, "spec.hostCluster.ingressController.namespace must not be empty")
+	}
+	if IngressControllerService(customObject) == "" {
+		return microerror.Maskf(invalidSpecError, "spec.hostCluster.ingressController.service must not be empty")
+	}
+
+	for _, p := range ProtocolPorts(customObject) {
+		if p.LBPort < 1 || p.LBPort > maxPort {
+			return microerror.Maskf(invalidSpecError, "LB port %d of protocol %q must be between 1 and %d", p.LBPort, p.Protocol, maxPort)
+		}
+		// Ingress ports may be zero in case they are discovered.
+		if p.IngressPort < 0 || p.IngressPort > maxPort {
+			return microerror.Maskf(invalidSpecError, "ingress port %d of LB port %d must be between 0 and %d", p.IngressPort, p.LBPort, maxPort)
+		}
+	}
+
+	return nil
+}
+
 func VersionBundleVersion(customObject v1alpha1.IngressConfig) string {
 	return customObject.Spec.VersionBundle.Version
 }
+
+func isDisabled(customObject v1alpha1.IngressConfig, p v1alpha1.IngressConfigSpecProtocolPort) bool {
+	for _, d := range DisabledProtocols(customObject) {
+		if d == Protocol(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// portList parses the given annotation of the given custom object as comma
+// separated list of ports, e.g. "31000,31001".
+func portList(customObject v1alpha1.IngressConfig, annotation string) ([]int, error) {
+	v, ok := customObject.GetAnnotations()[annotation]
+	if !ok || strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+
+	var ports []int
+	for _, s := range strings.Split(v, ",") {
+		p, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, microerror.Maskf(invalidAnnotationError, "%s must be a comma separated list of ports, got %q", annotation, v)
+		}
+		ports = append(ports, p)
+	}
+
+	return ports, nil
+}
+
+// portValues parses the given annotation of the given custom object as comma
+// separated list of port=value pairs, e.g. "31000=10m,31001=1h".
+func portValues(customObject v1alpha1.IngressConfig, annotation string) (map[int]string, error) {
+	v, ok := customObject.GetAnnotations()[annotation]
+	if !ok || strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+
+	values := map[int]string{}
+	for _, s := range strings.Split(v, ",") {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 {
+			return nil, microerror.Maskf(invalidAnnotationError, "%s must be a comma separated list of port=value pairs, got %q", annotation, v)
+		}
+		p, err := strconv.Atoi(strings.TrimSpace(kv[0]))
+		if err != nil {
+			return nil, microerror.Maskf(invalidAnnotationError, "%s must be a comma separated list of port=value pairs, got %q", annotation, v)
+		}
+		if _, ok := values[p]; ok {
+			return nil, microerror.Maskf(invalidAnnotationError, "%s must not list port %d twice", annotation, p)
+		}
+		values[p] = strings.TrimSpace(kv[1])
+	}
+
+	return values, nil
+}
+
+func selectKeys(m map[string]string, keys []string) map[string]string {
+	selected := map[string]string{}
+	for _, k := range keys {
+		v, ok := m[k]
+		if ok {
+			selected[k] = v
+		}
+	}
+
+	return selected
+}
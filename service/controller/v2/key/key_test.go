@@ -0,0 +1,332 @@
+package key
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestCustomObject() v1alpha1.IngressConfig {
+	return v1alpha1.IngressConfig{
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID: "al9qy",
+			},
+			HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+				IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+					ConfigMap: "ingress-controller",
+					Namespace: "kube-system",
+					Service:   "ingress-controller",
+				},
+			},
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+				{IngressPort: 30011, LBPort: 31001, Protocol: " HTTPS"},
+			},
+		},
+	}
+}
+
+func Test_Key_Defaults(t *testing.T) {
+	customObject := newTestCustomObject()
+
+	if ClusterNamespace(customObject) != "al9qy" {
+		t.Fatal("expected", "al9qy", "got", ClusterNamespace(customObject))
+	}
+	if ClusterService(customObject) != DefaultClusterService {
+		t.Fatal("expected", DefaultClusterService, "got", ClusterService(customObject))
+	}
+
+	customObject.Spec.GuestCluster.Namespace = "guest"
+	customObject.Spec.GuestCluster.Service = "ingress"
+
+	if ClusterNamespace(customObject) != "guest" {
+		t.Fatal("expected", "guest", "got", ClusterNamespace(customObject))
+	}
+	if ClusterService(customObject) != "ingress" {
+		t.Fatal("expected", "ingress", "got", ClusterService(customObject))
+	}
+}
+
+func Test_Key_Accessors(t *testing.T) {
+	customObject := newTestCustomObject()
+
+	if IngressControllerID(customObject) != "kube-system/ingress-controller" {
+		t.Fatal("expected", "kube-system/ingress-controller", "got", IngressControllerID(customObject))
+	}
+	if !reflect.DeepEqual(LBPorts(customObject), []int{31000, 31001}) {
+		t.Fatal("expected", []int{31000, 31001}, "got", LBPorts(customObject))
+	}
+	if Protocol(ProtocolPorts(customObject)[1]) != ProtocolHTTPS {
+		t.Fatal("expected", ProtocolHTTPS, "got", Protocol(ProtocolPorts(customObject)[1]))
+	}
+	if CertificateSecretName(customObject, "ingress") != "al9qy-ingress" {
+		t.Fatal("expected", "al9qy-ingress", "got", CertificateSecretName(customObject, "ingress"))
+	}
+	if NodePortsValue(map[int]int{31001: 32107, 31000: 30518}) != "31000=30518,31001=32107" {
+		t.Fatal("expected", "31000=30518,31001=32107", "got", NodePortsValue(map[int]int{31001: 32107, 31000: 30518}))
+	}
+}
+
+func Test_Key_DisabledProtocols(t *testing.T) {
+	customObject := newTestCustomObject()
+
+	if len(DisabledProtocolPorts(customObject)) != 0 {
+		t.Fatal("expected", 0, "got", len(DisabledProtocolPorts(customObject)))
+	}
+	if len(EnabledProtocolPorts(customObject)) != 2 {
+		t.Fatal("expected", 2, "got", len(EnabledProtocolPorts(customObject)))
+	}
+
+	customObject.Annotations = map[string]string{
+		DisabledProtocolsAnnotation: " HTTP, ,udp",
+	}
+
+	if !reflect.DeepEqual(DisabledProtocols(customObject), []string{"http", "udp"}) {
+		t.Fatal("expected", []string{"http", "udp"}, "got", DisabledProtocols(customObject))
+	}
+	if !reflect.DeepEqual(DisabledProtocolPorts(customObject), ProtocolPorts(customObject)[:1]) {
+		t.Fatal("expected", ProtocolPorts(customObject)[:1], "got", DisabledProtocolPorts(customObject))
+	}
+	if !reflect.DeepEqual(EnabledProtocolPorts(customObject), ProtocolPorts(customObject)[1:]) {
+		t.Fatal("expected", ProtocolPorts(customObject)[1:], "got", EnabledProtocolPorts(customObject))
+	}
+}
+
+func Test_Key_ToCustomObject(t *testing.T) {
+	customObject := newTestCustomObject()
+
+	converted, err := ToCustomObject(&customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reflect.DeepEqual(converted, customObject) {
+		t.Fatal("expected", customObject, "got", converted)
+	}
+
+	_, err = ToCustomObject(customObject)
+	if !IsWrongTypeError(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Key_ValidateSpec(t *testing.T) {
+	testCases := []struct {
+		Modify       func(customObject *v1alpha1.IngressConfig)
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures a complete spec is valid.
+		{
+			Modify:       func(customObject *v1alpha1.IngressConfig) {},
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures a missing cluster ID is rejected.
+		{
+			Modify:       func(customObject *v1alpha1.IngressConfig) { customObject.Spec.GuestCluster.ID = "" },
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 2 ensures a missing ingress controller config map is rejected.
+		{
+			Modify: func(customObject *v1alpha1.IngressConfig) {
+				customObject.Spec.HostCluster.IngressController.ConfigMap = ""
+			},
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 3 ensures a missing ingress controller service is rejected.
+		{
+			Modify: func(customObject *v1alpha1.IngressConfig) {
+				customObject.Spec.HostCluster.IngressController.Service = ""
+			},
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 4 ensures LB ports out of range are rejected.
+		{
+			Modify:       func(customObject *v1alpha1.IngressConfig) { customObject.Spec.ProtocolPorts[0].LBPort = 0 },
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 5 ensures missing ingress ports are valid, since they may be
+		// discovered.
+		{
+			Modify:       func(customObject *v1alpha1.IngressConfig) { customObject.Spec.ProtocolPorts[0].IngressPort = 0 },
+			ErrorMatcher: nil,
+		},
+
+		// Test 6 ensures ingress ports out of range are rejected.
+		{
+			Modify:       func(customObject *v1alpha1.IngressConfig) { customObject.Spec.ProtocolPorts[0].IngressPort = 65536 },
+			ErrorMatcher: IsInvalidSpec,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject := newTestCustomObject()
+		tc.Modify(&customObject)
+
+		err := ValidateSpec(customObject)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+	}
+}
+
+func Test_Key_PortSettingsByLBPort(t *testing.T) {
+	testCases := []struct {
+		Annotations      map[string]string
+		ExpectedSettings map[int]PortSettings
+		ErrorMatcher     func(error) bool
+	}{
+		// Test 0 ensures no settings are returned without annotations.
+		{
+			Annotations:      nil,
+			ExpectedSettings: map[int]PortSettings{},
+			ErrorMatcher:     nil,
+		},
+
+		// Test 1 ensures settings of both annotations are merged by LB port.
+		{
+			Annotations: map[string]string{
+				IdleTimeoutAnnotation:    "31000=10m",
+				MaxConnectionsAnnotation: "31000=1000, 31001=50",
+			},
+			ExpectedSettings: map[int]PortSettings{
+				31000: {IdleTimeout: 10 * time.Minute, MaxConnections: 1000},
+				31001: {MaxConnections: 50},
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures ports listed twice are rejected.
+		{
+			Annotations: map[string]string{
+				MaxConnectionsAnnotation: "31000=1000,31000=50",
+			},
+			ErrorMatcher: IsInvalidAnnotation,
+		},
+
+		// Test 3 ensures non positive values are rejected.
+		{
+			Annotations: map[string]string{
+				IdleTimeoutAnnotation: "31000=-1m",
+			},
+			ErrorMatcher: IsInvalidAnnotation,
+		},
+
+		// Test 4 ensures application protocols are merged with other settings in
+		// any case.
+		{
+			Annotations: map[string]string{
+				ApplicationProtocolsAnnotation: "31000=gRPC,31001=h2",
+				IdleTimeoutAnnotation:          "31000=10m",
+			},
+			ExpectedSettings: map[int]PortSettings{
+				31000: {ApplicationProtocol: ApplicationProtocolGRPC, IdleTimeout: 10 * time.Minute},
+				31001: {ApplicationProtocol: ApplicationProtocolH2},
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 5 ensures unknown application protocols are rejected.
+		{
+			Annotations: map[string]string{
+				ApplicationProtocolsAnnotation: "31000=websocket",
+			},
+			ErrorMatcher: IsInvalidAnnotation,
+		},
+
+		// Test 6 ensures source ranges are separated by spaces and returned in
+		// their canonical form.
+		{
+			Annotations: map[string]string{
+				SourceRangesAnnotation: "31000=10.1.2.3/8  192.168.0.0/16,31001=2001:db8::1/32",
+			},
+			ExpectedSettings: map[int]PortSettings{
+				31000: {SourceRanges: []string{"10.0.0.0/8", "192.168.0.0/16"}},
+				31001: {SourceRanges: []string{"2001:db8::/32"}},
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 7 ensures source ranges must be CIDRs.
+		{
+			Annotations: map[string]string{
+				SourceRangesAnnotation: "31000=10.0.0.1",
+			},
+			ErrorMatcher: IsInvalidAnnotation,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject := newTestCustomObject()
+		customObject.ObjectMeta = metav1.ObjectMeta{Annotations: tc.Annotations}
+
+		settings, err := PortSettingsByLBPort(customObject)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil {
+			if !tc.ErrorMatcher(err) {
+				t.Fatal("test", i, "expected", true, "got", false)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(settings, tc.ExpectedSettings) {
+			t.Fatal("test", i, "expected", tc.ExpectedSettings, "got", settings)
+		}
+	}
+}
+
+func Test_Key_ServiceSourceRanges(t *testing.T) {
+	testCases := []struct {
+		Annotations    map[string]string
+		ExpectedRanges []string
+	}{
+		// Test 0 ensures the service is not restricted without source ranges.
+		{
+			Annotations:    nil,
+			ExpectedRanges: nil,
+		},
+
+		// Test 1 ensures the service is not restricted in case any LB port is
+		// not.
+		{
+			Annotations: map[string]string{
+				SourceRangesAnnotation: "31000=10.0.0.0/8",
+			},
+			ExpectedRanges: nil,
+		},
+
+		// Test 2 ensures the service is restricted to the union of the source
+		// ranges of all LB ports in case all of them are restricted.
+		{
+			Annotations: map[string]string{
+				SourceRangesAnnotation: "31000=192.168.0.0/16 10.0.0.0/8,31001=10.0.0.0/8",
+			},
+			ExpectedRanges: []string{"10.0.0.0/8", "192.168.0.0/16"},
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject := newTestCustomObject()
+		customObject.ObjectMeta = metav1.ObjectMeta{Annotations: tc.Annotations}
+
+		ranges, err := ServiceSourceRanges(customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(ranges, tc.ExpectedRanges) {
+			t.Fatal("test", i, "expected", tc.ExpectedRanges, "got", ranges)
+		}
+	}
+}
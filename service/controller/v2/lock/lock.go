@@ -0,0 +1,160 @@
+// Package lock serializes the writes of the resources managing the objects of
+// shared ingress controllers across all replicas of the operator. Wrapped
+// resources hold the Lease of the ingress controller an IngressConfig
+// references while they execute, so that only one reconciliation at a time
+// reads and writes its config map and service. The Lease is acquired by the
+// first wrapped resource and held until the last one executed, so that the
+// ordered writes of a reconciliation are not interleaved with the ones of
+// other reconciliations. Wrapped resources execute with a context canceled once
+// the Lease is lost.
+package lock
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// Locker acquires the Lease of the given name in the given namespace and
+// returns the function releasing it, together with a context canceled once the
+// Lease is lost. It is implemented by lease.Locker.
+type Locker interface {
+	Lock(ctx context.Context, namespace, name string) (context.Context, func(), error)
+}
+
+// LeaseName returns the name of the Lease of the ingress controller with the
+// given service. The Lease lives in the namespace of the ingress controller.
+func LeaseName(projectName, service string) string {
+	return fmt.Sprintf("%s-%s", projectName, service)
+}
+
+// Wrap wraps the given resources, which are executed in the given order, so
+// that they hold the Lease of the ingress controller of the reconciled
+// IngressConfig from the first until the last of them executed. The Lease is
+// released early in case a resource fails or cancels the reconciliation, since
+// the resources after it are not executed then. Failed resources acquire the
+// Lease again when they are retried.
+func Wrap(resources []controller.Resource, locker Locker, projectName string) []controller.Resource {
+	var wrapped []controller.Resource
+	for i, r := range resources {
+		w := &resourceWrapper{
+			last:        i == len(resources)-1,
+			locker:      locker,
+			projectName: projectName,
+			resource:    r,
+		}
+		wrapped = append(wrapped, w)
+	}
+
+	return wrapped
+}
+
+type resourceWrapper struct {
+	last        bool
+	locker      Locker
+	projectName string
+	resource    controller.Resource
+}
+
+func (w *resourceWrapper) EnsureCreated(ctx context.Context, obj interface{}) error {
+	err := w.execute(ctx, obj, w.resource.EnsureCreated)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (w *resourceWrapper) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	err := w.execute(ctx, obj, w.resource.EnsureDeleted)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (w *resourceWrapper) Name() string {
+	return w.resource.Name()
+}
+
+// Wrapped implements the wrapper interface of operatorkit, so that wrapping
+// resources use the name of the wrapped resource.
+func (w *resourceWrapper) Wrapped() controller.Resource {
+	return w.resource
+}
+
+// execute executes the given function of the wrapped resource holding the
+// Lease, which is acquired in case no resource before holds it already.
+func (w *resourceWrapper) execute(ctx context.Context, obj interface{}, f func(ctx context.Context, obj interface{}) error) (err error) {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if cc.Lease == nil {
+		cc.Lease, err = w.lock(ctx, obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	lease := cc.Lease
+	defer func() {
+		if w.last || err != nil || reconciliationcanceledcontext.IsCanceled(ctx) {
+			cc.Lease = nil
+			lease.Release()
+		}
+	}()
+
+	leaseCtx, cancel := withLease(ctx, lease.Context)
+	defer cancel()
+
+	err = f(leaseCtx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (w *resourceWrapper) lock(ctx context.Context, obj interface{}) (*controllercontext.Lease, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	leaseCtx, release, err := w.locker.Lock(ctx, key.IngressControllerNamespace(customObject), LeaseName(w.projectName, key.IngressControllerService(customObject)))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	lease := &controllercontext.Lease{
+		Context: leaseCtx,
+		Release: release,
+	}
+
+	return lease, nil
+}
+
+// withLease returns a context derived from the given one, which is canceled
+// as well once the context of the Lease is canceled.
+func withLease(ctx, leaseCtx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		select {
+		case <-leaseCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
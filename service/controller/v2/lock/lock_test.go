@@ -0,0 +1,170 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/operatorkit/controller"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+)
+
+// testLocker records the acquisitions and releases of Leases.
+type testLocker struct {
+	cancel     context.CancelFunc
+	operations *[]string
+}
+
+func (l *testLocker) Lock(ctx context.Context, namespace, name string) (context.Context, func(), error) {
+	*l.operations = append(*l.operations, "lock "+namespace+"/"+name)
+
+	leaseCtx, cancel := context.WithCancel(ctx)
+	l.cancel = cancel
+
+	release := func() {
+		*l.operations = append(*l.operations, "release "+namespace+"/"+name)
+		cancel()
+	}
+
+	return leaseCtx, release, nil
+}
+
+// testResource records its executions and fails in case it is configured to.
+type testResource struct {
+	execute    func(ctx context.Context) error
+	name       string
+	operations *[]string
+}
+
+func (r *testResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	*r.operations = append(*r.operations, "create "+r.name)
+	if r.execute != nil {
+		return r.execute(ctx)
+	}
+	return nil
+}
+
+func (r *testResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	*r.operations = append(*r.operations, "delete "+r.name)
+	return nil
+}
+
+func (r *testResource) Name() string {
+	return r.name
+}
+
+func newTestIngressConfig() *v1alpha1.IngressConfig {
+	customObject := v1alpha1.IngressConfig{}
+	customObject.Spec.HostCluster.IngressController.Namespace = "kube-system"
+	customObject.Spec.HostCluster.IngressController.Service = "ingress-controller"
+
+	return &customObject
+}
+
+func Test_Lock_Wrap(t *testing.T) {
+	testCases := []struct {
+		Fail               bool
+		ExpectedOperations []string
+	}{
+		// Test 0 ensures the Lease is held from the first until the last
+		// resource executed.
+		{
+			Fail: false,
+			ExpectedOperations: []string{
+				"lock kube-system/ingress-operator-ingress-controller",
+				"create service",
+				"create configmap",
+				"create cleanup",
+				"release kube-system/ingress-operator-ingress-controller",
+			},
+		},
+
+		// Test 1 ensures the Lease is released in case a resource fails, since
+		// the resources after it are not executed.
+		{
+			Fail: true,
+			ExpectedOperations: []string{
+				"lock kube-system/ingress-operator-ingress-controller",
+				"create service",
+				"create configmap",
+				"release kube-system/ingress-operator-ingress-controller",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		var operations []string
+		locker := &testLocker{operations: &operations}
+
+		configMap := &testResource{name: "configmap", operations: &operations}
+		if tc.Fail {
+			configMap.execute = func(ctx context.Context) error { return errors.New("test error") }
+		}
+
+		resources := Wrap([]controller.Resource{
+			&testResource{name: "service", operations: &operations},
+			configMap,
+			&testResource{name: "cleanup", operations: &operations},
+		}, locker, "ingress-operator")
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+
+		var err error
+		for _, r := range resources {
+			err = r.EnsureCreated(ctx, newTestIngressConfig())
+			if err != nil {
+				break
+			}
+		}
+		if (err != nil) != tc.Fail {
+			t.Fatal("test", i, "expected", tc.Fail, "got", err)
+		}
+
+		if !reflect.DeepEqual(operations, tc.ExpectedOperations) {
+			t.Fatal("test", i, "expected", tc.ExpectedOperations, "got", operations)
+		}
+
+		cc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if cc.Lease != nil {
+			t.Fatal("test", i, "expected", nil, "got", cc.Lease)
+		}
+	}
+}
+
+// Test_Lock_Wrap_lost ensures wrapped resources execute with a context
+// canceled once the Lease is lost.
+func Test_Lock_Wrap_lost(t *testing.T) {
+	var operations []string
+	locker := &testLocker{operations: &operations}
+
+	resource := &testResource{
+		execute: func(ctx context.Context) error {
+			locker.cancel()
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return nil
+			}
+		},
+		name:       "configmap",
+		operations: &operations,
+	}
+
+	resources := Wrap([]controller.Resource{resource}, locker, "ingress-operator")
+
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+
+	err := resources[0].EnsureCreated(ctx, newTestIngressConfig())
+	if err == nil {
+		t.Fatal("expected", "canceled context", "got", nil)
+	}
+}
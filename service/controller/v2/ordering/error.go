@@ -0,0 +1,12 @@
+package ordering
+
+import "github.com/giantswarm/microerror"
+
+var invalidOrderError = &microerror.Error{
+	Kind: "invalidOrderError",
+}
+
+// IsInvalidOrder asserts invalidOrderError.
+func IsInvalidOrder(err error) bool {
+	return microerror.Cause(err) == invalidOrderError
+}
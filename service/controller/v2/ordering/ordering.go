@@ -0,0 +1,142 @@
+// Package ordering orders the config map and service resources of the
+// resource set. The service port of an LB port has to exist before the config
+// map routes traffic to it, and the config map entry has to be removed before
+// the service port. Otherwise traffic is briefly misrouted, e.g. to the
+// ingress port of a guest cluster the port was handed over from. The resource
+// set executes its resources in the same order for creation and deletion, so
+// the service resource is split into a create only and a cleanup part
+// surrounding the config map resource. Service ports removed on creation, e.g.
+// the ones of disabled protocols, are removed by the cleanup part.
+package ordering
+
+import (
+	"context"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+)
+
+// Order is the order of the config map and service resources.
+type Order string
+
+const (
+	// ConfigMapFirst executes the config map resource before the service
+	// resource for both creation and deletion. Combined with the transaction
+	// coordinator of the service resource, failed service updates roll back
+	// the config map update.
+	ConfigMapFirst Order = "configmap-first"
+	// ServiceFirst creates service ports before config map entries and deletes
+	// config map entries before service ports, also when removing single ports
+	// on creation.
+	ServiceFirst Order = "service-first"
+)
+
+// Orders are all supported orders.
+var Orders = []Order{
+	ConfigMapFirst,
+	ServiceFirst,
+}
+
+// Parse returns the order of the given name.
+func Parse(name string) (Order, error) {
+	for _, o := range Orders {
+		if string(o) == name {
+			return o, nil
+		}
+	}
+
+	var names []string
+	for _, o := range Orders {
+		names = append(names, string(o))
+	}
+
+	return "", microerror.Maskf(invalidOrderError, "order must be one of %s, got %q", strings.Join(names, ", "), name)
+}
+
+// Resources returns the given config map and service resources arranged in
+// the given order.
+func Resources(order Order, configMap, service controller.Resource) ([]controller.Resource, error) {
+	switch order {
+	case ConfigMapFirst:
+		return []controller.Resource{configMap, service}, nil
+	case ServiceFirst:
+		return []controller.Resource{&createOnlyResource{resource: service}, configMap, &cleanupResource{resource: service}}, nil
+	}
+
+	return nil, microerror.Maskf(invalidOrderError, "unknown order %q", order)
+}
+
+// createOnlyResource executes the wrapped resource on creation only.
+type createOnlyResource struct {
+	resource controller.Resource
+}
+
+func (r *createOnlyResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	err := r.resource.EnsureCreated(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *createOnlyResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
+
+func (r *createOnlyResource) Name() string {
+	return r.resource.Name()
+}
+
+// Wrapped implements the wrapper interface of operatorkit, so that wrapping
+// resources use the name of the wrapped resource.
+func (r *createOnlyResource) Wrapped() controller.Resource {
+	return r.resource
+}
+
+// cleanupResource executes the wrapped resource on deletion, and on creation
+// in case it deferred removing service ports until the config map entries
+// routing to them got removed.
+type cleanupResource struct {
+	resource controller.Resource
+}
+
+func (r *cleanupResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !cc.ServicePortRemovalsDeferred {
+		return nil
+	}
+
+	err = r.resource.EnsureCreated(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *cleanupResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	err := r.resource.EnsureDeleted(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (r *cleanupResource) Name() string {
+	return r.resource.Name()
+}
+
+// Wrapped implements the wrapper interface of operatorkit, so that wrapping
+// resources use the name of the wrapped resource.
+func (r *cleanupResource) Wrapped() controller.Resource {
+	return r.resource
+}
@@ -0,0 +1,150 @@
+package ordering
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/operatorkit/controller"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+)
+
+// testResource records the operations executed on it. It defers removals of
+// service ports on creation if configured so.
+type testResource struct {
+	name       string
+	operations *[]string
+	defers     bool
+}
+
+func (r *testResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	*r.operations = append(*r.operations, "create "+r.name)
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if r.defers {
+		cc.ServicePortRemovalsDeferred = true
+	}
+
+	return nil
+}
+
+func (r *testResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	*r.operations = append(*r.operations, "delete "+r.name)
+	return nil
+}
+
+func (r *testResource) Name() string {
+	return r.name
+}
+
+func Test_Ordering_Resources(t *testing.T) {
+	testCases := []struct {
+		Order              string
+		Deferred           bool
+		ExpectedOperations []string
+		ErrorMatcher       func(error) bool
+	}{
+		// Test 0 ensures the service is created first and deleted last.
+		{
+			Order: "service-first",
+			ExpectedOperations: []string{
+				"create service",
+				"create configmap",
+				"delete configmap",
+				"delete service",
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures the config map is created and deleted first.
+		{
+			Order: "configmap-first",
+			ExpectedOperations: []string{
+				"create configmap",
+				"create service",
+				"delete configmap",
+				"delete service",
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures the service is created again after the config map in
+		// case it deferred removing service ports.
+		{
+			Order:    "service-first",
+			Deferred: true,
+			ExpectedOperations: []string{
+				"create service",
+				"create configmap",
+				"create service",
+				"delete configmap",
+				"delete service",
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 3 ensures the config map is created first, regardless of
+		// deferred removals of service ports.
+		{
+			Order:    "configmap-first",
+			Deferred: true,
+			ExpectedOperations: []string{
+				"create configmap",
+				"create service",
+				"delete configmap",
+				"delete service",
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 4 ensures unknown orders are rejected.
+		{
+			Order:              "random",
+			ExpectedOperations: nil,
+			ErrorMatcher:       IsInvalidOrder,
+		},
+	}
+
+	for i, tc := range testCases {
+		var operations []string
+		configMap := &testResource{name: "configmap", operations: &operations}
+		service := &testResource{name: "service", operations: &operations, defers: tc.Deferred}
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+
+		var resources []controller.Resource
+		order, err := Parse(tc.Order)
+		if err == nil {
+			resources, err = Resources(order, configMap, service)
+		}
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+
+		for _, r := range resources {
+			err := r.EnsureCreated(ctx, nil)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+		for _, r := range resources {
+			err := r.EnsureDeleted(ctx, nil)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			if r.Name() != "configmap" && r.Name() != "service" {
+				t.Fatal("test", i, "expected", "configmap or service", "got", r.Name())
+			}
+		}
+
+		if !reflect.DeepEqual(operations, tc.ExpectedOperations) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedOperations, operations)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package v2
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/ordering"
+)
+
+// Test_Ordering_disabledProtocols ensures the config map entries and the
+// service ports of disabled protocols are removed within a single
+// reconciliation for all orders, the config map entries always before the
+// service ports.
+func Test_Ordering_disabledProtocols(t *testing.T) {
+	fixture, err := decodeCrashFixture("testdata/crash/disabled.yaml")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	expectedData := map[string]string{
+		"31000": "al9qy/worker:30010",
+	}
+	expectedPorts := fixture.ServicePorts[:1]
+	expectedWrites := []string{
+		"patch configmaps",
+		"update services",
+	}
+
+	for _, o := range ordering.Orders {
+		h := newCrashHost(t, fixture)
+		h.order = o
+
+		err := h.Reconcile(t, fixture)
+		if err != nil {
+			t.Fatal("order", o, "expected", nil, "got", err)
+		}
+
+		var writes []string
+		for _, a := range h.k8sClient.Actions() {
+			switch a.GetVerb() {
+			case "get", "list", "watch":
+				continue
+			}
+			writes = append(writes, a.GetVerb()+" "+a.GetResource().Resource)
+		}
+		if !reflect.DeepEqual(writes, expectedWrites) {
+			t.Fatalf("order %s expected %#v got %#v", o, expectedWrites, writes)
+		}
+
+		data, ports := h.State(t, fixture)
+		if !reflect.DeepEqual(data, expectedData) {
+			t.Fatalf("order %s expected %#v got %#v", o, expectedData, data)
+		}
+		if !reflect.DeepEqual(ports, []apiv1.ServicePort(expectedPorts)) {
+			t.Fatalf("order %s expected %#v got %#v", o, expectedPorts, ports)
+		}
+	}
+}
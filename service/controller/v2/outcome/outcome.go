@@ -0,0 +1,109 @@
+// Package outcome observes the outcomes of reconciliation loops. operatorkit
+// executes the resources of a resource set one after another and stops at the
+// first error or canceled reconciliation, but does not expose the outcome of a
+// loop as a whole. Wrapping all resources of a resource set reveals it.
+package outcome
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+)
+
+// Outcome is the outcome of a reconciliation loop.
+type Outcome string
+
+const (
+	// Canceled is the outcome of loops canceled by a resource, e.g. because
+	// the IngressConfig got rejected.
+	Canceled Outcome = "canceled"
+	// Failed is the outcome of loops stopped by an error of a resource.
+	Failed Outcome = "failed"
+	// Succeeded is the outcome of loops having executed all resources.
+	Succeeded Outcome = "succeeded"
+)
+
+// Observer is notified about the outcomes of reconciliation loops.
+type Observer interface {
+	// Observe is called once per reconciliation loop of the given object with
+	// its outcome. The given error is the one stopping failed loops.
+	Observe(ctx context.Context, obj interface{}, o Outcome, err error)
+}
+
+// Wrap wraps the given resources, so that the given observers are notified
+// about the outcomes of the reconciliation loops. The resources are expected
+// to be the complete and ordered list of a resource set. Errors should already
+// be retried by the given resources, so that only errors stopping the loop are
+// observed.
+func Wrap(resources []controller.Resource, observers ...Observer) []controller.Resource {
+	var wrapped []controller.Resource
+	for i, r := range resources {
+		w := &resourceWrapper{
+			last:      i == len(resources)-1,
+			observers: observers,
+			resource:  r,
+		}
+
+		wrapped = append(wrapped, w)
+	}
+
+	return wrapped
+}
+
+// resourceWrapper notifies the observers in case the loop ends with the
+// wrapped resource.
+type resourceWrapper struct {
+	last      bool
+	observers []Observer
+	resource  controller.Resource
+}
+
+func (w *resourceWrapper) EnsureCreated(ctx context.Context, obj interface{}) error {
+	err := w.resource.EnsureCreated(ctx, obj)
+	w.observe(ctx, obj, err)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (w *resourceWrapper) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	err := w.resource.EnsureDeleted(ctx, obj)
+	w.observe(ctx, obj, err)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (w *resourceWrapper) Name() string {
+	return w.resource.Name()
+}
+
+// Wrapped implements the wrapper interface of operatorkit, so that wrapping
+// resources use the name of the wrapped resource.
+func (w *resourceWrapper) Wrapped() controller.Resource {
+	return w.resource
+}
+
+func (w *resourceWrapper) observe(ctx context.Context, obj interface{}, err error) {
+	var o Outcome
+	switch {
+	case err != nil:
+		o = Failed
+	case reconciliationcanceledcontext.IsCanceled(ctx):
+		o = Canceled
+	case w.last:
+		o = Succeeded
+	default:
+		return
+	}
+
+	for _, observer := range w.observers {
+		observer.Observe(ctx, obj, o, err)
+	}
+}
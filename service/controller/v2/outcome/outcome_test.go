@@ -0,0 +1,96 @@
+package outcome
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+)
+
+// testObserver records the observed outcomes.
+type testObserver struct {
+	outcomes []Outcome
+}
+
+func (o *testObserver) Observe(ctx context.Context, obj interface{}, outcome Outcome, err error) {
+	o.outcomes = append(o.outcomes, outcome)
+}
+
+// testResource fails or cancels the reconciliation in case configured to.
+type testResource struct {
+	cancel bool
+	fail   bool
+}
+
+func (r *testResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	if r.fail {
+		return microerror.New("test error")
+	}
+	if r.cancel {
+		reconciliationcanceledcontext.SetCanceled(ctx)
+	}
+
+	return nil
+}
+
+func (r *testResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return r.EnsureCreated(ctx, obj)
+}
+
+func (r *testResource) Name() string {
+	return "test"
+}
+
+func Test_Outcome_Wrap(t *testing.T) {
+	testCases := []struct {
+		Resources        []controller.Resource
+		ExpectedOutcomes []Outcome
+	}{
+		// Test 0 ensures loops executing all resources succeed.
+		{
+			Resources:        []controller.Resource{&testResource{}, &testResource{}},
+			ExpectedOutcomes: []Outcome{Succeeded, Succeeded},
+		},
+
+		// Test 1 ensures loops stopped by an error fail.
+		{
+			Resources:        []controller.Resource{&testResource{}, &testResource{fail: true}, &testResource{}},
+			ExpectedOutcomes: []Outcome{Failed, Failed},
+		},
+
+		// Test 2 ensures loops canceled by a resource are canceled.
+		{
+			Resources:        []controller.Resource{&testResource{cancel: true}, &testResource{}},
+			ExpectedOutcomes: []Outcome{Canceled, Canceled},
+		},
+	}
+
+	for i, tc := range testCases {
+		observer := &testObserver{}
+		resources := Wrap(tc.Resources, observer)
+
+		// The operatorkit controller stops executing resources on errors and
+		// canceled reconciliations.
+		ctx := reconciliationcanceledcontext.NewContext(context.Background(), make(chan struct{}))
+		for _, r := range resources {
+			err := r.EnsureCreated(ctx, nil)
+			if err != nil || reconciliationcanceledcontext.IsCanceled(ctx) {
+				break
+			}
+		}
+		ctx = reconciliationcanceledcontext.NewContext(context.Background(), make(chan struct{}))
+		for _, r := range resources {
+			err := r.EnsureDeleted(ctx, nil)
+			if err != nil || reconciliationcanceledcontext.IsCanceled(ctx) {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(observer.outcomes, tc.ExpectedOutcomes) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedOutcomes, observer.outcomes)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package pin manages the pins of LB ports recorded on the shared host cluster
+// objects, i.e. the ingress controller service and config map. A pinned port
+// belongs to a guest cluster and must never be reallocated to another guest
+// cluster. Pins are recorded in an annotation of the form
+//
+//	ingress-operator.giantswarm.io/pins: "31000=al9qy,31001=al9qy"
+package pin
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Annotation is the annotation of the shared host cluster objects holding
+	// the pinned ports and the IDs of the guest clusters owning them.
+	Annotation = "ingress-operator.giantswarm.io/pins"
+)
+
+// FromAnnotations returns the pins recorded in the given annotations, mapping
+// ports to the IDs of the guest clusters owning them. Malformed entries are
+// ignored.
+func FromAnnotations(annotations map[string]string) map[int]string {
+	pins := map[int]string{}
+
+	for _, e := range strings.Split(annotations[Annotation], ",") {
+		parts := strings.Split(strings.TrimSpace(e), "=")
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		p, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+
+		pins[p] = parts[1]
+	}
+
+	return pins
+}
+
+// ToAnnotations records the given pins in the given annotations. The
+// annotation is removed in case there are no pins. The potentially newly
+// allocated annotations are returned.
+func ToAnnotations(annotations map[string]string, pins map[int]string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	if len(pins) == 0 {
+		delete(annotations, Annotation)
+		return annotations
+	}
+
+	var ports []int
+	for p := range pins {
+		ports = append(ports, p)
+	}
+	sort.Ints(ports)
+
+	var entries []string
+	for _, p := range ports {
+		entries = append(entries, fmt.Sprintf("%d=%s", p, pins[p]))
+	}
+
+	annotations[Annotation] = strings.Join(entries, ",")
+
+	return annotations
+}
+
+// Conflict returns the ID of the guest cluster owning the pin of the given
+// port, in case the port is pinned by another guest cluster than the given
+// one.
+func Conflict(pins map[int]string, clusterID string, port int) (string, bool) {
+	owner, ok := pins[port]
+	if !ok || owner == clusterID {
+		return "", false
+	}
+
+	return owner, true
+}
+
+// Update returns a copy of the given pins where the pins of the given guest
+// cluster are replaced by the given pinned ports. The returned bool expresses
+// whether the pins changed.
+func Update(pins map[int]string, clusterID string, pinned []int) (map[int]string, bool) {
+	updated := map[int]string{}
+	for p, owner := range pins {
+		if owner != clusterID {
+			updated[p] = owner
+		}
+	}
+	for _, p := range pinned {
+		updated[p] = clusterID
+	}
+
+	changed := len(updated) != len(pins)
+	for p, owner := range updated {
+		if pins[p] != owner {
+			changed = true
+		}
+	}
+
+	return updated, changed
+}
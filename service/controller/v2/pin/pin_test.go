@@ -0,0 +1,82 @@
+package pin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Pin_Annotations(t *testing.T) {
+	pins := map[int]string{
+		31001: "al9qy",
+		31000: "p1l6x",
+	}
+
+	annotations := ToAnnotations(nil, pins)
+	if annotations[Annotation] != "31000=p1l6x,31001=al9qy" {
+		t.Fatal("expected", "31000=p1l6x,31001=al9qy", "got", annotations[Annotation])
+	}
+
+	result := FromAnnotations(annotations)
+	if !reflect.DeepEqual(pins, result) {
+		t.Fatalf("expected %#v got %#v", pins, result)
+	}
+
+	annotations = ToAnnotations(annotations, nil)
+	if _, ok := annotations[Annotation]; ok {
+		t.Fatal("expected", false, "got", true)
+	}
+}
+
+func Test_Pin_Update(t *testing.T) {
+	testCases := []struct {
+		Pins            map[int]string
+		ClusterID       string
+		Pinned          []int
+		ExpectedPins    map[int]string
+		ExpectedChanged bool
+	}{
+		// Test 0 ensures pins of a guest cluster are added.
+		{
+			Pins:      map[int]string{31000: "p1l6x"},
+			ClusterID: "al9qy",
+			Pinned:    []int{31001},
+			ExpectedPins: map[int]string{
+				31000: "p1l6x",
+				31001: "al9qy",
+			},
+			ExpectedChanged: true,
+		},
+
+		// Test 1 ensures pins of a guest cluster are removed when not pinned
+		// anymore.
+		{
+			Pins: map[int]string{
+				31000: "p1l6x",
+				31001: "al9qy",
+			},
+			ClusterID:       "al9qy",
+			Pinned:          nil,
+			ExpectedPins:    map[int]string{31000: "p1l6x"},
+			ExpectedChanged: true,
+		},
+
+		// Test 2 ensures unchanged pins are detected.
+		{
+			Pins:            map[int]string{31001: "al9qy"},
+			ClusterID:       "al9qy",
+			Pinned:          []int{31001},
+			ExpectedPins:    map[int]string{31001: "al9qy"},
+			ExpectedChanged: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		pins, changed := Update(tc.Pins, tc.ClusterID, tc.Pinned)
+		if !reflect.DeepEqual(tc.ExpectedPins, pins) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedPins, pins)
+		}
+		if changed != tc.ExpectedChanged {
+			t.Fatal("test", i, "expected", tc.ExpectedChanged, "got", changed)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package capacity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+const (
+	// EventReason is the reason of the events raised for IngressConfigs refused
+	// because of the maximum number of service ports.
+	EventReason = "ServicePortLimitReached"
+)
+
+// EnsureCreated cancels the reconciliation of IngressConfigs whose LB ports not
+// yet being ports of the service of the shared ingress controller would exceed
+// its maximum number of ports.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	namespace := cc.IngressController.Namespace
+	name := cc.IngressController.Service
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("counting the ports of service %s/%s", namespace, name))
+
+	service, err := r.k8sClient.GetService(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		logging.Debug(ctx, r.logger, fmt.Sprintf("did not find service %s/%s", namespace, name))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	current := map[int]bool{}
+	for _, p := range service.Spec.Ports {
+		current[int(p.Port)] = true
+	}
+
+	var added int
+	for _, p := range key.LBPorts(customObject) {
+		if !current[p] {
+			added++
+		}
+	}
+
+	servicePortsGauge.WithLabelValues(namespace, name).Set(float64(len(current)))
+	serviceMaxPortsGauge.WithLabelValues(namespace, name).Set(float64(r.maxPorts))
+
+	if added == 0 || len(current)+added <= r.maxPorts {
+		logging.Debug(ctx, r.logger, fmt.Sprintf("service %s/%s has %d of %d ports and needs %d more", namespace, name, len(current), r.maxPorts, added))
+		return nil
+	}
+
+	message := fmt.Sprintf("service %s/%s has %d of %d ports and cannot take %d more, consider adding another ingress controller", namespace, name, len(current), r.maxPorts, added)
+
+	logging.Warning(ctx, r.logger, fmt.Sprintf("refusing the ingress config: %s", message))
+	refusedCounter.WithLabelValues(namespace, name).Inc()
+	cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.PortsAllocated, conditions.StatusFalse, EventReason, message))
+
+	// Failing to raise the event must not make the reconciliation proceed, so
+	// the error is only logged.
+	_, err = r.k8sClient.CreateEvent(ctx, customObject.Namespace, r.newEvent(customObject, message))
+	if err != nil {
+		logging.Error(ctx, r.logger, "failed raising event for refused ingress config", err)
+	}
+
+	reconciliationcanceledcontext.SetCanceled(ctx)
+	logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+	return nil
+}
+
+func (r *Resource) newEvent(customObject v1alpha1.IngressConfig, message string) *corev1.Event {
+	now := metav1.NewTime(time.Now())
+
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", customObject.Name, now.UnixNano()),
+			Namespace: customObject.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      v1alpha1.SchemeGroupVersion.String(),
+			Kind:            "IngressConfig",
+			Name:            customObject.Name,
+			Namespace:       customObject.Namespace,
+			ResourceVersion: customObject.ResourceVersion,
+			UID:             customObject.UID,
+		},
+		Reason:  EventReason,
+		Message: message,
+		Source: corev1.EventSource{
+			Component: r.projectName,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           corev1.EventTypeWarning,
+	}
+
+	return event
+}
@@ -0,0 +1,135 @@
+package capacity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+)
+
+func Test_Capacity_EnsureCreated(t *testing.T) {
+	testCases := []struct {
+		CurrentPorts   []int32
+		LBPorts        []int
+		ExpectedCancel bool
+		ExpectedEvents int
+	}{
+		// Test 0 ensures ports are accepted as long as the service stays within
+		// the maximum.
+		{
+			CurrentPorts:   []int32{31010},
+			LBPorts:        []int{31000, 31001},
+			ExpectedCancel: false,
+			ExpectedEvents: 0,
+		},
+
+		// Test 1 ensures ports exceeding the maximum are refused.
+		{
+			CurrentPorts:   []int32{31010, 31011},
+			LBPorts:        []int{31000, 31001},
+			ExpectedCancel: true,
+			ExpectedEvents: 1,
+		},
+
+		// Test 2 ensures ports already being ports of the service are accepted,
+		// even when the service is full.
+		{
+			CurrentPorts:   []int32{31000, 31001, 31010},
+			LBPorts:        []int{31000, 31001},
+			ExpectedCancel: false,
+			ExpectedEvents: 0,
+		},
+	}
+
+	for i, tc := range testCases {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+		}
+		for _, p := range tc.CurrentPorts {
+			service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{Port: p})
+		}
+
+		k8sClient := fake.NewSimpleClientset(service)
+
+		var newResource *Resource
+		{
+			clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+
+			c.MaxPorts = 3
+			c.ProjectName = "ingress-operator"
+
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		customObject := &v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "al9qy",
+				Namespace: "default",
+			},
+		}
+		for _, p := range tc.LBPorts {
+			customObject.Spec.ProtocolPorts = append(customObject.Spec.ProtocolPorts, v1alpha1.IngressConfigSpecProtocolPort{LBPort: p})
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			IngressController: controllercontext.IngressController{
+				ConfigMap: "ingress-controller",
+				Namespace: "kube-system",
+				Service:   "ingress-controller",
+			},
+		})
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+		err := newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		if reconciliationcanceledcontext.IsCanceled(ctx) != tc.ExpectedCancel {
+			t.Fatal("test", i, "expected", tc.ExpectedCancel, "got", reconciliationcanceledcontext.IsCanceled(ctx))
+		}
+
+		list, err := k8sClient.CoreV1().Events("default").List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if len(list.Items) != tc.ExpectedEvents {
+			t.Fatal("test", i, "expected", tc.ExpectedEvents, "got", len(list.Items))
+		}
+
+		cc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		c := conditions.Get(cc.Conditions, conditions.PortsAllocated)
+		if tc.ExpectedCancel && (c.Status != conditions.StatusFalse || c.Reason != EventReason) {
+			t.Fatalf("test %d expected %s got %#v", i, "refused condition", c)
+		}
+		if !tc.ExpectedCancel && c.Status != conditions.StatusUnknown {
+			t.Fatalf("test %d expected %s got %#v", i, "no condition", c)
+		}
+	}
+}
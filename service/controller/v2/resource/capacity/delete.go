@@ -0,0 +1,11 @@
+package capacity
+
+import (
+	"context"
+)
+
+// EnsureDeleted does nothing. Deleting IngressConfigs only ever frees ports of
+// the service.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package capacity
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "capacity"
+)
+
+var (
+	servicePortsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "service_ports",
+			Help:      "A gauge metric expressing the number of ports of the service of a shared ingress controller.",
+		},
+		[]string{"namespace", "service"},
+	)
+	serviceMaxPortsGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "service_max_ports",
+			Help:      "A gauge metric expressing the maximum number of ports of the service of a shared ingress controller.",
+		},
+		[]string{"namespace", "service"},
+	)
+	refusedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "refused_total",
+			Help:      "A counter metric expressing the number of reconciliations of IngressConfigs refused because their LB ports exceed the maximum number of ports of the service of the shared ingress controller.",
+		},
+		[]string{"namespace", "service"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(servicePortsGauge)
+	prometheus.MustRegister(serviceMaxPortsGauge)
+	prometheus.MustRegister(refusedCounter)
+}
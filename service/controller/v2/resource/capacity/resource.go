@@ -0,0 +1,96 @@
+// Package capacity implements a resource protecting the service of the shared
+// ingress controller against growing beyond a maximum number of ports. Every
+// LB port of every guest cluster is a port of the shared service and
+// kube-proxy programs rules for each of them on every node, so its costs grow
+// with the number of ports. IngressConfigs whose LB ports would push the
+// service beyond the maximum are refused before their ports get allocated. A
+// warning event suggests adding another ingress controller instead.
+package capacity
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "capacityv2"
+)
+
+// Config represents the configuration used to create a new capacity resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// MaxPorts is the maximum number of ports of the service of the shared
+	// ingress controller.
+	MaxPorts int
+	// ProjectName is the name of the operator reported as the source of the
+	// events raised for refused IngressConfigs.
+	ProjectName string
+}
+
+// DefaultConfig provides a default configuration to create a new capacity
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		MaxPorts:    0,
+		ProjectName: "",
+	}
+}
+
+// Resource implements the capacity resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	maxPorts    int
+	projectName string
+}
+
+// New creates a new configured capacity resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	// Settings.
+	if config.MaxPorts < 1 {
+		return nil, microerror.Maskf(invalidConfigError, "config.MaxPorts must be greater than 0")
+	}
+	if config.ProjectName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.ProjectName must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		maxPorts:    config.MaxPorts,
+		projectName: config.ProjectName,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -0,0 +1,86 @@
+package certificate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureCreated looks up the certificate Secrets of the guest cluster of the
+// given IngressConfig. In case any of them does not exist yet, the https LB
+// ports are put into the controller context as pending, so the config map and
+// service resources leave them out. The reconciliation goes on for all other
+// LB ports. The CertificatesIssued condition is recorded either way, so that
+// the IngressConfig does not become ready while certificates are pending.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	httpsPorts := httpsLBPorts(customObject)
+	if len(r.components) == 0 || len(httpsPorts) == 0 {
+		cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.CertificatesIssued, conditions.StatusTrue, "", ""))
+		return nil
+	}
+
+	logging.Debug(ctx, r.logger, "looking up certificate secrets")
+
+	var missing []string
+	for _, c := range r.components {
+		name := key.CertificateSecretName(customObject, c)
+
+		_, err := r.k8sClient.GetSecret(ctx, r.namespace, name)
+		if clients.IsNotFound(err) {
+			missing = append(missing, name)
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	if len(missing) == 0 {
+		logging.Debug(ctx, r.logger, "found certificate secrets")
+		cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.CertificatesIssued, conditions.StatusTrue, "", ""))
+		return nil
+	}
+
+	message := fmt.Sprintf("waiting for certificate secrets %s in namespace %s", strings.Join(missing, ", "), r.namespace)
+	logging.Debug(ctx, r.logger, fmt.Sprintf("%s, leaving out LB ports %v", message, httpsPorts))
+
+	if cc.CertificatePendingPorts == nil {
+		cc.CertificatePendingPorts = map[int]bool{}
+	}
+	for _, p := range httpsPorts {
+		cc.CertificatePendingPorts[p] = true
+	}
+	cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.CertificatesIssued, conditions.StatusFalse, Reason, message))
+
+	return nil
+}
+
+// httpsLBPorts returns the LB ports of the https protocol ports of the given
+// custom object. Certificates are not required in case https is disabled.
+func httpsLBPorts(customObject v1alpha1.IngressConfig) []int {
+	var ports []int
+	for _, p := range key.EnabledProtocolPorts(customObject) {
+		if key.Protocol(p) == key.ProtocolHTTPS {
+			ports = append(ports, p.LBPort)
+		}
+	}
+
+	return ports
+}
@@ -0,0 +1,127 @@
+package certificate
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+)
+
+func Test_Certificate_EnsureCreated(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy-ingress",
+			Namespace: "default",
+		},
+	}
+
+	protocolPorts := []v1alpha1.IngressConfigSpecProtocolPort{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{IngressPort: 30011, LBPort: 31001, Protocol: "HTTPS"},
+	}
+
+	testCases := []struct {
+		Components                 []string
+		ProtocolPorts              []v1alpha1.IngressConfigSpecProtocolPort
+		ExpectedPendingPorts       map[int]bool
+		ExpectedCertificatesIssued conditions.Status
+	}{
+		// Test 0 ensures nothing is waited for without components.
+		{
+			Components:                 nil,
+			ProtocolPorts:              protocolPorts,
+			ExpectedPendingPorts:       nil,
+			ExpectedCertificatesIssued: conditions.StatusTrue,
+		},
+
+		// Test 1 ensures nothing is waited for without https LB ports.
+		{
+			Components: []string{"api"},
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+			},
+			ExpectedPendingPorts:       nil,
+			ExpectedCertificatesIssued: conditions.StatusTrue,
+		},
+
+		// Test 2 ensures https LB ports are not pending once the certificate
+		// secrets exist.
+		{
+			Components:                 []string{"ingress"},
+			ProtocolPorts:              protocolPorts,
+			ExpectedPendingPorts:       nil,
+			ExpectedCertificatesIssued: conditions.StatusTrue,
+		},
+
+		// Test 3 ensures only https LB ports are pending as long as any
+		// certificate secret is missing.
+		{
+			Components:                 []string{"ingress", "api"},
+			ProtocolPorts:              protocolPorts,
+			ExpectedPendingPorts:       map[int]bool{31001: true},
+			ExpectedCertificatesIssued: conditions.StatusFalse,
+		},
+	}
+
+	for i, tc := range testCases {
+		var newResource *Resource
+		{
+			clientsInterface, err := clients.New(clients.Config{K8sClient: fake.NewSimpleClientset(secret)})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+
+			c.Components = tc.Components
+			c.Namespace = "default"
+
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		customObject := &v1alpha1.IngressConfig{
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+					ID:        "al9qy",
+					Namespace: "al9qy",
+					Service:   "worker",
+				},
+				ProtocolPorts: tc.ProtocolPorts,
+			},
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+
+		err := newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		cc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(tc.ExpectedPendingPorts, cc.CertificatePendingPorts) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedPendingPorts, cc.CertificatePendingPorts)
+		}
+		status := conditions.Get(cc.Conditions, conditions.CertificatesIssued).Status
+		if status != tc.ExpectedCertificatesIssued {
+			t.Fatal("test", i, "expected", tc.ExpectedCertificatesIssued, "got", status)
+		}
+	}
+}
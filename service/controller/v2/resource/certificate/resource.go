@@ -0,0 +1,98 @@
+// Package certificate implements a resource gating the https LB ports of
+// IngressConfigs on the certificates of their guest clusters. cert-operator
+// issues the certificates of CertConfigs into Secrets named after the guest
+// cluster and the cluster component. As long as any of the configured
+// Secrets does not exist the https LB ports are left out of the config map and
+// the service, so that the host cluster ingress controller does not serve
+// broken TLS while the guest cluster is being created. Other LB ports are
+// not affected.
+package certificate
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "certificatev2"
+	// Reason is the reason of the CertificatesIssued condition while
+	// certificates are pending.
+	Reason = "CertificatesPending"
+)
+
+// Config represents the configuration used to create a new certificate
+// resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// Components are the cluster components whose certificates https LB ports
+	// wait for, e.g. "ingress". Nothing is waited for in case it is empty.
+	Components []string
+	// Namespace is the namespace cert-operator issues the certificate Secrets
+	// into.
+	Namespace string
+}
+
+// DefaultConfig provides a default configuration to create a new certificate
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		Components: nil,
+		Namespace:  "",
+	}
+}
+
+// Resource implements the certificate resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	components []string
+	namespace  string
+}
+
+// New creates a new configured certificate resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	// Settings.
+	if len(config.Components) != 0 && config.Namespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.Namespace must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		components: config.Components,
+		namespace:  config.Namespace,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
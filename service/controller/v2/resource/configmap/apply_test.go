@@ -0,0 +1,301 @@
+package configmap
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+)
+
+// testWrite is a write issued against the Kubernetes API, as recorded by the
+// fake clientset.
+type testWrite struct {
+	Verb      string
+	Resource  string
+	Namespace string
+}
+
+// newTestClientset returns a fake clientset serving the given config map. The
+// config map is kept in a separate tracker, since the object tracker of the
+// fake clientset cannot apply JSON patches. The patches written are recorded
+// in the given slice.
+func newTestClientset(configMap *apiv1.ConfigMap, patches *[]string) (*fake.Clientset, error) {
+	tracker := k8stesting.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())
+	err := tracker.Add(configMap)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	k8sClient := fake.NewSimpleClientset()
+	k8sClient.PrependReactor("*", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patchAction, ok := action.(k8stesting.PatchAction)
+		if !ok {
+			return k8stesting.ObjectReaction(tracker)(action)
+		}
+		*patches = append(*patches, string(patchAction.GetPatch()))
+
+		gvr := apiv1.SchemeGroupVersion.WithResource("configmaps")
+		obj, err := tracker.Get(gvr, patchAction.GetNamespace(), patchAction.GetName())
+		if err != nil {
+			return true, nil, microerror.Mask(err)
+		}
+		configMap := obj.(*apiv1.ConfigMap)
+
+		err = applyDataPatch(configMap, patchAction.GetPatch())
+		if err != nil {
+			return true, nil, microerror.Mask(err)
+		}
+
+		return true, configMap, tracker.Update(gvr, configMap, patchAction.GetNamespace())
+	})
+
+	return k8sClient, nil
+}
+
+// testWrites returns the writes of the given recorded actions. Reads are
+// ignored.
+func testWrites(actions []k8stesting.Action) []testWrite {
+	var writes []testWrite
+	for _, a := range actions {
+		switch a.GetVerb() {
+		case "get", "list", "watch":
+			continue
+		}
+
+		writes = append(writes, testWrite{
+			Verb:      a.GetVerb(),
+			Resource:  a.GetResource().Resource,
+			Namespace: a.GetNamespace(),
+		})
+	}
+
+	return writes
+}
+
+func newTestApplyCustomObject(deleted bool) *v1alpha1.IngressConfig {
+	customObject := &v1alpha1.IngressConfig{
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        "al9qy",
+				Namespace: "al9qy",
+				Service:   "worker",
+			},
+			HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+				IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+					ConfigMap: "ingress-controller",
+					Namespace: "kube-system",
+					Service:   "ingress-controller",
+				},
+			},
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{
+					IngressPort: 30010,
+					Protocol:    "http",
+					LBPort:      31000,
+				},
+			},
+		},
+	}
+	if deleted {
+		now := metav1.Now()
+		customObject.DeletionTimestamp = &now
+	}
+
+	return customObject
+}
+
+// Test_ConfigMap_Apply ensures the resource issues exactly the expected writes
+// against the Kubernetes API and skips writes in case nothing changed.
+func Test_ConfigMap_Apply(t *testing.T) {
+	testCases := []struct {
+		Deleted         bool
+		CurrentData     map[string]string
+		ExpectedData    map[string]string
+		ExpectedWrites  []testWrite
+		ExpectedPatches []string
+	}{
+		// Test 0 ensures a missing item is added with a single patch of its
+		// key, which tests the key to be absent.
+		{
+			Deleted: false,
+			CurrentData: map[string]string{
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedData: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedWrites: []testWrite{
+				{Verb: "patch", Resource: "configmaps", Namespace: "kube-system"},
+			},
+			ExpectedPatches: []string{
+				`[{"op":"test","path":"/data/31000","value":null},{"op":"add","path":"/data/31000","value":"al9qy/worker:30010"}]`,
+			},
+		},
+
+		// Test 1 ensures nothing is written in case the config map is up to date.
+		{
+			Deleted: false,
+			CurrentData: map[string]string{
+				"31000": "al9qy/worker:30010",
+			},
+			ExpectedData: map[string]string{
+				"31000": "al9qy/worker:30010",
+			},
+			ExpectedWrites: nil,
+		},
+
+		// Test 2 ensures the items of a deleted guest cluster are removed with a
+		// single update.
+		{
+			Deleted: true,
+			CurrentData: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedData: map[string]string{
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "configmaps", Namespace: "kube-system"},
+			},
+		},
+
+		// Test 3 ensures nothing is written in case the items of a deleted guest
+		// cluster are already gone.
+		{
+			Deleted: true,
+			CurrentData: map[string]string{
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedData: map[string]string{
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedWrites: nil,
+		},
+
+		// Test 4 ensures a changed item is replaced with a single patch, which
+		// tests its key for the value it got computed from.
+		{
+			Deleted: false,
+			CurrentData: map[string]string{
+				"31000": "p1l6x/worker:30010",
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedData: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedWrites: []testWrite{
+				{Verb: "patch", Resource: "configmaps", Namespace: "kube-system"},
+			},
+			ExpectedPatches: []string{
+				`[{"op":"test","path":"/data/31000","value":"p1l6x/worker:30010"},{"op":"replace","path":"/data/31000","value":"al9qy/worker:30010"}]`,
+			},
+		},
+
+		// Test 5 ensures a config map without any data is updated as a whole,
+		// since there is no data to patch keys into.
+		{
+			Deleted:     false,
+			CurrentData: nil,
+			ExpectedData: map[string]string{
+				"31000": "al9qy/worker:30010",
+			},
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "configmaps", Namespace: "kube-system"},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		var patches []string
+		k8sClient, err := newTestClientset(&apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+			Data: tc.CurrentData,
+		}, &patches)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		var crudResource *controller.CRUDResource
+		{
+			a, err := allocator.New(allocator.Config{})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.Allocator = a
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+
+			ops, err := New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			crudResource, err = controller.NewCRUDResource(controller.CRUDResourceConfig{Logger: microloggertest.New(), Ops: ops})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			IngressController: controllercontext.IngressController{
+				ConfigMap: "ingress-controller",
+				Namespace: "kube-system",
+				Service:   "ingress-controller",
+			},
+		})
+
+		k8sClient.ClearActions()
+
+		if tc.Deleted {
+			err = crudResource.EnsureDeleted(ctx, newTestApplyCustomObject(true))
+		} else {
+			err = crudResource.EnsureCreated(ctx, newTestApplyCustomObject(false))
+		}
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		writes := testWrites(k8sClient.Actions())
+		if !reflect.DeepEqual(writes, tc.ExpectedWrites) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedWrites, writes)
+		}
+		if !reflect.DeepEqual(patches, tc.ExpectedPatches) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedPatches, patches)
+		}
+
+		configMap, err := k8sClient.CoreV1().ConfigMaps("kube-system").Get("ingress-controller", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(configMap.Data, tc.ExpectedData) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedData, configMap.Data)
+		}
+	}
+}
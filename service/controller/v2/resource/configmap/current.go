@@ -7,33 +7,50 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
 	"github.com/giantswarm/operatorkit/controller/context/resourcecanceledcontext"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/deletiondelay"
 	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
 )
 
 func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interface{}, error) {
-	customObject, err := toCustomObject(obj)
+	customObject, err := key.ToCustomObject(obj)
 	if err != nil {
-		return microerror.Mask(err), nil
+		return nil, microerror.Mask(err)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", "get current state")
+	logging.Debug(ctx, r.logger, "get current state")
 
 	// Lookup the current state of the configmap.
-	namespace := customObject.Spec.HostCluster.IngressController.Namespace
-	configMap := customObject.Spec.HostCluster.IngressController.ConfigMap
-	k8sConfigMap, err := r.k8sClient.CoreV1().ConfigMaps(namespace).Get(configMap, metav1.GetOptions{})
+	cc, err := controllercontext.FromContext(ctx)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
+
+	namespace := cc.IngressController.Namespace
+	configMap := cc.IngressController.ConfigMap
+	k8sConfigMap, err := r.k8sClient.GetConfigMap(ctx, namespace, configMap)
+	if clients.IsNotFound(err) && key.IsDeleted(customObject) {
+		// In case the config map does not exist anymore while the custom object is
+		// being deleted, there is nothing left to delete. This happens e.g. when
+		// a dedicated ingress controller got removed already.
+		logging.Debug(ctx, r.logger, "did not find the config map in the Kubernetes API")
+
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
 	// Ensure that the map is assignable. This prevents panics down the road in
 	// case the config map has no data at all.
 	if k8sConfigMap.Data == nil {
 		k8sConfigMap.Data = map[string]string{}
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found k8s state: %#v", *k8sConfigMap))
+	logging.Debug(ctx, r.logger, fmt.Sprintf("found k8s state: %#v", *k8sConfigMap))
+
+	cc.Transaction.Begin(k8sConfigMap.Data)
 
 	// In case a cluster deletion happens, we want to delete the ingress
 	// controller config map data. We still need to use it for resource creation
@@ -44,18 +61,21 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 	// event got replayed. Then we just remove the config map data as usual.
 	if key.IsDeleted(customObject) {
 		n := key.ClusterNamespace(customObject)
-		list, err := r.k8sClient.CoreV1().Pods(n).List(metav1.ListOptions{})
+		list, err := r.k8sClient.ListPods(ctx, n)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 		if len(list.Items) != 0 {
-			r.logger.LogCtx(ctx, "level", "debug", "message", "cannot finish deletion of namespace due to existing pods")
+			deletiondelay.Wait(customObject, list.Items)
+			logging.Debug(ctx, r.logger, "cannot finish deletion of namespace due to existing pods")
 			resourcecanceledcontext.SetCanceled(ctx)
 			finalizerskeptcontext.SetKept(ctx)
-			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource for custom object")
+			logging.Debug(ctx, r.logger, "canceling resource for custom object")
 
 			return nil, nil
 		}
+
+		deletiondelay.Done(customObject)
 	}
 
 	return k8sConfigMap, nil
@@ -6,10 +6,15 @@ import (
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/diff"
+	"github.com/giantswarm/ingress-operator/service/jitter"
 )
 
 func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange interface{}) error {
-	customObject, err := toCustomObject(obj)
+	cc, err := controllercontext.FromContext(ctx)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -19,17 +24,19 @@ func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange inte
 	}
 
 	if configMapToDelete != nil {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "deleting the config map data in the Kubernetes API")
+		logging.Debug(ctx, r.logger, "deleting the config map data in the Kubernetes API")
+
+		jitter.Sleep(ctx, r.writeJitter)
 
-		namespace := customObject.Spec.HostCluster.IngressController.Namespace
-		_, err := r.k8sClient.CoreV1().ConfigMaps(namespace).Update(configMapToDelete)
+		namespace := cc.IngressController.Namespace
+		_, err := r.k8sClient.UpdateConfigMap(ctx, namespace, configMapToDelete)
 		if err != nil {
 			return microerror.Mask(err)
 		}
 
-		r.logger.LogCtx(ctx, "level", "debug", "message", "deleted the config map data in the Kubernetes API")
+		logging.Debug(ctx, r.logger, "deleted the config map data in the Kubernetes API")
 	} else {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "the config map data does not need to be deleted in the Kubernetes API")
+		logging.Debug(ctx, r.logger, "the config map data does not need to be deleted in the Kubernetes API")
 	}
 
 	return nil
@@ -52,12 +59,15 @@ func (r *Resource) newDeleteChange(ctx context.Context, obj, currentState, desir
 	if err != nil {
 		return microerror.Mask(err), nil
 	}
+	if currentConfigMap == nil {
+		return nil, nil
+	}
 	dState, ok := desiredState.(map[string]string)
 	if !ok {
 		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", map[string]string{}, desiredState)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", "get delete state")
+	logging.Debug(ctx, r.logger, "get delete state")
 
 	// Make sure the current state of the Kubernetes resources is known by the
 	// delete action. The resources we already fetched represent the source of
@@ -74,15 +84,22 @@ func (r *Resource) newDeleteChange(ctx context.Context, obj, currentState, desir
 	// desired state, because a decent reconciliation is not always only an update
 	// operation of existing resources, but e.g. deletion of resources. In our
 	// case here we only transform data within resources. Therefore the update.
+	var changes []diff.Change
 	newData := map[string]string{}
 	for k, v := range deleteState.Data {
-		if !inConfigMapData(dState, k, v) {
+		if inConfigMapData(dState, k, v) && !r.isReserved(k) {
+			changes = append(changes, diff.Removed(k, v))
+		} else {
 			newData[k] = v
 		}
 	}
 	deleteState.Data = newData
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found delete state: %#v", deleteState))
+	logging.Debug(ctx, r.logger, fmt.Sprintf("computed delete patch: %s", diff.Render(changes)))
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
 
 	return deleteState, nil
 }
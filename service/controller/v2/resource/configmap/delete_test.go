@@ -9,6 +9,9 @@ import (
 	"github.com/giantswarm/micrologger/microloggertest"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
 )
 
 func Test_Service_newDeleteChange(t *testing.T) {
@@ -114,14 +117,68 @@ func Test_Service_newDeleteChange(t *testing.T) {
 			},
 			ErrorMatcher: nil,
 		},
+
+		// Test 2 ensures data of reserved ports is never removed.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+						{
+							IngressPort: 30011,
+							Protocol:    "https",
+							LBPort:      31999,
+						},
+					},
+				},
+			},
+			CurrentState: &apiv1.ConfigMap{
+				Data: map[string]string{
+					"31000": "al9qy/worker:30010",
+					"31999": "al9qy/worker:30011",
+				},
+			},
+			DesiredState: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31999": "al9qy/worker:30011",
+			},
+			Expected: &apiv1.ConfigMap{
+				Data: map[string]string{
+					"31999": "al9qy/worker:30011",
+				},
+			},
+			ErrorMatcher: nil,
+		},
 	}
 
 	var err error
 	var newResource *Resource
 	{
+		var a *allocator.Allocator
+		a, err = allocator.New(allocator.Config{Reserved: []int{31999}})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
 		c := DefaultConfig()
 
-		c.K8sClient = fake.NewSimpleClientset()
+		c.Allocator = a
+		var k8sClient clients.Interface
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c.K8sClient = k8sClient
 		c.Logger = microloggertest.New()
 
 		newResource, err = New(c)
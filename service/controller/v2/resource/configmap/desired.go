@@ -5,33 +5,89 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
 )
 
 func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
-	customObject, err := toCustomObject(obj)
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "get desired state")
+
+	dState := DesiredData(cc, customObject)
+
+	// Discovered ingress ports and port names are not covered by the
+	// validation resource. Nothing is written in case they are not safe.
+	err = ValidateData(dState)
 	if err != nil {
-		return microerror.Mask(err), nil
+		return nil, microerror.Mask(err)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", "get desired state")
+	logging.Debug(ctx, r.logger, fmt.Sprintf("found desired state: %#v", dState))
+
+	return dState, nil
+}
+
+// DesiredData returns the config map data the given custom object requires,
+// mapping its LB ports to the ingress ports of its guest cluster. LB ports
+// passing TLS through hand over connections with the PROXY protocol. Named
+// ports reference the port of the guest cluster service by the name resolved
+// by the discovery resource. LB ports waiting for their certificates and LB
+// ports of disabled protocols are left out.
+func DesiredData(cc *controllercontext.Context, customObject v1alpha1.IngressConfig) map[string]string {
+	// Invalid annotations are refused by the validation resource, so that they
+	// never reach this point during reconciliation.
+	passthrough, _ := key.TLSPassthroughPorts(customObject)
+
+	data := map[string]string{}
+	for _, p := range key.EnabledProtocolPorts(customObject) {
+		if cc.CertificatePendingPorts[p.LBPort] {
+			continue
+		}
 
-	// Lookup the desired state of the config map to have a reference of data how
-	// it should be.
-	dState := map[string]string{}
-	for _, p := range customObject.Spec.ProtocolPorts {
 		configMapKey := strconv.Itoa(p.LBPort)
 		configMapValue := fmt.Sprintf(
 			DataValueFormat,
-			customObject.Spec.GuestCluster.Namespace,
-			customObject.Spec.GuestCluster.Service,
-			p.IngressPort,
+			key.ClusterNamespace(customObject),
+			key.ClusterService(customObject),
+			cc.IngressPort(p),
 		)
+		if name, ok := cc.PortNames[p.LBPort]; ok {
+			configMapValue = fmt.Sprintf(
+				NamedDataValueFormat,
+				key.ClusterNamespace(customObject),
+				key.ClusterService(customObject),
+				name,
+			)
+		}
+		if inPorts(passthrough, p.LBPort) {
+			configMapValue += ProxyProtocolSuffix
+		}
 
-		dState[configMapKey] = configMapValue
+		data[configMapKey] = configMapValue
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found desired state: %#v", dState))
+	return data
+}
 
-	return dState, nil
+func inPorts(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+
+	return false
 }
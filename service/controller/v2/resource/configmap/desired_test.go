@@ -7,12 +7,20 @@ import (
 
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
 )
 
 func Test_Service_GetDesiredState(t *testing.T) {
 	testCases := []struct {
 		Obj          interface{}
+		IngressPorts map[int]int
+		PortNames    map[int]string
 		Expected     map[string]string
 		ErrorMatcher func(error) bool
 	}{
@@ -89,14 +97,158 @@ func Test_Service_GetDesiredState(t *testing.T) {
 			},
 			ErrorMatcher: nil,
 		},
+
+		// Test 2 ensures discovered ingress ports take precedence over the ones
+		// of the spec.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+						IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+							ConfigMap: "ingress-controller",
+							Namespace: "kube-system",
+							Service:   "ingress-controller",
+						},
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 0,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+						{
+							IngressPort: 30011,
+							Protocol:    "https",
+							LBPort:      31001,
+						},
+					},
+				},
+			},
+			IngressPorts: map[int]int{
+				31000: 32080,
+			},
+			Expected: map[string]string{
+				"31000": "al9qy/worker:32080",
+				"31001": "al9qy/worker:30011",
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 3 ensures LB ports passing TLS through hand over connections with
+		// the PROXY protocol.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						key.TLSPassthroughAnnotation: "31001",
+					},
+				},
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+						IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+							ConfigMap: "ingress-controller",
+							Namespace: "kube-system",
+							Service:   "ingress-controller",
+						},
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+						{
+							IngressPort: 30011,
+							Protocol:    "https",
+							LBPort:      31001,
+						},
+					},
+				},
+			},
+			Expected: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31001": "al9qy/worker:30011::PROXY",
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 4 ensures named ports reference the guest cluster service port by
+		// its resolved name.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						key.NamedPortsAnnotation:     "31001",
+						key.TLSPassthroughAnnotation: "31001",
+					},
+				},
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+						IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+							ConfigMap: "ingress-controller",
+							Namespace: "kube-system",
+							Service:   "ingress-controller",
+						},
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+						{
+							IngressPort: 30011,
+							Protocol:    "https",
+							LBPort:      31001,
+						},
+					},
+				},
+			},
+			PortNames: map[int]string{
+				31001: "https",
+			},
+			Expected: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31001": "al9qy/worker:https::PROXY",
+			},
+			ErrorMatcher: nil,
+		},
 	}
 
 	var err error
 	var newResource *Resource
 	{
+		var a *allocator.Allocator
+		a, err = allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
 		c := DefaultConfig()
 
-		c.K8sClient = fake.NewSimpleClientset()
+		c.Allocator = a
+		var k8sClient clients.Interface
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c.K8sClient = k8sClient
 		c.Logger = microloggertest.New()
 
 		newResource, err = New(c)
@@ -106,7 +258,8 @@ func Test_Service_GetDesiredState(t *testing.T) {
 	}
 
 	for i, tc := range testCases {
-		result, err := newResource.GetDesiredState(context.TODO(), tc.Obj)
+		ctx := controllercontext.NewContext(context.TODO(), controllercontext.Context{IngressPorts: tc.IngressPorts, PortNames: tc.PortNames})
+		result, err := newResource.GetDesiredState(ctx, tc.Obj)
 		if err != nil && tc.ErrorMatcher == nil {
 			t.Fatal("test", i, "expected", nil, "got", err)
 		}
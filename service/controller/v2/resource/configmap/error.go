@@ -13,6 +13,24 @@ func IsInvalidConfig(err error) bool {
 	return microerror.Cause(err) == invalidConfigError
 }
 
+var invalidDataKeyError = &microerror.Error{
+	Kind: "invalidDataKeyError",
+}
+
+// IsInvalidDataKey asserts invalidDataKeyError.
+func IsInvalidDataKey(err error) bool {
+	return microerror.Cause(err) == invalidDataKeyError
+}
+
+var invalidDataValueError = &microerror.Error{
+	Kind: "invalidDataValueError",
+}
+
+// IsInvalidDataValue asserts invalidDataValueError.
+func IsInvalidDataValue(err error) bool {
+	return microerror.Cause(err) == invalidDataValueError
+}
+
 var wrongTypeError = &microerror.Error{
 	Kind: "wrongTypeError",
 }
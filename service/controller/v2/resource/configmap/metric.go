@@ -0,0 +1,27 @@
+package configmap
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "config_map"
+)
+
+var (
+	correctionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "corrections_total",
+			Help:      "A counter metric expressing the number of updates of the config map of an ingress controller because its data drifted from the desired state.",
+		},
+		[]string{"namespace", "config_map"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(correctionsCounter)
+}
@@ -0,0 +1,79 @@
+package configmap
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/diff"
+)
+
+// dataUpdate is the update change of the resource. It carries the changes of
+// the config map data next to the updated config map, so that only the
+// changed data keys are written.
+type dataUpdate struct {
+	ConfigMap *apiv1.ConfigMap
+	Changes   []diff.Change
+	// Replace is whether the whole config map has to be updated, because it
+	// had no data the changed keys could be patched into.
+	Replace bool
+}
+
+// absentValue is the value of test operations asserting that a path does not
+// exist. The API server applies test operations of null values to missing
+// paths successfully, and fails them in case the path exists.
+var absentValue = json.RawMessage("null")
+
+// patchOperation is a single JSON Patch operation, see RFC 6902.
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// dataPatch returns the JSON Patch writing the given changes to the
+// /data/<key> paths of the config map. Updated and removed keys are tested for
+// the value they got computed from first, and added keys are tested to be
+// absent, so that the patch fails in case another writer changed them since
+// the config map got read. Changes of other keys do not conflict.
+func dataPatch(changes []diff.Change) ([]byte, error) {
+	var ops []patchOperation
+	for _, c := range changes {
+		path := dataPath(c.Key)
+
+		old, err := json.Marshal(c.Old)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		value, err := json.Marshal(c.New)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		switch {
+		case c.Old == "":
+			ops = append(ops, patchOperation{Op: "test", Path: path, Value: absentValue})
+			ops = append(ops, patchOperation{Op: "add", Path: path, Value: value})
+		case c.New == "":
+			ops = append(ops, patchOperation{Op: "test", Path: path, Value: old})
+			ops = append(ops, patchOperation{Op: "remove", Path: path})
+		default:
+			ops = append(ops, patchOperation{Op: "test", Path: path, Value: old})
+			ops = append(ops, patchOperation{Op: "replace", Path: path, Value: value})
+		}
+	}
+
+	b, err := json.Marshal(ops)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return b, nil
+}
+
+// dataPath returns the JSON Pointer of the given config map data key.
+func dataPath(key string) string {
+	return "/data/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
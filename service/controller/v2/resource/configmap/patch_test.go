@@ -0,0 +1,177 @@
+package configmap
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/microerror"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/diff"
+)
+
+var invalidPatchError = &microerror.Error{
+	Kind: "invalidPatchError",
+}
+
+func isInvalidPatch(err error) bool {
+	return microerror.Cause(err) == invalidPatchError
+}
+
+// applyDataPatch applies the given JSON Patch of config map data keys to the
+// given config map the way the Kubernetes API does. It only supports the
+// operations of the patches written by the resource, since the fake clientset
+// cannot apply JSON patches.
+func applyDataPatch(configMap *apiv1.ConfigMap, patch []byte) error {
+	var ops []struct {
+		Op    string  `json:"op"`
+		Path  string  `json:"path"`
+		Value *string `json:"value"`
+	}
+	err := json.Unmarshal(patch, &ops)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if configMap.Data == nil {
+		return microerror.Maskf(invalidPatchError, "path %#q does not exist", "/data")
+	}
+
+	data := map[string]string{}
+	for k, v := range configMap.Data {
+		data[k] = v
+	}
+
+	for _, o := range ops {
+		if !strings.HasPrefix(o.Path, "/data/") {
+			return microerror.Maskf(invalidPatchError, "path %#q is not a config map data key", o.Path)
+		}
+		k := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(o.Path, "/data/"))
+		v, ok := data[k]
+
+		switch o.Op {
+		case "add":
+			data[k] = *o.Value
+		case "remove":
+			if !ok {
+				return microerror.Maskf(invalidPatchError, "path %#q does not exist", o.Path)
+			}
+			delete(data, k)
+		case "replace":
+			if !ok {
+				return microerror.Maskf(invalidPatchError, "path %#q does not exist", o.Path)
+			}
+			data[k] = *o.Value
+		case "test":
+			// Testing for null asserts the path does not exist.
+			if o.Value == nil && ok {
+				return microerror.Maskf(invalidPatchError, "path %#q exists", o.Path)
+			}
+			if o.Value != nil && (!ok || v != *o.Value) {
+				return microerror.Maskf(invalidPatchError, "path %#q does not have value %#q", o.Path, *o.Value)
+			}
+		default:
+			return microerror.Maskf(invalidPatchError, "operation %#q is not supported", o.Op)
+		}
+	}
+
+	configMap.Data = data
+
+	return nil
+}
+
+// Test_dataPatch ensures patches fail in case another writer changed the keys
+// they write since the config map got read.
+func Test_dataPatch(t *testing.T) {
+	testCases := []struct {
+		Changes      []diff.Change
+		CurrentData  map[string]string
+		ExpectedData map[string]string
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures a key is added in case it is still absent.
+		{
+			Changes: []diff.Change{
+				{Key: "31000", New: "al9qy/worker:30010"},
+			},
+			CurrentData: map[string]string{
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedData: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31010": "p1l6x/worker:30010",
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures adding a key fails in case another writer added it
+		// since the config map got read.
+		{
+			Changes: []diff.Change{
+				{Key: "31000", New: "al9qy/worker:30010"},
+			},
+			CurrentData: map[string]string{
+				"31000": "p1l6x/worker:30010",
+			},
+			ExpectedData: map[string]string{
+				"31000": "p1l6x/worker:30010",
+			},
+			ErrorMatcher: isInvalidPatch,
+		},
+
+		// Test 2 ensures replacing a key fails in case another writer changed
+		// it since the config map got read.
+		{
+			Changes: []diff.Change{
+				{Key: "31000", Old: "p1l6x/worker:30010", New: "al9qy/worker:30010"},
+			},
+			CurrentData: map[string]string{
+				"31000": "x7a2b/worker:30010",
+			},
+			ExpectedData: map[string]string{
+				"31000": "x7a2b/worker:30010",
+			},
+			ErrorMatcher: isInvalidPatch,
+		},
+
+		// Test 3 ensures removing a key fails in case another writer removed
+		// it since the config map got read.
+		{
+			Changes: []diff.Change{
+				{Key: "31000", Old: "al9qy/worker:30010"},
+			},
+			CurrentData: map[string]string{
+				"31010": "p1l6x/worker:30010",
+			},
+			ExpectedData: map[string]string{
+				"31010": "p1l6x/worker:30010",
+			},
+			ErrorMatcher: isInvalidPatch,
+		},
+	}
+
+	for i, tc := range testCases {
+		patch, err := dataPatch(tc.Changes)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		configMap := &apiv1.ConfigMap{Data: tc.CurrentData}
+		err = applyDataPatch(configMap, patch)
+		if err != nil {
+			if tc.ErrorMatcher == nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			} else if !tc.ErrorMatcher(err) {
+				t.Fatal("test", i, "expected", true, "got", false)
+			}
+		} else if tc.ErrorMatcher != nil {
+			t.Fatal("test", i, "expected", "error", "got", nil)
+		}
+
+		if !reflect.DeepEqual(configMap.Data, tc.ExpectedData) {
+			t.Fatal("test", i, "expected", tc.ExpectedData, "got", configMap.Data)
+		}
+	}
+}
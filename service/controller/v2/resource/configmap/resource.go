@@ -1,12 +1,15 @@
 package configmap
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	apiv1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
 
-	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
 )
 
 const (
@@ -19,6 +22,21 @@ const (
 	//     namespace/service:30011
 	//
 	DataValueFormat = "%s/%s:%d"
+	// NamedDataValueFormat is the format string used to create the config map
+	// data value of named ports. It references the port of the guest cluster
+	// service by name instead of by number. E.g.:
+	//
+	//     namespace/service:https
+	//
+	NamedDataValueFormat = "%s/%s:%s"
+	// ProxyProtocolSuffix is appended to the config map data value of LB ports
+	// passing TLS through. nginx then hands over connections to the guest
+	// cluster ingress controller with the PROXY protocol, so that the client
+	// address is known despite the encrypted traffic. E.g.:
+	//
+	//     namespace/service:30011::PROXY
+	//
+	ProxyProtocolSuffix = "::PROXY"
 	// Name is the identifier of the resource.
 	Name = "configmapv2"
 )
@@ -26,8 +44,17 @@ const (
 // Config represents the configuration used to create a new config map resource.
 type Config struct {
 	// Dependencies.
-	K8sClient kubernetes.Interface
+	Allocator *allocator.Allocator
+	K8sClient clients.Interface
 	Logger    micrologger.Logger
+
+	// Settings.
+
+	// WriteJitter is the maximum of the randomized delay before writes to the
+	// Kubernetes API. Writes to objects shared by many guest clusters are
+	// delayed to break synchronized retries of conflicting writes. Zero
+	// disables the delay.
+	WriteJitter time.Duration
 }
 
 // DefaultConfig provides a default configuration to create a new config map
@@ -35,21 +62,32 @@ type Config struct {
 func DefaultConfig() Config {
 	return Config{
 		// Dependencies.
+		Allocator: nil,
 		K8sClient: nil,
 		Logger:    nil,
+
+		// Settings.
+		WriteJitter: 0,
 	}
 }
 
 // Resource implements the config map resource.
 type Resource struct {
 	// Dependencies.
-	k8sClient kubernetes.Interface
+	allocator *allocator.Allocator
+	k8sClient clients.Interface
 	logger    micrologger.Logger
+
+	// Settings.
+	writeJitter time.Duration
 }
 
 // New creates a new configured config map resource.
 func New(config Config) (*Resource, error) {
 	// Dependencies.
+	if config.Allocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Allocator must not be empty")
+	}
 	if config.K8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
 	}
@@ -59,8 +97,12 @@ func New(config Config) (*Resource, error) {
 
 	newResource := &Resource{
 		// Dependencies.
+		allocator: config.Allocator,
 		k8sClient: config.K8sClient,
 		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		writeJitter: config.WriteJitter,
 	}
 
 	return newResource, nil
@@ -80,14 +122,15 @@ func inConfigMapData(data map[string]string, k, v string) bool {
 	return false
 }
 
-func toCustomObject(v interface{}) (v1alpha1.IngressConfig, error) {
-	customObjectPointer, ok := v.(*v1alpha1.IngressConfig)
-	if !ok {
-		return v1alpha1.IngressConfig{}, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &v1alpha1.IngressConfig{}, v)
+// isReserved returns whether the given config map data key refers to a port
+// reserved for system use. Data of reserved ports is never touched.
+func (r *Resource) isReserved(k string) bool {
+	p, err := strconv.Atoi(k)
+	if err != nil {
+		return false
 	}
-	customObject := *customObjectPointer
 
-	return customObject, nil
+	return r.allocator.IsReserved(p)
 }
 
 func toConfigMap(v interface{}) (*apiv1.ConfigMap, error) {
@@ -102,3 +145,16 @@ func toConfigMap(v interface{}) (*apiv1.ConfigMap, error) {
 
 	return configMaps, nil
 }
+
+func toDataUpdate(v interface{}) (*dataUpdate, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	update, ok := v.(*dataUpdate)
+	if !ok {
+		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &dataUpdate{}, v)
+	}
+
+	return update, nil
+}
@@ -3,36 +3,68 @@ package configmap
 import (
 	"context"
 	"fmt"
+	"strconv"
 
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller"
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/diff"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/jitter"
 )
 
 func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
-	customObject, err := toCustomObject(obj)
+	cc, err := controllercontext.FromContext(ctx)
 	if err != nil {
 		return microerror.Mask(err)
 	}
-	configMapToUpdate, err := toConfigMap(updateChange)
+	update, err := toDataUpdate(updateChange)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	if configMapToUpdate != nil {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "updating the config map data in the Kubernetes API")
+	if update != nil {
+		logging.Debug(ctx, r.logger, "updating the config map data in the Kubernetes API")
 
-		namespace := customObject.Spec.HostCluster.IngressController.Namespace
-		_, err := r.k8sClient.CoreV1().ConfigMaps(namespace).Update(configMapToUpdate)
-		if err != nil {
-			return microerror.Mask(err)
+		jitter.Sleep(ctx, r.writeJitter)
+
+		configMapToUpdate := update.ConfigMap
+		namespace := cc.IngressController.Namespace
+		if update.Replace {
+			_, err := r.k8sClient.UpdateConfigMap(ctx, namespace, configMapToUpdate)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		} else {
+			patch, err := dataPatch(update.Changes)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			_, err = r.k8sClient.PatchConfigMap(ctx, namespace, configMapToUpdate.Name, types.JSONPatchType, patch)
+			if err != nil {
+				return microerror.Mask(err)
+			}
 		}
 
-		r.logger.LogCtx(ctx, "level", "debug", "message", "updated the config map data in the Kubernetes API")
+		cc.Transaction.ConfigMapUpdated(configMapToUpdate.Data)
+		cc.Corrections++
+		correctionsCounter.WithLabelValues(namespace, configMapToUpdate.Name).Inc()
+
+		logging.Debug(ctx, r.logger, "updated the config map data in the Kubernetes API")
 	} else {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "the config map data does not need to be updated from the Kubernetes API")
+		logging.Debug(ctx, r.logger, "the config map data does not need to be updated from the Kubernetes API")
 	}
 
+	cc.ConfigMapApplied = true
+	cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.ConfigMapSynced, conditions.StatusTrue, "", ""))
+
 	return nil
 }
 
@@ -49,6 +81,15 @@ func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentState, desire
 }
 
 func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	// Reserved ports must never be handed to any guest cluster.
+	err = r.allocator.Validate(key.LBPorts(customObject))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
 	currentConfigMap, err := toConfigMap(currentState)
 	if err != nil {
 		return microerror.Mask(err), nil
@@ -58,22 +99,69 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", map[string]string{}, desiredState)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", "finding out which config map items have to be updated")
+	logging.Debug(ctx, r.logger, "finding out which config map items have to be updated")
 
 	var updateState *apiv1.ConfigMap
-	var count int
+	var changes []diff.Change
+	// A JSON patch can only add keys to existing data, so config maps without
+	// any data are updated as a whole.
+	replace := len(currentConfigMap.Data) == 0
 	{
 		updateState = currentConfigMap
 
 		for k, v := range dState {
 			if !inConfigMapData(updateState.Data, k, v) {
+				old, ok := updateState.Data[k]
+				if ok {
+					changes = append(changes, diff.Updated(k, old, v))
+				} else {
+					changes = append(changes, diff.Added(k, v))
+				}
+
 				updateState.Data[k] = v
-				count++
 			}
 		}
+
+		// Entries of disabled protocols written before the protocols got
+		// disabled are removed, as long as they still route to the guest
+		// cluster.
+		for _, p := range key.DisabledProtocolPorts(customObject) {
+			k := strconv.Itoa(p.LBPort)
+			v, ok := updateState.Data[k]
+			if !ok || r.isReserved(k) || !routesTo(customObject, v) {
+				continue
+			}
+
+			changes = append(changes, diff.Removed(k, v))
+			delete(updateState.Data, k)
+		}
+	}
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("found %d config map items that have to be updated", len(changes)))
+	logging.Debug(ctx, r.logger, fmt.Sprintf("computed update patch: %s", diff.Render(changes)))
+
+	// Writing an unchanged config map would only cause needless conflicts with
+	// the other writers of the shared config map.
+	if len(changes) == 0 {
+		return nil, nil
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d config map items that have to be updated", count))
+	update := &dataUpdate{
+		ConfigMap: updateState,
+		Changes:   changes,
+		Replace:   replace,
+	}
+
+	return update, nil
+}
+
+// routesTo returns whether the given config map data value routes to the
+// guest cluster of the given custom object.
+func routesTo(customObject v1alpha1.IngressConfig, v string) bool {
+	value, err := ParseDataValue(v)
+	if err != nil {
+		return false
+	}
 
-	return updateState, nil
+	return value.Namespace == key.ClusterNamespace(customObject) && value.Service == key.ClusterService(customObject)
 }
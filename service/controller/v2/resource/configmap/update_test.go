@@ -8,7 +8,12 @@ import (
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
 )
 
 func Test_Service_newUpdateChange(t *testing.T) {
@@ -117,14 +122,87 @@ func Test_Service_newUpdateChange(t *testing.T) {
 			},
 			ErrorMatcher: nil,
 		},
+
+		// Test 2 ensures entries of disabled protocols are removed, unless they
+		// route to another guest cluster.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						key.DisabledProtocolsAnnotation: "HTTP",
+					},
+				},
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+						IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+							ConfigMap: "ingress-controller",
+							Namespace: "kube-system",
+							Service:   "ingress-controller",
+						},
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+						{
+							IngressPort: 30011,
+							Protocol:    "https",
+							LBPort:      31001,
+						},
+						{
+							IngressPort: 30012,
+							Protocol:    "http",
+							LBPort:      31002,
+						},
+					},
+				},
+			},
+			CurrentState: &apiv1.ConfigMap{
+				Data: map[string]string{
+					"31000": "al9qy/worker:30010",
+					"31001": "al9qy/worker:30011",
+					"31002": "p1l6x/worker:30012",
+				},
+			},
+			DesiredState: map[string]string{
+				"31001": "al9qy/worker:30011",
+			},
+			Expected: &apiv1.ConfigMap{
+				Data: map[string]string{
+					"31001": "al9qy/worker:30011",
+					"31002": "p1l6x/worker:30012",
+				},
+			},
+			ErrorMatcher: nil,
+		},
 	}
 
 	var err error
 	var newResource *Resource
 	{
+		var a *allocator.Allocator
+		a, err = allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
 		c := DefaultConfig()
 
-		c.K8sClient = fake.NewSimpleClientset()
+		c.Allocator = a
+		var k8sClient clients.Interface
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c.K8sClient = k8sClient
 		c.Logger = microloggertest.New()
 
 		newResource, err = New(c)
@@ -141,12 +219,12 @@ func Test_Service_newUpdateChange(t *testing.T) {
 		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
 			t.Fatal("test", i, "expected", true, "got", false)
 		}
-		e, ok := result.(*apiv1.ConfigMap)
+		e, ok := result.(*dataUpdate)
 		if !ok {
 			t.Fatalf("test %d expected %#v got %#v", i, true, false)
 		}
-		if !reflect.DeepEqual(tc.Expected, e) {
-			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, e)
+		if !reflect.DeepEqual(tc.Expected, e.ConfigMap) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, e.ConfigMap)
 		}
 	}
 }
@@ -0,0 +1,124 @@
+package configmap
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxPort is the highest valid TCP port.
+const maxPort = 65535
+
+// DataValue is a parsed config map data value.
+type DataValue struct {
+	Namespace string
+	Service   string
+	// Port is either the number or the name of the port of the service.
+	Port string
+	// ProxyProtocol is whether the value carries the PROXY protocol suffix.
+	ProxyProtocol bool
+}
+
+// IsNamed returns whether the value references the port of the service by
+// name.
+func (v DataValue) IsNamed() bool {
+	_, err := strconv.Atoi(v.Port)
+	return err != nil
+}
+
+// ParseDataValue parses the given config map data value written in either
+// DataValueFormat or NamedDataValueFormat, optionally followed by
+// ProxyProtocolSuffix. The namespace ends at the first slash and the port
+// starts after the last colon, so that namespaces and services containing
+// dots, as accepted by some backends, are parsed as well. E.g.:
+//
+//	namespace/service:30010
+//	team.namespace/service:https::PROXY
+func ParseDataValue(v string) (DataValue, error) {
+	var value DataValue
+
+	if strings.HasSuffix(v, ProxyProtocolSuffix) {
+		v = strings.TrimSuffix(v, ProxyProtocolSuffix)
+		value.ProxyProtocol = true
+	}
+
+	slash := strings.Index(v, "/")
+	colon := strings.LastIndex(v, ":")
+	if slash < 1 || colon < slash+2 || colon == len(v)-1 {
+		return DataValue{}, microerror.Maskf(invalidDataValueError, "expected namespace/service:port, got %q", v)
+	}
+
+	value.Namespace = v[:slash]
+	value.Service = v[slash+1 : colon]
+	value.Port = v[colon+1:]
+
+	return value, nil
+}
+
+// ValidateData returns an error in case any key or value of the given config
+// map data is not strictly safe to be written to the config map of a shared
+// ingress controller. The ingress controller interprets every key as LB port
+// and every value as upstream, so that anything else, e.g. whitespace or
+// separators, could inject configuration affecting other guest clusters. It is
+// used to validate IngressConfigs as well as the desired state before it is
+// written.
+func ValidateData(data map[string]string) error {
+	for k, v := range data {
+		err := ValidateDataKey(k)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		err = ValidateDataValue(v)
+		if err != nil {
+			return microerror.Maskf(invalidDataValueError, "value of LB port %s: %s", k, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// ValidateDataKey returns an error in case the given config map data key is
+// not an LB port in its canonical decimal form, e.g. "30010" but not "030010"
+// or "+30010".
+func ValidateDataKey(k string) error {
+	p, err := strconv.Atoi(k)
+	if err != nil || strconv.Itoa(p) != k || p < 1 || p > maxPort {
+		return microerror.Maskf(invalidDataKeyError, "expected LB port between 1 and %d, got %q", maxPort, k)
+	}
+
+	return nil
+}
+
+// ValidateDataValue returns an error in case the given config map data value
+// cannot be parsed, its namespace or service is not a DNS subdomain, or its
+// port is neither a port number in its canonical decimal form nor a valid port
+// name. Port number zero is accepted, since ingress ports are zero until they
+// are discovered.
+func ValidateDataValue(v string) error {
+	value, err := ParseDataValue(v)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if errs := validation.IsDNS1123Subdomain(value.Namespace); len(errs) != 0 {
+		return microerror.Maskf(invalidDataValueError, "namespace %q %s", value.Namespace, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Subdomain(value.Service); len(errs) != 0 {
+		return microerror.Maskf(invalidDataValueError, "service %q %s", value.Service, strings.Join(errs, ", "))
+	}
+
+	if value.IsNamed() {
+		if errs := validation.IsValidPortName(value.Port); len(errs) != 0 {
+			return microerror.Maskf(invalidDataValueError, "port name %q %s", value.Port, strings.Join(errs, ", "))
+		}
+	} else {
+		p, _ := strconv.Atoi(value.Port)
+		if strconv.Itoa(p) != value.Port || p < 0 || p > maxPort {
+			return microerror.Maskf(invalidDataValueError, "expected port between 0 and %d, got %q", maxPort, value.Port)
+		}
+	}
+
+	return nil
+}
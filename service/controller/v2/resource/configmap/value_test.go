@@ -0,0 +1,145 @@
+package configmap
+
+import (
+	"testing"
+)
+
+func Test_ParseDataValue(t *testing.T) {
+	testCases := []struct {
+		Value        string
+		Expected     DataValue
+		ExpectedName bool
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures values referencing ports by number are parsed.
+		{
+			Value:        "al9qy/worker:30010",
+			Expected:     DataValue{Namespace: "al9qy", Service: "worker", Port: "30010"},
+			ExpectedName: false,
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures values referencing ports by name are parsed together
+		// with the PROXY protocol suffix.
+		{
+			Value:        "al9qy/worker:https::PROXY",
+			Expected:     DataValue{Namespace: "al9qy", Service: "worker", Port: "https", ProxyProtocol: true},
+			ExpectedName: true,
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures namespaces and services containing dots are parsed.
+		{
+			Value:        "team.al9qy/worker.ingress:30010",
+			Expected:     DataValue{Namespace: "team.al9qy", Service: "worker.ingress", Port: "30010"},
+			ExpectedName: false,
+			ErrorMatcher: nil,
+		},
+
+		// Test 3 ensures values lacking the namespace are refused.
+		{
+			Value:        "worker:30010",
+			Expected:     DataValue{},
+			ErrorMatcher: IsInvalidDataValue,
+		},
+
+		// Test 4 ensures values lacking the port are refused.
+		{
+			Value:        "al9qy/worker:",
+			Expected:     DataValue{},
+			ErrorMatcher: IsInvalidDataValue,
+		},
+	}
+
+	for i, tc := range testCases {
+		value, err := ParseDataValue(tc.Value)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if value != tc.Expected {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, value)
+		}
+		if tc.ErrorMatcher == nil && value.IsNamed() != tc.ExpectedName {
+			t.Fatal("test", i, "expected", tc.ExpectedName, "got", value.IsNamed())
+		}
+	}
+}
+
+func Test_ValidateData(t *testing.T) {
+	testCases := []struct {
+		Data         map[string]string
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures values referencing ports by number or name are valid.
+		{
+			Data: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31001": "team.al9qy/worker:https::PROXY",
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures ingress ports which are not discovered yet are valid.
+		{
+			Data:         map[string]string{"31000": "al9qy/worker:0"},
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures keys which are not in their canonical form are
+		// rejected.
+		{
+			Data:         map[string]string{"031000": "al9qy/worker:30010"},
+			ErrorMatcher: IsInvalidDataKey,
+		},
+
+		// Test 3 ensures keys which are no ports are rejected.
+		{
+			Data:         map[string]string{"proxy-body-size": "al9qy/worker:30010"},
+			ErrorMatcher: IsInvalidDataKey,
+		},
+
+		// Test 4 ensures keys out of range are rejected.
+		{
+			Data:         map[string]string{"70000": "al9qy/worker:30010"},
+			ErrorMatcher: IsInvalidDataKey,
+		},
+
+		// Test 5 ensures namespaces containing newlines are rejected.
+		{
+			Data:         map[string]string{"31000": "al9qy\n31001: other/worker:30010"},
+			ErrorMatcher: IsInvalidDataValue,
+		},
+
+		// Test 6 ensures services containing separators are rejected.
+		{
+			Data:         map[string]string{"31000": "al9qy/worker:30010;other:30011"},
+			ErrorMatcher: IsInvalidDataValue,
+		},
+
+		// Test 7 ensures ports which are neither numbers nor port names are
+		// rejected.
+		{
+			Data:         map[string]string{"31000": "al9qy/worker:+30010"},
+			ErrorMatcher: IsInvalidDataValue,
+		},
+
+		// Test 8 ensures ports out of range are rejected.
+		{
+			Data:         map[string]string{"31000": "al9qy/worker:70000"},
+			ErrorMatcher: IsInvalidDataValue,
+		},
+	}
+
+	for i, tc := range testCases {
+		err := ValidateData(tc.Data)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package deleteprotection
+
+import (
+	"context"
+)
+
+// EnsureCreated is a no-op. Delete protection only affects deletions.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	return nil
+}
@@ -0,0 +1,84 @@
+package deleteprotection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+const (
+	// EventReason is the reason of the events raised for blocked deletions.
+	EventReason = "DeleteProtected"
+)
+
+// EnsureDeleted cancels the deletion of IngressConfigs having the delete
+// protection annotation set, so that no other resource acts upon the deletion.
+// The finalizer is kept, which makes the deletion proceed as soon as the
+// annotation got removed.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !key.IsDeleteProtected(customObject) {
+		return nil
+	}
+
+	message := fmt.Sprintf("deletion of ingress config is blocked until annotation %s is removed", key.DeleteProtectionAnnotation)
+
+	logging.Warning(ctx, r.logger, message)
+
+	// Failing to raise the event must not lift the protection, so the error is
+	// only logged.
+	_, err = r.k8sClient.CreateEvent(ctx, customObject.Namespace, r.newEvent(customObject, message))
+	if err != nil {
+		logging.Error(ctx, r.logger, "failed raising event for blocked deletion", err)
+	}
+
+	reconciliationcanceledcontext.SetCanceled(ctx)
+	finalizerskeptcontext.SetKept(ctx)
+	logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+	return nil
+}
+
+func (r *Resource) newEvent(customObject v1alpha1.IngressConfig, message string) *corev1.Event {
+	now := metav1.NewTime(time.Now())
+
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", customObject.Name, now.UnixNano()),
+			Namespace: customObject.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      v1alpha1.SchemeGroupVersion.String(),
+			Kind:            "IngressConfig",
+			Name:            customObject.Name,
+			Namespace:       customObject.Namespace,
+			ResourceVersion: customObject.ResourceVersion,
+			UID:             customObject.UID,
+		},
+		Reason:  EventReason,
+		Message: message,
+		Source: corev1.EventSource{
+			Component: r.projectName,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           corev1.EventTypeWarning,
+	}
+
+	return event
+}
@@ -0,0 +1,109 @@
+package deleteprotection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+func Test_DeleteProtection_EnsureDeleted(t *testing.T) {
+	testCases := []struct {
+		Annotations    map[string]string
+		ExpectedBlock  bool
+		ExpectedEvents int
+	}{
+		// Test 0 ensures the deletion of an unprotected ingress config proceeds.
+		{
+			Annotations:    nil,
+			ExpectedBlock:  false,
+			ExpectedEvents: 0,
+		},
+
+		// Test 1 ensures the deletion of a protected ingress config is blocked and
+		// an event is raised.
+		{
+			Annotations: map[string]string{
+				key.DeleteProtectionAnnotation: "true",
+			},
+			ExpectedBlock:  true,
+			ExpectedEvents: 1,
+		},
+
+		// Test 2 ensures only the value "true" enables the protection.
+		{
+			Annotations: map[string]string{
+				key.DeleteProtectionAnnotation: "false",
+			},
+			ExpectedBlock:  false,
+			ExpectedEvents: 0,
+		},
+	}
+
+	for i, tc := range testCases {
+		k8sClient := fake.NewSimpleClientset()
+
+		var newResource *Resource
+		{
+			clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+
+			c.ProjectName = "ingress-operator"
+
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		customObject := &v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: tc.Annotations,
+				Name:        "al9qy",
+				Namespace:   "default",
+			},
+		}
+
+		ctx := context.Background()
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+		ctx = finalizerskeptcontext.NewContext(ctx, make(chan struct{}))
+
+		err := newResource.EnsureDeleted(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		if reconciliationcanceledcontext.IsCanceled(ctx) != tc.ExpectedBlock {
+			t.Fatal("test", i, "expected", tc.ExpectedBlock, "got", reconciliationcanceledcontext.IsCanceled(ctx))
+		}
+		if finalizerskeptcontext.IsKept(ctx) != tc.ExpectedBlock {
+			t.Fatal("test", i, "expected", tc.ExpectedBlock, "got", finalizerskeptcontext.IsKept(ctx))
+		}
+
+		list, err := k8sClient.CoreV1().Events("default").List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if len(list.Items) != tc.ExpectedEvents {
+			t.Fatal("test", i, "expected", tc.ExpectedEvents, "got", len(list.Items))
+		}
+		if tc.ExpectedEvents != 0 && list.Items[0].Reason != EventReason {
+			t.Fatal("test", i, "expected", EventReason, "got", list.Items[0].Reason)
+		}
+	}
+}
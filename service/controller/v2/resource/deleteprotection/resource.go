@@ -0,0 +1,85 @@
+// Package deleteprotection implements a resource guarding IngressConfigs
+// annotated with the delete protection annotation from accidental deletion.
+// The deletion of a protected IngressConfig is not processed and its
+// finalizer is kept until the annotation got removed.
+package deleteprotection
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "deleteprotectionv2"
+)
+
+// Config represents the configuration used to create a new delete protection
+// resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// ProjectName is the name of the operator reported as the source of the
+	// events raised for blocked deletions.
+	ProjectName string
+}
+
+// DefaultConfig provides a default configuration to create a new delete
+// protection resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		ProjectName: "",
+	}
+}
+
+// Resource implements the delete protection resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	projectName string
+}
+
+// New creates a new configured delete protection resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	// Settings.
+	if config.ProjectName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.ProjectName must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		projectName: config.ProjectName,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
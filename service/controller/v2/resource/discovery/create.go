@@ -0,0 +1,204 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureCreated discovers the ingress ports of the given IngressConfig. In
+// case the ingress port of a protocol cannot be discovered the one of the spec
+// is used. In case the spec does not define one either, the reconciliation is
+// canceled, since traffic could not be forwarded anywhere.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	missing, err := r.discover(ctx, cc, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if len(missing) != 0 {
+		logging.Warning(ctx, r.logger, fmt.Sprintf("cannot discover ingress ports of protocols %s", strings.Join(missing, ", ")))
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+		return nil
+	}
+
+	unresolved, err := r.resolveNames(ctx, cc, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if len(unresolved) != 0 {
+		logging.Warning(ctx, r.logger, fmt.Sprintf("cannot resolve port names of LB ports %s", strings.Join(unresolved, ", ")))
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+	}
+
+	return nil
+}
+
+// discover puts the discovered ingress ports of the given custom object into
+// the given controller context. It returns the protocols neither having a
+// discovered ingress port nor one in the spec.
+func (r *Resource) discover(ctx context.Context, cc *controllercontext.Context, customObject v1alpha1.IngressConfig) ([]string, error) {
+	selector := key.IngressPortSelector(customObject)
+	if selector == "" {
+		return nil, nil
+	}
+
+	namespace := key.ClusterNamespace(customObject)
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("discovering ingress ports from services in namespace %s matching %q", namespace, selector))
+
+	list, err := r.k8sClient.ListServices(ctx, namespace, selector)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	// Services are ordered by name, so that the same service wins every time in
+	// case the selector matches more than one.
+	services := list.Items
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	if len(services) > 1 {
+		logging.Warning(ctx, r.logger, fmt.Sprintf("found %d services matching %q, using the first one having a matching port", len(services), selector))
+	}
+
+	nodePorts := map[string]int{}
+	for _, s := range services {
+		for _, p := range s.Spec.Ports {
+			name := strings.ToLower(p.Name)
+			_, ok := nodePorts[name]
+			if ok || p.NodePort == 0 {
+				continue
+			}
+			nodePorts[name] = int(p.NodePort)
+		}
+	}
+
+	var missing []string
+	for _, p := range key.ProtocolPorts(customObject) {
+		nodePort, ok := nodePorts[key.Protocol(p)]
+		if ok {
+			if cc.IngressPorts == nil {
+				cc.IngressPorts = map[int]int{}
+			}
+			cc.IngressPorts[p.LBPort] = nodePort
+
+			logging.Debug(ctx, r.logger, fmt.Sprintf("discovered ingress port %d of protocol %s", nodePort, p.Protocol))
+			continue
+		}
+
+		if p.IngressPort != 0 {
+			logging.Debug(ctx, r.logger, fmt.Sprintf("did not discover ingress port of protocol %s, using ingress port %d of the spec", p.Protocol, p.IngressPort))
+			continue
+		}
+
+		missing = append(missing, p.Protocol)
+	}
+
+	return missing, nil
+}
+
+// resolveNames puts the names of the guest cluster service ports of the named
+// ports of the given custom object into the given controller context. The
+// service port matching the ingress port must have a valid name, since the
+// host cluster ingress controller would not be able to resolve it otherwise.
+// It returns the LB ports whose names cannot be resolved.
+func (r *Resource) resolveNames(ctx context.Context, cc *controllercontext.Context, customObject v1alpha1.IngressConfig) ([]string, error) {
+	// Invalid annotations are refused by the validation resource, so that they
+	// never reach this point during reconciliation.
+	named, _ := key.NamedPorts(customObject)
+	if len(named) == 0 {
+		return nil, nil
+	}
+
+	namespace := key.ClusterNamespace(customObject)
+	name := key.ClusterService(customObject)
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("resolving port names from service %s/%s", namespace, name))
+
+	var ports []corev1.ServicePort
+	{
+		service, err := r.k8sClient.GetService(ctx, namespace, name)
+		if clients.IsNotFound(err) {
+			logging.Debug(ctx, r.logger, fmt.Sprintf("did not find service %s/%s", namespace, name))
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		} else {
+			ports = service.Spec.Ports
+		}
+	}
+
+	var unresolved []string
+	for _, p := range key.ProtocolPorts(customObject) {
+		if !inPorts(named, p.LBPort) {
+			continue
+		}
+
+		portName := portNameOf(ports, cc.IngressPort(p))
+		if portName == "" {
+			unresolved = append(unresolved, strconv.Itoa(p.LBPort))
+			continue
+		}
+
+		if cc.PortNames == nil {
+			cc.PortNames = map[int]string{}
+		}
+		cc.PortNames[p.LBPort] = portName
+
+		logging.Debug(ctx, r.logger, fmt.Sprintf("resolved port name %q of LB port %d", portName, p.LBPort))
+	}
+
+	return unresolved, nil
+}
+
+func inPorts(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+
+	return false
+}
+
+// portNameOf returns the name of the given service port, which is empty in
+// case the port does not exist or its name is no valid port name.
+func portNameOf(ports []corev1.ServicePort, port int) string {
+	for _, p := range ports {
+		if int(p.Port) != port {
+			continue
+		}
+		if len(validation.IsValidPortName(p.Name)) != 0 {
+			return ""
+		}
+
+		return p.Name
+	}
+
+	return ""
+}
@@ -0,0 +1,191 @@
+package discovery
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+func Test_Discovery_EnsureCreated(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app": "nginx-ingress-controller",
+			},
+			Name:      "nginx-ingress-controller",
+			Namespace: "al9qy",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", NodePort: 32080, Port: 80},
+				{Name: "https", NodePort: 32443, Port: 443},
+			},
+		},
+	}
+
+	worker := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "worker",
+			Namespace: "al9qy",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 30010},
+				{Name: "https", Port: 30011},
+				{Name: "", Port: 30012},
+			},
+		},
+	}
+
+	testCases := []struct {
+		Selector             string
+		NamedPorts           string
+		ProtocolPorts        []v1alpha1.IngressConfigSpecProtocolPort
+		ExpectedIngressPorts map[int]int
+		ExpectedPortNames    map[int]string
+		ExpectedCancel       bool
+	}{
+		// Test 0 ensures nothing is discovered without the selector annotation.
+		{
+			Selector: "",
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+			},
+			ExpectedIngressPorts: nil,
+			ExpectedCancel:       false,
+		},
+
+		// Test 1 ensures the NodePorts of the matching service are discovered by
+		// protocol.
+		{
+			Selector: "app=nginx-ingress-controller",
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 0, LBPort: 31000, Protocol: "http"},
+				{IngressPort: 30011, LBPort: 31001, Protocol: "HTTPS"},
+			},
+			ExpectedIngressPorts: map[int]int{31000: 32080, 31001: 32443},
+			ExpectedCancel:       false,
+		},
+
+		// Test 2 ensures the ingress port of the spec is used in case it cannot
+		// be discovered.
+		{
+			Selector: "app=nginx-ingress-controller",
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 0, LBPort: 31000, Protocol: "http"},
+				{IngressPort: 30012, LBPort: 31002, Protocol: "udp"},
+			},
+			ExpectedIngressPorts: map[int]int{31000: 32080},
+			ExpectedCancel:       false,
+		},
+
+		// Test 3 ensures the reconciliation is canceled in case an ingress port
+		// can neither be discovered nor is defined in the spec.
+		{
+			Selector: "app=unknown",
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 0, LBPort: 31000, Protocol: "http"},
+			},
+			ExpectedIngressPorts: nil,
+			ExpectedCancel:       true,
+		},
+
+		// Test 4 ensures the port names of named ports are resolved from the
+		// guest cluster service by ingress port.
+		{
+			Selector:   "",
+			NamedPorts: "31001",
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+				{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+			},
+			ExpectedIngressPorts: nil,
+			ExpectedPortNames:    map[int]string{31001: "https"},
+			ExpectedCancel:       false,
+		},
+
+		// Test 5 ensures the reconciliation is canceled in case the guest cluster
+		// service port of a named port has no name.
+		{
+			Selector:   "",
+			NamedPorts: "31002",
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30012, LBPort: 31002, Protocol: "tcp"},
+			},
+			ExpectedIngressPorts: nil,
+			ExpectedPortNames:    nil,
+			ExpectedCancel:       true,
+		},
+	}
+
+	for i, tc := range testCases {
+		var newResource *Resource
+		{
+			clientsInterface, err := clients.New(clients.Config{K8sClient: fake.NewSimpleClientset(service, worker)})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		customObject := &v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					key.IngressPortSelectorAnnotation: tc.Selector,
+					key.NamedPortsAnnotation:          tc.NamedPorts,
+				},
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+					ID:        "al9qy",
+					Namespace: "al9qy",
+					Service:   "worker",
+				},
+				ProtocolPorts: tc.ProtocolPorts,
+			},
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+		err := newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		if reconciliationcanceledcontext.IsCanceled(ctx) != tc.ExpectedCancel {
+			t.Fatal("test", i, "expected", tc.ExpectedCancel, "got", reconciliationcanceledcontext.IsCanceled(ctx))
+		}
+
+		cc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(tc.ExpectedIngressPorts, cc.IngressPorts) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedIngressPorts, cc.IngressPorts)
+		}
+		if !reflect.DeepEqual(tc.ExpectedPortNames, cc.PortNames) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedPortNames, cc.PortNames)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package discovery
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureDeleted discovers the ingress ports of the given IngressConfig, so the
+// config map and service resources find the data to delete. Ingress ports
+// which cannot be discovered do not block the deletion.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, err = r.discover(ctx, cc, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+// Package discovery implements a resource discovering the ingress ports of
+// IngressConfigs having the ingress port selector annotation. The ingress
+// ports are looked up from the NodePorts of the Service of the guest cluster
+// ingress controller in the guest cluster namespace, whose ports are named
+// like the protocols of the IngressConfig. Discovered ingress ports are put
+// into the controller context, so the ledger, config map and service resources
+// use them instead of the ingress ports of the spec. IngressConfigs are
+// reconciled again on every resync of the informer, which keeps the config
+// map in sync when the guest cluster ingress controller changes its NodePorts.
+// The resource also resolves the port names of named ports from the guest
+// cluster service, so that the config map references them by name.
+package discovery
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "discoveryv2"
+)
+
+// Config represents the configuration used to create a new discovery
+// resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+}
+
+// DefaultConfig provides a default configuration to create a new discovery
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+	}
+}
+
+// Resource implements the discovery resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+}
+
+// New creates a new configured discovery resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger.With("resource", Name),
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -0,0 +1,145 @@
+package ingresscontroller
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureCreated makes sure the config maps, the service and the deployment of
+// the dedicated ingress controller exist. Existing config maps and services are
+// not touched, because their data is managed by the config map and service
+// resources, except for the labels and annotations propagated from the
+// IngressConfig and the source ranges of the service. The deployment is
+// updated in case its image changed.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "ensuring dedicated ingress controller exists")
+
+	for _, c := range r.newConfigMaps(customObject) {
+		current, err := r.k8sClient.GetConfigMap(ctx, c.Namespace, c.Name)
+		if clients.IsNotFound(err) {
+			_, err = r.k8sClient.CreateConfigMap(ctx, c.Namespace, c)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else if syncObjectMeta(&current.ObjectMeta, c.ObjectMeta) {
+			_, err = r.k8sClient.UpdateConfigMap(ctx, c.Namespace, current)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	{
+		s := r.newService(customObject)
+
+		// The service of the dedicated ingress controller only serves the guest
+		// cluster, so it restricts its clients in case all LB ports do.
+		sourceRanges, err := key.ServiceSourceRanges(customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		s.Spec.LoadBalancerSourceRanges = sourceRanges
+
+		current, err := r.k8sClient.GetService(ctx, s.Namespace, s.Name)
+		if clients.IsNotFound(err) {
+			_, err = r.k8sClient.CreateService(ctx, s.Namespace, s)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else {
+			changed := syncObjectMeta(&current.ObjectMeta, s.ObjectMeta)
+			if !reflect.DeepEqual(current.Spec.LoadBalancerSourceRanges, s.Spec.LoadBalancerSourceRanges) {
+				current.Spec.LoadBalancerSourceRanges = s.Spec.LoadBalancerSourceRanges
+				changed = true
+			}
+
+			if changed {
+				_, err = r.k8sClient.UpdateService(ctx, s.Namespace, current)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+			}
+		}
+	}
+
+	{
+		d := r.newDeployment(customObject)
+
+		current, err := r.k8sClient.GetDeployment(ctx, d.Namespace, d.Name)
+		if clients.IsNotFound(err) {
+			_, err = r.k8sClient.CreateDeployment(ctx, d.Namespace, d)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else {
+			changed := syncObjectMeta(&current.ObjectMeta, d.ObjectMeta)
+			// A deployment without containers got mangled by someone else and
+			// is repaired with the desired containers.
+			if len(current.Spec.Template.Spec.Containers) == 0 {
+				current.Spec.Template.Spec.Containers = d.Spec.Template.Spec.Containers
+				changed = true
+			} else if current.Spec.Template.Spec.Containers[0].Image != r.image {
+				current.Spec.Template.Spec.Containers[0].Image = r.image
+				changed = true
+			}
+
+			if changed {
+				_, err = r.k8sClient.UpdateDeployment(ctx, d.Namespace, current)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+			}
+		}
+	}
+
+	logging.Debug(ctx, r.logger, "ensured dedicated ingress controller exists")
+
+	return nil
+}
+
+// syncObjectMeta adds the labels and annotations of the desired object meta to
+// the current object meta. The returned bool expresses whether the current
+// object meta changed. Labels and annotations not being desired are kept,
+// since they might be managed by someone else.
+func syncObjectMeta(current *metav1.ObjectMeta, desired metav1.ObjectMeta) bool {
+	var changed bool
+
+	for k, v := range desired.Labels {
+		if current.Labels[k] != v {
+			if current.Labels == nil {
+				current.Labels = map[string]string{}
+			}
+			current.Labels[k] = v
+			changed = true
+		}
+	}
+	for k, v := range desired.Annotations {
+		if current.Annotations[k] != v {
+			if current.Annotations == nil {
+				current.Annotations = map[string]string{}
+			}
+			current.Annotations[k] = v
+			changed = true
+		}
+	}
+
+	return changed
+}
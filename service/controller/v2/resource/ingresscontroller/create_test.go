@@ -0,0 +1,314 @@
+package ingresscontroller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+func Test_IngressController_EnsureCreated(t *testing.T) {
+	customObject := &v1alpha1.IngressConfig{
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        "al9qy",
+				Namespace: "al9qy",
+				Service:   "worker",
+			},
+		},
+	}
+
+	var err error
+
+	k8sClient := fake.NewSimpleClientset()
+
+	var newResource *Resource
+	{
+		var clientsInterface clients.Interface
+		clientsInterface, err = clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := DefaultConfig()
+
+		c.K8sClient = clientsInterface
+		c.Logger = microloggertest.New()
+
+		c.Image = "quay.io/giantswarm/nginx-ingress-controller:0.12.0"
+		c.Namespace = "kube-system"
+		c.ServiceAccount = "nginx-ingress-controller"
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	// Ensuring the dedicated ingress controller twice verifies the resource is
+	// idempotent.
+	for i := 0; i < 2; i++ {
+		err = newResource.EnsureCreated(context.TODO(), customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+	}
+
+	for _, n := range []string{"nginx-ingress-controller-al9qy", "nginx-ingress-controller-al9qy-tcp-services", "nginx-ingress-controller-al9qy-stream-snippets"} {
+		_, err := k8sClient.CoreV1().ConfigMaps("kube-system").Get(n, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	_, err = k8sClient.CoreV1().Services("kube-system").Get("nginx-ingress-controller-al9qy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	d, err := k8sClient.AppsV1().Deployments("kube-system").Get("nginx-ingress-controller-al9qy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if d.Spec.Template.Spec.Containers[0].Image != "quay.io/giantswarm/nginx-ingress-controller:0.12.0" {
+		t.Fatal("expected", "quay.io/giantswarm/nginx-ingress-controller:0.12.0", "got", d.Spec.Template.Spec.Containers[0].Image)
+	}
+
+	// A deployment without containers is repaired instead of failing the
+	// reconciliation.
+	d.Spec.Template.Spec.Containers = nil
+	_, err = k8sClient.AppsV1().Deployments("kube-system").Update(d)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = newResource.EnsureCreated(context.TODO(), customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	d, err = k8sClient.AppsV1().Deployments("kube-system").Get("nginx-ingress-controller-al9qy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(d.Spec.Template.Spec.Containers) != 1 {
+		t.Fatal("expected", 1, "got", len(d.Spec.Template.Spec.Containers))
+	}
+	if d.Spec.Template.Spec.Containers[0].Image != "quay.io/giantswarm/nginx-ingress-controller:0.12.0" {
+		t.Fatal("expected", "quay.io/giantswarm/nginx-ingress-controller:0.12.0", "got", d.Spec.Template.Spec.Containers[0].Image)
+	}
+
+	err = newResource.EnsureDeleted(context.TODO(), customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	_, err = k8sClient.AppsV1().Deployments("kube-system").Get("nginx-ingress-controller-al9qy", metav1.GetOptions{})
+	if err == nil {
+		t.Fatal("expected", "not found error", "got", nil)
+	}
+}
+
+func Test_IngressController_EnsureCreated_Propagation(t *testing.T) {
+	customObject := &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"giantswarm.io/pipeline": "testing",
+				"unrelated":              "annotation",
+			},
+			Labels: map[string]string{
+				"giantswarm.io/customer":    "acme",
+				"giantswarm.io/environment": "staging",
+				"unrelated":                 "label",
+			},
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        "al9qy",
+				Namespace: "al9qy",
+				Service:   "worker",
+			},
+		},
+	}
+
+	var err error
+
+	k8sClient := fake.NewSimpleClientset()
+
+	var newResource *Resource
+	{
+		var clientsInterface clients.Interface
+		clientsInterface, err = clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := DefaultConfig()
+
+		c.K8sClient = clientsInterface
+		c.Logger = microloggertest.New()
+
+		c.Image = "quay.io/giantswarm/nginx-ingress-controller:0.12.0"
+		c.Namespace = "kube-system"
+		c.PropagatedAnnotations = []string{"giantswarm.io/pipeline"}
+		c.PropagatedLabels = []string{"giantswarm.io/customer", "giantswarm.io/environment"}
+		c.ServiceAccount = "nginx-ingress-controller"
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	err = newResource.EnsureCreated(context.TODO(), customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Changing the labels of the IngressConfig verifies the propagated labels of
+	// existing objects are kept in sync.
+	customObject.Labels["giantswarm.io/environment"] = "production"
+
+	err = newResource.EnsureCreated(context.TODO(), customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	var metas []metav1.ObjectMeta
+	{
+		for _, n := range []string{"nginx-ingress-controller-al9qy", "nginx-ingress-controller-al9qy-tcp-services"} {
+			c, err := k8sClient.CoreV1().ConfigMaps("kube-system").Get(n, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			metas = append(metas, c.ObjectMeta)
+		}
+
+		s, err := k8sClient.CoreV1().Services("kube-system").Get("nginx-ingress-controller-al9qy", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		metas = append(metas, s.ObjectMeta)
+
+		if _, ok := s.Spec.Selector["giantswarm.io/environment"]; ok {
+			t.Fatal("expected", "propagated label not to be selected", "got", s.Spec.Selector)
+		}
+
+		d, err := k8sClient.AppsV1().Deployments("kube-system").Get("nginx-ingress-controller-al9qy", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		metas = append(metas, d.ObjectMeta)
+	}
+
+	for i, m := range metas {
+		if m.Labels["giantswarm.io/customer"] != "acme" {
+			t.Fatal("test", i, "expected", "acme", "got", m.Labels["giantswarm.io/customer"])
+		}
+		if m.Labels["giantswarm.io/environment"] != "production" {
+			t.Fatal("test", i, "expected", "production", "got", m.Labels["giantswarm.io/environment"])
+		}
+		if m.Labels["app"] == "" {
+			t.Fatal("test", i, "expected", "app label", "got", m.Labels)
+		}
+		if _, ok := m.Labels["unrelated"]; ok {
+			t.Fatal("test", i, "expected", "unrelated label not to be propagated", "got", m.Labels)
+		}
+		if m.Annotations["giantswarm.io/pipeline"] != "testing" {
+			t.Fatal("test", i, "expected", "testing", "got", m.Annotations["giantswarm.io/pipeline"])
+		}
+		if _, ok := m.Annotations["unrelated"]; ok {
+			t.Fatal("test", i, "expected", "unrelated annotation not to be propagated", "got", m.Annotations)
+		}
+	}
+}
+
+// Test_IngressController_EnsureCreated_SourceRanges ensures the service of the
+// dedicated ingress controller is restricted to the source ranges of the LB
+// ports as long as all of them are restricted.
+func Test_IngressController_EnsureCreated_SourceRanges(t *testing.T) {
+	customObject := &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				key.SourceRangesAnnotation: "31000=10.0.0.0/8,31001=192.168.0.0/16",
+			},
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        "al9qy",
+				Namespace: "al9qy",
+				Service:   "worker",
+			},
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+				{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+			},
+		},
+	}
+
+	k8sClient := fake.NewSimpleClientset()
+
+	var newResource *Resource
+	{
+		clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := DefaultConfig()
+
+		c.K8sClient = clientsInterface
+		c.Logger = microloggertest.New()
+
+		c.Image = "quay.io/giantswarm/nginx-ingress-controller:0.12.0"
+		c.Namespace = "kube-system"
+		c.ServiceAccount = "nginx-ingress-controller"
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	testCases := []struct {
+		SourceRanges         string
+		ExpectedSourceRanges []string
+	}{
+		// Test 0 ensures the service is created with the union of the source
+		// ranges.
+		{
+			SourceRanges:         "31000=10.0.0.0/8,31001=192.168.0.0/16",
+			ExpectedSourceRanges: []string{"10.0.0.0/8", "192.168.0.0/16"},
+		},
+		// Test 1 ensures the restriction of the service is lifted in case an LB
+		// port is not restricted anymore.
+		{
+			SourceRanges:         "31000=10.0.0.0/8",
+			ExpectedSourceRanges: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject.Annotations[key.SourceRangesAnnotation] = tc.SourceRanges
+
+		err := newResource.EnsureCreated(context.TODO(), customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		s, err := k8sClient.CoreV1().Services("kube-system").Get("nginx-ingress-controller-al9qy", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(s.Spec.LoadBalancerSourceRanges, tc.ExpectedSourceRanges) {
+			t.Fatal("test", i, "expected", tc.ExpectedSourceRanges, "got", s.Spec.LoadBalancerSourceRanges)
+		}
+	}
+}
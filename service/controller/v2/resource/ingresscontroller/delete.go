@@ -0,0 +1,82 @@
+package ingresscontroller
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/resourcecanceledcontext"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/deletiondelay"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureDeleted removes the dedicated ingress controller. The same way the
+// config map and service resources delay the deletion of their data, the
+// dedicated ingress controller is kept as long as there are pods in the guest
+// cluster namespace, in order to still be able to connect to the guest cluster
+// API via ingress while draining nodes.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	{
+		n := key.ClusterNamespace(customObject)
+		list, err := r.k8sClient.ListPods(ctx, n)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if len(list.Items) != 0 {
+			deletiondelay.Wait(customObject, list.Items)
+			logging.Debug(ctx, r.logger, "cannot finish deletion of namespace due to existing pods")
+			resourcecanceledcontext.SetCanceled(ctx)
+			finalizerskeptcontext.SetKept(ctx)
+			logging.Debug(ctx, r.logger, "canceling resource for custom object")
+
+			return nil
+		}
+
+		deletiondelay.Done(customObject)
+	}
+
+	logging.Debug(ctx, r.logger, "deleting dedicated ingress controller")
+
+	{
+		d := r.newDeployment(customObject)
+
+		err := r.k8sClient.DeleteDeployment(ctx, d.Namespace, d.Name)
+		if clients.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	{
+		s := r.newService(customObject)
+
+		err := r.k8sClient.DeleteService(ctx, s.Namespace, s.Name)
+		if clients.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	for _, c := range r.newConfigMaps(customObject) {
+		err := r.k8sClient.DeleteConfigMap(ctx, c.Namespace, c.Name)
+		if clients.IsNotFound(err) {
+			// fall through
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	logging.Debug(ctx, r.logger, "deleted dedicated ingress controller")
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package ingresscontroller
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+const (
+	labelApp     = "app"
+	labelCluster = "giantswarm.io/cluster"
+)
+
+func (r *Resource) newLabels(customObject v1alpha1.IngressConfig) map[string]string {
+	return map[string]string{
+		labelApp:     key.DedicatedIngressControllerName(customObject),
+		labelCluster: key.ClusterID(customObject),
+	}
+}
+
+// newObjectMeta returns the object meta of the objects of the dedicated
+// ingress controller. On top of the labels selecting the dedicated ingress
+// controller, the object meta carries the labels and annotations propagated
+// from the IngressConfig. The propagated labels are never used in selectors,
+// since they may change over time.
+func (r *Resource) newObjectMeta(customObject v1alpha1.IngressConfig, name string) metav1.ObjectMeta {
+	labels := key.PropagatedLabels(customObject, r.propagatedLabels)
+	for k, v := range r.newLabels(customObject) {
+		labels[k] = v
+	}
+
+	var annotations map[string]string
+	if len(r.propagatedAnnotations) != 0 {
+		annotations = key.PropagatedAnnotations(customObject, r.propagatedAnnotations)
+	}
+
+	return metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   r.namespace,
+		Labels:      labels,
+		Annotations: annotations,
+	}
+}
+
+// newConfigMaps returns the main config map, the TCP services config map and
+// the stream snippets config map of the dedicated ingress controller. Note that
+// the data of the TCP services config map is managed by the config map
+// resource and the data of the stream snippets config map is managed by the
+// port settings resource.
+func (r *Resource) newConfigMaps(customObject v1alpha1.IngressConfig) []*apiv1.ConfigMap {
+	configMaps := []*apiv1.ConfigMap{
+		{
+			ObjectMeta: r.newObjectMeta(customObject, key.DedicatedIngressControllerName(customObject)),
+			Data:       map[string]string{},
+		},
+		{
+			ObjectMeta: r.newObjectMeta(customObject, key.DedicatedIngressControllerTCPServicesName(customObject)),
+			Data:       map[string]string{},
+		},
+		{
+			ObjectMeta: r.newObjectMeta(customObject, key.DedicatedIngressControllerStreamSnippetsName(customObject)),
+			Data:       map[string]string{},
+		},
+	}
+
+	return configMaps
+}
+
+func (r *Resource) newDeployment(customObject v1alpha1.IngressConfig) *appsv1.Deployment {
+	name := key.DedicatedIngressControllerName(customObject)
+	replicas := int32(Replicas)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: r.newObjectMeta(customObject, name),
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: r.newLabels(customObject),
+			},
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: r.newLabels(customObject),
+				},
+				Spec: apiv1.PodSpec{
+					ServiceAccountName: r.serviceAccount,
+					Containers: []apiv1.Container{
+						{
+							Name:  "nginx-ingress-controller",
+							Image: r.image,
+							Args: []string{
+								"/nginx-ingress-controller",
+								fmt.Sprintf("--configmap=%s/%s", r.namespace, name),
+								fmt.Sprintf("--tcp-services-configmap=%s/%s", r.namespace, key.DedicatedIngressControllerTCPServicesName(customObject)),
+								fmt.Sprintf("--watch-namespace=%s", key.ClusterNamespace(customObject)),
+								fmt.Sprintf("--ingress-class=%s", name),
+								fmt.Sprintf("--election-id=%s", name),
+								fmt.Sprintf("--healthz-port=%d", HealthzPort),
+							},
+							Env: []apiv1.EnvVar{
+								{
+									Name: "POD_NAME",
+									ValueFrom: &apiv1.EnvVarSource{
+										FieldRef: &apiv1.ObjectFieldSelector{
+											FieldPath: "metadata.name",
+										},
+									},
+								},
+								{
+									Name: "POD_NAMESPACE",
+									ValueFrom: &apiv1.EnvVarSource{
+										FieldRef: &apiv1.ObjectFieldSelector{
+											FieldPath: "metadata.namespace",
+										},
+									},
+								},
+							},
+							Ports: []apiv1.ContainerPort{
+								{
+									Name:          "healthz",
+									ContainerPort: HealthzPort,
+								},
+							},
+							ReadinessProbe: &apiv1.Probe{
+								Handler: apiv1.Handler{
+									HTTPGet: &apiv1.HTTPGetAction{
+										Path: "/healthz",
+										Port: intstr.FromInt(HealthzPort),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return deployment
+}
+
+// newService returns the service of the dedicated ingress controller. The
+// service is created with the health check port only, because a service
+// cannot be created without any port. The ports of the guest cluster are
+// managed by the service resource.
+func (r *Resource) newService(customObject v1alpha1.IngressConfig) *apiv1.Service {
+	service := &apiv1.Service{
+		ObjectMeta: r.newObjectMeta(customObject, key.DedicatedIngressControllerName(customObject)),
+		Spec: apiv1.ServiceSpec{
+			Type:     apiv1.ServiceTypeNodePort,
+			Selector: r.newLabels(customObject),
+			Ports: []apiv1.ServicePort{
+				{
+					Name:       "healthz",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       HealthzPort,
+					TargetPort: intstr.FromInt(HealthzPort),
+				},
+			},
+		},
+	}
+
+	return service
+}
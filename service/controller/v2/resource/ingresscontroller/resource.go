@@ -0,0 +1,122 @@
+// Package ingresscontroller implements a resource provisioning a dedicated
+// nginx ingress controller per guest cluster in the host cluster. The
+// Deployment, Service and config maps of the dedicated ingress controller are
+// fully lifecycle managed based on the IngressConfig. The service ports and
+// TCP services of the dedicated ingress controller are managed by the service
+// and config map resources, the same way they are managed for the shared
+// ingress controller.
+package ingresscontroller
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// HealthzPort is the port the dedicated ingress controller serves its health
+	// checks on.
+	HealthzPort = 10254
+	// Name is the identifier of the resource.
+	Name = "ingresscontrollerv2"
+	// Replicas is the number of pods each dedicated ingress controller runs.
+	Replicas = 2
+)
+
+// Config represents the configuration used to create a new ingress controller
+// resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// Image is the container image used for dedicated ingress controllers.
+	Image string
+	// Namespace is the host cluster namespace dedicated ingress controllers are
+	// provisioned in.
+	Namespace string
+	// PropagatedAnnotations are the annotation keys propagated from the
+	// IngressConfig to the objects of the dedicated ingress controller.
+	PropagatedAnnotations []string
+	// PropagatedLabels are the label keys propagated from the IngressConfig to
+	// the objects of the dedicated ingress controller.
+	PropagatedLabels []string
+	// ServiceAccount is the name of the service account in Namespace used by the
+	// pods of dedicated ingress controllers.
+	ServiceAccount string
+}
+
+// DefaultConfig provides a default configuration to create a new ingress
+// controller resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		Image:                 "",
+		Namespace:             "",
+		PropagatedAnnotations: nil,
+		PropagatedLabels:      nil,
+		ServiceAccount:        "",
+	}
+}
+
+// Resource implements the ingress controller resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	image                 string
+	namespace             string
+	propagatedAnnotations []string
+	propagatedLabels      []string
+	serviceAccount        string
+}
+
+// New creates a new configured ingress controller resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	// Settings.
+	if config.Image == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.Image must not be empty")
+	}
+	if config.Namespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.Namespace must not be empty")
+	}
+	if config.ServiceAccount == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.ServiceAccount must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		image:                 config.Image,
+		namespace:             config.Namespace,
+		propagatedAnnotations: config.PropagatedAnnotations,
+		propagatedLabels:      config.PropagatedLabels,
+		serviceAccount:        config.ServiceAccount,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -0,0 +1,185 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+// EnsureCreated records the LB ports of the guest cluster in the ledger. In
+// case an LB port is allocated to another guest cluster the reconciliation is
+// canceled, so the other guest cluster's data is never overwritten. The same
+// applies in case an LB port released by another guest cluster still cools
+// off and in case the ledger got written by a newer operator version.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	allocations, err := r.newAllocations(cc, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "recording allocations in the ledger")
+
+	err = r.ledger.Record(ctx, key.ClusterID(customObject), allocations)
+	if ledger.IsAllocationConflict(err) {
+		message := err.Error()
+		if owners := r.conflictingOwners(ctx, customObject); len(owners) > 0 {
+			message = fmt.Sprintf("%s, requested by IngressConfig %s", message, strings.Join(owners, ", "))
+		}
+
+		logging.Warning(ctx, r.logger, fmt.Sprintf("rejecting the ingress config: %s", message))
+		allocationConflictsCounter.WithLabelValues(key.ClusterID(customObject)).Inc()
+		cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.PortsAllocated, conditions.StatusFalse, "AllocationConflict", message))
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+		return nil
+	} else if ledger.IsCoolingOff(err) {
+		// The reconciliation is retried with the next resync, once the LB port
+		// may have cooled off.
+		logging.Warning(ctx, r.logger, fmt.Sprintf("deferring the ingress config: %s", err.Error()))
+		cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.PortsAllocated, conditions.StatusFalse, "PortCoolingOff", err.Error()))
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+		return nil
+	} else if ledger.IsVersionSkew(err) {
+		// Another replica of a newer operator version is active during a
+		// rolling upgrade. It reconciles the ingress config instead.
+		logging.Warning(ctx, r.logger, err.Error())
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("recorded %d allocations in the ledger", len(allocations)))
+	cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.PortsAllocated, conditions.StatusTrue, "", ""))
+
+	r.observePool(ctx)
+
+	return nil
+}
+
+// conflictingOwners returns the other IngressConfigs requesting any LB port of
+// the given IngressConfig, in the format namespace/name. Failing to look them
+// up only loses the detail, so errors are only logged.
+func (r *Resource) conflictingOwners(ctx context.Context, customObject v1alpha1.IngressConfig) []string {
+	if r.lister == nil {
+		return nil
+	}
+
+	var owners []string
+	for _, p := range key.LBPorts(customObject) {
+		list, err := r.lister.ListIngressConfigsByLBPort(ctx, p)
+		if err != nil {
+			logging.WarningErr(ctx, r.logger, fmt.Sprintf("failed looking up the IngressConfigs requesting LB port %d", p), err)
+			return nil
+		}
+
+		for _, o := range list.Items {
+			if key.ClusterID(o) == key.ClusterID(customObject) {
+				continue
+			}
+			owner := o.Namespace + "/" + o.Name
+			if !containsString(owners, owner) {
+				owners = append(owners, owner)
+			}
+		}
+	}
+
+	sort.Strings(owners)
+
+	return owners
+}
+
+func (r *Resource) newAllocations(cc *controllercontext.Context, customObject v1alpha1.IngressConfig) ([]ledger.Allocation, error) {
+	pinned, err := key.PinnedPorts(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var labels map[string]string
+	if len(r.propagatedLabels) != 0 {
+		labels = key.PropagatedLabels(customObject, r.propagatedLabels)
+	}
+
+	owner := customObject.Namespace + "/" + customObject.Name
+
+	var allocations []ledger.Allocation
+	for _, p := range key.ProtocolPorts(customObject) {
+		a := ledger.Allocation{
+			ClusterID:     key.ClusterID(customObject),
+			IngressConfig: owner,
+			IngressPort:   cc.IngressPort(p),
+			Labels:        labels,
+			LBPort:        p.LBPort,
+			Pinned:        inPorts(pinned, p.LBPort),
+			Protocol:      key.Protocol(p),
+		}
+
+		allocations = append(allocations, a)
+	}
+
+	// External ports are recorded as well, so that they are never allocated to
+	// another guest cluster.
+	external, err := key.ExternalPorts(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	for _, p := range external {
+		a := ledger.Allocation{
+			ClusterID:     key.ClusterID(customObject),
+			External:      true,
+			IngressConfig: owner,
+			Labels:        labels,
+			LBPort:        p,
+		}
+
+		allocations = append(allocations, a)
+	}
+
+	return allocations, nil
+}
+
+func inPorts(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,363 @@
+package ledger
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+	"github.com/giantswarm/ingress-operator/service/lister"
+	"github.com/giantswarm/ingress-operator/service/pool"
+)
+
+// testClient never populates the cache of the lister, so that its reads are
+// served by the in-memory IngressConfigs.
+type testClient struct{}
+
+func (c *testClient) List(opts metav1.ListOptions) (*v1alpha1.IngressConfigList, error) {
+	return nil, microerror.New("not implemented")
+}
+
+func (c *testClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return nil, microerror.New("not implemented")
+}
+
+func newTestCustomObject(clusterID string, lbPort int) *v1alpha1.IngressConfig {
+	return &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				key.PinnedPortsAnnotation: "31000",
+			},
+			Labels: map[string]string{
+				"giantswarm.io/customer": "acme",
+				"unrelated":              "label",
+			},
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        clusterID,
+				Namespace: clusterID,
+				Service:   "worker",
+			},
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{
+					IngressPort: 30010,
+					Protocol:    "http",
+					LBPort:      lbPort,
+				},
+			},
+		},
+	}
+}
+
+func Test_Ledger_EnsureCreated(t *testing.T) {
+	var err error
+
+	var k8sClient clients.Interface
+	{
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var allocationLedger *ledger.Ledger
+	{
+		c := ledger.Config{
+			K8sClient: k8sClient,
+			Logger:    microloggertest.New(),
+
+			Name:      "ingress-operator-allocations",
+			Namespace: "kube-system",
+		}
+
+		allocationLedger, err = ledger.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var poolMonitor *pool.Monitor
+	{
+		var portAllocator *allocator.Allocator
+		portAllocator, err = allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := pool.Config{
+			Allocator: portAllocator,
+			K8sClient: k8sClient,
+			Ledger:    allocationLedger,
+			Logger:    microloggertest.New(),
+
+			ProjectName: "ingress-operator",
+		}
+
+		poolMonitor, err = pool.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var ingressConfigLister *lister.Lister
+	{
+		owner := newTestCustomObject("al9qy", 31000)
+		owner.Name = "al9qy"
+		owner.Namespace = "default"
+
+		c := lister.Config{
+			Clients:   map[string]lister.Client{"": &testClient{}},
+			G8sClient: clientstest.NewIngressConfigs(*owner),
+			Logger:    microloggertest.New(),
+		}
+
+		ingressConfigLister, err = lister.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var newResource *Resource
+	{
+		c := DefaultConfig()
+
+		c.K8sClient = k8sClient
+		c.Ledger = allocationLedger
+		c.Lister = ingressConfigLister
+		c.Logger = microloggertest.New()
+		c.Pool = poolMonitor
+
+		c.PropagatedLabels = []string{"giantswarm.io/customer"}
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	{
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+		err = newResource.EnsureCreated(ctx, newTestCustomObject("al9qy", 31000))
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if reconciliationcanceledcontext.IsCanceled(ctx) {
+			t.Fatal("expected", false, "got", true)
+		}
+
+		c, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if !conditions.IsTrue(c.Conditions, conditions.PortsAllocated) {
+			t.Fatal("expected", true, "got", false)
+		}
+	}
+
+	// Another guest cluster requesting the same LB port must be rejected.
+	{
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+		err = newResource.EnsureCreated(ctx, newTestCustomObject("p1l6x", 31000))
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if !reconciliationcanceledcontext.IsCanceled(ctx) {
+			t.Fatal("expected", true, "got", false)
+		}
+
+		// The condition names the IngressConfig owning the LB port.
+		c, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		message := conditions.Get(c.Conditions, conditions.PortsAllocated).Message
+		if !strings.HasSuffix(message, "requested by IngressConfig default/al9qy") {
+			t.Fatal("expected", "requested by IngressConfig default/al9qy", "got", message)
+		}
+	}
+
+	// Another guest cluster declaring the same LB port as external port must
+	// be rejected as well.
+	{
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+		customObject := newTestCustomObject("p1l6x", 31001)
+		customObject.Annotations[key.ExternalPortsAnnotation] = "31000"
+
+		err = newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if !reconciliationcanceledcontext.IsCanceled(ctx) {
+			t.Fatal("expected", true, "got", false)
+		}
+	}
+
+	list, err := allocationLedger.List(context.Background())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list) != 1 || list[0].ClusterID != "al9qy" || !list[0].Pinned {
+		t.Fatalf("expected %s got %#v", "pinned allocation of al9qy", list)
+	}
+	if len(list[0].Labels) != 1 || list[0].Labels["giantswarm.io/customer"] != "acme" {
+		t.Fatal("expected", map[string]string{"giantswarm.io/customer": "acme"}, "got", list[0].Labels)
+	}
+
+	// External ports are recorded as external allocations of the guest
+	// cluster.
+	{
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+		customObject := newTestCustomObject("al9qy", 31000)
+		customObject.Annotations[key.ExternalPortsAnnotation] = "31500"
+
+		err = newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		list, err = allocationLedger.List(context.Background())
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if len(list) != 2 || list[0].External || !list[1].External || list[1].LBPort != 31500 || list[1].ClusterID != "al9qy" {
+			t.Fatalf("expected %s got %#v", "external allocation of al9qy", list)
+		}
+	}
+
+	err = newResource.EnsureDeleted(context.Background(), newTestCustomObject("al9qy", 31000))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err = allocationLedger.List(context.Background())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list) != 0 {
+		t.Fatal("expected", 0, "got", len(list))
+	}
+}
+
+func Test_Ledger_EnsureCreated_coolingOff(t *testing.T) {
+	var err error
+
+	var k8sClient clients.Interface
+	{
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var allocationLedger *ledger.Ledger
+	{
+		c := ledger.Config{
+			K8sClient: k8sClient,
+			Logger:    microloggertest.New(),
+
+			CoolingOff: time.Hour,
+			Name:       "ingress-operator-allocations",
+			Namespace:  "kube-system",
+		}
+
+		allocationLedger, err = ledger.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var poolMonitor *pool.Monitor
+	{
+		var portAllocator *allocator.Allocator
+		portAllocator, err = allocator.New(allocator.Config{CoolingOff: time.Hour})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := pool.Config{
+			Allocator: portAllocator,
+			K8sClient: k8sClient,
+			Ledger:    allocationLedger,
+			Logger:    microloggertest.New(),
+
+			ProjectName: "ingress-operator",
+		}
+
+		poolMonitor, err = pool.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var newResource *Resource
+	{
+		c := DefaultConfig()
+
+		c.K8sClient = k8sClient
+		c.Ledger = allocationLedger
+		c.Logger = microloggertest.New()
+		c.Pool = poolMonitor
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	err = allocationLedger.Record(context.Background(), "al9qy", []ledger.Allocation{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = allocationLedger.Release(context.Background(), "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Another guest cluster requesting the LB port while it cools off must be
+	// deferred.
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+	ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+	err = newResource.EnsureCreated(ctx, newTestCustomObject("p1l6x", 31000))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reconciliationcanceledcontext.IsCanceled(ctx) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	c, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	condition := conditions.Get(c.Conditions, conditions.PortsAllocated)
+	if condition.Status != conditions.StatusFalse || condition.Reason != "PortCoolingOff" {
+		t.Fatalf("expected %s got %#v", "PortsAllocated False with reason PortCoolingOff", condition)
+	}
+}
@@ -0,0 +1,66 @@
+package ledger
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/resourcecanceledcontext"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/deletiondelay"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+// EnsureDeleted releases the allocations of the guest cluster. The same way
+// the config map and service resources delay the deletion of their data, the
+// allocations are kept as long as there are pods in the guest cluster
+// namespace, so the LB ports are not handed to another guest cluster while
+// still being in use. In case the ledger got written by a newer operator
+// version the allocations are kept until the newer version releases them.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	{
+		n := key.ClusterNamespace(customObject)
+		list, err := r.k8sClient.ListPods(ctx, n)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if len(list.Items) != 0 {
+			deletiondelay.Wait(customObject, list.Items)
+			logging.Debug(ctx, r.logger, "cannot finish deletion of namespace due to existing pods")
+			resourcecanceledcontext.SetCanceled(ctx)
+			finalizerskeptcontext.SetKept(ctx)
+			logging.Debug(ctx, r.logger, "canceling resource for custom object")
+
+			return nil
+		}
+
+		deletiondelay.Done(customObject)
+	}
+
+	logging.Debug(ctx, r.logger, "releasing allocations from the ledger")
+
+	err = r.ledger.Release(ctx, key.ClusterID(customObject))
+	if ledger.IsVersionSkew(err) {
+		logging.Warning(ctx, r.logger, err.Error())
+		resourcecanceledcontext.SetCanceled(ctx)
+		finalizerskeptcontext.SetKept(ctx)
+		logging.Debug(ctx, r.logger, "canceling resource for custom object")
+
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "released allocations from the ledger")
+
+	r.observePool(ctx)
+
+	return nil
+}
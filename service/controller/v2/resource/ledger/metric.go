@@ -0,0 +1,27 @@
+package ledger
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "ledger"
+)
+
+var (
+	allocationConflictsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "allocation_conflicts_total",
+			Help:      "A counter metric expressing the number of IngressConfigs rejected because their LB ports are allocated to another guest cluster.",
+		},
+		[]string{"cluster_id"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(allocationConflictsCounter)
+}
@@ -0,0 +1,117 @@
+// Package ledger implements a resource recording the LB ports of guest
+// clusters in the allocation ledger. The allocations of a guest cluster are
+// recorded before the config map and service resources apply them, so an LB
+// port already allocated to another guest cluster is rejected instead of
+// overwritten.
+package ledger
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+	"github.com/giantswarm/ingress-operator/service/lister"
+	"github.com/giantswarm/ingress-operator/service/pool"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "ledgerv2"
+)
+
+// Config represents the configuration used to create a new ledger resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Ledger    *ledger.Ledger
+	// Lister looks up the IngressConfigs requesting conflicting LB ports, so
+	// that they are named in the condition of the rejected IngressConfig. It is
+	// optional.
+	Lister *lister.Lister
+	Logger micrologger.Logger
+	Pool   *pool.Monitor
+
+	// Settings.
+
+	// PropagatedLabels are the label keys propagated from the IngressConfig to
+	// the allocations recorded in the ledger.
+	PropagatedLabels []string
+}
+
+// DefaultConfig provides a default configuration to create a new ledger
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Ledger:    nil,
+		Lister:    nil,
+		Logger:    nil,
+		Pool:      nil,
+
+		// Settings.
+		PropagatedLabels: nil,
+	}
+}
+
+// Resource implements the ledger resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	ledger    *ledger.Ledger
+	lister    *lister.Lister
+	logger    micrologger.Logger
+	pool      *pool.Monitor
+
+	// Settings.
+	propagatedLabels []string
+}
+
+// New creates a new configured ledger resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Ledger must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+	if config.Pool == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Pool must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		ledger:    config.Ledger,
+		lister:    config.Lister,
+		logger:    config.Logger.With("resource", Name),
+		pool:      config.Pool,
+
+		// Settings.
+		propagatedLabels: config.PropagatedLabels,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
+
+// observePool updates the utilization of the port pool after the ledger
+// changed. Failing to do so must not fail the reconciliation, so errors are
+// only logged.
+func (r *Resource) observePool(ctx context.Context) {
+	err := r.pool.Observe(ctx)
+	if err != nil {
+		logging.Error(ctx, r.logger, "failed observing port pool utilization", err)
+	}
+}
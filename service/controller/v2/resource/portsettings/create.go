@@ -0,0 +1,152 @@
+package portsettings
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureCreated writes the stream snippets of the LB ports of the guest
+// cluster into the stream snippets config map of the ingress controller and
+// removes the snippets of LB ports which have no settings anymore. The config
+// map is created in case it does not exist yet. The reconciliation is canceled
+// in case source ranges cannot be enforced, without writing any snippet.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// The service of the ingress controller is only looked up in case any LB
+	// port has source ranges.
+	clientIP := true
+	{
+		restricted, err := hasSourceRanges(customObject)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		if restricted {
+			clientIP, err = r.seesClientIP(ctx, cc)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	snippets, err := newSnippets(customObject, clientIP)
+	if IsUnenforceableSourceRanges(err) {
+		logging.Warning(ctx, r.logger, fmt.Sprintf("refusing the ingress config: %s", err.Error()))
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	namespace := cc.IngressController.Namespace
+	name := cc.IngressController.StreamSnippets
+
+	logging.Debug(ctx, r.logger, "ensuring stream snippets of LB ports")
+
+	configMap, err := r.k8sClient.GetConfigMap(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		configMap = &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Data: map[string]string{},
+		}
+
+		if !applySnippets(configMap, snippets) {
+			logging.Debug(ctx, r.logger, "no LB port has settings")
+			return nil
+		}
+
+		_, err = r.k8sClient.CreateConfigMap(ctx, namespace, configMap)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		logging.Debug(ctx, r.logger, fmt.Sprintf("created config map %s/%s with stream snippets", namespace, name))
+
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !applySnippets(configMap, snippets) {
+		logging.Debug(ctx, r.logger, "stream snippets of LB ports are up to date")
+		return nil
+	}
+
+	_, err = r.k8sClient.UpdateConfigMap(ctx, namespace, configMap)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "ensured stream snippets of LB ports")
+
+	return nil
+}
+
+// seesClientIP returns whether nginx sees the client IP of connections to the
+// LB ports of the ingress controller of the given controller context. That is
+// the case in case the ingress controller takes it from the PROXY protocol or
+// its service has the Local external traffic policy, so that kube-proxy does
+// not masquerade connections behind the IP of the node they arrived at.
+func (r *Resource) seesClientIP(ctx context.Context, cc *controllercontext.Context) (bool, error) {
+	if r.proxyProtocol {
+		return true, nil
+	}
+
+	service, err := r.k8sClient.GetService(ctx, cc.IngressController.Namespace, cc.IngressController.Service)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return service.Spec.ExternalTrafficPolicy == apiv1.ServiceExternalTrafficPolicyTypeLocal, nil
+}
+
+// applySnippets writes the given snippets into the data of the given config
+// map. Empty snippets remove the data of their key. It returns whether the
+// data changed.
+func applySnippets(configMap *apiv1.ConfigMap, snippets map[string]string) bool {
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+
+	var changed bool
+	for k, v := range snippets {
+		current, ok := configMap.Data[k]
+		if v == "" {
+			if ok {
+				delete(configMap.Data, k)
+				changed = true
+			}
+			continue
+		}
+		if !ok || current != v {
+			configMap.Data[k] = v
+			changed = true
+		}
+	}
+
+	return changed
+}
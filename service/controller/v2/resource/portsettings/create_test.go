@@ -0,0 +1,286 @@
+package portsettings
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+func newTestCustomObject(annotations map[string]string) *v1alpha1.IngressConfig {
+	return &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: annotations,
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        "al9qy",
+				Namespace: "al9qy",
+				Service:   "worker",
+			},
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{
+					IngressPort: 30010,
+					Protocol:    "http",
+					LBPort:      31000,
+				},
+				{
+					IngressPort: 30011,
+					Protocol:    "https",
+					LBPort:      31001,
+				},
+			},
+		},
+	}
+}
+
+func newTestContext() context.Context {
+	return controllercontext.NewContext(context.Background(), controllercontext.Context{
+		IngressController: controllercontext.IngressController{
+			Namespace:      "kube-system",
+			Service:        "ingress-controller",
+			StreamSnippets: "ingress-controller-stream-snippets",
+		},
+	})
+}
+
+func Test_PortSettings_EnsureCreated(t *testing.T) {
+	testCases := []struct {
+		Annotations           map[string]string
+		CurrentData           map[string]string
+		ExternalTrafficPolicy apiv1.ServiceExternalTrafficPolicyType
+		ProxyProtocol         bool
+		ExpectedCancel        bool
+		ExpectedData          map[string]string
+	}{
+		// Test 0 ensures the config map is not created in case no LB port has
+		// settings.
+		{
+			Annotations:  nil,
+			CurrentData:  nil,
+			ExpectedData: nil,
+		},
+
+		// Test 1 ensures the config map is created with the snippets of the LB
+		// ports having settings.
+		{
+			Annotations: map[string]string{
+				key.IdleTimeoutAnnotation:    "31000=10m, 31001=1500ms",
+				key.MaxConnectionsAnnotation: "31000=1000",
+			},
+			CurrentData: nil,
+			ExpectedData: map[string]string{
+				"31000": "# al9qy\nproxy_timeout 600s;\nlimit_conn ingress_operator_connections 1000;\n",
+				"31001": "# al9qy\nproxy_timeout 2s;\n",
+			},
+		},
+
+		// Test 2 ensures snippets of LB ports without settings are removed while
+		// snippets of other LB ports are kept.
+		{
+			Annotations: map[string]string{
+				key.MaxConnectionsAnnotation: "31001=50",
+			},
+			CurrentData: map[string]string{
+				"31000": "# al9qy\nproxy_timeout 600s;\n",
+				"31010": "# p1l6x\nproxy_timeout 60s;\n",
+			},
+			ExpectedData: map[string]string{
+				"31001": "# al9qy\nlimit_conn ingress_operator_connections 50;\n",
+				"31010": "# p1l6x\nproxy_timeout 60s;\n",
+			},
+		},
+
+		// Test 3 ensures LB ports carrying gRPC or HTTP/2 keep idle streams open
+		// unless they have an explicit idle timeout.
+		{
+			Annotations: map[string]string{
+				key.ApplicationProtocolsAnnotation: "31000=grpc,31001=h2",
+				key.IdleTimeoutAnnotation:          "31001=5m",
+			},
+			CurrentData: nil,
+			ExpectedData: map[string]string{
+				"31000": "# al9qy\nproxy_timeout 3600s;\n",
+				"31001": "# al9qy\nproxy_timeout 300s;\n",
+			},
+		},
+
+		// Test 4 ensures LB ports with source ranges allow only them and deny
+		// all other clients in case the service of the ingress controller keeps
+		// the client IP.
+		{
+			Annotations: map[string]string{
+				key.MaxConnectionsAnnotation: "31000=1000",
+				key.SourceRangesAnnotation:   "31000=10.0.0.0/8 192.168.0.0/16",
+			},
+			CurrentData:           nil,
+			ExternalTrafficPolicy: apiv1.ServiceExternalTrafficPolicyTypeLocal,
+			ExpectedData: map[string]string{
+				"31000": "# al9qy\nlimit_conn ingress_operator_connections 1000;\nallow 10.0.0.0/8;\nallow 192.168.0.0/16;\ndeny all;\n",
+			},
+		},
+
+		// Test 5 ensures source ranges are enforced in case the ingress
+		// controller takes the client IP from the PROXY protocol.
+		{
+			Annotations: map[string]string{
+				key.SourceRangesAnnotation: "31001=203.0.113.7/32",
+			},
+			CurrentData:           nil,
+			ExternalTrafficPolicy: apiv1.ServiceExternalTrafficPolicyTypeCluster,
+			ProxyProtocol:         true,
+			ExpectedData: map[string]string{
+				"31001": "# al9qy\nallow 203.0.113.7/32;\ndeny all;\n",
+			},
+		},
+
+		// Test 6 ensures the ingress config is refused in case nginx does not
+		// see the client IP, without touching any snippet.
+		{
+			Annotations: map[string]string{
+				key.IdleTimeoutAnnotation:  "31000=10m",
+				key.SourceRangesAnnotation: "31001=203.0.113.7/32",
+			},
+			CurrentData: map[string]string{
+				"31000": "# al9qy\nproxy_timeout 60s;\n",
+			},
+			ExternalTrafficPolicy: apiv1.ServiceExternalTrafficPolicyTypeCluster,
+			ExpectedCancel:        true,
+			ExpectedData: map[string]string{
+				"31000": "# al9qy\nproxy_timeout 60s;\n",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		objects := []runtime.Object{
+			&apiv1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ingress-controller",
+					Namespace: "kube-system",
+				},
+				Spec: apiv1.ServiceSpec{
+					ExternalTrafficPolicy: tc.ExternalTrafficPolicy,
+				},
+			},
+		}
+		if tc.CurrentData != nil {
+			objects = append(objects, &apiv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ingress-controller-stream-snippets",
+					Namespace: "kube-system",
+				},
+				Data: tc.CurrentData,
+			})
+		}
+		k8sClient := fake.NewSimpleClientset(objects...)
+
+		var err error
+		var newResource *Resource
+		{
+			var clientsInterface clients.Interface
+			clientsInterface, err = clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+
+			c.ProxyProtocol = tc.ProxyProtocol
+
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		ctx := reconciliationcanceledcontext.NewContext(newTestContext(), make(chan struct{}))
+
+		err = newResource.EnsureCreated(ctx, newTestCustomObject(tc.Annotations))
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if reconciliationcanceledcontext.IsCanceled(ctx) != tc.ExpectedCancel {
+			t.Fatal("test", i, "expected", tc.ExpectedCancel, "got", reconciliationcanceledcontext.IsCanceled(ctx))
+		}
+
+		configMap, err := k8sClient.CoreV1().ConfigMaps("kube-system").Get("ingress-controller-stream-snippets", metav1.GetOptions{})
+		if tc.ExpectedData == nil {
+			if err == nil {
+				t.Fatal("test", i, "expected", "not found error", "got", nil)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(configMap.Data, tc.ExpectedData) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedData, configMap.Data)
+		}
+	}
+}
+
+func Test_PortSettings_EnsureDeleted(t *testing.T) {
+	k8sClient := fake.NewSimpleClientset(&apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-controller-stream-snippets",
+			Namespace: "kube-system",
+		},
+		Data: map[string]string{
+			"31000": "# al9qy\nproxy_timeout 600s;\n",
+			// The LB port got handed over to another guest cluster already, so its
+			// snippet must be kept.
+			"31001": "# p1l6x\nproxy_timeout 60s;\n",
+		},
+	})
+
+	var err error
+	var newResource *Resource
+	{
+		var clientsInterface clients.Interface
+		clientsInterface, err = clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := DefaultConfig()
+
+		c.K8sClient = clientsInterface
+		c.Logger = microloggertest.New()
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	err = newResource.EnsureDeleted(newTestContext(), newTestCustomObject(nil))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	configMap, err := k8sClient.CoreV1().ConfigMaps("kube-system").Get("ingress-controller-stream-snippets", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	expected := map[string]string{
+		"31001": "# p1l6x\nproxy_timeout 60s;\n",
+	}
+	if !reflect.DeepEqual(configMap.Data, expected) {
+		t.Fatalf("expected %#v got %#v", expected, configMap.Data)
+	}
+}
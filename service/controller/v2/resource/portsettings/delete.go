@@ -0,0 +1,66 @@
+package portsettings
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureDeleted removes the stream snippets of the LB ports of the guest
+// cluster. Snippets written for another guest cluster are kept, in case an LB
+// port got handed over already.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	namespace := cc.IngressController.Namespace
+	name := cc.IngressController.StreamSnippets
+
+	logging.Debug(ctx, r.logger, "deleting stream snippets of LB ports")
+
+	configMap, err := r.k8sClient.GetConfigMap(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		logging.Debug(ctx, r.logger, "did not find the stream snippets config map in the Kubernetes API")
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var changed bool
+	for _, p := range key.LBPorts(customObject) {
+		k := strconv.Itoa(p)
+
+		v, ok := configMap.Data[k]
+		if ok && isOwnedBy(v, key.ClusterID(customObject)) {
+			delete(configMap.Data, k)
+			changed = true
+		}
+	}
+
+	if !changed {
+		logging.Debug(ctx, r.logger, "stream snippets of LB ports are already deleted")
+		return nil
+	}
+
+	_, err = r.k8sClient.UpdateConfigMap(ctx, namespace, configMap)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "deleted stream snippets of LB ports")
+
+	return nil
+}
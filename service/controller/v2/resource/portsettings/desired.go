@@ -0,0 +1,93 @@
+package portsettings
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// hasSourceRanges returns whether any LB port of the given custom object
+// restricts its clients to source ranges.
+func hasSourceRanges(customObject v1alpha1.IngressConfig) (bool, error) {
+	settings, err := key.PortSettingsByLBPort(customObject)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	for _, s := range settings {
+		if len(s.SourceRanges) > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// newSnippets returns the stream snippets of the given custom object by config
+// map data key. LB ports of the custom object without settings map to an empty
+// snippet, meaning any existing snippet of the LB port has to be removed.
+// clientIP is whether nginx sees the client IP of connections. Source ranges
+// cannot be enforced otherwise, so an unenforceable source ranges error is
+// returned in case any LB port has some.
+func newSnippets(customObject v1alpha1.IngressConfig, clientIP bool) (map[string]string, error) {
+	settings, err := key.PortSettingsByLBPort(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	snippets := map[string]string{}
+	for _, p := range key.LBPorts(customObject) {
+		s, ok := settings[p]
+		if !ok {
+			snippets[strconv.Itoa(p)] = ""
+			continue
+		}
+		if len(s.SourceRanges) > 0 && !clientIP {
+			return nil, microerror.Maskf(unenforceableSourceRangesError, "%s of LB port %d cannot be enforced, since nginx does not see the client IP of connections", key.SourceRangesAnnotation, p)
+		}
+
+		snippets[strconv.Itoa(p)] = newSnippet(key.ClusterID(customObject), s)
+	}
+
+	return snippets, nil
+}
+
+func newSnippet(clusterID string, settings key.PortSettings) string {
+	lines := []string{
+		fmt.Sprintf(OwnerFormat, clusterID),
+	}
+	idleTimeout := settings.IdleTimeout
+	if idleTimeout == 0 && settings.IsMultiplexed() {
+		idleTimeout = MultiplexedIdleTimeout
+	}
+	if idleTimeout > 0 {
+		// nginx only supports timeouts in whole seconds. Fractions are rounded up
+		// so that short timeouts do not end up disabling the timeout.
+		lines = append(lines, fmt.Sprintf("proxy_timeout %ds;", int64(math.Ceil(idleTimeout.Seconds()))))
+	}
+	if settings.MaxConnections > 0 {
+		lines = append(lines, fmt.Sprintf("limit_conn %s %d;", ConnectionLimitZone, settings.MaxConnections))
+	}
+	if len(settings.SourceRanges) > 0 {
+		// The access rules are checked in order, so clients not matching any of
+		// the allowed source ranges are denied.
+		for _, c := range settings.SourceRanges {
+			lines = append(lines, fmt.Sprintf("allow %s;", c))
+		}
+		lines = append(lines, "deny all;")
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// isOwnedBy returns whether the given stream snippet was written for the guest
+// cluster with the given ID.
+func isOwnedBy(snippet, clusterID string) bool {
+	return strings.SplitN(snippet, "\n", 2)[0] == fmt.Sprintf(OwnerFormat, clusterID)
+}
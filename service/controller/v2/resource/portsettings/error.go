@@ -0,0 +1,23 @@
+package portsettings
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var unenforceableSourceRangesError = &microerror.Error{
+	Kind: "unenforceableSourceRangesError",
+}
+
+// IsUnenforceableSourceRanges asserts unenforceableSourceRangesError.
+func IsUnenforceableSourceRanges(err error) bool {
+	return microerror.Cause(err) == unenforceableSourceRangesError
+}
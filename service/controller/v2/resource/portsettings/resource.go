@@ -0,0 +1,110 @@
+// Package portsettings implements a resource tuning the connections of single
+// LB ports of the host cluster ingress controller. The idle timeout,
+// connection limit, application protocol and source ranges of an LB port are
+// taken from the annotations of the IngressConfig and written as nginx stream
+// snippet into the stream snippets config map of the ingress controller, keyed
+// by LB port. The nginx template of
+// the ingress controller includes the snippet of an LB port into the server
+// block listening on it. Source ranges are only enforced in case nginx sees the
+// client IP of connections, i.e. the service of the ingress controller has the
+// Local external traffic policy or the ingress controller takes the client IP
+// from the PROXY protocol. IngressConfigs with source ranges are refused
+// otherwise, since nginx would only see the IPs of the nodes.
+package portsettings
+
+import (
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// ConnectionLimitZone is the name of the nginx shared memory zone the
+	// connection limits of LB ports are accounted in. The nginx template of the
+	// ingress controller has to define it in the stream context, e.g.:
+	//
+	//     limit_conn_zone $server_port zone=ingress_operator_connections:1m;
+	//
+	ConnectionLimitZone = "ingress_operator_connections"
+	// MultiplexedIdleTimeout is the idle timeout of LB ports carrying gRPC or
+	// HTTP/2 without explicit idle timeout. Their connections carry long lived
+	// streams, e.g. watches, which stay idle for longer than the nginx default
+	// of 10 minutes and would be cut otherwise.
+	MultiplexedIdleTimeout = time.Hour
+	// Name is the identifier of the resource.
+	Name = "portsettingsv2"
+	// OwnerFormat is the format string of the first line of each stream
+	// snippet. It records the guest cluster owning the snippet, so that only
+	// snippets of the guest cluster itself are removed on its deletion. E.g.:
+	//
+	//     # al9qy
+	//
+	OwnerFormat = "# %s"
+)
+
+// Config represents the configuration used to create a new port settings
+// resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// ProxyProtocol is whether the ingress controller receives the PROXY
+	// protocol and nginx takes the client IP of connections to LB ports from
+	// it.
+	ProxyProtocol bool
+}
+
+// DefaultConfig provides a default configuration to create a new port settings
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		ProxyProtocol: false,
+	}
+}
+
+// Resource implements the port settings resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	proxyProtocol bool
+}
+
+// New creates a new configured port settings resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		proxyProtocol: config.ProxyProtocol,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -0,0 +1,52 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureCreated cancels the reconciliation of quarantined IngressConfigs until
+// their next attempt is due.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	nextAttempt, parked := r.parked(customObject)
+	if !parked {
+		return nil
+	}
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("ingress config is quarantined until %s", nextAttempt.Format(time.RFC3339)))
+	reconciliationcanceledcontext.SetCanceled(ctx)
+	logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+	return nil
+}
+
+// parked returns the time of the next attempt to reconcile the given custom
+// object and whether it is quarantined and its next attempt is not due yet.
+func (r *Resource) parked(customObject v1alpha1.IngressConfig) (time.Time, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	e, ok := r.entries[objectKey(customObject)]
+	if !ok || !e.quarantined {
+		return time.Time{}, false
+	}
+
+	return e.nextAttempt, r.now().Before(e.nextAttempt)
+}
+
+func objectKey(customObject v1alpha1.IngressConfig) string {
+	return fmt.Sprintf("%s/%s", customObject.Namespace, customObject.Name)
+}
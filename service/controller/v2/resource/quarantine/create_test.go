@@ -0,0 +1,147 @@
+package quarantine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+func Test_Quarantine_EnsureCreated(t *testing.T) {
+	testCases := []struct {
+		Outcomes         []outcome.Outcome
+		Elapsed          time.Duration
+		ExpectedCanceled bool
+		ExpectedDegraded bool
+	}{
+		// Test 0 ensures IngressConfigs failing less often than the threshold
+		// are not quarantined.
+		{
+			Outcomes:         []outcome.Outcome{outcome.Failed, outcome.Failed},
+			Elapsed:          0,
+			ExpectedCanceled: false,
+			ExpectedDegraded: false,
+		},
+
+		// Test 1 ensures IngressConfigs failing as often as the threshold are
+		// quarantined and marked as degraded.
+		{
+			Outcomes:         []outcome.Outcome{outcome.Failed, outcome.Failed, outcome.Failed},
+			Elapsed:          0,
+			ExpectedCanceled: true,
+			ExpectedDegraded: true,
+		},
+
+		// Test 2 ensures quarantined IngressConfigs are reconciled once the retry
+		// interval passed.
+		{
+			Outcomes:         []outcome.Outcome{outcome.Failed, outcome.Failed, outcome.Failed},
+			Elapsed:          time.Hour,
+			ExpectedCanceled: false,
+			ExpectedDegraded: true,
+		},
+
+		// Test 3 ensures successful reconciliations reset the failures.
+		{
+			Outcomes:         []outcome.Outcome{outcome.Failed, outcome.Failed, outcome.Succeeded, outcome.Failed},
+			Elapsed:          0,
+			ExpectedCanceled: false,
+			ExpectedDegraded: false,
+		},
+
+		// Test 4 ensures successful reconciliations release quarantined
+		// IngressConfigs.
+		{
+			Outcomes:         []outcome.Outcome{outcome.Failed, outcome.Failed, outcome.Failed, outcome.Succeeded},
+			Elapsed:          0,
+			ExpectedCanceled: false,
+			ExpectedDegraded: true,
+		},
+
+		// Test 5 ensures canceled reconciliations do not reset the failures.
+		{
+			Outcomes:         []outcome.Outcome{outcome.Failed, outcome.Failed, outcome.Canceled, outcome.Failed},
+			Elapsed:          0,
+			ExpectedCanceled: true,
+			ExpectedDegraded: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject := &v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "al9qy",
+				Namespace: "default",
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+					ID: "al9qy",
+				},
+			},
+		}
+		g8sClient := clientstest.NewIngressConfigs(*customObject)
+
+		var newResource *Resource
+		{
+			c := DefaultConfig()
+
+			c.G8sClient = g8sClient
+			c.Logger = microloggertest.New()
+
+			c.RetryInterval = 30 * time.Minute
+			c.Threshold = 3
+
+			var err error
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		now := time.Unix(0, 0)
+		newResource.now = func() time.Time { return now }
+
+		for _, o := range tc.Outcomes {
+			var err error
+			if o == outcome.Failed {
+				err = microerror.New("test error")
+			}
+			newResource.Observe(context.Background(), customObject, o, err)
+		}
+
+		now = now.Add(tc.Elapsed)
+
+		ctx := reconciliationcanceledcontext.NewContext(context.Background(), make(chan struct{}))
+		err := newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		canceled := reconciliationcanceledcontext.IsCanceled(ctx)
+		if canceled != tc.ExpectedCanceled {
+			t.Fatal("test", i, "expected", tc.ExpectedCanceled, "got", canceled)
+		}
+
+		current, err := g8sClient.GetIngressConfig(context.Background(), "default", "al9qy")
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		list, err := conditions.FromCustomObject(*current)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		degraded := conditions.IsTrue(list, conditions.Degraded)
+		if degraded != tc.ExpectedDegraded {
+			t.Fatal("test", i, "expected", tc.ExpectedDegraded, "got", degraded)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureDeleted cancels the deletion of quarantined IngressConfigs until their
+// next attempt is due. The finalizer is kept, so that the deletion is retried.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	nextAttempt, parked := r.parked(customObject)
+	if !parked {
+		return nil
+	}
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("ingress config is quarantined until %s", nextAttempt.Format(time.RFC3339)))
+	reconciliationcanceledcontext.SetCanceled(ctx)
+	finalizerskeptcontext.SetKept(ctx)
+	logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+	return nil
+}
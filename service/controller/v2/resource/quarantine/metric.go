@@ -0,0 +1,36 @@
+package quarantine
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "quarantine"
+)
+
+var (
+	quarantinedGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "quarantined",
+			Help:      "A gauge metric expressing whether the IngressConfig of a guest cluster is quarantined after failing consecutively.",
+		},
+		[]string{"cluster_id"},
+	)
+	quarantinesCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "quarantines_total",
+			Help:      "A counter metric expressing the number of times IngressConfigs got quarantined.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(quarantinedGauge)
+	prometheus.MustRegister(quarantinesCounter)
+}
@@ -0,0 +1,119 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+// Observe records the outcome of a reconciliation loop. IngressConfigs are
+// quarantined once their reconciliation failed the configured number of times
+// in a row and released as soon as it succeeded. Canceled reconciliations
+// neither count as failure nor as success. It implements outcome.Observer.
+func (r *Resource) Observe(ctx context.Context, obj interface{}, o outcome.Outcome, err error) {
+	customObject, convertErr := key.ToCustomObject(obj)
+	if convertErr != nil {
+		return
+	}
+
+	switch o {
+	case outcome.Failed:
+		r.fail(ctx, customObject, err)
+	case outcome.Succeeded:
+		r.succeed(ctx, customObject)
+	}
+}
+
+func (r *Resource) fail(ctx context.Context, customObject v1alpha1.IngressConfig, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	k := objectKey(customObject)
+	e, ok := r.entries[k]
+	if !ok {
+		e = &entry{}
+		r.entries[k] = e
+	}
+
+	e.failures++
+	e.nextAttempt = r.now().Add(r.retryInterval)
+
+	if e.quarantined || e.failures < r.threshold {
+		return
+	}
+
+	e.quarantined = true
+	quarantinedGauge.WithLabelValues(key.ClusterID(customObject)).Set(1)
+	quarantinesCounter.Inc()
+
+	message := fmt.Sprintf("reconciliation failed %d times in a row, retrying every %s: %s", e.failures, r.retryInterval, err.Error())
+	logging.Warning(ctx, r.logger, fmt.Sprintf("quarantining ingress config: %s", message))
+
+	// Failing to record the condition must not prevent the quarantine, so the
+	// error is only logged.
+	setErr := r.setDegraded(ctx, customObject, message)
+	if setErr != nil {
+		logging.Error(ctx, r.logger, "failed recording the quarantine in the conditions", setErr)
+	}
+}
+
+func (r *Resource) succeed(ctx context.Context, customObject v1alpha1.IngressConfig) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	k := objectKey(customObject)
+	e, ok := r.entries[k]
+	if !ok {
+		return
+	}
+
+	delete(r.entries, k)
+
+	if e.quarantined {
+		quarantinedGauge.DeleteLabelValues(key.ClusterID(customObject))
+		logging.Info(ctx, r.logger, fmt.Sprintf("released ingress config from quarantine after %d failed reconciliations", e.failures))
+	}
+}
+
+// setDegraded records the Degraded condition of the quarantine on the given
+// custom object. The status resource is never reached by failing
+// reconciliations, so the condition is written directly. It is replaced by
+// the status resource once the reconciliation succeeds again.
+func (r *Resource) setDegraded(ctx context.Context, customObject v1alpha1.IngressConfig, message string) error {
+	latest, err := r.g8sClient.GetIngressConfig(ctx, customObject.Namespace, customObject.Name)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	current, err := conditions.FromCustomObject(*latest)
+	if conditions.IsInvalidAnnotation(err) {
+		current = nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	desired := conditions.Set(current, conditions.New(conditions.Degraded, conditions.StatusTrue, Reason, message))
+	v, err := conditions.ToAnnotation(desired)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if latest.Annotations == nil {
+		latest.Annotations = map[string]string{}
+	}
+	latest.Annotations[conditions.Annotation] = v
+
+	_, err = r.g8sClient.UpdateIngressConfig(ctx, latest.Namespace, latest)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package quarantine implements a resource parking IngressConfigs whose
+// reconciliation failed consecutively, e.g. because they are malformed in a
+// way validation does not catch. Parked IngressConfigs are only reconciled
+// once per retry interval, so that they do not consume retries at the expense
+// of the rest of the fleet. The resource observes the outcomes of the
+// reconciliation loops and has to be the first resource of the resource set.
+package quarantine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "quarantinev2"
+	// Reason is the reason of the Degraded condition of quarantined
+	// IngressConfigs.
+	Reason = "Quarantined"
+)
+
+// Config represents the configuration used to create a new quarantine
+// resource.
+type Config struct {
+	// Dependencies.
+	G8sClient clients.IngressConfigs
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// RetryInterval is the interval in which quarantined IngressConfigs are
+	// reconciled.
+	RetryInterval time.Duration
+	// Threshold is the number of consecutive failed reconciliations after
+	// which an IngressConfig is quarantined.
+	Threshold int
+}
+
+// DefaultConfig provides a default configuration to create a new quarantine
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		G8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		RetryInterval: 0,
+		Threshold:     0,
+	}
+}
+
+// entry is the failure record of a single IngressConfig.
+type entry struct {
+	failures    int
+	nextAttempt time.Time
+	quarantined bool
+}
+
+// Resource implements the quarantine resource.
+type Resource struct {
+	// Dependencies.
+	g8sClient clients.IngressConfigs
+	logger    micrologger.Logger
+
+	// Internals.
+	entries map[string]*entry
+	mutex   sync.Mutex
+	now     func() time.Time
+
+	// Settings.
+	retryInterval time.Duration
+	threshold     int
+}
+
+// New creates a new configured quarantine resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.G8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	// Settings.
+	if config.RetryInterval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "config.RetryInterval must be greater than 0")
+	}
+	if config.Threshold <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "config.Threshold must be greater than 0")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		g8sClient: config.G8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Internals.
+		entries: map[string]*entry{},
+		mutex:   sync.Mutex{},
+		now:     time.Now,
+
+		// Settings.
+		retryInterval: config.RetryInterval,
+		threshold:     config.Threshold,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
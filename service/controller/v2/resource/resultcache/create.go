@@ -0,0 +1,152 @@
+package resultcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/slo"
+)
+
+// EnsureCreated cancels the reconciliation in case the fingerprint of the
+// IngressConfig matches the one of its last successful reconciliation and the
+// entry did not expire yet. The skipped reconciliation counts as successful
+// for the SLO of the guest cluster. Otherwise the fingerprint is kept until
+// the outcome of the reconciliation is observed.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	f, err := r.fingerprint(ctx, customObject, cc.IngressController)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	k := objectKey(customObject)
+	e, ok := r.entries[k]
+	if ok && e.fingerprint == f && r.now().Before(e.expires) {
+		hitsCounter.Inc()
+		slo.Reconciled(key.ClusterID(customObject))
+
+		logging.Debug(ctx, r.logger, "ingress config and affected objects did not change since the last successful reconciliation")
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+		return nil
+	}
+
+	missesCounter.Inc()
+	r.pending[k] = f
+
+	return nil
+}
+
+// fingerprint returns a digest of the parts of the given custom object driving
+// its reconciliation and of the resource versions of the objects of the given
+// ingress controller and of the guest cluster service. Objects which do not
+// exist contribute an empty resource version.
+func (r *Resource) fingerprint(ctx context.Context, customObject v1alpha1.IngressConfig, c controllercontext.IngressController) (string, error) {
+	configMap, err := r.configMapVersion(ctx, c.Namespace, c.ConfigMap)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	service, err := r.serviceVersion(ctx, c.Namespace, c.Service)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	streamSnippets, err := r.configMapVersion(ctx, c.Namespace, c.StreamSnippets)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	guestService, err := r.serviceVersion(ctx, key.ClusterNamespace(customObject), key.ClusterService(customObject))
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	input := struct {
+		Annotations           map[string]string          `json:"annotations"`
+		ConfigMap             string                     `json:"configMap"`
+		ConfigMapVersion      string                     `json:"configMapVersion"`
+		GuestServiceVersion   string                     `json:"guestServiceVersion"`
+		Labels                map[string]string          `json:"labels"`
+		Service               string                     `json:"service"`
+		ServiceVersion        string                     `json:"serviceVersion"`
+		Spec                  v1alpha1.IngressConfigSpec `json:"spec"`
+		StreamSnippets        string                     `json:"streamSnippets"`
+		StreamSnippetsVersion string                     `json:"streamSnippetsVersion"`
+		UID                   string                     `json:"uid"`
+	}{
+		Annotations:           customObject.Annotations,
+		ConfigMap:             c.Namespace + "/" + c.ConfigMap,
+		ConfigMapVersion:      configMap,
+		GuestServiceVersion:   guestService,
+		Labels:                customObject.Labels,
+		Service:               c.Namespace + "/" + c.Service,
+		ServiceVersion:        service,
+		Spec:                  customObject.Spec,
+		StreamSnippets:        c.Namespace + "/" + c.StreamSnippets,
+		StreamSnippetsVersion: streamSnippets,
+		UID:                   string(customObject.UID),
+	}
+
+	b, err := json.Marshal(input)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (r *Resource) configMapVersion(ctx context.Context, namespace, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	configMap, err := r.k8sClient.GetConfigMap(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return configMap.ResourceVersion, nil
+}
+
+func (r *Resource) serviceVersion(ctx context.Context, namespace, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+
+	service, err := r.k8sClient.GetService(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return service.ResourceVersion, nil
+}
+
+func objectKey(customObject v1alpha1.IngressConfig) string {
+	return fmt.Sprintf("%s/%s", customObject.Namespace, customObject.Name)
+}
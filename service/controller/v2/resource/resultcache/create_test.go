@@ -0,0 +1,320 @@
+package resultcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+func Test_ResultCache_EnsureCreated(t *testing.T) {
+	testCases := []struct {
+		Outcome          outcome.Outcome
+		PendingPorts     map[int]bool
+		Modify           func(customObject *v1alpha1.IngressConfig, k8sClient kubernetes.Interface) error
+		Elapsed          time.Duration
+		ExpectedCanceled bool
+	}{
+		// Test 0 ensures reconciliations of unchanged IngressConfigs are skipped
+		// after a successful reconciliation.
+		{
+			Outcome:          outcome.Succeeded,
+			Modify:           nil,
+			Elapsed:          0,
+			ExpectedCanceled: true,
+		},
+
+		// Test 1 ensures unchanged IngressConfigs are reconciled again after a
+		// failed reconciliation.
+		{
+			Outcome:          outcome.Failed,
+			Modify:           nil,
+			Elapsed:          0,
+			ExpectedCanceled: false,
+		},
+
+		// Test 2 ensures unchanged IngressConfigs are reconciled again after a
+		// canceled reconciliation, e.g. because certificates were pending.
+		{
+			Outcome:          outcome.Canceled,
+			Modify:           nil,
+			Elapsed:          0,
+			ExpectedCanceled: false,
+		},
+
+		// Test 3 ensures IngressConfigs whose spec changed are reconciled.
+		{
+			Outcome: outcome.Succeeded,
+			Modify: func(customObject *v1alpha1.IngressConfig, k8sClient kubernetes.Interface) error {
+				customObject.Spec.ProtocolPorts[0].LBPort = 31001
+				return nil
+			},
+			Elapsed:          0,
+			ExpectedCanceled: false,
+		},
+
+		// Test 4 ensures IngressConfigs whose annotations changed are
+		// reconciled.
+		{
+			Outcome: outcome.Succeeded,
+			Modify: func(customObject *v1alpha1.IngressConfig, k8sClient kubernetes.Interface) error {
+				customObject.Annotations = map[string]string{"ingress-operator.giantswarm.io/pinned-ports": "31000"}
+				return nil
+			},
+			Elapsed:          0,
+			ExpectedCanceled: false,
+		},
+
+		// Test 5 ensures IngressConfigs are reconciled in case the service of
+		// the ingress controller changed, e.g. because its ports got edited
+		// manually.
+		{
+			Outcome: outcome.Succeeded,
+			Modify: func(customObject *v1alpha1.IngressConfig, k8sClient kubernetes.Interface) error {
+				service, err := k8sClient.CoreV1().Services("kube-system").Get("ingress-controller", metav1.GetOptions{})
+				if err != nil {
+					return microerror.Mask(err)
+				}
+				service.ResourceVersion = "2"
+				_, err = k8sClient.CoreV1().Services("kube-system").Update(service)
+				return microerror.Mask(err)
+			},
+			Elapsed:          0,
+			ExpectedCanceled: false,
+		},
+
+		// Test 6 ensures IngressConfigs are reconciled in case the config map of
+		// the ingress controller got deleted.
+		{
+			Outcome: outcome.Succeeded,
+			Modify: func(customObject *v1alpha1.IngressConfig, k8sClient kubernetes.Interface) error {
+				return k8sClient.CoreV1().ConfigMaps("kube-system").Delete("ingress-controller", &metav1.DeleteOptions{})
+			},
+			Elapsed:          0,
+			ExpectedCanceled: false,
+		},
+
+		// Test 7 ensures IngressConfigs are reconciled in case the service of the
+		// guest cluster ingress controller changed.
+		{
+			Outcome: outcome.Succeeded,
+			Modify: func(customObject *v1alpha1.IngressConfig, k8sClient kubernetes.Interface) error {
+				service, err := k8sClient.CoreV1().Services("al9qy").Get("worker", metav1.GetOptions{})
+				if err != nil {
+					return microerror.Mask(err)
+				}
+				service.ResourceVersion = "2"
+				_, err = k8sClient.CoreV1().Services("al9qy").Update(service)
+				return microerror.Mask(err)
+			},
+			Elapsed:          0,
+			ExpectedCanceled: false,
+		},
+
+		// Test 8 ensures unchanged IngressConfigs are reconciled once their
+		// entry expired.
+		{
+			Outcome:          outcome.Succeeded,
+			Modify:           nil,
+			Elapsed:          time.Hour,
+			ExpectedCanceled: false,
+		},
+
+		// Test 9 ensures unchanged IngressConfigs are reconciled again in case
+		// their LB ports waited for certificates, which may have been issued
+		// since.
+		{
+			Outcome:          outcome.Succeeded,
+			PendingPorts:     map[int]bool{31000: true},
+			Modify:           nil,
+			Elapsed:          0,
+			ExpectedCanceled: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject := &v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "al9qy",
+				Namespace: "default",
+				UID:       "a5b1c2d3",
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+					ID:      "al9qy",
+					Service: "worker",
+				},
+				ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+					{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+				},
+			},
+		}
+
+		k8sClient := fake.NewSimpleClientset(
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "ingress-controller",
+					Namespace:       "kube-system",
+					ResourceVersion: "1",
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "ingress-controller",
+					Namespace:       "kube-system",
+					ResourceVersion: "1",
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "worker",
+					Namespace:       "al9qy",
+					ResourceVersion: "1",
+				},
+			},
+		)
+		k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		var newResource *Resource
+		{
+			c := DefaultConfig()
+
+			c.K8sClient = k8sClients
+			c.Logger = microloggertest.New()
+
+			c.TTL = 30 * time.Minute
+
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		now := time.Unix(0, 0)
+		newResource.now = func() time.Time { return now }
+
+		newContext := func(pendingPorts map[int]bool) context.Context {
+			ctx := reconciliationcanceledcontext.NewContext(context.Background(), make(chan struct{}))
+			cc := controllercontext.Context{
+				CertificatePendingPorts: pendingPorts,
+				IngressController: controllercontext.IngressController{
+					ConfigMap:      "ingress-controller",
+					Namespace:      "kube-system",
+					Service:        "ingress-controller",
+					StreamSnippets: "ingress-controller-stream-snippets",
+				},
+			}
+
+			return controllercontext.NewContext(ctx, cc)
+		}
+
+		// The first reconciliation is never skipped.
+		ctx := newContext(tc.PendingPorts)
+		err = newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if reconciliationcanceledcontext.IsCanceled(ctx) {
+			t.Fatal("test", i, "expected", false, "got", true)
+		}
+
+		var observeErr error
+		if tc.Outcome == outcome.Failed {
+			observeErr = microerror.New("test error")
+		}
+		newResource.Observe(ctx, customObject, tc.Outcome, observeErr)
+
+		if tc.Modify != nil {
+			err = tc.Modify(customObject, k8sClient)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+		now = now.Add(tc.Elapsed)
+
+		ctx = newContext(nil)
+		err = newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		canceled := reconciliationcanceledcontext.IsCanceled(ctx)
+		if canceled != tc.ExpectedCanceled {
+			t.Fatal("test", i, "expected", tc.ExpectedCanceled, "got", canceled)
+		}
+	}
+}
+
+func Test_ResultCache_EnsureDeleted(t *testing.T) {
+	customObject := &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	k8sClients, err := clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	c := DefaultConfig()
+
+	c.K8sClient = k8sClients
+	c.Logger = microloggertest.New()
+
+	c.TTL = 30 * time.Minute
+
+	newResource, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newContext := func() context.Context {
+		ctx := reconciliationcanceledcontext.NewContext(context.Background(), make(chan struct{}))
+		return controllercontext.NewContext(ctx, controllercontext.Context{})
+	}
+
+	ctx := newContext()
+	err = newResource.EnsureCreated(ctx, customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newResource.Observe(ctx, customObject, outcome.Succeeded, nil)
+
+	// The deletion forgets the cached result, so that an IngressConfig
+	// recreated with the same name is reconciled.
+	err = newResource.EnsureDeleted(newContext(), customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx = newContext()
+	err = newResource.EnsureCreated(ctx, customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if reconciliationcanceledcontext.IsCanceled(ctx) {
+		t.Fatal("expected", false, "got", true)
+	}
+}
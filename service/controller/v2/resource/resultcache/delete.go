@@ -0,0 +1,30 @@
+package resultcache
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureDeleted forgets the cached result of the IngressConfig. Deletions are
+// never skipped.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.forget(objectKey(customObject))
+
+	return nil
+}
+
+func (r *Resource) forget(k string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.entries, k)
+	delete(r.pending, k)
+}
@@ -0,0 +1,35 @@
+package resultcache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "result_cache"
+)
+
+var (
+	hitsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "hits_total",
+			Help:      "A counter metric expressing the number of reconciliations skipped because neither the IngressConfig nor the objects it affects changed since its last successful reconciliation.",
+		},
+	)
+	missesCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "misses_total",
+			Help:      "A counter metric expressing the number of reconciliations executed because the IngressConfig or the objects it affects changed or its cache entry expired.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(hitsCounter)
+	prometheus.MustRegister(missesCounter)
+}
@@ -0,0 +1,56 @@
+package resultcache
+
+import (
+	"context"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+// Observe caches the fingerprint of successful reconciliations. Failed
+// reconciliations forget the cached result, so that the IngressConfig is
+// reconciled again on its next attempt. Canceled reconciliations, e.g. cache
+// hits, neither cache nor forget anything. Successful reconciliations leaving
+// LB ports pending on their certificates are not cached either, since the
+// certificates are not part of the fingerprint. It implements
+// outcome.Observer.
+func (r *Resource) Observe(ctx context.Context, obj interface{}, o outcome.Outcome, err error) {
+	customObject, convertErr := key.ToCustomObject(obj)
+	if convertErr != nil {
+		return
+	}
+
+	k := objectKey(customObject)
+
+	switch o {
+	case outcome.Failed:
+		r.forget(k)
+	case outcome.Canceled:
+		r.mutex.Lock()
+		delete(r.pending, k)
+		r.mutex.Unlock()
+	case outcome.Succeeded:
+		r.mutex.Lock()
+		f, ok := r.pending[k]
+		if ok && customObject.DeletionTimestamp == nil && !certificatesPending(ctx) {
+			r.entries[k] = entry{
+				expires:     r.now().Add(r.ttl),
+				fingerprint: f,
+			}
+		}
+		delete(r.pending, k)
+		r.mutex.Unlock()
+	}
+}
+
+// certificatesPending returns whether LB ports of the reconciliation of the
+// given context wait for their certificates.
+func certificatesPending(ctx context.Context) bool {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return false
+	}
+
+	return len(cc.CertificatePendingPorts) > 0
+}
@@ -0,0 +1,116 @@
+// Package resultcache implements a resource skipping the reconciliation of
+// IngressConfigs which did not change since their last successful
+// reconciliation. The result of a reconciliation is cached by a fingerprint of
+// the IngressConfig and of the resource versions of the host and guest cluster
+// objects it affects. Periodic resyncs of unchanged IngressConfigs are then
+// canceled right away instead of recomputing and comparing the desired state
+// of all resources. Skipped reconciliations still count as successful for the
+// SLO of the guest cluster. Entries expire after the TTL, so that drift of
+// state the fingerprint does not cover, e.g. the ledger, is still repaired.
+// Reconciliations waiting for certificates are never cached. The resource
+// observes the outcomes of the reconciliation loops and has to be executed
+// before the resources managing any state.
+package resultcache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "resultcachev2"
+)
+
+// Config represents the configuration used to create a new result cache
+// resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// TTL is the time a successful reconciliation is cached. IngressConfigs
+	// are reconciled once their entry expired, even if nothing changed.
+	TTL time.Duration
+}
+
+// DefaultConfig provides a default configuration to create a new result cache
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		TTL: 0,
+	}
+}
+
+// entry is the cached result of a single IngressConfig.
+type entry struct {
+	expires     time.Time
+	fingerprint string
+}
+
+// Resource implements the result cache resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Internals.
+	entries map[string]entry
+	mutex   sync.Mutex
+	now     func() time.Time
+	// pending are the fingerprints of the reconciliations in progress. They
+	// become entries once the reconciliation succeeded.
+	pending map[string]string
+
+	// Settings.
+	ttl time.Duration
+}
+
+// New creates a new configured result cache resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	// Settings.
+	if config.TTL <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "config.TTL must be greater than 0")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Internals.
+		entries: map[string]entry{},
+		mutex:   sync.Mutex{},
+		now:     time.Now,
+		pending: map[string]string{},
+
+		// Settings.
+		ttl: config.TTL,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -0,0 +1,210 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureCreated self-tests the LB ports of the given IngressConfig, unless they
+// already passed the self-test through the same service. LB ports waiting for
+// certificates are not tested, since they are not applied yet. The result is
+// recorded in the IngressReachable condition and an event is raised whenever
+// it changes. Failing LB ports are tested again in the next reconciliation.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var ports []int
+	for _, p := range key.LBPorts(customObject) {
+		if !cc.CertificatePendingPorts[p] {
+			ports = append(ports, p)
+		}
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+	sort.Ints(ports)
+
+	namespace := cc.IngressController.Namespace
+	name := cc.IngressController.Service
+
+	current, err := conditions.FromCustomObject(customObject)
+	if conditions.IsInvalidAnnotation(err) {
+		// The status resource replaces invalid conditions.
+		current = nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	reachable := fmt.Sprintf("LB ports %s are reachable through service %s/%s", portsString(ports), namespace, name)
+	existing := conditions.Get(current, conditions.IngressReachable)
+	if existing.Status == conditions.StatusTrue && existing.Message == reachable {
+		logging.Debug(ctx, r.logger, "LB ports already passed the self-test")
+		return nil
+	}
+
+	service, err := r.k8sClient.GetService(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		logging.Debug(ctx, r.logger, fmt.Sprintf("did not find service %s/%s", namespace, name))
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if service.Spec.ClusterIP == "" || service.Spec.ClusterIP == corev1.ClusterIPNone {
+		logging.Debug(ctx, r.logger, fmt.Sprintf("not self-testing LB ports, service %s/%s has no cluster IP", namespace, name))
+		return nil
+	}
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("self-testing LB ports %s", portsString(ports)))
+
+	failures := r.probeAll(service.Spec.ClusterIP, ports)
+
+	var c conditions.Condition
+	if len(failures) == 0 {
+		logging.Debug(ctx, r.logger, "LB ports passed the self-test")
+		c = conditions.New(conditions.IngressReachable, conditions.StatusTrue, "", reachable)
+	} else {
+		message := fmt.Sprintf("LB ports are not reachable through service %s/%s: %s", namespace, name, strings.Join(failures, ", "))
+		logging.Warning(ctx, r.logger, message)
+		c = conditions.New(conditions.IngressReachable, conditions.StatusFalse, EventReasonUnreachable, message)
+	}
+	cc.Conditions = conditions.Set(cc.Conditions, c)
+
+	if c.Status == existing.Status && c.Message == existing.Message {
+		return nil
+	}
+
+	// Failing to raise the event must not fail the reconciliation, since the
+	// result is recorded in the condition anyway.
+	_, err = r.k8sClient.CreateEvent(ctx, customObject.Namespace, r.newEvent(customObject, c))
+	if err != nil {
+		logging.Error(ctx, r.logger, "failed raising event for self-tested ingress config", err)
+	}
+
+	return nil
+}
+
+// probeAll probes the given ports of the given IP in parallel and returns the
+// failures in the order of the ports.
+func (r *Resource) probeAll(ip string, ports []int) []string {
+	results := make([]string, len(ports))
+
+	var wg sync.WaitGroup
+	for i, p := range ports {
+		wg.Add(1)
+		go func(i, p int) {
+			defer wg.Done()
+			results[i] = r.probe(net.JoinHostPort(ip, strconv.Itoa(p)), p)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var failures []string
+	for _, f := range results {
+		if f != "" {
+			failures = append(failures, f)
+		}
+	}
+
+	return failures
+}
+
+// probe connects to the given address and waits for the connection to be
+// closed. It returns the reason the given LB port failed or an empty string in
+// case it passed. Connections which receive data or stay open until the
+// timeout pass.
+func (r *Resource) probe(address string, lbPort int) string {
+	conn, err := net.DialTimeout("tcp", address, r.timeout)
+	if err != nil {
+		return fmt.Sprintf("LB port %d refused the connection: %s", lbPort, err.Error())
+	}
+	defer conn.Close()
+
+	err = conn.SetReadDeadline(time.Now().Add(r.timeout))
+	if err != nil {
+		return fmt.Sprintf("LB port %d failed: %s", lbPort, err.Error())
+	}
+
+	_, err = conn.Read(make([]byte, 1))
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return ""
+	} else if err == io.EOF {
+		return fmt.Sprintf("LB port %d closed the connection", lbPort)
+	} else if err != nil {
+		return fmt.Sprintf("LB port %d failed: %s", lbPort, err.Error())
+	}
+
+	return ""
+}
+
+func (r *Resource) newEvent(customObject v1alpha1.IngressConfig, c conditions.Condition) *corev1.Event {
+	now := metav1.NewTime(time.Now())
+
+	reason := EventReasonReachable
+	eventType := corev1.EventTypeNormal
+	if c.Status != conditions.StatusTrue {
+		reason = EventReasonUnreachable
+		eventType = corev1.EventTypeWarning
+	}
+
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", customObject.Name, now.UnixNano()),
+			Namespace: customObject.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      v1alpha1.SchemeGroupVersion.String(),
+			Kind:            "IngressConfig",
+			Name:            customObject.Name,
+			Namespace:       customObject.Namespace,
+			ResourceVersion: customObject.ResourceVersion,
+			UID:             customObject.UID,
+		},
+		Reason:  reason,
+		Message: c.Message,
+		Source: corev1.EventSource{
+			Component: r.projectName,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           eventType,
+	}
+
+	return event
+}
+
+func portsString(ports []int) string {
+	var s []string
+	for _, p := range ports {
+		s = append(s, strconv.Itoa(p))
+	}
+
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,211 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+)
+
+const (
+	portOpen    = "open"
+	portClosing = "closing"
+	portRefused = "refused"
+)
+
+// newTestPort returns a local port behaving like an LB port of the ingress
+// controller. Open ports keep accepted connections open like an ingress
+// controller connected to the guest cluster. Closing ports close accepted
+// connections like an ingress controller failing to connect to the guest
+// cluster. Refused ports do not accept connections at all.
+func newTestPort(t *testing.T, behaviour string) (int, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+
+	if behaviour == portRefused {
+		l.Close()
+		return port, func() {}
+	}
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			if behaviour == portClosing {
+				conn.Close()
+			}
+		}
+	}()
+
+	return port, func() { l.Close() }
+}
+
+func Test_SelfTest_EnsureCreated(t *testing.T) {
+	testCases := []struct {
+		Ports []string
+		// Passed makes the IngressConfig carry the condition of having passed
+		// the self-test already.
+		Passed         bool
+		Pending        bool
+		ExpectedStatus conditions.Status
+		ExpectedEvents int
+	}{
+		// Test 0 ensures LB ports keeping connections open pass.
+		{
+			Ports:          []string{portOpen, portOpen},
+			ExpectedStatus: conditions.StatusTrue,
+			ExpectedEvents: 1,
+		},
+
+		// Test 1 ensures LB ports closing connections fail.
+		{
+			Ports:          []string{portClosing},
+			ExpectedStatus: conditions.StatusFalse,
+			ExpectedEvents: 1,
+		},
+
+		// Test 2 ensures LB ports refusing connections fail.
+		{
+			Ports:          []string{portRefused},
+			ExpectedStatus: conditions.StatusFalse,
+			ExpectedEvents: 1,
+		},
+
+		// Test 3 ensures a single failing LB port fails the self-test.
+		{
+			Ports:          []string{portOpen, portClosing},
+			ExpectedStatus: conditions.StatusFalse,
+			ExpectedEvents: 1,
+		},
+
+		// Test 4 ensures LB ports which passed before are not tested again.
+		{
+			Ports:          []string{portClosing},
+			Passed:         true,
+			ExpectedStatus: conditions.StatusUnknown,
+			ExpectedEvents: 0,
+		},
+
+		// Test 5 ensures LB ports waiting for certificates are not tested.
+		{
+			Ports:          []string{portRefused},
+			Pending:        true,
+			ExpectedStatus: conditions.StatusUnknown,
+			ExpectedEvents: 0,
+		},
+	}
+
+	for i, tc := range testCases {
+		var ports []int
+		for _, b := range tc.Ports {
+			p, closeFunc := newTestPort(t, b)
+			defer closeFunc()
+			ports = append(ports, p)
+		}
+
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "127.0.0.1",
+			},
+		}
+		k8sClient := fake.NewSimpleClientset(service)
+
+		var newResource *Resource
+		{
+			clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+
+			c.ProjectName = "ingress-operator"
+			c.Timeout = 100 * time.Millisecond
+
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		customObject := &v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{},
+				Name:        "al9qy",
+				Namespace:   "default",
+			},
+		}
+		pending := map[int]bool{}
+		for _, p := range ports {
+			customObject.Spec.ProtocolPorts = append(customObject.Spec.ProtocolPorts, v1alpha1.IngressConfigSpecProtocolPort{LBPort: p})
+			pending[p] = tc.Pending
+		}
+		if tc.Passed {
+			message := fmt.Sprintf("LB ports %s are reachable through service kube-system/ingress-controller", portsString(ports))
+			v, err := conditions.ToAnnotation([]conditions.Condition{
+				conditions.New(conditions.IngressReachable, conditions.StatusTrue, "", message),
+			})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			customObject.Annotations[conditions.Annotation] = v
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			CertificatePendingPorts: pending,
+			IngressController: controllercontext.IngressController{
+				ConfigMap: "ingress-controller",
+				Namespace: "kube-system",
+				Service:   "ingress-controller",
+			},
+		})
+
+		err := newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		cc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		c := conditions.Get(cc.Conditions, conditions.IngressReachable)
+		if c.Status != tc.ExpectedStatus {
+			t.Fatalf("test %d expected %s got %#v", i, tc.ExpectedStatus, c)
+		}
+		if c.Status == conditions.StatusFalse && c.Reason != EventReasonUnreachable {
+			t.Fatal("test", i, "expected", EventReasonUnreachable, "got", c.Reason)
+		}
+
+		list, err := k8sClient.CoreV1().Events("default").List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if len(list.Items) != tc.ExpectedEvents {
+			t.Fatal("test", i, "expected", tc.ExpectedEvents, "got", len(list.Items))
+		}
+	}
+}
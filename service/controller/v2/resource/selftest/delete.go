@@ -0,0 +1,11 @@
+package selftest
+
+import (
+	"context"
+)
+
+// EnsureDeleted does nothing. There is no ingress to test for deleted
+// IngressConfigs.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
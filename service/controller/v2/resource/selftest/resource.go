@@ -0,0 +1,111 @@
+// Package selftest implements a resource checking that ingress traffic
+// actually reaches the guest cluster once the LB ports of an IngressConfig got
+// applied. It connects to every LB port through the service of the host
+// cluster ingress controller. The ingress controller accepts connections on
+// its LB ports regardless of the guest cluster, but closes them right away in
+// case it cannot connect to the ingress port in the guest cluster. A
+// connection which stays open or receives data therefore proves that the
+// guest cluster backend is reachable. The result is recorded in the
+// IngressReachable condition and raised as an event, so that provisioning
+// pipelines can wait for working ingress rather than updated config maps.
+package selftest
+
+import (
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "selftestv2"
+)
+
+const (
+	// EventReasonReachable is the reason of the events raised for
+	// IngressConfigs whose LB ports passed the self-test.
+	EventReasonReachable = "IngressReachable"
+	// EventReasonUnreachable is the reason of the events raised for
+	// IngressConfigs whose LB ports failed the self-test. It is also the
+	// reason of the IngressReachable condition.
+	EventReasonUnreachable = "IngressUnreachable"
+)
+
+// Config represents the configuration used to create a new selftest resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// ProjectName is the name of the operator reported as the source of the
+	// events raised for self-tested IngressConfigs.
+	ProjectName string
+	// Timeout is the maximum time connecting to an LB port takes. It is also
+	// the time a connection has to stay open to pass the self-test.
+	Timeout time.Duration
+}
+
+// DefaultConfig provides a default configuration to create a new selftest
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		ProjectName: "",
+		Timeout:     2 * time.Second,
+	}
+}
+
+// Resource implements the selftest resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	projectName string
+	timeout     time.Duration
+}
+
+// New creates a new configured selftest resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	// Settings.
+	if config.ProjectName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.ProjectName must not be empty")
+	}
+	if config.Timeout <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "config.Timeout must be greater than 0")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		projectName: config.ProjectName,
+		timeout:     config.Timeout,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
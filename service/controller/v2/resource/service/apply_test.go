@@ -0,0 +1,611 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/backoff"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/transaction"
+	"github.com/giantswarm/ingress-operator/service/servicepolicy"
+)
+
+// testWrite is a write issued against the Kubernetes API, as recorded by the
+// fake clientset.
+type testWrite struct {
+	Verb      string
+	Resource  string
+	Namespace string
+}
+
+// testWrites returns the writes of the given recorded actions. Reads are
+// ignored.
+func testWrites(actions []k8stesting.Action) []testWrite {
+	var writes []testWrite
+	for _, a := range actions {
+		switch a.GetVerb() {
+		case "get", "list", "watch":
+			continue
+		}
+
+		writes = append(writes, testWrite{
+			Verb:      a.GetVerb(),
+			Resource:  a.GetResource().Resource,
+			Namespace: a.GetNamespace(),
+		})
+	}
+
+	return writes
+}
+
+// newTestCoordinator returns a coordinator retrying twice without delay.
+func newTestCoordinator(t *testing.T, k8sClient clients.Interface) *transaction.Coordinator {
+	c := transaction.Config{
+		K8sClient: k8sClient,
+		Logger:    microloggertest.New(),
+
+		BackOffFactory: func() backoff.Interface { return backoff.NewMaxRetries(2, time.Millisecond) },
+	}
+
+	coordinator, err := transaction.New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return coordinator
+}
+
+func newTestApplyCustomObject(deleted bool) *v1alpha1.IngressConfig {
+	customObject := &v1alpha1.IngressConfig{
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID:        "al9qy",
+				Namespace: "al9qy",
+				Service:   "worker",
+			},
+			HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+				IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+					ConfigMap: "ingress-controller",
+					Namespace: "kube-system",
+					Service:   "ingress-controller",
+				},
+			},
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{
+					IngressPort: 30010,
+					Protocol:    "http",
+					LBPort:      31000,
+				},
+			},
+		},
+	}
+	if deleted {
+		now := metav1.Now()
+		customObject.DeletionTimestamp = &now
+	}
+
+	return customObject
+}
+
+// Test_Service_Apply ensures the resource issues exactly the expected writes
+// against the Kubernetes API and skips writes in case nothing changed.
+func Test_Service_Apply(t *testing.T) {
+	otherPort := apiv1.ServicePort{
+		Name:       "http-30010-p1l6x",
+		Protocol:   apiv1.ProtocolTCP,
+		Port:       int32(31010),
+		TargetPort: intstr.FromInt(31010),
+		NodePort:   int32(31010),
+	}
+	ownPort := apiv1.ServicePort{
+		Name:       "http-30010-al9qy",
+		Protocol:   apiv1.ProtocolTCP,
+		Port:       int32(31000),
+		TargetPort: intstr.FromInt(31000),
+		NodePort:   int32(31000),
+	}
+
+	testCases := []struct {
+		Deleted        bool
+		CurrentPorts   []apiv1.ServicePort
+		ExpectedPorts  []apiv1.ServicePort
+		ExpectedWrites []testWrite
+	}{
+		// Test 0 ensures a missing port is added with a single update.
+		{
+			Deleted:       false,
+			CurrentPorts:  []apiv1.ServicePort{otherPort},
+			ExpectedPorts: []apiv1.ServicePort{otherPort, ownPort},
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "services", Namespace: "kube-system"},
+			},
+		},
+
+		// Test 1 ensures nothing is written in case the service is up to date.
+		{
+			Deleted:        false,
+			CurrentPorts:   []apiv1.ServicePort{ownPort},
+			ExpectedPorts:  []apiv1.ServicePort{ownPort},
+			ExpectedWrites: nil,
+		},
+
+		// Test 2 ensures the ports of a deleted guest cluster are removed with a
+		// single update.
+		{
+			Deleted:       true,
+			CurrentPorts:  []apiv1.ServicePort{ownPort, otherPort},
+			ExpectedPorts: []apiv1.ServicePort{otherPort},
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "services", Namespace: "kube-system"},
+			},
+		},
+
+		// Test 3 ensures nothing is written in case the ports of a deleted guest
+		// cluster are already gone.
+		{
+			Deleted:        true,
+			CurrentPorts:   []apiv1.ServicePort{otherPort},
+			ExpectedPorts:  []apiv1.ServicePort{otherPort},
+			ExpectedWrites: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		k8sClient := fake.NewSimpleClientset(&apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+			Spec: apiv1.ServiceSpec{
+				Ports: tc.CurrentPorts,
+			},
+		})
+
+		var crudResource *controller.CRUDResource
+		{
+			a, err := allocator.New(allocator.Config{})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.Allocator = a
+			c.Coordinator = newTestCoordinator(t, clientsInterface)
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+			c.ProjectName = "ingress-operator"
+
+			ops, err := New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			crudResource, err = controller.NewCRUDResource(controller.CRUDResourceConfig{Logger: microloggertest.New(), Ops: ops})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			IngressController: controllercontext.IngressController{
+				ConfigMap: "ingress-controller",
+				Namespace: "kube-system",
+				Service:   "ingress-controller",
+			},
+		})
+
+		k8sClient.ClearActions()
+
+		var err error
+		if tc.Deleted {
+			err = crudResource.EnsureDeleted(ctx, newTestApplyCustomObject(true))
+		} else {
+			err = crudResource.EnsureCreated(ctx, newTestApplyCustomObject(false))
+		}
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		writes := testWrites(k8sClient.Actions())
+		if !reflect.DeepEqual(writes, tc.ExpectedWrites) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedWrites, writes)
+		}
+
+		service, err := k8sClient.CoreV1().Services("kube-system").Get("ingress-controller", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(service.Spec.Ports, tc.ExpectedPorts) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedPorts, service.Spec.Ports)
+		}
+	}
+}
+
+// Test_Service_Apply_partial ensures failing service updates following a
+// config map update are retried and the config map update is rolled back in
+// case the service still cannot be updated.
+func Test_Service_Apply_partial(t *testing.T) {
+	ownPort := apiv1.ServicePort{
+		Name:       "http-30010-al9qy",
+		Protocol:   apiv1.ProtocolTCP,
+		Port:       int32(31000),
+		TargetPort: intstr.FromInt(31000),
+		NodePort:   int32(31000),
+	}
+
+	testCases := []struct {
+		Partial          bool
+		FailedUpdates    int
+		ExpectedCanceled bool
+		ExpectedData     map[string]string
+		ExpectedPorts    []apiv1.ServicePort
+		ErrorMatcher     func(error) bool
+	}{
+		// Test 0 ensures a failed service update of a partial apply is retried
+		// and the config map update is kept once the retry succeeded.
+		{
+			Partial:          true,
+			FailedUpdates:    1,
+			ExpectedCanceled: false,
+			ExpectedData:     map[string]string{"31000": "al9qy/worker:30010"},
+			ExpectedPorts:    []apiv1.ServicePort{ownPort},
+			ErrorMatcher:     nil,
+		},
+
+		// Test 1 ensures the config map update of a partial apply is rolled back
+		// and the reconciliation is canceled in case the service update keeps
+		// failing.
+		{
+			Partial:          true,
+			FailedUpdates:    10,
+			ExpectedCanceled: true,
+			ExpectedData:     map[string]string{},
+			ExpectedPorts:    nil,
+			ErrorMatcher:     nil,
+		},
+
+		// Test 2 ensures failed service updates are returned as they are in case
+		// the config map did not get updated.
+		{
+			Partial:          false,
+			FailedUpdates:    1,
+			ExpectedCanceled: false,
+			ExpectedData:     map[string]string{"31000": "al9qy/worker:30010"},
+			ExpectedPorts:    nil,
+			ErrorMatcher:     func(err error) bool { return err != nil },
+		},
+	}
+
+	for i, tc := range testCases {
+		k8sClient := fake.NewSimpleClientset(
+			&apiv1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ingress-controller",
+					Namespace: "kube-system",
+				},
+				Data: map[string]string{"31000": "al9qy/worker:30010"},
+			},
+			&apiv1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ingress-controller",
+					Namespace: "kube-system",
+				},
+			},
+		)
+
+		failed := 0
+		k8sClient.PrependReactor("update", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if failed < tc.FailedUpdates {
+				failed++
+				return true, nil, microerror.New("test error")
+			}
+
+			return false, nil, nil
+		})
+
+		var crudResource *controller.CRUDResource
+		{
+			a, err := allocator.New(allocator.Config{})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.Allocator = a
+			c.Coordinator = newTestCoordinator(t, clientsInterface)
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+			c.ProjectName = "ingress-operator"
+
+			ops, err := New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			crudResource, err = controller.NewCRUDResource(controller.CRUDResourceConfig{Logger: microloggertest.New(), Ops: ops})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			IngressController: controllercontext.IngressController{
+				ConfigMap: "ingress-controller",
+				Namespace: "kube-system",
+				Service:   "ingress-controller",
+			},
+		})
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+		cc, err := controllercontext.FromContext(ctx)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.Partial {
+			cc.Transaction.Begin(map[string]string{})
+			cc.Transaction.ConfigMapUpdated(map[string]string{"31000": "al9qy/worker:30010"})
+		}
+
+		err = crudResource.EnsureCreated(ctx, newTestApplyCustomObject(false))
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+
+		canceled := reconciliationcanceledcontext.IsCanceled(ctx)
+		if canceled != tc.ExpectedCanceled {
+			t.Fatal("test", i, "expected", tc.ExpectedCanceled, "got", canceled)
+		}
+		if cc.Transaction.IsPartial() {
+			t.Fatal("test", i, "expected", false, "got", true)
+		}
+
+		configMap, err := k8sClient.CoreV1().ConfigMaps("kube-system").Get("ingress-controller", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(configMap.Data, tc.ExpectedData) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedData, configMap.Data)
+		}
+
+		service, err := k8sClient.CoreV1().Services("kube-system").Get("ingress-controller", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(service.Spec.Ports, tc.ExpectedPorts) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedPorts, service.Spec.Ports)
+		}
+	}
+}
+
+// Test_Service_Apply_policy ensures service fields set by the owner of the
+// ingress controller after the operator read the service are preserved by the
+// service policy.
+func Test_Service_Apply_policy(t *testing.T) {
+	ownPort := apiv1.ServicePort{
+		Name:       "http-30010-al9qy",
+		Protocol:   apiv1.ProtocolTCP,
+		Port:       int32(31000),
+		TargetPort: intstr.FromInt(31000),
+		NodePort:   int32(31000),
+	}
+
+	testCases := []struct {
+		Preserved                        []string
+		ExpectedExternalIPs              []string
+		ExpectedLoadBalancerSourceRanges []string
+		ExpectedWrites                   []testWrite
+	}{
+		// Test 0 ensures the fields set by the owner are overwritten in case
+		// the policy preserves nothing.
+		{
+			Preserved:                        nil,
+			ExpectedExternalIPs:              nil,
+			ExpectedLoadBalancerSourceRanges: nil,
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "services", Namespace: "kube-system"},
+			},
+		},
+
+		// Test 1 ensures the preserved fields set by the owner are kept by the
+		// update.
+		{
+			Preserved:                        []string{"externalIPs", "loadBalancerSourceRanges"},
+			ExpectedExternalIPs:              []string{"10.0.0.1"},
+			ExpectedLoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "services", Namespace: "kube-system"},
+			},
+		},
+
+		// Test 2 ensures only the ports are patched in case the policy preserves
+		// fields unknown to the vendored API types.
+		{
+			Preserved:                        []string{"externalIPs", "topologyKeys"},
+			ExpectedExternalIPs:              []string{"10.0.0.1"},
+			ExpectedLoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+			ExpectedWrites: []testWrite{
+				{Verb: "patch", Resource: "services", Namespace: "kube-system"},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		stale := &apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+		}
+
+		// The service is kept in a separate tracker, since the reactors of the
+		// fake clientset cannot call it while handling the patch.
+		tracker := k8stesting.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())
+		{
+			s := stale.DeepCopy()
+			s.Spec.ExternalIPs = []string{"10.0.0.1"}
+			s.Spec.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+
+			err := tracker.Add(s)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		k8sClient := fake.NewSimpleClientset(&apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+		})
+
+		// The first read of the service returns it as it was before its owner
+		// set the external fields.
+		gets := 0
+		k8sClient.PrependReactor("*", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			switch action.GetVerb() {
+			case "get":
+				gets++
+				if gets == 1 {
+					return true, stale.DeepCopy(), nil
+				}
+			case "patch":
+				return true, nil, testPatchService(tracker, action.(k8stesting.PatchAction).GetPatch())
+			}
+
+			return k8stesting.ObjectReaction(tracker)(action)
+		})
+
+		var crudResource *controller.CRUDResource
+		{
+			a, err := allocator.New(allocator.Config{})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			policy, err := servicepolicy.New(tc.Preserved)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.Allocator = a
+			c.Coordinator = newTestCoordinator(t, clientsInterface)
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+			c.ProjectName = "ingress-operator"
+			c.Policy = policy
+
+			ops, err := New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			crudResource, err = controller.NewCRUDResource(controller.CRUDResourceConfig{Logger: microloggertest.New(), Ops: ops})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			IngressController: controllercontext.IngressController{
+				ConfigMap: "ingress-controller",
+				Namespace: "kube-system",
+				Service:   "ingress-controller",
+			},
+		})
+
+		err := crudResource.EnsureCreated(ctx, newTestApplyCustomObject(false))
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		var writes []testWrite
+		for _, w := range testWrites(k8sClient.Actions()) {
+			if w.Resource == "services" {
+				writes = append(writes, w)
+			}
+		}
+		if !reflect.DeepEqual(writes, tc.ExpectedWrites) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedWrites, writes)
+		}
+
+		obj, err := tracker.Get(apiv1.SchemeGroupVersion.WithResource("services"), "kube-system", "ingress-controller")
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		service := obj.(*apiv1.Service)
+		if !reflect.DeepEqual(service.Spec.Ports, []apiv1.ServicePort{ownPort}) {
+			t.Fatalf("test %d expected %#v got %#v", i, []apiv1.ServicePort{ownPort}, service.Spec.Ports)
+		}
+		if !reflect.DeepEqual(service.Spec.ExternalIPs, tc.ExpectedExternalIPs) {
+			t.Fatal("test", i, "expected", tc.ExpectedExternalIPs, "got", service.Spec.ExternalIPs)
+		}
+		if !reflect.DeepEqual(service.Spec.LoadBalancerSourceRanges, tc.ExpectedLoadBalancerSourceRanges) {
+			t.Fatal("test", i, "expected", tc.ExpectedLoadBalancerSourceRanges, "got", service.Spec.LoadBalancerSourceRanges)
+		}
+	}
+}
+
+// testPatchService applies the given JSON merge patch to the service stored
+// in the given tracker. The patch must only write the ports of the service.
+func testPatchService(tracker k8stesting.ObjectTracker, patch []byte) error {
+	var p struct {
+		Spec map[string]json.RawMessage `json:"spec"`
+	}
+	err := json.Unmarshal(patch, &p)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if len(p.Spec) != 1 || p.Spec["ports"] == nil {
+		return microerror.Newf("expected patch of the ports only, got %s", patch)
+	}
+
+	gvr := apiv1.SchemeGroupVersion.WithResource("services")
+	obj, err := tracker.Get(gvr, "kube-system", "ingress-controller")
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	service := obj.(*apiv1.Service)
+
+	err = json.Unmarshal(p.Spec["ports"], &service.Spec.Ports)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return tracker.Update(gvr, service, "kube-system")
+}
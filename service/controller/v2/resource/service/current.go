@@ -7,27 +7,48 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller/context/finalizerskeptcontext"
 	"github.com/giantswarm/operatorkit/controller/context/resourcecanceledcontext"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiv1 "k8s.io/api/core/v1"
 
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/deletiondelay"
 	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
 )
 
 func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interface{}, error) {
-	customObject, err := toCustomObject(obj)
+	customObject, err := key.ToCustomObject(obj)
 	if err != nil {
-		return microerror.Mask(err), nil
+		return nil, microerror.Mask(err)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", "get current state")
+	logging.Debug(ctx, r.logger, "get current state")
 
-	namespace := customObject.Spec.HostCluster.IngressController.Namespace
-	service := customObject.Spec.HostCluster.IngressController.Service
-	k8sService, err := r.k8sClient.CoreV1().Services(namespace).Get(service, metav1.GetOptions{})
+	cc, err := controllercontext.FromContext(ctx)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found k8s state: %#v", *k8sService))
+	namespace := cc.IngressController.Namespace
+	service := cc.IngressController.Service
+	k8sService, err := r.k8sClient.GetService(ctx, namespace, service)
+	if clients.IsNotFound(err) && key.IsDeleted(customObject) {
+		// In case the service does not exist anymore while the custom object is
+		// being deleted, there is nothing left to delete. This happens e.g. when
+		// a dedicated ingress controller got removed already.
+		logging.Debug(ctx, r.logger, "did not find the service in the Kubernetes API")
+
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("found k8s state: %#v", *k8sService))
+
+	cc.NodePorts = nodePorts(k8sService, key.LBPorts(customObject))
+	// The ports are copied, since the changes are computed on the current
+	// state in place.
+	cc.ServicePorts = append([]apiv1.ServicePort(nil), k8sService.Spec.Ports...)
 
 	// In case a cluster deletion happens, we want to delete the ingress
 	// controller service data. We still need to use it for resource creation in
@@ -38,18 +59,21 @@ func (r *Resource) GetCurrentState(ctx context.Context, obj interface{}) (interf
 	// replayed. Then we just remove the service data as usual.
 	if key.IsDeleted(customObject) {
 		n := key.ClusterNamespace(customObject)
-		list, err := r.k8sClient.CoreV1().Pods(n).List(metav1.ListOptions{})
+		list, err := r.k8sClient.ListPods(ctx, n)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 		if len(list.Items) != 0 {
-			r.logger.LogCtx(ctx, "level", "debug", "message", "cannot finish deletion of namespace due to existing pods")
+			deletiondelay.Wait(customObject, list.Items)
+			logging.Debug(ctx, r.logger, "cannot finish deletion of namespace due to existing pods")
 			resourcecanceledcontext.SetCanceled(ctx)
 			finalizerskeptcontext.SetKept(ctx)
-			r.logger.LogCtx(ctx, "level", "debug", "message", "canceling resource for custom object")
+			logging.Debug(ctx, r.logger, "canceling resource for custom object")
 
 			return nil, nil
 		}
+
+		deletiondelay.Done(customObject)
 	}
 
 	return k8sService, nil
@@ -7,10 +7,17 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller"
 	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/diff"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/pin"
+	"github.com/giantswarm/ingress-operator/service/jitter"
 )
 
 func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange interface{}) error {
-	customObject, err := toCustomObject(obj)
+	cc, err := controllercontext.FromContext(ctx)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -20,17 +27,21 @@ func (r *Resource) ApplyDeleteChange(ctx context.Context, obj, deleteChange inte
 	}
 
 	if serviceToDelete != nil {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "deleting the service data in the Kubernetes API")
+		logging.Debug(ctx, r.logger, "deleting the service data in the Kubernetes API")
+
+		jitter.Sleep(ctx, r.writeJitter)
 
-		namespace := customObject.Spec.HostCluster.IngressController.Namespace
-		_, err := r.k8sClient.CoreV1().Services(namespace).Update(serviceToDelete)
+		namespace := cc.IngressController.Namespace
+		_, err := r.writeService(ctx, namespace, serviceToDelete)
 		if err != nil {
 			return microerror.Mask(err)
 		}
 
-		r.logger.LogCtx(ctx, "level", "debug", "message", "deleted the service data in the Kubernetes API")
+		cc.ServicePorts = recordPortMutations(namespace, serviceToDelete.Name, cc.ServicePorts, serviceToDelete.Spec.Ports)
+
+		logging.Debug(ctx, r.logger, "deleted the service data in the Kubernetes API")
 	} else {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "the service data does not need to be deleted in the Kubernetes API")
+		logging.Debug(ctx, r.logger, "the service data does not need to be deleted in the Kubernetes API")
 	}
 
 	return nil
@@ -49,16 +60,23 @@ func (r *Resource) NewDeletePatch(ctx context.Context, obj, currentState, desire
 }
 
 func (r *Resource) newDeleteChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
 	currentService, err := toService(currentState)
 	if err != nil {
 		return microerror.Mask(err), nil
 	}
+	if currentService == nil {
+		return nil, nil
+	}
 	dState, ok := desiredState.([]apiv1.ServicePort)
 	if !ok {
 		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", []apiv1.ServicePort{}, desiredState)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", "get delete state")
+	logging.Debug(ctx, r.logger, "get delete state")
 
 	// Make sure the current state of the Kubernetes resources is known by the
 	// delete action. The resources we already fetched represent the source of
@@ -75,15 +93,30 @@ func (r *Resource) newDeleteChange(ctx context.Context, obj, currentState, desir
 	// desired state, because a decent reconciliation is not always only an update
 	// operation of existing resources, but e.g. deletion of resources. In our
 	// case here we only transform data within resources. Therefore the update.
+	var changes []diff.Change
 	var newPorts []apiv1.ServicePort
 	for _, p := range deleteState.Spec.Ports {
-		if !inServicePorts(dState, p) {
+		// Service ports of reserved ports are never touched.
+		if inServicePorts(dState, p) && !r.allocator.IsReserved(int(p.Port)) {
+			changes = append(changes, diff.Removed(portKey(p), p.Name))
+		} else {
 			newPorts = append(newPorts, p)
 		}
 	}
 	deleteState.Spec.Ports = newPorts
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found delete state: %#v", deleteState))
+	// Pins of the guest cluster are released together with its service ports.
+	pins, changed := pin.Update(pin.FromAnnotations(deleteState.Annotations), key.ClusterID(customObject), nil)
+	if changed {
+		changes = append(changes, diff.Removed(pin.Annotation, deleteState.Annotations[pin.Annotation]))
+		deleteState.Annotations = pin.ToAnnotations(deleteState.Annotations, pins)
+	}
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("computed delete patch: %s", diff.Render(changes)))
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
 
 	return deleteState, nil
 }
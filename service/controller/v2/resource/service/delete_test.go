@@ -10,6 +10,9 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
 )
 
 func Test_Service_newDeleteChange(t *testing.T) {
@@ -437,15 +440,86 @@ func Test_Service_newDeleteChange(t *testing.T) {
 			},
 			ErrorMatcher: nil,
 		},
+
+		// Test 5 ensures that service ports created from mixed case protocols
+		// are deleted with the normalized desired state.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+						IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+							ConfigMap: "ingress-controller",
+							Namespace: "kube-system",
+							Service:   "ingress-controller",
+						},
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "HTTP",
+							LBPort:      31000,
+						},
+					},
+				},
+			},
+			CurrentState: &apiv1.Service{
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "HTTP-30010-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31000),
+							TargetPort: intstr.FromInt(31000),
+							NodePort:   int32(31000),
+						},
+					},
+				},
+			},
+			DesiredState: []apiv1.ServicePort{
+				{
+					Name:       "http-30010-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31000),
+					TargetPort: intstr.FromInt(31000),
+					NodePort:   int32(31000),
+				},
+			},
+			Expected: &apiv1.Service{
+				Spec: apiv1.ServiceSpec{
+					Ports: nil,
+				},
+			},
+			ErrorMatcher: nil,
+		},
 	}
 
 	var err error
 	var newResource *Resource
 	{
+		var a *allocator.Allocator
+		a, err = allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
 		c := DefaultConfig()
 
-		c.K8sClient = fake.NewSimpleClientset()
+		c.Allocator = a
+		var k8sClient clients.Interface
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c.Coordinator = newTestCoordinator(t, k8sClient)
+		c.K8sClient = k8sClient
 		c.Logger = microloggertest.New()
+		c.ProjectName = "ingress-operator"
 
 		newResource, err = New(c)
 		if err != nil {
@@ -4,28 +4,53 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/microerror"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
 )
 
 func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
-	customObject, err := toCustomObject(obj)
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
 	if err != nil {
-		return microerror.Mask(err), nil
+		return nil, microerror.Mask(err)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", "get desired state")
+	logging.Debug(ctx, r.logger, "get desired state")
+
+	dState := DesiredPorts(cc, customObject)
+
+	logging.Debug(ctx, r.logger, fmt.Sprintf("found desired state: %#v", dState))
+
+	return dState, nil
+}
+
+// DesiredPorts returns the service ports the given custom object requires,
+// forwarding each of its LB ports to the node port of the same number. In case
+// the service is of type LoadBalancer the node ports are left to Kubernetes
+// when computing the update change. LB ports waiting for their certificates
+// and LB ports of disabled protocols are left out.
+func DesiredPorts(cc *controllercontext.Context, customObject v1alpha1.IngressConfig) []apiv1.ServicePort {
+	ports := []apiv1.ServicePort{}
+	for _, p := range key.EnabledProtocolPorts(customObject) {
+		if cc.CertificatePendingPorts[p.LBPort] {
+			continue
+		}
 
-	// Lookup the desired state of the service to have a reference of ports how
-	// they should be.
-	dState := []apiv1.ServicePort{}
-	for _, p := range customObject.Spec.ProtocolPorts {
 		servicePortName := fmt.Sprintf(
 			PortNameFormat,
-			p.Protocol,
-			p.IngressPort,
-			customObject.Spec.GuestCluster.ID,
+			key.Protocol(p),
+			cc.IngressPort(p),
+			key.ClusterID(customObject),
 		)
 
 		newPort := apiv1.ServicePort{
@@ -36,10 +61,8 @@ func (r *Resource) GetDesiredState(ctx context.Context, obj interface{}) (interf
 			NodePort:   int32(p.LBPort),
 		}
 
-		dState = append(dState, newPort)
+		ports = append(ports, newPort)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found desired state: %#v", dState))
-
-	return dState, nil
+	return ports
 }
@@ -10,11 +10,16 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
 )
 
 func Test_Service_GetDesiredState(t *testing.T) {
 	testCases := []struct {
 		Obj          interface{}
+		IngressPorts map[int]int
 		Expected     []apiv1.ServicePort
 		ErrorMatcher func(error) bool
 	}{
@@ -115,15 +120,132 @@ func Test_Service_GetDesiredState(t *testing.T) {
 			},
 			ErrorMatcher: nil,
 		},
+
+		// Test 2 ensures discovered ingress ports take precedence over the ones
+		// of the spec.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+						IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+							ConfigMap: "ingress-controller",
+							Namespace: "kube-system",
+							Service:   "ingress-controller",
+						},
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 0,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+						{
+							IngressPort: 30011,
+							Protocol:    "https",
+							LBPort:      31001,
+						},
+					},
+				},
+			},
+			IngressPorts: map[int]int{
+				31000: 32080,
+			},
+			Expected: []apiv1.ServicePort{
+				{
+					Name:       "http-32080-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31000),
+					TargetPort: intstr.FromInt(31000),
+					NodePort:   int32(31000),
+				},
+				{
+					Name:       "https-30011-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31001),
+					TargetPort: intstr.FromInt(31001),
+					NodePort:   int32(31001),
+				},
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 3 ensures protocols are normalized to lower case.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+						IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+							ConfigMap: "ingress-controller",
+							Namespace: "kube-system",
+							Service:   "ingress-controller",
+						},
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "HTTP",
+							LBPort:      31000,
+						},
+						{
+							IngressPort: 30011,
+							Protocol:    " Https",
+							LBPort:      31001,
+						},
+					},
+				},
+			},
+			Expected: []apiv1.ServicePort{
+				{
+					Name:       "http-30010-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31000),
+					TargetPort: intstr.FromInt(31000),
+					NodePort:   int32(31000),
+				},
+				{
+					Name:       "https-30011-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31001),
+					TargetPort: intstr.FromInt(31001),
+					NodePort:   int32(31001),
+				},
+			},
+			ErrorMatcher: nil,
+		},
 	}
 
 	var err error
 	var newResource *Resource
 	{
+		var a *allocator.Allocator
+		a, err = allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
 		c := DefaultConfig()
 
-		c.K8sClient = fake.NewSimpleClientset()
+		c.Allocator = a
+		var k8sClient clients.Interface
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c.Coordinator = newTestCoordinator(t, k8sClient)
+		c.K8sClient = k8sClient
 		c.Logger = microloggertest.New()
+		c.ProjectName = "ingress-operator"
 
 		newResource, err = New(c)
 		if err != nil {
@@ -132,7 +254,8 @@ func Test_Service_GetDesiredState(t *testing.T) {
 	}
 
 	for i, tc := range testCases {
-		result, err := newResource.GetDesiredState(context.TODO(), tc.Obj)
+		ctx := controllercontext.NewContext(context.TODO(), controllercontext.Context{IngressPorts: tc.IngressPorts})
+		result, err := newResource.GetDesiredState(ctx, tc.Obj)
 		if err != nil && tc.ErrorMatcher == nil {
 			t.Fatal("test", i, "expected", nil, "got", err)
 		}
@@ -13,6 +13,15 @@ func IsInvalidConfig(err error) bool {
 	return microerror.Cause(err) == invalidConfigError
 }
 
+var portPinnedError = &microerror.Error{
+	Kind: "portPinnedError",
+}
+
+// IsPortPinned asserts portPinnedError.
+func IsPortPinned(err error) bool {
+	return microerror.Cause(err) == portPinnedError
+}
+
 var servicePortNotFoundError = &microerror.Error{
 	Kind: "servicePortNotFoundError",
 }
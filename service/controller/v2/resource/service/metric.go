@@ -0,0 +1,67 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "service"
+)
+
+var (
+	correctionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "corrections_total",
+			Help:      "A counter metric expressing the number of updates of the service of an ingress controller because its ports drifted from the desired state.",
+		},
+		[]string{"namespace", "service"},
+	)
+	portMutationsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "port_mutations_total",
+			Help:      "A counter metric expressing the number of ports added to, removed from or updated in the service of an ingress controller. Every mutation causes kube-proxy to update the iptables or ipvs rules of all nodes, so its increase per day quantifies the dataplane churn.",
+		},
+		[]string{"namespace", "service", "mutation"},
+	)
+	preservedFieldsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "preserved_field_resets_total",
+			Help:      "A counter metric expressing the number of times a field of the service of a shared ingress controller preserved by the service policy was about to be changed by the operator and got reset to its current value.",
+		},
+		[]string{"namespace", "service", "field"},
+	)
+	repairedPortNamesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "repaired_port_names_total",
+			Help:      "A counter metric expressing the number of duplicated port names of the service of a shared ingress controller rewritten to canonical names.",
+		},
+		[]string{"namespace", "service"},
+	)
+	stalePortNamesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "stale_port_names_total",
+			Help:      "A counter metric expressing the number of service ports of the service of an ingress controller named after the reconciled guest cluster ID, but left behind by an earlier guest cluster with the same ID or an earlier spec, and overwritten with the desired ports.",
+		},
+		[]string{"namespace", "service"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(correctionsCounter)
+	prometheus.MustRegister(portMutationsCounter)
+	prometheus.MustRegister(preservedFieldsCounter)
+	prometheus.MustRegister(repairedPortNamesCounter)
+	prometheus.MustRegister(stalePortNamesCounter)
+}
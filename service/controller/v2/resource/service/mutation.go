@@ -0,0 +1,52 @@
+package service
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const (
+	mutationAdded   = "added"
+	mutationRemoved = "removed"
+	mutationUpdated = "updated"
+)
+
+// portMutations returns the number of ports added, removed and updated by
+// writing the given after ports over the given before ports, by mutation.
+// Ports are identified by their port, like the changes of the patches.
+func portMutations(before, after []apiv1.ServicePort) map[string]int {
+	mutations := map[string]int{}
+
+	b := map[string]apiv1.ServicePort{}
+	for _, p := range before {
+		b[portKey(p)] = p
+	}
+	a := map[string]bool{}
+	for _, p := range after {
+		a[portKey(p)] = true
+
+		old, ok := b[portKey(p)]
+		if !ok {
+			mutations[mutationAdded]++
+		} else if old.String() != p.String() {
+			mutations[mutationUpdated]++
+		}
+	}
+	for k := range b {
+		if !a[k] {
+			mutations[mutationRemoved]++
+		}
+	}
+
+	return mutations
+}
+
+// recordPortMutations counts the port mutations of the given service written
+// with the given after ports and returns the after ports, which are the before
+// ports of the next write within the same reconciliation loop.
+func recordPortMutations(namespace, service string, before, after []apiv1.ServicePort) []apiv1.ServicePort {
+	for mutation, n := range portMutations(before, after) {
+		portMutationsCounter.WithLabelValues(namespace, service, mutation).Add(float64(n))
+	}
+
+	return append([]apiv1.ServicePort(nil), after...)
+}
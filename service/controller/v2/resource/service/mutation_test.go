@@ -0,0 +1,66 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_Service_portMutations(t *testing.T) {
+	testCases := []struct {
+		Before   []apiv1.ServicePort
+		After    []apiv1.ServicePort
+		Expected map[string]int
+	}{
+		// Test 0 ensures unchanged ports are no mutations.
+		{
+			Before: []apiv1.ServicePort{
+				{Name: "http-30010-al9qy", Port: 30010, NodePort: 30010, TargetPort: intstr.FromInt(30010)},
+			},
+			After: []apiv1.ServicePort{
+				{Name: "http-30010-al9qy", Port: 30010, NodePort: 30010, TargetPort: intstr.FromInt(30010)},
+			},
+			Expected: map[string]int{},
+		},
+
+		// Test 1 ensures added, removed and updated ports are counted.
+		{
+			Before: []apiv1.ServicePort{
+				{Name: "http-30010-al9qy", Port: 30010, NodePort: 30010},
+				{Name: "https-30011-al9qy", Port: 30011, NodePort: 30011},
+				{Name: "http-30012-p1l6x", Port: 30012, NodePort: 30012},
+			},
+			After: []apiv1.ServicePort{
+				{Name: "http-30010-al9qy", Port: 30010, NodePort: 30010},
+				{Name: "https-30011-x7a2b", Port: 30011, NodePort: 30011},
+				{Name: "http-30013-x7a2b", Port: 30013, NodePort: 30013},
+				{Name: "https-30014-x7a2b", Port: 30014, NodePort: 30014},
+			},
+			Expected: map[string]int{
+				mutationAdded:   2,
+				mutationRemoved: 1,
+				mutationUpdated: 1,
+			},
+		},
+
+		// Test 2 ensures all ports of a new service are added.
+		{
+			Before: nil,
+			After: []apiv1.ServicePort{
+				{Name: "http-30010-al9qy", Port: 30010, NodePort: 30010},
+			},
+			Expected: map[string]int{
+				mutationAdded: 1,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		mutations := portMutations(tc.Before, tc.After)
+		if !reflect.DeepEqual(mutations, tc.Expected) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, mutations)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/diff"
+)
+
+// orphanedClusterID is the guest cluster ID canonical port names are rendered
+// with in case the guest cluster owning the port is unknown.
+const orphanedClusterID = "orphaned"
+
+// repairDuplicatedNames rewrites the names of service ports sharing their name
+// with another port of the same service, which historic bugs left behind and
+// which the Kubernetes API refuses on any further update. The given owned
+// ports keep their names, since these are the desired names of the reconciled
+// guest cluster. Of the remaining ports sharing a name, the first one keeps
+// it. All others get the canonical name of ports not owned by the reconciled
+// guest cluster, see canonicalName. The given pins map LB ports to the guest
+// clusters owning them. The renames are returned as changes.
+func repairDuplicatedNames(ports []apiv1.ServicePort, owned []apiv1.ServicePort, pins map[int]string) []diff.Change {
+	ownedNames := map[string]int32{}
+	for _, p := range owned {
+		ownedNames[p.Name] = p.Port
+	}
+
+	var changes []diff.Change
+	seen := map[string]bool{}
+	for i, p := range ports {
+		if p.Name == "" {
+			continue
+		}
+
+		port, isOwned := ownedNames[p.Name]
+		if isOwned && port == p.Port {
+			continue
+		}
+		if !isOwned && !seen[p.Name] {
+			seen[p.Name] = true
+			continue
+		}
+
+		name := canonicalName(p, pins)
+		ports[i].Name = name
+		changes = append(changes, diff.Updated(portKey(p), p.Name, name))
+	}
+
+	return changes
+}
+
+// canonicalName returns the canonical name of a service port whose name
+// cannot be trusted, in the format of PortNameFormat. The protocol is the
+// first segment of the current name. The ingress port of a port not owned by
+// the reconciled guest cluster is unknown, so the LB port takes its place,
+// which keeps the name unique because LB ports are unique. The guest cluster
+// is the one the port is pinned to, or the one of the current name otherwise,
+// so that the port stays attributed to its guest cluster.
+func canonicalName(p apiv1.ServicePort, pins map[int]string) string {
+	protocol := strings.SplitN(p.Name, "-", 2)[0]
+
+	clusterID, ok := pins[int(p.Port)]
+	if !ok {
+		clusterID, ok = portNameClusterID(p.Name)
+	}
+	if !ok {
+		clusterID = orphanedClusterID
+	}
+
+	return fmt.Sprintf(PortNameFormat, protocol, p.Port, clusterID)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func Test_Service_canonicalName(t *testing.T) {
+	testCases := []struct {
+		Port     apiv1.ServicePort
+		Pins     map[int]string
+		Expected string
+	}{
+		// Test 0 ensures the guest cluster ID of the current name is kept.
+		{
+			Port:     apiv1.ServicePort{Name: "http-30010-al9qy", Port: 31005},
+			Pins:     map[int]string{},
+			Expected: "http-31005-al9qy",
+		},
+		// Test 1 ensures the guest cluster the port is pinned to takes
+		// precedence over the one of the current name.
+		{
+			Port:     apiv1.ServicePort{Name: "http-30010-al9qy", Port: 31005},
+			Pins:     map[int]string{31005: "p1l6x"},
+			Expected: "http-31005-p1l6x",
+		},
+		// Test 2 ensures ports of unknown guest clusters are named orphaned.
+		{
+			Port:     apiv1.ServicePort{Name: "https", Port: 31002},
+			Pins:     map[int]string{31000: "al9qy"},
+			Expected: "https-31002-orphaned",
+		},
+	}
+
+	for i, tc := range testCases {
+		name := canonicalName(tc.Port, tc.Pins)
+		if name != tc.Expected {
+			t.Fatal("test", i, "expected", tc.Expected, "got", name)
+		}
+	}
+}
@@ -1,11 +1,18 @@
 package service
 
 import (
-	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	apiv1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/transaction"
+	"github.com/giantswarm/ingress-operator/service/servicepolicy"
 )
 
 const (
@@ -24,8 +31,24 @@ const (
 // Config represents the configuration used to create a new service.
 type Config struct {
 	// Dependencies.
-	K8sClient kubernetes.Interface
-	Logger    micrologger.Logger
+	Allocator   *allocator.Allocator
+	Coordinator *transaction.Coordinator
+	K8sClient   clients.Interface
+	Logger      micrologger.Logger
+
+	// Settings.
+
+	// Policy is the policy of the service spec fields the operator must
+	// preserve verbatim. The zero value preserves nothing.
+	Policy servicepolicy.Policy
+	// ProjectName is the name of the operator reported as the source of the
+	// events raised for replaced stale port names.
+	ProjectName string
+	// WriteJitter is the maximum of the randomized delay before writes to the
+	// Kubernetes API. Writes to objects shared by many guest clusters are
+	// delayed to break synchronized retries of conflicting writes. Zero
+	// disables the delay.
+	WriteJitter time.Duration
 }
 
 // DefaultConfig provides a default configuration to create a new service by
@@ -33,21 +56,41 @@ type Config struct {
 func DefaultConfig() Config {
 	return Config{
 		// Dependencies.
-		K8sClient: nil,
-		Logger:    nil,
+		Allocator:   nil,
+		Coordinator: nil,
+		K8sClient:   nil,
+		Logger:      nil,
+
+		// Settings.
+		Policy:      servicepolicy.Policy{},
+		ProjectName: "",
+		WriteJitter: 0,
 	}
 }
 
 // Resource implements the service.
 type Resource struct {
 	// Dependencies.
-	k8sClient kubernetes.Interface
-	logger    micrologger.Logger
+	allocator   *allocator.Allocator
+	coordinator *transaction.Coordinator
+	k8sClient   clients.Interface
+	logger      micrologger.Logger
+
+	// Settings.
+	policy      servicepolicy.Policy
+	projectName string
+	writeJitter time.Duration
 }
 
 // New creates a new configured service.
 func New(config Config) (*Resource, error) {
 	// Dependencies.
+	if config.Allocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Allocator must not be empty")
+	}
+	if config.Coordinator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Coordinator must not be empty")
+	}
 	if config.K8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
 	}
@@ -55,10 +98,22 @@ func New(config Config) (*Resource, error) {
 		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
 	}
 
+	// Settings.
+	if config.ProjectName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.ProjectName must not be empty")
+	}
+
 	newService := &Resource{
 		// Dependencies.
-		k8sClient: config.K8sClient,
-		logger:    config.Logger.With("resource", Name),
+		allocator:   config.Allocator,
+		coordinator: config.Coordinator,
+		k8sClient:   config.K8sClient,
+		logger:      config.Logger.With("resource", Name),
+
+		// Settings.
+		policy:      config.Policy,
+		projectName: config.ProjectName,
+		writeJitter: config.WriteJitter,
 	}
 
 	return newService, nil
@@ -68,8 +123,14 @@ func (r *Resource) Name() string {
 	return Name
 }
 
+// inServicePorts returns whether the given port is one of the given ports.
+// Port names are compared case insensitively, so that ports created from
+// IngressConfigs with mixed case protocols are still found after protocols got
+// normalized.
 func inServicePorts(ports []apiv1.ServicePort, p apiv1.ServicePort) bool {
+	p.Name = strings.ToLower(p.Name)
 	for _, pp := range ports {
+		pp.Name = strings.ToLower(pp.Name)
 		if pp.String() == p.String() {
 			return true
 		}
@@ -88,14 +149,30 @@ func getServicePortByPort(list []apiv1.ServicePort, item int32) (apiv1.ServicePo
 	return apiv1.ServicePort{}, microerror.Maskf(servicePortNotFoundError, "no service port with port '%d'", item)
 }
 
-func toCustomObject(v interface{}) (v1alpha1.IngressConfig, error) {
-	customObjectPointer, ok := v.(*v1alpha1.IngressConfig)
-	if !ok {
-		return v1alpha1.IngressConfig{}, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &v1alpha1.IngressConfig{}, v)
+// nodePorts returns the node ports of the given service by LB port, limited to
+// the given LB ports. It is nil unless the service is of type LoadBalancer,
+// since node ports equal LB ports otherwise.
+func nodePorts(service *apiv1.Service, lbPorts []int) map[int]int {
+	if service == nil || service.Spec.Type != apiv1.ServiceTypeLoadBalancer {
+		return nil
+	}
+
+	m := map[int]int{}
+	for _, lbPort := range lbPorts {
+		p, err := getServicePortByPort(service.Spec.Ports, int32(lbPort))
+		if err != nil || p.NodePort == 0 {
+			continue
+		}
+		m[lbPort] = int(p.NodePort)
 	}
-	customObject := *customObjectPointer
 
-	return customObject, nil
+	return m
+}
+
+// portKey returns the key used to identify the given service port when
+// rendering diffs.
+func portKey(p apiv1.ServicePort) string {
+	return strconv.Itoa(int(p.Port))
 }
 
 func toService(v interface{}) (*apiv1.Service, error) {
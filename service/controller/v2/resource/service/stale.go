@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/diff"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+const (
+	// StalePortNamesEventReason is the reason of the events raised for
+	// IngressConfigs whose LB ports still carried stale port names, see
+	// classifyRename.
+	StalePortNamesEventReason = "StalePortNamesReplaced"
+)
+
+const (
+	renameNormalized = "normalized"
+	renameOrphaned   = "orphaned"
+	renameStale      = "stale"
+)
+
+// classifyRename returns why the given current port serving the LB port of the
+// given desired port has to be renamed to the desired name. Guest cluster IDs
+// are reused once their guest cluster got deleted, so ports left behind by a
+// deleted guest cluster carry the ID of the new guest cluster, e.g.
+// http-30010-al9qy, while the new guest cluster requests the same LB port with
+// another ingress port or protocol, e.g. http-30011-al9qy. These ports are
+// stale, the same as the ports of an earlier spec of the guest cluster, which
+// cannot be told apart. Ports named after other guest clusters are orphaned.
+// Ports only differing in case are normalized.
+func classifyRename(clusterID string, current, desired apiv1.ServicePort) string {
+	if strings.EqualFold(current.Name, desired.Name) {
+		return renameNormalized
+	}
+
+	id, ok := portNameClusterID(current.Name)
+	if ok && strings.EqualFold(id, clusterID) {
+		return renameStale
+	}
+
+	return renameOrphaned
+}
+
+// portNameClusterID returns the guest cluster ID of the given port name in
+// the format of PortNameFormat and whether the name has this format.
+func portNameClusterID(name string) (string, bool) {
+	parts := strings.SplitN(name, "-", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return "", false
+	}
+	_, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", false
+	}
+
+	return parts[2], true
+}
+
+// stalePortNames returns the renames of stale ports, see classifyRename, done
+// by writing the given after ports over the given before ports of the guest
+// cluster with the given ID. Ports are identified by their port.
+func stalePortNames(clusterID string, before, after []apiv1.ServicePort) []diff.Change {
+	b := map[int32]apiv1.ServicePort{}
+	for _, p := range before {
+		b[p.Port] = p
+	}
+
+	var changes []diff.Change
+	for _, p := range after {
+		old, ok := b[p.Port]
+		if !ok || old.Name == p.Name {
+			continue
+		}
+		if classifyRename(clusterID, old, p) != renameStale {
+			continue
+		}
+
+		changes = append(changes, diff.Updated(portKey(p), old.Name, p.Name))
+	}
+
+	return changes
+}
+
+// raiseStalePortNamesEvent raises an event for the given custom object listing
+// the given renames of stale ports. Failing to raise the event must not fail
+// the reconciliation, since the service already got updated, so the error is
+// only logged.
+func (r *Resource) raiseStalePortNamesEvent(ctx context.Context, customObject v1alpha1.IngressConfig, changes []diff.Change) {
+	message := fmt.Sprintf("replaced %d stale service port names of guest cluster %s, left behind by an earlier guest cluster with the same ID or an earlier spec: %s", len(changes), key.ClusterID(customObject), diff.Render(changes))
+
+	_, err := r.k8sClient.CreateEvent(ctx, customObject.Namespace, r.newEvent(customObject, message))
+	if err != nil {
+		logging.Error(ctx, r.logger, "failed raising event for stale service port names", err)
+	}
+}
+
+func (r *Resource) newEvent(customObject v1alpha1.IngressConfig, message string) *apiv1.Event {
+	now := metav1.NewTime(time.Now())
+
+	event := &apiv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", customObject.Name, now.UnixNano()),
+			Namespace: customObject.Namespace,
+		},
+		InvolvedObject: apiv1.ObjectReference{
+			APIVersion:      v1alpha1.SchemeGroupVersion.String(),
+			Kind:            "IngressConfig",
+			Name:            customObject.Name,
+			Namespace:       customObject.Namespace,
+			ResourceVersion: customObject.ResourceVersion,
+			UID:             customObject.UID,
+		},
+		Reason:  StalePortNamesEventReason,
+		Message: message,
+		Source: apiv1.EventSource{
+			Component: r.projectName,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           apiv1.EventTypeWarning,
+	}
+
+	return event
+}
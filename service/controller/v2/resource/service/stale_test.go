@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+)
+
+func Test_Service_classifyRename(t *testing.T) {
+	testCases := []struct {
+		Current  string
+		Desired  string
+		Expected string
+	}{
+		// Test 0 ensures ports only differing in case are normalized.
+		{
+			Current:  "HTTP-30010-al9qy",
+			Desired:  "http-30010-al9qy",
+			Expected: renameNormalized,
+		},
+		// Test 1 ensures ports named after the same guest cluster ID with
+		// another ingress port are stale.
+		{
+			Current:  "http-30020-al9qy",
+			Desired:  "http-30010-al9qy",
+			Expected: renameStale,
+		},
+		// Test 2 ensures ports named after the same guest cluster ID with
+		// another protocol are stale, regardless of the case of the ID.
+		{
+			Current:  "https-30010-AL9QY",
+			Desired:  "http-30010-al9qy",
+			Expected: renameStale,
+		},
+		// Test 3 ensures ports named after other guest clusters are orphaned.
+		{
+			Current:  "http-30010-p1l6x",
+			Desired:  "http-30010-al9qy",
+			Expected: renameOrphaned,
+		},
+		// Test 4 ensures ports not named in the port name format are orphaned.
+		{
+			Current:  "http-al9qy",
+			Desired:  "http-30010-al9qy",
+			Expected: renameOrphaned,
+		},
+	}
+
+	for i, tc := range testCases {
+		result := classifyRename("al9qy", apiv1.ServicePort{Name: tc.Current}, apiv1.ServicePort{Name: tc.Desired})
+		if result != tc.Expected {
+			t.Fatal("test", i, "expected", tc.Expected, "got", result)
+		}
+	}
+}
+
+// Test_Service_Apply_stalePortNames ensures the LB ports of a reused guest
+// cluster ID still carrying the port names of the earlier guest cluster are
+// overwritten and reported by an event, while orphaned and normalized ports
+// are overwritten silently.
+func Test_Service_Apply_stalePortNames(t *testing.T) {
+	desiredPort := apiv1.ServicePort{
+		Name:       "http-30010-al9qy",
+		Protocol:   apiv1.ProtocolTCP,
+		Port:       int32(31000),
+		TargetPort: intstr.FromInt(31000),
+		NodePort:   int32(31000),
+	}
+
+	testCases := []struct {
+		CurrentName     string
+		ExpectedWrites  []testWrite
+		ExpectedMessage string
+	}{
+		// Test 0 ensures a port of the earlier guest cluster with another
+		// ingress port is reported.
+		{
+			CurrentName: "http-30020-al9qy",
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "services", Namespace: "kube-system"},
+				{Verb: "create", Resource: "events", Namespace: "default"},
+			},
+			ExpectedMessage: "31000 http-30020-al9qy -> http-30010-al9qy",
+		},
+		// Test 1 ensures a port of the earlier guest cluster with another
+		// protocol is reported.
+		{
+			CurrentName: "https-30010-al9qy",
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "services", Namespace: "kube-system"},
+				{Verb: "create", Resource: "events", Namespace: "default"},
+			},
+			ExpectedMessage: "31000 https-30010-al9qy -> http-30010-al9qy",
+		},
+		// Test 2 ensures an orphaned port of another guest cluster is not
+		// reported.
+		{
+			CurrentName: "http-30010-p1l6x",
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "services", Namespace: "kube-system"},
+			},
+		},
+		// Test 3 ensures a port with mixed case protocol is not reported.
+		{
+			CurrentName: "HTTP-30010-al9qy",
+			ExpectedWrites: []testWrite{
+				{Verb: "update", Resource: "services", Namespace: "kube-system"},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		currentPort := desiredPort
+		currentPort.Name = tc.CurrentName
+
+		k8sClient := fake.NewSimpleClientset(&apiv1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+			Spec: apiv1.ServiceSpec{
+				Ports: []apiv1.ServicePort{currentPort},
+			},
+		})
+
+		var crudResource *controller.CRUDResource
+		{
+			a, err := allocator.New(allocator.Config{})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.Allocator = a
+			c.Coordinator = newTestCoordinator(t, clientsInterface)
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+			c.ProjectName = "ingress-operator"
+
+			ops, err := New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			crudResource, err = controller.NewCRUDResource(controller.CRUDResourceConfig{Logger: microloggertest.New(), Ops: ops})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			IngressController: controllercontext.IngressController{
+				ConfigMap: "ingress-controller",
+				Namespace: "kube-system",
+				Service:   "ingress-controller",
+			},
+		})
+
+		k8sClient.ClearActions()
+
+		customObject := newTestApplyCustomObject(false)
+		customObject.Name = "al9qy"
+		customObject.Namespace = "default"
+
+		err := crudResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		writes := testWrites(k8sClient.Actions())
+		if !reflect.DeepEqual(writes, tc.ExpectedWrites) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedWrites, writes)
+		}
+
+		service, err := k8sClient.CoreV1().Services("kube-system").Get("ingress-controller", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(service.Spec.Ports, []apiv1.ServicePort{desiredPort}) {
+			t.Fatalf("test %d expected %#v got %#v", i, []apiv1.ServicePort{desiredPort}, service.Spec.Ports)
+		}
+
+		events, err := k8sClient.CoreV1().Events("default").List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ExpectedMessage == "" {
+			if len(events.Items) != 0 {
+				t.Fatal("test", i, "expected", 0, "got", len(events.Items))
+			}
+			continue
+		}
+		if len(events.Items) != 1 {
+			t.Fatal("test", i, "expected", 1, "got", len(events.Items))
+		}
+		e := events.Items[0]
+		if e.Reason != StalePortNamesEventReason {
+			t.Fatal("test", i, "expected", StalePortNamesEventReason, "got", e.Reason)
+		}
+		if e.InvolvedObject.Name != "al9qy" {
+			t.Fatal("test", i, "expected", "al9qy", "got", e.InvolvedObject.Name)
+		}
+		if !strings.HasSuffix(e.Message, tc.ExpectedMessage) {
+			t.Fatal("test", i, "expected", tc.ExpectedMessage, "got", e.Message)
+		}
+	}
+}
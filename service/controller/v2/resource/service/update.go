@@ -3,14 +3,31 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
 	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/diff"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/pin"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/transaction"
+	"github.com/giantswarm/ingress-operator/service/jitter"
 )
 
 func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
-	customObject, err := toCustomObject(obj)
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cc, err := controllercontext.FromContext(ctx)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -19,19 +36,33 @@ func (r *Resource) ApplyUpdateChange(ctx context.Context, obj, updateChange inte
 		return microerror.Mask(err)
 	}
 
-	if serviceToUpdate != nil {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "updating the service data in the Kubernetes API")
-
-		namespace := customObject.Spec.HostCluster.IngressController.Namespace
-		_, err := r.k8sClient.CoreV1().Services(namespace).Update(serviceToUpdate)
-		if err != nil {
-			return microerror.Mask(err)
+	err = r.updateService(ctx, customObject, serviceToUpdate, r.writeJitter)
+	if err != nil && cc.Transaction.IsPartial() {
+		// The config map already routes the traffic of the desired service
+		// ports. Leaving it updated on its own breaks that traffic, so the
+		// service update is retried right away and the config map update is
+		// rolled back in case it keeps failing.
+		retry := func() error {
+			return r.retryUpdate(ctx, obj)
 		}
 
-		r.logger.LogCtx(ctx, "level", "debug", "message", "updated the service data in the Kubernetes API")
-	} else {
-		r.logger.LogCtx(ctx, "level", "debug", "message", "the service data does not need to be updated in the Kubernetes API")
+		namespace := cc.IngressController.Namespace
+		configMap := cc.IngressController.ConfigMap
+		err = r.coordinator.Resolve(ctx, &cc.Transaction, namespace, configMap, retry)
+		if transaction.IsRolledBack(err) {
+			logging.Error(ctx, r.logger, "failed to update the service data in the Kubernetes API", err)
+			reconciliationcanceledcontext.SetCanceled(ctx)
+			logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+			return nil
+		}
 	}
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc.Transaction.Commit()
+	cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.ServiceSynced, conditions.StatusTrue, "", ""))
 
 	return nil
 }
@@ -48,7 +79,100 @@ func (r *Resource) NewUpdatePatch(ctx context.Context, obj, currentState, desire
 	return patch, nil
 }
 
+// retryUpdate computes and applies the update of the service from its current
+// state without any write jitter.
+func (r *Resource) retryUpdate(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	currentState, err := r.GetCurrentState(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if currentState == nil {
+		return nil
+	}
+	desiredState, err := r.GetDesiredState(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	updateChange, err := r.newUpdateChange(ctx, obj, currentState, desiredState)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	serviceToUpdate, err := toService(updateChange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = r.updateService(ctx, customObject, serviceToUpdate, 0)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// updateService updates the given service in case it is not nil, after a
+// random delay of at most the given write jitter.
+func (r *Resource) updateService(ctx context.Context, customObject v1alpha1.IngressConfig, serviceToUpdate *apiv1.Service, writeJitter time.Duration) error {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if serviceToUpdate != nil {
+		logging.Debug(ctx, r.logger, "updating the service data in the Kubernetes API")
+
+		jitter.Sleep(ctx, writeJitter)
+
+		namespace := cc.IngressController.Namespace
+		updated, err := r.writeService(ctx, namespace, serviceToUpdate)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		// Node ports of services of type LoadBalancer are assigned by
+		// Kubernetes, so they are only known once the service got updated.
+		cc.NodePorts = nodePorts(updated, key.LBPorts(customObject))
+		cc.Corrections++
+		correctionsCounter.WithLabelValues(namespace, serviceToUpdate.Name).Inc()
+
+		stale := stalePortNames(key.ClusterID(customObject), cc.ServicePorts, serviceToUpdate.Spec.Ports)
+		if len(stale) > 0 {
+			r.raiseStalePortNamesEvent(ctx, customObject, stale)
+		}
+
+		cc.ServicePorts = recordPortMutations(namespace, serviceToUpdate.Name, cc.ServicePorts, serviceToUpdate.Spec.Ports)
+
+		logging.Debug(ctx, r.logger, "updated the service data in the Kubernetes API")
+	} else {
+		logging.Debug(ctx, r.logger, "the service data does not need to be updated in the Kubernetes API")
+	}
+
+	return nil
+}
+
 func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desiredState interface{}) (interface{}, error) {
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	// Reserved ports must never be handed to any guest cluster.
+	err = r.allocator.Validate(key.LBPorts(customObject))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	pinned, err := key.PinnedPorts(customObject)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
 	currentService, err := toService(currentState)
 	if err != nil {
 		return microerror.Mask(err), nil
@@ -58,41 +182,122 @@ func (r *Resource) newUpdateChange(ctx context.Context, obj, currentState, desir
 		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", []apiv1.ServicePort{}, desiredState)
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", "finding out which service ports have to be updated")
+	logging.Debug(ctx, r.logger, "finding out which service ports have to be updated")
 
 	var serviceToUpdate *apiv1.Service
-	var count int
+	var changes []diff.Change
 	{
 		// TODO use DeepCopy to create a copy of the current service to prevent
 		// weird side effects as soon as the method it available.
 
+		clusterID := key.ClusterID(customObject)
+		pins := pin.FromAnnotations(currentService.Annotations)
+
+		// Services of type LoadBalancer get their node ports assigned by
+		// Kubernetes, so node ports must neither be set for new ports nor be
+		// changed for existing ones.
+		loadBalancer := currentService.Spec.Type == apiv1.ServiceTypeLoadBalancer
+
 		for _, desiredPort := range desiredPorts {
+			// Ports pinned to another guest cluster must never be reallocated, not
+			// even when they look orphaned.
+			owner, ok := pin.Conflict(pins, clusterID, int(desiredPort.Port))
+			if ok {
+				return nil, microerror.Maskf(portPinnedError, "port %d is pinned to guest cluster %s", desiredPort.Port, owner)
+			}
+
 			currentPort, err := getServicePortByPort(currentService.Spec.Ports, desiredPort.Port)
+			if loadBalancer {
+				desiredPort.NodePort = currentPort.NodePort
+			}
 			if IsServicePortNotFound(err) {
 				currentService.Spec.Ports = append(currentService.Spec.Ports, desiredPort)
-				count++
+				changes = append(changes, diff.Added(portKey(desiredPort), desiredPort.Name))
 				continue
 			}
 
 			if currentPort.Name != desiredPort.Name {
-				r.logger.LogCtx(ctx, "level", "warning", "message", "found orphaned service port, overwriting it with desired service port")
+				switch classifyRename(clusterID, currentPort, desiredPort) {
+				case renameNormalized:
+					logging.Debug(ctx, r.logger, fmt.Sprintf("found service port %#q with mixed case protocol, normalizing it", currentPort.Name))
+				case renameStale:
+					logging.Warning(ctx, r.logger, fmt.Sprintf("found stale service port %#q of an earlier guest cluster with the same ID or an earlier spec, overwriting it with desired service port %#q", currentPort.Name, desiredPort.Name))
+					stalePortNamesCounter.WithLabelValues(currentService.Namespace, currentService.Name).Inc()
+				default:
+					logging.Warning(ctx, r.logger, "found orphaned service port, overwriting it with desired service port")
+				}
 
 				for i, cp := range currentService.Spec.Ports {
 					if cp.Port == desiredPort.Port {
 						currentService.Spec.Ports[i] = desiredPort
-						count++
+						changes = append(changes, diff.Updated(portKey(desiredPort), cp.Name, desiredPort.Name))
 						break
 					}
 				}
 			}
 		}
 
-		if count > 0 {
+		// Ports of disabled protocols created before the protocols got
+		// disabled are removed, as long as they are still owned by the guest
+		// cluster. Their config map entries have to be removed first, so the
+		// removals are deferred until the config map resource applied its
+		// changes.
+		var ports []apiv1.ServicePort
+		for _, cp := range currentService.Spec.Ports {
+			if !isDisabledPort(customObject, cp) {
+				ports = append(ports, cp)
+				continue
+			}
+			if !cc.ConfigMapApplied {
+				cc.ServicePortRemovalsDeferred = true
+				ports = append(ports, cp)
+				continue
+			}
+
+			changes = append(changes, diff.Removed(portKey(cp), cp.Name))
+		}
+		currentService.Spec.Ports = ports
+
+		// Duplicated port names are repaired after the owned ports got their
+		// desired names, so that owned ports always keep them.
+		repaired := repairDuplicatedNames(currentService.Spec.Ports, desiredPorts, pins)
+		if len(repaired) > 0 {
+			logging.Warning(ctx, r.logger, fmt.Sprintf("found %d service ports with duplicated names, rewriting them to canonical names: %s", len(repaired), diff.Render(repaired)))
+			repairedPortNamesCounter.WithLabelValues(currentService.Namespace, currentService.Name).Add(float64(len(repaired)))
+			changes = append(changes, repaired...)
+		}
+
+		updatedPins, changed := pin.Update(pins, clusterID, pinned)
+		if changed {
+			old := currentService.Annotations[pin.Annotation]
+			currentService.Annotations = pin.ToAnnotations(currentService.Annotations, updatedPins)
+			changes = append(changes, diff.Updated(pin.Annotation, old, currentService.Annotations[pin.Annotation]))
+		}
+
+		if len(changes) > 0 {
 			serviceToUpdate = currentService
 		}
 	}
 
-	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("found %d service ports that have to be updated", count))
+	logging.Debug(ctx, r.logger, fmt.Sprintf("found %d service ports that have to be updated", len(changes)))
+	logging.Debug(ctx, r.logger, fmt.Sprintf("computed update patch: %s", diff.Render(changes)))
 
 	return serviceToUpdate, nil
 }
+
+// isDisabledPort returns whether the given service port is owned by the guest
+// cluster of the given custom object and serves the LB port of a disabled
+// protocol.
+func isDisabledPort(customObject v1alpha1.IngressConfig, p apiv1.ServicePort) bool {
+	if !strings.HasSuffix(strings.ToLower(p.Name), "-"+strings.ToLower(key.ClusterID(customObject))) {
+		return false
+	}
+
+	for _, d := range key.DisabledProtocolPorts(customObject) {
+		if int(p.Port) == d.LBPort {
+			return true
+		}
+	}
+
+	return false
+}
@@ -8,8 +8,15 @@ import (
 	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/micrologger/microloggertest"
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/pin"
 )
 
 func Test_Service_newUpdateChange(t *testing.T) {
@@ -427,15 +434,416 @@ func Test_Service_newUpdateChange(t *testing.T) {
 			},
 			ErrorMatcher: nil,
 		},
+
+		// Test 5 ensures ports pinned to another guest cluster are never
+		// overwritten, even when they look orphaned.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+					},
+				},
+			},
+			CurrentState: &apiv1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						pin.Annotation: "31000=p1l6x",
+					},
+				},
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "http-30010-p1l6x",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31000),
+							TargetPort: intstr.FromInt(31000),
+							NodePort:   int32(31000),
+						},
+					},
+				},
+			},
+			DesiredState: []apiv1.ServicePort{
+				{
+					Name:       "http-30010-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31000),
+					TargetPort: intstr.FromInt(31000),
+					NodePort:   int32(31000),
+				},
+			},
+			Expected:     nil,
+			ErrorMatcher: IsPortPinned,
+		},
+
+		// Test 6 ensures ports pinned by the guest cluster are recorded on the
+		// service.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						key.PinnedPortsAnnotation: "31000",
+					},
+				},
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+					},
+				},
+			},
+			CurrentState: &apiv1.Service{
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "http-30010-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31000),
+							TargetPort: intstr.FromInt(31000),
+							NodePort:   int32(31000),
+						},
+					},
+				},
+			},
+			DesiredState: []apiv1.ServicePort{
+				{
+					Name:       "http-30010-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31000),
+					TargetPort: intstr.FromInt(31000),
+					NodePort:   int32(31000),
+				},
+			},
+			Expected: &apiv1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						pin.Annotation: "31000=al9qy",
+					},
+				},
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "http-30010-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31000),
+							TargetPort: intstr.FromInt(31000),
+							NodePort:   int32(31000),
+						},
+					},
+				},
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 7 ensures duplicated port names are rewritten to canonical names,
+		// while the ports of the guest cluster keep their desired names.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+					},
+				},
+			},
+			CurrentState: &apiv1.Service{
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "http-30010-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31005),
+							TargetPort: intstr.FromInt(31005),
+							NodePort:   int32(31005),
+						},
+						{
+							Name:       "http-30010-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31000),
+							TargetPort: intstr.FromInt(31000),
+							NodePort:   int32(31000),
+						},
+						{
+							Name:       "https-30011-foo",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31001),
+							TargetPort: intstr.FromInt(31001),
+							NodePort:   int32(31001),
+						},
+						{
+							Name:       "https-30011-foo",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31002),
+							TargetPort: intstr.FromInt(31002),
+							NodePort:   int32(31002),
+						},
+					},
+				},
+			},
+			DesiredState: []apiv1.ServicePort{
+				{
+					Name:       "http-30010-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31000),
+					TargetPort: intstr.FromInt(31000),
+					NodePort:   int32(31000),
+				},
+			},
+			Expected: &apiv1.Service{
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "http-31005-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31005),
+							TargetPort: intstr.FromInt(31005),
+							NodePort:   int32(31005),
+						},
+						{
+							Name:       "http-30010-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31000),
+							TargetPort: intstr.FromInt(31000),
+							NodePort:   int32(31000),
+						},
+						{
+							Name:       "https-30011-foo",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31001),
+							TargetPort: intstr.FromInt(31001),
+							NodePort:   int32(31001),
+						},
+						{
+							Name:       "https-31002-foo",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31002),
+							TargetPort: intstr.FromInt(31002),
+							NodePort:   int32(31002),
+						},
+					},
+				},
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 8 ensures node ports of services of type LoadBalancer are left to
+		// Kubernetes.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+						{
+							IngressPort: 30011,
+							Protocol:    "https",
+							LBPort:      31001,
+						},
+					},
+				},
+			},
+			CurrentState: &apiv1.Service{
+				Spec: apiv1.ServiceSpec{
+					Type: apiv1.ServiceTypeLoadBalancer,
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "http-30010-foo",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31000),
+							TargetPort: intstr.FromInt(31000),
+							NodePort:   int32(30518),
+						},
+					},
+				},
+			},
+			DesiredState: []apiv1.ServicePort{
+				{
+					Name:       "http-30010-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31000),
+					TargetPort: intstr.FromInt(31000),
+					NodePort:   int32(31000),
+				},
+				{
+					Name:       "https-30011-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31001),
+					TargetPort: intstr.FromInt(31001),
+					NodePort:   int32(31001),
+				},
+			},
+			Expected: &apiv1.Service{
+				Spec: apiv1.ServiceSpec{
+					Type: apiv1.ServiceTypeLoadBalancer,
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "http-30010-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31000),
+							TargetPort: intstr.FromInt(31000),
+							NodePort:   int32(30518),
+						},
+						{
+							Name:       "https-30011-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31001),
+							TargetPort: intstr.FromInt(31001),
+						},
+					},
+				},
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 9 ensures ports of disabled protocols are removed, unless they
+		// are owned by another guest cluster.
+		{
+			Obj: &v1alpha1.IngressConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						key.DisabledProtocolsAnnotation: "http",
+					},
+				},
+				Spec: v1alpha1.IngressConfigSpec{
+					GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+						ID:        "al9qy",
+						Namespace: "al9qy",
+						Service:   "worker",
+					},
+					ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+						{
+							IngressPort: 30010,
+							Protocol:    "http",
+							LBPort:      31000,
+						},
+						{
+							IngressPort: 30011,
+							Protocol:    "https",
+							LBPort:      31001,
+						},
+						{
+							IngressPort: 30012,
+							Protocol:    "http",
+							LBPort:      31002,
+						},
+					},
+				},
+			},
+			CurrentState: &apiv1.Service{
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "http-30010-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31000),
+							TargetPort: intstr.FromInt(31000),
+							NodePort:   int32(31000),
+						},
+						{
+							Name:       "https-30011-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31001),
+							TargetPort: intstr.FromInt(31001),
+							NodePort:   int32(31001),
+						},
+						{
+							Name:       "http-30012-p1l6x",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31002),
+							TargetPort: intstr.FromInt(31002),
+							NodePort:   int32(31002),
+						},
+					},
+				},
+			},
+			DesiredState: []apiv1.ServicePort{
+				{
+					Name:       "https-30011-al9qy",
+					Protocol:   apiv1.ProtocolTCP,
+					Port:       int32(31001),
+					TargetPort: intstr.FromInt(31001),
+					NodePort:   int32(31001),
+				},
+			},
+			Expected: &apiv1.Service{
+				Spec: apiv1.ServiceSpec{
+					Ports: []apiv1.ServicePort{
+						{
+							Name:       "https-30011-al9qy",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31001),
+							TargetPort: intstr.FromInt(31001),
+							NodePort:   int32(31001),
+						},
+						{
+							Name:       "http-30012-p1l6x",
+							Protocol:   apiv1.ProtocolTCP,
+							Port:       int32(31002),
+							TargetPort: intstr.FromInt(31002),
+							NodePort:   int32(31002),
+						},
+					},
+				},
+			},
+			ErrorMatcher: nil,
+		},
 	}
 
 	var err error
 	var newResource *Resource
 	{
+		var a *allocator.Allocator
+		a, err = allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
 		c := DefaultConfig()
 
-		c.K8sClient = fake.NewSimpleClientset()
+		c.Allocator = a
+		var k8sClient clients.Interface
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c.Coordinator = newTestCoordinator(t, k8sClient)
+		c.K8sClient = k8sClient
 		c.Logger = microloggertest.New()
+		c.ProjectName = "ingress-operator"
 
 		newResource, err = New(c)
 		if err != nil {
@@ -443,14 +851,21 @@ func Test_Service_newUpdateChange(t *testing.T) {
 		}
 	}
 
+	// The config map resource applied its changes already, so that ports of
+	// disabled protocols are removed, see Test_Ordering_disabledProtocols.
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{ConfigMapApplied: true})
+
 	for i, tc := range testCases {
-		result, err := newResource.newUpdateChange(context.TODO(), tc.Obj, tc.CurrentState, tc.DesiredState)
+		result, err := newResource.newUpdateChange(ctx, tc.Obj, tc.CurrentState, tc.DesiredState)
 		if err != nil && tc.ErrorMatcher == nil {
 			t.Fatal("test", i, "expected", nil, "got", err)
 		}
 		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
 			t.Fatal("test", i, "expected", true, "got", false)
 		}
+		if tc.ErrorMatcher != nil {
+			continue
+		}
 		e, ok := result.(*apiv1.Service)
 		if !ok {
 			t.Fatalf("test %d expected %#v got %#v", i, true, false)
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/pin"
+	"github.com/giantswarm/ingress-operator/service/servicepolicy"
+)
+
+// writeService writes the given service to the Kubernetes API. The fields
+// preserved by the service policy are reset to the ones currently stored
+// before, so that they are never changed by the operator. In case the policy
+// preserves fields unknown to the vendored API types, only the ports and the
+// pin annotation are written with a JSON merge patch, since an update of the
+// whole service would drop them.
+func (r *Resource) writeService(ctx context.Context, namespace string, service *apiv1.Service) (*apiv1.Service, error) {
+	updated, err := WriteService(ctx, r.k8sClient, r.logger, r.policy, namespace, service)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return updated, nil
+}
+
+// WriteService writes the given service to the Kubernetes API the same way
+// the service resource does, preserving the fields of the given policy. It is
+// used by other writers of the services of shared ingress controllers.
+func WriteService(ctx context.Context, k8sClient clients.Services, logger micrologger.Logger, policy servicepolicy.Policy, namespace string, service *apiv1.Service) (*apiv1.Service, error) {
+	if policy.IsEmpty() {
+		updated, err := k8sClient.UpdateService(ctx, namespace, service)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return updated, nil
+	}
+
+	current, err := k8sClient.GetService(ctx, namespace, service.Name)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	reset := policy.Enforce(current, service)
+	if len(reset) > 0 {
+		logging.Warning(ctx, logger, fmt.Sprintf("refusing to change preserved service fields %s, keeping their current values", strings.Join(reset, ", ")))
+		for _, f := range reset {
+			preservedFieldsCounter.WithLabelValues(namespace, service.Name, f).Inc()
+		}
+	}
+
+	if !policy.RequiresPatch() {
+		updated, err := k8sClient.UpdateService(ctx, namespace, service)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return updated, nil
+	}
+
+	patch, err := portsPatch(service)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	updated, err := k8sClient.PatchService(ctx, namespace, service.Name, types.MergePatchType, patch)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return updated, nil
+}
+
+// portsPatch returns the JSON merge patch writing the ports and the pin
+// annotation of the given service. The resource version makes the patch fail
+// with a conflict in case the service changed since it got read, the same as
+// an update.
+func portsPatch(service *apiv1.Service) ([]byte, error) {
+	// A null annotation removes the pin annotation in case the service does
+	// not pin any port anymore.
+	var pins interface{}
+	if v, ok := service.Annotations[pin.Annotation]; ok {
+		pins = v
+	}
+
+	ports := service.Spec.Ports
+	if ports == nil {
+		ports = []apiv1.ServicePort{}
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				pin.Annotation: pins,
+			},
+			"resourceVersion": service.ResourceVersion,
+		},
+		"spec": map[string]interface{}{
+			"ports": ports,
+		},
+	}
+
+	b, err := json.Marshal(patch)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return b, nil
+}
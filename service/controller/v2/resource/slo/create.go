@@ -0,0 +1,25 @@
+package slo
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureCreated records the successful reconciliation of the guest cluster of
+// the IngressConfig.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	clusters.reconcile(key.ClusterID(customObject))
+
+	logging.Debug(ctx, r.logger, "recorded successful reconciliation")
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package slo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+)
+
+func Test_SLO_EnsureCreated(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+
+	clusters = newTracker()
+	clusters.now = func() time.Time { return now }
+
+	newResource, err := New(Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	customObject := &v1alpha1.IngressConfig{
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID: "al9qy",
+			},
+		},
+	}
+
+	// Guest clusters are not tracked before being seen.
+	_, ok := clusters.secondsSince("al9qy")
+	if ok {
+		t.Fatal("expected", false, "got", true)
+	}
+
+	// Guest clusters never reconciled successfully count from the time they
+	// were first seen.
+	Track("al9qy")
+	now = now.Add(10 * time.Minute)
+	Track("al9qy")
+
+	s, ok := clusters.secondsSince("al9qy")
+	if !ok || s != 600 {
+		t.Fatal("expected", 600, "got", s)
+	}
+
+	err = newResource.EnsureCreated(context.TODO(), customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	now = now.Add(time.Minute)
+
+	s, ok = clusters.secondsSince("al9qy")
+	if !ok || s != 60 {
+		t.Fatal("expected", 60, "got", s)
+	}
+
+	// Reconciliations skipped by other resources count as successful as well.
+	now = now.Add(time.Minute)
+	Reconciled("al9qy")
+	s, ok = clusters.secondsSince("al9qy")
+	if !ok || s != 0 {
+		t.Fatal("expected", 0, "got", s)
+	}
+
+	err = newResource.EnsureDeleted(context.TODO(), customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	_, ok = clusters.secondsSince("al9qy")
+	if ok {
+		t.Fatal("expected", false, "got", true)
+	}
+}
@@ -0,0 +1,25 @@
+package slo
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// EnsureDeleted stops tracking the guest cluster of the IngressConfig, since
+// deleted guest clusters are not reconciled anymore.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	clusters.forget(key.ClusterID(customObject))
+
+	logging.Debug(ctx, r.logger, "stopped tracking reconciliations")
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package slo
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "slo"
+)
+
+var (
+	intervalHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "reconcile_interval_seconds",
+			Help:      "A histogram metric expressing the seconds between successive successful reconciliations of the same guest cluster across all guest clusters.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 13),
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(intervalHistogram)
+	prometheus.MustRegister(clusters)
+}
@@ -0,0 +1,58 @@
+// Package slo implements a resource recording when guest clusters got
+// reconciled successfully. It runs last, so it is only reached in case all
+// other resources succeeded. The time since the last successful reconciliation
+// of every guest cluster is exposed as gauge, which allows alerting on guest
+// clusters not being reconciled for too long. Guest clusters are tracked from
+// the time they are first seen by the controller, so guest clusters never
+// being reconciled successfully are covered as well.
+package slo
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "slov2"
+)
+
+// Config represents the configuration used to create a new slo resource.
+type Config struct {
+	// Dependencies.
+	Logger micrologger.Logger
+}
+
+// DefaultConfig provides a default configuration to create a new slo resource
+// by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		Logger: nil,
+	}
+}
+
+// Resource implements the slo resource.
+type Resource struct {
+	// Dependencies.
+	logger micrologger.Logger
+}
+
+// New creates a new configured slo resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		logger: config.Logger.With("resource", Name),
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
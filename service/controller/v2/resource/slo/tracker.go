@@ -0,0 +1,117 @@
+package slo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+// clusters tracks the guest clusters of all controllers, since the resource
+// sets of all workers share the registered metrics.
+var clusters = newTracker()
+
+// Track starts tracking the guest cluster with the given ID in case it is not
+// tracked yet. The guest cluster counts as reconciled at the time it is first
+// tracked. It is called as soon as the controller starts reconciling an
+// IngressConfig.
+func Track(clusterID string) {
+	clusters.track(clusterID)
+}
+
+// Reconciled records the successful reconciliation of the guest cluster with
+// the given ID. It is called by resources skipping the rest of a successful
+// reconciliation, e.g. result cache hits, since the slo resource is not
+// executed then.
+func Reconciled(clusterID string) {
+	clusters.reconcile(clusterID)
+}
+
+// tracker records the time of the last successful reconciliation per guest
+// cluster and exposes the time since then at collection time.
+type tracker struct {
+	desc  *prometheus.Desc
+	mutex sync.Mutex
+	now   func() time.Time
+	last  map[string]time.Time
+	// observed records which guest clusters got reconciled successfully at
+	// least once, so that the interval histogram is only fed with intervals
+	// between successful reconciliations.
+	observed map[string]bool
+}
+
+func newTracker() *tracker {
+	return &tracker{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(project.PrometheusNamespace, PrometheusSubsystem, "seconds_since_last_reconcile"),
+			"A gauge metric expressing the seconds since the last successful reconciliation of a guest cluster.",
+			[]string{"cluster_id"},
+			nil,
+		),
+		mutex:    sync.Mutex{},
+		now:      time.Now,
+		last:     map[string]time.Time{},
+		observed: map[string]bool{},
+	}
+}
+
+func (t *tracker) track(clusterID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_, ok := t.last[clusterID]
+	if !ok {
+		t.last[clusterID] = t.now()
+	}
+}
+
+func (t *tracker) reconcile(clusterID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	if t.observed[clusterID] {
+		intervalHistogram.Observe(now.Sub(t.last[clusterID]).Seconds())
+	}
+
+	t.last[clusterID] = now
+	t.observed[clusterID] = true
+}
+
+func (t *tracker) forget(clusterID string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.last, clusterID)
+	delete(t.observed, clusterID)
+}
+
+// secondsSince returns the seconds since the last successful reconciliation of
+// the guest cluster with the given ID and whether it is tracked at all.
+func (t *tracker) secondsSince(clusterID string) (float64, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	last, ok := t.last[clusterID]
+	if !ok {
+		return 0, false
+	}
+
+	return t.now().Sub(last).Seconds(), true
+}
+
+func (t *tracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.desc
+}
+
+func (t *tracker) Collect(ch chan<- prometheus.Metric) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	for id, last := range t.last {
+		ch <- prometheus.MustNewConstMetric(t.desc, prometheus.GaugeValue, now.Sub(last).Seconds(), id)
+	}
+}
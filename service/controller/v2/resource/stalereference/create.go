@@ -0,0 +1,173 @@
+package stalereference
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+const (
+	// EventReason is the reason of the events raised for stale IngressConfigs.
+	EventReason = "IngressControllerNotFound"
+	// EventReasonUnsupportedServiceType is the reason of the events raised for
+	// IngressConfigs referencing an ingress controller whose service does not
+	// expose node ports.
+	EventReasonUnsupportedServiceType = "UnsupportedServiceType"
+)
+
+// EnsureCreated cancels the reconciliation of IngressConfigs referencing an
+// ingress controller whose config map or service does not exist. After the
+// grace period the IngressConfig is flagged as stale.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "looking for the objects of the referenced ingress controller")
+
+	service, object, name, err := r.findMissing(ctx, cc.IngressController)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if object == "" {
+		logging.Debug(ctx, r.logger, "found the objects of the referenced ingress controller")
+
+		return r.ensureServiceType(ctx, customObject, cc, service)
+	}
+
+	message := fmt.Sprintf("%s %s/%s of the referenced ingress controller does not exist", object, cc.IngressController.Namespace, name)
+
+	if time.Since(customObject.CreationTimestamp.Time) < r.gracePeriod {
+		logging.Debug(ctx, r.logger, fmt.Sprintf("%s, waiting for it to show up", message))
+	} else {
+		logging.Warning(ctx, r.logger, fmt.Sprintf("%s, ingress config is stale", message))
+		errorsCounter.WithLabelValues(object).Inc()
+
+		// Failing to raise the event must not make the reconciliation proceed,
+		// so the error is only logged.
+		_, err = r.k8sClient.CreateEvent(ctx, customObject.Namespace, r.newEvent(customObject, EventReason, message))
+		if err != nil {
+			logging.Error(ctx, r.logger, "failed raising event for stale ingress config", err)
+		}
+	}
+
+	reconciliationcanceledcontext.SetCanceled(ctx)
+	logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+	return nil
+}
+
+// ensureServiceType cancels the reconciliation in case the given service of
+// the ingress controller is of a type node ports do not apply to, i.e. a
+// ClusterIP service, also a headless one, or an ExternalName service. The
+// service resource would otherwise write node ports the API server rejects
+// over and over again. The IngressConfig is flagged right away, since the type
+// of an existing service does not change on its own.
+func (r *Resource) ensureServiceType(ctx context.Context, customObject v1alpha1.IngressConfig, cc *controllercontext.Context, service *corev1.Service) error {
+	if isSupportedServiceType(service.Spec.Type) {
+		return nil
+	}
+
+	t := string(service.Spec.Type)
+	if service.Spec.Type == corev1.ServiceTypeClusterIP && service.Spec.ClusterIP == corev1.ClusterIPNone {
+		t = "headless " + t
+	}
+	message := fmt.Sprintf("service %s/%s of the referenced ingress controller is of type %s, which does not expose node ports, expected %s or %s", service.Namespace, service.Name, t, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer)
+
+	logging.Warning(ctx, r.logger, fmt.Sprintf("refusing the ingress config: %s", message))
+	unsupportedServiceTypeCounter.WithLabelValues(string(service.Spec.Type)).Inc()
+	cc.Conditions = conditions.Set(cc.Conditions, conditions.New(conditions.ServiceSynced, conditions.StatusFalse, EventReasonUnsupportedServiceType, message))
+
+	// Failing to raise the event must not make the reconciliation proceed, so
+	// the error is only logged.
+	_, err := r.k8sClient.CreateEvent(ctx, customObject.Namespace, r.newEvent(customObject, EventReasonUnsupportedServiceType, message))
+	if err != nil {
+		logging.Error(ctx, r.logger, "failed raising event for refused ingress config", err)
+	}
+
+	reconciliationcanceledcontext.SetCanceled(ctx)
+	logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+	return nil
+}
+
+// findMissing returns the service and the kind and name of the first missing
+// object of the given ingress controller. The kind is empty in case all
+// objects exist.
+func (r *Resource) findMissing(ctx context.Context, ingressController controllercontext.IngressController) (*corev1.Service, string, string, error) {
+	_, err := r.k8sClient.GetConfigMap(ctx, ingressController.Namespace, ingressController.ConfigMap)
+	if clients.IsNotFound(err) {
+		return nil, "configmap", ingressController.ConfigMap, nil
+	} else if err != nil {
+		return nil, "", "", microerror.Mask(err)
+	}
+
+	service, err := r.k8sClient.GetService(ctx, ingressController.Namespace, ingressController.Service)
+	if clients.IsNotFound(err) {
+		return nil, "service", ingressController.Service, nil
+	} else if err != nil {
+		return nil, "", "", microerror.Mask(err)
+	}
+
+	return service, "", "", nil
+}
+
+// isSupportedServiceType returns whether node ports apply to services of the
+// given type. Services without type are accepted, since the API server
+// defaults the type of all stored services.
+func isSupportedServiceType(t corev1.ServiceType) bool {
+	switch t {
+	case "", corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+		return true
+	}
+
+	return false
+}
+
+func (r *Resource) newEvent(customObject v1alpha1.IngressConfig, reason, message string) *corev1.Event {
+	now := metav1.NewTime(time.Now())
+
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", customObject.Name, now.UnixNano()),
+			Namespace: customObject.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      v1alpha1.SchemeGroupVersion.String(),
+			Kind:            "IngressConfig",
+			Name:            customObject.Name,
+			Namespace:       customObject.Namespace,
+			ResourceVersion: customObject.ResourceVersion,
+			UID:             customObject.UID,
+		},
+		Reason:  reason,
+		Message: message,
+		Source: corev1.EventSource{
+			Component: r.projectName,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           corev1.EventTypeWarning,
+	}
+
+	return event
+}
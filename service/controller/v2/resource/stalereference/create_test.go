@@ -0,0 +1,178 @@
+package stalereference
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+)
+
+func Test_StaleReference_EnsureCreated(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-controller",
+			Namespace: "kube-system",
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-controller",
+			Namespace: "kube-system",
+		},
+	}
+
+	newService := func(t corev1.ServiceType, clusterIP string) *corev1.Service {
+		s := service.DeepCopy()
+		s.Spec.Type = t
+		s.Spec.ClusterIP = clusterIP
+		return s
+	}
+
+	testCases := []struct {
+		Objects        []runtime.Object
+		Age            time.Duration
+		ExpectedCancel bool
+		ExpectedEvents int
+		ExpectedReason string
+	}{
+		// Test 0 ensures the reconciliation of an ingress config referencing an
+		// existing ingress controller proceeds.
+		{
+			Objects:        []runtime.Object{configMap, service},
+			Age:            time.Hour,
+			ExpectedCancel: false,
+			ExpectedEvents: 0,
+		},
+
+		// Test 1 ensures the reconciliation of an ingress config referencing a
+		// missing service is canceled without flagging it during the grace
+		// period.
+		{
+			Objects:        []runtime.Object{configMap},
+			Age:            time.Second,
+			ExpectedCancel: true,
+			ExpectedEvents: 0,
+		},
+
+		// Test 2 ensures an ingress config referencing a missing config map is
+		// flagged as stale after the grace period.
+		{
+			Objects:        []runtime.Object{service},
+			Age:            time.Hour,
+			ExpectedCancel: true,
+			ExpectedEvents: 1,
+			ExpectedReason: EventReason,
+		},
+
+		// Test 3 ensures an ingress config referencing a headless service is
+		// refused right away, also during the grace period.
+		{
+			Objects:        []runtime.Object{configMap, newService(corev1.ServiceTypeClusterIP, corev1.ClusterIPNone)},
+			Age:            time.Second,
+			ExpectedCancel: true,
+			ExpectedEvents: 1,
+			ExpectedReason: EventReasonUnsupportedServiceType,
+		},
+
+		// Test 4 ensures an ingress config referencing a ClusterIP service is
+		// refused.
+		{
+			Objects:        []runtime.Object{configMap, newService(corev1.ServiceTypeClusterIP, "10.0.0.10")},
+			Age:            time.Hour,
+			ExpectedCancel: true,
+			ExpectedEvents: 1,
+			ExpectedReason: EventReasonUnsupportedServiceType,
+		},
+
+		// Test 5 ensures an ingress config referencing an ExternalName service
+		// is refused.
+		{
+			Objects:        []runtime.Object{configMap, newService(corev1.ServiceTypeExternalName, "")},
+			Age:            time.Hour,
+			ExpectedCancel: true,
+			ExpectedEvents: 1,
+			ExpectedReason: EventReasonUnsupportedServiceType,
+		},
+
+		// Test 6 ensures the reconciliation of an ingress config referencing a
+		// LoadBalancer service proceeds.
+		{
+			Objects:        []runtime.Object{configMap, newService(corev1.ServiceTypeLoadBalancer, "10.0.0.10")},
+			Age:            time.Hour,
+			ExpectedCancel: false,
+			ExpectedEvents: 0,
+		},
+	}
+
+	for i, tc := range testCases {
+		k8sClient := fake.NewSimpleClientset(tc.Objects...)
+
+		var newResource *Resource
+		{
+			clientsInterface, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			c := DefaultConfig()
+
+			c.K8sClient = clientsInterface
+			c.Logger = microloggertest.New()
+
+			c.GracePeriod = 5 * time.Minute
+			c.ProjectName = "ingress-operator"
+
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		customObject := &v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-tc.Age)),
+				Name:              "al9qy",
+				Namespace:         "default",
+			},
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			IngressController: controllercontext.IngressController{
+				ConfigMap: "ingress-controller",
+				Namespace: "kube-system",
+				Service:   "ingress-controller",
+			},
+		})
+		ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+		err := newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		if reconciliationcanceledcontext.IsCanceled(ctx) != tc.ExpectedCancel {
+			t.Fatal("test", i, "expected", tc.ExpectedCancel, "got", reconciliationcanceledcontext.IsCanceled(ctx))
+		}
+
+		list, err := k8sClient.CoreV1().Events("default").List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if len(list.Items) != tc.ExpectedEvents {
+			t.Fatal("test", i, "expected", tc.ExpectedEvents, "got", len(list.Items))
+		}
+		if tc.ExpectedEvents != 0 && list.Items[0].Reason != tc.ExpectedReason {
+			t.Fatal("test", i, "expected", tc.ExpectedReason, "got", list.Items[0].Reason)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package stalereference
+
+import (
+	"context"
+)
+
+// EnsureDeleted does nothing. The config map and service resources already
+// tolerate missing objects of the ingress controller on deletion.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package stalereference
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "stale_reference"
+)
+
+var (
+	errorsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "errors_total",
+			Help:      "A counter metric expressing the number of reconciliations of IngressConfigs referencing a missing object of the host cluster ingress controller after the grace period.",
+		},
+		[]string{"object"},
+	)
+	unsupportedServiceTypeCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "unsupported_service_type_total",
+			Help:      "A counter metric expressing the number of reconciliations of IngressConfigs refused because the service of the host cluster ingress controller is of a type not exposing node ports.",
+		},
+		[]string{"type"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(errorsCounter)
+	prometheus.MustRegister(unsupportedServiceTypeCounter)
+}
@@ -0,0 +1,101 @@
+// Package stalereference implements a resource detecting IngressConfigs which
+// reference a host cluster ingress controller whose config map or service does
+// not exist. Reconciling such IngressConfigs would fail over and over again.
+// Their reconciliation is canceled instead. Once the grace period since the
+// creation of the IngressConfig passed, the IngressConfig is flagged as stale
+// by a warning event and the stale reference metric. IngressConfigs
+// referencing a service node ports do not apply to, e.g. a headless or
+// ClusterIP service, are refused right away the same way.
+package stalereference
+
+import (
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "stalereferencev2"
+)
+
+// Config represents the configuration used to create a new stale reference
+// resource.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// GracePeriod is the time after the creation of an IngressConfig during
+	// which missing objects of the referenced ingress controller are expected
+	// to show up, e.g. while the host cluster is being set up.
+	GracePeriod time.Duration
+	// ProjectName is the name of the operator reported as the source of the
+	// events raised for stale IngressConfigs.
+	ProjectName string
+}
+
+// DefaultConfig provides a default configuration to create a new stale
+// reference resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		GracePeriod: 0,
+		ProjectName: "",
+	}
+}
+
+// Resource implements the stale reference resource.
+type Resource struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	gracePeriod time.Duration
+	projectName string
+}
+
+// New creates a new configured stale reference resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.K8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	// Settings.
+	if config.GracePeriod < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "config.GracePeriod must not be negative")
+	}
+	if config.ProjectName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "config.ProjectName must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		gracePeriod: config.GracePeriod,
+		projectName: config.ProjectName,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
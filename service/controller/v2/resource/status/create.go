@@ -0,0 +1,137 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// requiredConditions are the conditions which have to be true for the
+// IngressConfig to be ready.
+var requiredConditions = []conditions.Type{
+	conditions.PortsAllocated,
+	conditions.ConfigMapSynced,
+	conditions.ServiceSynced,
+}
+
+// optionalConditions are the conditions which only keep the IngressConfig from
+// being ready in case they are false, since IngressConfigs reconciled before
+// they were introduced do not have them yet.
+var optionalConditions = []conditions.Type{
+	conditions.CertificatesIssued,
+	conditions.IngressReachable,
+}
+
+// EnsureCreated merges the conditions recorded during the reconciliation
+// into the conditions of the IngressConfig and records the node ports assigned
+// to its LB ports. The IngressConfig is only updated in case any of them
+// changed.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	current, err := conditions.FromCustomObject(customObject)
+	if conditions.IsInvalidAnnotation(err) {
+		logging.Warning(ctx, r.logger, fmt.Sprintf("replacing invalid conditions: %s", err.Error()))
+		current = nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	desired := r.withReady(conditions.Merge(current, cc.Conditions))
+	nodePorts := key.NodePortsValue(cc.NodePorts)
+	if conditions.Equal(current, desired) && customObject.GetAnnotations()[key.NodePortsAnnotation] == nodePorts {
+		logging.Debug(ctx, r.logger, "conditions are up to date")
+		return nil
+	}
+
+	logging.Debug(ctx, r.logger, "updating conditions")
+
+	v, err := conditions.ToAnnotation(desired)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// The latest version of the IngressConfig is fetched, so that only the
+	// status annotations are written and nothing else gets overwritten.
+	latest, err := r.g8sClient.GetIngressConfig(ctx, customObject.Namespace, customObject.Name)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if latest.Annotations == nil {
+		latest.Annotations = map[string]string{}
+	}
+	latest.Annotations[conditions.Annotation] = v
+	if nodePorts == "" {
+		delete(latest.Annotations, key.NodePortsAnnotation)
+	} else {
+		latest.Annotations[key.NodePortsAnnotation] = nodePorts
+	}
+
+	_, err = r.g8sClient.UpdateIngressConfig(ctx, latest.Namespace, latest)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "updated conditions")
+
+	return nil
+}
+
+// withReady returns the given conditions with the Ready and Degraded
+// conditions derived from the required and optional conditions. Unmanaged
+// conditions are ignored.
+func (r *Resource) withReady(current []conditions.Condition) []conditions.Condition {
+	var pending []string
+	for _, t := range requiredConditions {
+		if isType(r.unmanagedConditions, t) {
+			continue
+		}
+		if !conditions.IsTrue(current, t) {
+			pending = append(pending, string(t))
+		}
+	}
+	for _, t := range optionalConditions {
+		if isType(r.unmanagedConditions, t) {
+			continue
+		}
+		if conditions.Get(current, t).Status == conditions.StatusFalse {
+			pending = append(pending, string(t))
+		}
+	}
+
+	if len(pending) == 0 {
+		current = conditions.Set(current, conditions.New(conditions.Ready, conditions.StatusTrue, "", ""))
+		current = conditions.Set(current, conditions.New(conditions.Degraded, conditions.StatusFalse, "", ""))
+	} else {
+		message := fmt.Sprintf("conditions %s are not true", strings.Join(pending, ", "))
+		current = conditions.Set(current, conditions.New(conditions.Ready, conditions.StatusFalse, "NotSynced", message))
+		current = conditions.Set(current, conditions.New(conditions.Degraded, conditions.StatusTrue, "NotSynced", message))
+	}
+
+	return current
+}
+
+func isType(types []conditions.Type, t conditions.Type) bool {
+	for _, o := range types {
+		if o == t {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,243 @@
+package status
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+func Test_Status_EnsureCreated(t *testing.T) {
+	g8sClient := clientstest.NewIngressConfigs(v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy",
+			Namespace: "default",
+		},
+	})
+
+	var err error
+	var newResource *Resource
+	{
+		c := DefaultConfig()
+
+		c.G8sClient = g8sClient
+		c.Logger = microloggertest.New()
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	synced := []conditions.Condition{
+		conditions.New(conditions.PortsAllocated, conditions.StatusTrue, "", ""),
+		conditions.New(conditions.ConfigMapSynced, conditions.StatusTrue, "", ""),
+		conditions.New(conditions.ServiceSynced, conditions.StatusTrue, "", ""),
+	}
+
+	testCases := []struct {
+		Recorded          []conditions.Condition
+		NodePorts         map[int]int
+		ExpectedReady     conditions.Status
+		ExpectedNodePorts string
+		ExpectedUpdates   int
+	}{
+		// Test 0 ensures an ingress config having all required conditions
+		// recorded becomes ready.
+		{
+			Recorded:        synced,
+			ExpectedReady:   conditions.StatusTrue,
+			ExpectedUpdates: 1,
+		},
+
+		// Test 1 ensures the ingress config is not updated in case the conditions
+		// did not change.
+		{
+			Recorded:        synced,
+			ExpectedReady:   conditions.StatusTrue,
+			ExpectedUpdates: 1,
+		},
+
+		// Test 2 ensures the ingress config stops being ready in case a required
+		// condition turns false.
+		{
+			Recorded: []conditions.Condition{
+				conditions.New(conditions.ServiceSynced, conditions.StatusFalse, "", ""),
+			},
+			ExpectedReady:   conditions.StatusFalse,
+			ExpectedUpdates: 2,
+		},
+
+		// Test 3 ensures node ports assigned to the LB ports are recorded, even
+		// when the conditions did not change.
+		{
+			Recorded: []conditions.Condition{
+				conditions.New(conditions.ServiceSynced, conditions.StatusFalse, "", ""),
+			},
+			NodePorts: map[int]int{
+				31001: 32107,
+				31000: 30518,
+			},
+			ExpectedReady:     conditions.StatusFalse,
+			ExpectedNodePorts: "31000=30518,31001=32107",
+			ExpectedUpdates:   3,
+		},
+
+		// Test 4 ensures recorded node ports are removed once there are none
+		// anymore.
+		{
+			Recorded: []conditions.Condition{
+				conditions.New(conditions.ServiceSynced, conditions.StatusFalse, "", ""),
+			},
+			ExpectedReady:     conditions.StatusFalse,
+			ExpectedNodePorts: "",
+			ExpectedUpdates:   4,
+		},
+
+		// Test 5 ensures the ingress config does not become ready while its
+		// certificates are not issued, even though all required conditions are
+		// true.
+		{
+			Recorded: append([]conditions.Condition{
+				conditions.New(conditions.CertificatesIssued, conditions.StatusFalse, "CertificatesPending", ""),
+			}, synced...),
+			ExpectedReady:   conditions.StatusFalse,
+			ExpectedUpdates: 5,
+		},
+
+		// Test 6 ensures the ingress config becomes ready once its certificates
+		// got issued.
+		{
+			Recorded: []conditions.Condition{
+				conditions.New(conditions.CertificatesIssued, conditions.StatusTrue, "", ""),
+			},
+			ExpectedReady:   conditions.StatusTrue,
+			ExpectedUpdates: 6,
+		},
+
+		// Test 7 ensures the ingress config does not become ready while its LB
+		// ports fail the self-test.
+		{
+			Recorded: []conditions.Condition{
+				conditions.New(conditions.IngressReachable, conditions.StatusFalse, "IngressUnreachable", ""),
+			},
+			ExpectedReady:   conditions.StatusFalse,
+			ExpectedUpdates: 7,
+		},
+
+		// Test 8 ensures the ingress config becomes ready once its LB ports
+		// passed the self-test.
+		{
+			Recorded: []conditions.Condition{
+				conditions.New(conditions.IngressReachable, conditions.StatusTrue, "", ""),
+			},
+			ExpectedReady:   conditions.StatusTrue,
+			ExpectedUpdates: 8,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject, err := g8sClient.GetIngressConfig(context.Background(), "default", "al9qy")
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+			Conditions: tc.Recorded,
+			NodePorts:  tc.NodePorts,
+		})
+
+		err = newResource.EnsureCreated(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		if g8sClient.Updates() != tc.ExpectedUpdates {
+			t.Fatal("test", i, "expected", tc.ExpectedUpdates, "got", g8sClient.Updates())
+		}
+
+		current, err := g8sClient.GetIngressConfig(context.Background(), "default", "al9qy")
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		list, err := conditions.FromCustomObject(*current)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if conditions.Get(list, conditions.Ready).Status != tc.ExpectedReady {
+			t.Fatal("test", i, "expected", tc.ExpectedReady, "got", conditions.Get(list, conditions.Ready).Status)
+		}
+		nodePorts := current.Annotations[key.NodePortsAnnotation]
+		if nodePorts != tc.ExpectedNodePorts {
+			t.Fatal("test", i, "expected", tc.ExpectedNodePorts, "got", nodePorts)
+		}
+		if conditions.IsTrue(list, conditions.Degraded) == (tc.ExpectedReady == conditions.StatusTrue) {
+			t.Fatal("test", i, "expected", tc.ExpectedReady != conditions.StatusTrue, "got", conditions.IsTrue(list, conditions.Degraded))
+		}
+	}
+}
+
+// Test_Status_EnsureCreated_unmanaged ensures ingress configs become ready
+// without the conditions of disabled resources, even in case they were
+// recorded as false before.
+func Test_Status_EnsureCreated_unmanaged(t *testing.T) {
+	g8sClient := clientstest.NewIngressConfigs(v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "al9qy",
+			Namespace: "default",
+		},
+	})
+
+	var err error
+	var newResource *Resource
+	{
+		c := DefaultConfig()
+
+		c.G8sClient = g8sClient
+		c.Logger = microloggertest.New()
+
+		c.UnmanagedConditions = []conditions.Type{conditions.ConfigMapSynced, conditions.IngressReachable}
+
+		newResource, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{
+		Conditions: []conditions.Condition{
+			conditions.New(conditions.IngressReachable, conditions.StatusFalse, "IngressUnreachable", ""),
+			conditions.New(conditions.PortsAllocated, conditions.StatusTrue, "", ""),
+			conditions.New(conditions.ServiceSynced, conditions.StatusTrue, "", ""),
+		},
+	})
+
+	customObject, err := g8sClient.GetIngressConfig(context.Background(), "default", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = newResource.EnsureCreated(ctx, customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	customObject, err = g8sClient.GetIngressConfig(context.Background(), "default", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	list, err := conditions.FromCustomObject(*customObject)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !conditions.IsTrue(list, conditions.Ready) {
+		t.Fatal("expected", conditions.StatusTrue, "got", conditions.Get(list, conditions.Ready).Status)
+	}
+}
@@ -0,0 +1,10 @@
+package status
+
+import (
+	"context"
+)
+
+// EnsureDeleted does nothing. The conditions vanish with the IngressConfig.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
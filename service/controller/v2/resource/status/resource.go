@@ -0,0 +1,83 @@
+// Package status implements a resource persisting the conditions recorded by
+// the other resources during the reconciliation of an IngressConfig. It
+// derives the Ready and Degraded conditions from them. The resource runs
+// last, so conditions recorded by reconciliations which got canceled or
+// failed are not persisted.
+package status
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "statusv2"
+)
+
+// Config represents the configuration used to create a new status resource.
+type Config struct {
+	// Dependencies.
+	G8sClient clients.IngressConfigs
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// UnmanagedConditions are the conditions no resource records, since the
+	// resources recording them are disabled. They do not keep the
+	// IngressConfig from being ready, even in case an earlier reconciliation
+	// recorded them as false.
+	UnmanagedConditions []conditions.Type
+}
+
+// DefaultConfig provides a default configuration to create a new status
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		G8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		UnmanagedConditions: nil,
+	}
+}
+
+// Resource implements the status resource.
+type Resource struct {
+	// Dependencies.
+	g8sClient clients.IngressConfigs
+	logger    micrologger.Logger
+
+	// Settings.
+	unmanagedConditions []conditions.Type
+}
+
+// New creates a new configured status resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.G8sClient must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		g8sClient: config.G8sClient,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		unmanagedConditions: config.UnmanagedConditions,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
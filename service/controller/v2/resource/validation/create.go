@@ -0,0 +1,244 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/configmap"
+	"github.com/giantswarm/ingress-operator/service/feature"
+)
+
+// protocolExpression matches normalized protocols which can be part of a
+// service port name, which has to be a DNS label.
+var protocolExpression = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// EnsureCreated validates the IngressConfig and cancels the reconciliation in
+// case it is invalid, so that no other resource acts upon it.
+func (r *Resource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject, err := key.ToCustomObject(obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "validating the ingress config")
+
+	err = Validate(r.allocator, customObject)
+	if err == nil {
+		err = ValidateScope(r.namespaces, customObject)
+	}
+	if IsInvalidSpec(err) || key.IsInvalidSpec(err) || key.IsInvalidAnnotation(err) {
+		logging.Warning(ctx, r.logger, fmt.Sprintf("rejecting the ingress config: %s", err.Error()))
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		logging.Debug(ctx, r.logger, "canceling reconciliation for custom object")
+
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	logging.Debug(ctx, r.logger, "validated the ingress config")
+
+	return nil
+}
+
+// Validate returns an error in case the given custom object is invalid. The
+// error is either an invalid spec error of this or the key package or an
+// invalid annotation error of the key package.
+func Validate(a *allocator.Allocator, customObject v1alpha1.IngressConfig) error {
+	err := key.ValidateSpec(customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	unsupported, err := feature.Unsupported(customObject)
+	if feature.IsUnknownVersion(err) {
+		return microerror.Maskf(invalidSpecError, "%s", err.Error())
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	if len(unsupported) != 0 {
+		return microerror.Maskf(invalidSpecError, "version bundle version %q does not support %s", key.VersionBundleVersion(customObject), strings.Join(unsupported, ", "))
+	}
+
+	err = a.Validate(key.LBPorts(customObject))
+	if err != nil {
+		return microerror.Maskf(invalidSpecError, "%s", err.Error())
+	}
+
+	err = validateProtocols(key.ProtocolPorts(customObject))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	pinned, err := key.PinnedPorts(customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, p := range pinned {
+		if !inLBPorts(key.ProtocolPorts(customObject), p) {
+			return microerror.Maskf(invalidSpecError, "pinned port %d is not an LB port of the ingress config", p)
+		}
+	}
+
+	err = a.ValidatePinned(pinned)
+	if err != nil {
+		return microerror.Maskf(invalidSpecError, "%s", err.Error())
+	}
+
+	err = validateExternal(a, customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = validateTLSPassthrough(customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// The config map data is validated the same way the config map resource
+	// validates its desired state, so that IngressConfigs whose guest cluster
+	// namespace or service would inject configuration into the config map of
+	// a shared ingress controller are rejected early.
+	err = configmap.ValidateData(configmap.DesiredData(&controllercontext.Context{}, customObject))
+	if configmap.IsInvalidDataKey(err) || configmap.IsInvalidDataValue(err) {
+		return microerror.Maskf(invalidSpecError, "%s", err.Error())
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	named, err := key.NamedPorts(customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, p := range named {
+		if !inLBPorts(key.ProtocolPorts(customObject), p) {
+			return microerror.Maskf(invalidSpecError, "named port %d is not an LB port of the ingress config", p)
+		}
+	}
+
+	settings, err := key.PortSettingsByLBPort(customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for p := range settings {
+		if !inLBPorts(key.ProtocolPorts(customObject), p) {
+			return microerror.Maskf(invalidSpecError, "port settings of port %d which is not an LB port of the ingress config", p)
+		}
+	}
+
+	return nil
+}
+
+// ValidateScope returns an invalid spec error in case the operator runs
+// namespace-scoped and the given custom object references a guest cluster
+// service outside of its own namespace. Tenants of shared host clusters must
+// not route LB ports to the guest clusters of other tenants. Nothing is
+// rejected in case no namespaces are given.
+func ValidateScope(namespaces []string, customObject v1alpha1.IngressConfig) error {
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	if key.ClusterNamespace(customObject) != customObject.Namespace {
+		return microerror.Maskf(invalidSpecError, "guest cluster namespace %q must be the namespace %q of the ingress config in namespace-scoped mode", key.ClusterNamespace(customObject), customObject.Namespace)
+	}
+
+	return nil
+}
+
+// validateProtocols returns an error in case any protocol cannot be part of a
+// service port name or two protocol ports would result in the same service
+// port name. Protocols are compared in their normalized form, so that e.g.
+// HTTP and http of the same ingress port are detected as duplicates.
+func validateProtocols(protocolPorts []v1alpha1.IngressConfigSpecProtocolPort) error {
+	seen := map[string]int{}
+	for _, p := range protocolPorts {
+		protocol := key.Protocol(p)
+		if !protocolExpression.MatchString(protocol) {
+			return microerror.Maskf(invalidSpecError, "protocol %q of LB port %d must consist of alphanumeric characters or '-'", p.Protocol, p.LBPort)
+		}
+
+		k := fmt.Sprintf("%s/%d", protocol, p.IngressPort)
+		other, ok := seen[k]
+		if ok {
+			return microerror.Maskf(invalidSpecError, "protocol %q of LB port %d duplicates the one of LB port %d", p.Protocol, p.LBPort, other)
+		}
+		seen[k] = p.LBPort
+	}
+
+	return nil
+}
+
+// validateExternal returns an error in case an external port is an LB port of
+// the spec, which the operator would manage, or is reserved for system use.
+func validateExternal(a *allocator.Allocator, customObject v1alpha1.IngressConfig) error {
+	external, err := key.ExternalPorts(customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, p := range external {
+		if inLBPorts(key.ProtocolPorts(customObject), p) {
+			return microerror.Maskf(invalidSpecError, "external port %d must not be an LB port of the ingress config", p)
+		}
+	}
+
+	err = a.Validate(external)
+	if err != nil {
+		return microerror.Maskf(invalidSpecError, "%s", err.Error())
+	}
+
+	return nil
+}
+
+// validateTLSPassthrough returns an error in case TLS passthrough is enabled
+// for an LB port which is not the one of an https protocol port. Other
+// protocols do not carry TLS the guest cluster ingress controller could
+// terminate.
+func validateTLSPassthrough(customObject v1alpha1.IngressConfig) error {
+	passthrough, err := key.TLSPassthroughPorts(customObject)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, lbPort := range passthrough {
+		var protocol string
+		for _, p := range key.ProtocolPorts(customObject) {
+			if p.LBPort == lbPort {
+				protocol = key.Protocol(p)
+			}
+		}
+
+		if protocol == "" {
+			return microerror.Maskf(invalidSpecError, "TLS passthrough port %d is not an LB port of the ingress config", lbPort)
+		}
+		if protocol != key.ProtocolHTTPS {
+			return microerror.Maskf(invalidSpecError, "TLS passthrough port %d must be an https port, got protocol %q", lbPort, protocol)
+		}
+	}
+
+	return nil
+}
+
+func inLBPorts(protocolPorts []v1alpha1.IngressConfigSpecProtocolPort, port int) bool {
+	for _, p := range protocolPorts {
+		if p.LBPort == port {
+			return true
+		}
+	}
+
+	return false
+}
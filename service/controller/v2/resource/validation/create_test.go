@@ -0,0 +1,426 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+var (
+	guestCluster = v1alpha1.IngressConfigSpecGuestCluster{
+		ID: "al9qy",
+	}
+	hostCluster = v1alpha1.IngressConfigSpecHostCluster{
+		IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+			ConfigMap: "ingress-controller",
+			Namespace: "kube-system",
+			Service:   "ingress-controller",
+		},
+	}
+)
+
+func Test_Validation_Validate(t *testing.T) {
+	testCases := []struct {
+		PinnedPorts    string
+		ExternalPorts  string
+		IdleTimeout    string
+		MaxConnections string
+		TLSPassthrough string
+		Reserved       []int
+		ErrorMatcher   func(error) bool
+	}{
+		// Test 0 ensures an ingress config without pinned ports is valid.
+		{
+			PinnedPorts:  "",
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures pinned LB ports of the ingress config are valid.
+		{
+			PinnedPorts:  "31000, 31001",
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures pinned ports which are not LB ports of the ingress
+		// config are rejected.
+		{
+			PinnedPorts:  "31002",
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 3 ensures LB ports which are reserved for system use are
+		// rejected.
+		{
+			PinnedPorts:  "31001",
+			Reserved:     []int{31001},
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 4 ensures malformed pinned ports are rejected.
+		{
+			PinnedPorts:  "31000,http",
+			ErrorMatcher: key.IsInvalidAnnotation,
+		},
+
+		// Test 5 ensures settings of LB ports of the ingress config are valid.
+		{
+			IdleTimeout:    "31000=10m",
+			MaxConnections: "31000=1000,31001=50",
+			ErrorMatcher:   nil,
+		},
+
+		// Test 6 ensures settings of ports which are not LB ports of the ingress
+		// config are rejected.
+		{
+			MaxConnections: "31002=1000",
+			ErrorMatcher:   IsInvalidSpec,
+		},
+
+		// Test 7 ensures malformed settings are rejected.
+		{
+			IdleTimeout:  "31000=forever",
+			ErrorMatcher: key.IsInvalidAnnotation,
+		},
+
+		// Test 8 ensures TLS passthrough of https LB ports is valid.
+		{
+			TLSPassthrough: "31001",
+			ErrorMatcher:   nil,
+		},
+
+		// Test 9 ensures TLS passthrough of LB ports of other protocols is
+		// rejected.
+		{
+			TLSPassthrough: "31000",
+			ErrorMatcher:   IsInvalidSpec,
+		},
+
+		// Test 10 ensures TLS passthrough of ports which are not LB ports of the
+		// ingress config is rejected.
+		{
+			TLSPassthrough: "31002",
+			ErrorMatcher:   IsInvalidSpec,
+		},
+
+		// Test 11 ensures malformed TLS passthrough ports are rejected.
+		{
+			TLSPassthrough: "https",
+			ErrorMatcher:   key.IsInvalidAnnotation,
+		},
+
+		// Test 12 ensures external ports which are not LB ports of the ingress
+		// config are valid.
+		{
+			ExternalPorts: "31500,31501",
+			ErrorMatcher:  nil,
+		},
+
+		// Test 13 ensures external ports which are LB ports of the ingress config
+		// are rejected, since the operator manages them.
+		{
+			ExternalPorts: "31000",
+			ErrorMatcher:  IsInvalidSpec,
+		},
+
+		// Test 14 ensures external ports which are reserved for system use are
+		// rejected.
+		{
+			ExternalPorts: "31500",
+			Reserved:      []int{31500},
+			ErrorMatcher:  IsInvalidSpec,
+		},
+	}
+
+	for i, tc := range testCases {
+		var err error
+		var newResource *Resource
+		{
+			var a *allocator.Allocator
+			{
+				c := allocator.Config{
+					Reserved: tc.Reserved,
+				}
+
+				a, err = allocator.New(c)
+				if err != nil {
+					t.Fatal("test", i, "expected", nil, "got", err)
+				}
+			}
+
+			c := DefaultConfig()
+
+			c.Allocator = a
+			c.Logger = microloggertest.New()
+
+			newResource, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+		}
+
+		customObject := v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					key.ExternalPortsAnnotation:  tc.ExternalPorts,
+					key.IdleTimeoutAnnotation:    tc.IdleTimeout,
+					key.MaxConnectionsAnnotation: tc.MaxConnections,
+					key.PinnedPortsAnnotation:    tc.PinnedPorts,
+					key.TLSPassthroughAnnotation: tc.TLSPassthrough,
+				},
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: guestCluster,
+				HostCluster:  hostCluster,
+				ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+					{
+						IngressPort: 30010,
+						Protocol:    "http",
+						LBPort:      31000,
+					},
+					{
+						IngressPort: 30011,
+						Protocol:    "https",
+						LBPort:      31001,
+					},
+				},
+			},
+		}
+
+		err = Validate(newResource.allocator, customObject)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+	}
+}
+
+func Test_Validation_Validate_protocols(t *testing.T) {
+	testCases := []struct {
+		ProtocolPorts []v1alpha1.IngressConfigSpecProtocolPort
+		ErrorMatcher  func(error) bool
+	}{
+		// Test 0 ensures protocols in any case are valid.
+		{
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: "HTTP"},
+				{IngressPort: 30011, LBPort: 31001, Protocol: "Https"},
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures protocols differing only in case are rejected for the
+		// same ingress port, since they result in the same service port name.
+		{
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+				{IngressPort: 30010, LBPort: 31001, Protocol: "HTTP"},
+			},
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 2 ensures the same protocol is valid for different ingress ports.
+		{
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+				{IngressPort: 30011, LBPort: 31001, Protocol: "HTTP"},
+			},
+			ErrorMatcher: nil,
+		},
+
+		// Test 3 ensures empty protocols are rejected.
+		{
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: " "},
+			},
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 4 ensures protocols which cannot be part of a service port name
+		// are rejected.
+		{
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 31000, Protocol: "http/2"},
+			},
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 5 ensures LB ports out of range are rejected.
+		{
+			ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+				{IngressPort: 30010, LBPort: 70000, Protocol: "http"},
+			},
+			ErrorMatcher: key.IsInvalidSpec,
+		},
+	}
+
+	for i, tc := range testCases {
+		a, err := allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		customObject := v1alpha1.IngressConfig{
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster:  guestCluster,
+				HostCluster:   hostCluster,
+				ProtocolPorts: tc.ProtocolPorts,
+			},
+		}
+
+		err = Validate(a, customObject)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+	}
+}
+
+func Test_Validation_Validate_guestCluster(t *testing.T) {
+	testCases := []struct {
+		Namespace    string
+		Service      string
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures the defaults of the guest cluster are valid.
+		{
+			Namespace:    "",
+			Service:      "",
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures namespaces and services containing dots are valid.
+		{
+			Namespace:    "team.al9qy",
+			Service:      "worker.ingress",
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures namespaces injecting further config map entries are
+		// rejected.
+		{
+			Namespace:    "al9qy/worker:30010\n31000: other",
+			Service:      "",
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 3 ensures services containing separators are rejected.
+		{
+			Namespace:    "",
+			Service:      "worker:30010;",
+			ErrorMatcher: IsInvalidSpec,
+		},
+
+		// Test 4 ensures services containing whitespace are rejected.
+		{
+			Namespace:    "",
+			Service:      "worker ingress",
+			ErrorMatcher: IsInvalidSpec,
+		},
+	}
+
+	for i, tc := range testCases {
+		a, err := allocator.New(allocator.Config{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		g := guestCluster
+		g.Namespace = tc.Namespace
+		g.Service = tc.Service
+
+		customObject := v1alpha1.IngressConfig{
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: g,
+				HostCluster:  hostCluster,
+				ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+					{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+				},
+			},
+		}
+
+		err = Validate(a, customObject)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+	}
+}
+
+func Test_Validation_ValidateScope(t *testing.T) {
+	testCases := []struct {
+		Namespace      string
+		GuestNamespace string
+		Namespaces     []string
+		ErrorMatcher   func(error) bool
+	}{
+		// Test 0 ensures nothing is rejected in case the operator is not
+		// namespace-scoped.
+		{
+			Namespace:      "default",
+			GuestNamespace: "al9qy",
+			Namespaces:     nil,
+			ErrorMatcher:   nil,
+		},
+
+		// Test 1 ensures guest cluster services in the namespace of the ingress
+		// config are accepted.
+		{
+			Namespace:      "tenant-a",
+			GuestNamespace: "tenant-a",
+			Namespaces:     []string{"tenant-a", "tenant-b"},
+			ErrorMatcher:   nil,
+		},
+
+		// Test 2 ensures guest cluster services in the namespace of another
+		// tenant are rejected.
+		{
+			Namespace:      "tenant-a",
+			GuestNamespace: "tenant-b",
+			Namespaces:     []string{"tenant-a", "tenant-b"},
+			ErrorMatcher:   IsInvalidSpec,
+		},
+
+		// Test 3 ensures the guest cluster namespace defaulting to the cluster
+		// ID is rejected in case it is not the namespace of the ingress config.
+		{
+			Namespace:      "tenant-a",
+			GuestNamespace: "",
+			Namespaces:     []string{"tenant-a"},
+			ErrorMatcher:   IsInvalidSpec,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject := v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: tc.Namespace,
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+					ID:        "al9qy",
+					Namespace: tc.GuestNamespace,
+				},
+				HostCluster: hostCluster,
+			},
+		}
+
+		err := ValidateScope(tc.Namespaces, customObject)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package validation
+
+import (
+	"context"
+)
+
+// EnsureDeleted does nothing. Deletion of IngressConfigs must never be
+// blocked by validation, so that their data is always cleaned up.
+func (r *Resource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
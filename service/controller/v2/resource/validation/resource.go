@@ -0,0 +1,80 @@
+// Package validation implements a resource validating IngressConfigs before
+// any other resource acts upon them. Invalid IngressConfigs are not
+// reconciled at all and the reason of the rejection is logged.
+package validation
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+)
+
+const (
+	// Name is the identifier of the resource.
+	Name = "validationv2"
+)
+
+// Config represents the configuration used to create a new validation
+// resource.
+type Config struct {
+	// Dependencies.
+	Allocator *allocator.Allocator
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// Namespaces are the namespaces IngressConfigs are honored in. In case it
+	// is not empty IngressConfigs referencing guest cluster services in other
+	// namespaces than their own are rejected.
+	Namespaces []string
+}
+
+// DefaultConfig provides a default configuration to create a new validation
+// resource by best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		Allocator: nil,
+		Logger:    nil,
+
+		// Settings.
+		Namespaces: nil,
+	}
+}
+
+// Resource implements the validation resource.
+type Resource struct {
+	// Dependencies.
+	allocator *allocator.Allocator
+	logger    micrologger.Logger
+
+	// Settings.
+	namespaces []string
+}
+
+// New creates a new configured validation resource.
+func New(config Config) (*Resource, error) {
+	// Dependencies.
+	if config.Allocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Allocator must not be empty")
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "config.Logger must not be empty")
+	}
+
+	newResource := &Resource{
+		// Dependencies.
+		allocator: config.Allocator,
+		logger:    config.Logger.With("resource", Name),
+
+		// Settings.
+		namespaces: config.Namespaces,
+	}
+
+	return newResource, nil
+}
+
+func (r *Resource) Name() string {
+	return Name
+}
@@ -2,7 +2,12 @@ package v2
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/backoff"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/giantswarm/operatorkit/controller"
@@ -10,37 +15,401 @@ import (
 	"github.com/giantswarm/operatorkit/controller/resource/retryresource"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/conditions"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
 	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/lock"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/ordering"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/capacity"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/certificate"
 	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/configmap"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/deleteprotection"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/discovery"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/ingresscontroller"
+	ledgerresource "github.com/giantswarm/ingress-operator/service/controller/v2/resource/ledger"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/portsettings"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/quarantine"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/resultcache"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/selftest"
 	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/service"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/slo"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/stalereference"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/status"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/validation"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/timing"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/transaction"
+	"github.com/giantswarm/ingress-operator/service/crdschema"
+	"github.com/giantswarm/ingress-operator/service/history"
+	"github.com/giantswarm/ingress-operator/service/jitter"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+	"github.com/giantswarm/ingress-operator/service/lister"
+	"github.com/giantswarm/ingress-operator/service/locator"
+	"github.com/giantswarm/ingress-operator/service/pool"
+	"github.com/giantswarm/ingress-operator/service/servicepolicy"
+	"github.com/giantswarm/ingress-operator/service/startup"
 )
 
 type ResourceSetConfig struct {
-	K8sClient kubernetes.Interface
-	Logger    micrologger.Logger
+	Allocator *allocator.Allocator
+	G8sClient versioned.Interface
+	// HistoryRecorder records the latest reconciliation of all IngressConfigs.
+	// It is optional.
+	HistoryRecorder *history.Recorder
+	K8sClient       kubernetes.Interface
+	Ledger          *ledger.Ledger
+	// Locator discovers the config maps and services of shared ingress
+	// controllers by label selectors instead of using the names referenced by
+	// IngressConfigs. It is optional.
+	Locator *locator.Locator
+	// Locker serializes the writes to the config maps and services of shared
+	// ingress controllers across replicas. It is optional.
+	Locker lock.Locker
+	// Lister looks up IngressConfigs by the LB ports they request, so that
+	// allocation conflicts name the IngressConfigs involved. It is optional.
+	Lister *lister.Lister
+	Logger micrologger.Logger
+	Pool   *pool.Monitor
+	// SchemaGuard refuses updates of IngressConfigs setting fields unknown to
+	// this version of the operator. It is optional.
+	SchemaGuard *crdschema.Guard
+	// StartupReporter records the first reconciliation of all IngressConfigs.
+	// It is optional.
+	StartupReporter *startup.Reporter
 
+	// CertificateComponents are the cluster components whose certificates
+	// https LB ports wait for before being added. CertificateNamespace is the
+	// namespace cert-operator issues the certificate Secrets into. Nothing is
+	// waited for in case CertificateComponents is empty.
+	CertificateComponents []string
+	CertificateNamespace  string
+	// DedicatedIngressController enables the provisioning of a dedicated
+	// ingress controller per guest cluster instead of using the shared ingress
+	// controller referenced by the IngressConfig.
+	DedicatedIngressController               bool
+	DedicatedIngressControllerImage          string
+	DedicatedIngressControllerNamespace      string
+	DedicatedIngressControllerServiceAccount string
+	// DisabledResources are the names of the resources which are not executed,
+	// e.g. "configmap" in case the config map is managed by another system.
+	// Names are the ones of the resources without the version suffix.
+	DisabledResources []string
+	// IngressControllers are the identities of the host cluster ingress
+	// controllers this operator instance reconciles IngressConfigs of, e.g.
+	// "kube-system/ingress-controller". All IngressConfigs are reconciled in
+	// case it is empty.
+	IngressControllers []string
+	// MaxServicePorts is the maximum number of ports of the service of a
+	// shared ingress controller. Zero disables the limit.
+	MaxServicePorts int
+	// Namespaces are the namespaces IngressConfigs are honored in. In case it
+	// is not empty the operator runs namespace-scoped. IngressConfigs of other
+	// namespaces are ignored and IngressConfigs referencing guest cluster
+	// services in other namespaces than their own are rejected.
+	Namespaces  []string
 	ProjectName string
+	// PropagatedAnnotations and PropagatedLabels are the annotation and label
+	// keys propagated from IngressConfigs to the host cluster objects managed
+	// for their guest clusters.
+	PropagatedAnnotations []string
+	PropagatedLabels      []string
+	// ProxyProtocol is whether shared ingress controllers receive the PROXY
+	// protocol and nginx takes the client IP of connections to LB ports from
+	// it, which the source ranges of LB ports rely on, see the portsettings
+	// resource.
+	ProxyProtocol bool
+	// QuarantineRetryInterval is the interval in which quarantined
+	// IngressConfigs are reconciled. QuarantineThreshold is the number of
+	// consecutive failed reconciliations after which an IngressConfig is
+	// quarantined. Zero disables the quarantine.
+	QuarantineRetryInterval time.Duration
+	QuarantineThreshold     int
+	// ResourceOrder is the name of the order of the service and config map
+	// resources, see the ordering package.
+	ResourceOrder string
+	// ResultCacheTTL is the time successful reconciliations are cached, see
+	// the resultcache resource. Zero disables the cache.
+	ResultCacheTTL time.Duration
+	// Route selects the custom objects the resource set reconciles by the
+	// canary annotation. All custom objects are reconciled by default.
+	Route Route
+	// SelfTest enables connecting to the LB ports of IngressConfigs through
+	// the host cluster ingress controller once they got applied, see the
+	// selftest resource. SelfTestTimeout is the maximum time a connection
+	// takes.
+	SelfTest        bool
+	SelfTestTimeout time.Duration
+	// ServicePolicy is the policy of the service spec fields of shared ingress
+	// controllers the operator must preserve verbatim. It does not apply to
+	// dedicated ingress controllers, whose services the operator owns.
+	ServicePolicy servicepolicy.Policy
+	// StreamSnippets is the config map in the namespace of the shared ingress
+	// controller holding the nginx stream snippets tuning single LB ports.
+	StreamSnippets string
 }
 
+const (
+	// SharedWriteJitter is the maximum of the randomized delay before writes to
+	// the config map and service of the shared ingress controller, which are
+	// written by the reconciliations of all guest clusters.
+	SharedWriteJitter = 500 * time.Millisecond
+	// StaleReferenceGracePeriod is the time after the creation of an
+	// IngressConfig after which it is flagged as stale in case the shared
+	// ingress controller it references does not exist.
+	StaleReferenceGracePeriod = 10 * time.Minute
+)
+
 func NewResourceSet(config ResourceSetConfig) (*controller.ResourceSet, error) {
+	if config.Allocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Allocator must not be empty", config)
+	}
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
 	if config.K8sClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
 	}
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Ledger must not be empty", config)
+	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.Pool == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Pool must not be empty", config)
+	}
 
 	if config.ProjectName == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.ProjectName must not be empty", config)
 	}
+	if config.StreamSnippets == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.StreamSnippets must not be empty", config)
+	}
+
+	err := ValidateIngressControllers(config.IngressControllers)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	resourceOrder, err := ordering.Parse(config.ResourceOrder)
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ResourceOrder %s", config, err.Error())
+	}
+
+	var k8sClient clients.Interface
+	{
+		c := clients.Config{
+			K8sClient: config.K8sClient,
+		}
+
+		k8sClient, err = clients.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var g8sClient clients.IngressConfigs
+	{
+		c := clients.G8sConfig{
+			G8sClient: config.G8sClient,
+		}
+
+		g8sClient, err = clients.NewG8s(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		if config.SchemaGuard != nil {
+			g8sClient = config.SchemaGuard.Wrap(g8sClient)
+		}
+	}
+
+	// The quarantine resource is also an observer of the outcomes of the
+	// reconciliation loops, so its concrete type is kept.
+	var quarantineResource *quarantine.Resource
+	if config.QuarantineThreshold > 0 {
+		c := quarantine.Config{
+			G8sClient: g8sClient,
+			Logger:    config.Logger,
+
+			RetryInterval: config.QuarantineRetryInterval,
+			Threshold:     config.QuarantineThreshold,
+		}
+
+		quarantineResource, err = quarantine.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The result cache resource is also an observer of the outcomes of the
+	// reconciliation loops, so its concrete type is kept.
+	var resultCacheResource *resultcache.Resource
+	if config.ResultCacheTTL > 0 {
+		c := resultcache.DefaultConfig()
+
+		c.K8sClient = k8sClient
+		c.Logger = config.Logger
+
+		c.TTL = config.ResultCacheTTL
+
+		resultCacheResource, err = resultcache.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var deleteProtectionResource controller.Resource
+	{
+		c := deleteprotection.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+
+			ProjectName: config.ProjectName,
+		}
+
+		deleteProtectionResource, err = deleteprotection.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var validationResource controller.Resource
+	{
+		c := validation.Config{
+			Allocator: config.Allocator,
+			Logger:    config.Logger,
+
+			Namespaces: config.Namespaces,
+		}
+
+		validationResource, err = validation.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// Dedicated ingress controllers are provisioned by the operator itself, so
+	// only references to shared ingress controllers can become stale.
+	var staleReferenceResource controller.Resource
+	if !config.DedicatedIngressController {
+		c := stalereference.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+
+			GracePeriod: StaleReferenceGracePeriod,
+			ProjectName: config.ProjectName,
+		}
+
+		staleReferenceResource, err = stalereference.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// Services of dedicated ingress controllers only carry the ports of a
+	// single guest cluster, so only shared ingress controllers are limited.
+	var capacityResource controller.Resource
+	if !config.DedicatedIngressController && config.MaxServicePorts > 0 {
+		c := capacity.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+
+			MaxPorts:    config.MaxServicePorts,
+			ProjectName: config.ProjectName,
+		}
+
+		capacityResource, err = capacity.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var ingressControllerResource controller.Resource
+	if config.DedicatedIngressController {
+		c := ingresscontroller.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+
+			Image:                 config.DedicatedIngressControllerImage,
+			Namespace:             config.DedicatedIngressControllerNamespace,
+			PropagatedAnnotations: config.PropagatedAnnotations,
+			PropagatedLabels:      config.PropagatedLabels,
+			ServiceAccount:        config.DedicatedIngressControllerServiceAccount,
+		}
+
+		ingressControllerResource, err = ingresscontroller.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// Objects of dedicated ingress controllers are only written by the
+	// reconciliation of their own guest cluster, so there are no write storms to
+	// break.
+	writeJitter := SharedWriteJitter
+	if config.DedicatedIngressController {
+		writeJitter = 0
+	}
+
+	var discoveryResource controller.Resource
+	{
+		c := discovery.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+		}
+
+		discoveryResource, err = discovery.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var certificateResource controller.Resource
+	{
+		c := certificate.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+
+			Components: config.CertificateComponents,
+			Namespace:  config.CertificateNamespace,
+		}
+
+		certificateResource, err = certificate.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var ledgerResource controller.Resource
+	{
+		c := ledgerresource.Config{
+			K8sClient: k8sClient,
+			Ledger:    config.Ledger,
+			Lister:    config.Lister,
+			Logger:    config.Logger,
+			Pool:      config.Pool,
+
+			PropagatedLabels: config.PropagatedLabels,
+		}
 
-	var err error
+		ledgerResource, err = ledgerresource.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
 
 	var configMapResource controller.Resource
 	{
 		c := configmap.Config{
-			K8sClient: config.K8sClient,
+			Allocator: config.Allocator,
+			K8sClient: k8sClient,
 			Logger:    config.Logger,
+
+			WriteJitter: writeJitter,
 		}
 
 		ops, err := configmap.New(c)
@@ -54,11 +423,37 @@ func NewResourceSet(config ResourceSetConfig) (*controller.ResourceSet, error) {
 		}
 	}
 
+	var coordinator *transaction.Coordinator
+	{
+		c := transaction.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+
+			// Priority retries are short and skip the write jitter, since the
+			// traffic of the guest cluster is broken while they are pending.
+			BackOffFactory: func() backoff.Interface { return jitter.NewBackOff(3, 100*time.Millisecond, time.Second) },
+		}
+
+		coordinator, err = transaction.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var serviceResource controller.Resource
 	{
 		c := service.Config{
-			K8sClient: config.K8sClient,
-			Logger:    config.Logger,
+			Allocator:   config.Allocator,
+			Coordinator: coordinator,
+			K8sClient:   k8sClient,
+			Logger:      config.Logger,
+
+			ProjectName: config.ProjectName,
+			WriteJitter: writeJitter,
+		}
+
+		if !config.DedicatedIngressController {
+			c.Policy = config.ServicePolicy
 		}
 
 		ops, err := service.New(c)
@@ -72,14 +467,165 @@ func NewResourceSet(config ResourceSetConfig) (*controller.ResourceSet, error) {
 		}
 	}
 
-	resources := []controller.Resource{
-		configMapResource,
-		serviceResource,
+	var portSettingsResource controller.Resource
+	{
+		c := portsettings.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+
+			// Dedicated ingress controllers are provisioned by the operator
+			// without the PROXY protocol.
+			ProxyProtocol: config.ProxyProtocol && !config.DedicatedIngressController,
+		}
+
+		portSettingsResource, err = portsettings.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var selfTestResource controller.Resource
+	if config.SelfTest {
+		c := selftest.Config{
+			K8sClient: k8sClient,
+			Logger:    config.Logger,
+
+			ProjectName: config.ProjectName,
+			Timeout:     config.SelfTestTimeout,
+		}
+
+		selfTestResource, err = selftest.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var statusResource controller.Resource
+	{
+		unmanaged := unmanagedConditions(config.DisabledResources)
+		if selfTestResource == nil {
+			// Conditions recorded while the self-test was enabled must not keep
+			// IngressConfigs from becoming ready.
+			unmanaged = append(unmanaged, conditions.IngressReachable)
+		}
+
+		c := status.Config{
+			G8sClient: g8sClient,
+			Logger:    config.Logger,
+
+			UnmanagedConditions: unmanaged,
+		}
+
+		statusResource, err = status.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var sloResource controller.Resource
+	{
+		c := slo.Config{
+			Logger: config.Logger,
+		}
+
+		sloResource, err = slo.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var resources []controller.Resource
+	if quarantineResource != nil {
+		// Quarantined ingress configs must not be acted upon by any resource
+		// until their next attempt is due.
+		resources = append(resources, quarantineResource)
+	}
+	// Protected ingress configs must not be deleted and invalid ingress configs
+	// must be rejected before any other resource acts upon them.
+	resources = append(resources, deleteProtectionResource, validationResource)
+	if resultCacheResource != nil {
+		// Ingress configs which did not change since their last successful
+		// reconciliation must not be recomputed by the resources managing
+		// state.
+		resources = append(resources, resultCacheResource)
+	}
+	if staleReferenceResource != nil {
+		// Ingress configs referencing a missing ingress controller must not be
+		// acted upon by the resources managing its data.
+		resources = append(resources, staleReferenceResource)
+	}
+	if capacityResource != nil {
+		// Ingress configs exceeding the capacity of the service must be refused
+		// before their LB ports get allocated.
+		resources = append(resources, capacityResource)
+	}
+	if ingressControllerResource != nil {
+		// The dedicated ingress controller has to exist before the config map and
+		// service resources can manage its data.
+		resources = append(resources, ingressControllerResource)
+	}
+	// Ingress ports have to be discovered before they are recorded in the
+	// ledger. Allocations have to be recorded in the ledger before the config
+	// map and service resources apply them.
+	resources = append(resources, discoveryResource, ledgerResource)
+	// LB ports waiting for their certificates have to be known before the
+	// config map and service resources compute their desired state.
+	resources = append(resources, certificateResource)
+	{
+		ordered, err := ordering.Resources(resourceOrder, configMapResource, serviceResource)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		if config.Locker != nil && !config.DedicatedIngressController {
+			// The config map and service of shared ingress controllers are
+			// written by the reconciliations of all guest clusters, also by other
+			// replicas. Disabled resources are left out before, so that the last
+			// wrapped resource releasing the Lease is executed.
+			var enabled []controller.Resource
+			for _, r := range ordered {
+				if !isDisabled(config.DisabledResources, r) {
+					enabled = append(enabled, r)
+				}
+			}
+			ordered = lock.Wrap(enabled, config.Locker, config.ProjectName)
+		}
+
+		resources = append(resources, ordered...)
+	}
+	resources = append(resources, portSettingsResource)
+	if selfTestResource != nil {
+		// LB ports can only be self-tested once the config map, the service and
+		// the port settings got applied.
+		resources = append(resources, selfTestResource)
+	}
+	// The status resource persists the conditions recorded by all other
+	// resources. The slo resource records successful reconciliations and is
+	// therefore only reached after all other resources succeeded.
+	resources = append(resources, statusResource, sloResource)
+
+	resources, err = disableResources(resources, config.DisabledResources)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	{
+		var names []string
+		for _, r := range resources {
+			names = append(names, resourceName(r))
+		}
+		config.Logger.Log("level", "info", "message", fmt.Sprintf("executing resources %s", strings.Join(names, ", ")))
+		if len(config.DisabledResources) != 0 {
+			config.Logger.Log("level", "warning", "message", fmt.Sprintf("disabled resources %s", strings.Join(config.DisabledResources, ", ")))
+		}
 	}
 
 	{
 		c := retryresource.WrapConfig{
 			Logger: config.Logger,
+
+			// Retries are randomized so reconciliations failing on the same
+			// conflicting write do not retry in lockstep.
+			BackOffFactory: func() backoff.Interface { return jitter.NewBackOff(2, time.Second, 4*time.Second) },
 		}
 
 		resources, err = retryresource.Wrap(resources, c)
@@ -99,26 +645,93 @@ func NewResourceSet(config ResourceSetConfig) (*controller.ResourceSet, error) {
 		}
 	}
 
+	// Resources are timed including their retries. The timings are logged
+	// once the reconciliation ended.
+	resources = timing.Wrap(resources)
+
+	var timingReporter *timing.Reporter
+	{
+		c := timing.Config{
+			Logger: config.Logger,
+		}
+
+		timingReporter, err = timing.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// Outcomes are observed around the retried resources, so that only errors
+	// which stop the reconciliation are observed.
+	{
+		observers := []outcome.Observer{
+			timingReporter,
+		}
+		if quarantineResource != nil && !isDisabled(config.DisabledResources, quarantineResource) {
+			observers = append(observers, quarantineResource)
+		}
+		if resultCacheResource != nil && !isDisabled(config.DisabledResources, resultCacheResource) {
+			observers = append(observers, resultCacheResource)
+		}
+		if config.StartupReporter != nil {
+			observers = append(observers, config.StartupReporter)
+		}
+		if config.HistoryRecorder != nil {
+			observers = append(observers, config.HistoryRecorder)
+		}
+
+		resources = outcome.Wrap(resources, observers...)
+	}
+
 	handlesFunc := func(obj interface{}) bool {
 		customObject, err := key.ToCustomObject(obj)
 		if err != nil {
 			return false
 		}
 
-		if key.VersionBundleVersion(customObject) == VersionBundle().Version {
-			return true
+		return Handles(customObject, config.IngressControllers, config.Namespaces) && handlesRoute(customObject, config.Route)
+	}
+
+	initCtxFunc := func(ctx context.Context, obj interface{}) (context.Context, error) {
+		customObject, err := key.ToCustomObject(obj)
+		if err != nil {
+			return nil, microerror.Mask(err)
 		}
-		// TODO remove this hack with the next version bundle version or as soon as
-		// all ingressconfigs obtain a real version bundle version.
-		if key.VersionBundleVersion(customObject) == "" {
-			return true
+
+		slo.Track(key.ClusterID(customObject))
+
+		ctx = logging.NewContext(ctx, key.ClusterID(customObject))
+
+		var c controllercontext.Context
+		if config.DedicatedIngressController {
+			c.IngressController = controllercontext.IngressController{
+				ConfigMap:      key.DedicatedIngressControllerTCPServicesName(customObject),
+				Namespace:      config.DedicatedIngressControllerNamespace,
+				Service:        key.DedicatedIngressControllerName(customObject),
+				StreamSnippets: key.DedicatedIngressControllerStreamSnippetsName(customObject),
+			}
+		} else {
+			c.IngressController = controllercontext.IngressController{
+				ConfigMap:      key.IngressControllerConfigMap(customObject),
+				Namespace:      key.IngressControllerNamespace(customObject),
+				Service:        key.IngressControllerService(customObject),
+				StreamSnippets: config.StreamSnippets,
+			}
+
+			if config.Locator != nil {
+				located, err := config.Locator.Locate(ctx, c.IngressController.Namespace)
+				if err != nil {
+					return nil, microerror.Mask(err)
+				}
+
+				c.IngressController.ConfigMap = located.ConfigMap
+				c.IngressController.Service = located.Service
+			}
 		}
 
-		return false
-	}
+		ctx = timing.NewContext(ctx)
 
-	initCtxFunc := func(ctx context.Context, obj interface{}) (context.Context, error) {
-		return ctx, nil
+		return controllercontext.NewContext(ctx, c), nil
 	}
 
 	var resourceSet *controller.ResourceSet
@@ -142,7 +755,7 @@ func NewResourceSet(config ResourceSetConfig) (*controller.ResourceSet, error) {
 func toCRUDResource(logger micrologger.Logger, ops controller.CRUDResourceOps) (*controller.CRUDResource, error) {
 	c := controller.CRUDResourceConfig{
 		Logger: logger,
-		Ops:    ops,
+		Ops:    timing.WrapOps(ops),
 	}
 
 	r, err := controller.NewCRUDResource(c)
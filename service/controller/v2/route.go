@@ -0,0 +1,36 @@
+package v2
+
+import (
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// Route selects the custom objects a resource set reconciles by the canary
+// annotation, so that a canary resource set with changed behaviour can
+// reconcile a few guest clusters next to the stable resource set reconciling
+// all others.
+type Route string
+
+const (
+	// RouteAll reconciles all custom objects regardless of the canary
+	// annotation. It is used in case no canary resource set exists.
+	RouteAll Route = ""
+	// RouteCanary reconciles the custom objects annotated as canary.
+	RouteCanary Route = "canary"
+	// RouteStable reconciles the custom objects not annotated as canary.
+	RouteStable Route = "stable"
+)
+
+// handlesRoute returns whether the given custom object is reconciled on the
+// given route.
+func handlesRoute(customObject v1alpha1.IngressConfig, route Route) bool {
+	switch route {
+	case RouteCanary:
+		return key.IsCanary(customObject)
+	case RouteStable:
+		return !key.IsCanary(customObject)
+	default:
+		return true
+	}
+}
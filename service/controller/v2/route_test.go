@@ -0,0 +1,71 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+func Test_ResourceSet_handlesRoute(t *testing.T) {
+	testCases := []struct {
+		Annotations map[string]string
+		Route       Route
+		Expected    bool
+	}{
+		// Test 0 ensures canary custom objects are handled in case no canary
+		// resource set exists.
+		{
+			Annotations: map[string]string{key.CanaryAnnotation: "true"},
+			Route:       RouteAll,
+			Expected:    true,
+		},
+
+		// Test 1 ensures canary custom objects are handled by the canary resource
+		// set.
+		{
+			Annotations: map[string]string{key.CanaryAnnotation: "true"},
+			Route:       RouteCanary,
+			Expected:    true,
+		},
+
+		// Test 2 ensures canary custom objects are not handled by the stable
+		// resource set.
+		{
+			Annotations: map[string]string{key.CanaryAnnotation: "true"},
+			Route:       RouteStable,
+			Expected:    false,
+		},
+
+		// Test 3 ensures custom objects not opting into the canary are handled by
+		// the stable resource set.
+		{
+			Annotations: map[string]string{key.CanaryAnnotation: "false"},
+			Route:       RouteStable,
+			Expected:    true,
+		},
+
+		// Test 4 ensures custom objects without annotations are not handled by
+		// the canary resource set.
+		{
+			Annotations: nil,
+			Route:       RouteCanary,
+			Expected:    false,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject := v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: tc.Annotations,
+			},
+		}
+
+		result := handlesRoute(customObject, tc.Route)
+		if result != tc.Expected {
+			t.Fatalf("test %d expected %t got %t", i, tc.Expected, result)
+		}
+	}
+}
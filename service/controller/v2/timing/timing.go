@@ -0,0 +1,276 @@
+// Package timing measures the time the resources of a reconciliation loop take
+// and logs a single summary line per loop. CRUD resources are broken down into
+// fetching the current state, computing the desired state, computing the patch
+// and applying it, which shows whether latency of the Kubernetes API or diff
+// computation dominates a slow reconciliation.
+package timing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/controller"
+
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+// Phase is a part of the execution of a CRUD resource.
+type Phase string
+
+const (
+	// Current is the phase of fetching the current state.
+	Current Phase = "current"
+	// Desired is the phase of computing the desired state.
+	Desired Phase = "desired"
+	// Patch is the phase of computing the patch from current and desired
+	// state.
+	Patch Phase = "patch"
+	// Apply is the phase of applying the create, delete and update changes of
+	// the patch.
+	Apply Phase = "apply"
+)
+
+var phases = []Phase{Current, Desired, Patch, Apply}
+
+type contextKey string
+
+const timingsKey contextKey = "timings"
+
+// Timings are the durations measured during a single reconciliation loop.
+type Timings struct {
+	mutex     sync.Mutex
+	order     []string
+	resources map[string]*resourceTimings
+}
+
+type resourceTimings struct {
+	phases map[Phase]time.Duration
+	total  time.Duration
+}
+
+// NewContext returns a context carrying empty timings. It is called once per
+// reconciliation loop.
+func NewContext(ctx context.Context) context.Context {
+	t := &Timings{
+		resources: map[string]*resourceTimings{},
+	}
+
+	return context.WithValue(ctx, timingsKey, t)
+}
+
+// FromContext returns the timings of the given context, if any.
+func FromContext(ctx context.Context) (*Timings, bool) {
+	t, ok := ctx.Value(timingsKey).(*Timings)
+	return t, ok
+}
+
+// Add adds the given duration to the given phase of the given resource.
+// Durations of the same phase add up, e.g. when a resource is retried.
+func (t *Timings) Add(resource string, phase Phase, d time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.resource(resource).phases[phase] += d
+}
+
+func (t *Timings) addTotal(resource string, d time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.resource(resource).total += d
+}
+
+// keyVals returns the summary of the timings as logger key values. Resources
+// are in the order they got executed. The total is the sum of all resources.
+func (t *Timings) keyVals() []interface{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var total time.Duration
+	var keyVals []interface{}
+	for _, name := range t.order {
+		r := t.resources[name]
+		total += r.total
+
+		var parts []string
+		for _, p := range phases {
+			d, ok := r.phases[p]
+			if ok {
+				parts = append(parts, fmt.Sprintf("%s=%s", p, d))
+			}
+		}
+		parts = append(parts, fmt.Sprintf("total=%s", r.total))
+
+		keyVals = append(keyVals, name, strings.Join(parts, " "))
+	}
+
+	return append([]interface{}{"total", total.String()}, keyVals...)
+}
+
+func (t *Timings) resource(name string) *resourceTimings {
+	r, ok := t.resources[name]
+	if !ok {
+		r = &resourceTimings{
+			phases: map[Phase]time.Duration{},
+		}
+		t.order = append(t.order, name)
+		t.resources[name] = r
+	}
+
+	return r
+}
+
+// record adds the time passed since start to the given phase of the given
+// resource in case the context carries timings.
+func record(ctx context.Context, resource string, phase Phase, start time.Time) {
+	t, ok := FromContext(ctx)
+	if ok {
+		t.Add(resource, phase, time.Since(start))
+	}
+}
+
+// Config represents the configuration used to create a new reporter.
+type Config struct {
+	Logger micrologger.Logger
+}
+
+// Reporter logs the timings of every reconciliation loop once it ended. It
+// implements outcome.Observer.
+type Reporter struct {
+	logger micrologger.Logger
+}
+
+// New creates a new configured reporter.
+func New(config Config) (*Reporter, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Reporter{
+		logger: config.Logger,
+	}
+
+	return r, nil
+}
+
+// Observe logs the summary of the timings of the ended reconciliation loop.
+func (r *Reporter) Observe(ctx context.Context, obj interface{}, o outcome.Outcome, err error) {
+	t, ok := FromContext(ctx)
+	if !ok {
+		return
+	}
+
+	keyVals := []interface{}{"outcome", string(o)}
+	keyVals = append(keyVals, t.keyVals()...)
+
+	logging.Debug(ctx, r.logger, "measured reconciliation timings", keyVals...)
+}
+
+// Wrap wraps the given resources, so that the total time each of them takes
+// is measured.
+func Wrap(resources []controller.Resource) []controller.Resource {
+	var wrapped []controller.Resource
+	for _, r := range resources {
+		wrapped = append(wrapped, &resourceWrapper{resource: r})
+	}
+
+	return wrapped
+}
+
+type resourceWrapper struct {
+	resource controller.Resource
+}
+
+func (w *resourceWrapper) EnsureCreated(ctx context.Context, obj interface{}) error {
+	defer w.record(ctx, time.Now())
+
+	err := w.resource.EnsureCreated(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (w *resourceWrapper) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	defer w.record(ctx, time.Now())
+
+	err := w.resource.EnsureDeleted(ctx, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (w *resourceWrapper) Name() string {
+	return w.resource.Name()
+}
+
+// Wrapped implements the wrapper interface of operatorkit, so that wrapping
+// resources use the name of the wrapped resource.
+func (w *resourceWrapper) Wrapped() controller.Resource {
+	return w.resource
+}
+
+func (w *resourceWrapper) record(ctx context.Context, start time.Time) {
+	t, ok := FromContext(ctx)
+	if ok {
+		t.addTotal(w.resource.Name(), time.Since(start))
+	}
+}
+
+// WrapOps wraps the given CRUD resource ops, so that the time each phase takes
+// is measured.
+func WrapOps(ops controller.CRUDResourceOps) controller.CRUDResourceOps {
+	return &opsWrapper{ops: ops}
+}
+
+type opsWrapper struct {
+	ops controller.CRUDResourceOps
+}
+
+func (w *opsWrapper) Name() string {
+	return w.ops.Name()
+}
+
+func (w *opsWrapper) GetCurrentState(ctx context.Context, obj interface{}) (interface{}, error) {
+	defer record(ctx, w.ops.Name(), Current, time.Now())
+	return w.ops.GetCurrentState(ctx, obj)
+}
+
+func (w *opsWrapper) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
+	defer record(ctx, w.ops.Name(), Desired, time.Now())
+	return w.ops.GetDesiredState(ctx, obj)
+}
+
+func (w *opsWrapper) NewUpdatePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*controller.Patch, error) {
+	defer record(ctx, w.ops.Name(), Patch, time.Now())
+	return w.ops.NewUpdatePatch(ctx, obj, currentState, desiredState)
+}
+
+func (w *opsWrapper) NewDeletePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*controller.Patch, error) {
+	defer record(ctx, w.ops.Name(), Patch, time.Now())
+	return w.ops.NewDeletePatch(ctx, obj, currentState, desiredState)
+}
+
+func (w *opsWrapper) ApplyCreateChange(ctx context.Context, obj, createChange interface{}) error {
+	defer record(ctx, w.ops.Name(), Apply, time.Now())
+	return w.ops.ApplyCreateChange(ctx, obj, createChange)
+}
+
+func (w *opsWrapper) ApplyDeleteChange(ctx context.Context, obj, deleteChange interface{}) error {
+	defer record(ctx, w.ops.Name(), Apply, time.Now())
+	return w.ops.ApplyDeleteChange(ctx, obj, deleteChange)
+}
+
+func (w *opsWrapper) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
+	defer record(ctx, w.ops.Name(), Apply, time.Now())
+	return w.ops.ApplyUpdateChange(ctx, obj, updateChange)
+}
@@ -0,0 +1,173 @@
+package timing
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+// testOps is a CRUD resource updating on every reconciliation.
+type testOps struct{}
+
+func (o *testOps) Name() string {
+	return "test"
+}
+
+func (o *testOps) GetCurrentState(ctx context.Context, obj interface{}) (interface{}, error) {
+	return "current", nil
+}
+
+func (o *testOps) GetDesiredState(ctx context.Context, obj interface{}) (interface{}, error) {
+	return "desired", nil
+}
+
+func (o *testOps) NewUpdatePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*controller.Patch, error) {
+	p := controller.NewPatch()
+	p.SetUpdateChange(desiredState)
+	return p, nil
+}
+
+func (o *testOps) NewDeletePatch(ctx context.Context, obj, currentState, desiredState interface{}) (*controller.Patch, error) {
+	p := controller.NewPatch()
+	p.SetDeleteChange(currentState)
+	return p, nil
+}
+
+func (o *testOps) ApplyCreateChange(ctx context.Context, obj, createChange interface{}) error {
+	return nil
+}
+
+func (o *testOps) ApplyDeleteChange(ctx context.Context, obj, deleteChange interface{}) error {
+	return nil
+}
+
+func (o *testOps) ApplyUpdateChange(ctx context.Context, obj, updateChange interface{}) error {
+	return nil
+}
+
+// testResource is a resource without phases.
+type testResource struct{}
+
+func (r *testResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	return nil
+}
+
+func (r *testResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
+
+func (r *testResource) Name() string {
+	return "other"
+}
+
+func Test_Timing_Timings(t *testing.T) {
+	testCases := []struct {
+		Add             func(t *Timings)
+		ExpectedKeyVals []interface{}
+	}{
+		// Test 0 ensures empty timings have a zero total.
+		{
+			Add:             func(t *Timings) {},
+			ExpectedKeyVals: []interface{}{"total", "0s"},
+		},
+
+		// Test 1 ensures phases are summarized in their order and durations of
+		// the same phase add up.
+		{
+			Add: func(t *Timings) {
+				t.Add("configmapv2", Apply, 10*time.Millisecond)
+				t.Add("configmapv2", Current, 5*time.Millisecond)
+				t.Add("configmapv2", Apply, 20*time.Millisecond)
+				t.addTotal("configmapv2", 40*time.Millisecond)
+			},
+			ExpectedKeyVals: []interface{}{
+				"total", "40ms",
+				"configmapv2", "current=5ms apply=30ms total=40ms",
+			},
+		},
+
+		// Test 2 ensures resources are summarized in the order they got executed
+		// and the total is the sum of all resources.
+		{
+			Add: func(t *Timings) {
+				t.addTotal("validationv2", time.Millisecond)
+				t.Add("servicev2", Desired, time.Millisecond)
+				t.addTotal("servicev2", 2*time.Millisecond)
+			},
+			ExpectedKeyVals: []interface{}{
+				"total", "3ms",
+				"validationv2", "total=1ms",
+				"servicev2", "desired=1ms total=2ms",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		timings, _ := FromContext(NewContext(context.Background()))
+		tc.Add(timings)
+
+		keyVals := timings.keyVals()
+		if !reflect.DeepEqual(keyVals, tc.ExpectedKeyVals) {
+			t.Fatal("test", i, "expected", tc.ExpectedKeyVals, "got", keyVals)
+		}
+	}
+}
+
+func Test_Timing_Wrap(t *testing.T) {
+	crudResource, err := controller.NewCRUDResource(controller.CRUDResourceConfig{
+		Logger: microloggertest.New(),
+		Ops:    WrapOps(&testOps{}),
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	resources := Wrap([]controller.Resource{&testResource{}, crudResource})
+
+	ctx := NewContext(context.Background())
+	for _, r := range resources {
+		err := r.EnsureCreated(ctx, nil)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	timings, ok := FromContext(ctx)
+	if !ok {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	if !reflect.DeepEqual(timings.order, []string{"other", "test"}) {
+		t.Fatal("expected", []string{"other", "test"}, "got", timings.order)
+	}
+	if len(timings.resources["other"].phases) != 0 {
+		t.Fatal("expected", 0, "got", len(timings.resources["other"].phases))
+	}
+	for _, p := range phases {
+		_, ok := timings.resources["test"].phases[p]
+		if !ok {
+			t.Fatal("expected", p, "got", nil)
+		}
+	}
+
+	// Resources executed without timings in the context are not measured.
+	for _, r := range resources {
+		err := r.EnsureDeleted(context.Background(), nil)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	reporter, err := New(Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	reporter.Observe(ctx, nil, outcome.Succeeded, nil)
+	reporter.Observe(context.Background(), nil, outcome.Succeeded, nil)
+}
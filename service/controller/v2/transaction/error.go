@@ -0,0 +1,21 @@
+package transaction
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var rolledBackError = &microerror.Error{
+	Kind: "rolledBackError",
+}
+
+// IsRolledBack asserts rolledBackError.
+func IsRolledBack(err error) bool {
+	return microerror.Cause(err) == rolledBackError
+}
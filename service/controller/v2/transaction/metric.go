@@ -0,0 +1,40 @@
+package transaction
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "transaction"
+)
+
+const (
+	// OutcomeCompleted is the outcome of partial applies completed by retrying
+	// the service update.
+	OutcomeCompleted = "completed"
+	// OutcomeFailed is the outcome of partial applies neither completed nor
+	// rolled back. The config map update stays applied on its own until the
+	// next retry.
+	OutcomeFailed = "failed"
+	// OutcomeRolledBack is the outcome of partial applies whose config map
+	// update got rolled back.
+	OutcomeRolledBack = "rolled_back"
+)
+
+var (
+	partialAppliesCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "partial_applies_total",
+			Help:      "A counter metric expressing the number of config map updates whose service update failed, by how they were resolved.",
+		},
+		[]string{"namespace", "config_map", "outcome"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(partialAppliesCounter)
+}
@@ -0,0 +1,204 @@
+// Package transaction coordinates the updates of the config map and the
+// service of an ingress controller. Together they route the traffic of the LB
+// ports of a guest cluster, so an updated config map without an updated
+// service breaks that traffic until the next successful reconciliation. In
+// case the service update fails after the config map got updated, the
+// coordinator retries the service update with priority and rolls back the
+// config map update in case the service still cannot be updated.
+package transaction
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/backoff"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/logging"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/diff"
+)
+
+// Transaction records the config map update of a single reconciliation loop
+// until the service got updated as well. The zero value is ready to use.
+type Transaction struct {
+	configMapChanges []diff.Change
+	configMapData    map[string]string
+}
+
+// Begin records the given data of the config map before any update, so that
+// an update applied afterwards can be rolled back.
+func (t *Transaction) Begin(data map[string]string) {
+	t.configMapChanges = nil
+	t.configMapData = map[string]string{}
+	for k, v := range data {
+		t.configMapData[k] = v
+	}
+}
+
+// ConfigMapUpdated records the given data the config map got updated with.
+func (t *Transaction) ConfigMapUpdated(data map[string]string) {
+	var changes []diff.Change
+	for k, v := range data {
+		old, ok := t.configMapData[k]
+		if !ok {
+			changes = append(changes, diff.Added(k, v))
+		} else if old != v {
+			changes = append(changes, diff.Updated(k, old, v))
+		}
+	}
+	for k, old := range t.configMapData {
+		_, ok := data[k]
+		if !ok {
+			changes = append(changes, diff.Removed(k, old))
+		}
+	}
+
+	t.configMapChanges = changes
+}
+
+// Commit completes the transaction once the service got updated.
+func (t *Transaction) Commit() {
+	t.configMapChanges = nil
+}
+
+// IsPartial returns whether the config map got updated without the service
+// being updated yet.
+func (t *Transaction) IsPartial() bool {
+	return len(t.configMapChanges) > 0
+}
+
+// Config represents the configuration used to create a new coordinator.
+type Config struct {
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// BackOffFactory creates the backoff of the priority retries of failed
+	// service updates and of the rollbacks of config map updates.
+	BackOffFactory func() backoff.Interface
+}
+
+// Coordinator resolves partial applies of config map and service updates.
+type Coordinator struct {
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	backOffFactory func() backoff.Interface
+}
+
+// New creates a new configured coordinator.
+func New(config Config) (*Coordinator, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.BackOffFactory == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.BackOffFactory must not be empty", config)
+	}
+
+	c := &Coordinator{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		backOffFactory: config.BackOffFactory,
+	}
+
+	return c, nil
+}
+
+// Resolve resolves the given partial transaction after the service update
+// failed. The given retry function re-applies the service update from fresh
+// state. In case it keeps failing, the config map changes of the transaction
+// are reverted in the given config map and a rolled back error is returned.
+// Entries changed by other writers in the meantime are left untouched. Any
+// other error means the transaction could neither be completed nor rolled
+// back.
+func (c *Coordinator) Resolve(ctx context.Context, t *Transaction, namespace, configMap string, retry func() error) error {
+	logging.Warning(ctx, c.logger, fmt.Sprintf("found config map updated without its service: %s", diff.Render(t.configMapChanges)))
+	logging.Debug(ctx, c.logger, "retrying the service update with priority")
+
+	err := backoff.Retry(retry, c.backOffFactory())
+	if err == nil {
+		t.Commit()
+		partialAppliesCounter.WithLabelValues(namespace, configMap, OutcomeCompleted).Inc()
+		logging.Debug(ctx, c.logger, "retried the service update with priority")
+
+		return nil
+	}
+
+	logging.Warning(ctx, c.logger, fmt.Sprintf("failed to retry the service update: %s", err.Error()))
+	logging.Debug(ctx, c.logger, "rolling back the config map update")
+
+	o := func() error {
+		return c.rollback(ctx, t, namespace, configMap)
+	}
+
+	err = backoff.Retry(o, c.backOffFactory())
+	if err != nil {
+		partialAppliesCounter.WithLabelValues(namespace, configMap, OutcomeFailed).Inc()
+		return microerror.Mask(err)
+	}
+
+	rolledBack := t.configMapChanges
+	t.Commit()
+	partialAppliesCounter.WithLabelValues(namespace, configMap, OutcomeRolledBack).Inc()
+	logging.Debug(ctx, c.logger, "rolled back the config map update")
+
+	return microerror.Maskf(rolledBackError, "rolled back config map changes %s", diff.Render(rolledBack))
+}
+
+func (c *Coordinator) rollback(ctx context.Context, t *Transaction, namespace, configMap string) error {
+	current, err := c.k8sClient.GetConfigMap(ctx, namespace, configMap)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	data, changed := Revert(current.Data, t.configMapChanges)
+	if !changed {
+		return nil
+	}
+
+	current.Data = data
+	_, err = c.k8sClient.UpdateConfigMap(ctx, namespace, current)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// Revert returns the given data with the given changes reverted and whether
+// any entry got reverted. Only entries still having the value they got changed
+// to are reverted, so that changes of other writers applied in the meantime
+// are kept.
+func Revert(data map[string]string, changes []diff.Change) (map[string]string, bool) {
+	reverted := map[string]string{}
+	for k, v := range data {
+		reverted[k] = v
+	}
+
+	var changed bool
+	for _, c := range changes {
+		v, ok := reverted[c.Key]
+		if c.New == "" {
+			if ok {
+				continue
+			}
+		} else if !ok || v != c.New {
+			continue
+		}
+
+		if c.Old == "" {
+			delete(reverted, c.Key)
+		} else {
+			reverted[c.Key] = c.Old
+		}
+		changed = true
+	}
+
+	return reverted, changed
+}
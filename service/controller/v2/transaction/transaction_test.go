@@ -0,0 +1,71 @@
+package transaction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Transaction_Revert(t *testing.T) {
+	testCases := []struct {
+		Before          map[string]string
+		After           map[string]string
+		Current         map[string]string
+		ExpectedPartial bool
+		Expected        map[string]string
+		ExpectedChanged bool
+	}{
+		// Test 0 ensures added, updated and removed entries are reverted.
+		{
+			Before:          map[string]string{"31000": "al9qy/worker:30010", "31001": "al9qy/worker:30011"},
+			After:           map[string]string{"31000": "al9qy/worker:30020", "31002": "al9qy/worker:30012"},
+			Current:         map[string]string{"31000": "al9qy/worker:30020", "31002": "al9qy/worker:30012"},
+			ExpectedPartial: true,
+			Expected:        map[string]string{"31000": "al9qy/worker:30010", "31001": "al9qy/worker:30011"},
+			ExpectedChanged: true,
+		},
+
+		// Test 1 ensures entries changed by other writers in the meantime are
+		// kept.
+		{
+			Before:          map[string]string{"31000": "al9qy/worker:30010"},
+			After:           map[string]string{"31000": "al9qy/worker:30020", "31002": "al9qy/worker:30012"},
+			Current:         map[string]string{"31000": "p1l6x/worker:30010", "31001": "p1l6x/worker:30011"},
+			ExpectedPartial: true,
+			Expected:        map[string]string{"31000": "p1l6x/worker:30010", "31001": "p1l6x/worker:30011"},
+			ExpectedChanged: false,
+		},
+
+		// Test 2 ensures nothing is reverted without changes.
+		{
+			Before:          map[string]string{"31000": "al9qy/worker:30010"},
+			After:           map[string]string{"31000": "al9qy/worker:30010"},
+			Current:         map[string]string{"31000": "al9qy/worker:30010"},
+			ExpectedPartial: false,
+			Expected:        map[string]string{"31000": "al9qy/worker:30010"},
+			ExpectedChanged: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		var tx Transaction
+		tx.Begin(tc.Before)
+		tx.ConfigMapUpdated(tc.After)
+
+		if tx.IsPartial() != tc.ExpectedPartial {
+			t.Fatal("test", i, "expected", tc.ExpectedPartial, "got", tx.IsPartial())
+		}
+
+		reverted, changed := Revert(tc.Current, tx.configMapChanges)
+		if changed != tc.ExpectedChanged {
+			t.Fatal("test", i, "expected", tc.ExpectedChanged, "got", changed)
+		}
+		if !reflect.DeepEqual(reverted, tc.Expected) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, reverted)
+		}
+
+		tx.Commit()
+		if tx.IsPartial() {
+			t.Fatal("test", i, "expected", false, "got", true)
+		}
+	}
+}
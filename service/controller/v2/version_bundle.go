@@ -2,24 +2,12 @@ package v2
 
 import (
 	"github.com/giantswarm/versionbundle"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
 )
 
+// VersionBundle returns the version bundle of the IngressConfigs reconciled by
+// this resource set, which is the version bundle of the operator.
 func VersionBundle() versionbundle.Bundle {
-	return versionbundle.Bundle{
-		Changelogs: []versionbundle.Changelog{
-			{
-				Component:   "ingress-operator",
-				Description: "Introduce the first version of the ingress-operator.",
-				Kind:        versionbundle.KindAdded,
-			},
-		},
-		Components: []versionbundle.Component{
-			{
-				Name:    "ingress-operator",
-				Version: "0.1.0",
-			},
-		},
-		Name:    "ingress-operator",
-		Version: "0.1.0",
-	}
+	return project.NewVersionBundle()
 }
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/controller"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/giantswarm/ingress-operator/service/controller/queue"
+)
+
+// idleInformer is the informer the operatorkit controllers of the workers are
+// booted with. It never dispatches any events, since the workers dispatch the
+// events of their queues themselves.
+type idleInformer struct {
+	resyncPeriod time.Duration
+}
+
+func (i idleInformer) Boot(ctx context.Context) error {
+	return nil
+}
+
+func (i idleInformer) ResyncPeriod() time.Duration {
+	return i.resyncPeriod
+}
+
+func (i idleInformer) Watch(ctx context.Context) (chan watch.Event, chan watch.Event, chan error) {
+	return make(chan watch.Event), make(chan watch.Event), make(chan error)
+}
+
+// worker dispatches the events of its queue to the event functions of its
+// operatorkit controller.
+type worker struct {
+	controller *controller.Controller
+	queue      *queue.Informer
+}
+
+// workerGroup runs workers until they are shut down. Operatorkit controllers
+// process the events of their informer forever once booted and cannot be
+// stopped. They are therefore booted once with an idle informer, which ensures
+// the CRD and collects the reconciliation errors, while the workers dispatch
+// the events of their queues to the controllers until Shutdown.
+type workerGroup struct {
+	logger micrologger.Logger
+
+	bootOnce sync.Once
+	cancel   context.CancelFunc
+	mutex    sync.Mutex
+	running  sync.WaitGroup
+	workers  []worker
+}
+
+// Boot starts dispatching the events of all queues in the background. Calling
+// Boot after Shutdown resumes the dispatching.
+func (w *workerGroup) Boot() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.cancel != nil {
+		return
+	}
+
+	w.bootOnce.Do(func() {
+		for _, wk := range w.workers {
+			go wk.controller.Boot()
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	for _, wk := range w.workers {
+		w.running.Add(1)
+		go func(wk worker) {
+			defer w.running.Done()
+			w.run(ctx, wk)
+		}(wk)
+	}
+}
+
+// Shutdown stops dispatching events and waits for the reconciliations in
+// progress to finish. Objects waiting in the queues are kept and dispatched
+// once the workers are booted again. The cache metrics of the queues are
+// flushed, since their informers relist all objects once booted again.
+func (w *workerGroup) Shutdown() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	w.cancel = nil
+
+	w.running.Wait()
+
+	for _, wk := range w.workers {
+		wk.queue.Stop()
+		wk.queue.FlushCache()
+	}
+}
+
+// run dispatches the events of the given worker's queue to its controller
+// until the given context is canceled. An event being dispatched is always
+// reconciled to the end.
+func (w *workerGroup) run(ctx context.Context, wk worker) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-wk.controller.Booted():
+	}
+
+	err := wk.queue.Boot(ctx)
+	if err != nil {
+		w.logger.Log("level", "error", "message", "failed booting queue", "stack", fmt.Sprintf("%#v", err))
+		return
+	}
+
+	deleteChan, updateChan, errChan := wk.queue.Watch(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-deleteChan:
+			wk.controller.DeleteFunc(e.Object)
+		case e := <-updateChan:
+			wk.controller.UpdateFunc(nil, e.Object)
+		case err := <-errChan:
+			w.logger.Log("level", "error", "message", "failed watching objects", "stack", fmt.Sprintf("%#v", err))
+		}
+	}
+}
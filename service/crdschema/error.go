@@ -0,0 +1,23 @@
+package crdschema
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var readOnlyError = &microerror.Error{
+	Kind: "readOnlyError",
+}
+
+// IsReadOnly asserts readOnlyError.
+func IsReadOnly(err error) bool {
+	return microerror.Cause(err) == readOnlyError
+}
@@ -0,0 +1,179 @@
+// Package crdschema protects IngressConfigs from losing fields when the
+// IngressConfig CRD of the cluster is newer than this version of the operator,
+// e.g. while rolling back the operator after a CRD upgrade. IngressConfigs are
+// decoded into the vendored types, so updating them silently strips every
+// field the types do not know. On boot the guard compares the CRD schema of
+// the cluster against the fields this version knows. In case the schema
+// declares unknown fields, the operator is degraded and IngressConfigs setting
+// any of them become read-only, which is logged and exposed as metrics.
+package crdschema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+// Config represents the configuration used to create a new guard.
+type Config struct {
+	G8sClient    clients.UnstructuredIngressConfigs
+	K8sExtClient apiextensionsclient.Interface
+	Logger       micrologger.Logger
+}
+
+// DefaultConfig provides a default configuration to create a new guard by
+// best effort.
+func DefaultConfig() Config {
+	return Config{
+		G8sClient:    nil,
+		K8sExtClient: nil,
+		Logger:       nil,
+	}
+}
+
+// Guard refuses updates of IngressConfigs which would drop fields unknown to
+// this version of the operator.
+type Guard struct {
+	// Dependencies.
+	g8sClient    clients.UnstructuredIngressConfigs
+	k8sExtClient apiextensionsclient.Interface
+	logger       micrologger.Logger
+
+	// Internals.
+	mutex         sync.Mutex
+	unknownFields []string
+}
+
+// New creates a new configured guard.
+func New(config Config) (*Guard, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.K8sExtClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sExtClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	g := &Guard{
+		// Dependencies.
+		g8sClient:    config.G8sClient,
+		k8sExtClient: config.K8sExtClient,
+		logger:       config.Logger,
+
+		// Internals.
+		mutex:         sync.Mutex{},
+		unknownFields: nil,
+	}
+
+	return g, nil
+}
+
+// Check compares the IngressConfig CRD schema of the cluster against the
+// fields this version of the operator knows and degrades the operator in case
+// the schema declares unknown fields. A missing CRD is compatible, since it is
+// created by this version of the operator then.
+func (g *Guard) Check(ctx context.Context) error {
+	name := v1alpha1.NewIngressConfigCRD().Name
+
+	crd, err := g.k8sExtClient.ApiextensionsV1beta1().CustomResourceDefinitions().Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		g.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("CRD %s does not exist yet", name))
+		g.setUnknownFields(nil)
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	unknownFields := UnknownSchemaFields(crd)
+	g.setUnknownFields(unknownFields)
+
+	if len(unknownFields) == 0 {
+		g.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("schema of CRD %s is compatible", name))
+	} else {
+		g.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("schema of CRD %s is newer than this version of the operator, IngressConfigs setting unknown fields are read-only: %s", name, strings.Join(unknownFields, ", ")))
+	}
+
+	return nil
+}
+
+// Degraded returns whether the CRD schema of the cluster declares fields
+// unknown to this version of the operator.
+func (g *Guard) Degraded() bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return len(g.unknownFields) > 0
+}
+
+// Wrap returns IngressConfigs refusing updates of IngressConfigs which set
+// fields unknown to this version of the operator with a read-only error while
+// the operator is degraded. All other operations are served by the given
+// IngressConfigs.
+func (g *Guard) Wrap(g8sClient clients.IngressConfigs) clients.IngressConfigs {
+	return &guardedIngressConfigs{
+		IngressConfigs: g8sClient,
+		guard:          g,
+	}
+}
+
+// ensureWritable returns a read-only error in case the operator is degraded
+// and the given IngressConfig, as stored by the API server, sets fields unknown
+// to this version of the operator.
+func (g *Guard) ensureWritable(ctx context.Context, namespace, name string) error {
+	if !g.Degraded() {
+		return nil
+	}
+
+	current, err := g.g8sClient.GetIngressConfigUnstructured(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		// The update fails anyway, so the API server reports it.
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	unknownFields := UnknownObjectFields(current.Object)
+	if len(unknownFields) > 0 {
+		refusedUpdatesCounter.Inc()
+		g.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("refusing update of IngressConfig %s/%s dropping unknown fields: %s", namespace, name, strings.Join(unknownFields, ", ")))
+
+		return microerror.Maskf(readOnlyError, "IngressConfig %s/%s sets fields unknown to this version of the operator: %s", namespace, name, strings.Join(unknownFields, ", "))
+	}
+
+	return nil
+}
+
+func (g *Guard) setUnknownFields(unknownFields []string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.unknownFields = unknownFields
+	unknownFieldsGauge.Set(float64(len(unknownFields)))
+}
+
+type guardedIngressConfigs struct {
+	clients.IngressConfigs
+
+	guard *Guard
+}
+
+func (c *guardedIngressConfigs) UpdateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error) {
+	err := c.guard.ensureWritable(ctx, namespace, ingressConfig.Name)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return c.IngressConfigs.UpdateIngressConfig(ctx, namespace, ingressConfig)
+}
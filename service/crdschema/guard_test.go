@@ -0,0 +1,102 @@
+package crdschema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+)
+
+// testUnstructuredIngressConfigs is an in-memory implementation of
+// clients.UnstructuredIngressConfigs.
+type testUnstructuredIngressConfigs struct {
+	items map[string]map[string]interface{}
+}
+
+func (c *testUnstructuredIngressConfigs) GetIngressConfigUnstructured(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	obj, ok := c.items[namespace+"/"+name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "core.giantswarm.io", Resource: "ingressconfigs"}, name)
+	}
+
+	return &unstructured.Unstructured{Object: obj}, nil
+}
+
+func Test_Guard_Wrap(t *testing.T) {
+	g8sClient := &testUnstructuredIngressConfigs{
+		items: map[string]map[string]interface{}{
+			"default/al9qy": {
+				"metadata": map[string]interface{}{"name": "al9qy"},
+				"spec": map[string]interface{}{
+					"guestCluster":   map[string]interface{}{"id": "al9qy"},
+					"tlsPassthrough": true,
+				},
+			},
+			"default/p1l6x": {
+				"metadata": map[string]interface{}{"name": "p1l6x"},
+				"spec": map[string]interface{}{
+					"guestCluster": map[string]interface{}{"id": "p1l6x"},
+				},
+			},
+		},
+	}
+
+	g, err := New(Config{
+		G8sClient:    g8sClient,
+		K8sExtClient: &apiextensionsclient.Clientset{},
+		Logger:       microloggertest.New(),
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newIngressConfig := func(name string) *v1alpha1.IngressConfig {
+		return &v1alpha1.IngressConfig{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	ingressConfigs := clientstest.NewIngressConfigs(*newIngressConfig("al9qy"), *newIngressConfig("p1l6x"))
+	c := g.Wrap(ingressConfigs)
+	ctx := context.TODO()
+
+	// All updates pass while the operator is not degraded.
+	_, err = c.UpdateIngressConfig(ctx, "default", newIngressConfig("al9qy"))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	g.setUnknownFields([]string{"spec.tlsPassthrough"})
+	if !g.Degraded() {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// Updates dropping unknown fields are refused once degraded.
+	_, err = c.UpdateIngressConfig(ctx, "default", newIngressConfig("al9qy"))
+	if !IsReadOnly(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// IngressConfigs not setting unknown fields stay writable.
+	_, err = c.UpdateIngressConfig(ctx, "default", newIngressConfig("p1l6x"))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Missing IngressConfigs are left to the API server.
+	_, err = c.UpdateIngressConfig(ctx, "default", newIngressConfig("cl0ck"))
+	if !clients.IsNotFound(err) {
+		t.Fatal("expected", "not found error", "got", err)
+	}
+
+	if ingressConfigs.Updates() != 3 {
+		t.Fatal("expected", 3, "got", ingressConfigs.Updates())
+	}
+}
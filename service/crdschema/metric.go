@@ -0,0 +1,35 @@
+package crdschema
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "crd_schema"
+)
+
+var (
+	refusedUpdatesCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "refused_updates_total",
+			Help:      "A counter metric expressing the number of IngressConfig updates refused because the IngressConfig has fields unknown to this version of the operator.",
+		},
+	)
+	unknownFieldsGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "unknown_fields",
+			Help:      "A gauge metric expressing the number of fields of the IngressConfig CRD schema of the cluster unknown to this version of the operator. IngressConfigs using them are read-only.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(refusedUpdatesCounter)
+	prometheus.MustRegister(unknownFieldsGauge)
+}
@@ -0,0 +1,146 @@
+package crdschema
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+const (
+	// metadataField is the field of the object metadata. It is managed by the
+	// API server and not validated by CRD schemas, so its content is never
+	// compared.
+	metadataField = "metadata"
+)
+
+// knownFields are the paths of the IngressConfig fields this version of the
+// operator knows, e.g. "spec.guestCluster.id". Items of lists share the path
+// of the list.
+var knownFields = newKnownFields(reflect.TypeOf(v1alpha1.IngressConfig{}))
+
+func newKnownFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	addKnownFields(fields, t, "")
+
+	return fields
+}
+
+func addKnownFields(fields map[string]bool, t reflect.Type, prefix string) {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		addKnownFields(fields, t.Elem(), prefix)
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" && f.Anonymous {
+			addKnownFields(fields, f.Type, prefix)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		path := joinPath(prefix, name)
+		fields[path] = true
+
+		if path != metadataField {
+			addKnownFields(fields, f.Type, path)
+		}
+	}
+}
+
+// UnknownSchemaFields returns the paths of the fields the given CRD schema
+// declares, but this version of the operator does not know, sorted by path.
+// Only the outermost unknown field of a subtree is returned. CRDs without
+// validation schema do not declare any fields.
+func UnknownSchemaFields(crd *apiextensionsv1beta1.CustomResourceDefinition) []string {
+	if crd.Spec.Validation == nil || crd.Spec.Validation.OpenAPIV3Schema == nil {
+		return nil
+	}
+
+	var unknown []string
+	addUnknownSchemaFields(&unknown, *crd.Spec.Validation.OpenAPIV3Schema, "")
+	sort.Strings(unknown)
+
+	return unknown
+}
+
+func addUnknownSchemaFields(unknown *[]string, props apiextensionsv1beta1.JSONSchemaProps, prefix string) {
+	for name, p := range props.Properties {
+		path := joinPath(prefix, name)
+		if !knownFields[path] {
+			*unknown = append(*unknown, path)
+			continue
+		}
+		if path != metadataField {
+			addUnknownSchemaFields(unknown, p, path)
+		}
+	}
+
+	if props.Items != nil {
+		if props.Items.Schema != nil {
+			addUnknownSchemaFields(unknown, *props.Items.Schema, prefix)
+		}
+		for _, p := range props.Items.JSONSchemas {
+			addUnknownSchemaFields(unknown, p, prefix)
+		}
+	}
+}
+
+// UnknownObjectFields returns the paths of the fields the given IngressConfig,
+// as stored by the API server, sets, but this version of the operator does not
+// know, sorted by path. These fields would be dropped by updates of the
+// IngressConfig decoded into the vendored types.
+func UnknownObjectFields(obj map[string]interface{}) []string {
+	unknown := map[string]bool{}
+	addUnknownObjectFields(unknown, obj, "")
+
+	var l []string
+	for path := range unknown {
+		l = append(l, path)
+	}
+	sort.Strings(l)
+
+	return l
+}
+
+func addUnknownObjectFields(unknown map[string]bool, v interface{}, prefix string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			path := joinPath(prefix, name)
+			if !knownFields[path] {
+				unknown[path] = true
+				continue
+			}
+			if path != metadataField {
+				addUnknownObjectFields(unknown, value, path)
+			}
+		}
+	case []interface{}:
+		for _, value := range v {
+			addUnknownObjectFields(unknown, value, prefix)
+		}
+	}
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
@@ -0,0 +1,134 @@
+package crdschema
+
+import (
+	"reflect"
+	"testing"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+)
+
+func Test_UnknownSchemaFields(t *testing.T) {
+	testCases := []struct {
+		name     string
+		crd      *apiextensionsv1beta1.CustomResourceDefinition
+		expected []string
+	}{
+		{
+			name:     "case 0: CRD without validation schema",
+			crd:      &apiextensionsv1beta1.CustomResourceDefinition{},
+			expected: nil,
+		},
+		{
+			name: "case 1: schema declaring known fields only",
+			crd: newTestCRD(map[string]apiextensionsv1beta1.JSONSchemaProps{
+				"metadata": {
+					Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+						"name": {},
+					},
+				},
+				"spec": {
+					Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+						"guestCluster": {
+							Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+								"id": {},
+							},
+						},
+						"protocolPorts": {
+							Items: &apiextensionsv1beta1.JSONSchemaPropsOrArray{
+								Schema: &apiextensionsv1beta1.JSONSchemaProps{
+									Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+										"lbPort": {},
+									},
+								},
+							},
+						},
+					},
+				},
+			}),
+			expected: nil,
+		},
+		{
+			name: "case 2: schema declaring unknown fields",
+			crd: newTestCRD(map[string]apiextensionsv1beta1.JSONSchemaProps{
+				"spec": {
+					Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+						"protocolPorts": {
+							Items: &apiextensionsv1beta1.JSONSchemaPropsOrArray{
+								Schema: &apiextensionsv1beta1.JSONSchemaProps{
+									Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+										"allowedSourceRanges": {},
+										"lbPort":              {},
+									},
+								},
+							},
+						},
+						"tlsPassthrough": {
+							Properties: map[string]apiextensionsv1beta1.JSONSchemaProps{
+								"enabled": {},
+							},
+						},
+					},
+				},
+				"status": {},
+			}),
+			expected: []string{
+				"spec.protocolPorts.allowedSourceRanges",
+				"spec.tlsPassthrough",
+				"status",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			unknown := UnknownSchemaFields(tc.crd)
+			if !reflect.DeepEqual(unknown, tc.expected) {
+				t.Fatalf("expected %#v got %#v", tc.expected, unknown)
+			}
+		})
+	}
+}
+
+func Test_UnknownObjectFields(t *testing.T) {
+	obj := map[string]interface{}{
+		"apiVersion": "core.giantswarm.io/v1alpha1",
+		"kind":       "IngressConfig",
+		"metadata": map[string]interface{}{
+			"name":        "al9qy",
+			"annotations": map[string]interface{}{"a": "b"},
+		},
+		"spec": map[string]interface{}{
+			"guestCluster": map[string]interface{}{
+				"id": "al9qy",
+			},
+			"protocolPorts": []interface{}{
+				map[string]interface{}{"lbPort": int64(31000)},
+				map[string]interface{}{"lbPort": int64(31001), "idleTimeout": "60s"},
+				map[string]interface{}{"lbPort": int64(31002), "idleTimeout": "30s"},
+			},
+			"tlsPassthrough": true,
+		},
+	}
+
+	expected := []string{
+		"spec.protocolPorts.idleTimeout",
+		"spec.tlsPassthrough",
+	}
+
+	unknown := UnknownObjectFields(obj)
+	if !reflect.DeepEqual(unknown, expected) {
+		t.Fatalf("expected %#v got %#v", expected, unknown)
+	}
+}
+
+func newTestCRD(properties map[string]apiextensionsv1beta1.JSONSchemaProps) *apiextensionsv1beta1.CustomResourceDefinition {
+	return &apiextensionsv1beta1.CustomResourceDefinition{
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Validation: &apiextensionsv1beta1.CustomResourceValidation{
+				OpenAPIV3Schema: &apiextensionsv1beta1.JSONSchemaProps{
+					Properties: properties,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,208 @@
+// Package deprecation implements the reporting of IngressConfigs which still
+// rely on deprecated behaviour, so that they can be cleaned up before the
+// legacy code paths are removed. IngressConfigs without version bundle version
+// are only reconciled because the current controller version adopts them as
+// a fallback. IngressConfigs of a version bundle version no controller
+// version handles anymore, e.g. the removed v1 controller, are not reconciled
+// at all. Both are counted and reported as a warning and a metric on boot and
+// periodically afterwards.
+//
+// Legacy ingresstpr objects are not reported here, see the legacy package.
+package deprecation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+const (
+	// ReasonUnsupportedVersion is the reason of IngressConfigs whose version
+	// bundle version is handled by no controller version.
+	ReasonUnsupportedVersion = "unsupported_version"
+	// ReasonUnversioned is the reason of IngressConfigs without version bundle
+	// version.
+	ReasonUnversioned = "unversioned"
+)
+
+// Config represents the configuration used to create a new reporter.
+type Config struct {
+	G8sClient clients.IngressConfigs
+	Logger    micrologger.Logger
+
+	// Interval is the interval in which the deprecations are reported.
+	Interval time.Duration
+	// Versions are the version bundle versions handled by the controller.
+	Versions []string
+}
+
+// Reporter reports IngressConfigs relying on deprecated behaviour.
+type Reporter struct {
+	g8sClient clients.IngressConfigs
+	logger    micrologger.Logger
+
+	mutex sync.Mutex
+	stop  chan struct{}
+
+	interval time.Duration
+	versions []string
+}
+
+// Result describes the IngressConfigs relying on deprecated behaviour.
+type Result struct {
+	// IngressConfigs are the deprecated IngressConfigs in the format
+	// namespace/name by reason.
+	IngressConfigs map[string][]string
+}
+
+// New creates a new configured reporter.
+func New(config Config) (*Reporter, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+	if len(config.Versions) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Versions must not be empty", config)
+	}
+
+	r := &Reporter{
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+
+		mutex: sync.Mutex{},
+		stop:  nil,
+
+		interval: config.Interval,
+		versions: config.Versions,
+	}
+
+	return r, nil
+}
+
+// Boot reports the deprecations right away and then periodically in the
+// background.
+func (r *Reporter) Boot() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stop != nil {
+		return
+	}
+
+	r.stop = make(chan struct{})
+	go r.run(r.stop)
+}
+
+// Shutdown stops reporting the deprecations.
+func (r *Reporter) Shutdown() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.stop == nil {
+		return
+	}
+
+	close(r.stop)
+	r.stop = nil
+}
+
+// Report counts the IngressConfigs relying on deprecated behaviour, updates
+// the metric and logs a warning in case there are any.
+func (r *Reporter) Report(ctx context.Context) (Result, error) {
+	list, err := r.g8sClient.ListIngressConfigs(ctx, "")
+	if err != nil {
+		return Result{}, microerror.Mask(err)
+	}
+
+	result := Result{
+		IngressConfigs: map[string][]string{
+			ReasonUnsupportedVersion: nil,
+			ReasonUnversioned:        nil,
+		},
+	}
+
+	for _, customObject := range list.Items {
+		reason := r.reason(key.VersionBundleVersion(customObject))
+		if reason == "" {
+			continue
+		}
+		result.IngressConfigs[reason] = append(result.IngressConfigs[reason], customObject.Namespace+"/"+customObject.Name)
+	}
+
+	var reasons []string
+	for reason, names := range result.IngressConfigs {
+		sort.Strings(names)
+		deprecatedGauge.WithLabelValues(reason).Set(float64(len(names)))
+
+		if len(names) > 0 {
+			reasons = append(reasons, reason)
+		}
+	}
+	sort.Strings(reasons)
+
+	for _, reason := range reasons {
+		names := result.IngressConfigs[reason]
+		r.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("found %d deprecated IngressConfigs: %s", len(names), describe(reason)), "reason", reason, "ingressConfigs", strings.Join(names, ","))
+	}
+
+	return result, nil
+}
+
+// reason returns the reason the given version bundle version is deprecated,
+// and an empty string in case it is not.
+func (r *Reporter) reason(version string) string {
+	if version == "" {
+		return ReasonUnversioned
+	}
+	for _, v := range r.versions {
+		if v == version {
+			return ""
+		}
+	}
+
+	return ReasonUnsupportedVersion
+}
+
+func (r *Reporter) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := r.Report(context.Background())
+		if err != nil {
+			r.logger.Log("level", "error", "message", "failed reporting deprecated IngressConfigs", "stack", fmt.Sprintf("%#v", err))
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func describe(reason string) string {
+	switch reason {
+	case ReasonUnsupportedVersion:
+		return "their version bundle version is not reconciled by any controller version anymore, update it to a supported version"
+	case ReasonUnversioned:
+		return "they lack a version bundle version and are only reconciled by fallback, set the version bundle version explicitly"
+	}
+
+	return reason
+}
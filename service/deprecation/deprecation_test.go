@@ -0,0 +1,70 @@
+package deprecation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+)
+
+func newTestIngressConfig(name, version string) v1alpha1.IngressConfig {
+	return v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			VersionBundle: v1alpha1.IngressConfigSpecVersionBundle{
+				Version: version,
+			},
+		},
+	}
+}
+
+func Test_Reporter_Report(t *testing.T) {
+	g8sClient := clientstest.NewIngressConfigs(
+		newTestIngressConfig("p1l6x", "0.2.0"),
+		newTestIngressConfig("x7a2b", "0.1.0"),
+		newTestIngressConfig("cl0ck", ""),
+		newTestIngressConfig("al9qy", ""),
+	)
+
+	r, err := New(Config{
+		G8sClient: g8sClient,
+		Logger:    microloggertest.New(),
+
+		Interval: time.Hour,
+		Versions: []string{"0.2.0"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	result, err := r.Report(context.TODO())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	expected := map[string][]string{
+		ReasonUnsupportedVersion: {"default/x7a2b"},
+		ReasonUnversioned:        {"default/al9qy", "default/cl0ck"},
+	}
+	if len(result.IngressConfigs) != len(expected) {
+		t.Fatal("expected", expected, "got", result.IngressConfigs)
+	}
+	for reason, names := range expected {
+		if len(result.IngressConfigs[reason]) != len(names) {
+			t.Fatal("expected", names, "got", result.IngressConfigs[reason])
+		}
+		for i, n := range names {
+			if result.IngressConfigs[reason][i] != n {
+				t.Fatal("test", i, "expected", n, "got", result.IngressConfigs[reason][i])
+			}
+		}
+	}
+}
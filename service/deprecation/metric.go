@@ -0,0 +1,27 @@
+package deprecation
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "deprecation"
+)
+
+var (
+	deprecatedGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "ingress_configs",
+			Help:      "A gauge metric expressing the number of IngressConfigs still relying on deprecated behaviour, by reason.",
+		},
+		[]string{"reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(deprecatedGauge)
+}
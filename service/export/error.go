@@ -0,0 +1,21 @@
+package export
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var unknownFormatError = &microerror.Error{
+	Kind: "unknownFormatError",
+}
+
+// IsUnknownFormat asserts unknownFormatError.
+func IsUnknownFormat(err error) bool {
+	return microerror.Cause(err) == unknownFormatError
+}
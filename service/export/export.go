@@ -0,0 +1,137 @@
+// Package export renders the allocation table of the allocation ledger in
+// formats known to engineers debugging ingress controllers, i.e. an nginx
+// stream config snippet and an haproxy map file. The export is generated from
+// the ledger rather than from the config maps of the ingress controllers, so
+// that it can be compared against them. The ledger does not record the
+// namespace and service of guest clusters, so upstreams follow the defaults
+// of IngressConfigs.
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+// Format is a format the allocation table can be rendered in.
+type Format string
+
+const (
+	// HAProxy is the format of haproxy map files, mapping LB ports to the
+	// upstreams in the notation of the config maps of the ingress controllers,
+	// e.g. "30010 al9qy/worker:30010".
+	HAProxy Format = "haproxy"
+	// Nginx is the format of nginx stream config snippets, holding a server
+	// block per LB port.
+	Nginx Format = "nginx"
+)
+
+// IsFormat returns whether the given format is known.
+func IsFormat(f Format) bool {
+	return f == HAProxy || f == Nginx
+}
+
+// Config represents the configuration used to create a new exporter.
+type Config struct {
+	Ledger *ledger.Ledger
+	Logger micrologger.Logger
+}
+
+// Exporter renders the allocation table of the allocation ledger.
+type Exporter struct {
+	ledger *ledger.Ledger
+	logger micrologger.Logger
+}
+
+// New creates a new configured exporter.
+func New(config Config) (*Exporter, error) {
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Ledger must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	e := &Exporter{
+		ledger: config.Ledger,
+		logger: config.Logger,
+	}
+
+	return e, nil
+}
+
+// Export renders the allocations recorded in the ledger in the given format.
+func (e *Exporter) Export(ctx context.Context, f Format) ([]byte, error) {
+	allocations, err := e.ledger.List(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	b, err := Render(f, allocations)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return b, nil
+}
+
+// Render renders the given allocations in the given format, ordered by LB
+// port. External allocations are rendered as comments, since their upstreams
+// are not managed by the operator.
+func Render(f Format, allocations []ledger.Allocation) ([]byte, error) {
+	if !IsFormat(f) {
+		return nil, microerror.Maskf(unknownFormatError, "format must be %s or %s, got %q", HAProxy, Nginx, f)
+	}
+
+	sorted := append([]ledger.Allocation{}, allocations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LBPort < sorted[j].LBPort })
+
+	var b bytes.Buffer
+
+	b.WriteString("# LB port allocations recorded in the allocation ledger of ingress-operator.\n")
+	b.WriteString("# Upstreams assume the default namespace and service of guest clusters.\n")
+
+	switch f {
+	case HAProxy:
+		renderHAProxy(&b, sorted)
+	case Nginx:
+		renderNginx(&b, sorted)
+	}
+
+	return b.Bytes(), nil
+}
+
+func renderHAProxy(b *bytes.Buffer, allocations []ledger.Allocation) {
+	for _, a := range allocations {
+		if a.External {
+			fmt.Fprintf(b, "# %d external %s\n", a.LBPort, a.ClusterID)
+			continue
+		}
+
+		fmt.Fprintf(b, "%d %s/%s:%d\n", a.LBPort, a.ClusterID, key.DefaultClusterService, a.IngressPort)
+	}
+}
+
+func renderNginx(b *bytes.Buffer, allocations []ledger.Allocation) {
+	for _, a := range allocations {
+		b.WriteString("\n")
+
+		if a.External {
+			fmt.Fprintf(b, "# LB port %d of guest cluster %s is managed externally.\n", a.LBPort, a.ClusterID)
+			continue
+		}
+
+		fmt.Fprintf(b, "# Guest cluster %s, protocol %s.\n", a.ClusterID, a.Protocol)
+		fmt.Fprintf(b, "server {\n")
+		fmt.Fprintf(b, "    listen %d;\n", a.LBPort)
+		fmt.Fprintf(b, "    proxy_pass %s.%s.svc:%d;\n", key.DefaultClusterService, a.ClusterID, a.IngressPort)
+		fmt.Fprintf(b, "}\n")
+	}
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+func Test_Export_Render(t *testing.T) {
+	allocations := []ledger.Allocation{
+		{ClusterID: "p1l6x", External: true, LBPort: 31002},
+		{ClusterID: "al9qy", IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+		{ClusterID: "al9qy", IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+	}
+
+	testCases := []struct {
+		Format       Format
+		Expected     string
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures allocations are rendered as haproxy map file ordered
+		// by LB port.
+		{
+			Format: HAProxy,
+			Expected: `# LB port allocations recorded in the allocation ledger of ingress-operator.
+# Upstreams assume the default namespace and service of guest clusters.
+31000 al9qy/worker:30010
+31001 al9qy/worker:30011
+# 31002 external p1l6x
+`,
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures allocations are rendered as nginx stream config
+		// snippet ordered by LB port.
+		{
+			Format: Nginx,
+			Expected: `# LB port allocations recorded in the allocation ledger of ingress-operator.
+# Upstreams assume the default namespace and service of guest clusters.
+
+# Guest cluster al9qy, protocol http.
+server {
+    listen 31000;
+    proxy_pass worker.al9qy.svc:30010;
+}
+
+# Guest cluster al9qy, protocol https.
+server {
+    listen 31001;
+    proxy_pass worker.al9qy.svc:30011;
+}
+
+# LB port 31002 of guest cluster p1l6x is managed externally.
+`,
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures unknown formats are rejected.
+		{
+			Format:       "traefik",
+			Expected:     "",
+			ErrorMatcher: IsUnknownFormat,
+		},
+	}
+
+	for i, tc := range testCases {
+		b, err := Render(tc.Format, allocations)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if string(b) != tc.Expected {
+			t.Fatal("test", i, "expected", tc.Expected, "got", string(b))
+		}
+	}
+}
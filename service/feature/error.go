@@ -0,0 +1,14 @@
+package feature
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var unknownVersionError = &microerror.Error{
+	Kind: "unknownVersionError",
+}
+
+// IsUnknownVersion asserts unknownVersionError.
+func IsUnknownVersion(err error) bool {
+	return microerror.Cause(err) == unknownVersionError
+}
@@ -0,0 +1,172 @@
+// Package feature implements the registry of the spec fields and annotations
+// of IngressConfigs supported per version bundle version. It is the machine
+// readable changelog of the IngressConfig API, served by the features
+// endpoint and used by the validation resource to refuse IngressConfigs using
+// features their targeted version bundle version does not support. Every new
+// spec field or annotation has to be registered with the version introducing
+// it.
+package feature
+
+import (
+	"strings"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+const (
+	// KindAnnotation is the kind of features configured by an annotation.
+	KindAnnotation = "annotation"
+	// KindField is the kind of features configured by a spec field.
+	KindField = "field"
+)
+
+// Feature is a spec field or annotation of IngressConfigs.
+type Feature struct {
+	// Kind is either KindAnnotation or KindField.
+	Kind string `json:"kind"`
+	// Name is the annotation key or the JSON path of the spec field, e.g.
+	// "spec.guestCluster.service".
+	Name string `json:"name"`
+	// Since is the version bundle version introducing the feature.
+	Since string `json:"since"`
+}
+
+// Version is a version bundle version together with the features it
+// supports.
+type Version struct {
+	Version  string    `json:"version"`
+	Features []Feature `json:"features"`
+}
+
+// Versions are the version bundle versions in the order of their release. The
+// last one is the current version, which unversioned IngressConfigs target.
+var Versions = []string{
+	"0.1.0",
+}
+
+// Features are all features in the order of their introduction.
+var Features = []Feature{
+	{Kind: KindField, Name: "spec.guestCluster.id", Since: "0.1.0"},
+	{Kind: KindField, Name: "spec.guestCluster.namespace", Since: "0.1.0"},
+	{Kind: KindField, Name: "spec.guestCluster.service", Since: "0.1.0"},
+	{Kind: KindField, Name: "spec.hostCluster.ingressController.configMap", Since: "0.1.0"},
+	{Kind: KindField, Name: "spec.hostCluster.ingressController.namespace", Since: "0.1.0"},
+	{Kind: KindField, Name: "spec.hostCluster.ingressController.service", Since: "0.1.0"},
+	{Kind: KindField, Name: "spec.protocolPorts", Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.ApplicationProtocolsAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.CanaryAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.DeleteProtectionAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.DisabledProtocolsAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.ExternalPortsAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.IdleTimeoutAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.IngressPortSelectorAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.MaxConnectionsAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.NamedPortsAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.PinnedPortsAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.SourceRangesAnnotation, Since: "0.1.0"},
+	{Kind: KindAnnotation, Name: key.TLSPassthroughAnnotation, Since: "0.1.0"},
+}
+
+// Changelog returns the registered versions together with the features each
+// of them supports.
+func Changelog() []Version {
+	var changelog []Version
+	for _, v := range Versions {
+		supported, _ := Supported(v)
+		changelog = append(changelog, Version{Version: v, Features: supported})
+	}
+
+	return changelog
+}
+
+// Supported returns the features supported by the given version bundle
+// version. Features are supported by the version introducing them and all
+// later versions. The empty version is the current version.
+func Supported(version string) ([]Feature, error) {
+	index := indexOf(version)
+	if index < 0 {
+		return nil, microerror.Maskf(unknownVersionError, "version bundle version %q is not registered", version)
+	}
+
+	var supported []Feature
+	for _, f := range Features {
+		if indexOf(f.Since) <= index {
+			supported = append(supported, f)
+		}
+	}
+
+	return supported, nil
+}
+
+// Unsupported returns the names of the features the given custom object uses
+// which its version bundle version does not support.
+func Unsupported(customObject v1alpha1.IngressConfig) ([]string, error) {
+	supported, err := Supported(key.VersionBundleVersion(customObject))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	names := map[string]bool{}
+	for _, f := range supported {
+		names[f.Name] = true
+	}
+
+	var unsupported []string
+	for _, n := range Used(customObject) {
+		if !names[n] {
+			unsupported = append(unsupported, n)
+		}
+	}
+
+	return unsupported, nil
+}
+
+// Used returns the names of the registered features the given custom object
+// uses. Spec fields are used in case they are not empty, annotations in case
+// they are set.
+func Used(customObject v1alpha1.IngressConfig) []string {
+	fields := map[string]bool{
+		"spec.guestCluster.id":                         customObject.Spec.GuestCluster.ID != "",
+		"spec.guestCluster.namespace":                  customObject.Spec.GuestCluster.Namespace != "",
+		"spec.guestCluster.service":                    customObject.Spec.GuestCluster.Service != "",
+		"spec.hostCluster.ingressController.configMap": customObject.Spec.HostCluster.IngressController.ConfigMap != "",
+		"spec.hostCluster.ingressController.namespace": customObject.Spec.HostCluster.IngressController.Namespace != "",
+		"spec.hostCluster.ingressController.service":   customObject.Spec.HostCluster.IngressController.Service != "",
+		"spec.protocolPorts":                           len(customObject.Spec.ProtocolPorts) != 0,
+	}
+
+	var used []string
+	for _, f := range Features {
+		switch f.Kind {
+		case KindAnnotation:
+			if strings.TrimSpace(customObject.GetAnnotations()[f.Name]) != "" {
+				used = append(used, f.Name)
+			}
+		case KindField:
+			if fields[f.Name] {
+				used = append(used, f.Name)
+			}
+		}
+	}
+
+	return used
+}
+
+// indexOf returns the position of the given version in the order of their
+// release, which is -1 for unknown versions.
+func indexOf(version string) int {
+	if version == "" {
+		return len(Versions) - 1
+	}
+
+	for i, v := range Versions {
+		if v == version {
+			return i
+		}
+	}
+
+	return -1
+}
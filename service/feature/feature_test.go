@@ -0,0 +1,119 @@
+package feature
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+// Test_Feature_Features ensures every feature is introduced by a registered
+// version and registered only once.
+func Test_Feature_Features(t *testing.T) {
+	seen := map[string]bool{}
+	for i, f := range Features {
+		if indexOf(f.Since) < 0 || f.Since == "" {
+			t.Fatal("test", i, "expected", Versions, "got", f.Since)
+		}
+		if f.Kind != KindAnnotation && f.Kind != KindField {
+			t.Fatal("test", i, "expected", []string{KindAnnotation, KindField}, "got", f.Kind)
+		}
+		if seen[f.Name] {
+			t.Fatal("test", i, "expected", false, "got", true)
+		}
+		seen[f.Name] = true
+	}
+}
+
+func Test_Feature_Unsupported(t *testing.T) {
+	versions, features := Versions, Features
+	defer func() { Versions, Features = versions, features }()
+
+	Versions = []string{"0.1.0", "0.2.0"}
+	Features = []Feature{
+		{Kind: KindField, Name: "spec.guestCluster.id", Since: "0.1.0"},
+		{Kind: KindField, Name: "spec.guestCluster.service", Since: "0.2.0"},
+		{Kind: KindAnnotation, Name: key.PinnedPortsAnnotation, Since: "0.1.0"},
+		{Kind: KindAnnotation, Name: key.NamedPortsAnnotation, Since: "0.2.0"},
+	}
+
+	testCases := []struct {
+		Version      string
+		Annotations  map[string]string
+		Service      string
+		Expected     []string
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures features of the targeted version are supported.
+		{
+			Version:      "0.1.0",
+			Annotations:  map[string]string{key.PinnedPortsAnnotation: "31000"},
+			Service:      "",
+			Expected:     nil,
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures features of later versions are not supported.
+		{
+			Version:      "0.1.0",
+			Annotations:  map[string]string{key.NamedPortsAnnotation: "31000"},
+			Service:      "worker",
+			Expected:     []string{"spec.guestCluster.service", key.NamedPortsAnnotation},
+			ErrorMatcher: nil,
+		},
+
+		// Test 2 ensures unversioned custom objects target the current version.
+		{
+			Version:      "",
+			Annotations:  map[string]string{key.NamedPortsAnnotation: "31000"},
+			Service:      "worker",
+			Expected:     nil,
+			ErrorMatcher: nil,
+		},
+
+		// Test 3 ensures unknown versions are refused.
+		{
+			Version:      "9.9.9",
+			Annotations:  nil,
+			Service:      "",
+			Expected:     nil,
+			ErrorMatcher: IsUnknownVersion,
+		},
+	}
+
+	for i, tc := range testCases {
+		customObject := v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: tc.Annotations,
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+					ID:      "al9qy",
+					Service: tc.Service,
+				},
+				VersionBundle: v1alpha1.IngressConfigSpecVersionBundle{
+					Version: tc.Version,
+				},
+			},
+		}
+
+		unsupported, err := Unsupported(customObject)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if !reflect.DeepEqual(tc.Expected, unsupported) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, unsupported)
+		}
+	}
+
+	changelog := Changelog()
+	if len(changelog) != 2 || len(changelog[0].Features) != 2 || len(changelog[1].Features) != 4 {
+		t.Fatalf("expected %d and %d features got %#v", 2, 4, changelog)
+	}
+}
@@ -1,6 +1,8 @@
 package healthz
 
 import (
+	"time"
+
 	"github.com/giantswarm/k8shealthz"
 	"github.com/giantswarm/microendpoint/service/healthz"
 	"github.com/giantswarm/microerror"
@@ -11,8 +13,19 @@ import (
 // Config represents the configuration used to create a healthz service.
 type Config struct {
 	// Dependencies.
+
+	// Breaker reports the state of the circuit breaker of writes to the
+	// Kubernetes API.
+	Breaker   healthz.Service
 	K8sClient kubernetes.Interface
 	Logger    micrologger.Logger
+
+	// Settings.
+
+	// K8sInterval and K8sTimeout configure the Kubernetes health check, see
+	// K8sConfig.
+	K8sInterval time.Duration
+	K8sTimeout  time.Duration
 }
 
 // DefaultConfig provides a default configuration to create a new healthz
@@ -20,28 +33,42 @@ type Config struct {
 func DefaultConfig() Config {
 	return Config{
 		// Dependencies.
+		Breaker:   nil,
 		K8sClient: nil,
 		Logger:    nil,
+
+		// Settings.
+		K8sInterval: 0,
+		K8sTimeout:  k8shealthz.Timeout,
 	}
 }
 
 // New creates a new configured healthz service.
 func New(config Config) (*Service, error) {
+	if config.Breaker == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Breaker must not be empty", config)
+	}
+
 	var err error
 
 	var k8sService healthz.Service
 	{
-		k8sConfig := k8shealthz.DefaultConfig()
-		k8sConfig.K8sClient = config.K8sClient
-		k8sConfig.Logger = config.Logger
-		k8sService, err = k8shealthz.New(k8sConfig)
+		k8sConfig := K8sConfig{
+			K8sClient: config.K8sClient,
+			Logger:    config.Logger,
+
+			Interval: config.K8sInterval,
+			Timeout:  config.K8sTimeout,
+		}
+		k8sService, err = NewK8s(k8sConfig)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 	}
 
 	newService := &Service{
-		K8s: k8sService,
+		Breaker: config.Breaker,
+		K8s:     k8sService,
 	}
 
 	return newService, nil
@@ -49,5 +76,6 @@ func New(config Config) (*Service, error) {
 
 // Service is the healthz service collection.
 type Service struct {
-	K8s healthz.Service
+	Breaker healthz.Service
+	K8s     healthz.Service
 }
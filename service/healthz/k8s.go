@@ -0,0 +1,128 @@
+package healthz
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/k8shealthz"
+	"github.com/giantswarm/microendpoint/service/healthz"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/client-go/kubernetes"
+)
+
+// K8sConfig represents the configuration used to create a Kubernetes health
+// check.
+type K8sConfig struct {
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	// Interval is the interval in which the connectivity to Kubernetes is
+	// checked. Health checks within the interval report the result of the last
+	// check. Zero checks on every health check.
+	Interval time.Duration
+	// Timeout is the maximum time the connectivity check may take before the
+	// health check fails.
+	Timeout time.Duration
+}
+
+// K8s checks the connectivity to the Kubernetes API like k8shealthz does, but
+// with a configurable timeout and interval, and reports the measured latency,
+// so that probes can be tuned to slow API servers.
+type K8s struct {
+	k8sClient kubernetes.Interface
+	logger    micrologger.Logger
+
+	checked  time.Time
+	mutex    sync.Mutex
+	response healthz.Response
+
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// NewK8s creates a new configured Kubernetes health check.
+func NewK8s(config K8sConfig) (*K8s, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Interval < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must not be negative", config)
+	}
+	if config.Timeout <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Timeout must be greater than 0", config)
+	}
+
+	k := &K8s{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		interval: config.Interval,
+		timeout:  config.Timeout,
+	}
+
+	return k, nil
+}
+
+// GetHealthz implements the health check for Kubernetes. The message of the
+// response contains the latency of the check.
+func (k *K8s) GetHealthz(ctx context.Context) (healthz.Response, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if k.interval > 0 && !k.checked.IsZero() && time.Since(k.checked) < k.interval {
+		return k.response, nil
+	}
+
+	k.response = k.check(ctx)
+	k.checked = time.Now()
+
+	return k.response, nil
+}
+
+func (k *K8s) check(ctx context.Context) healthz.Response {
+	ctx, cancel := context.WithTimeout(ctx, k.timeout)
+	defer cancel()
+
+	start := time.Now()
+	ch := make(chan error, 1)
+	go func() {
+		_, err := k.k8sClient.CoreV1().RESTClient().Get().AbsPath("/").Context(ctx).DoRaw()
+		ch <- err
+	}()
+
+	failed := false
+	var message string
+	select {
+	case err := <-ch:
+		latency := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			failed = true
+			message = fmt.Sprintf("%s (latency %s)", err.Error(), latency)
+		} else {
+			message = fmt.Sprintf("%s (latency %s)", k8shealthz.SuccessMessage, latency)
+		}
+	case <-ctx.Done():
+		failed = true
+		message = fmt.Sprintf("timed out after %s", k.timeout)
+	}
+
+	if failed {
+		k.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("Kubernetes health check failed: %s", message))
+	}
+
+	r := healthz.Response{
+		Description: k8shealthz.Description,
+		Failed:      failed,
+		Message:     message,
+		Name:        k8shealthz.Name,
+	}
+
+	return r
+}
@@ -0,0 +1,86 @@
+package healthz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func newTestK8s(t *testing.T, handler http.HandlerFunc, interval, timeout time.Duration) (*K8s, func()) {
+	server := httptest.NewServer(handler)
+
+	k8sClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	k, err := NewK8s(K8sConfig{
+		K8sClient: k8sClient,
+		Logger:    microloggertest.New(),
+
+		Interval: interval,
+		Timeout:  timeout,
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return k, server.Close
+}
+
+func Test_K8s_GetHealthz(t *testing.T) {
+	var requests int32
+	k, closeServer := newTestK8s(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte("{}"))
+	}, time.Hour, time.Second)
+	defer closeServer()
+
+	r, err := k.GetHealthz(context.TODO())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if r.Failed {
+		t.Fatal("expected", false, "got", true)
+	}
+	if !strings.Contains(r.Message, "latency") {
+		t.Fatal("expected", "latency", "got", r.Message)
+	}
+
+	// The result of the last check is reported within the interval.
+	_, err = k.GetHealthz(context.TODO())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if atomic.LoadInt32(&requests) != 1 {
+		t.Fatal("expected", 1, "got", atomic.LoadInt32(&requests))
+	}
+}
+
+func Test_K8s_GetHealthz_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	k, closeServer := newTestK8s(t, func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}, 0, 50*time.Millisecond)
+	defer closeServer()
+	defer close(done)
+
+	r, err := k.GetHealthz(context.TODO())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !r.Failed {
+		t.Fatal("expected", true, "got", false)
+	}
+	if !strings.Contains(r.Message, "timed out after 50ms") {
+		t.Fatal("expected", "timed out after 50ms", "got", r.Message)
+	}
+}
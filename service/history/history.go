@@ -0,0 +1,119 @@
+// Package history records the outcome of the latest reconciliation loop of
+// every IngressConfig, so that the state of the guest clusters can be
+// inspected through the operator without access to its logs or the host
+// cluster.
+package history
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+// Config represents the configuration used to create a new recorder.
+type Config struct {
+	Logger micrologger.Logger
+}
+
+// Entry is the outcome of the latest reconciliation loop of an IngressConfig.
+type Entry struct {
+	ClusterID string `json:"clusterID"`
+	// Error is the error stopping the latest reconciliation loop in case it
+	// failed.
+	Error string `json:"error,omitempty"`
+	// Failures is the number of consecutive reconciliation loops which failed.
+	Failures     int             `json:"failures"`
+	Name         string          `json:"name"`
+	Namespace    string          `json:"namespace"`
+	Outcome      outcome.Outcome `json:"outcome"`
+	ReconciledAt time.Time       `json:"reconciledAt"`
+}
+
+// Recorder records the outcomes of reconciliation loops. It implements
+// outcome.Observer.
+type Recorder struct {
+	logger micrologger.Logger
+
+	mutex   sync.Mutex
+	entries map[string]Entry
+	now     func() time.Time
+}
+
+// New creates a new configured recorder.
+func New(config Config) (*Recorder, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Recorder{
+		logger: config.Logger,
+
+		entries: map[string]Entry{},
+		now:     time.Now,
+	}
+
+	return r, nil
+}
+
+// List returns the entries of all IngressConfigs reconciled since the operator
+// started, ordered by namespace and name.
+func (r *Recorder) List() []Entry {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	entries := []Entry{}
+	for _, e := range r.entries {
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries
+}
+
+// Observe records the outcome of the reconciliation loop of the given object.
+// The entries of IngressConfigs are removed once their deletion succeeded.
+func (r *Recorder) Observe(ctx context.Context, obj interface{}, o outcome.Outcome, err error) {
+	customObject, convertErr := key.ToCustomObject(obj)
+	if convertErr != nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	k := customObject.Namespace + "/" + customObject.Name
+
+	if customObject.DeletionTimestamp != nil && o == outcome.Succeeded {
+		delete(r.entries, k)
+		return
+	}
+
+	e := Entry{
+		ClusterID:    key.ClusterID(customObject),
+		Name:         customObject.Name,
+		Namespace:    customObject.Namespace,
+		Outcome:      o,
+		ReconciledAt: r.now().UTC(),
+	}
+	if o == outcome.Failed {
+		e.Failures = r.entries[k].Failures + 1
+		if err != nil {
+			e.Error = err.Error()
+		}
+	}
+
+	r.entries[k] = e
+}
@@ -0,0 +1,79 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+func newTestIngressConfig(namespace, name, clusterID string) *v1alpha1.IngressConfig {
+	return &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID: clusterID,
+			},
+		},
+	}
+}
+
+func Test_History_Observe(t *testing.T) {
+	ctx := context.TODO()
+
+	r, err := New(Config{Logger: microloggertest.New()})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	r.now = func() time.Time { return time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	a := newTestIngressConfig("default", "a", "al9qy")
+	b := newTestIngressConfig("default", "b", "x8c2p")
+
+	r.Observe(ctx, b, outcome.Failed, microerror.New("test error"))
+	r.Observe(ctx, a, outcome.Succeeded, nil)
+	r.Observe(ctx, b, outcome.Failed, microerror.New("test error"))
+
+	entries := r.List()
+	if len(entries) != 2 {
+		t.Fatal("expected", 2, "got", len(entries))
+	}
+	if entries[0].Name != "a" || entries[0].ClusterID != "al9qy" || entries[0].Outcome != outcome.Succeeded || entries[0].Error != "" {
+		t.Fatal("expected", "succeeded entry of a", "got", entries[0])
+	}
+	if entries[1].Name != "b" || entries[1].Failures != 2 || entries[1].Error != "test error" {
+		t.Fatal("expected", "two failures of b", "got", entries[1])
+	}
+	if !entries[1].ReconciledAt.Equal(r.now()) {
+		t.Fatal("expected", r.now(), "got", entries[1].ReconciledAt)
+	}
+
+	// Consecutive failures are reset by loops not failing.
+	r.Observe(ctx, b, outcome.Canceled, nil)
+	entries = r.List()
+	if entries[1].Failures != 0 || entries[1].Error != "" {
+		t.Fatal("expected", "no failures of b", "got", entries[1])
+	}
+
+	// Entries are removed once the deletion of the IngressConfig succeeded.
+	deletionTimestamp := metav1.Now()
+	a.DeletionTimestamp = &deletionTimestamp
+	r.Observe(ctx, a, outcome.Failed, microerror.New("test error"))
+	if len(r.List()) != 2 {
+		t.Fatal("expected", 2, "got", len(r.List()))
+	}
+	r.Observe(ctx, a, outcome.Succeeded, nil)
+	entries = r.List()
+	if len(entries) != 1 || entries[0].Name != "b" {
+		t.Fatal("expected", "entry of b only", "got", entries)
+	}
+}
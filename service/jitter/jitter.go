@@ -0,0 +1,71 @@
+// Package jitter provides randomized delays and backoffs. Many reconciliations
+// contending on the same shared host cluster objects, e.g. the ingress
+// controller service, cause write conflicts. Retrying them at the same fixed
+// intervals keeps the writers synchronized. Randomizing delays and retries
+// spreads the writes over time and breaks such thundering herds.
+package jitter
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	gsbackoff "github.com/giantswarm/backoff"
+)
+
+const (
+	// RandomizationFactor is the factor by which the intervals of the backoffs
+	// returned by NewBackOff are randomized.
+	RandomizationFactor = 0.5
+)
+
+var (
+	random      = rand.New(rand.NewSource(time.Now().UnixNano()))
+	randomMutex sync.Mutex
+)
+
+// Duration returns a random duration in the interval [0, max).
+func Duration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+
+	return time.Duration(random.Int63n(int64(max)))
+}
+
+// NewBackOff returns an exponential backoff with randomized intervals,
+// starting at the given initial interval and growing up to the given max
+// interval. The backoff stops after the given number of retries.
+func NewBackOff(maxRetries uint64, initialInterval, maxInterval time.Duration) gsbackoff.Interface {
+	b := &backoff.ExponentialBackOff{
+		InitialInterval:     initialInterval,
+		RandomizationFactor: RandomizationFactor,
+		Multiplier:          backoff.DefaultMultiplier,
+		MaxInterval:         maxInterval,
+		MaxElapsedTime:      0,
+		Clock:               backoff.SystemClock,
+	}
+
+	b.Reset()
+
+	return backoff.WithMaxRetries(b, maxRetries)
+}
+
+// Sleep blocks for a random duration in the interval [0, max) or until the
+// given context is done.
+func Sleep(ctx context.Context, max time.Duration) {
+	d := Duration(max)
+	if d == 0 {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
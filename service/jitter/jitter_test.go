@@ -0,0 +1,63 @@
+package jitter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff"
+)
+
+func Test_Jitter_Duration(t *testing.T) {
+	if Duration(0) != 0 {
+		t.Fatal("expected", 0, "got", Duration(0))
+	}
+
+	for i := 0; i < 100; i++ {
+		d := Duration(time.Second)
+		if d < 0 || d >= time.Second {
+			t.Fatal("test", i, "expected", "duration in [0, 1s)", "got", d)
+		}
+	}
+}
+
+func Test_Jitter_NewBackOff(t *testing.T) {
+	b := NewBackOff(3, 100*time.Millisecond, time.Second)
+
+	for i := 0; i < 3; i++ {
+		d := b.NextBackOff()
+		if d == backoff.Stop {
+			t.Fatal("test", i, "expected", "backoff", "got", "stop")
+		}
+		if d > time.Second+time.Second/2 {
+			t.Fatal("test", i, "expected", "backoff below max interval", "got", d)
+		}
+	}
+
+	if b.NextBackOff() != backoff.Stop {
+		t.Fatal("expected", "stop", "got", "backoff")
+	}
+
+	b.Reset()
+
+	if b.NextBackOff() == backoff.Stop {
+		t.Fatal("expected", "backoff", "got", "stop")
+	}
+}
+
+func Test_Jitter_Sleep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		Sleep(ctx, time.Hour)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected", "sleep to return on done context", "got", "timeout")
+	}
+}
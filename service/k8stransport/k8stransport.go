@@ -0,0 +1,88 @@
+// Package k8stransport configures the timeouts and keep-alive of the HTTP
+// transport client-go uses to talk to the Kubernetes API. The client-go
+// defaults let requests hang for a long time during outages of the host
+// cluster API, which blocks the serialized reconciliation of IngressConfigs.
+// The requests sent through the transport are counted, so that the API call
+// volume of the operator can be measured, e.g. by the load test.
+package k8stransport
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"k8s.io/client-go/rest"
+)
+
+// Config represents the timeouts applied to a rest config.
+type Config struct {
+	// DialTimeout is the maximum time establishing a TCP connection may take.
+	DialTimeout time.Duration
+	// KeepAlive is the interval of TCP keep-alive probes of established
+	// connections. Dead connections are detected after a few intervals.
+	KeepAlive time.Duration
+	// RequestTimeout is the maximum time a single request may take, including
+	// reading the response body.
+	RequestTimeout time.Duration
+	// TLSHandshakeTimeout is the maximum time the TLS handshake may take.
+	TLSHandshakeTimeout time.Duration
+}
+
+// Apply configures the given rest config to use the given timeouts. Clients
+// created from the rest config afterwards use them.
+func Apply(restConfig *rest.Config, config Config) error {
+	if restConfig == nil {
+		return microerror.Maskf(invalidConfigError, "restConfig must not be empty")
+	}
+	if config.DialTimeout <= 0 {
+		return microerror.Maskf(invalidConfigError, "%T.DialTimeout must be greater than 0", config)
+	}
+	if config.KeepAlive < 0 {
+		return microerror.Maskf(invalidConfigError, "%T.KeepAlive must not be negative", config)
+	}
+	if config.RequestTimeout <= 0 {
+		return microerror.Maskf(invalidConfigError, "%T.RequestTimeout must be greater than 0", config)
+	}
+	if config.TLSHandshakeTimeout <= 0 {
+		return microerror.Maskf(invalidConfigError, "%T.TLSHandshakeTimeout must be greater than 0", config)
+	}
+
+	restConfig.Timeout = config.RequestTimeout
+
+	restConfig.Dial = (&net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: config.KeepAlive,
+	}).Dial
+
+	// client-go does not expose the TLS handshake timeout of the transports it
+	// creates, so it is set on the transport when it gets wrapped. Transports
+	// which are not plain HTTP transports, e.g. ones of authentication
+	// plugins, are left alone.
+	wrapTransport := restConfig.WrapTransport
+	restConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		t, ok := rt.(*http.Transport)
+		if ok {
+			t.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+		}
+
+		if wrapTransport != nil {
+			rt = wrapTransport(rt)
+		}
+
+		return countingRoundTripper{next: rt}
+	}
+
+	return nil
+}
+
+// countingRoundTripper counts the requests passed to the next round tripper.
+type countingRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (c countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestsCounter.WithLabelValues(req.Method).Inc()
+
+	return c.next.RoundTrip(req)
+}
@@ -0,0 +1,78 @@
+package k8stransport
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func Test_K8sTransport_Apply(t *testing.T) {
+	restConfig := &rest.Config{
+		Host: "https://127.0.0.1:6443",
+	}
+
+	var wrapped bool
+	restConfig.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		wrapped = true
+		return rt
+	}
+
+	c := Config{
+		DialTimeout:         5 * time.Second,
+		KeepAlive:           15 * time.Second,
+		RequestTimeout:      20 * time.Second,
+		TLSHandshakeTimeout: 3 * time.Second,
+	}
+
+	err := Apply(restConfig, c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	if restConfig.Timeout != 20*time.Second {
+		t.Fatal("expected", 20*time.Second, "got", restConfig.Timeout)
+	}
+	if restConfig.Dial == nil {
+		t.Fatal("expected", "dial function", "got", nil)
+	}
+
+	transport := &http.Transport{}
+	restConfig.WrapTransport(transport)
+
+	if transport.TLSHandshakeTimeout != 3*time.Second {
+		t.Fatal("expected", 3*time.Second, "got", transport.TLSHandshakeTimeout)
+	}
+	if !wrapped {
+		t.Fatal("expected", "existing transport wrapper to be called", "got", nil)
+	}
+
+	// The timeouts must be effective for the transports client-go creates.
+	rt, err := rest.TransportFor(restConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	// The requests are counted by the outermost round tripper.
+	counting, ok := rt.(countingRoundTripper)
+	if !ok {
+		t.Fatalf("expected %T got %T", countingRoundTripper{}, rt)
+	}
+	if counting.next.(*http.Transport).TLSHandshakeTimeout != 3*time.Second {
+		t.Fatal("expected", 3*time.Second, "got", counting.next.(*http.Transport).TLSHandshakeTimeout)
+	}
+}
+
+func Test_K8sTransport_Apply_invalidConfig(t *testing.T) {
+	c := Config{
+		DialTimeout:         0,
+		KeepAlive:           15 * time.Second,
+		RequestTimeout:      20 * time.Second,
+		TLSHandshakeTimeout: 3 * time.Second,
+	}
+
+	err := Apply(&rest.Config{}, c)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
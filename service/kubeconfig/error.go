@@ -0,0 +1,23 @@
+package kubeconfig
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var invalidKubeConfigError = &microerror.Error{
+	Kind: "invalidKubeConfigError",
+}
+
+// IsInvalidKubeConfig asserts invalidKubeConfigError.
+func IsInvalidKubeConfig(err error) bool {
+	return microerror.Cause(err) == invalidKubeConfigError
+}
@@ -0,0 +1,207 @@
+// Package kubeconfig creates client-go rest configs from kubeconfig files.
+// This enables running the operator out of cluster against control planes
+// which are only accessible using a kubeconfig, e.g. managed control planes
+// authenticating clients via exec credential plugins.
+package kubeconfig
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/giantswarm/microerror"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Config represents the configuration used to create a rest config from a
+// kubeconfig file.
+type Config struct {
+	// Context is the name of the kubeconfig context to use. The current context
+	// of the kubeconfig is used when empty.
+	Context string
+	// Path is the path of the kubeconfig file.
+	Path string
+}
+
+type kubeConfig struct {
+	Clusters       []namedCluster `json:"clusters"`
+	Contexts       []namedContext `json:"contexts"`
+	CurrentContext string         `json:"current-context"`
+	Users          []namedUser    `json:"users"`
+}
+
+type namedCluster struct {
+	Name    string  `json:"name"`
+	Cluster cluster `json:"cluster"`
+}
+
+type cluster struct {
+	CertificateAuthority     string `json:"certificate-authority"`
+	CertificateAuthorityData string `json:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+	Server                   string `json:"server"`
+}
+
+type namedContext struct {
+	Name    string      `json:"name"`
+	Context kubeContext `json:"context"`
+}
+
+type kubeContext struct {
+	Cluster string `json:"cluster"`
+	User    string `json:"user"`
+}
+
+type namedUser struct {
+	Name string `json:"name"`
+	User user   `json:"user"`
+}
+
+type user struct {
+	ClientCertificate     string                   `json:"client-certificate"`
+	ClientCertificateData string                   `json:"client-certificate-data"`
+	ClientKey             string                   `json:"client-key"`
+	ClientKeyData         string                   `json:"client-key-data"`
+	Exec                  *clientcmdapi.ExecConfig `json:"exec"`
+	Password              string                   `json:"password"`
+	Token                 string                   `json:"token"`
+	TokenFile             string                   `json:"tokenFile"`
+	Username              string                   `json:"username"`
+}
+
+// New returns a rest config based on the given kubeconfig file. Relative file
+// paths within the kubeconfig are resolved relative to the kubeconfig file,
+// the same way kubectl does.
+func New(config Config) (*rest.Config, error) {
+	if config.Path == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Path must not be empty", config)
+	}
+
+	b, err := ioutil.ReadFile(config.Path)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var k kubeConfig
+	err = yaml.Unmarshal(b, &k)
+	if err != nil {
+		return nil, microerror.Maskf(invalidKubeConfigError, "%s", err.Error())
+	}
+
+	name := config.Context
+	if name == "" {
+		name = k.CurrentContext
+	}
+	if name == "" {
+		return nil, microerror.Maskf(invalidKubeConfigError, "no context given and no current context set")
+	}
+
+	var ctx *kubeContext
+	for _, c := range k.Contexts {
+		if c.Name == name {
+			ctx = &c.Context
+			break
+		}
+	}
+	if ctx == nil {
+		return nil, microerror.Maskf(invalidKubeConfigError, "context %q not found", name)
+	}
+
+	var cl *cluster
+	for _, c := range k.Clusters {
+		if c.Name == ctx.Cluster {
+			cl = &c.Cluster
+			break
+		}
+	}
+	if cl == nil {
+		return nil, microerror.Maskf(invalidKubeConfigError, "cluster %q of context %q not found", ctx.Cluster, name)
+	}
+
+	var u user
+	for _, c := range k.Users {
+		if c.Name == ctx.User {
+			u = c.User
+			break
+		}
+	}
+
+	dir := filepath.Dir(config.Path)
+
+	restConfig := &rest.Config{
+		Host: cl.Server,
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile:   resolve(dir, cl.CertificateAuthority),
+			CertFile: resolve(dir, u.ClientCertificate),
+			Insecure: cl.InsecureSkipTLSVerify,
+			KeyFile:  resolve(dir, u.ClientKey),
+		},
+
+		BearerToken:  u.Token,
+		ExecProvider: u.Exec,
+		Password:     u.Password,
+		Username:     u.Username,
+	}
+
+	restConfig.CAData, err = decode("certificate-authority-data", cl.CertificateAuthorityData)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	restConfig.CertData, err = decode("client-certificate-data", u.ClientCertificateData)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	restConfig.KeyData, err = decode("client-key-data", u.ClientKeyData)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if restConfig.BearerToken == "" && u.TokenFile != "" {
+		restConfig.BearerToken, err = ReadToken(resolve(dir, u.TokenFile))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return restConfig, nil
+}
+
+// ReadToken returns the bearer token stored in the given file, e.g. a
+// ServiceAccount token file.
+func ReadToken(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", microerror.Maskf(invalidKubeConfigError, "token file %q must not be empty", path)
+	}
+
+	return token, nil
+}
+
+func decode(field, data string) ([]byte, error) {
+	if data == "" {
+		return nil, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, microerror.Maskf(invalidKubeConfigError, "%s must be base64 encoded", field)
+	}
+
+	return b, nil
+}
+
+func resolve(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,110 @@
+package kubeconfig
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: ci
+clusters:
+- name: ci
+  cluster:
+    server: https://api.ci.example.com
+    certificate-authority-data: ` + "Y2EK" + `
+- name: dev
+  cluster:
+    server: https://api.dev.example.com
+    certificate-authority: ca.pem
+contexts:
+- name: ci
+  context:
+    cluster: ci
+    user: exec
+- name: dev
+  context:
+    cluster: dev
+    user: token
+users:
+- name: exec
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1alpha1
+      command: aws-iam-authenticator
+      args:
+      - token
+      - -i
+      - ci
+      env:
+      - name: AWS_PROFILE
+        value: ci
+- name: token
+  user:
+    tokenFile: token
+`
+
+func Test_KubeConfig_New(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config")
+	err = ioutil.WriteFile(path, []byte(testKubeConfig), 0600)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "token"), []byte("secret\n"), 0600)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	{
+		restConfig, err := New(Config{Path: path})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if restConfig.Host != "https://api.ci.example.com" {
+			t.Fatal("expected", "https://api.ci.example.com", "got", restConfig.Host)
+		}
+		ca, _ := base64.StdEncoding.DecodeString("Y2EK")
+		if !reflect.DeepEqual(restConfig.CAData, ca) {
+			t.Fatal("expected", ca, "got", restConfig.CAData)
+		}
+		if restConfig.ExecProvider == nil {
+			t.Fatal("expected", "exec provider", "got", nil)
+		}
+		if restConfig.ExecProvider.Command != "aws-iam-authenticator" {
+			t.Fatal("expected", "aws-iam-authenticator", "got", restConfig.ExecProvider.Command)
+		}
+		if len(restConfig.ExecProvider.Env) != 1 || restConfig.ExecProvider.Env[0].Value != "ci" {
+			t.Fatalf("expected %#v got %#v", "AWS_PROFILE=ci", restConfig.ExecProvider.Env)
+		}
+	}
+
+	{
+		restConfig, err := New(Config{Context: "dev", Path: path})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if restConfig.CAFile != filepath.Join(dir, "ca.pem") {
+			t.Fatal("expected", filepath.Join(dir, "ca.pem"), "got", restConfig.CAFile)
+		}
+		if restConfig.BearerToken != "secret" {
+			t.Fatal("expected", "secret", "got", restConfig.BearerToken)
+		}
+	}
+
+	{
+		_, err := New(Config{Context: "prod", Path: path})
+		if !IsInvalidKubeConfig(err) {
+			t.Fatal("expected", true, "got", false)
+		}
+	}
+}
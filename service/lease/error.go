@@ -0,0 +1,32 @@
+package lease
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var lockTimeoutError = &microerror.Error{
+	Kind: "lockTimeoutError",
+}
+
+// IsLockTimeout asserts lockTimeoutError.
+func IsLockTimeout(err error) bool {
+	return microerror.Cause(err) == lockTimeoutError
+}
+
+var lostLeaseError = &microerror.Error{
+	Kind: "lostLeaseError",
+}
+
+// IsLostLease asserts lostLeaseError.
+func IsLostLease(err error) bool {
+	return microerror.Cause(err) == lostLeaseError
+}
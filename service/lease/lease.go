@@ -0,0 +1,377 @@
+// Package lease implements locks shared by all replicas of the operator based
+// on Lease objects. A mutex of the process only serializes the workers of a
+// single replica, while multiple replicas, e.g. during rolling upgrades or
+// when sharding IngressConfigs, still write the same objects concurrently.
+// Holding the Lease of an object serializes its writes across replicas. Within
+// a replica the workers are serialized by a semaphore per Lease in addition,
+// since they share the holder identity of the replica.
+package lease
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+// Config represents the configuration used to create a new locker.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Coordination
+	Logger    micrologger.Logger
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+
+	// Settings.
+
+	// Duration is the time a Lease is valid without being renewed. Leases of
+	// crashed replicas are taken over once it passed. Held Leases are renewed
+	// in a third of it.
+	Duration time.Duration
+	// Identity is the holder identity of the replica, e.g. its pod name.
+	Identity string
+	// RetryInterval is the interval in which Leases held by other replicas are
+	// checked again.
+	RetryInterval time.Duration
+	// Timeout is the maximum time acquiring a Lease takes.
+	Timeout time.Duration
+}
+
+// DefaultConfig provides a default configuration to create a new locker by
+// best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+		Now:       time.Now,
+
+		// Settings.
+		Duration:      15 * time.Second,
+		Identity:      "",
+		RetryInterval: time.Second,
+		Timeout:       30 * time.Second,
+	}
+}
+
+// Locker acquires and releases Leases.
+type Locker struct {
+	// Dependencies.
+	k8sClient clients.Coordination
+	logger    micrologger.Logger
+	now       func() time.Time
+
+	// Internals.
+	mutex sync.Mutex
+	// semaphores serialize the workers of this replica acquiring the same
+	// Lease. They are channels, so that waiting for them can be canceled.
+	semaphores map[string]chan struct{}
+
+	// Settings.
+	duration      time.Duration
+	identity      string
+	retryInterval time.Duration
+	timeout       time.Duration
+}
+
+// New creates a new configured locker.
+func New(config Config) (*Locker, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	// Settings.
+	if config.Duration < time.Second {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Duration must be at least 1s", config)
+	}
+	if config.Identity == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Identity must not be empty", config)
+	}
+	if config.RetryInterval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.RetryInterval must be greater than 0", config)
+	}
+	if config.Timeout <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Timeout must be greater than 0", config)
+	}
+
+	l := &Locker{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+		now:       config.Now,
+
+		// Internals.
+		mutex:      sync.Mutex{},
+		semaphores: map[string]chan struct{}{},
+
+		// Settings.
+		duration:      config.Duration,
+		identity:      config.Identity,
+		retryInterval: config.RetryInterval,
+		timeout:       config.Timeout,
+	}
+
+	return l, nil
+}
+
+// Lock acquires the Lease of the given name in the given namespace, which is
+// created in case it does not exist. It waits for Leases held by other
+// replicas or other workers of this replica to be released or to expire, and
+// fails with a lock timeout error in case this takes longer than the timeout. The Lease is renewed in the
+// background until the returned function releases it. The returned context is
+// derived from the given one and canceled once the Lease is lost, so that
+// writes relying on it stop before another replica takes it over.
+func (l *Locker) Lock(ctx context.Context, namespace, name string) (context.Context, func(), error) {
+	deadline := l.now().Add(l.timeout)
+
+	sem := l.semaphore(namespace, name)
+	select {
+	case <-ctx.Done():
+		return nil, nil, microerror.Mask(ctx.Err())
+	case <-time.After(l.timeout):
+		return nil, nil, microerror.Maskf(lockTimeoutError, "lease %s/%s is held by another worker of %s", namespace, name, l.identity)
+	case sem <- struct{}{}:
+	}
+
+	for {
+		holder, err := l.tryAcquire(ctx, namespace, name)
+		if err != nil {
+			<-sem
+			return nil, nil, microerror.Mask(err)
+		}
+		if holder == "" {
+			break
+		}
+
+		if !l.now().Before(deadline) {
+			<-sem
+			return nil, nil, microerror.Maskf(lockTimeoutError, "lease %s/%s is held by %s", namespace, name, holder)
+		}
+
+		l.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("waiting for lease %s/%s held by %s", namespace, name, holder))
+
+		select {
+		case <-ctx.Done():
+			<-sem
+			return nil, nil, microerror.Mask(ctx.Err())
+		case <-time.After(l.retryInterval):
+		}
+	}
+
+	leaseCtx, cancel := context.WithCancel(ctx)
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go l.renew(ctx, namespace, name, cancel, stop, stopped)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			close(stop)
+			<-stopped
+			cancel()
+
+			err := l.release(ctx, namespace, name)
+			if err != nil {
+				l.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("failed releasing lease %s/%s, it expires in %s", namespace, name, l.duration), "stack", fmt.Sprintf("%#v", err))
+			}
+
+			<-sem
+		})
+	}
+
+	return leaseCtx, release, nil
+}
+
+// semaphore returns the semaphore serializing the workers of this replica
+// acquiring the given Lease. It is held while a value is sent to it.
+func (l *Locker) semaphore(namespace, name string) chan struct{} {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	k := namespace + "/" + name
+	sem, ok := l.semaphores[k]
+	if !ok {
+		sem = make(chan struct{}, 1)
+		l.semaphores[k] = sem
+	}
+
+	return sem
+}
+
+// tryAcquire acquires the given Lease in case it is free, expired or held by
+// this replica already. It returns the holder of the Lease in case it is held
+// by another replica, and an empty string in case it got acquired. Conflicting
+// writes of other replicas acquiring the Lease at the same time are reported
+// like a held Lease, so that they are retried.
+func (l *Locker) tryAcquire(ctx context.Context, namespace, name string) (string, error) {
+	t := l.now()
+
+	current, err := l.k8sClient.GetLease(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		lease := &unstructured.Unstructured{}
+		lease.SetAPIVersion(clients.CoordinationGroupVersion.String())
+		lease.SetKind("Lease")
+		lease.SetName(name)
+		lease.SetNamespace(namespace)
+		lease.Object["spec"] = l.newSpec(t, t, 0)
+
+		_, err = l.k8sClient.CreateLease(ctx, namespace, lease)
+		if clients.IsAlreadyExists(err) {
+			return "another replica", nil
+		} else if err != nil {
+			return "", microerror.Mask(err)
+		}
+
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	holder, _, _ := unstructured.NestedString(current.Object, "spec", "holderIdentity")
+	transitions, _, _ := unstructured.NestedInt64(current.Object, "spec", "leaseTransitions")
+	acquireTime := t
+
+	switch {
+	case holder == l.identity:
+		// The Lease is still held by this replica, e.g. because releasing it
+		// failed before, so it is renewed.
+		if a := parseTime(current, "acquireTime"); !a.IsZero() {
+			acquireTime = a
+		}
+	case holder != "" && !isExpired(current, t):
+		return holder, nil
+	case holder != "":
+		transitions++
+	}
+
+	current.Object["spec"] = l.newSpec(acquireTime, t, transitions)
+
+	_, err = l.k8sClient.UpdateLease(ctx, namespace, current)
+	if clients.IsConflict(err) {
+		return "another replica", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return "", nil
+}
+
+// renew renews the given Lease in a third of its duration until stop is
+// closed. Failed renewals are retried, since the Lease stays valid until its
+// duration passed. The Lease is considered lost in case another replica holds
+// it or it was not renewed within its duration, and cancel is called.
+func (l *Locker) renew(ctx context.Context, namespace, name string, cancel func(), stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+
+	ticker := time.NewTicker(l.duration / 3)
+	defer ticker.Stop()
+
+	renewed := l.now()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		t := l.now()
+		err := l.update(ctx, namespace, name, func(current *unstructured.Unstructured) {
+			unstructured.SetNestedField(current.Object, t.UTC().Format(metav1.RFC3339Micro), "spec", "renewTime")
+		})
+		if IsLostLease(err) || (err != nil && !t.Before(renewed.Add(l.duration))) {
+			l.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("lost lease %s/%s, canceling writes relying on it", namespace, name), "stack", fmt.Sprintf("%#v", err))
+			cancel()
+			return
+		} else if err != nil {
+			l.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("failed renewing lease %s/%s", namespace, name), "stack", fmt.Sprintf("%#v", err))
+			continue
+		}
+
+		renewed = t
+	}
+}
+
+// release clears the holder of the given Lease, so that other replicas can
+// acquire it right away.
+func (l *Locker) release(ctx context.Context, namespace, name string) error {
+	err := l.update(ctx, namespace, name, func(current *unstructured.Unstructured) {
+		unstructured.SetNestedField(current.Object, "", "spec", "holderIdentity")
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// update applies the given modification to the given Lease in case it is
+// still held by this replica.
+func (l *Locker) update(ctx context.Context, namespace, name string, modify func(current *unstructured.Unstructured)) error {
+	current, err := l.k8sClient.GetLease(ctx, namespace, name)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	holder, _, _ := unstructured.NestedString(current.Object, "spec", "holderIdentity")
+	if holder != l.identity {
+		return microerror.Maskf(lostLeaseError, "lease %s/%s is held by %s", namespace, name, holder)
+	}
+
+	modify(current)
+
+	_, err = l.k8sClient.UpdateLease(ctx, namespace, current)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (l *Locker) newSpec(acquireTime, renewTime time.Time, transitions int64) map[string]interface{} {
+	return map[string]interface{}{
+		"acquireTime":          acquireTime.UTC().Format(metav1.RFC3339Micro),
+		"holderIdentity":       l.identity,
+		"leaseDurationSeconds": int64(l.duration / time.Second),
+		"leaseTransitions":     transitions,
+		"renewTime":            renewTime.UTC().Format(metav1.RFC3339Micro),
+	}
+}
+
+// isExpired returns whether the given Lease has not been renewed within its
+// duration. Leases lacking a valid renew time are expired.
+func isExpired(lease *unstructured.Unstructured, t time.Time) bool {
+	renewTime := parseTime(lease, "renewTime")
+	if renewTime.IsZero() {
+		return true
+	}
+
+	seconds, _, _ := unstructured.NestedInt64(lease.Object, "spec", "leaseDurationSeconds")
+
+	return !t.Before(renewTime.Add(time.Duration(seconds) * time.Second))
+}
+
+func parseTime(lease *unstructured.Unstructured, field string) time.Time {
+	s, _, _ := unstructured.NestedString(lease.Object, "spec", field)
+
+	t, err := time.Parse(metav1.RFC3339Micro, s)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return t
+}
@@ -0,0 +1,279 @@
+package lease
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// testCoordination is an in-memory implementation of clients.Coordination
+// shared by the lockers of multiple replicas.
+type testCoordination struct {
+	mutex  sync.Mutex
+	leases map[string]*unstructured.Unstructured
+}
+
+func newTestCoordination() *testCoordination {
+	return &testCoordination{
+		leases: map[string]*unstructured.Unstructured{},
+	}
+}
+
+func (c *testCoordination) CreateLease(ctx context.Context, namespace string, lease *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	k := namespace + "/" + lease.GetName()
+	if _, ok := c.leases[k]; ok {
+		return nil, errors.NewAlreadyExists(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, lease.GetName())
+	}
+	c.leases[k] = lease.DeepCopy()
+
+	return lease, nil
+}
+
+func (c *testCoordination) GetLease(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	lease, ok := c.leases[namespace+"/"+name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "coordination.k8s.io", Resource: "leases"}, name)
+	}
+
+	return lease.DeepCopy(), nil
+}
+
+func (c *testCoordination) UpdateLease(ctx context.Context, namespace string, lease *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.leases[namespace+"/"+lease.GetName()] = lease.DeepCopy()
+
+	return lease, nil
+}
+
+func (c *testCoordination) holder(namespace, name string) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	holder, _, _ := unstructured.NestedString(c.leases[namespace+"/"+name].Object, "spec", "holderIdentity")
+
+	return holder
+}
+
+func newTestLocker(t *testing.T, k8sClient *testCoordination, identity string) *Locker {
+	return newTestLockerWithClock(t, k8sClient, identity, time.Now)
+}
+
+func newTestLockerWithClock(t *testing.T, k8sClient *testCoordination, identity string, now func() time.Time) *Locker {
+	c := DefaultConfig()
+
+	c.K8sClient = k8sClient
+	c.Logger = microloggertest.New()
+	c.Now = now
+
+	c.Duration = 3 * time.Second
+	c.Identity = identity
+	c.RetryInterval = 10 * time.Millisecond
+	c.Timeout = 100 * time.Millisecond
+
+	l, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return l
+}
+
+func Test_Locker_Lock(t *testing.T) {
+	ctx := context.TODO()
+	k8sClient := newTestCoordination()
+
+	a := newTestLocker(t, k8sClient, "ingress-operator-0")
+	b := newTestLocker(t, k8sClient, "ingress-operator-1")
+
+	_, release, err := a.Lock(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if k8sClient.holder("kube-system", "ingress-operator-ingress-controller") != "ingress-operator-0" {
+		t.Fatal("expected", "ingress-operator-0", "got", k8sClient.holder("kube-system", "ingress-operator-ingress-controller"))
+	}
+
+	// Another replica cannot acquire the Lease while it is held.
+	_, _, err = b.Lock(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if !IsLockTimeout(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// Leases of other ingress controllers are independent.
+	_, releaseOther, err := b.Lock(ctx, "kube-system", "ingress-operator-other-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	releaseOther()
+
+	release()
+	if k8sClient.holder("kube-system", "ingress-operator-ingress-controller") != "" {
+		t.Fatal("expected", "", "got", k8sClient.holder("kube-system", "ingress-operator-ingress-controller"))
+	}
+
+	// The released Lease is acquired by the other replica right away.
+	_, release, err = b.Lock(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if k8sClient.holder("kube-system", "ingress-operator-ingress-controller") != "ingress-operator-1" {
+		t.Fatal("expected", "ingress-operator-1", "got", k8sClient.holder("kube-system", "ingress-operator-ingress-controller"))
+	}
+	release()
+}
+
+func Test_Locker_Lock_expired(t *testing.T) {
+	ctx := context.TODO()
+	k8sClient := newTestCoordination()
+
+	// The other replica's clock is ahead by more than the duration of the
+	// Lease, as if the Lease was not renewed since.
+	a := newTestLocker(t, k8sClient, "ingress-operator-0")
+	b := newTestLockerWithClock(t, k8sClient, "ingress-operator-1", func() time.Time { return time.Now().Add(time.Minute) })
+
+	// The replica holding the Lease crashes without releasing it.
+	_, crashed, err := a.Lock(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer crashed()
+
+	_, release, err := b.Lock(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if k8sClient.holder("kube-system", "ingress-operator-ingress-controller") != "ingress-operator-1" {
+		t.Fatal("expected", "ingress-operator-1", "got", k8sClient.holder("kube-system", "ingress-operator-ingress-controller"))
+	}
+
+	lease, err := k8sClient.GetLease(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	transitions, _, _ := unstructured.NestedInt64(lease.Object, "spec", "leaseTransitions")
+	if transitions != 1 {
+		t.Fatal("expected", 1, "got", transitions)
+	}
+
+	release()
+}
+
+func Test_Locker_Lock_process(t *testing.T) {
+	ctx := context.TODO()
+	k8sClient := newTestCoordination()
+
+	l := newTestLocker(t, k8sClient, "ingress-operator-0")
+
+	_, release, err := l.Lock(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Workers of the same replica share its identity, so they are serialized
+	// within the process.
+	acquired := make(chan struct{})
+	go func() {
+		_, r, err := l.Lock(ctx, "kube-system", "ingress-operator-ingress-controller")
+		if err == nil {
+			r()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected", "second worker to wait", "got", "lease")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected", "lease", "got", "timeout")
+	}
+}
+
+func Test_Locker_Lock_processCanceled(t *testing.T) {
+	k8sClient := newTestCoordination()
+
+	l := newTestLocker(t, k8sClient, "ingress-operator-0")
+
+	_, release, err := l.Lock(context.TODO(), "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer release()
+
+	// A worker waiting for another worker of the same replica stops waiting
+	// once its context is canceled.
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	_, _, err = l.Lock(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if microerror.Cause(err) != context.Canceled {
+		t.Fatal("expected", context.Canceled, "got", err)
+	}
+
+	// It also stops waiting once the timeout passed.
+	_, _, err = l.Lock(context.TODO(), "kube-system", "ingress-operator-ingress-controller")
+	if !IsLockTimeout(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Locker_Lock_lost(t *testing.T) {
+	ctx := context.TODO()
+	k8sClient := newTestCoordination()
+
+	c := DefaultConfig()
+	c.K8sClient = k8sClient
+	c.Logger = microloggertest.New()
+	c.Duration = time.Second
+	c.Identity = "ingress-operator-0"
+
+	l, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	leaseCtx, release, err := l.Lock(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer release()
+
+	// Another replica takes the Lease over, e.g. because renewing it failed
+	// for longer than its duration.
+	lease, err := k8sClient.GetLease(ctx, "kube-system", "ingress-operator-ingress-controller")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	unstructured.SetNestedField(lease.Object, "ingress-operator-1", "spec", "holderIdentity")
+	_, err = k8sClient.UpdateLease(ctx, "kube-system", lease)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	select {
+	case <-leaseCtx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected", "canceled context", "got", "timeout")
+	}
+}
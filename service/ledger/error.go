@@ -0,0 +1,50 @@
+package ledger
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var allocationConflictError = &microerror.Error{
+	Kind: "allocationConflictError",
+}
+
+// IsAllocationConflict asserts allocationConflictError.
+func IsAllocationConflict(err error) bool {
+	return microerror.Cause(err) == allocationConflictError
+}
+
+var coolingOffError = &microerror.Error{
+	Kind: "coolingOffError",
+}
+
+// IsCoolingOff asserts coolingOffError.
+func IsCoolingOff(err error) bool {
+	return microerror.Cause(err) == coolingOffError
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var tooManyConflictsError = &microerror.Error{
+	Kind: "tooManyConflictsError",
+}
+
+// IsTooManyConflicts asserts tooManyConflictsError.
+func IsTooManyConflicts(err error) bool {
+	return microerror.Cause(err) == tooManyConflictsError
+}
+
+var versionSkewError = &microerror.Error{
+	Kind: "versionSkewError",
+}
+
+// IsVersionSkew asserts versionSkewError.
+func IsVersionSkew(err error) bool {
+	return microerror.Cause(err) == versionSkewError
+}
@@ -0,0 +1,594 @@
+// Package ledger implements the durable record of which LB ports are allocated
+// to which guest clusters. The ledger is kept in a dedicated config map in the
+// host cluster, independent of the config maps and services of the ingress
+// controllers. Every change to the ledger is done in a single optimistically
+// locked update of the config map, so concurrent writers never lose
+// allocations. LB ports released by guest clusters stay in the ledger during
+// the cooling-off period, so that they are not reassigned to another guest
+// cluster while clients may still connect to them.
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/jitter"
+)
+
+const (
+	// AdoptedAnnotation is the annotation of the ledger config map recording
+	// that the legacy entries of the ingress controller config maps got
+	// adopted, so that the adoption only happens once.
+	AdoptedAnnotation = "ingress-operator.giantswarm.io/adopted"
+	// VersionAnnotation is the annotation of the ledger config map recording
+	// the version of the operator which wrote the ledger last. Operators of
+	// older versions refuse to overwrite it, since they might drop information
+	// they do not know about during rolling upgrades.
+	VersionAnnotation = "ingress-operator.giantswarm.io/version"
+	// conflictJitter is the maximum of the randomized delay before retrying a
+	// conflicting write.
+	conflictJitter = 500 * time.Millisecond
+	// maxAttempts is the number of attempts to write the ledger in case of
+	// conflicting writes.
+	maxAttempts = 5
+)
+
+var (
+	// now returns the current time. It is replaced in tests.
+	now = time.Now
+)
+
+// Allocation is a single LB port allocated to a guest cluster.
+type Allocation struct {
+	// AllocatedAt is the time the LB port got allocated to the guest cluster.
+	// It is set by the ledger. Allocations recorded before allocation times
+	// were tracked get the time they are recorded next.
+	AllocatedAt time.Time `json:"allocatedAt"`
+	ClusterID   string    `json:"clusterID"`
+	// External is whether the LB port is managed by an external system on
+	// behalf of the guest cluster. External allocations have neither ingress
+	// port nor protocol.
+	External bool `json:"external"`
+	// IngressConfig is the IngressConfig requesting the LB port, in the format
+	// namespace/name, since IngressConfigs may live in any namespace. It is
+	// empty for allocations which are not recorded by an IngressConfig, e.g.
+	// adopted ones.
+	IngressConfig string `json:"ingressConfig,omitempty"`
+	IngressPort   int    `json:"ingressPort"`
+	// Labels are the labels propagated from the IngressConfig, e.g. the
+	// environment or customer of the guest cluster.
+	Labels map[string]string `json:"labels,omitempty"`
+	LBPort int               `json:"lbPort"`
+	// Legacy is whether the LB port is requested by a legacy ingresstpr object.
+	// Legacy allocations are never recorded in the ledger, see the legacy
+	// package.
+	Legacy   bool   `json:"legacy,omitempty"`
+	Pinned   bool   `json:"pinned"`
+	Protocol string `json:"protocol"`
+	// ReleasedAt is the time the guest cluster released the LB port. Released
+	// allocations are kept until the cooling-off period passed. They are not
+	// listed, but keep the LB port from being allocated to another guest
+	// cluster.
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+}
+
+// Config represents the configuration used to create a new ledger.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// AllowDowngrade allows overwriting the ledger in case it got written by a
+	// newer operator version, e.g. after rolling back an upgrade.
+	AllowDowngrade bool
+	// CoolingOff is the time released LB ports cannot be allocated to another
+	// guest cluster, see the allocator package. Zero removes released LB ports
+	// from the ledger right away.
+	CoolingOff time.Duration
+	// Name is the name of the config map holding the ledger.
+	Name string
+	// Namespace is the namespace of the config map holding the ledger.
+	Namespace string
+	// Version is the version of the operator. It is recorded on every write
+	// of the ledger. Version skew is not detected in case it is empty.
+	Version string
+}
+
+// Ledger implements the allocation ledger.
+type Ledger struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	allowDowngrade bool
+	coolingOff     time.Duration
+	name           string
+	namespace      string
+	version        *semver.Version
+}
+
+// New creates a new configured ledger.
+func New(config Config) (*Ledger, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	// Settings.
+	if config.Name == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Name must not be empty", config)
+	}
+	if config.Namespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Namespace must not be empty", config)
+	}
+	if config.CoolingOff < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CoolingOff must not be negative", config)
+	}
+	var version *semver.Version
+	if config.Version != "" {
+		v, err := semver.NewVersion(config.Version)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Version must be a semantic version, got %q", config, config.Version)
+		}
+		version = v
+	}
+
+	l := &Ledger{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		// Settings.
+		allowDowngrade: config.AllowDowngrade,
+		coolingOff:     config.CoolingOff,
+		name:           config.Name,
+		namespace:      config.Namespace,
+		version:        version,
+	}
+
+	return l, nil
+}
+
+// Name returns the name of the config map holding the ledger.
+func (l *Ledger) Name() string {
+	return l.name
+}
+
+// Namespace returns the namespace of the config map holding the ledger.
+func (l *Ledger) Namespace() string {
+	return l.namespace
+}
+
+// List returns all allocations recorded in the ledger, sorted by LB port.
+// Released allocations cooling off are not listed.
+func (l *Ledger) List(ctx context.Context) ([]Allocation, error) {
+	configMap, err := l.k8sClient.GetConfigMap(ctx, l.namespace, l.name)
+	if clients.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	allocations := l.fromData(ctx, configMap.Data)
+
+	var list []Allocation
+	for _, a := range allocations {
+		if a.ReleasedAt != nil {
+			continue
+		}
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LBPort < list[j].LBPort
+	})
+
+	return list, nil
+}
+
+// Record replaces the allocations of the given guest cluster with the given
+// allocations. Recording an LB port allocated to another guest cluster fails
+// with an allocation conflict and leaves the ledger untouched. Recording an LB
+// port another guest cluster released during the cooling-off period fails
+// with a cooling-off error. Guest clusters can always allocate the LB ports
+// they released themselves again.
+func (l *Ledger) Record(ctx context.Context, clusterID string, allocations []Allocation) error {
+	var allocated, deallocated int
+
+	err := l.update(ctx, nil, func(current map[int]Allocation) error {
+		allocated, deallocated = 0, 0
+
+		for _, a := range allocations {
+			c, ok := current[a.LBPort]
+			if !ok || c.ClusterID == clusterID {
+				continue
+			}
+			if c.ReleasedAt != nil {
+				return microerror.Maskf(coolingOffError, "LB port %d got released by guest cluster %s and cools off until %s", a.LBPort, c.ClusterID, c.ReleasedAt.Add(l.coolingOff).Format(time.RFC3339))
+			}
+
+			if c.IngressConfig != "" {
+				return microerror.Maskf(allocationConflictError, "LB port %d is allocated to guest cluster %s by IngressConfig %s", a.LBPort, c.ClusterID, c.IngressConfig)
+			}
+
+			return microerror.Maskf(allocationConflictError, "LB port %d is allocated to guest cluster %s", a.LBPort, c.ClusterID)
+		}
+
+		allocatedAt := map[int]time.Time{}
+		for p, c := range current {
+			if c.ClusterID == clusterID && c.ReleasedAt == nil {
+				allocatedAt[p] = c.AllocatedAt
+				delete(current, p)
+			}
+		}
+		recorded := map[int]bool{}
+		for _, a := range allocations {
+			if _, ok := allocatedAt[a.LBPort]; !ok {
+				allocated++
+			}
+			recorded[a.LBPort] = true
+
+			a.AllocatedAt = allocatedAt[a.LBPort]
+			if a.AllocatedAt.IsZero() {
+				a.AllocatedAt = now().UTC().Truncate(time.Second)
+			}
+			a.ClusterID = clusterID
+			current[a.LBPort] = a
+		}
+		for p := range allocatedAt {
+			if !recorded[p] {
+				deallocated++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	allocationsCounter.Add(float64(allocated))
+	deallocationsCounter.Add(float64(deallocated))
+
+	return nil
+}
+
+// Release releases all allocations of the given guest cluster. They are kept
+// in the ledger until the cooling-off period passed.
+func (l *Ledger) Release(ctx context.Context, clusterID string) error {
+	var deallocated int
+
+	err := l.update(ctx, nil, func(current map[int]Allocation) error {
+		deallocated = 0
+
+		releasedAt := now().UTC().Truncate(time.Second)
+		for p, c := range current {
+			if c.ClusterID == clusterID && c.ReleasedAt == nil {
+				c.ReleasedAt = &releasedAt
+				current[p] = c
+				deallocated++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	deallocationsCounter.Add(float64(deallocated))
+
+	return nil
+}
+
+// ReleaseLBPort removes the allocation of the given LB port from the ledger,
+// regardless of the guest cluster it is allocated to. It is used to release
+// leaked allocations, which is why the LB port does not cool off. It returns
+// the released allocation and whether the LB port was allocated at all.
+func (l *Ledger) ReleaseLBPort(ctx context.Context, port int) (Allocation, bool, error) {
+	var released Allocation
+	var ok bool
+
+	err := l.update(ctx, nil, func(current map[int]Allocation) error {
+		released, ok = current[port]
+		delete(current, port)
+
+		return nil
+	})
+	if err != nil {
+		return Allocation{}, false, microerror.Mask(err)
+	}
+
+	if ok && released.ReleasedAt == nil {
+		deallocationsCounter.Inc()
+	}
+
+	return released, ok, nil
+}
+
+// IsAdopted returns whether the legacy entries of the ingress controller
+// config maps got adopted into the ledger already.
+func (l *Ledger) IsAdopted(ctx context.Context) (bool, error) {
+	configMap, err := l.k8sClient.GetConfigMap(ctx, l.namespace, l.name)
+	if clients.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return configMap.Annotations[AdoptedAnnotation] == "true", nil
+}
+
+// Inspection describes the integrity of the ledger config map.
+type Inspection struct {
+	// Malformed are the keys of the entries which cannot be parsed, sorted.
+	// They are dropped by the next write of the ledger.
+	Malformed []string
+	// Missing is whether the ledger config map does not exist, e.g. because
+	// it got deleted.
+	Missing bool
+}
+
+// IsCorrupted returns whether the ledger lost allocations, so that it has to
+// be reconstructed.
+func (i Inspection) IsCorrupted() bool {
+	return i.Missing || len(i.Malformed) > 0
+}
+
+// Inspect checks the integrity of the ledger config map.
+func (l *Ledger) Inspect(ctx context.Context) (Inspection, error) {
+	configMap, err := l.k8sClient.GetConfigMap(ctx, l.namespace, l.name)
+	if clients.IsNotFound(err) {
+		return Inspection{Missing: true}, nil
+	} else if err != nil {
+		return Inspection{}, microerror.Mask(err)
+	}
+
+	_, malformed := parseData(configMap.Data)
+
+	return Inspection{Malformed: malformed}, nil
+}
+
+// Adopt records the given allocations of legacy entries in the ledger and
+// marks the ledger as adopted. Allocations of LB ports which are already
+// recorded are skipped, since the ledger is the source of truth. It returns the
+// allocations which got recorded.
+func (l *Ledger) Adopt(ctx context.Context, allocations []Allocation) ([]Allocation, error) {
+	var adopted []Allocation
+
+	annotations := map[string]string{
+		AdoptedAnnotation: "true",
+	}
+
+	err := l.update(ctx, annotations, func(current map[int]Allocation) error {
+		adopted = nil
+
+		for _, a := range allocations {
+			_, ok := current[a.LBPort]
+			if ok {
+				continue
+			}
+
+			// The time legacy entries got allocated at is unknown, so their
+			// adoption is the earliest time the ledger can tell.
+			if a.AllocatedAt.IsZero() {
+				a.AllocatedAt = now().UTC().Truncate(time.Second)
+			}
+			current[a.LBPort] = a
+			adopted = append(adopted, a)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return adopted, nil
+}
+
+// update applies the given modification to the current allocations and writes
+// the result back in case anything changed. The given annotations are set on
+// the config map along with the allocations. Conflicting writes are retried
+// based on the latest version of the ledger.
+func (l *Ledger) update(ctx context.Context, annotations map[string]string, modify func(current map[int]Allocation) error) error {
+	for i := 0; i < maxAttempts; i++ {
+		configMap, err := l.k8sClient.GetConfigMap(ctx, l.namespace, l.name)
+		create := clients.IsNotFound(err)
+		if create {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      l.name,
+					Namespace: l.namespace,
+				},
+			}
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		err = l.checkVersion(ctx, configMap)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		// Allocations which cooled off are removed before the modification, so
+		// that they do not block their LB ports, and after it, so that
+		// allocations released without cooling-off period are removed right
+		// away.
+		current := l.fromData(ctx, configMap.Data)
+		l.removeCooledOff(current)
+
+		err = modify(current)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		l.removeCooledOff(current)
+
+		data, err := toData(current)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if !create && equal(configMap.Data, data) && hasAnnotations(configMap, annotations) {
+			return nil
+		}
+		configMap.Data = data
+		for k, v := range annotations {
+			if configMap.Annotations == nil {
+				configMap.Annotations = map[string]string{}
+			}
+			configMap.Annotations[k] = v
+		}
+		if l.version != nil {
+			if configMap.Annotations == nil {
+				configMap.Annotations = map[string]string{}
+			}
+			configMap.Annotations[VersionAnnotation] = l.version.String()
+		}
+
+		if create {
+			_, err = l.k8sClient.CreateConfigMap(ctx, l.namespace, configMap)
+		} else {
+			_, err = l.k8sClient.UpdateConfigMap(ctx, l.namespace, configMap)
+		}
+		if clients.IsConflict(err) || clients.IsAlreadyExists(err) {
+			l.logger.LogCtx(ctx, "level", "debug", "message", "retrying ledger update due to conflicting write")
+			jitter.Sleep(ctx, conflictJitter)
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	return microerror.Maskf(tooManyConflictsError, "ledger update failed after %d attempts", maxAttempts)
+}
+
+// checkVersion returns an error in case the given ledger config map got
+// written by a newer operator version. Unknown or malformed versions are
+// ignored, since the ledger might have been written before versions were
+// recorded.
+func (l *Ledger) checkVersion(ctx context.Context, configMap *corev1.ConfigMap) error {
+	if l.version == nil {
+		return nil
+	}
+
+	written, err := semver.NewVersion(configMap.GetAnnotations()[VersionAnnotation])
+	if err != nil {
+		return nil
+	}
+	if !l.version.LessThan(*written) {
+		return nil
+	}
+
+	if l.allowDowngrade {
+		l.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("overwriting ledger written by newer operator version %s with version %s", written, l.version))
+		return nil
+	}
+
+	return microerror.Maskf(versionSkewError, "ledger got written by newer operator version %s, refusing to overwrite it with version %s", written, l.version)
+}
+
+// removeCooledOff removes the released allocations whose cooling-off period
+// passed from the given allocations.
+func (l *Ledger) removeCooledOff(allocations map[int]Allocation) {
+	for p, a := range allocations {
+		if a.ReleasedAt != nil && !now().Before(a.ReleasedAt.Add(l.coolingOff)) {
+			delete(allocations, p)
+		}
+	}
+}
+
+// fromData parses the given config map data. Malformed entries are skipped,
+// since a single broken entry must not render the whole ledger unusable.
+func (l *Ledger) fromData(ctx context.Context, data map[string]string) map[int]Allocation {
+	allocations, malformed := parseData(data)
+	for _, k := range malformed {
+		l.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("skipping malformed ledger entry %q", k))
+	}
+
+	return allocations
+}
+
+// parseData parses the given config map data. It returns the keys of the
+// malformed entries, sorted, in addition.
+func parseData(data map[string]string) (map[int]Allocation, []string) {
+	allocations := map[int]Allocation{}
+	var malformed []string
+
+	for k, v := range data {
+		p, err := strconv.Atoi(k)
+		if err != nil {
+			malformed = append(malformed, k)
+			continue
+		}
+
+		var a Allocation
+		err = json.Unmarshal([]byte(v), &a)
+		if err != nil {
+			malformed = append(malformed, k)
+			continue
+		}
+		a.LBPort = p
+
+		allocations[p] = a
+	}
+
+	sort.Strings(malformed)
+
+	return allocations, malformed
+}
+
+func equal(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasAnnotations(configMap *corev1.ConfigMap, annotations map[string]string) bool {
+	for k, v := range annotations {
+		if configMap.Annotations[k] != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+func toData(allocations map[int]Allocation) (map[string]string, error) {
+	data := map[string]string{}
+
+	for p, a := range allocations {
+		b, err := json.Marshal(a)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		data[strconv.Itoa(p)] = string(b)
+	}
+
+	return data, nil
+}
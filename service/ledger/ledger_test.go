@@ -0,0 +1,423 @@
+package ledger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+func newTestLedger(t *testing.T) *Ledger {
+	return newTestLedgerWithCoolingOff(t, 0)
+}
+
+func newTestLedgerWithCoolingOff(t *testing.T, coolingOff time.Duration) *Ledger {
+	k8sClient, err := clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	c := Config{
+		K8sClient: k8sClient,
+		Logger:    microloggertest.New(),
+
+		CoolingOff: coolingOff,
+		Name:       "ingress-operator-allocations",
+		Namespace:  "kube-system",
+	}
+
+	l, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return l
+}
+
+func Test_Ledger(t *testing.T) {
+	ctx := context.TODO()
+	l := newTestLedger(t)
+
+	t0 := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return t0 }
+	defer func() { now = time.Now }()
+
+	list, err := l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list) != 0 {
+		t.Fatal("expected", 0, "got", len(list))
+	}
+
+	err = l.Record(ctx, "al9qy", []Allocation{
+		{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = l.Record(ctx, "p1l6x", []Allocation{
+		{IngressPort: 30010, LBPort: 31002, Pinned: true, Protocol: "http"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Recording LB ports of another guest cluster must fail without modifying
+	// the ledger.
+	err = l.Record(ctx, "p1l6x", []Allocation{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+	})
+	if !IsAllocationConflict(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// Recording a subset of the allocations of a guest cluster must remove the
+	// other allocations of the guest cluster. Allocations recorded again must
+	// keep the time they got allocated at.
+	now = func() time.Time { return t0.Add(time.Hour) }
+	err = l.Record(ctx, "al9qy", []Allocation{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err = l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	expected := []Allocation{
+		{AllocatedAt: t0, ClusterID: "al9qy", IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{AllocatedAt: t0, ClusterID: "p1l6x", IngressPort: 30010, LBPort: 31002, Pinned: true, Protocol: "http"},
+	}
+	if !reflect.DeepEqual(expected, list) {
+		t.Fatalf("expected %#v got %#v", expected, list)
+	}
+
+	err = l.Release(ctx, "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err = l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reflect.DeepEqual(expected[1:], list) {
+		t.Fatalf("expected %#v got %#v", expected[1:], list)
+	}
+}
+
+func Test_Ledger_CoolingOff(t *testing.T) {
+	ctx := context.TODO()
+	l := newTestLedgerWithCoolingOff(t, time.Hour)
+
+	t0 := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return t0 }
+	defer func() { now = time.Now }()
+
+	err := l.Record(ctx, "al9qy", []Allocation{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = l.Release(ctx, "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Released allocations must not be listed while cooling off.
+	list, err := l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list) != 0 {
+		t.Fatal("expected", 0, "got", len(list))
+	}
+
+	// Another guest cluster must not get the LB port during the cooling-off
+	// period.
+	now = func() time.Time { return t0.Add(59 * time.Minute) }
+	err = l.Record(ctx, "p1l6x", []Allocation{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+	})
+	if !IsCoolingOff(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// The guest cluster which released the LB port can allocate it again.
+	err = l.Record(ctx, "al9qy", []Allocation{
+		{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Another guest cluster gets the LB port once it cooled off.
+	now = func() time.Time { return t0.Add(time.Hour) }
+	err = l.Record(ctx, "p1l6x", []Allocation{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	list, err = l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	expected := []Allocation{
+		{AllocatedAt: t0.Add(time.Hour), ClusterID: "p1l6x", IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{AllocatedAt: t0.Add(59 * time.Minute), ClusterID: "al9qy", IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+	}
+	if !reflect.DeepEqual(expected, list) {
+		t.Fatalf("expected %#v got %#v", expected, list)
+	}
+}
+
+func Test_Ledger_Adopt(t *testing.T) {
+	ctx := context.TODO()
+	l := newTestLedger(t)
+
+	adopted, err := l.IsAdopted(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if adopted {
+		t.Fatal("expected", false, "got", true)
+	}
+
+	err = l.Record(ctx, "al9qy", []Allocation{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// The LB port recorded already must be skipped, since the ledger is the
+	// source of truth.
+	list, err := l.Adopt(ctx, []Allocation{
+		{ClusterID: "p1l6x", IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{ClusterID: "p1l6x", IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list) != 1 || list[0].LBPort != 31001 {
+		t.Fatalf("expected %s got %#v", "adopted LB port 31001", list)
+	}
+
+	adopted, err = l.IsAdopted(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !adopted {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	all, err := l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(all) != 2 || all[0].ClusterID != "al9qy" || all[1].ClusterID != "p1l6x" {
+		t.Fatalf("expected %s got %#v", "allocations of al9qy and p1l6x", all)
+	}
+}
+
+func Test_Ledger_ReleaseLBPort(t *testing.T) {
+	ctx := context.TODO()
+	l := newTestLedger(t)
+
+	err := l.Record(ctx, "al9qy", []Allocation{
+		{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+		{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	released, ok, err := l.ReleaseLBPort(ctx, 31001)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !ok || released.ClusterID != "al9qy" {
+		t.Fatalf("expected %#v got %#v", "allocation of al9qy", released)
+	}
+
+	_, ok, err = l.ReleaseLBPort(ctx, 31001)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if ok {
+		t.Fatal("expected", false, "got", true)
+	}
+
+	list, err := l.List(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list) != 1 || list[0].LBPort != 31000 {
+		t.Fatalf("expected %#v got %#v", "allocation of 31000", list)
+	}
+}
+
+func Test_Ledger_VersionSkew(t *testing.T) {
+	testCases := []struct {
+		Writer         string
+		Version        string
+		AllowDowngrade bool
+		ErrorMatcher   func(error) bool
+	}{
+		// Test 0 ensures a newer version overwrites the ledger written by an
+		// older version.
+		{
+			Writer:       "0.1.0",
+			Version:      "0.2.0",
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures an older version refuses to overwrite the ledger
+		// written by a newer version.
+		{
+			Writer:       "0.2.0",
+			Version:      "0.1.0",
+			ErrorMatcher: IsVersionSkew,
+		},
+
+		// Test 2 ensures an older version overwrites the ledger written by a
+		// newer version in case downgrades are allowed.
+		{
+			Writer:         "0.2.0",
+			Version:        "0.1.0",
+			AllowDowngrade: true,
+			ErrorMatcher:   nil,
+		},
+
+		// Test 3 ensures version skew is not detected without version.
+		{
+			Writer:       "0.2.0",
+			Version:      "",
+			ErrorMatcher: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		k8sClient, err := clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		newLedger := func(version string, allowDowngrade bool) *Ledger {
+			c := Config{
+				K8sClient: k8sClient,
+				Logger:    microloggertest.New(),
+
+				AllowDowngrade: allowDowngrade,
+				Name:           "ingress-operator-allocations",
+				Namespace:      "kube-system",
+				Version:        version,
+			}
+
+			l, err := New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+
+			return l
+		}
+
+		err = newLedger(tc.Writer, false).Record(context.TODO(), "al9qy", []Allocation{{IngressPort: 30010, LBPort: 31000, Protocol: "http"}})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		err = newLedger(tc.Version, tc.AllowDowngrade).Record(context.TODO(), "p1l6x", []Allocation{{IngressPort: 30010, LBPort: 31001, Protocol: "http"}})
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+
+		configMap, err := k8sClient.GetConfigMap(context.TODO(), "kube-system", "ingress-operator-allocations")
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		expected := tc.Writer
+		if tc.ErrorMatcher == nil && tc.Version != "" {
+			expected = tc.Version
+		}
+		if configMap.Annotations[VersionAnnotation] != expected {
+			t.Fatal("test", i, "expected", expected, "got", configMap.Annotations[VersionAnnotation])
+		}
+	}
+}
+
+func Test_Ledger_Inspect(t *testing.T) {
+	ctx := context.TODO()
+	l := newTestLedger(t)
+
+	inspection, err := l.Inspect(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !inspection.Missing || !inspection.IsCorrupted() {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	err = l.Record(ctx, "al9qy", []Allocation{{IngressPort: 30010, LBPort: 31000, Protocol: "http"}})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	inspection, err = l.Inspect(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if inspection.IsCorrupted() {
+		t.Fatal("expected", false, "got", true)
+	}
+
+	configMap, err := l.k8sClient.GetConfigMap(ctx, l.namespace, l.name)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	configMap.Data["31001"] = "{"
+	configMap.Data["http"] = "{}"
+	_, err = l.k8sClient.UpdateConfigMap(ctx, l.namespace, configMap)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	inspection, err = l.Inspect(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reflect.DeepEqual(inspection.Malformed, []string{"31001", "http"}) {
+		t.Fatal("expected", []string{"31001", "http"}, "got", inspection.Malformed)
+	}
+
+	// Writing the ledger drops the malformed entries.
+	_, err = l.Adopt(ctx, nil)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	inspection, err = l.Inspect(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if inspection.IsCorrupted() {
+		t.Fatal("expected", false, "got", true)
+	}
+}
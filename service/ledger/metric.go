@@ -0,0 +1,35 @@
+package ledger
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "ledger"
+)
+
+var (
+	allocationsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "allocations_total",
+			Help:      "A counter metric expressing the number of LB ports newly allocated to guest clusters. Its rate is the allocation churn.",
+		},
+	)
+	deallocationsCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "deallocations_total",
+			Help:      "A counter metric expressing the number of LB ports released by guest clusters or force released. Its rate is the deallocation churn.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(allocationsCounter)
+	prometheus.MustRegister(deallocationsCounter)
+}
@@ -0,0 +1,228 @@
+// Package legacy implements a read-only watcher of the legacy ingresstpr
+// objects, which predate IngressConfigs. They are neither reconciled nor
+// written. The watcher only lists them periodically, so that the remaining
+// ones are exposed as metric and by the allocations endpoint, flagged as
+// legacy, until all of them got migrated to IngressConfigs. Clusters which do
+// not serve the legacy resource anymore have none left.
+package legacy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+// Config represents the configuration used to create a new watcher.
+type Config struct {
+	K8sClient clients.Legacy
+	Logger    micrologger.Logger
+
+	// Interval is the interval in which the legacy ingresstpr objects are
+	// listed.
+	Interval time.Duration
+}
+
+// Object is a legacy ingresstpr object. Its spec has the same structure as
+// the one of IngressConfigs.
+type Object struct {
+	Name      string
+	Namespace string
+	Spec      v1alpha1.IngressConfigSpec
+}
+
+// Watcher lists the legacy ingresstpr objects periodically.
+type Watcher struct {
+	k8sClient clients.Legacy
+	logger    micrologger.Logger
+
+	mutex   sync.Mutex
+	objects []Object
+	stop    chan struct{}
+
+	interval time.Duration
+}
+
+// New creates a new configured watcher.
+func New(config Config) (*Watcher, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+
+	w := &Watcher{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		mutex:   sync.Mutex{},
+		objects: nil,
+		stop:    nil,
+
+		interval: config.Interval,
+	}
+
+	return w, nil
+}
+
+// Allocations returns the LB port allocations requested by the legacy
+// ingresstpr objects found by the latest sync. They are flagged as legacy,
+// since they are not recorded in the ledger. In case namespace and service are
+// not empty, only the allocations of the legacy ingresstpr objects targeting
+// the ingress controller of the given service are returned.
+func (w *Watcher) Allocations(namespace, service string) []ledger.Allocation {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var allocations []ledger.Allocation
+	for _, o := range w.objects {
+		ingressController := o.Spec.HostCluster.IngressController
+		if service != "" && (ingressController.Namespace != namespace || ingressController.Service != service) {
+			continue
+		}
+
+		for _, p := range o.Spec.ProtocolPorts {
+			a := ledger.Allocation{
+				ClusterID:     o.Spec.GuestCluster.ID,
+				IngressConfig: o.Namespace + "/" + o.Name,
+				IngressPort:   p.IngressPort,
+				LBPort:        p.LBPort,
+				Legacy:        true,
+				Protocol:      key.Protocol(p),
+			}
+
+			allocations = append(allocations, a)
+		}
+	}
+
+	return allocations
+}
+
+// Boot syncs the legacy ingresstpr objects right away and then periodically
+// in the background.
+func (w *Watcher) Boot() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.stop != nil {
+		return
+	}
+
+	w.stop = make(chan struct{})
+	go w.run(w.stop)
+}
+
+// Shutdown stops syncing the legacy ingresstpr objects. The objects found by
+// the latest sync are kept.
+func (w *Watcher) Shutdown() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.stop == nil {
+		return
+	}
+
+	close(w.stop)
+	w.stop = nil
+}
+
+// Sync lists the legacy ingresstpr objects, updates the metrics and logs a
+// warning in case there are any left. Objects whose spec cannot be decoded
+// are counted, but request no allocations.
+func (w *Watcher) Sync(ctx context.Context) ([]Object, error) {
+	list, err := w.k8sClient.ListIngressTPRs(ctx, "")
+	if clients.IsNotFound(err) {
+		// The legacy resource is not served, so there are no legacy ingresstpr
+		// objects left.
+		list = &unstructured.UnstructuredList{}
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var objects []Object
+	var lbPorts int
+	for _, item := range list.Items {
+		o := Object{
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+		}
+
+		err := decodeSpec(item, &o.Spec)
+		if err != nil {
+			w.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("failed decoding the spec of legacy ingresstpr %s/%s", o.Namespace, o.Name), "stack", fmt.Sprintf("%#v", err))
+			o.Spec = v1alpha1.IngressConfigSpec{}
+		}
+
+		objects = append(objects, o)
+		lbPorts += len(o.Spec.ProtocolPorts)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Namespace+"/"+objects[i].Name < objects[j].Namespace+"/"+objects[j].Name
+	})
+
+	ingressTPRsGauge.Set(float64(len(objects)))
+	ingressTPRLBPortsGauge.Set(float64(lbPorts))
+
+	if len(objects) > 0 {
+		var names []string
+		for _, o := range objects {
+			names = append(names, o.Namespace+"/"+o.Name)
+		}
+		w.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("found %d legacy ingresstpr objects, which are not reconciled, migrate them to IngressConfigs", len(objects)), "ingressTPRs", strings.Join(names, ","))
+	}
+
+	w.mutex.Lock()
+	w.objects = objects
+	w.mutex.Unlock()
+
+	return objects, nil
+}
+
+func (w *Watcher) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := w.Sync(context.Background())
+		if err != nil {
+			w.logger.Log("level", "error", "message", "failed listing legacy ingresstpr objects", "stack", fmt.Sprintf("%#v", err))
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func decodeSpec(item unstructured.Unstructured, spec *v1alpha1.IngressConfigSpec) error {
+	b, err := json.Marshal(item.Object["spec"])
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = json.Unmarshal(b, spec)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package legacy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// testLegacy is an in-memory implementation of clients.Legacy. The legacy
+// resource is not served in case items is nil.
+type testLegacy struct {
+	items []unstructured.Unstructured
+}
+
+func (c *testLegacy) ListIngressTPRs(ctx context.Context, namespace string) (*unstructured.UnstructuredList, error) {
+	if c.items == nil {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "giantswarm.io", Resource: "ingressconfigs"}, "")
+	}
+
+	return &unstructured.UnstructuredList{Items: c.items}, nil
+}
+
+func newTestIngressTPR(name, clusterID, ingressControllerService string, lbPorts ...int64) unstructured.Unstructured {
+	var protocolPorts []interface{}
+	for _, p := range lbPorts {
+		protocolPorts = append(protocolPorts, map[string]interface{}{
+			"ingressPort": int64(30010),
+			"lbPort":      p,
+			"protocol":    "HTTP",
+		})
+	}
+
+	return unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "giantswarm.io/v1",
+			"kind":       "IngressConfig",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"guestCluster": map[string]interface{}{
+					"id": clusterID,
+				},
+				"hostCluster": map[string]interface{}{
+					"ingressController": map[string]interface{}{
+						"namespace": "kube-system",
+						"service":   ingressControllerService,
+					},
+				},
+				"protocolPorts": protocolPorts,
+			},
+		},
+	}
+}
+
+func Test_Watcher_Sync(t *testing.T) {
+	testCases := []struct {
+		items                     []unstructured.Unstructured
+		ingressControllerService  string
+		expectedObjects           []string
+		expectedAllocationLBPorts []int
+	}{
+		// Test 0 ensures no legacy ingresstpr objects are found in case the
+		// legacy resource is not served.
+		{
+			items:                     nil,
+			expectedObjects:           nil,
+			expectedAllocationLBPorts: nil,
+		},
+		// Test 1 ensures the allocations of all legacy ingresstpr objects are
+		// returned flagged as legacy.
+		{
+			items: []unstructured.Unstructured{
+				newTestIngressTPR("x7a2b", "x7a2b", "ingress-controller", 31002),
+				newTestIngressTPR("al9qy", "al9qy", "ingress-controller", 31000, 31001),
+			},
+			expectedObjects:           []string{"default/al9qy", "default/x7a2b"},
+			expectedAllocationLBPorts: []int{31000, 31001, 31002},
+		},
+		// Test 2 ensures the allocations are filtered by ingress controller.
+		{
+			items: []unstructured.Unstructured{
+				newTestIngressTPR("x7a2b", "x7a2b", "other-ingress-controller", 31002),
+				newTestIngressTPR("al9qy", "al9qy", "ingress-controller", 31000, 31001),
+			},
+			ingressControllerService:  "ingress-controller",
+			expectedObjects:           []string{"default/al9qy", "default/x7a2b"},
+			expectedAllocationLBPorts: []int{31000, 31001},
+		},
+	}
+
+	for i, tc := range testCases {
+		w, err := New(Config{
+			K8sClient: &testLegacy{items: tc.items},
+			Logger:    microloggertest.New(),
+
+			Interval: time.Hour,
+		})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		objects, err := w.Sync(context.TODO())
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		if len(objects) != len(tc.expectedObjects) {
+			t.Fatal("test", i, "expected", tc.expectedObjects, "got", objects)
+		}
+		for j, o := range objects {
+			if o.Namespace+"/"+o.Name != tc.expectedObjects[j] {
+				t.Fatal("test", i, "expected", tc.expectedObjects[j], "got", o.Namespace+"/"+o.Name)
+			}
+		}
+
+		var namespace string
+		if tc.ingressControllerService != "" {
+			namespace = "kube-system"
+		}
+		allocations := w.Allocations(namespace, tc.ingressControllerService)
+		if len(allocations) != len(tc.expectedAllocationLBPorts) {
+			t.Fatal("test", i, "expected", tc.expectedAllocationLBPorts, "got", allocations)
+		}
+		for j, a := range allocations {
+			if a.LBPort != tc.expectedAllocationLBPorts[j] {
+				t.Fatal("test", i, "expected", tc.expectedAllocationLBPorts[j], "got", a.LBPort)
+			}
+			if !a.Legacy {
+				t.Fatal("test", i, "expected", true, "got", a.Legacy)
+			}
+			if a.Protocol != "http" {
+				t.Fatal("test", i, "expected", "http", "got", a.Protocol)
+			}
+		}
+	}
+}
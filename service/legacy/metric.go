@@ -0,0 +1,35 @@
+package legacy
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "legacy"
+)
+
+var (
+	ingressTPRsGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "ingresstprs",
+			Help:      "A gauge metric expressing the number of legacy ingresstpr objects left to migrate to IngressConfigs.",
+		},
+	)
+	ingressTPRLBPortsGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "ingresstpr_lb_ports",
+			Help:      "A gauge metric expressing the number of LB ports requested by legacy ingresstpr objects.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(ingressTPRsGauge)
+	prometheus.MustRegister(ingressTPRLBPortsGauge)
+}
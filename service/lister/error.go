@@ -0,0 +1,30 @@
+package lister
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var wrongTypeError = &microerror.Error{
+	Kind: "wrongTypeError",
+}
+
+// IsWrongType asserts wrongTypeError.
+func IsWrongType(err error) bool {
+	return microerror.Cause(err) == wrongTypeError
+}
+
+var notSyncedError = &microerror.Error{
+	Kind: "notSyncedError",
+}
+
+// IsNotSynced asserts notSyncedError.
+func IsNotSynced(err error) bool {
+	return microerror.Cause(err) == notSyncedError
+}
@@ -0,0 +1,619 @@
+// Package lister implements a cache of all IngressConfigs, so that looking up
+// IngressConfigs other than the reconciled one does not list all of them from
+// the API server every time, e.g. when releasing leaked LB ports or requeueing
+// all IngressConfigs. The cache is kept up to date by an indexer informer of
+// client-go and indexes the IngressConfigs by the ID of their guest cluster,
+// by the LB ports they request and by the ingress controller they target, so
+// that looking up the owners of an LB port or the IngressConfigs of an ingress
+// controller does not scan all of them.
+//
+// The vendored apiextensions release ships a clientset of the IngressConfigs
+// but no generated informers and listers. The cache is therefore built from
+// the indexer informer of client-go over the configured clients, which are
+// also what lets the initial list be paged.
+//
+// The Lister implements clients.IngressConfigs. Reads are served from the cache
+// once it synced and from the API server before, so that no component acts on
+// an incomplete list. Writes always go to the API server.
+//
+// The Lister also implements the watcher of the operatorkit informer, so that
+// the informers of all controller workers are served from the cache instead of
+// listing and watching the API server each.
+//
+// In namespace-scoped mode the cache is populated by one informer per
+// configured namespace, so that the operator neither lists nor watches the
+// IngressConfigs of other namespaces. IngressConfigs of other namespaces are
+// neither served from the cache nor from the API server then.
+package lister
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+)
+
+const (
+	// watchQueueLength is the number of changes buffered per watch of the
+	// cache.
+	watchQueueLength = 100
+)
+
+const (
+	// ClusterIDIndex is the name of the index of the IngressConfigs by the ID
+	// of their guest cluster.
+	ClusterIDIndex = "clusterID"
+	// IngressControllerIndex is the name of the index of the IngressConfigs by
+	// the ingress controller they target, in the format namespace/service.
+	IngressControllerIndex = "ingressController"
+	// LBPortIndex is the name of the index of the IngressConfigs by the LB
+	// ports they request.
+	LBPortIndex = "lbPort"
+)
+
+// Client is the subset of the IngressConfig client the cache is populated
+// with.
+type Client interface {
+	List(opts metav1.ListOptions) (*v1alpha1.IngressConfigList, error)
+	Watch(opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// Config represents the configuration used to create a new lister.
+type Config struct {
+	// Clients list and watch the IngressConfigs to populate the cache with,
+	// usually pagers listing them page by page, by the namespace they are
+	// scoped to. The client of the empty namespace watches all namespaces and
+	// must not be combined with clients of single namespaces.
+	Clients map[string]Client
+	// G8sClient serves reads before the cache synced and all writes.
+	G8sClient clients.IngressConfigs
+	Logger    micrologger.Logger
+
+	// ResyncPeriod is the interval in which the cache is relisted. Zero only
+	// relists in case the watch ends.
+	ResyncPeriod time.Duration
+}
+
+// broadcaster dispatches the changes of a cache to its watches until the cache
+// shuts down.
+type broadcaster struct {
+	*watch.Broadcaster
+
+	closed bool
+}
+
+// informer populates the part of the cache of the namespace its client is
+// scoped to.
+type informer struct {
+	controller cache.Controller
+	indexer    cache.Indexer
+}
+
+// Lister serves IngressConfigs from a cache.
+type Lister struct {
+	clients   map[string]Client
+	g8sClient clients.IngressConfigs
+	logger    micrologger.Logger
+
+	// broadcaster dispatches the changes of the cache to the watches of the
+	// cache. eventMutex guards it and serializes dispatching changes and
+	// starting watches, so that watches neither miss changes nor get them
+	// before the cached objects.
+	broadcaster *broadcaster
+	eventMutex  sync.Mutex
+	informers   map[string]*informer
+	mutex       sync.Mutex
+	namespaces  []string
+	stop        chan struct{}
+
+	resyncPeriod time.Duration
+}
+
+// New creates a new configured lister.
+func New(config Config) (*Lister, error) {
+	if len(config.Clients) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Clients must not be empty", config)
+	}
+	_, all := config.Clients[""]
+	if all && len(config.Clients) > 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Clients must not contain clients of single namespaces together with the one of all namespaces", config)
+	}
+	var namespaces []string
+	for n, c := range config.Clients {
+		if c == nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Clients[%q] must not be empty", config, n)
+		}
+		namespaces = append(namespaces, n)
+	}
+	sort.Strings(namespaces)
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.ResyncPeriod < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ResyncPeriod must not be negative", config)
+	}
+
+	l := &Lister{
+		clients:   config.Clients,
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+
+		broadcaster: nil,
+		eventMutex:  sync.Mutex{},
+		informers:   nil,
+		mutex:       sync.Mutex{},
+		namespaces:  namespaces,
+		stop:        nil,
+
+		resyncPeriod: config.ResyncPeriod,
+	}
+
+	return l, nil
+}
+
+// Boot starts populating the cache in the background. Calling Boot after
+// Shutdown populates a new cache.
+func (l *Lister) Boot() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.stop != nil {
+		return
+	}
+
+	// The broadcaster blocks dispatching changes in case a watch is not
+	// consuming them, which is what watches of the API server do as well.
+	b := &broadcaster{
+		Broadcaster: watch.NewBroadcaster(watchQueueLength, watch.WaitIfChannelFull),
+	}
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			l.dispatch(b, watch.Added, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			l.dispatch(b, watch.Modified, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			l.dispatch(b, watch.Deleted, obj)
+		},
+	}
+
+	l.eventMutex.Lock()
+	l.broadcaster = b
+	l.eventMutex.Unlock()
+
+	l.informers = map[string]*informer{}
+	l.stop = make(chan struct{})
+
+	for _, n := range l.namespaces {
+		i := l.newInformer(l.clients[n], handler)
+		l.informers[n] = i
+
+		go i.controller.Run(l.stop)
+	}
+
+	l.logger.Log("level", "debug", "message", "started populating the IngressConfig cache")
+}
+
+// Shutdown stops populating the cache. Reads are served from the API server
+// until the cache is booted again.
+func (l *Lister) Shutdown() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.stop == nil {
+		return
+	}
+
+	close(l.stop)
+
+	// Changes the informer dispatches while it stops are dropped, since the
+	// watches of the cache are closed.
+	l.eventMutex.Lock()
+	l.broadcaster.closed = true
+	l.broadcaster.Shutdown()
+	l.broadcaster = nil
+	l.eventMutex.Unlock()
+
+	l.informers = nil
+	l.stop = nil
+
+	l.logger.Log("level", "debug", "message", "stopped populating the IngressConfig cache")
+}
+
+// HasSynced returns whether the cache is booted and holds all IngressConfigs
+// listed initially.
+func (l *Lister) HasSynced() bool {
+	return l.syncedIndexers() != nil
+}
+
+func (l *Lister) CreateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error) {
+	return l.g8sClient.CreateIngressConfig(ctx, namespace, ingressConfig)
+}
+
+func (l *Lister) DeleteIngressConfig(ctx context.Context, namespace, name string) error {
+	return l.g8sClient.DeleteIngressConfig(ctx, namespace, name)
+}
+
+// GetIngressConfig returns a copy of the cached IngressConfig. It fails with a
+// not found error of the API machinery in case it is not cached, like the API
+// server does.
+func (l *Lister) GetIngressConfig(ctx context.Context, namespace, name string) (*v1alpha1.IngressConfig, error) {
+	if !l.watches(namespace) {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: v1alpha1.SchemeGroupVersion.Group, Resource: "ingressconfigs"}, name)
+	}
+
+	indexers := l.syncedIndexers()
+	if indexers == nil {
+		return l.g8sClient.GetIngressConfig(ctx, namespace, name)
+	}
+
+	indexer, ok := indexers[namespace]
+	if !ok {
+		indexer = indexers[""]
+	}
+
+	obj, exists, err := indexer.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if !exists {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: v1alpha1.SchemeGroupVersion.Group, Resource: "ingressconfigs"}, name)
+	}
+
+	ingressConfig, err := toIngressConfig(obj)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return ingressConfig, nil
+}
+
+// ListIngressConfigs returns copies of the cached IngressConfigs of the given
+// namespace, or of all watched namespaces in case it is empty.
+func (l *Lister) ListIngressConfigs(ctx context.Context, namespace string) (*v1alpha1.IngressConfigList, error) {
+	indexers := l.syncedIndexers()
+	if indexers == nil {
+		list, err := l.listFromAPI(ctx, namespace)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return list, nil
+	}
+
+	var objs []interface{}
+	for _, indexer := range indexers {
+		if namespace == "" {
+			objs = append(objs, indexer.List()...)
+		} else {
+			o, err := indexer.ByIndex(cache.NamespaceIndex, namespace)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			objs = append(objs, o...)
+		}
+	}
+
+	list, err := toIngressConfigList(objs)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list, nil
+}
+
+// ListIngressConfigsByClusterID returns copies of the IngressConfigs of the
+// given guest cluster in all namespaces.
+func (l *Lister) ListIngressConfigsByClusterID(ctx context.Context, clusterID string) (*v1alpha1.IngressConfigList, error) {
+	list, err := l.listByIndex(ctx, ClusterIDIndex, clusterID, clusterIDIndexFunc)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list, nil
+}
+
+// ListIngressConfigsByIngressController returns copies of the IngressConfigs
+// targeting the ingress controller of the given service in all namespaces.
+func (l *Lister) ListIngressConfigsByIngressController(ctx context.Context, namespace, service string) (*v1alpha1.IngressConfigList, error) {
+	list, err := l.listByIndex(ctx, IngressControllerIndex, namespace+"/"+service, ingressControllerIndexFunc)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list, nil
+}
+
+// ListIngressConfigsByLBPort returns copies of the IngressConfigs requesting
+// the given LB port in all namespaces. More than one IngressConfig requesting
+// the same LB port is a conflict.
+func (l *Lister) ListIngressConfigsByLBPort(ctx context.Context, lbPort int) (*v1alpha1.IngressConfigList, error) {
+	list, err := l.listByIndex(ctx, LBPortIndex, strconv.Itoa(lbPort), lbPortIndexFunc)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list, nil
+}
+
+// Watch implements the watcher of the operatorkit informer. It emits an added
+// event for every cached IngressConfig and then the changes of the cache, like
+// a watch of the API server without resource version. It fails with a not
+// synced error in case the cache did not sync yet, so that the informer
+// retries. The watch is closed in case the Lister shuts down.
+func (l *Lister) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	indexers := l.syncedIndexers()
+
+	l.eventMutex.Lock()
+	defer l.eventMutex.Unlock()
+
+	if indexers == nil || l.broadcaster == nil {
+		return nil, microerror.Maskf(notSyncedError, "IngressConfig cache did not sync yet")
+	}
+
+	var events []watch.Event
+	for _, indexer := range indexers {
+		for _, obj := range indexer.List() {
+			customObject, err := toIngressConfig(obj)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			events = append(events, watch.Event{Type: watch.Added, Object: customObject})
+		}
+	}
+
+	return l.broadcaster.WatchWithPrefix(events), nil
+}
+
+func (l *Lister) UpdateIngressConfig(ctx context.Context, namespace string, ingressConfig *v1alpha1.IngressConfig) (*v1alpha1.IngressConfig, error) {
+	return l.g8sClient.UpdateIngressConfig(ctx, namespace, ingressConfig)
+}
+
+// listByIndex returns copies of the cached IngressConfigs having the given
+// value in the given index. Before the cache synced all IngressConfigs are
+// listed from the API server and filtered with the index function instead.
+func (l *Lister) listByIndex(ctx context.Context, index, value string, indexFunc cache.IndexFunc) (*v1alpha1.IngressConfigList, error) {
+	indexers := l.syncedIndexers()
+	if indexers == nil {
+		list, err := l.listFromAPI(ctx, "")
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		filtered := &v1alpha1.IngressConfigList{}
+		for _, customObject := range list.Items {
+			values, err := indexFunc(&customObject)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			if containsString(values, value) {
+				filtered.Items = append(filtered.Items, customObject)
+			}
+		}
+
+		return filtered, nil
+	}
+
+	var objs []interface{}
+	for _, indexer := range indexers {
+		o, err := indexer.ByIndex(index, value)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		objs = append(objs, o...)
+	}
+
+	list, err := toIngressConfigList(objs)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return list, nil
+}
+
+// dispatch emits a copy of the given changed object to all watches of the
+// cache.
+func (l *Lister) dispatch(b *broadcaster, eventType watch.EventType, obj interface{}) {
+	tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+	if ok {
+		obj = tombstone.Obj
+	}
+
+	customObject, err := toIngressConfig(obj)
+	if err != nil {
+		l.logger.Log("level", "warning", "message", "cannot dispatch change of the IngressConfig cache", "stack", fmt.Sprintf("%#v", err))
+		return
+	}
+
+	l.eventMutex.Lock()
+	defer l.eventMutex.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.Action(eventType, customObject)
+}
+
+// listFromAPI lists the IngressConfigs of the given namespace from the API
+// server, or the ones of all watched namespaces in case it is empty. Nothing is
+// listed for namespaces which are not watched.
+func (l *Lister) listFromAPI(ctx context.Context, namespace string) (*v1alpha1.IngressConfigList, error) {
+	if !l.watches(namespace) {
+		return &v1alpha1.IngressConfigList{}, nil
+	}
+
+	if namespace != "" {
+		list, err := l.g8sClient.ListIngressConfigs(ctx, namespace)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return list, nil
+	}
+
+	list := &v1alpha1.IngressConfigList{}
+	for _, n := range l.namespaces {
+		namespaced, err := l.g8sClient.ListIngressConfigs(ctx, n)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		list.Items = append(list.Items, namespaced.Items...)
+	}
+
+	return list, nil
+}
+
+// newInformer returns an informer populating the cache with the IngressConfigs
+// of the given client.
+func (l *Lister) newInformer(client Client, handler cache.ResourceEventHandler) *informer {
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.Watch(options)
+		},
+	}
+
+	indexers := cache.Indexers{
+		ClusterIDIndex:         clusterIDIndexFunc,
+		IngressControllerIndex: ingressControllerIndexFunc,
+		LBPortIndex:            lbPortIndexFunc,
+		cache.NamespaceIndex:   cache.MetaNamespaceIndexFunc,
+	}
+
+	i := &informer{}
+	i.indexer, i.controller = cache.NewIndexerInformer(lw, &v1alpha1.IngressConfig{}, l.resyncPeriod, handler, indexers)
+
+	return i
+}
+
+// syncedIndexers returns the indexers of the cache by namespace in case all of
+// them synced, and nil otherwise.
+func (l *Lister) syncedIndexers() map[string]cache.Indexer {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.informers == nil {
+		return nil
+	}
+
+	indexers := map[string]cache.Indexer{}
+	for n, i := range l.informers {
+		if !i.controller.HasSynced() {
+			return nil
+		}
+		indexers[n] = i.indexer
+	}
+
+	return indexers
+}
+
+// watches returns whether the cache holds the IngressConfigs of the given
+// namespace, or of all watched namespaces in case it is empty.
+func (l *Lister) watches(namespace string) bool {
+	if namespace == "" {
+		return true
+	}
+
+	_, all := l.clients[""]
+	_, ok := l.clients[namespace]
+
+	return all || ok
+}
+
+func clusterIDIndexFunc(obj interface{}) ([]string, error) {
+	customObject, ok := obj.(*v1alpha1.IngressConfig)
+	if !ok {
+		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &v1alpha1.IngressConfig{}, obj)
+	}
+
+	return []string{key.ClusterID(*customObject)}, nil
+}
+
+func ingressControllerIndexFunc(obj interface{}) ([]string, error) {
+	customObject, ok := obj.(*v1alpha1.IngressConfig)
+	if !ok {
+		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &v1alpha1.IngressConfig{}, obj)
+	}
+
+	return []string{key.IngressControllerNamespace(*customObject) + "/" + key.IngressControllerService(*customObject)}, nil
+}
+
+func lbPortIndexFunc(obj interface{}) ([]string, error) {
+	customObject, ok := obj.(*v1alpha1.IngressConfig)
+	if !ok {
+		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &v1alpha1.IngressConfig{}, obj)
+	}
+
+	var values []string
+	for _, p := range key.LBPorts(*customObject) {
+		values = append(values, strconv.Itoa(p))
+	}
+
+	return values, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toIngressConfig returns a copy of the cached object, since the cache must
+// not be modified by callers.
+func toIngressConfig(obj interface{}) (*v1alpha1.IngressConfig, error) {
+	customObject, ok := obj.(*v1alpha1.IngressConfig)
+	if !ok {
+		return nil, microerror.Maskf(wrongTypeError, "expected '%T', got '%T'", &v1alpha1.IngressConfig{}, obj)
+	}
+
+	return customObject.DeepCopy(), nil
+}
+
+// toIngressConfigList returns copies of the cached objects sorted by
+// namespace and name, like the API server lists them.
+func toIngressConfigList(objs []interface{}) (*v1alpha1.IngressConfigList, error) {
+	list := &v1alpha1.IngressConfigList{}
+	for _, obj := range objs {
+		customObject, err := toIngressConfig(obj)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		list.Items = append(list.Items, *customObject)
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].Namespace != list.Items[j].Namespace {
+			return list.Items[i].Namespace < list.Items[j].Namespace
+		}
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	return list, nil
+}
@@ -0,0 +1,448 @@
+package lister
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+)
+
+// testClient lists its items once and then streams the events of its fake
+// watcher.
+type testClient struct {
+	items   []v1alpha1.IngressConfig
+	watcher *watch.FakeWatcher
+}
+
+func (c *testClient) List(opts metav1.ListOptions) (*v1alpha1.IngressConfigList, error) {
+	list := &v1alpha1.IngressConfigList{Items: c.items}
+	list.ResourceVersion = "1"
+
+	return list, nil
+}
+
+func (c *testClient) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	return c.watcher, nil
+}
+
+func newTestIngressConfig(namespace, name, clusterID string, lbPorts ...int) v1alpha1.IngressConfig {
+	customObject := v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.IngressConfigSpec{
+			GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+				ID: clusterID,
+			},
+			HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+				IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+					Namespace: "kube-system",
+					Service:   "ingress-controller",
+				},
+			},
+		},
+	}
+	for _, p := range lbPorts {
+		customObject.Spec.ProtocolPorts = append(customObject.Spec.ProtocolPorts, v1alpha1.IngressConfigSpecProtocolPort{LBPort: p})
+	}
+
+	return customObject
+}
+
+func Test_Lister(t *testing.T) {
+	ctx := context.TODO()
+
+	items := []v1alpha1.IngressConfig{
+		newTestIngressConfig("default", "p1l6x", "p1l6x"),
+		newTestIngressConfig("default", "al9qy", "al9qy"),
+		newTestIngressConfig("giantswarm", "al9qy", "al9qy"),
+	}
+
+	client := &testClient{
+		items:   items,
+		watcher: watch.NewFake(),
+	}
+	g8sClient := clientstest.NewIngressConfigs(items...)
+
+	l, err := New(Config{
+		Clients:   map[string]Client{"": client},
+		G8sClient: g8sClient,
+		Logger:    microloggertest.New(),
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Reads are served by the API server before the cache is booted.
+	list, err := l.ListIngressConfigs(ctx, "")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 3 {
+		t.Fatal("expected", 3, "got", len(list.Items))
+	}
+	if g8sClient.Reads() != 1 {
+		t.Fatal("expected", 1, "got", g8sClient.Reads())
+	}
+
+	l.Boot()
+	defer l.Shutdown()
+
+	waitFor(t, l.HasSynced)
+
+	list, err = l.ListIngressConfigs(ctx, "")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 3 {
+		t.Fatal("expected", 3, "got", len(list.Items))
+	}
+	if list.Items[0].Namespace+"/"+list.Items[0].Name != "default/al9qy" {
+		t.Fatal("expected", "default/al9qy", "got", list.Items[0].Namespace+"/"+list.Items[0].Name)
+	}
+	if g8sClient.Reads() != 1 {
+		t.Fatal("expected", 1, "got", g8sClient.Reads())
+	}
+
+	list, err = l.ListIngressConfigs(ctx, "giantswarm")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatal("expected", 1, "got", len(list.Items))
+	}
+
+	list, err = l.ListIngressConfigsByClusterID(ctx, "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 2 {
+		t.Fatal("expected", 2, "got", len(list.Items))
+	}
+
+	// Modifying returned IngressConfigs does not modify the cache.
+	customObject, err := l.GetIngressConfig(ctx, "default", "p1l6x")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	customObject.Spec.GuestCluster.ID = "x7a2b"
+
+	list, err = l.ListIngressConfigsByClusterID(ctx, "p1l6x")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatal("expected", 1, "got", len(list.Items))
+	}
+
+	// Watched events update the cache and its index.
+	deleted := newTestIngressConfig("default", "p1l6x", "p1l6x")
+	client.watcher.Delete(&deleted)
+	added := newTestIngressConfig("default", "x7a2b", "x7a2b")
+	client.watcher.Add(&added)
+
+	waitFor(t, func() bool {
+		list, err := l.ListIngressConfigsByClusterID(ctx, "x7a2b")
+		return err == nil && len(list.Items) == 1
+	})
+
+	_, err = l.GetIngressConfig(ctx, "default", "p1l6x")
+	if !errors.IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("expected", true, "got", false)
+}
+
+func Test_Lister_indexes(t *testing.T) {
+	ctx := context.TODO()
+
+	items := []v1alpha1.IngressConfig{
+		newTestIngressConfig("default", "al9qy", "al9qy", 31000, 31001),
+		newTestIngressConfig("default", "p1l6x", "p1l6x", 31002),
+		// The IngressConfig requests an LB port of another guest cluster.
+		newTestIngressConfig("default", "x7a2b", "x7a2b", 31001),
+	}
+	items[2].Spec.HostCluster.IngressController.Service = "other-controller"
+
+	g8sClient := clientstest.NewIngressConfigs(items...)
+
+	l, err := New(Config{
+		Clients:   map[string]Client{"": &testClient{items: items, watcher: watch.NewFake()}},
+		G8sClient: g8sClient,
+		Logger:    microloggertest.New(),
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	assertNames := func(list *v1alpha1.IngressConfigList, expected ...string) {
+		t.Helper()
+
+		if len(list.Items) != len(expected) {
+			t.Fatalf("expected %v got %#v", expected, list.Items)
+		}
+		for i, e := range expected {
+			if list.Items[i].Name != e {
+				t.Fatal("test", i, "expected", e, "got", list.Items[i].Name)
+			}
+		}
+	}
+
+	// The lookups are the same before the cache synced, when the API server
+	// serves them, and afterwards.
+	for _, boot := range []bool{false, true} {
+		if boot {
+			l.Boot()
+			defer l.Shutdown()
+
+			waitFor(t, l.HasSynced)
+		}
+
+		list, err := l.ListIngressConfigsByLBPort(ctx, 31001)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		assertNames(list, "al9qy", "x7a2b")
+
+		list, err = l.ListIngressConfigsByLBPort(ctx, 31003)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		assertNames(list)
+
+		list, err = l.ListIngressConfigsByIngressController(ctx, "kube-system", "ingress-controller")
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		assertNames(list, "al9qy", "p1l6x")
+	}
+
+	if g8sClient.Reads() != 3 {
+		t.Fatal("expected", 3, "got", g8sClient.Reads())
+	}
+}
+
+func Test_Lister_Watch(t *testing.T) {
+	items := []v1alpha1.IngressConfig{
+		newTestIngressConfig("default", "p1l6x", "p1l6x"),
+		newTestIngressConfig("default", "al9qy", "al9qy"),
+	}
+
+	client := &testClient{
+		items:   items,
+		watcher: watch.NewFake(),
+	}
+
+	l, err := New(Config{
+		Clients:   map[string]Client{"": client},
+		G8sClient: clientstest.NewIngressConfigs(items...),
+		Logger:    microloggertest.New(),
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Watches are refused before the cache is synced.
+	_, err = l.Watch(metav1.ListOptions{})
+	if !IsNotSynced(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	l.Boot()
+	waitFor(t, l.HasSynced)
+
+	w, err := l.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Watches start with the cached IngressConfigs.
+	names := map[string]bool{}
+	for i := 0; i < len(items); i++ {
+		e := nextEvent(t, w)
+		if e.Type != watch.Added {
+			t.Fatal("expected", watch.Added, "got", e.Type)
+		}
+		names[e.Object.(*v1alpha1.IngressConfig).Name] = true
+	}
+	if !names["p1l6x"] || !names["al9qy"] {
+		t.Fatal("expected", "p1l6x and al9qy", "got", names)
+	}
+
+	// Watched events are forwarded once they reached the cache.
+	modified := newTestIngressConfig("default", "p1l6x", "x7a2b")
+	client.watcher.Modify(&modified)
+	e := nextEvent(t, w)
+	if e.Type != watch.Modified {
+		t.Fatal("expected", watch.Modified, "got", e.Type)
+	}
+	if e.Object.(*v1alpha1.IngressConfig).Spec.GuestCluster.ID != "x7a2b" {
+		t.Fatal("expected", "x7a2b", "got", e.Object.(*v1alpha1.IngressConfig).Spec.GuestCluster.ID)
+	}
+
+	deleted := newTestIngressConfig("default", "al9qy", "al9qy")
+	client.watcher.Delete(&deleted)
+	e = nextEvent(t, w)
+	if e.Type != watch.Deleted {
+		t.Fatal("expected", watch.Deleted, "got", e.Type)
+	}
+
+	// Shutting down the cache closes its watches.
+	l.Shutdown()
+	select {
+	case _, ok := <-w.ResultChan():
+		if ok {
+			t.Fatal("expected", false, "got", true)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected", "closed watch", "got", "timeout")
+	}
+}
+
+func nextEvent(t *testing.T, w watch.Interface) watch.Event {
+	t.Helper()
+
+	select {
+	case e := <-w.ResultChan():
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("expected", "event", "got", "timeout")
+	}
+
+	return watch.Event{}
+}
+
+func Test_Lister_namespaces(t *testing.T) {
+	ctx := context.TODO()
+
+	items := []v1alpha1.IngressConfig{
+		newTestIngressConfig("default", "p1l6x", "p1l6x"),
+		newTestIngressConfig("giantswarm", "al9qy", "al9qy"),
+		newTestIngressConfig("kube-system", "x7a2b", "x7a2b"),
+	}
+
+	// Every namespace is watched by its own client only listing the
+	// IngressConfigs of the namespace.
+	clients := map[string]Client{}
+	for _, n := range []string{"default", "giantswarm"} {
+		var namespaced []v1alpha1.IngressConfig
+		for _, customObject := range items {
+			if customObject.Namespace == n {
+				namespaced = append(namespaced, customObject)
+			}
+		}
+		clients[n] = &testClient{items: namespaced, watcher: watch.NewFake()}
+	}
+
+	g8sClient := clientstest.NewIngressConfigs(items...)
+
+	l, err := New(Config{
+		Clients:   clients,
+		G8sClient: g8sClient,
+		Logger:    microloggertest.New(),
+	})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Reads before the cache is booted only list the watched namespaces.
+	list, err := l.ListIngressConfigs(ctx, "")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 2 {
+		t.Fatal("expected", 2, "got", len(list.Items))
+	}
+	if g8sClient.Reads() != 2 {
+		t.Fatal("expected", 2, "got", g8sClient.Reads())
+	}
+
+	list, err = l.ListIngressConfigsByClusterID(ctx, "x7a2b")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 0 {
+		t.Fatal("expected", 0, "got", len(list.Items))
+	}
+
+	l.Boot()
+	defer l.Shutdown()
+
+	waitFor(t, l.HasSynced)
+
+	list, err = l.ListIngressConfigs(ctx, "")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 2 {
+		t.Fatal("expected", 2, "got", len(list.Items))
+	}
+	if list.Items[0].Namespace != "default" || list.Items[1].Namespace != "giantswarm" {
+		t.Fatal("expected", "default and giantswarm", "got", list.Items[0].Namespace+" and "+list.Items[1].Namespace)
+	}
+
+	list, err = l.ListIngressConfigs(ctx, "kube-system")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 0 {
+		t.Fatal("expected", 0, "got", len(list.Items))
+	}
+
+	_, err = l.GetIngressConfig(ctx, "giantswarm", "al9qy")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	_, err = l.GetIngressConfig(ctx, "kube-system", "x7a2b")
+	if !errors.IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// Changes of any watched namespace reach the cache and its watches.
+	w, err := l.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	nextEvent(t, w)
+	nextEvent(t, w)
+
+	added := newTestIngressConfig("giantswarm", "r4nd0", "r4nd0")
+	clients["giantswarm"].(*testClient).watcher.Add(&added)
+
+	e := nextEvent(t, w)
+	if e.Type != watch.Added {
+		t.Fatal("expected", watch.Added, "got", e.Type)
+	}
+	if e.Object.(*v1alpha1.IngressConfig).Name != "r4nd0" {
+		t.Fatal("expected", "r4nd0", "got", e.Object.(*v1alpha1.IngressConfig).Name)
+	}
+
+	list, err = l.ListIngressConfigsByClusterID(ctx, "r4nd0")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatal("expected", 1, "got", len(list.Items))
+	}
+}
@@ -0,0 +1,32 @@
+package locator
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var ambiguousError = &microerror.Error{
+	Kind: "ambiguousError",
+}
+
+// IsAmbiguous asserts ambiguousError.
+func IsAmbiguous(err error) bool {
+	return microerror.Cause(err) == ambiguousError
+}
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var notFoundError = &microerror.Error{
+	Kind: "notFoundError",
+}
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return microerror.Cause(err) == notFoundError
+}
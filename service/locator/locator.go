@@ -0,0 +1,157 @@
+// Package locator discovers the config map and the service of shared host
+// cluster ingress controllers by label selectors. Installations rendering the
+// ingress controller with Helm get names with release suffixes, e.g.
+// "nginx-ingress-controller-1a2b3", which differ from the names IngressConfigs
+// reference. The locator looks up the objects matching the selectors in the
+// namespace of the referenced ingress controller instead, so that the names do
+// not have to be configured per installation.
+package locator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+// Config represents the configuration used to create a new locator.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Interface
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// ConfigMapSelector is the label selector of the config map of the ingress
+	// controller, e.g. "app=nginx-ingress-controller,component=tcp-services".
+	ConfigMapSelector string
+	// ServiceSelector is the label selector of the service of the ingress
+	// controller, e.g. "app=nginx-ingress-controller".
+	ServiceSelector string
+}
+
+// DefaultConfig provides a default configuration to create a new locator by
+// best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		ConfigMapSelector: "",
+		ServiceSelector:   "",
+	}
+}
+
+// IngressController are the names of the objects of an ingress controller.
+type IngressController struct {
+	ConfigMap string
+	Service   string
+}
+
+// Locator discovers the objects of ingress controllers.
+type Locator struct {
+	// Dependencies.
+	k8sClient clients.Interface
+	logger    micrologger.Logger
+
+	// Settings.
+	configMapSelector string
+	serviceSelector   string
+}
+
+// New creates a new configured locator.
+func New(config Config) (*Locator, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	// Settings.
+	if config.ConfigMapSelector == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ConfigMapSelector must not be empty", config)
+	}
+	if _, err := labels.Parse(config.ConfigMapSelector); err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ConfigMapSelector must be a label selector, %s", config, err.Error())
+	}
+	if config.ServiceSelector == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ServiceSelector must not be empty", config)
+	}
+	if _, err := labels.Parse(config.ServiceSelector); err != nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ServiceSelector must be a label selector, %s", config, err.Error())
+	}
+
+	l := &Locator{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		// Settings.
+		configMapSelector: config.ConfigMapSelector,
+		serviceSelector:   config.ServiceSelector,
+	}
+
+	return l, nil
+}
+
+// Locate returns the names of the config map and the service of the ingress
+// controller in the given namespace. Exactly one config map and one service
+// have to match the selectors. It fails with a not found error in case none
+// matches and with an ambiguous error in case more than one matches, since
+// guessing would route traffic through the wrong ingress controller.
+func (l *Locator) Locate(ctx context.Context, namespace string) (IngressController, error) {
+	configMaps, err := l.k8sClient.ListConfigMaps(ctx, namespace, l.configMapSelector)
+	if err != nil {
+		return IngressController{}, microerror.Mask(err)
+	}
+	var configMapNames []string
+	for _, c := range configMaps.Items {
+		configMapNames = append(configMapNames, c.Name)
+	}
+
+	services, err := l.k8sClient.ListServices(ctx, namespace, l.serviceSelector)
+	if err != nil {
+		return IngressController{}, microerror.Mask(err)
+	}
+	var serviceNames []string
+	for _, s := range services.Items {
+		serviceNames = append(serviceNames, s.Name)
+	}
+
+	configMap, err := single("config map", namespace, l.configMapSelector, configMapNames)
+	if err != nil {
+		return IngressController{}, microerror.Mask(err)
+	}
+	service, err := single("service", namespace, l.serviceSelector, serviceNames)
+	if err != nil {
+		return IngressController{}, microerror.Mask(err)
+	}
+
+	l.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("located config map %s/%s and service %s/%s of the ingress controller", namespace, configMap, namespace, service))
+
+	c := IngressController{
+		ConfigMap: configMap,
+		Service:   service,
+	}
+
+	return c, nil
+}
+
+func single(kind, namespace, selector string, names []string) (string, error) {
+	switch len(names) {
+	case 0:
+		return "", microerror.Maskf(notFoundError, "no %s in namespace %s matches %q", kind, namespace, selector)
+	case 1:
+		return names[0], nil
+	default:
+		return "", microerror.Maskf(ambiguousError, "%d %ss in namespace %s match %q: %v", len(names), kind, namespace, selector, names)
+	}
+}
@@ -0,0 +1,179 @@
+package locator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+func Test_Locator_Locate(t *testing.T) {
+	newConfigMap := func(name, namespace string, labels map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:    labels,
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+	}
+	newService := func(name, namespace string, labels map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:    labels,
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+	}
+
+	tcpServices := map[string]string{"app": "nginx-ingress-controller", "component": "tcp-services"}
+	controller := map[string]string{"app": "nginx-ingress-controller"}
+
+	testCases := []struct {
+		Objects        []runtime.Object
+		ExpectedResult IngressController
+		ErrorMatcher   func(error) bool
+	}{
+		// Test 0 ensures the objects with release suffixes matching the
+		// selectors are located, while objects in other namespaces and objects
+		// not matching the selectors are ignored.
+		{
+			Objects: []runtime.Object{
+				newConfigMap("nginx-ingress-controller-tcp-1a2b3", "kube-system", tcpServices),
+				newConfigMap("nginx-ingress-controller-1a2b3", "kube-system", controller),
+				newConfigMap("nginx-ingress-controller-tcp-4c5d6", "other", tcpServices),
+				newService("nginx-ingress-controller-1a2b3", "kube-system", controller),
+				newService("nginx-ingress-controller-4c5d6", "other", controller),
+			},
+			ExpectedResult: IngressController{
+				ConfigMap: "nginx-ingress-controller-tcp-1a2b3",
+				Service:   "nginx-ingress-controller-1a2b3",
+			},
+			ErrorMatcher: nil,
+		},
+		// Test 1 ensures a not found error is returned in case no config map
+		// matches the selector.
+		{
+			Objects: []runtime.Object{
+				newService("nginx-ingress-controller-1a2b3", "kube-system", controller),
+			},
+			ExpectedResult: IngressController{},
+			ErrorMatcher:   IsNotFound,
+		},
+		// Test 2 ensures a not found error is returned in case no service
+		// matches the selector.
+		{
+			Objects: []runtime.Object{
+				newConfigMap("nginx-ingress-controller-tcp-1a2b3", "kube-system", tcpServices),
+			},
+			ExpectedResult: IngressController{},
+			ErrorMatcher:   IsNotFound,
+		},
+		// Test 3 ensures an ambiguous error is returned in case multiple
+		// services match the selector, e.g. during the upgrade of the release.
+		{
+			Objects: []runtime.Object{
+				newConfigMap("nginx-ingress-controller-tcp-1a2b3", "kube-system", tcpServices),
+				newService("nginx-ingress-controller-1a2b3", "kube-system", controller),
+				newService("nginx-ingress-controller-4c5d6", "kube-system", controller),
+			},
+			ExpectedResult: IngressController{},
+			ErrorMatcher:   IsAmbiguous,
+		},
+	}
+
+	for i, tc := range testCases {
+		k8sClients, err := clients.New(clients.Config{K8sClient: fake.NewSimpleClientset(tc.Objects...)})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		c := DefaultConfig()
+
+		c.K8sClient = k8sClients
+		c.Logger = microloggertest.New()
+
+		c.ConfigMapSelector = "app=nginx-ingress-controller,component=tcp-services"
+		c.ServiceSelector = "app=nginx-ingress-controller"
+
+		l, err := New(c)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		result, err := l.Locate(context.TODO(), "kube-system")
+		if err != nil {
+			if tc.ErrorMatcher == nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			} else if !tc.ErrorMatcher(err) {
+				t.Fatal("test", i, "expected", true, "got", false)
+			}
+		} else if tc.ErrorMatcher != nil {
+			t.Fatal("test", i, "expected", "error", "got", nil)
+		}
+
+		if result != tc.ExpectedResult {
+			t.Fatal("test", i, "expected", tc.ExpectedResult, "got", result)
+		}
+	}
+}
+
+func Test_Locator_New(t *testing.T) {
+	k8sClients, err := clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	testCases := []struct {
+		ConfigMapSelector string
+		ServiceSelector   string
+		ErrorMatcher      func(error) bool
+	}{
+		// Test 0 ensures valid selectors are accepted.
+		{
+			ConfigMapSelector: "app=nginx-ingress-controller",
+			ServiceSelector:   "app in (nginx-ingress-controller)",
+			ErrorMatcher:      nil,
+		},
+		// Test 1 ensures both selectors are required.
+		{
+			ConfigMapSelector: "app=nginx-ingress-controller",
+			ServiceSelector:   "",
+			ErrorMatcher:      IsInvalidConfig,
+		},
+		// Test 2 ensures malformed selectors are rejected.
+		{
+			ConfigMapSelector: "app in nginx-ingress-controller",
+			ServiceSelector:   "app=nginx-ingress-controller",
+			ErrorMatcher:      IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		c := DefaultConfig()
+
+		c.K8sClient = k8sClients
+		c.Logger = microloggertest.New()
+
+		c.ConfigMapSelector = tc.ConfigMapSelector
+		c.ServiceSelector = tc.ServiceSelector
+
+		_, err := New(c)
+		if err != nil {
+			if tc.ErrorMatcher == nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			} else if !tc.ErrorMatcher(err) {
+				t.Fatal("test", i, "expected", true, "got", false)
+			}
+		} else if tc.ErrorMatcher != nil {
+			t.Fatal("test", i, "expected", "error", "got", nil)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Package monitoring manages the Prometheus Operator objects monitoring the
+// operator itself. The ServiceMonitor makes Prometheus scrape the operator and
+// the PrometheusRule ships the recommended alerts on drift, allocation
+// conflicts and the exhaustion of the port pool, so that the observability of
+// the operator does not depend on objects maintained elsewhere. The objects are
+// kept in sync periodically, so manual changes get reverted.
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+const (
+	// DefaultPoolUtilizationThreshold is the utilization of the port pool
+	// alerted on in case no other threshold is configured.
+	DefaultPoolUtilizationThreshold = 0.95
+)
+
+// ParseLabels parses the given list of labels as used for the configuration
+// of the manager, e.g. "release=prometheus".
+func ParseLabels(list []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, s := range list {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, microerror.Maskf(invalidConfigError, "label must have the format key=value, got %q", s)
+		}
+		labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return labels, nil
+}
+
+// Config represents the configuration used to create a new manager.
+type Config struct {
+	// Dependencies.
+	K8sClient clients.Monitoring
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// Interval is the interval in which the objects are synced.
+	Interval time.Duration
+	// Labels are added to the objects in addition to the app label, so that
+	// they are selected by the Prometheus instance, e.g. release=prometheus.
+	Labels map[string]string
+	// Namespace is the namespace of the operator, which is the namespace the
+	// objects are managed in.
+	Namespace string
+	// PoolUtilizationThreshold is the utilization of the port pool alerted on.
+	PoolUtilizationThreshold float64
+	// ProjectName is the name of the operator. It names the objects and selects
+	// the service of the operator.
+	ProjectName string
+}
+
+// DefaultConfig provides a default configuration to create a new manager by
+// best effort.
+func DefaultConfig() Config {
+	return Config{
+		// Dependencies.
+		K8sClient: nil,
+		Logger:    nil,
+
+		// Settings.
+		Interval:                 5 * time.Minute,
+		Labels:                   nil,
+		Namespace:                "",
+		PoolUtilizationThreshold: DefaultPoolUtilizationThreshold,
+		ProjectName:              "",
+	}
+}
+
+// Manager keeps the ServiceMonitor and the PrometheusRule of the operator in
+// sync.
+type Manager struct {
+	// Dependencies.
+	k8sClient clients.Monitoring
+	logger    micrologger.Logger
+
+	// Internals.
+	mutex sync.Mutex
+	stop  chan struct{}
+
+	// Settings.
+	interval                 time.Duration
+	labels                   map[string]string
+	namespace                string
+	poolUtilizationThreshold float64
+	projectName              string
+}
+
+// New creates a new configured manager.
+func New(config Config) (*Manager, error) {
+	// Dependencies.
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	// Settings.
+	if config.Interval <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Interval must be greater than 0", config)
+	}
+	if config.Namespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Namespace must not be empty", config)
+	}
+	if config.PoolUtilizationThreshold <= 0 || config.PoolUtilizationThreshold > 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.PoolUtilizationThreshold must be a fraction between 0 and 1, got %v", config, config.PoolUtilizationThreshold)
+	}
+	if config.ProjectName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ProjectName must not be empty", config)
+	}
+
+	labels := map[string]string{}
+	for k, v := range config.Labels {
+		labels[k] = v
+	}
+	labels["app"] = config.ProjectName
+
+	m := &Manager{
+		// Dependencies.
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		// Internals.
+		mutex: sync.Mutex{},
+		stop:  nil,
+
+		// Settings.
+		interval:                 config.Interval,
+		labels:                   labels,
+		namespace:                config.Namespace,
+		poolUtilizationThreshold: config.PoolUtilizationThreshold,
+		projectName:              config.ProjectName,
+	}
+
+	return m, nil
+}
+
+// Boot starts syncing the objects in the background. Failing syncs are logged
+// and retried in the next interval, e.g. in case the Prometheus Operator is
+// not installed yet.
+func (m *Manager) Boot() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.stop != nil {
+		return
+	}
+
+	m.stop = make(chan struct{})
+	go m.run(m.stop)
+}
+
+// Shutdown stops syncing the objects. The objects are left in place.
+func (m *Manager) Shutdown() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.stop == nil {
+		return
+	}
+
+	close(m.stop)
+	m.stop = nil
+}
+
+// Ensure creates the objects in case they do not exist and updates them in
+// case they drifted from the desired state.
+func (m *Manager) Ensure(ctx context.Context) error {
+	err := m.ensure(ctx, m.newServiceMonitor(), m.k8sClient.GetServiceMonitor, m.k8sClient.CreateServiceMonitor, m.k8sClient.UpdateServiceMonitor)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = m.ensure(ctx, m.newPrometheusRule(), m.k8sClient.GetPrometheusRule, m.k8sClient.CreatePrometheusRule, m.k8sClient.UpdatePrometheusRule)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+type getFunc func(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error)
+
+type writeFunc func(ctx context.Context, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error)
+
+func (m *Manager) ensure(ctx context.Context, desired *unstructured.Unstructured, get getFunc, create, update writeFunc) error {
+	kind := desired.GetKind()
+
+	current, err := get(ctx, m.namespace, desired.GetName())
+	if clients.IsNotFound(err) {
+		m.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("creating the %s", kind))
+
+		_, err = create(ctx, m.namespace, desired)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		m.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("created the %s", kind))
+
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if !isModified(current, desired) {
+		return nil
+	}
+
+	m.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("updating the %s", kind))
+
+	// Labels not managed by the operator, e.g. added by other tooling, are
+	// kept.
+	labels := current.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range desired.GetLabels() {
+		labels[k] = v
+	}
+
+	updated := current.DeepCopy()
+	updated.SetLabels(labels)
+	updated.Object["spec"] = desired.Object["spec"]
+
+	_, err = update(ctx, m.namespace, updated)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	m.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("updated the %s", kind))
+
+	return nil
+}
+
+func (m *Manager) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		err := m.Ensure(context.Background())
+		if err != nil {
+			m.logger.Log("level", "error", "message", "failed syncing the monitoring objects", "stack", fmt.Sprintf("%#v", err))
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// isModified returns whether the spec or the labels of the current object
+// differ from the desired ones.
+func isModified(current, desired *unstructured.Unstructured) bool {
+	if !reflect.DeepEqual(current.Object["spec"], desired.Object["spec"]) {
+		return true
+	}
+
+	labels := current.GetLabels()
+	for k, v := range desired.GetLabels() {
+		if labels[k] != v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,198 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// testClient stores the objects in memory. Objects are JSON round-tripped, as
+// they would be by the Kubernetes API.
+type testClient struct {
+	objects map[string]*unstructured.Unstructured
+	updates int
+}
+
+func newTestClient() *testClient {
+	return &testClient{
+		objects: map[string]*unstructured.Unstructured{},
+	}
+}
+
+func (c *testClient) CreatePrometheusRule(ctx context.Context, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return c.put("PrometheusRule", obj), nil
+}
+
+func (c *testClient) CreateServiceMonitor(ctx context.Context, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return c.put("ServiceMonitor", obj), nil
+}
+
+func (c *testClient) GetPrometheusRule(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	return c.get("PrometheusRule", name)
+}
+
+func (c *testClient) GetServiceMonitor(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	return c.get("ServiceMonitor", name)
+}
+
+func (c *testClient) UpdatePrometheusRule(ctx context.Context, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	c.updates++
+	return c.put("PrometheusRule", obj), nil
+}
+
+func (c *testClient) UpdateServiceMonitor(ctx context.Context, namespace string, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	c.updates++
+	return c.put("ServiceMonitor", obj), nil
+}
+
+func (c *testClient) get(kind, name string) (*unstructured.Unstructured, error) {
+	obj, ok := c.objects[kind+"/"+name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{Group: "monitoring.coreos.com", Resource: kind}, name)
+	}
+
+	return roundTrip(obj), nil
+}
+
+func (c *testClient) put(kind string, obj *unstructured.Unstructured) *unstructured.Unstructured {
+	c.objects[kind+"/"+obj.GetName()] = roundTrip(obj)
+	return obj
+}
+
+func roundTrip(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	b, err := json.Marshal(obj.Object)
+	if err != nil {
+		panic(err)
+	}
+
+	decoded := &unstructured.Unstructured{}
+	err = decoded.UnmarshalJSON(b)
+	if err != nil {
+		panic(err)
+	}
+
+	return decoded
+}
+
+func Test_Monitoring_ParseLabels(t *testing.T) {
+	testCases := []struct {
+		List         []string
+		Expected     map[string]string
+		ErrorMatcher func(error) bool
+	}{
+		// Test 0 ensures labels are parsed.
+		{
+			List:         []string{"release=prometheus", " team = ingress "},
+			Expected:     map[string]string{"release": "prometheus", "team": "ingress"},
+			ErrorMatcher: nil,
+		},
+
+		// Test 1 ensures labels without value separator are rejected.
+		{
+			List:         []string{"release"},
+			ErrorMatcher: IsInvalidConfig,
+		},
+
+		// Test 2 ensures labels without key are rejected.
+		{
+			List:         []string{"=prometheus"},
+			ErrorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		labels, err := ParseLabels(tc.List)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if tc.ErrorMatcher == nil && len(labels) != len(tc.Expected) {
+			t.Fatal("test", i, "expected", tc.Expected, "got", labels)
+		}
+		for k, v := range tc.Expected {
+			if labels[k] != v {
+				t.Fatal("test", i, "expected", v, "got", labels[k])
+			}
+		}
+	}
+}
+
+func Test_Monitoring_Ensure(t *testing.T) {
+	ctx := context.TODO()
+	client := newTestClient()
+
+	c := DefaultConfig()
+
+	c.K8sClient = client
+	c.Logger = microloggertest.New()
+
+	c.Labels = map[string]string{"release": "prometheus"}
+	c.Namespace = "giantswarm"
+	c.ProjectName = "ingress-operator"
+
+	m, err := New(c)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = m.Ensure(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	for _, kind := range []string{"PrometheusRule", "ServiceMonitor"} {
+		obj, err := client.get(kind, "ingress-operator")
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if obj.GetLabels()["release"] != "prometheus" || obj.GetLabels()["app"] != "ingress-operator" {
+			t.Fatal("expected", "labels app and release", "got", obj.GetLabels())
+		}
+	}
+
+	// Objects in sync must not be updated.
+	err = m.Ensure(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if client.updates != 0 {
+		t.Fatal("expected", 0, "got", client.updates)
+	}
+
+	// Drifted objects must be updated, keeping labels added by others.
+	obj, err := client.get("ServiceMonitor", "ingress-operator")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	obj.Object["spec"] = map[string]interface{}{}
+	labels := obj.GetLabels()
+	labels["owner"] = "someone"
+	obj.SetLabels(labels)
+	client.put("ServiceMonitor", obj)
+
+	err = m.Ensure(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if client.updates != 1 {
+		t.Fatal("expected", 1, "got", client.updates)
+	}
+
+	obj, err = client.get("ServiceMonitor", "ingress-operator")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if obj.GetLabels()["owner"] != "someone" {
+		t.Fatal("expected", "someone", "got", obj.GetLabels()["owner"])
+	}
+	if isModified(obj, m.newServiceMonitor()) {
+		t.Fatal("expected", false, "got", true)
+	}
+}
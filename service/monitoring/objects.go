@@ -0,0 +1,121 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+// newServiceMonitor returns the ServiceMonitor scraping the metrics endpoint
+// of the service of the operator. The spec only consists of strings, maps and
+// slices, so that it compares equal to the spec decoded from the API.
+func (m *Manager) newServiceMonitor() *unstructured.Unstructured {
+	obj := m.newObject("ServiceMonitor")
+
+	obj.Object["spec"] = map[string]interface{}{
+		"endpoints": []interface{}{
+			map[string]interface{}{
+				"path": "/metrics",
+				"port": "http",
+			},
+		},
+		"namespaceSelector": map[string]interface{}{
+			"matchNames": []interface{}{
+				m.namespace,
+			},
+		},
+		"selector": map[string]interface{}{
+			"matchLabels": map[string]interface{}{
+				"app": m.projectName,
+			},
+		},
+	}
+
+	return obj
+}
+
+// newPrometheusRule returns the PrometheusRule holding the recommended alerts
+// of the operator.
+func (m *Manager) newPrometheusRule() *unstructured.Unstructured {
+	obj := m.newObject("PrometheusRule")
+
+	threshold := strconv.FormatFloat(m.poolUtilizationThreshold, 'f', -1, 64)
+
+	obj.Object["spec"] = map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name": m.projectName,
+				"rules": []interface{}{
+					newAlert(
+						"IngressOperatorDriftCorrected",
+						`sum(increase({__name__=~"ingress_operator_(config_map|service)_corrections_total"}[1h])) > 10`,
+						"30m",
+						"Host cluster ingress controller objects keep drifting from the state the operator desires. Something else keeps changing them.",
+					),
+					newAlert(
+						"IngressOperatorAllocationConflict",
+						`sum(increase(ingress_operator_ledger_allocation_conflicts_total[15m])) by (cluster_id) > 0`,
+						"15m",
+						"IngressConfig of guest cluster {{ $labels.cluster_id }} requests LB ports allocated to another guest cluster.",
+					),
+					newAlert(
+						"IngressOperatorDeletionBlocked",
+						`max(ingress_operator_deletion_blocked_seconds) by (cluster_id) > 0`,
+						"5m",
+						"Deletion of guest cluster {{ $labels.cluster_id }} is blocked by pods remaining in its namespace for longer than the SLA. The DeletionBlocked event of its IngressConfig names the pods.",
+					),
+					newRecord(
+						"ingress_operator_service:port_mutations:increase1d",
+						`sum(increase(ingress_operator_service_port_mutations_total[1d])) by (namespace, service)`,
+					),
+					newAlert(
+						"IngressOperatorPortPoolExhausted",
+						fmt.Sprintf(`max(ingress_operator_port_pool_utilization_ratio) >= %s`, threshold),
+						"15m",
+						fmt.Sprintf("The LB port pool is utilized to {{ $value }}, which reaches the threshold of %s. Provision another ingress controller and port range.", threshold),
+					),
+				},
+			},
+		},
+	}
+
+	return obj
+}
+
+func (m *Manager) newObject(kind string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+
+	obj.SetAPIVersion(clients.MonitoringGroupVersion.String())
+	obj.SetKind(kind)
+	obj.SetName(m.projectName)
+	obj.SetNamespace(m.namespace)
+	obj.SetLabels(m.labels)
+
+	return obj
+}
+
+// newRecord returns a recording rule, which precomputes expensive
+// expressions, e.g. ones over a day of samples.
+func newRecord(name, expr string) map[string]interface{} {
+	return map[string]interface{}{
+		"expr":   expr,
+		"record": name,
+	}
+}
+
+func newAlert(name, expr, duration, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"alert": name,
+		"annotations": map[string]interface{}{
+			"description": description,
+		},
+		"expr": expr,
+		"for":  duration,
+		"labels": map[string]interface{}{
+			"severity": "notify",
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package pool
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "port_pool"
+)
+
+var (
+	allocatedGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "allocated",
+			Help:      "A gauge metric expressing the number of LB ports recorded in the allocation ledger.",
+		},
+	)
+	allocatedByClusterAgeGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "allocated_by_cluster_age",
+			Help:      "A gauge metric expressing the number of LB ports recorded in the allocation ledger by the age of their guest cluster's first allocation, bucketed by the maximum age.",
+		},
+		[]string{"age"},
+	)
+	allocatedByProtocolGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "allocated_by_protocol",
+			Help:      "A gauge metric expressing the number of LB ports recorded in the allocation ledger by protocol. External allocations have the protocol external.",
+		},
+		[]string{"protocol"},
+	)
+	capacityGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "capacity",
+			Help:      "A gauge metric expressing the number of available LB ports which are not reserved.",
+		},
+	)
+	thresholdCrossingsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "threshold_crossings_total",
+			Help:      "A counter metric expressing the number of times the utilization of the port pool crossed a threshold upwards.",
+		},
+		[]string{"threshold"},
+	)
+	utilizationGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "utilization_ratio",
+			Help:      "A gauge metric expressing the fraction of the capacity of the port pool being allocated.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(allocatedGauge)
+	prometheus.MustRegister(allocatedByClusterAgeGauge)
+	prometheus.MustRegister(allocatedByProtocolGauge)
+	prometheus.MustRegister(capacityGauge)
+	prometheus.MustRegister(thresholdCrossingsCounter)
+	prometheus.MustRegister(utilizationGauge)
+}
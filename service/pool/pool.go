@@ -0,0 +1,297 @@
+// Package pool implements the monitoring of the utilization of the LB port
+// pool. Fleet automation can use the emitted metrics and events to provision
+// another ingress controller and port range before the pool runs out of ports.
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+const (
+	// EventReason is the reason of the events raised when the utilization of
+	// the port pool crosses a threshold.
+	EventReason = "PortPoolUtilizationHigh"
+	// ExternalProtocol is the protocol label of external allocations, which
+	// have no protocol.
+	ExternalProtocol = "external"
+)
+
+// ageBucket is a bucket of the age of guest clusters. Guest clusters are
+// counted in the first bucket whose maximum age they do not exceed.
+type ageBucket struct {
+	Label  string
+	MaxAge time.Duration
+}
+
+var (
+	// ageBuckets are the buckets of the age of guest clusters. The last bucket
+	// has no maximum age.
+	ageBuckets = []ageBucket{
+		{Label: "1d", MaxAge: 24 * time.Hour},
+		{Label: "7d", MaxAge: 7 * 24 * time.Hour},
+		{Label: "30d", MaxAge: 30 * 24 * time.Hour},
+		{Label: "90d", MaxAge: 90 * 24 * time.Hour},
+		{Label: "365d", MaxAge: 365 * 24 * time.Hour},
+		{Label: "older", MaxAge: 0},
+	}
+
+	// now returns the current time. It is replaced in tests.
+	now = time.Now
+)
+
+// ParseThresholds parses the given list of utilization thresholds as used for
+// the configuration of the monitor, e.g. "0.8,0.95".
+func ParseThresholds(list []string) ([]float64, error) {
+	var thresholds []float64
+	for _, s := range list {
+		t, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "threshold must be a number, got %q", s)
+		}
+		thresholds = append(thresholds, t)
+	}
+
+	return thresholds, nil
+}
+
+// Config represents the configuration used to create a new monitor.
+type Config struct {
+	// Dependencies.
+	Allocator *allocator.Allocator
+	K8sClient clients.Interface
+	Ledger    *ledger.Ledger
+	Logger    micrologger.Logger
+
+	// Settings.
+
+	// ProjectName is the name of the operator reported as the source of the
+	// raised events.
+	ProjectName string
+	// Thresholds are the utilization fractions, e.g. 0.8 and 0.95, which raise
+	// an event when being crossed upwards.
+	Thresholds []float64
+}
+
+// Monitor observes the utilization of the port pool based on the allocations
+// recorded in the ledger.
+type Monitor struct {
+	// Dependencies.
+	allocator *allocator.Allocator
+	k8sClient clients.Interface
+	ledger    *ledger.Ledger
+	logger    micrologger.Logger
+
+	// Internals.
+	level     int
+	mutex     sync.Mutex
+	protocols map[string]bool
+
+	// Settings.
+	projectName string
+	thresholds  []float64
+}
+
+// New creates a new configured monitor.
+func New(config Config) (*Monitor, error) {
+	// Dependencies.
+	if config.Allocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Allocator must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Ledger must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	// Settings.
+	if config.ProjectName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ProjectName must not be empty", config)
+	}
+	for _, t := range config.Thresholds {
+		if t <= 0 || t > 1 {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Thresholds must only contain fractions between 0 and 1, got %v", config, t)
+		}
+	}
+
+	thresholds := append([]float64{}, config.Thresholds...)
+	sort.Float64s(thresholds)
+
+	m := &Monitor{
+		// Dependencies.
+		allocator: config.Allocator,
+		k8sClient: config.K8sClient,
+		ledger:    config.Ledger,
+		logger:    config.Logger,
+
+		// Internals.
+		level:     0,
+		mutex:     sync.Mutex{},
+		protocols: map[string]bool{},
+
+		// Settings.
+		projectName: config.ProjectName,
+		thresholds:  thresholds,
+	}
+
+	return m, nil
+}
+
+// Observe computes the current utilization of the port pool and updates the
+// metrics accordingly. Crossing a threshold upwards raises a warning event on
+// the ledger config map. Dropping below a threshold rearms it.
+func (m *Monitor) Observe(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	allocations, err := m.ledger.List(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var ports []int
+	for _, a := range allocations {
+		ports = append(ports, a.LBPort)
+	}
+
+	utilization := m.allocator.Utilization(ports)
+
+	allocatedGauge.Set(float64(len(ports)))
+	capacityGauge.Set(float64(m.allocator.Capacity()))
+	utilizationGauge.Set(utilization)
+
+	for protocol, n := range byProtocol(allocations, m.protocols) {
+		allocatedByProtocolGauge.WithLabelValues(protocol).Set(float64(n))
+	}
+	for age, n := range byClusterAge(allocations, now()) {
+		allocatedByClusterAgeGauge.WithLabelValues(age).Set(float64(n))
+	}
+
+	level := m.levelOf(utilization)
+	if level > m.level {
+		threshold := m.thresholds[level-1]
+		message := fmt.Sprintf("utilization of the port pool is %.2f and crossed the threshold of %.2f", utilization, threshold)
+
+		m.logger.LogCtx(ctx, "level", "warning", "message", message)
+		thresholdCrossingsCounter.WithLabelValues(strconv.FormatFloat(threshold, 'f', -1, 64)).Inc()
+
+		_, err := m.k8sClient.CreateEvent(ctx, m.ledger.Namespace(), m.newEvent(message))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+	m.level = level
+
+	return nil
+}
+
+// byProtocol returns the number of the given allocations by protocol. The
+// given protocols seen before are returned with zero allocations in case none
+// are left, so that their gauges are reset. Newly seen protocols are added to
+// them.
+func byProtocol(allocations []ledger.Allocation, seen map[string]bool) map[string]int {
+	counts := map[string]int{}
+	for p := range seen {
+		counts[p] = 0
+	}
+
+	for _, a := range allocations {
+		protocol := a.Protocol
+		if a.External {
+			protocol = ExternalProtocol
+		}
+		counts[protocol]++
+		seen[protocol] = true
+	}
+
+	return counts
+}
+
+// byClusterAge returns the number of the given allocations by the age bucket
+// of their guest cluster at the given time. The age of a guest cluster is the
+// age of its first allocation, since the ledger does not know the creation of
+// guest clusters.
+func byClusterAge(allocations []ledger.Allocation, t time.Time) map[string]int {
+	firstAllocatedAt := map[string]time.Time{}
+	for _, a := range allocations {
+		f, ok := firstAllocatedAt[a.ClusterID]
+		if !ok || a.AllocatedAt.Before(f) {
+			firstAllocatedAt[a.ClusterID] = a.AllocatedAt
+		}
+	}
+
+	counts := map[string]int{}
+	for _, b := range ageBuckets {
+		counts[b.Label] = 0
+	}
+
+	for _, a := range allocations {
+		age := t.Sub(firstAllocatedAt[a.ClusterID])
+		for _, b := range ageBuckets {
+			if b.MaxAge == 0 || age <= b.MaxAge {
+				counts[b.Label]++
+				break
+			}
+		}
+	}
+
+	return counts
+}
+
+// levelOf returns the number of thresholds the given utilization reached.
+func (m *Monitor) levelOf(utilization float64) int {
+	var level int
+	for _, t := range m.thresholds {
+		if utilization >= t {
+			level++
+		}
+	}
+
+	return level
+}
+
+func (m *Monitor) newEvent(message string) *corev1.Event {
+	now := metav1.NewTime(time.Now())
+
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", m.ledger.Name(), now.UnixNano()),
+			Namespace: m.ledger.Namespace(),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Name:       m.ledger.Name(),
+			Namespace:  m.ledger.Namespace(),
+		},
+		Reason:  EventReason,
+		Message: message,
+		Source: corev1.EventSource{
+			Component: m.projectName,
+		},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           corev1.EventTypeWarning,
+	}
+
+	return event
+}
@@ -0,0 +1,196 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+func Test_Pool_Observe(t *testing.T) {
+	var err error
+
+	k8sClient := fake.NewSimpleClientset()
+
+	var clientsInterface clients.Interface
+	{
+		clientsInterface, err = clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var available []int
+	for p := 31000; p < 31010; p++ {
+		available = append(available, p)
+	}
+
+	var portAllocator *allocator.Allocator
+	{
+		portAllocator, err = allocator.New(allocator.Config{Available: available})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var allocationLedger *ledger.Ledger
+	{
+		c := ledger.Config{
+			K8sClient: clientsInterface,
+			Logger:    microloggertest.New(),
+
+			Name:      "ingress-operator-allocations",
+			Namespace: "kube-system",
+		}
+
+		allocationLedger, err = ledger.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var monitor *Monitor
+	{
+		c := Config{
+			Allocator: portAllocator,
+			K8sClient: clientsInterface,
+			Ledger:    allocationLedger,
+			Logger:    microloggertest.New(),
+
+			ProjectName: "ingress-operator",
+			Thresholds:  []float64{0.95, 0.8},
+		}
+
+		monitor, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	testCases := []struct {
+		Allocated      int
+		ExpectedEvents int
+	}{
+		// Test 0 ensures no event is raised below the thresholds.
+		{
+			Allocated:      5,
+			ExpectedEvents: 0,
+		},
+
+		// Test 1 ensures crossing the first threshold raises an event.
+		{
+			Allocated:      8,
+			ExpectedEvents: 1,
+		},
+
+		// Test 2 ensures staying above a threshold does not raise another event.
+		{
+			Allocated:      9,
+			ExpectedEvents: 1,
+		},
+
+		// Test 3 ensures crossing the second threshold raises an event.
+		{
+			Allocated:      10,
+			ExpectedEvents: 2,
+		},
+
+		// Test 4 ensures dropping below the thresholds does not raise an event.
+		{
+			Allocated:      5,
+			ExpectedEvents: 2,
+		},
+
+		// Test 5 ensures crossing a threshold again raises an event.
+		{
+			Allocated:      8,
+			ExpectedEvents: 3,
+		},
+	}
+
+	for i, tc := range testCases {
+		var allocations []ledger.Allocation
+		for _, p := range available[:tc.Allocated] {
+			allocations = append(allocations, ledger.Allocation{LBPort: p, IngressPort: 30010, Protocol: "http"})
+		}
+
+		err = allocationLedger.Record(context.TODO(), "al9qy", allocations)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		err = monitor.Observe(context.TODO())
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		list, err := k8sClient.CoreV1().Events("kube-system").List(metav1.ListOptions{})
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if len(list.Items) != tc.ExpectedEvents {
+			t.Fatal("test", i, "expected", tc.ExpectedEvents, "got", len(list.Items))
+		}
+	}
+}
+
+func Test_Pool_New_InvalidThreshold(t *testing.T) {
+	_, err := ParseThresholds([]string{"0.8", "high"})
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	thresholds, err := ParseThresholds([]string{"0.8", " 0.95"})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(thresholds) != 2 || thresholds[1] != 0.95 {
+		t.Fatal("expected", []float64{0.8, 0.95}, "got", thresholds)
+	}
+}
+
+func Test_Pool_distributions(t *testing.T) {
+	t0 := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	allocations := []ledger.Allocation{
+		// The guest cluster al9qy is 40 days old, the age of its first
+		// allocation, although its second allocation is recent.
+		{ClusterID: "al9qy", LBPort: 31000, Protocol: "http", AllocatedAt: t0.Add(-40 * 24 * time.Hour)},
+		{ClusterID: "al9qy", LBPort: 31001, Protocol: "https", AllocatedAt: t0.Add(-time.Hour)},
+		{ClusterID: "p1l6x", LBPort: 31002, Protocol: "http", AllocatedAt: t0.Add(-2 * time.Hour)},
+		{ClusterID: "x7a2b", LBPort: 31003, External: true, AllocatedAt: t0.Add(-400 * 24 * time.Hour)},
+	}
+
+	seen := map[string]bool{"tcp": true}
+	protocols := byProtocol(allocations, seen)
+	expectedProtocols := map[string]int{"external": 1, "http": 2, "https": 1, "tcp": 0}
+	if len(protocols) != len(expectedProtocols) {
+		t.Fatal("expected", expectedProtocols, "got", protocols)
+	}
+	for p, n := range expectedProtocols {
+		if protocols[p] != n {
+			t.Fatal("expected", n, "got", protocols[p], "for protocol", p)
+		}
+		if !seen[p] {
+			t.Fatal("expected", true, "got", false, "for protocol", p)
+		}
+	}
+
+	ages := byClusterAge(allocations, t0)
+	expectedAges := map[string]int{"1d": 1, "7d": 0, "30d": 0, "90d": 2, "365d": 0, "older": 1}
+	if len(ages) != len(expectedAges) {
+		t.Fatal("expected", expectedAges, "got", ages)
+	}
+	for a, n := range expectedAges {
+		if ages[a] != n {
+			t.Fatal("expected", n, "got", ages[a], "for age", a)
+		}
+	}
+}
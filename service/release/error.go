@@ -0,0 +1,21 @@
+package release
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var portOwnedError = &microerror.Error{
+	Kind: "portOwnedError",
+}
+
+// IsPortOwned asserts portOwnedError.
+func IsPortOwned(err error) bool {
+	return microerror.Cause(err) == portOwnedError
+}
@@ -0,0 +1,277 @@
+// Package release implements the forced release of leaked LB port
+// allocations. An allocation leaks in case the IngressConfig owning it is gone
+// while its entries are left behind, e.g. because the IngressConfig got
+// deleted while the operator was not running. Releasing the LB port removes it
+// from the ledger and from the config maps and services of the shared ingress
+// controllers, which otherwise requires manual surgery on resources shared by
+// all guest clusters.
+package release
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/pin"
+	serviceresource "github.com/giantswarm/ingress-operator/service/controller/v2/resource/service"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+	"github.com/giantswarm/ingress-operator/service/locator"
+	"github.com/giantswarm/ingress-operator/service/servicepolicy"
+)
+
+// Lister lists IngressConfigs. It is implemented by the IngressConfig lister,
+// which looks up the IngressConfigs requesting an LB port in its index.
+type Lister interface {
+	ListIngressConfigs(ctx context.Context, namespace string) (*v1alpha1.IngressConfigList, error)
+	ListIngressConfigsByLBPort(ctx context.Context, lbPort int) (*v1alpha1.IngressConfigList, error)
+}
+
+// Config represents the configuration used to create a new releaser.
+type Config struct {
+	Allocator *allocator.Allocator
+	G8sClient Lister
+	K8sClient clients.Interface
+	Ledger    *ledger.Ledger
+	// Locator discovers the config maps and services of shared ingress
+	// controllers by label selectors instead of using the names referenced by
+	// IngressConfigs. It is optional.
+	Locator *locator.Locator
+	Logger  micrologger.Logger
+	// ServicePolicy is the policy of the service spec fields the operator must
+	// preserve verbatim, the same as for the service resource.
+	ServicePolicy servicepolicy.Policy
+}
+
+// Releaser releases leaked LB port allocations.
+type Releaser struct {
+	allocator     *allocator.Allocator
+	g8sClient     Lister
+	k8sClient     clients.Interface
+	ledger        *ledger.Ledger
+	locator       *locator.Locator
+	logger        micrologger.Logger
+	servicePolicy servicepolicy.Policy
+}
+
+// Result describes a released LB port.
+type Result struct {
+	// ClusterID is the guest cluster the LB port was allocated to according to
+	// the ledger. It is empty in case the ledger did not record the LB port.
+	ClusterID string `json:"clusterID"`
+	// ConfigMaps are the config maps the LB port got removed from, in the
+	// format namespace/name.
+	ConfigMaps []string `json:"configMaps"`
+	LBPort     int      `json:"lbPort"`
+	// Services are the services the LB port got removed from, in the format
+	// namespace/name.
+	Services []string `json:"services"`
+}
+
+// New creates a new configured releaser.
+func New(config Config) (*Releaser, error) {
+	if config.Allocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Allocator must not be empty", config)
+	}
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Ledger must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Releaser{
+		allocator:     config.Allocator,
+		g8sClient:     config.G8sClient,
+		k8sClient:     config.K8sClient,
+		ledger:        config.Ledger,
+		locator:       config.Locator,
+		logger:        config.Logger,
+		servicePolicy: config.ServicePolicy,
+	}
+
+	return r, nil
+}
+
+// Release removes the given LB port from the ledger and from the config maps
+// and services of all shared ingress controllers referenced by any
+// IngressConfig. It fails with a port owned error in case an IngressConfig
+// still requests the LB port, since the port would be allocated again by its
+// next reconciliation anyway. Reserved LB ports and the ones IngressConfigs
+// declare as managed by external systems are never released either, since
+// their entries are not leaked but owned by someone else.
+func (r *Releaser) Release(ctx context.Context, port int) (Result, error) {
+	if r.allocator.IsReserved(port) {
+		return Result{}, microerror.Maskf(portOwnedError, "LB port %d is reserved", port)
+	}
+
+	owners, err := r.g8sClient.ListIngressConfigsByLBPort(ctx, port)
+	if err != nil {
+		return Result{}, microerror.Mask(err)
+	}
+	if len(owners.Items) > 0 {
+		customObject := owners.Items[0]
+		return Result{}, microerror.Maskf(portOwnedError, "LB port %d is owned by IngressConfig %s/%s", port, customObject.Namespace, customObject.Name)
+	}
+
+	list, err := r.g8sClient.ListIngressConfigs(ctx, "")
+	if err != nil {
+		return Result{}, microerror.Mask(err)
+	}
+
+	for _, customObject := range list.Items {
+		externalPorts, err := key.ExternalPorts(customObject)
+		if err != nil {
+			return Result{}, microerror.Mask(err)
+		}
+		for _, p := range externalPorts {
+			if p == port {
+				return Result{}, microerror.Maskf(portOwnedError, "LB port %d is managed by an external system according to IngressConfig %s/%s", port, customObject.Namespace, customObject.Name)
+			}
+		}
+	}
+
+	type ingressController struct {
+		ConfigMap string
+		Namespace string
+		Service   string
+	}
+
+	controllers := map[ingressController]struct{}{}
+	for _, customObject := range list.Items {
+		c := ingressController{
+			ConfigMap: key.IngressControllerConfigMap(customObject),
+			Namespace: key.IngressControllerNamespace(customObject),
+			Service:   key.IngressControllerService(customObject),
+		}
+
+		if r.locator != nil {
+			located, err := r.locator.Locate(ctx, c.Namespace)
+			if err != nil {
+				return Result{}, microerror.Mask(err)
+			}
+
+			c.ConfigMap = located.ConfigMap
+			c.Service = located.Service
+		}
+
+		controllers[c] = struct{}{}
+	}
+
+	result := Result{
+		ConfigMaps: []string{},
+		LBPort:     port,
+		Services:   []string{},
+	}
+
+	for c := range controllers {
+		removed, err := r.releaseConfigMap(ctx, c.Namespace, c.ConfigMap, port)
+		if err != nil {
+			return Result{}, microerror.Mask(err)
+		}
+		if removed {
+			result.ConfigMaps = append(result.ConfigMaps, c.Namespace+"/"+c.ConfigMap)
+		}
+
+		removed, err = r.releaseService(ctx, c.Namespace, c.Service, port)
+		if err != nil {
+			return Result{}, microerror.Mask(err)
+		}
+		if removed {
+			result.Services = append(result.Services, c.Namespace+"/"+c.Service)
+		}
+	}
+
+	// The ledger is released last, so that the LB port cannot be allocated to
+	// another guest cluster while it is still part of the shared resources.
+	allocation, ok, err := r.ledger.ReleaseLBPort(ctx, port)
+	if err != nil {
+		return Result{}, microerror.Mask(err)
+	}
+	if ok {
+		result.ClusterID = allocation.ClusterID
+	}
+
+	sort.Strings(result.ConfigMaps)
+	sort.Strings(result.Services)
+
+	r.logger.LogCtx(ctx, "level", "info", "message", fmt.Sprintf("released LB port %d of guest cluster %#q", port, result.ClusterID), "configMaps", fmt.Sprintf("%v", result.ConfigMaps), "services", fmt.Sprintf("%v", result.Services))
+
+	return result, nil
+}
+
+func (r *Releaser) releaseConfigMap(ctx context.Context, namespace, name string, port int) (bool, error) {
+	configMap, err := r.k8sClient.GetConfigMap(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	k := strconv.Itoa(port)
+	_, ok := configMap.Data[k]
+	if !ok {
+		return false, nil
+	}
+	delete(configMap.Data, k)
+
+	_, err = r.k8sClient.UpdateConfigMap(ctx, namespace, configMap)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+func (r *Releaser) releaseService(ctx context.Context, namespace, name string, port int) (bool, error) {
+	service, err := r.k8sClient.GetService(ctx, namespace, name)
+	if clients.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	var removed bool
+	var ports []corev1.ServicePort
+	for _, p := range service.Spec.Ports {
+		if int(p.Port) == port {
+			removed = true
+			continue
+		}
+		ports = append(ports, p)
+	}
+
+	pins := pin.FromAnnotations(service.Annotations)
+	_, pinned := pins[port]
+	if pinned {
+		delete(pins, port)
+		service.Annotations = pin.ToAnnotations(service.Annotations, pins)
+		removed = true
+	}
+
+	if !removed {
+		return false, nil
+	}
+	service.Spec.Ports = ports
+
+	_, err = serviceresource.WriteService(ctx, r.k8sClient, r.logger, r.servicePolicy, namespace, service)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,303 @@
+package release
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/pin"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+// testIngressConfigs is an in-memory implementation of Lister.
+type testIngressConfigs struct {
+	*clientstest.IngressConfigs
+}
+
+func (c *testIngressConfigs) ListIngressConfigsByLBPort(ctx context.Context, lbPort int) (*v1alpha1.IngressConfigList, error) {
+	all, err := c.ListIngressConfigs(ctx, "")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	list := &v1alpha1.IngressConfigList{}
+	for _, item := range all.Items {
+		for _, p := range key.LBPorts(item) {
+			if p == lbPort {
+				list.Items = append(list.Items, item)
+			}
+		}
+	}
+
+	return list, nil
+}
+
+func Test_Release_Release(t *testing.T) {
+	testCases := []struct {
+		Port                 int
+		ExpectedResult       Result
+		ExpectedConfigMap    map[string]string
+		ExpectedServicePorts []int32
+		ExpectedPins         map[int]string
+		ErrorMatcher         func(error) bool
+	}{
+		// Test 0 ensures LB ports owned by an IngressConfig are not released.
+		{
+			Port: 31000,
+			ExpectedConfigMap: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31001": "p1l6x/worker:30010",
+				"31003": "kube-system/reserved:80",
+				"31004": "al9qy/external:30020",
+			},
+			ExpectedServicePorts: []int32{31000, 31001, 31003, 31004},
+			ExpectedPins: map[int]string{
+				31001: "p1l6x",
+			},
+			ErrorMatcher: IsPortOwned,
+		},
+
+		// Test 1 ensures leaked LB ports are removed from the config map, the
+		// service, its pins and the ledger.
+		{
+			Port: 31001,
+			ExpectedResult: Result{
+				ClusterID:  "p1l6x",
+				ConfigMaps: []string{"kube-system/ingress-controller"},
+				LBPort:     31001,
+				Services:   []string{"kube-system/ingress-controller"},
+			},
+			ExpectedConfigMap: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31003": "kube-system/reserved:80",
+				"31004": "al9qy/external:30020",
+			},
+			ExpectedServicePorts: []int32{31000, 31003, 31004},
+			ExpectedPins:         map[int]string{},
+			ErrorMatcher:         nil,
+		},
+
+		// Test 2 ensures releasing LB ports which are not in use anywhere
+		// succeeds without changes.
+		{
+			Port: 31002,
+			ExpectedResult: Result{
+				ConfigMaps: []string{},
+				LBPort:     31002,
+				Services:   []string{},
+			},
+			ExpectedConfigMap: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31003": "kube-system/reserved:80",
+				"31004": "al9qy/external:30020",
+			},
+			ExpectedServicePorts: []int32{31000, 31003, 31004},
+			ExpectedPins:         map[int]string{},
+			ErrorMatcher:         nil,
+		},
+
+		// Test 3 ensures reserved LB ports are not released.
+		{
+			Port: 31003,
+			ExpectedConfigMap: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31003": "kube-system/reserved:80",
+				"31004": "al9qy/external:30020",
+			},
+			ExpectedServicePorts: []int32{31000, 31003, 31004},
+			ExpectedPins:         map[int]string{},
+			ErrorMatcher:         IsPortOwned,
+		},
+
+		// Test 4 ensures LB ports managed by external systems are not released.
+		{
+			Port: 31004,
+			ExpectedConfigMap: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31003": "kube-system/reserved:80",
+				"31004": "al9qy/external:30020",
+			},
+			ExpectedServicePorts: []int32{31000, 31003, 31004},
+			ExpectedPins:         map[int]string{},
+			ErrorMatcher:         IsPortOwned,
+		},
+	}
+
+	ctx := context.TODO()
+
+	var err error
+
+	var k8sClient clients.Interface
+	{
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-controller",
+				Namespace: "kube-system",
+			},
+			Data: map[string]string{
+				"31000": "al9qy/worker:30010",
+				"31001": "p1l6x/worker:30010",
+				"31003": "kube-system/reserved:80",
+				"31004": "al9qy/external:30020",
+			},
+		}
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: pin.ToAnnotations(nil, map[int]string{31001: "p1l6x"}),
+				Name:        "ingress-controller",
+				Namespace:   "kube-system",
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{Name: "http-30010-al9qy", Port: 31000},
+					{Name: "http-30010-p1l6x", Port: 31001},
+					{Name: "reserved", Port: 31003},
+					{Name: "http-30020-al9qy", Port: 31004},
+				},
+			},
+		}
+
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset(configMap, service)})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var l *ledger.Ledger
+	{
+		c := ledger.Config{
+			K8sClient: k8sClient,
+			Logger:    microloggertest.New(),
+
+			Name:      "ingress-operator-allocations",
+			Namespace: "kube-system",
+		}
+
+		l, err = ledger.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		err = l.Record(ctx, "al9qy", []ledger.Allocation{{IngressPort: 30010, LBPort: 31000, Protocol: "http"}})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		err = l.Record(ctx, "p1l6x", []ledger.Allocation{{IngressPort: 30010, LBPort: 31001, Protocol: "http"}})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var newReleaser *Releaser
+	{
+		g8sClient := &testIngressConfigs{
+			IngressConfigs: clientstest.NewIngressConfigs(
+				v1alpha1.IngressConfig{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							key.ExternalPortsAnnotation: "31004",
+						},
+						Name:      "al9qy",
+						Namespace: "default",
+					},
+					Spec: v1alpha1.IngressConfigSpec{
+						GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+							ID:        "al9qy",
+							Namespace: "al9qy",
+							Service:   "worker",
+						},
+						HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+							IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+								ConfigMap: "ingress-controller",
+								Namespace: "kube-system",
+								Service:   "ingress-controller",
+							},
+						},
+						ProtocolPorts: []v1alpha1.IngressConfigSpecProtocolPort{
+							{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+						},
+					},
+				},
+			),
+		}
+
+		portAllocator, err := allocator.New(allocator.Config{
+			Available: []int{31000, 31001, 31002, 31003, 31004},
+			Reserved:  []int{31003},
+		})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := Config{
+			Allocator: portAllocator,
+			G8sClient: g8sClient,
+			K8sClient: k8sClient,
+			Ledger:    l,
+			Logger:    microloggertest.New(),
+		}
+
+		newReleaser, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	for i, tc := range testCases {
+		result, err := newReleaser.Release(ctx, tc.Port)
+		if err != nil && tc.ErrorMatcher == nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if tc.ErrorMatcher != nil && !tc.ErrorMatcher(err) {
+			t.Fatal("test", i, "expected", true, "got", false)
+		}
+		if tc.ErrorMatcher == nil && !reflect.DeepEqual(result, tc.ExpectedResult) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedResult, result)
+		}
+
+		configMap, err := k8sClient.GetConfigMap(ctx, "kube-system", "ingress-controller")
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(configMap.Data, tc.ExpectedConfigMap) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedConfigMap, configMap.Data)
+		}
+
+		service, err := k8sClient.GetService(ctx, "kube-system", "ingress-controller")
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		var ports []int32
+		for _, p := range service.Spec.Ports {
+			ports = append(ports, p.Port)
+		}
+		if !reflect.DeepEqual(ports, tc.ExpectedServicePorts) {
+			t.Fatal("test", i, "expected", tc.ExpectedServicePorts, "got", ports)
+		}
+		pins := pin.FromAnnotations(service.Annotations)
+		if !reflect.DeepEqual(pins, tc.ExpectedPins) {
+			t.Fatal("test", i, "expected", tc.ExpectedPins, "got", pins)
+		}
+
+		allocations, err := l.List(ctx)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+		for _, a := range allocations {
+			if a.LBPort == tc.Port && tc.ErrorMatcher == nil {
+				t.Fatal("test", i, "expected", "released LB port", "got", a)
+			}
+		}
+	}
+}
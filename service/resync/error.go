@@ -0,0 +1,21 @@
+package resync
+
+import "github.com/giantswarm/microerror"
+
+var invalidConfigError = &microerror.Error{
+	Kind: "invalidConfigError",
+}
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return microerror.Cause(err) == invalidConfigError
+}
+
+var resyncInProgressError = &microerror.Error{
+	Kind: "resyncInProgressError",
+}
+
+// IsResyncInProgress asserts resyncInProgressError.
+func IsResyncInProgress(err error) bool {
+	return microerror.Cause(err) == resyncInProgressError
+}
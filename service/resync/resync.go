@@ -0,0 +1,137 @@
+// Package resync implements the throttled requeueing of all IngressConfigs for
+// reconciliation. After maintenance of the host cluster all guest clusters
+// usually have to be reconciled again. Restarting the operator does that, but
+// reconciles all IngressConfigs at once and stampedes the Kubernetes API. A
+// resync lists the IngressConfigs once and puts them into the queues of the
+// controller at a bounded rate instead.
+package resync
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+)
+
+// Queue is the queue IngressConfigs are put into for reconciliation. It is
+// implemented by the ingress controller.
+type Queue interface {
+	Requeue(ctx context.Context, customObject *v1alpha1.IngressConfig)
+}
+
+// Config represents the configuration used to create a new resyncer.
+type Config struct {
+	G8sClient clients.IngressConfigs
+	Logger    micrologger.Logger
+	Queue     Queue
+
+	// Rate is the maximum number of IngressConfigs requeued per second.
+	Rate int
+}
+
+// Resyncer requeues all IngressConfigs at a bounded rate.
+type Resyncer struct {
+	g8sClient clients.IngressConfigs
+	logger    micrologger.Logger
+	queue     Queue
+
+	mutex   sync.Mutex
+	rate    int
+	running bool
+}
+
+// Result describes a started resync.
+type Result struct {
+	// IngressConfigs is the number of IngressConfigs being requeued.
+	IngressConfigs int `json:"ingressConfigs"`
+	// Rate is the maximum number of IngressConfigs requeued per second.
+	Rate int `json:"rate"`
+}
+
+// New creates a new configured resyncer.
+func New(config Config) (*Resyncer, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Queue == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Queue must not be empty", config)
+	}
+
+	if config.Rate < 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Rate must be greater than 0", config)
+	}
+
+	r := &Resyncer{
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+		queue:     config.Queue,
+
+		mutex:   sync.Mutex{},
+		rate:    config.Rate,
+		running: false,
+	}
+
+	return r, nil
+}
+
+// Start lists all IngressConfigs and requeues them in the background. Only one
+// resync runs at a time. Starting another one while a resync is running
+// returns an error asserted by IsResyncInProgress.
+func (r *Resyncer) Start(ctx context.Context) (Result, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.running {
+		return Result{}, microerror.Maskf(resyncInProgressError, "another resync is still requeueing IngressConfigs")
+	}
+
+	list, err := r.g8sClient.ListIngressConfigs(ctx, "")
+	if err != nil {
+		return Result{}, microerror.Mask(err)
+	}
+
+	r.running = true
+
+	// The resync outlives the request which started it, so it must not use its
+	// context.
+	go r.requeue(context.Background(), list.Items)
+
+	result := Result{
+		IngressConfigs: len(list.Items),
+		Rate:           r.rate,
+	}
+
+	return result, nil
+}
+
+func (r *Resyncer) requeue(ctx context.Context, customObjects []v1alpha1.IngressConfig) {
+	defer func() {
+		r.mutex.Lock()
+		r.running = false
+		r.mutex.Unlock()
+	}()
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("requeueing %d IngressConfigs at %d per second", len(customObjects), r.rate))
+
+	ticker := time.NewTicker(time.Second / time.Duration(r.rate))
+	defer ticker.Stop()
+
+	for i := range customObjects {
+		if i > 0 {
+			<-ticker.C
+		}
+
+		r.queue.Requeue(ctx, &customObjects[i])
+	}
+
+	r.logger.LogCtx(ctx, "level", "debug", "message", fmt.Sprintf("requeued %d IngressConfigs", len(customObjects)))
+}
@@ -0,0 +1,115 @@
+package resync
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+)
+
+// testQueue records the names of the requeued IngressConfigs.
+type testQueue struct {
+	mutex sync.Mutex
+	names []string
+}
+
+func (q *testQueue) Requeue(ctx context.Context, customObject *v1alpha1.IngressConfig) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.names = append(q.names, customObject.Name)
+}
+
+func (q *testQueue) Names() []string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return append([]string{}, q.names...)
+}
+
+func Test_Resyncer_Start(t *testing.T) {
+	ctx := context.TODO()
+
+	var items []v1alpha1.IngressConfig
+	for _, n := range []string{"al9qy", "p1l6x", "xa5ly"} {
+		items = append(items, v1alpha1.IngressConfig{ObjectMeta: metav1.ObjectMeta{Name: n}})
+	}
+	g8sClient := clientstest.NewIngressConfigs(items...)
+	queue := &testQueue{}
+
+	var err error
+
+	var r *Resyncer
+	{
+		c := Config{
+			G8sClient: g8sClient,
+			Logger:    microloggertest.New(),
+			Queue:     queue,
+
+			Rate: 10,
+		}
+
+		r, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	start := time.Now()
+
+	result, err := r.Start(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if result.IngressConfigs != 3 || result.Rate != 10 {
+		t.Fatalf("expected %s got %#v", "3 IngressConfigs at rate 10", result)
+	}
+
+	// Starting another resync while the first one is still running must be
+	// rejected.
+	_, err = r.Start(ctx)
+	if !IsResyncInProgress(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	for len(queue.Names()) != 3 {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("expected", 3, "got", len(queue.Names()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Three IngressConfigs at 10 per second are requeued within two intervals
+	// of 100 milliseconds.
+	if time.Since(start) < 200*time.Millisecond {
+		t.Fatal("expected", "requeueing to be throttled", "got", time.Since(start))
+	}
+
+	names := queue.Names()
+	for i, n := range []string{"al9qy", "p1l6x", "xa5ly"} {
+		if names[i] != n {
+			t.Fatal("test", i, "expected", n, "got", names[i])
+		}
+	}
+}
+
+func Test_Resyncer_New_invalidRate(t *testing.T) {
+	c := Config{
+		G8sClient: clientstest.NewIngressConfigs(),
+		Logger:    microloggertest.New(),
+		Queue:     &testQueue{},
+
+		Rate: 0,
+	}
+
+	_, err := New(c)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
@@ -3,12 +3,20 @@
 package service
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
 	"sync"
+	"time"
 
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
 	"github.com/giantswarm/apiextensions/pkg/clientset/versioned"
+	"github.com/giantswarm/backoff"
 	"github.com/giantswarm/microendpoint/service/version"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
+	"github.com/giantswarm/operatorkit/client/k8scrdclient"
 	"github.com/giantswarm/operatorkit/client/k8srestconfig"
 	"github.com/spf13/viper"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -16,8 +24,36 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/giantswarm/ingress-operator/flag"
+	"github.com/giantswarm/ingress-operator/pkg/project"
+	"github.com/giantswarm/ingress-operator/service/adoption"
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/breaker"
+	"github.com/giantswarm/ingress-operator/service/clients"
 	"github.com/giantswarm/ingress-operator/service/controller"
+	"github.com/giantswarm/ingress-operator/service/controller/pager"
+	"github.com/giantswarm/ingress-operator/service/controller/v2"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/deletiondelay"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/lock"
+	"github.com/giantswarm/ingress-operator/service/crdschema"
+	"github.com/giantswarm/ingress-operator/service/deprecation"
+	"github.com/giantswarm/ingress-operator/service/export"
 	"github.com/giantswarm/ingress-operator/service/healthz"
+	"github.com/giantswarm/ingress-operator/service/history"
+	"github.com/giantswarm/ingress-operator/service/k8stransport"
+	"github.com/giantswarm/ingress-operator/service/kubeconfig"
+	"github.com/giantswarm/ingress-operator/service/lease"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+	"github.com/giantswarm/ingress-operator/service/legacy"
+	"github.com/giantswarm/ingress-operator/service/lister"
+	"github.com/giantswarm/ingress-operator/service/locator"
+	"github.com/giantswarm/ingress-operator/service/monitoring"
+	"github.com/giantswarm/ingress-operator/service/pool"
+	"github.com/giantswarm/ingress-operator/service/release"
+	"github.com/giantswarm/ingress-operator/service/resync"
+	"github.com/giantswarm/ingress-operator/service/servicepolicy"
+	"github.com/giantswarm/ingress-operator/service/simulation"
+	"github.com/giantswarm/ingress-operator/service/startup"
+	"github.com/giantswarm/ingress-operator/service/usage"
 )
 
 type Config struct {
@@ -25,11 +61,6 @@ type Config struct {
 
 	Flag  *flag.Flag
 	Viper *viper.Viper
-
-	Description string
-	GitCommit   string
-	Name        string
-	Source      string
 }
 
 // DefaultConfig provides a default configuration to create a new service by
@@ -40,21 +71,45 @@ func DefaultConfig() Config {
 
 		Flag:  nil,
 		Viper: nil,
-
-		Description: "",
-		GitCommit:   "",
-		Name:        "",
-		Source:      "",
 	}
 }
 
 type Service struct {
-	Healthz *healthz.Service
-	Version *version.Service
+	Export     *export.Exporter
+	Healthz    *healthz.Service
+	History    *history.Recorder
+	Legacy     *legacy.Watcher
+	Ledger     *ledger.Ledger
+	Lister     *lister.Lister
+	Release    *release.Releaser
+	Resync     *resync.Resyncer
+	Simulation *simulation.Simulator
+	Usage      *usage.Reporter
+	Version    *version.Service
 
 	// Internals.
-	bootOnce          sync.Once
-	ingressController *controller.Ingress
+	adopter            *adoption.Adopter
+	adoptionController bootShutdowner
+	booted             bool
+	bridgeController   bootShutdowner
+	// cancel cancels the preparation of a boot in progress, if any.
+	cancel              context.CancelFunc
+	deletionAlerter     bootShutdowner
+	deprecationReporter bootShutdowner
+	ingressConfigLister bootShutdowner
+	ingressController   bootShutdowner
+	logger              micrologger.Logger
+	monitoringManager   bootShutdowner
+	mutex               sync.Mutex
+	schemaGuard         *crdschema.Guard
+	startupReporter     *startup.Reporter
+}
+
+// bootShutdowner is implemented by the long running components the service
+// manages, e.g. the ingress controller.
+type bootShutdowner interface {
+	Boot()
+	Shutdown()
 }
 
 // New creates a new configured service object.
@@ -70,7 +125,20 @@ func New(config Config) (*Service, error) {
 	var err error
 
 	var restConfig *rest.Config
-	{
+	if config.Viper.GetString(config.Flag.Service.Kubernetes.KubeConfig.Path) != "" {
+		c := kubeconfig.Config{
+			Context: config.Viper.GetString(config.Flag.Service.Kubernetes.KubeConfig.Context),
+			Path:    config.Viper.GetString(config.Flag.Service.Kubernetes.KubeConfig.Path),
+		}
+
+		restConfig, err = kubeconfig.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		restConfig.Burst = k8srestconfig.MaxBurst
+		restConfig.QPS = k8srestconfig.MaxQPS
+	} else {
 		c := k8srestconfig.Config{
 			Logger: config.Logger,
 
@@ -89,6 +157,49 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	if config.Viper.GetString(config.Flag.Service.Kubernetes.TokenFile) != "" {
+		restConfig.BearerToken, err = kubeconfig.ReadToken(config.Viper.GetString(config.Flag.Service.Kubernetes.TokenFile))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	{
+		c := k8stransport.Config{
+			DialTimeout:         config.Viper.GetDuration(config.Flag.Service.Kubernetes.Timeout.Dial),
+			KeepAlive:           config.Viper.GetDuration(config.Flag.Service.Kubernetes.KeepAlive),
+			RequestTimeout:      config.Viper.GetDuration(config.Flag.Service.Kubernetes.Timeout.Request),
+			TLSHandshakeTimeout: config.Viper.GetDuration(config.Flag.Service.Kubernetes.Timeout.TLSHandshake),
+		}
+
+		err = k8stransport.Apply(restConfig, c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var writeBreaker *breaker.Breaker
+	{
+		c := breaker.Config{
+			Logger: config.Logger,
+
+			CoolDown:    config.Viper.GetDuration(config.Flag.Service.Kubernetes.Breaker.CoolDown),
+			MinRequests: config.Viper.GetInt(config.Flag.Service.Kubernetes.Breaker.MinRequests),
+			Threshold:   config.Viper.GetFloat64(config.Flag.Service.Kubernetes.Breaker.Threshold),
+			Window:      config.Viper.GetDuration(config.Flag.Service.Kubernetes.Breaker.Window),
+		}
+
+		writeBreaker, err = breaker.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		err = writeBreaker.Apply(restConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	g8sClient, err := versioned.NewForConfig(restConfig)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -108,24 +219,490 @@ func New(config Config) (*Service, error) {
 	{
 		healthzConfig := healthz.DefaultConfig()
 
+		healthzConfig.Breaker = writeBreaker
 		healthzConfig.K8sClient = k8sClient
 		healthzConfig.Logger = config.Logger
 
+		healthzConfig.K8sInterval = config.Viper.GetDuration(config.Flag.Service.Kubernetes.Healthz.Interval)
+		healthzConfig.K8sTimeout = config.Viper.GetDuration(config.Flag.Service.Kubernetes.Healthz.Timeout)
+
 		healthzService, err = healthz.New(healthzConfig)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 	}
 
+	var portAllocator *allocator.Allocator
+	{
+		// Available ports and port ranges are parsed together, so that ports
+		// configured twice are detected as overlap.
+		var list []string
+		list = append(list, config.Viper.GetStringSlice(config.Flag.Service.HostCluster.AvailablePorts)...)
+		list = append(list, config.Viper.GetStringSlice(config.Flag.Service.HostCluster.PortRanges)...)
+
+		available, err := allocator.ParsePortRanges(list)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		reserved, err := allocator.ParsePorts(config.Viper.GetStringSlice(config.Flag.Service.HostCluster.ReservedPorts))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := allocator.Config{
+			Available:  available,
+			CoolingOff: config.Viper.GetDuration(config.Flag.Service.HostCluster.PortCoolingOff),
+			Reserved:   reserved,
+		}
+
+		portAllocator, err = allocator.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var allocationLedger *ledger.Ledger
+	{
+		k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := ledger.Config{
+			K8sClient: k8sClients,
+			Logger:    config.Logger,
+
+			AllowDowngrade: config.Viper.GetBool(config.Flag.Service.HostCluster.Ledger.AllowDowngrade),
+			CoolingOff:     portAllocator.CoolingOff(),
+			Name:           config.Viper.GetString(config.Flag.Service.HostCluster.Ledger.Name),
+			Namespace:      config.Viper.GetString(config.Flag.Service.HostCluster.Ledger.Namespace),
+			Version:        project.Version(),
+		}
+
+		allocationLedger, err = ledger.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The schema guard keeps this version of the operator from stripping the
+	// fields of IngressConfigs a newer IngressConfig CRD introduced.
+	var schemaGuard *crdschema.Guard
+	{
+		restClient, err := clients.NewG8sRESTClient(restConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		g8sClients, err := clients.NewG8sUnstructured(clients.G8sUnstructuredConfig{RESTClient: restClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := crdschema.DefaultConfig()
+
+		c.G8sClient = g8sClients
+		c.K8sExtClient = k8sExtClient
+		c.Logger = config.Logger
+
+		schemaGuard, err = crdschema.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// Ingress controllers limit the IngressConfigs this operator instance
+	// reconciles, so that multiple instances can run per host cluster.
+	ingressControllers := config.Viper.GetStringSlice(config.Flag.Service.Controller.IngressControllers)
+	// Namespaces limit the IngressConfigs honored to the ones of the tenants of
+	// shared host clusters in namespace-scoped mode.
+	namespaces := config.Viper.GetStringSlice(config.Flag.Service.Controller.Namespaces)
+
+	// The IngressConfig lister serves the IngressConfigs looked up by the
+	// components other than the controller from a cache, so that they do not
+	// list all IngressConfigs from the API server each time. The informers of
+	// the controller workers watch its cache as well. In namespace-scoped mode
+	// the cache is populated from the configured namespaces only, one pager per
+	// namespace, instead of watching all namespaces.
+	var ingressConfigLister *lister.Lister
+	{
+		g8sClients, err := clients.NewG8s(clients.G8sConfig{G8sClient: g8sClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		crdClient, err := k8scrdclient.New(k8scrdclient.Config{K8sExtClient: k8sExtClient, Logger: config.Logger})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		watched := namespaces
+		if len(watched) == 0 {
+			watched = []string{""}
+		}
+
+		pagers := map[string]lister.Client{}
+		for _, n := range watched {
+			c := pager.DefaultConfig()
+
+			c.Client = g8sClient.CoreV1alpha1().IngressConfigs(n)
+			c.Logger = config.Logger
+			// The CRD is ensured on boot by the operatorkit controller. It is
+			// ensured again in case it got deleted and recreated afterwards, so
+			// that the lister does not watch a resource which is not registered
+			// until the operator restarts.
+			c.EnsureCRD = func() error {
+				return crdClient.EnsureCreated(context.Background(), v1alpha1.NewIngressConfigCRD(), backoff.NewMaxRetries(7, 1*time.Second))
+			}
+			c.Limit = config.Viper.GetInt64(config.Flag.Service.Controller.ListLimit)
+
+			pagers[n], err = pager.New(c)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+
+		c := lister.Config{
+			Clients:   pagers,
+			G8sClient: schemaGuard.Wrap(g8sClients),
+			Logger:    config.Logger,
+		}
+
+		ingressConfigLister, err = lister.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The adopter looks the IngressConfigs up through the lister, so that only
+	// the IngressConfigs of the configured namespaces are adopted in
+	// namespace-scoped mode.
+	var ledgerAdopter *adoption.Adopter
+	{
+		k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := adoption.Config{
+			G8sClient: ingressConfigLister,
+			K8sClient: k8sClients,
+			Ledger:    allocationLedger,
+			Logger:    config.Logger,
+		}
+
+		ledgerAdopter, err = adoption.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The adoption controller is optional. It is nil in case it is disabled.
+	var adoptionController bootShutdowner
+	if config.Viper.GetBool(config.Flag.Service.Adoption.Enabled) {
+		k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := adoption.DefaultControllerConfig()
+
+		c.G8sClient = ingressConfigLister
+		c.K8sClient = k8sClients
+		c.Ledger = allocationLedger
+		c.Logger = config.Logger
+
+		c.CreateIngressConfigs = config.Viper.GetBool(config.Flag.Service.Adoption.CreateIngressConfigs)
+		c.GuestClusterService = config.Viper.GetString(config.Flag.Service.Adoption.GuestClusterService)
+		c.IngressControllerConfigMap = config.Viper.GetString(config.Flag.Service.Adoption.IngressController.ConfigMap)
+		c.IngressControllerNamespace = config.Viper.GetString(config.Flag.Service.Adoption.IngressController.Namespace)
+		c.IngressControllerService = config.Viper.GetString(config.Flag.Service.Adoption.IngressController.Service)
+		c.Interval = config.Viper.GetDuration(config.Flag.Service.Adoption.Interval)
+		c.Namespace = config.Viper.GetString(config.Flag.Service.Adoption.Namespace)
+		c.VersionBundleVersion = v2.VersionBundle().Version
+
+		adoptionController, err = adoption.NewController(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var poolMonitor *pool.Monitor
+	{
+		k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		thresholds, err := pool.ParseThresholds(config.Viper.GetStringSlice(config.Flag.Service.HostCluster.UtilizationThresholds))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := pool.Config{
+			Allocator: portAllocator,
+			K8sClient: k8sClients,
+			Ledger:    allocationLedger,
+			Logger:    config.Logger,
+
+			ProjectName: project.Name(),
+			Thresholds:  thresholds,
+		}
+
+		poolMonitor, err = pool.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var monitoringManager bootShutdowner
+	if config.Viper.GetBool(config.Flag.Service.Monitoring.Enabled) {
+		restClient, err := clients.NewMonitoringRESTClient(restConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		monitoringClients, err := clients.NewMonitoring(clients.MonitoringConfig{RESTClient: restClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		labels, err := monitoring.ParseLabels(config.Viper.GetStringSlice(config.Flag.Service.Monitoring.Labels))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		thresholds, err := pool.ParseThresholds(config.Viper.GetStringSlice(config.Flag.Service.HostCluster.UtilizationThresholds))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := monitoring.DefaultConfig()
+
+		c.K8sClient = monitoringClients
+		c.Logger = config.Logger
+
+		c.Interval = config.Viper.GetDuration(config.Flag.Service.Monitoring.Interval)
+		c.Labels = labels
+		c.Namespace = config.Viper.GetString(config.Flag.Service.Monitoring.Namespace)
+		c.ProjectName = project.Name()
+
+		// The alert fires at the highest threshold events are raised for, which
+		// is the last chance to act before the pool runs out of ports.
+		if len(thresholds) > 0 {
+			sort.Float64s(thresholds)
+			c.PoolUtilizationThreshold = thresholds[len(thresholds)-1]
+		}
+
+		monitoringManager, err = monitoring.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The locker is optional. It is nil in case writes to shared ingress
+	// controllers are only serialized by retrying conflicts.
+	var locker lock.Locker
+	if config.Viper.GetBool(config.Flag.Service.Controller.Lease.Enabled) {
+		restClient, err := clients.NewCoordinationRESTClient(restConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		coordinationClients, err := clients.NewCoordination(clients.CoordinationConfig{RESTClient: restClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		// The hostname is the name of the pod, which identifies the replica.
+		identity, err := os.Hostname()
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := lease.DefaultConfig()
+
+		c.K8sClient = coordinationClients
+		c.Logger = config.Logger
+
+		c.Duration = config.Viper.GetDuration(config.Flag.Service.Controller.Lease.Duration)
+		c.Identity = identity
+		c.Timeout = config.Viper.GetDuration(config.Flag.Service.Controller.Lease.Timeout)
+
+		locker, err = lease.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var startupReporter *startup.Reporter
+	{
+		c := startup.Config{
+			G8sClient: ingressConfigLister,
+			Logger:    config.Logger,
+
+			Handles: func(customObject v1alpha1.IngressConfig) bool {
+				return v2.Handles(customObject, ingressControllers, namespaces)
+			},
+			Timeout: config.Viper.GetDuration(config.Flag.Service.Controller.StartupReportTimeout),
+		}
+
+		startupReporter, err = startup.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var deprecationReporter *deprecation.Reporter
+	{
+		c := deprecation.Config{
+			G8sClient: ingressConfigLister,
+			Logger:    config.Logger,
+
+			Interval: config.Viper.GetDuration(config.Flag.Service.Controller.DeprecationInterval),
+			Versions: []string{v2.VersionBundle().Version},
+		}
+
+		deprecationReporter, err = deprecation.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The legacy watcher is optional. It is nil in case the remaining legacy
+	// ingresstpr objects are not listed.
+	var legacyWatcher *legacy.Watcher
+	if config.Viper.GetDuration(config.Flag.Service.Controller.LegacyInterval) > 0 {
+		restClient, err := clients.NewLegacyRESTClient(restConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		legacyClients, err := clients.NewLegacy(clients.LegacyConfig{RESTClient: restClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := legacy.Config{
+			K8sClient: legacyClients,
+			Logger:    config.Logger,
+
+			Interval: config.Viper.GetDuration(config.Flag.Service.Controller.LegacyInterval),
+		}
+
+		legacyWatcher, err = legacy.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The deletion alerter is optional. It is nil in case deletions delayed by
+	// remaining pods are not reported as blocked.
+	var deletionAlerter bootShutdowner
+	if config.Viper.GetDuration(config.Flag.Service.Controller.DeletionSLA) > 0 {
+		k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := deletiondelay.DefaultAlerterConfig()
+
+		c.K8sClient = k8sClients
+		c.Logger = config.Logger
+
+		c.ProjectName = project.Name()
+		c.SLA = config.Viper.GetDuration(config.Flag.Service.Controller.DeletionSLA)
+
+		deletionAlerter, err = deletiondelay.NewAlerter(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var historyRecorder *history.Recorder
+	{
+		c := history.Config{
+			Logger: config.Logger,
+		}
+
+		historyRecorder, err = history.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The locator is optional. It is nil in case the config maps and services
+	// of shared ingress controllers are the ones referenced by IngressConfigs.
+	var ingressControllerLocator *locator.Locator
+	{
+		configMapSelector := config.Viper.GetString(config.Flag.Service.HostCluster.IngressController.ConfigMapSelector)
+		serviceSelector := config.Viper.GetString(config.Flag.Service.HostCluster.IngressController.ServiceSelector)
+
+		if configMapSelector != "" || serviceSelector != "" {
+			k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+
+			c := locator.DefaultConfig()
+
+			c.K8sClient = k8sClients
+			c.Logger = config.Logger
+
+			c.ConfigMapSelector = configMapSelector
+			c.ServiceSelector = serviceSelector
+
+			ingressControllerLocator, err = locator.New(c)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+	}
+
+	servicePolicy, err := servicepolicy.New(config.Viper.GetStringSlice(config.Flag.Service.HostCluster.PreservedServiceFields))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	var ingressController *controller.Ingress
 	{
 		c := controller.IngressConfig{
-			G8sClient:    g8sClient,
-			K8sClient:    k8sClient,
-			K8sExtClient: k8sExtClient,
-			Logger:       config.Logger,
-
-			ProjectName: config.Name,
+			Allocator:       portAllocator,
+			G8sClient:       g8sClient,
+			HistoryRecorder: historyRecorder,
+			K8sClient:       k8sClient,
+			K8sExtClient:    k8sExtClient,
+			Ledger:          allocationLedger,
+			Lister:          ingressConfigLister,
+			Locator:         ingressControllerLocator,
+			Locker:          locker,
+			Logger:          config.Logger,
+			Pool:            poolMonitor,
+			SchemaGuard:     schemaGuard,
+			StartupReporter: startupReporter,
+
+			Canary:                                   config.Viper.GetBool(config.Flag.Service.Controller.Canary.Enabled),
+			CanaryDedicatedIngressController:         config.Viper.GetBool(config.Flag.Service.Controller.Canary.DedicatedIngressController),
+			CanaryResourceOrder:                      config.Viper.GetString(config.Flag.Service.Controller.Canary.ResourceOrder),
+			CanarySelfTest:                           config.Viper.GetBool(config.Flag.Service.Controller.Canary.SelfTest),
+			CertificateComponents:                    config.Viper.GetStringSlice(config.Flag.Service.Controller.Certificates.Components),
+			CertificateNamespace:                     config.Viper.GetString(config.Flag.Service.Controller.Certificates.Namespace),
+			Concurrency:                              config.Viper.GetInt(config.Flag.Service.Controller.Concurrency),
+			DedicatedIngressController:               config.Viper.GetBool(config.Flag.Service.HostCluster.Dedicated.Enabled),
+			DedicatedIngressControllerImage:          config.Viper.GetString(config.Flag.Service.HostCluster.Dedicated.Image),
+			DedicatedIngressControllerNamespace:      config.Viper.GetString(config.Flag.Service.HostCluster.Dedicated.Namespace),
+			DedicatedIngressControllerServiceAccount: config.Viper.GetString(config.Flag.Service.HostCluster.Dedicated.ServiceAccount),
+			DisabledResources:                        config.Viper.GetStringSlice(config.Flag.Service.Resources.Disable),
+			IngressControllers:                       ingressControllers,
+			MaxServicePorts:                          config.Viper.GetInt(config.Flag.Service.HostCluster.MaxServicePorts),
+			Namespaces:                               namespaces,
+			ProjectName:                              project.Name(),
+			PropagatedAnnotations:                    config.Viper.GetStringSlice(config.Flag.Service.HostCluster.Propagation.Annotations),
+			PropagatedLabels:                         config.Viper.GetStringSlice(config.Flag.Service.HostCluster.Propagation.Labels),
+			ProxyProtocol:                            config.Viper.GetBool(config.Flag.Service.HostCluster.IngressController.ProxyProtocol),
+			QuarantineRetryInterval:                  config.Viper.GetDuration(config.Flag.Service.Controller.Quarantine.RetryInterval),
+			QuarantineThreshold:                      config.Viper.GetInt(config.Flag.Service.Controller.Quarantine.Threshold),
+			ResourceOrder:                            config.Viper.GetString(config.Flag.Service.Controller.ResourceOrder),
+			ResultCacheTTL:                           config.Viper.GetDuration(config.Flag.Service.Controller.ResultCacheTTL),
+			SelfTest:                                 config.Viper.GetBool(config.Flag.Service.Controller.SelfTest.Enabled),
+			SelfTestTimeout:                          config.Viper.GetDuration(config.Flag.Service.Controller.SelfTest.Timeout),
+			ServicePolicy:                            servicePolicy,
+			StreamSnippets:                           config.Viper.GetString(config.Flag.Service.HostCluster.StreamSnippets),
 		}
 
 		ingressController, err = controller.NewIngress(c)
@@ -134,14 +711,116 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
+	var resyncer *resync.Resyncer
+	{
+		c := resync.Config{
+			G8sClient: ingressConfigLister,
+			Logger:    config.Logger,
+			Queue:     ingressController,
+
+			Rate: config.Viper.GetInt(config.Flag.Service.Controller.ResyncRate),
+		}
+
+		resyncer, err = resync.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var releaser *release.Releaser
+	{
+		k8sClients, err := clients.New(clients.Config{K8sClient: k8sClient})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := release.Config{
+			Allocator:     portAllocator,
+			G8sClient:     ingressConfigLister,
+			K8sClient:     k8sClients,
+			Ledger:        allocationLedger,
+			Locator:       ingressControllerLocator,
+			Logger:        config.Logger,
+			ServicePolicy: servicePolicy,
+		}
+
+		releaser, err = release.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var simulator *simulation.Simulator
+	{
+		c := simulation.Config{
+			Allocator: portAllocator,
+			Ledger:    allocationLedger,
+			Logger:    config.Logger,
+		}
+
+		simulator, err = simulation.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var exporter *export.Exporter
+	{
+		c := export.Config{
+			Ledger: allocationLedger,
+			Logger: config.Logger,
+		}
+
+		exporter, err = export.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var usageReporter *usage.Reporter
+	{
+		c := usage.Config{
+			Ledger: allocationLedger,
+			Logger: config.Logger,
+		}
+
+		usageReporter, err = usage.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// The bridge controller is optional. It is nil in case it is disabled.
+	var bridgeController bootShutdowner
+	if config.Viper.GetBool(config.Flag.Service.Bridge.Enabled) {
+		c := controller.BridgeConfig{
+			G8sClient:   g8sClient,
+			Logger:      config.Logger,
+			SchemaGuard: schemaGuard,
+
+			GuestClusterService:        config.Viper.GetString(config.Flag.Service.Bridge.GuestCluster.Service),
+			IngressControllerConfigMap: config.Viper.GetString(config.Flag.Service.Bridge.IngressController.ConfigMap),
+			IngressControllerNamespace: config.Viper.GetString(config.Flag.Service.Bridge.IngressController.Namespace),
+			IngressControllerService:   config.Viper.GetString(config.Flag.Service.Bridge.IngressController.Service),
+			Namespace:                  config.Viper.GetString(config.Flag.Service.Bridge.Namespace),
+			ProjectName:                project.Name(),
+		}
+
+		bridgeController, err = controller.NewBridge(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
 	var versionService *version.Service
 	{
 		versionConfig := version.DefaultConfig()
 
-		versionConfig.Description = config.Description
-		versionConfig.GitCommit = config.GitCommit
-		versionConfig.Name = config.Name
-		versionConfig.Source = config.Source
+		versionConfig.Description = project.Description()
+		versionConfig.GitCommit = project.GitSHA()
+		versionConfig.Name = project.Name()
+		versionConfig.Source = project.Source()
+		versionConfig.VersionBundles = NewVersionBundles()
 
 		versionService, err = version.New(versionConfig)
 		if err != nil {
@@ -150,18 +829,163 @@ func New(config Config) (*Service, error) {
 	}
 
 	newService := &Service{
-		Healthz: healthzService,
-		Version: versionService,
-
-		bootOnce:          sync.Once{},
-		ingressController: ingressController,
+		Export:     exporter,
+		Healthz:    healthzService,
+		History:    historyRecorder,
+		Legacy:     legacyWatcher,
+		Ledger:     allocationLedger,
+		Lister:     ingressConfigLister,
+		Release:    releaser,
+		Resync:     resyncer,
+		Simulation: simulator,
+		Usage:      usageReporter,
+		Version:    versionService,
+
+		adopter:             ledgerAdopter,
+		adoptionController:  adoptionController,
+		booted:              false,
+		bridgeController:    bridgeController,
+		cancel:              nil,
+		deletionAlerter:     deletionAlerter,
+		deprecationReporter: deprecationReporter,
+		ingressConfigLister: ingressConfigLister,
+		ingressController:   ingressController,
+		logger:              config.Logger,
+		monitoringManager:   monitoringManager,
+		mutex:               sync.Mutex{},
+		schemaGuard:         schemaGuard,
+		startupReporter:     startupReporter,
 	}
 
 	return newService, nil
 }
 
+// Boot starts the service's long running components. Boot is safe to be
+// called multiple times, also after Shutdown.
 func (s *Service) Boot() {
-	s.bootOnce.Do(func() {
-		go s.ingressController.Boot()
-	})
+	s.mutex.Lock()
+	if s.booted || s.cancel != nil {
+		s.mutex.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.mutex.Unlock()
+
+	// The preparation calls the API server and may take long. The mutex is not
+	// held meanwhile, so that Shutdown can cancel the preparation.
+	s.prepare(ctx)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	canceled := ctx.Err() != nil
+	cancel()
+	s.cancel = nil
+
+	if canceled {
+		if s.ingressConfigLister != nil {
+			s.ingressConfigLister.Shutdown()
+		}
+		return
+	}
+
+	s.ingressController.Boot()
+	if s.bridgeController != nil {
+		s.bridgeController.Boot()
+	}
+	if s.adoptionController != nil {
+		s.adoptionController.Boot()
+	}
+	if s.monitoringManager != nil {
+		s.monitoringManager.Boot()
+	}
+	if s.deprecationReporter != nil {
+		s.deprecationReporter.Boot()
+	}
+	if s.Legacy != nil {
+		s.Legacy.Boot()
+	}
+	if s.deletionAlerter != nil {
+		s.deletionAlerter.Boot()
+	}
+	s.booted = true
+}
+
+// prepare does everything which has to happen before the controllers are
+// booted. Failures are only logged, so that the operator runs anyway.
+func (s *Service) prepare(ctx context.Context) {
+	// The CRD schema of the cluster is checked before anything updates
+	// IngressConfigs. In case the check fails the operator is not degraded,
+	// which is logged, since refusing all updates would stop it entirely.
+	if s.schemaGuard != nil {
+		err := s.schemaGuard.Check(ctx)
+		if err != nil {
+			s.logger.Log("level", "error", "message", "failed checking the IngressConfig CRD schema", "stack", fmt.Sprintf("%#v", err))
+		}
+	}
+
+	if s.ingressConfigLister != nil {
+		s.ingressConfigLister.Boot()
+	}
+
+	// Legacy config map entries are adopted into the ledger before the
+	// controllers start allocating LB ports. Failing adoption must not prevent
+	// the operator from running, so it is only logged and retried on the next
+	// boot.
+	if s.adopter != nil {
+		err := s.adopter.Adopt(ctx)
+		if err != nil {
+			s.logger.Log("level", "error", "message", "failed adopting legacy config map entries into the ledger", "stack", fmt.Sprintf("%#v", err))
+		}
+	}
+
+	// The IngressConfigs the startup report covers have to be recorded before
+	// the controllers start reconciling them. Failing to record them only
+	// loses the report.
+	if s.startupReporter != nil {
+		err := s.startupReporter.Start(ctx)
+		if err != nil {
+			s.logger.Log("level", "error", "message", "failed starting the startup report", "stack", fmt.Sprintf("%#v", err))
+		}
+	}
+}
+
+// Shutdown stops the service's long running components. Shutdown is safe to
+// be called multiple times, also before Boot. A boot in progress is canceled.
+func (s *Service) Shutdown() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	if !s.booted {
+		return
+	}
+
+	if s.deletionAlerter != nil {
+		s.deletionAlerter.Shutdown()
+	}
+	if s.Legacy != nil {
+		s.Legacy.Shutdown()
+	}
+	if s.deprecationReporter != nil {
+		s.deprecationReporter.Shutdown()
+	}
+	if s.monitoringManager != nil {
+		s.monitoringManager.Shutdown()
+	}
+	if s.adoptionController != nil {
+		s.adoptionController.Shutdown()
+	}
+	if s.bridgeController != nil {
+		s.bridgeController.Shutdown()
+	}
+	s.ingressController.Shutdown()
+	if s.ingressConfigLister != nil {
+		s.ingressConfigLister.Shutdown()
+	}
+	s.booted = false
 }
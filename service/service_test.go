@@ -0,0 +1,72 @@
+package service
+
+import (
+	"sync"
+	"testing"
+)
+
+type testBootShutdowner struct {
+	boots     int
+	shutdowns int
+}
+
+func (b *testBootShutdowner) Boot() {
+	b.boots++
+}
+
+func (b *testBootShutdowner) Shutdown() {
+	b.shutdowns++
+}
+
+func Test_Service_BootShutdown(t *testing.T) {
+	b := &testBootShutdowner{}
+	bridge := &testBootShutdowner{}
+
+	s := &Service{
+		booted:            false,
+		bridgeController:  bridge,
+		ingressController: b,
+		mutex:             sync.Mutex{},
+	}
+
+	s.Shutdown()
+	s.Boot()
+	s.Boot()
+	s.Shutdown()
+	s.Shutdown()
+	s.Boot()
+
+	if b.boots != 2 {
+		t.Fatal("expected", 2, "got", b.boots)
+	}
+	if b.shutdowns != 1 {
+		t.Fatal("expected", 1, "got", b.shutdowns)
+	}
+	if bridge.boots != 2 {
+		t.Fatal("expected", 2, "got", bridge.boots)
+	}
+	if bridge.shutdowns != 1 {
+		t.Fatal("expected", 1, "got", bridge.shutdowns)
+	}
+}
+
+func Test_Service_BootShutdown_BridgeDisabled(t *testing.T) {
+	b := &testBootShutdowner{}
+
+	s := &Service{
+		booted:            false,
+		bridgeController:  nil,
+		ingressController: b,
+		mutex:             sync.Mutex{},
+	}
+
+	s.Boot()
+	s.Shutdown()
+
+	if b.boots != 1 {
+		t.Fatal("expected", 1, "got", b.boots)
+	}
+	if b.shutdowns != 1 {
+		t.Fatal("expected", 1, "got", b.shutdowns)
+	}
+}
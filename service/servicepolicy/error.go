@@ -0,0 +1,12 @@
+package servicepolicy
+
+import "github.com/giantswarm/microerror"
+
+var unknownFieldError = &microerror.Error{
+	Kind: "unknownFieldError",
+}
+
+// IsUnknownField asserts unknownFieldError.
+func IsUnknownField(err error) bool {
+	return microerror.Cause(err) == unknownFieldError
+}
@@ -0,0 +1,152 @@
+// Package servicepolicy implements the policy of the service spec fields of
+// shared ingress controllers the operator must preserve verbatim. The
+// operator only manages the ports of these services. Other fields, e.g.
+// externalIPs, are set by the owner of the ingress controller and must survive
+// every write of the operator.
+//
+// Fields unknown to the vendored API types, e.g. topologyKeys, are dropped by
+// updates of the whole service. Preserving them requires writing only the
+// fields managed by the operator, see RequiresPatch.
+package servicepolicy
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const (
+	ExternalIPs              = "externalIPs"
+	ExternalTrafficPolicy    = "externalTrafficPolicy"
+	LoadBalancerIP           = "loadBalancerIP"
+	LoadBalancerSourceRanges = "loadBalancerSourceRanges"
+	Selector                 = "selector"
+	SessionAffinity          = "sessionAffinity"
+	TopologyKeys             = "topologyKeys"
+)
+
+// field describes a service spec field the policy may preserve. Fields
+// unknown to the vendored API types have no accessors.
+type field struct {
+	get func(spec *apiv1.ServiceSpec) interface{}
+	set func(dst, src *apiv1.ServiceSpec)
+}
+
+var fields = map[string]field{
+	ExternalIPs: {
+		get: func(s *apiv1.ServiceSpec) interface{} { return s.ExternalIPs },
+		set: func(dst, src *apiv1.ServiceSpec) { dst.ExternalIPs = src.ExternalIPs },
+	},
+	ExternalTrafficPolicy: {
+		get: func(s *apiv1.ServiceSpec) interface{} { return s.ExternalTrafficPolicy },
+		set: func(dst, src *apiv1.ServiceSpec) { dst.ExternalTrafficPolicy = src.ExternalTrafficPolicy },
+	},
+	LoadBalancerIP: {
+		get: func(s *apiv1.ServiceSpec) interface{} { return s.LoadBalancerIP },
+		set: func(dst, src *apiv1.ServiceSpec) { dst.LoadBalancerIP = src.LoadBalancerIP },
+	},
+	LoadBalancerSourceRanges: {
+		get: func(s *apiv1.ServiceSpec) interface{} { return s.LoadBalancerSourceRanges },
+		set: func(dst, src *apiv1.ServiceSpec) { dst.LoadBalancerSourceRanges = src.LoadBalancerSourceRanges },
+	},
+	Selector: {
+		get: func(s *apiv1.ServiceSpec) interface{} { return s.Selector },
+		set: func(dst, src *apiv1.ServiceSpec) { dst.Selector = src.Selector },
+	},
+	SessionAffinity: {
+		get: func(s *apiv1.ServiceSpec) interface{} {
+			return []interface{}{s.SessionAffinity, s.SessionAffinityConfig}
+		},
+		set: func(dst, src *apiv1.ServiceSpec) {
+			dst.SessionAffinity = src.SessionAffinity
+			dst.SessionAffinityConfig = src.SessionAffinityConfig
+		},
+	},
+	TopologyKeys: {},
+}
+
+// Fields returns the names of all fields the policy may preserve, sorted.
+func Fields() []string {
+	var names []string
+	for n := range fields {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Policy is the set of service spec fields preserved verbatim. The zero value
+// preserves nothing.
+type Policy struct {
+	preserved map[string]bool
+}
+
+// New returns the policy preserving the given fields. Field names are the
+// JSON names of the service spec fields, e.g. externalIPs.
+func New(names []string) (Policy, error) {
+	p := Policy{
+		preserved: map[string]bool{},
+	}
+
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		if n == "" {
+			continue
+		}
+		if _, ok := fields[n]; !ok {
+			return Policy{}, microerror.Maskf(unknownFieldError, "service field %#q cannot be preserved, must be one of %s", n, strings.Join(Fields(), ", "))
+		}
+		p.preserved[n] = true
+	}
+
+	return p, nil
+}
+
+// Enforce resets the preserved fields of the given updated service to the
+// ones of the given current service, as read from the Kubernetes API. It
+// returns the names of the fields it reset, sorted.
+func (p Policy) Enforce(current, updated *apiv1.Service) []string {
+	var reset []string
+	for n := range p.preserved {
+		f := fields[n]
+		if f.get == nil {
+			continue
+		}
+		if reflect.DeepEqual(f.get(&current.Spec), f.get(&updated.Spec)) {
+			continue
+		}
+
+		f.set(&updated.Spec, &current.Spec)
+		reset = append(reset, n)
+	}
+	sort.Strings(reset)
+
+	return reset
+}
+
+// IsEmpty returns whether the policy preserves no field.
+func (p Policy) IsEmpty() bool {
+	return len(p.preserved) == 0
+}
+
+// Preserves returns whether the policy preserves the given field.
+func (p Policy) Preserves(name string) bool {
+	return p.preserved[name]
+}
+
+// RequiresPatch returns whether the policy preserves fields unknown to the
+// vendored API types. Services must then be written by patching only the
+// fields the operator manages, since updating the whole service drops them.
+func (p Policy) RequiresPatch() bool {
+	for n := range p.preserved {
+		if fields[n].get == nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package servicepolicy
+
+import (
+	"reflect"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func Test_Policy_New(t *testing.T) {
+	p, err := New([]string{"externalIPs", " topologyKeys", ""})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !p.Preserves(ExternalIPs) || !p.Preserves(TopologyKeys) || p.Preserves(Selector) {
+		t.Fatal("expected", []string{ExternalIPs, TopologyKeys}, "got", p.preserved)
+	}
+	if !p.RequiresPatch() {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	_, err = New([]string{"clusterIP"})
+	if !IsUnknownField(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	p, err = New(nil)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !p.IsEmpty() || p.RequiresPatch() {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Policy_Enforce(t *testing.T) {
+	testCases := []struct {
+		preserved       []string
+		updated         apiv1.ServiceSpec
+		expectedSpec    apiv1.ServiceSpec
+		expectedChanged []string
+	}{
+		// Test 0 ensures preserved fields changed in the updated service are
+		// reset to the current ones, while the ports are kept.
+		{
+			preserved: []string{ExternalIPs, LoadBalancerSourceRanges},
+			updated: apiv1.ServiceSpec{
+				LoadBalancerSourceRanges: []string{"0.0.0.0/0"},
+				Ports:                    []apiv1.ServicePort{{Port: 31000}},
+				Selector:                 map[string]string{"app": "other"},
+			},
+			expectedSpec: apiv1.ServiceSpec{
+				ExternalIPs:              []string{"10.0.0.1"},
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				Ports:                    []apiv1.ServicePort{{Port: 31000}},
+				Selector:                 map[string]string{"app": "other"},
+			},
+			expectedChanged: []string{ExternalIPs, LoadBalancerSourceRanges},
+		},
+		// Test 1 ensures nothing is reset in case the preserved fields did not
+		// change.
+		{
+			preserved: []string{ExternalIPs, Selector, TopologyKeys},
+			updated: apiv1.ServiceSpec{
+				ExternalIPs: []string{"10.0.0.1"},
+				Ports:       []apiv1.ServicePort{{Port: 31000}},
+				Selector:    map[string]string{"app": "ingress-controller"},
+			},
+			expectedSpec: apiv1.ServiceSpec{
+				ExternalIPs: []string{"10.0.0.1"},
+				Ports:       []apiv1.ServicePort{{Port: 31000}},
+				Selector:    map[string]string{"app": "ingress-controller"},
+			},
+			expectedChanged: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		current := &apiv1.Service{
+			Spec: apiv1.ServiceSpec{
+				ExternalIPs:              []string{"10.0.0.1"},
+				LoadBalancerSourceRanges: []string{"10.0.0.0/8"},
+				Selector:                 map[string]string{"app": "ingress-controller"},
+			},
+		}
+		updated := &apiv1.Service{Spec: tc.updated}
+
+		p, err := New(tc.preserved)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		changed := p.Enforce(current, updated)
+		if !reflect.DeepEqual(changed, tc.expectedChanged) {
+			t.Fatal("test", i, "expected", tc.expectedChanged, "got", changed)
+		}
+		if !reflect.DeepEqual(updated.Spec, tc.expectedSpec) {
+			t.Fatal("test", i, "expected", tc.expectedSpec, "got", updated.Spec)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package simulation computes the state the operator would generate for an
+// IngressConfig without persisting anything. Provisioning tooling uses it to
+// check IngressConfigs before creating them, e.g. whether their LB ports are
+// valid and still free.
+package simulation
+
+import (
+	"context"
+	"sort"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/configmap"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/service"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/resource/validation"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+// Config represents the configuration used to create a new simulator.
+type Config struct {
+	Allocator *allocator.Allocator
+	Ledger    *ledger.Ledger
+	Logger    micrologger.Logger
+}
+
+// Simulator computes the state generated for IngressConfigs.
+type Simulator struct {
+	allocator *allocator.Allocator
+	ledger    *ledger.Ledger
+	logger    micrologger.Logger
+}
+
+// Conflict is an LB port of a simulated IngressConfig which is already
+// allocated to another guest cluster.
+type Conflict struct {
+	ClusterID string `json:"clusterID"`
+	LBPort    int    `json:"lbPort"`
+}
+
+// Result is the state that would be generated for an IngressConfig.
+type Result struct {
+	// ConfigMapData is the data of the ingress controller config map
+	// generated for the IngressConfig.
+	ConfigMapData map[string]string `json:"configMapData"`
+	// Conflicts are the LB ports already allocated to other guest clusters.
+	Conflicts []Conflict `json:"conflicts"`
+	// ServicePorts are the ports of the ingress controller service generated
+	// for the IngressConfig.
+	ServicePorts []apiv1.ServicePort `json:"servicePorts"`
+	// ValidationErrors are the reasons the IngressConfig would be rejected
+	// for.
+	ValidationErrors []string `json:"validationErrors"`
+}
+
+// New creates a new configured simulator.
+func New(config Config) (*Simulator, error) {
+	if config.Allocator == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Allocator must not be empty", config)
+	}
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Ledger must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	s := &Simulator{
+		allocator: config.Allocator,
+		ledger:    config.Ledger,
+		logger:    config.Logger,
+	}
+
+	return s, nil
+}
+
+// Simulate returns the state that would be generated for the given custom
+// object. Rejections and conflicts are part of the result. Errors are only
+// returned in case the simulation itself fails.
+func (s *Simulator) Simulate(ctx context.Context, customObject v1alpha1.IngressConfig) (Result, error) {
+	// Ingress ports are not discovered, since the guest cluster of a simulated
+	// IngressConfig usually does not exist yet.
+	cc := &controllercontext.Context{}
+
+	result := Result{
+		ConfigMapData:    configmap.DesiredData(cc, customObject),
+		Conflicts:        []Conflict{},
+		ServicePorts:     service.DesiredPorts(cc, customObject),
+		ValidationErrors: []string{},
+	}
+
+	err := validation.Validate(s.allocator, customObject)
+	if validation.IsInvalidSpec(err) || key.IsInvalidSpec(err) || key.IsInvalidAnnotation(err) {
+		result.ValidationErrors = append(result.ValidationErrors, err.Error())
+	} else if err != nil {
+		return Result{}, microerror.Mask(err)
+	}
+
+	allocations, err := s.ledger.List(ctx)
+	if err != nil {
+		return Result{}, microerror.Mask(err)
+	}
+
+	owners := map[int]string{}
+	for _, a := range allocations {
+		owners[a.LBPort] = a.ClusterID
+	}
+
+	for _, p := range key.LBPorts(customObject) {
+		owner, ok := owners[p]
+		if ok && owner != key.ClusterID(customObject) {
+			result.Conflicts = append(result.Conflicts, Conflict{ClusterID: owner, LBPort: p})
+		}
+	}
+
+	sort.Slice(result.Conflicts, func(i, j int) bool { return result.Conflicts[i].LBPort < result.Conflicts[j].LBPort })
+
+	return result, nil
+}
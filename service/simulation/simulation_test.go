@@ -0,0 +1,198 @@
+package simulation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/giantswarm/ingress-operator/service/allocator"
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+func Test_Simulation_Simulate(t *testing.T) {
+	testCases := []struct {
+		ClusterID                string
+		LBPorts                  []int
+		PinnedPorts              string
+		ExpectedConfigMapData    map[string]string
+		ExpectedConflicts        []Conflict
+		ExpectedServicePorts     int
+		ExpectedValidationErrors int
+	}{
+		// Test 0 ensures free ports neither conflict nor get rejected.
+		{
+			ClusterID: "xk3m2",
+			LBPorts:   []int{31005},
+			ExpectedConfigMapData: map[string]string{
+				"31005": "xk3m2/worker:30010",
+			},
+			ExpectedConflicts:        []Conflict{},
+			ExpectedServicePorts:     1,
+			ExpectedValidationErrors: 0,
+		},
+
+		// Test 1 ensures ports allocated to other guest clusters are conflicts.
+		{
+			ClusterID: "xk3m2",
+			LBPorts:   []int{31001, 31000},
+			ExpectedConfigMapData: map[string]string{
+				"31000": "xk3m2/worker:30011",
+				"31001": "xk3m2/worker:30010",
+			},
+			ExpectedConflicts: []Conflict{
+				{ClusterID: "al9qy", LBPort: 31000},
+				{ClusterID: "al9qy", LBPort: 31001},
+			},
+			ExpectedServicePorts:     2,
+			ExpectedValidationErrors: 0,
+		},
+
+		// Test 2 ensures ports allocated to the same guest cluster are no
+		// conflicts.
+		{
+			ClusterID: "al9qy",
+			LBPorts:   []int{31000},
+			ExpectedConfigMapData: map[string]string{
+				"31000": "al9qy/worker:30010",
+			},
+			ExpectedConflicts:        []Conflict{},
+			ExpectedServicePorts:     1,
+			ExpectedValidationErrors: 0,
+		},
+
+		// Test 3 ensures reserved ports are validation errors.
+		{
+			ClusterID: "xk3m2",
+			LBPorts:   []int{30000},
+			ExpectedConfigMapData: map[string]string{
+				"30000": "xk3m2/worker:30010",
+			},
+			ExpectedConflicts:        []Conflict{},
+			ExpectedServicePorts:     1,
+			ExpectedValidationErrors: 1,
+		},
+
+		// Test 4 ensures invalid annotations are validation errors.
+		{
+			ClusterID:   "xk3m2",
+			LBPorts:     []int{31005},
+			PinnedPorts: "foo",
+			ExpectedConfigMapData: map[string]string{
+				"31005": "xk3m2/worker:30010",
+			},
+			ExpectedConflicts:        []Conflict{},
+			ExpectedServicePorts:     1,
+			ExpectedValidationErrors: 1,
+		},
+	}
+
+	ctx := context.TODO()
+
+	var err error
+
+	var l *ledger.Ledger
+	{
+		var k8sClient clients.Interface
+		k8sClient, err = clients.New(clients.Config{K8sClient: fake.NewSimpleClientset()})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := ledger.Config{
+			K8sClient: k8sClient,
+			Logger:    microloggertest.New(),
+
+			Name:      "ingress-operator-allocations",
+			Namespace: "kube-system",
+		}
+
+		l, err = ledger.New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		err = l.Record(ctx, "al9qy", []ledger.Allocation{
+			{IngressPort: 30010, LBPort: 31000, Protocol: "http"},
+			{IngressPort: 30011, LBPort: 31001, Protocol: "https"},
+		})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	var newSimulator *Simulator
+	{
+		var a *allocator.Allocator
+		a, err = allocator.New(allocator.Config{Reserved: []int{30000}})
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		c := Config{
+			Allocator: a,
+			Ledger:    l,
+			Logger:    microloggertest.New(),
+		}
+
+		newSimulator, err = New(c)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	for i, tc := range testCases {
+		customObject := v1alpha1.IngressConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					key.PinnedPortsAnnotation: tc.PinnedPorts,
+				},
+			},
+			Spec: v1alpha1.IngressConfigSpec{
+				GuestCluster: v1alpha1.IngressConfigSpecGuestCluster{
+					ID:        tc.ClusterID,
+					Namespace: tc.ClusterID,
+					Service:   "worker",
+				},
+				HostCluster: v1alpha1.IngressConfigSpecHostCluster{
+					IngressController: v1alpha1.IngressConfigSpecHostClusterIngressController{
+						ConfigMap: "ingress-controller",
+						Namespace: "kube-system",
+						Service:   "ingress-controller",
+					},
+				},
+			},
+		}
+		for n, p := range tc.LBPorts {
+			customObject.Spec.ProtocolPorts = append(customObject.Spec.ProtocolPorts, v1alpha1.IngressConfigSpecProtocolPort{
+				IngressPort: 30010 + n,
+				LBPort:      p,
+				Protocol:    "http",
+			})
+		}
+
+		result, err := newSimulator.Simulate(ctx, customObject)
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		if !reflect.DeepEqual(result.ConfigMapData, tc.ExpectedConfigMapData) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedConfigMapData, result.ConfigMapData)
+		}
+		if !reflect.DeepEqual(result.Conflicts, tc.ExpectedConflicts) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedConflicts, result.Conflicts)
+		}
+		if len(result.ServicePorts) != tc.ExpectedServicePorts {
+			t.Fatal("test", i, "expected", tc.ExpectedServicePorts, "got", len(result.ServicePorts))
+		}
+		if len(result.ValidationErrors) != tc.ExpectedValidationErrors {
+			t.Fatal("test", i, "expected", tc.ExpectedValidationErrors, "got", result.ValidationErrors)
+		}
+	}
+}
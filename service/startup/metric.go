@@ -0,0 +1,36 @@
+package startup
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/ingress-operator/pkg/project"
+)
+
+const (
+	PrometheusSubsystem = "startup"
+)
+
+var (
+	reportGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "report",
+			Help:      "A gauge metric expressing the items of the report of the first reconciliation of all IngressConfigs after the operator started.",
+		},
+		[]string{"item"},
+	)
+	reportDurationGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: project.PrometheusNamespace,
+			Subsystem: PrometheusSubsystem,
+			Name:      "report_duration_seconds",
+			Help:      "A gauge metric expressing the seconds the first reconciliation of all IngressConfigs after the operator started took.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(reportGauge)
+	prometheus.MustRegister(reportDurationGauge)
+}
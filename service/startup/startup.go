@@ -0,0 +1,216 @@
+// Package startup reports the outcome of the first reconciliation of all
+// IngressConfigs after the operator started. Operators upgrading the operator
+// get a single summary of how many IngressConfigs got reconciled, how many LB
+// ports got verified, how much drift got corrected and how many
+// reconciliations failed, instead of having to infer it from silence.
+package startup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/clients"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/key"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+// Config represents the configuration used to create a new reporter.
+type Config struct {
+	G8sClient clients.IngressConfigs
+	Logger    micrologger.Logger
+
+	// Handles returns whether the given IngressConfig is reconciled by this
+	// operator instance. Only these are waited for. It is optional, in which
+	// case all IngressConfigs are waited for.
+	Handles func(customObject v1alpha1.IngressConfig) bool
+	// Timeout is the maximum time waited for the first reconciliation of all
+	// IngressConfigs. The report is emitted at the latest after it, counting
+	// the IngressConfigs not reconciled yet as pending.
+	Timeout time.Duration
+}
+
+// Report summarizes the first reconciliation of all IngressConfigs existing
+// when the operator started.
+type Report struct {
+	// Corrections is the number of host cluster objects updated because they
+	// drifted from the desired state.
+	Corrections int
+	Duration    time.Duration
+	// Errors is the number of IngressConfigs whose reconciliation failed.
+	Errors         int
+	IngressConfigs int
+	// Pending is the number of IngressConfigs not reconciled before the
+	// timeout.
+	Pending int
+	// PortsVerified is the number of LB ports of the IngressConfigs reconciled
+	// successfully.
+	PortsVerified int
+}
+
+// Reporter collects and emits the startup report.
+type Reporter struct {
+	g8sClient clients.IngressConfigs
+	logger    micrologger.Logger
+
+	handles func(customObject v1alpha1.IngressConfig) bool
+	timeout time.Duration
+
+	mutex   sync.Mutex
+	done    bool
+	now     func() time.Time
+	pending map[string]bool
+	report  Report
+	started time.Time
+}
+
+// New creates a new configured reporter.
+func New(config Config) (*Reporter, error) {
+	if config.G8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Timeout <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Timeout must be greater than 0", config)
+	}
+
+	r := &Reporter{
+		g8sClient: config.G8sClient,
+		logger:    config.Logger,
+
+		handles: config.Handles,
+		timeout: config.Timeout,
+
+		now:     time.Now,
+		pending: nil,
+	}
+
+	return r, nil
+}
+
+// Start records the IngressConfigs existing at the time the operator starts.
+// It has to be called before the controllers start reconciling them.
+func (r *Reporter) Start(ctx context.Context) error {
+	list, err := r.g8sClient.ListIngressConfigs(ctx, "")
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.pending = map[string]bool{}
+	for _, customObject := range list.Items {
+		if r.handles != nil && !r.handles(customObject) {
+			continue
+		}
+		r.pending[objectKey(customObject.Namespace, customObject.Name)] = true
+	}
+	r.report = Report{IngressConfigs: len(r.pending)}
+	r.started = r.now()
+
+	if len(r.pending) == 0 {
+		r.emit()
+		return nil
+	}
+
+	time.AfterFunc(r.timeout, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+
+		r.emit()
+	})
+
+	return nil
+}
+
+// Observe records the outcome of the first reconciliation loop of the given
+// object. It implements outcome.Observer.
+func (r *Reporter) Observe(ctx context.Context, obj interface{}, o outcome.Outcome, err error) {
+	customObject, convertErr := key.ToCustomObject(obj)
+	if convertErr != nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	k := objectKey(customObject.Namespace, customObject.Name)
+	if r.done || !r.pending[k] {
+		return
+	}
+
+	delete(r.pending, k)
+
+	switch o {
+	case outcome.Failed:
+		r.report.Errors++
+	case outcome.Succeeded:
+		r.report.PortsVerified += len(key.LBPorts(customObject))
+	}
+
+	cc, ccErr := controllercontext.FromContext(ctx)
+	if ccErr == nil {
+		r.report.Corrections += cc.Corrections
+	}
+
+	if len(r.pending) == 0 {
+		r.emit()
+	}
+}
+
+// emit logs and exports the report in case it was not emitted yet. The mutex
+// has to be held by the caller.
+func (r *Reporter) emit() {
+	if r.done {
+		return
+	}
+	r.done = true
+
+	r.report.Pending = len(r.pending)
+	r.report.Duration = r.now().Sub(r.started)
+
+	reportGauge.WithLabelValues("corrections").Set(float64(r.report.Corrections))
+	reportGauge.WithLabelValues("errors").Set(float64(r.report.Errors))
+	reportGauge.WithLabelValues("ingress_configs").Set(float64(r.report.IngressConfigs))
+	reportGauge.WithLabelValues("pending").Set(float64(r.report.Pending))
+	reportGauge.WithLabelValues("ports_verified").Set(float64(r.report.PortsVerified))
+	reportDurationGauge.Set(r.report.Duration.Seconds())
+
+	level := "info"
+	if r.report.Errors > 0 || r.report.Pending > 0 {
+		level = "warning"
+	}
+
+	r.logger.Log(
+		"level", level,
+		"message", fmt.Sprintf("reconciled %d ingress configs after startup", r.report.IngressConfigs-r.report.Pending),
+		"corrections", r.report.Corrections,
+		"duration", r.report.Duration.String(),
+		"errors", r.report.Errors,
+		"ingressConfigs", r.report.IngressConfigs,
+		"pending", r.report.Pending,
+		"portsVerified", r.report.PortsVerified,
+	)
+}
+
+// Report returns the startup report and whether it got emitted already.
+func (r *Reporter) Report() (Report, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.report, r.done
+}
+
+func objectKey(namespace, name string) string {
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
@@ -0,0 +1,207 @@
+package startup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/apiextensions/pkg/apis/core/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/giantswarm/operatorkit/controller"
+	"github.com/giantswarm/operatorkit/controller/context/reconciliationcanceledcontext"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/ingress-operator/service/clients/clientstest"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/controllercontext"
+	"github.com/giantswarm/ingress-operator/service/controller/v2/outcome"
+)
+
+// testResource fails for the IngressConfigs of the given names, cancels the
+// reconciliation of the IngressConfigs of the given names and records a
+// correction otherwise.
+type testResource struct {
+	canceled map[string]bool
+	failed   map[string]bool
+}
+
+func (r *testResource) EnsureCreated(ctx context.Context, obj interface{}) error {
+	customObject := obj.(*v1alpha1.IngressConfig)
+	if r.failed[customObject.Name] {
+		return microerror.New("test error")
+	}
+	if r.canceled[customObject.Name] {
+		reconciliationcanceledcontext.SetCanceled(ctx)
+		return nil
+	}
+
+	cc, err := controllercontext.FromContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	cc.Corrections++
+
+	return nil
+}
+
+func (r *testResource) EnsureDeleted(ctx context.Context, obj interface{}) error {
+	return nil
+}
+
+func (r *testResource) Name() string {
+	return "test"
+}
+
+func newTestIngressConfig(name string, lbPorts ...int) *v1alpha1.IngressConfig {
+	customObject := &v1alpha1.IngressConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+	}
+	for _, p := range lbPorts {
+		customObject.Spec.ProtocolPorts = append(customObject.Spec.ProtocolPorts, v1alpha1.IngressConfigSpecProtocolPort{LBPort: p})
+	}
+
+	return customObject
+}
+
+func Test_Startup_Reporter(t *testing.T) {
+	testCases := []struct {
+		Canceled       []string
+		Failed         []string
+		Reconciled     []string
+		Unhandled      []string
+		ExpectedDone   bool
+		ExpectedReport Report
+	}{
+		// Test 0 ensures the report is emitted once all IngressConfigs got
+		// reconciled.
+		{
+			Reconciled:   []string{"al9qy", "p1l6x"},
+			ExpectedDone: true,
+			ExpectedReport: Report{
+				Corrections:    4,
+				IngressConfigs: 2,
+				PortsVerified:  3,
+			},
+		},
+
+		// Test 1 ensures the report is not emitted as long as IngressConfigs
+		// did not get reconciled.
+		{
+			Reconciled:   []string{"al9qy"},
+			ExpectedDone: false,
+			ExpectedReport: Report{
+				Corrections:    2,
+				IngressConfigs: 2,
+				PortsVerified:  2,
+			},
+		},
+
+		// Test 2 ensures failed and canceled reconciliations complete the
+		// report without verifying any ports.
+		{
+			Canceled:     []string{"p1l6x"},
+			Failed:       []string{"al9qy"},
+			Reconciled:   []string{"al9qy", "p1l6x"},
+			ExpectedDone: true,
+			ExpectedReport: Report{
+				Errors:         1,
+				IngressConfigs: 2,
+			},
+		},
+
+		// Test 3 ensures only the first reconciliation of IngressConfigs is
+		// reported.
+		{
+			Reconciled:   []string{"al9qy", "al9qy", "p1l6x"},
+			ExpectedDone: true,
+			ExpectedReport: Report{
+				Corrections:    4,
+				IngressConfigs: 2,
+				PortsVerified:  3,
+			},
+		},
+
+		// Test 4 ensures IngressConfigs reconciled by other operator instances
+		// are not waited for.
+		{
+			Reconciled:   []string{"al9qy"},
+			Unhandled:    []string{"p1l6x"},
+			ExpectedDone: true,
+			ExpectedReport: Report{
+				Corrections:    2,
+				IngressConfigs: 1,
+				PortsVerified:  2,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		ingressConfigs := map[string]*v1alpha1.IngressConfig{
+			"al9qy": newTestIngressConfig("al9qy", 31000, 31001),
+			"p1l6x": newTestIngressConfig("p1l6x", 31002),
+		}
+
+		var reporter *Reporter
+		{
+			c := Config{
+				G8sClient: clientstest.NewIngressConfigs(*ingressConfigs["al9qy"], *ingressConfigs["p1l6x"]),
+				Logger:    microloggertest.New(),
+
+				Handles: func(customObject v1alpha1.IngressConfig) bool {
+					for _, n := range tc.Unhandled {
+						if customObject.Name == n {
+							return false
+						}
+					}
+					return true
+				},
+				Timeout: time.Hour,
+			}
+
+			var err error
+			reporter, err = New(c)
+			if err != nil {
+				t.Fatal("test", i, "expected", nil, "got", err)
+			}
+			now := time.Unix(0, 0)
+			reporter.now = func() time.Time { return now }
+		}
+
+		err := reporter.Start(context.Background())
+		if err != nil {
+			t.Fatal("test", i, "expected", nil, "got", err)
+		}
+
+		r := &testResource{canceled: map[string]bool{}, failed: map[string]bool{}}
+		for _, n := range tc.Canceled {
+			r.canceled[n] = true
+		}
+		for _, n := range tc.Failed {
+			r.failed[n] = true
+		}
+		resources := outcome.Wrap([]controller.Resource{r, r}, reporter)
+
+		for _, n := range tc.Reconciled {
+			ctx := controllercontext.NewContext(context.Background(), controllercontext.Context{})
+			ctx = reconciliationcanceledcontext.NewContext(ctx, make(chan struct{}))
+
+			for _, resource := range resources {
+				err := resource.EnsureCreated(ctx, ingressConfigs[n])
+				if err != nil || reconciliationcanceledcontext.IsCanceled(ctx) {
+					break
+				}
+			}
+		}
+
+		report, done := reporter.Report()
+		if done != tc.ExpectedDone {
+			t.Fatal("test", i, "expected", tc.ExpectedDone, "got", done)
+		}
+		if report != tc.ExpectedReport {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.ExpectedReport, report)
+		}
+	}
+}
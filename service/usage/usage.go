@@ -0,0 +1,130 @@
+// Package usage reports the LB ports allocated to guest clusters and for how
+// long they have been allocated, based on the allocation ledger. Billing and
+// reporting systems charging per exposed port consume the report.
+package usage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+// Config represents the configuration used to create a new reporter.
+type Config struct {
+	Ledger *ledger.Ledger
+	Logger micrologger.Logger
+}
+
+// Reporter reports the usage of LB ports per guest cluster.
+type Reporter struct {
+	ledger *ledger.Ledger
+	logger micrologger.Logger
+}
+
+// Allocation is the usage of a single LB port.
+type Allocation struct {
+	AllocatedAt time.Time `json:"allocatedAt"`
+	LBPort      int       `json:"lbPort"`
+	Protocol    string    `json:"protocol"`
+	// UptimeSeconds is the number of seconds the LB port has been allocated
+	// for at the time of the report.
+	UptimeSeconds int64 `json:"uptimeSeconds"`
+}
+
+// Cluster is the usage of a single guest cluster.
+type Cluster struct {
+	Allocations []Allocation `json:"allocations"`
+	ClusterID   string       `json:"clusterID"`
+	// LBPorts is the number of LB ports allocated to the guest cluster.
+	LBPorts int `json:"lbPorts"`
+	// PortSeconds is the sum of the uptimes of all allocations of the guest
+	// cluster.
+	PortSeconds int64 `json:"portSeconds"`
+}
+
+// Report is the usage of all guest clusters at a point in time.
+type Report struct {
+	// Clusters are the guest clusters having LB ports allocated, sorted by
+	// their IDs.
+	Clusters []Cluster `json:"clusters"`
+	Time     time.Time `json:"time"`
+}
+
+// New creates a new configured reporter.
+func New(config Config) (*Reporter, error) {
+	if config.Ledger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Ledger must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Reporter{
+		ledger: config.Ledger,
+		logger: config.Logger,
+	}
+
+	return r, nil
+}
+
+// Report returns the current usage of all guest clusters.
+func (r *Reporter) Report(ctx context.Context) (Report, error) {
+	allocations, err := r.ledger.List(ctx)
+	if err != nil {
+		return Report{}, microerror.Mask(err)
+	}
+
+	return newReport(allocations, time.Now().UTC().Truncate(time.Second)), nil
+}
+
+// newReport computes the usage of the given allocations at the given time.
+// Allocations without allocation time have no uptime, since they got recorded
+// before allocation times were tracked and have not been recorded since.
+func newReport(allocations []ledger.Allocation, t time.Time) Report {
+	clusters := map[string]*Cluster{}
+	for _, a := range allocations {
+		c, ok := clusters[a.ClusterID]
+		if !ok {
+			c = &Cluster{
+				Allocations: []Allocation{},
+				ClusterID:   a.ClusterID,
+			}
+			clusters[a.ClusterID] = c
+		}
+
+		var uptime int64
+		if !a.AllocatedAt.IsZero() && a.AllocatedAt.Before(t) {
+			uptime = int64(t.Sub(a.AllocatedAt).Seconds())
+		}
+
+		c.Allocations = append(c.Allocations, Allocation{
+			AllocatedAt:   a.AllocatedAt,
+			LBPort:        a.LBPort,
+			Protocol:      a.Protocol,
+			UptimeSeconds: uptime,
+		})
+		c.LBPorts++
+		c.PortSeconds += uptime
+	}
+
+	report := Report{
+		Clusters: []Cluster{},
+		Time:     t,
+	}
+	for _, c := range clusters {
+		sort.Slice(c.Allocations, func(i, j int) bool {
+			return c.Allocations[i].LBPort < c.Allocations[j].LBPort
+		})
+		report.Clusters = append(report.Clusters, *c)
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		return report.Clusters[i].ClusterID < report.Clusters[j].ClusterID
+	})
+
+	return report
+}
@@ -0,0 +1,81 @@
+package usage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/ingress-operator/service/ledger"
+)
+
+func Test_Usage_newReport(t *testing.T) {
+	t0 := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Allocations []ledger.Allocation
+		Expected    []Cluster
+	}{
+		// Test 0 ensures an empty ledger results in an empty report.
+		{
+			Allocations: nil,
+			Expected:    []Cluster{},
+		},
+
+		// Test 1 ensures allocations are grouped by guest cluster and their
+		// uptimes are summed up.
+		{
+			Allocations: []ledger.Allocation{
+				{AllocatedAt: t0.Add(-time.Hour), ClusterID: "p1l6x", LBPort: 31002, Protocol: "http"},
+				{AllocatedAt: t0.Add(-2 * time.Hour), ClusterID: "al9qy", LBPort: 31001, Protocol: "https"},
+				{AllocatedAt: t0.Add(-time.Minute), ClusterID: "al9qy", LBPort: 31000, Protocol: "http"},
+			},
+			Expected: []Cluster{
+				{
+					Allocations: []Allocation{
+						{AllocatedAt: t0.Add(-time.Minute), LBPort: 31000, Protocol: "http", UptimeSeconds: 60},
+						{AllocatedAt: t0.Add(-2 * time.Hour), LBPort: 31001, Protocol: "https", UptimeSeconds: 7200},
+					},
+					ClusterID:   "al9qy",
+					LBPorts:     2,
+					PortSeconds: 7260,
+				},
+				{
+					Allocations: []Allocation{
+						{AllocatedAt: t0.Add(-time.Hour), LBPort: 31002, Protocol: "http", UptimeSeconds: 3600},
+					},
+					ClusterID:   "p1l6x",
+					LBPorts:     1,
+					PortSeconds: 3600,
+				},
+			},
+		},
+
+		// Test 2 ensures allocations without allocation time are counted without
+		// uptime.
+		{
+			Allocations: []ledger.Allocation{
+				{ClusterID: "al9qy", LBPort: 31000, Protocol: "http"},
+			},
+			Expected: []Cluster{
+				{
+					Allocations: []Allocation{
+						{LBPort: 31000, Protocol: "http", UptimeSeconds: 0},
+					},
+					ClusterID:   "al9qy",
+					LBPorts:     1,
+					PortSeconds: 0,
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		report := newReport(tc.Allocations, t0)
+		if !report.Time.Equal(t0) {
+			t.Fatal("test", i, "expected", t0, "got", report.Time)
+		}
+		if !reflect.DeepEqual(report.Clusters, tc.Expected) {
+			t.Fatalf("test %d expected %#v got %#v", i, tc.Expected, report.Clusters)
+		}
+	}
+}